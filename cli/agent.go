@@ -34,8 +34,20 @@ type agentMessageResponse struct {
 }
 
 type agentInfo struct {
-	Version  string `json:"version"`
-	BundleID string `json:"bundleId"`
+	Name      string `json:"name"`
+	Installed bool   `json:"installed"`
+	Version   string `json:"version,omitempty"`
+	BundleID  string `json:"bundleId"`
+
+	// ExpectedVersion is the version 'agent install' and 'agent update' put
+	// on the device, LatestVersion the newest release on GitHub
+	ExpectedVersion string `json:"expectedVersion"`
+	LatestVersion   string `json:"latestVersion,omitempty"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+
+	// Pinned is set when ExpectedVersion comes from config.json rather than
+	// this mobilecli
+	Pinned bool `json:"pinned,omitempty"`
 }
 
 type agentStatusResponse struct {
@@ -44,14 +56,16 @@ type agentStatusResponse struct {
 }
 
 var agentCmd = &cobra.Command{
-	Use:   "agent",
-	Short: "Agent management commands",
-	Long:  `Commands for managing the on-device agent.`,
+	Use:     "agent",
+	Aliases: []string{"agents"},
+	Short:   "Agent management commands",
+	Long:    `Commands for managing the on-device agent.`,
 }
 
 var agentStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Check agent installation status on a device",
+	Long:  `Reports whether the on-device agent is installed, its version, the version this mobilecli installs and the latest released version. The agent is DeviceKit on Android, and the DeviceKit WebDriverAgent runner on iOS.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		device, err := commands.FindDeviceOrAutoSelect(deviceId)
 		if err != nil {
@@ -59,22 +73,26 @@ var agentStatusCmd = &cobra.Command{
 		}
 
 		agent := findInstalledAgent(device)
+		info := describeAgent(device, agent)
 		if agent == nil {
 			printJson(&commands.CommandResponse{
 				Status: "fail",
-				Data: agentMessageResponse{
+				Data: agentStatusResponse{
 					Message: "Agent is not installed on the device",
+					Agent:   info,
 				},
 			})
 			return nil
 		}
 
+		message := fmt.Sprintf("Agent version %s is installed on device", agent.Version)
+		if info.UpdateAvailable {
+			message += fmt.Sprintf(", run 'mobilecli agent update' to install version %s", info.ExpectedVersion)
+		}
+
 		printJson(commands.NewSuccessResponse(agentStatusResponse{
-			Message: fmt.Sprintf("Agent version %s is installed on device", agent.Version),
-			Agent: agentInfo{
-				Version:  agent.Version,
-				BundleID: agent.PackageName,
-			},
+			Message: message,
+			Agent:   info,
 		}))
 		return nil
 	},
@@ -94,17 +112,22 @@ var agentInstallCmd = &cobra.Command{
 		utils.Verbose("platform: %s", device.Platform())
 		utils.Verbose("type: %s", device.DeviceType())
 
+		expectedVersion, err := agentVersionForPlatform(device.Platform())
+		if err != nil {
+			return err
+		}
+
+		if err := devices.CheckAgentCompatibility(device.Platform(), expectedVersion, device.Version()); err != nil {
+			return err
+		}
+
 		if !agentForce {
 			if agent := findInstalledAgent(device); agent != nil {
-				expectedVersion := agentVersionForPlatform(device.Platform())
 				if agent.Version == expectedVersion {
 					utils.Verbose("agent already installed with version %s", agent.Version)
 					printJson(commands.NewSuccessResponse(agentStatusResponse{
 						Message: "Agent is already installed",
-						Agent: agentInfo{
-							Version:  agent.Version,
-							BundleID: agent.PackageName,
-						},
+						Agent:   describeAgent(device, agent),
 					}))
 					return nil
 				}
@@ -116,28 +139,62 @@ var agentInstallCmd = &cobra.Command{
 			}
 		}
 
-		var installErr error
-		switch device.Platform() {
-		case "ios":
-			switch device.DeviceType() {
-			case "simulator":
-				installErr = installAgentOnSimulator(device)
-			case "real":
-				if agentProvisioningProfile == "" {
-					return fmt.Errorf("--provisioning-profile is required for real iOS devices")
-				}
-				installErr = installAgentOnRealIOS(device)
-			default:
-				return fmt.Errorf("unsupported device type: %s", device.DeviceType())
+		if err := installAgent(device, expectedVersion); err != nil {
+			return err
+		}
+
+		agent := findInstalledAgent(device)
+		if agent == nil {
+			return fmt.Errorf("agent was installed but could not be found")
+		}
+
+		printJson(commands.NewSuccessResponse(agentStatusResponse{
+			Message: "Agent installed successfully",
+			Agent:   describeAgent(device, agent),
+		}))
+		return nil
+	},
+}
+
+var agentUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update the agent on a device",
+	Long: `Installs the version of the on-device agent this mobilecli installs over the one on the device, unless that version is already installed. Installs the agent if it is missing.
+
+An agent newer than that version is only replaced with --force. Android can't install an older version over a newer one, so the newer one is uninstalled first, and reinstalled if the older one fails to install.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device, err := commands.FindDeviceOrAutoSelect(deviceId)
+		if err != nil {
+			return err
+		}
+
+		expectedVersion, err := agentVersionForPlatform(device.Platform())
+		if err != nil {
+			return err
+		}
+
+		if err := devices.CheckAgentCompatibility(device.Platform(), expectedVersion, device.Version()); err != nil {
+			return err
+		}
+
+		previous := findInstalledAgent(device)
+		if previous != nil {
+			if previous.Version == expectedVersion {
+				printJson(commands.NewSuccessResponse(agentStatusResponse{
+					Message: fmt.Sprintf("Agent version %s is up to date", previous.Version),
+					Agent:   describeAgent(device, previous),
+				}))
+				return nil
 			}
-		case "android":
-			installErr = installAgentOnAndroid(device)
-		default:
-			return fmt.Errorf("unsupported platform: %s", device.Platform())
+
+			if devices.IsAgentDowngrade(previous.Version, expectedVersion) && !agentForce {
+				return fmt.Errorf("installed agent version %s is newer than %s, use --force to downgrade", previous.Version, expectedVersion)
+			}
+			utils.Verbose("updating agent from version %s to %s", previous.Version, expectedVersion)
 		}
 
-		if installErr != nil {
-			return installErr
+		if err := updateAgent(device, previous, expectedVersion); err != nil {
+			return err
 		}
 
 		agent := findInstalledAgent(device)
@@ -145,17 +202,43 @@ var agentInstallCmd = &cobra.Command{
 			return fmt.Errorf("agent was installed but could not be found")
 		}
 
+		message := fmt.Sprintf("Agent installed with version %s", agent.Version)
+		if previous != nil {
+			message = fmt.Sprintf("Agent updated from version %s to %s", previous.Version, agent.Version)
+		}
+
 		printJson(commands.NewSuccessResponse(agentStatusResponse{
-			Message: "Agent installed successfully",
-			Agent: agentInfo{
-				Version:  agent.Version,
-				BundleID: agent.PackageName,
-			},
+			Message: message,
+			Agent:   describeAgent(device, agent),
 		}))
 		return nil
 	},
 }
 
+// updateAgent installs version over the installed agent. Android refuses
+// older versions over newer ones, so a newer agent is uninstalled first and
+// put back if version fails to install.
+func updateAgent(device devices.ControllableDevice, previous *devices.InstalledAppInfo, version string) error {
+	if previous == nil || device.Platform() != "android" || !devices.IsAgentDowngrade(previous.Version, version) {
+		return installAgent(device, version)
+	}
+
+	if _, err := device.UninstallApp(previous.PackageName); err != nil {
+		return fmt.Errorf("failed to uninstall existing agent: %w", err)
+	}
+
+	err := installAgent(device, version)
+	if err == nil {
+		return nil
+	}
+
+	utils.Verbose("restoring agent version %s after failed install: %v", previous.Version, err)
+	if rollbackErr := installAgent(device, previous.Version); rollbackErr != nil {
+		return fmt.Errorf("%w, and restoring agent version %s failed: %v", err, previous.Version, rollbackErr)
+	}
+	return fmt.Errorf("%w, agent version %s was restored", err, previous.Version)
+}
+
 var agentUninstallCmd = &cobra.Command{
 	Use:   "uninstall",
 	Short: "Uninstall the agent from a device",
@@ -200,7 +283,22 @@ func agentPackageForPlatform(platform string) string {
 	}
 }
 
-func agentVersionForPlatform(platform string) string {
+// agentVersionForPlatform returns the agent version to install, the one
+// pinned in config.json or else the one this mobilecli was released with
+func agentVersionForPlatform(platform string) (string, error) {
+	pins, err := devices.LoadAgentPins()
+	if err != nil {
+		return "", err
+	}
+	if pinned := pins.ForPlatform(platform); pinned != "" {
+		return pinned, nil
+	}
+	return bundledAgentVersion(platform), nil
+}
+
+// bundledAgentVersion returns the agent version this mobilecli was released
+// with, the one agentChecksums are pinned for
+func bundledAgentVersion(platform string) string {
 	switch platform {
 	case "android":
 		return agentVersionAndroid
@@ -211,7 +309,114 @@ func agentVersionForPlatform(platform string) string {
 	}
 }
 
-func downloadAndInstallAgent(device devices.ControllableDevice, agentURL, tmpPath string, transform func(string) (string, error)) error {
+// agentNameForPlatform names the agent for status output
+func agentNameForPlatform(platform string) string {
+	switch platform {
+	case "android":
+		return "DeviceKit for Android"
+	case "ios":
+		return "DeviceKit for iOS (WebDriverAgent runner)"
+	default:
+		return ""
+	}
+}
+
+// agentRepoForPlatform returns the GitHub repository the agent is released from
+func agentRepoForPlatform(platform string) string {
+	switch platform {
+	case "android":
+		return "mobile-next/devicekit-android"
+	case "ios":
+		return "mobile-next/devicekit-ios"
+	default:
+		return ""
+	}
+}
+
+// describeAgent reports the installed agent, which may be nil, against the
+// version this mobilecli installs and the latest release. The latest release
+// is looked up on GitHub and left out when that fails.
+func describeAgent(device devices.ControllableDevice, installed *devices.InstalledAppInfo) agentInfo {
+	platform := device.Platform()
+	info := agentInfo{
+		Name:     agentNameForPlatform(platform),
+		BundleID: agentPackageForPlatform(platform),
+	}
+
+	if expected, err := agentVersionForPlatform(platform); err != nil {
+		utils.Verbose("failed to read the agent pins: %v", err)
+		info.ExpectedVersion = bundledAgentVersion(platform)
+	} else {
+		info.ExpectedVersion = expected
+		info.Pinned = expected != bundledAgentVersion(platform)
+	}
+
+	if installed != nil {
+		info.Installed = true
+		info.Version = installed.Version
+		info.BundleID = installed.PackageName
+		info.UpdateAvailable = installed.Version != info.ExpectedVersion
+	}
+
+	if repo := agentRepoForPlatform(platform); repo != "" {
+		latest, err := utils.GetLatestReleaseVersion(repo)
+		if err != nil {
+			utils.Verbose("failed to look up the latest agent release: %v", err)
+		} else {
+			info.LatestVersion = latest
+		}
+	}
+
+	return info
+}
+
+// installAgent downloads and installs a version of the agent for the device's
+// platform
+func installAgent(device devices.ControllableDevice, version string) error {
+	switch device.Platform() {
+	case "ios":
+		switch device.DeviceType() {
+		case "simulator":
+			return installAgentOnSimulator(device, version)
+		case "real":
+			if agentProvisioningProfile == "" {
+				return fmt.Errorf("--provisioning-profile is required for real iOS devices")
+			}
+			return installAgentOnRealIOS(device, version)
+		default:
+			return fmt.Errorf("unsupported device type: %s", device.DeviceType())
+		}
+	case "android":
+		return installAgentOnAndroid(device, version)
+	default:
+		return fmt.Errorf("unsupported platform: %s", device.Platform())
+	}
+}
+
+// agentChecksum returns the sha256 an agent download must have. Checksums
+// of the version this mobilecli was released with are built in, those of
+// other versions are pinned in agent_checksums of config.json.
+func agentChecksum(platform, version, filename string) (string, error) {
+	if version == bundledAgentVersion(platform) {
+		expectedHash, ok := agentChecksums[filename]
+		if !ok {
+			return "", fmt.Errorf("no pinned checksum for %s", filename)
+		}
+		return expectedHash, nil
+	}
+
+	pins, err := devices.LoadAgentPins()
+	if err != nil {
+		return "", err
+	}
+	expectedHash := pins.ChecksumFor(filename)
+	if expectedHash == "" {
+		return "", fmt.Errorf("no checksum pinned for %s of agent version %s, add its sha256 to agent_checksums in config.json", filename, version)
+	}
+	return expectedHash, nil
+}
+
+func downloadAndInstallAgent(device devices.ControllableDevice, version, agentURL, tmpPath string, transform func(string) (string, error)) error {
 	utils.Verbose("downloading agent from %s", agentURL)
 	if err := utils.DownloadFile(agentURL, tmpPath); err != nil {
 		return fmt.Errorf("failed to download agent: %w", err)
@@ -220,9 +425,9 @@ func downloadAndInstallAgent(device devices.ControllableDevice, agentURL, tmpPat
 	defer func() { _ = os.Remove(tmpPath) }()
 
 	filename := filepath.Base(tmpPath)
-	expectedHash, ok := agentChecksums[filename]
-	if !ok {
-		return fmt.Errorf("no pinned checksum for %s", filename)
+	expectedHash, err := agentChecksum(device.Platform(), version, filename)
+	if err != nil {
+		return err
 	}
 	actualHash, err := utils.SHA256File(tmpPath)
 	if err != nil {
@@ -251,7 +456,7 @@ func downloadAndInstallAgent(device devices.ControllableDevice, agentURL, tmpPat
 	return waitForAgentInstalled(device)
 }
 
-func installAgentOnSimulator(device devices.ControllableDevice) error {
+func installAgentOnSimulator(device devices.ControllableDevice, version string) error {
 	var arch string
 	if runtime.GOARCH == "amd64" {
 		arch = "x86_64"
@@ -260,7 +465,7 @@ func installAgentOnSimulator(device devices.ControllableDevice) error {
 	}
 
 	filename := fmt.Sprintf("devicekit-ios-Sim-%s.zip", arch)
-	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-ios/releases/download/%s/%s", agentVersionIOS, filename)
+	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-ios/releases/download/%s/%s", version, filename)
 
 	tmpDir, err := os.MkdirTemp("", "mobilecli-agent-*")
 	if err != nil {
@@ -268,12 +473,12 @@ func installAgentOnSimulator(device devices.ControllableDevice) error {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
-	return downloadAndInstallAgent(device, agentURL, filepath.Join(tmpDir, filename), nil)
+	return downloadAndInstallAgent(device, version, agentURL, filepath.Join(tmpDir, filename), nil)
 }
 
-func installAgentOnRealIOS(device devices.ControllableDevice) error {
+func installAgentOnRealIOS(device devices.ControllableDevice, version string) error {
 	filename := "devicekit-ios-runner.ipa"
-	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-ios/releases/download/%s/%s", agentVersionIOS, filename)
+	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-ios/releases/download/%s/%s", version, filename)
 
 	tmpDir, err := os.MkdirTemp("", "mobilecli-agent-*")
 	if err != nil {
@@ -281,7 +486,7 @@ func installAgentOnRealIOS(device devices.ControllableDevice) error {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
-	return downloadAndInstallAgent(device, agentURL, filepath.Join(tmpDir, filename), func(downloaded string) (string, error) {
+	return downloadAndInstallAgent(device, version, agentURL, filepath.Join(tmpDir, filename), func(downloaded string) (string, error) {
 		utils.Verbose("re-signing agent with provisioning profile %s", agentProvisioningProfile)
 		resignedPath, err := utils.ResignIPA(downloaded, device.ID(), agentProvisioningProfile, "")
 		if err != nil {
@@ -291,9 +496,9 @@ func installAgentOnRealIOS(device devices.ControllableDevice) error {
 	})
 }
 
-func installAgentOnAndroid(device devices.ControllableDevice) error {
+func installAgentOnAndroid(device devices.ControllableDevice, version string) error {
 	filename := "devicekit.apk"
-	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-android/releases/download/%s/%s", agentVersionAndroid, filename)
+	agentURL := fmt.Sprintf("https://github.com/mobile-next/devicekit-android/releases/download/%s/%s", version, filename)
 
 	tmpDir, err := os.MkdirTemp("", "mobilecli-agent-*")
 	if err != nil {
@@ -301,7 +506,7 @@ func installAgentOnAndroid(device devices.ControllableDevice) error {
 	}
 	defer func() { _ = os.RemoveAll(tmpDir) }()
 
-	return downloadAndInstallAgent(device, agentURL, filepath.Join(tmpDir, filename), nil)
+	return downloadAndInstallAgent(device, version, agentURL, filepath.Join(tmpDir, filename), nil)
 }
 
 func findInstalledAgent(device devices.ControllableDevice) *devices.InstalledAppInfo {
@@ -361,10 +566,14 @@ func init() {
 
 	agentCmd.AddCommand(agentInstallCmd)
 	agentCmd.AddCommand(agentStatusCmd)
+	agentCmd.AddCommand(agentUpdateCmd)
 	agentCmd.AddCommand(agentUninstallCmd)
 
 	agentInstallCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to install the agent on")
 	agentStatusCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check")
+	agentUpdateCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to update the agent on")
+	agentUpdateCmd.Flags().BoolVar(&agentForce, "force", false, "replace a newer installed agent with an older version")
+	agentUpdateCmd.Flags().StringVar(&agentProvisioningProfile, "provisioning-profile", "", "path to a .mobileprovision file to use for re-signing (required for real iOS devices)")
 	agentUninstallCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to uninstall the agent from")
 	agentInstallCmd.Flags().BoolVar(&agentForce, "force", false, "force install even if agent is already installed")
 	agentInstallCmd.Flags().StringVar(&agentProvisioningProfile, "provisioning-profile", "", "path to a .mobileprovision file to use for re-signing (required for real iOS devices)")
@@ -1,10 +1,16 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
 	"github.com/spf13/cobra"
 )
 
@@ -15,10 +21,16 @@ var appsCmd = &cobra.Command{
 }
 
 var appsLaunchCmd = &cobra.Command{
-	Use:   "launch [bundle_id]",
-	Short: "Launch an app on a device",
-	Long:  `Launches an app on the specified device using its bundle ID (e.g., "com.example.app").`,
-	Args:  cobra.ExactArgs(1),
+	Use:     "launch [bundle_id]",
+	Aliases: []string{"start-activity"},
+	Short:   "Launch an app on a device",
+	Long: `Launches an app on the specified device using its bundle ID (e.g., "com.example.app").
+
+On Android, the app's launcher activity is started unless --activity names
+another one, and the response reports the component that was started. With
+--wait, the launch waits for the activity to be drawn and reports how long it
+took (totalTime and waitTime, in milliseconds).`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var locales []string
 		if locale != "" {
@@ -35,9 +47,11 @@ var appsLaunchCmd = &cobra.Command{
 			BundleID: args[0],
 			Locales:  locales,
 			Activity: activity,
+			Wait:     launchWait,
+			User:     appsUser,
 		}
 
-		response := commands.LaunchAppCommand(req)
+		response := runCommand("apps.launch", req, commands.LaunchAppCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -46,18 +60,43 @@ var appsLaunchCmd = &cobra.Command{
 	},
 }
 
+var (
+	terminateAll     bool
+	terminateExclude []string
+)
+
 var appsTerminateCmd = &cobra.Command{
 	Use:   "terminate [bundle_id]",
 	Short: "Terminate an app on a device",
-	Long:  `Terminates an app on the specified device using its bundle ID (e.g., "com.example.app").`,
-	Args:  cobra.ExactArgs(1),
+	Long:  `Terminates an app on the specified device using its bundle ID (e.g., "com.example.app"). With --all, every running launchable app is terminated instead, except mobilecli's agent and the apps given with --exclude.`,
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if terminateAll == (len(args) == 1) {
+			response := commands.NewErrorResponse(fmt.Errorf("specify either a bundle ID or --all"))
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		if terminateAll {
+			req := commands.TerminateAllAppsRequest{
+				DeviceID: deviceId,
+				Exclude:  terminateExclude,
+			}
+
+			response := runCommand("apps.terminateAll", req, commands.TerminateAllAppsCommand)
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		req := commands.AppRequest{
 			DeviceID: deviceId,
 			BundleID: args[0],
 		}
 
-		response := commands.TerminateAppCommand(req)
+		response := runCommand("apps.terminate", req, commands.TerminateAppCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -73,6 +112,7 @@ var appsListCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.ListAppsRequest{
 			DeviceID: deviceId,
+			User:     appsUser,
 		}
 
 		response := commands.ListAppsCommand(req)
@@ -88,23 +128,75 @@ var (
 	forceResign         bool
 	provisioningProfile string
 	signingIdentity     string
+	installURL          string
+	installSHA256       string
+	installGrant        bool
+	installAllowTest    bool
+	installInstant      bool
+	installDowngrade    bool
+	installUser         string
+	installKeystore     string
+	installKeystorePass string
+	installKeyAlias     string
+	installKeyPass      string
+	installProgress     bool
 )
 
 var appsInstallCmd = &cobra.Command{
 	Use:   "install [path]",
 	Short: "Install an app on a device",
-	Long:  `Installs an app on the specified device from the given path (.apk for Android, .zip for iOS Simulator, and .ipa for iOS).`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Installs an app on the specified device from the given path (.apk for Android, .zip for iOS Simulator, and .ipa for iOS). With --progress, upload and install progress is reported on stderr; Android then streams the apk into the package manager instead of using 'adb install'.
+
+Android also installs split apps from a bundletool .apks archive, picking the splits for the device's ABI, and from an .aab bundle, which needs bundletool on PATH or in $BUNDLETOOL. The apks built from an .aab are signed with --keystore, or with the debug keystore.
+
+An older version than the one installed is only installed on Android with --downgrade, and --user installs for another Android user. When the package manager rejects an app, the error's data holds its INSTALL_FAILED_* code, such as INSTALL_FAILED_VERSION_DOWNGRADE, and a hint.
+
+An .ipa signed for another team can be re-signed for a real iOS device on install: --force-resign finds a matching provisioning profile and signing identity in the keychain, and --signing-identity and --provisioning-profile pick them explicitly.
+
+With --url the app is downloaded into a local cache first. Interrupted downloads resume, and with --sha256 the download is verified and an already cached app is installed without downloading it again.
+
+With --remote, a local app is uploaded to the remote server in chunks and installed from there, and a --url is downloaded by the server.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if (len(args) == 0) == (installURL == "") {
+			err := fmt.Errorf("specify either a path or --url")
+			printJson(commands.NewErrorResponse(err))
+			return err
+		}
+
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+
 		req := commands.InstallAppRequest{
 			DeviceID:            deviceId,
-			Path:                args[0],
+			Path:                path,
+			URL:                 installURL,
+			SHA256:              installSHA256,
 			ForceResign:         forceResign,
 			ProvisioningProfile: provisioningProfile,
 			SigningIdentity:     signingIdentity,
+			GrantPermissions:    installGrant,
+			AllowTest:           installAllowTest,
+			Instant:             installInstant,
+			AllowDowngrade:      installDowngrade,
+			User:                installUser,
+			Keystore:            installKeystore,
+			KeystorePassword:    installKeystorePass,
+			KeyAlias:            installKeyAlias,
+			KeyPassword:         installKeyPass,
+		}
+		if installProgress {
+			req.OnProgress = newInstallProgressPrinter()
 		}
 
-		response := commands.InstallAppCommand(req)
+		var response *commands.CommandResponse
+		if serverURL := remoteServerURL(); serverURL != "" {
+			response = installOnRemoteServer(serverURL, req)
+		} else {
+			response = commands.InstallAppCommand(req)
+		}
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -113,6 +205,37 @@ var appsInstallCmd = &cobra.Command{
 	},
 }
 
+// newInstallProgressPrinter returns a progress callback that redraws the
+// upload line in place and prints each later phase on its own line
+func newInstallProgressPrinter() func(devices.InstallProgress) {
+	lastPhase := ""
+	lastPercent := -1
+
+	return func(progress devices.InstallProgress) {
+		if progress.Phase == lastPhase && progress.Percent == lastPercent {
+			return
+		}
+		if progress.Phase != lastPhase && lastPhase != "" {
+			fmt.Fprintln(os.Stderr)
+		}
+		lastPhase, lastPercent = progress.Phase, progress.Percent
+
+		switch {
+		case progress.Phase == devices.InstallPhaseUploading && progress.TotalBytes > 0:
+			const mb = 1024 * 1024
+			fmt.Fprintf(os.Stderr, "\rUploading %3d%% (%.1f / %.1f MB)", progress.Percent, float64(progress.BytesSent)/mb, float64(progress.TotalBytes)/mb)
+		case progress.Phase == devices.InstallPhaseInstalling && progress.Percent > 0:
+			fmt.Fprintf(os.Stderr, "\rInstalling %3d%%", progress.Percent)
+		case progress.Phase == devices.InstallPhaseUploading:
+			fmt.Fprintf(os.Stderr, "Uploading")
+		case progress.Phase == devices.InstallPhaseInstalling:
+			fmt.Fprintf(os.Stderr, "Installing")
+		case progress.Phase == devices.InstallPhaseCompleted:
+			fmt.Fprintf(os.Stderr, "Installed\n")
+		}
+	}
+}
+
 var appsUninstallCmd = &cobra.Command{
 	Use:   "uninstall [bundle_id]",
 	Short: "Uninstall an app from a device",
@@ -122,6 +245,7 @@ var appsUninstallCmd = &cobra.Command{
 		req := commands.UninstallAppRequest{
 			DeviceID:    deviceId,
 			PackageName: args[0],
+			User:        appsUser,
 		}
 
 		response := commands.UninstallAppCommand(req)
@@ -133,6 +257,32 @@ var appsUninstallCmd = &cobra.Command{
 	},
 }
 
+var appsContainerCmd = &cobra.Command{
+	Use:   "container [bundle_id]",
+	Short: "Get the bundle, data and app group container paths of an app",
+	Long: `Reports where an app and its files are on the device, so they can be read and
+written with the fs commands.
+
+On iOS simulators these are the app bundle, the data container and the shared
+containers of the app's groups (simctl get_app_container). On Android they are
+the directory of the app's apks and its data directory, which is only
+accessible for debuggable apps, as dataAccessible reports.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.AppContainersRequest{
+			DeviceID: deviceId,
+			BundleID: args[0],
+		}
+
+		response := commands.AppContainersCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
 var appsPathCmd = &cobra.Command{
 	Use:   "path [bundle_id]",
 	Short: "Get the container path of an app on a device",
@@ -152,6 +302,32 @@ var appsPathCmd = &cobra.Command{
 	},
 }
 
+var appsVerifyCmd = &cobra.Command{
+	Use:   "verify [path]",
+	Short: "Inspect an app file before installing it",
+	Long: `Parses an .apk, .ipa, .zip or .app without installing it and reports its
+bundle ID, version, minimum OS version, architectures and signing.
+
+With --device, also checks that the app can be installed on the device: its
+platform, OS version and architecture, and on real iOS devices its
+provisioning profile. An incompatible app exits non-zero, listing the
+problems, before a slow install attempt fails.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.AppVerifyRequest{
+			DeviceID: deviceId,
+			Path:     args[0],
+		}
+
+		response := commands.AppVerifyCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
 var appsForegroundCmd = &cobra.Command{
 	Use:   "foreground",
 	Short: "Get the currently foreground app on a device",
@@ -161,7 +337,83 @@ var appsForegroundCmd = &cobra.Command{
 			DeviceID: deviceId,
 		}
 
-		response := commands.ForegroundAppCommand(req)
+		response := runCommand("apps.foreground", req, commands.ForegroundAppCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var appsRunningCmd = &cobra.Command{
+	Use:   "running",
+	Short: "List running apps and their process state on a device",
+	Long:  `Lists apps that currently have live processes on the specified device, grouped by app, with each app marked as foreground, background or cached.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.RunningAppsRequest{
+			DeviceID: deviceId,
+		}
+
+		response := runCommand("apps.running", req, commands.RunningAppsCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var appsStateCmd = &cobra.Command{
+	Use:   "state [bundle_id]",
+	Short: "Show whether an app is installed, running or in the foreground",
+	Long:  `Reports the state of an app on the specified device: not-installed, not-running, foreground, background or suspended, along with whether it is installed, running and in the foreground.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.AppStateRequest{
+			DeviceID: deviceId,
+			BundleID: args[0],
+		}
+
+		response := runCommand("apps.state", req, commands.AppStateCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var appsCrashesCmd = &cobra.Command{
+	Use:   "crashes [bundle-id]",
+	Short: "List crash reports of an app",
+	Long:  `Lists crash reports belonging to an app: the crash log buffer on Android, and crash reports named after the app's executable on iOS devices and simulators. With --watch, existing crashes are skipped and each new crash is printed as a JSON event on its own line until interrupted.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.AppCrashesRequest{
+			DeviceID: deviceId,
+			BundleID: args[0],
+		}
+
+		if appsCrashesWatch {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			encoder := json.NewEncoder(os.Stdout)
+			err := commands.WatchAppCrashes(ctx, req, func(crash devices.CrashReport) {
+				_ = encoder.Encode(map[string]any{
+					"event": "crash",
+					"crash": crash,
+				})
+			})
+			if err != nil {
+				printJson(commands.NewErrorResponse(err))
+				return err
+			}
+			return nil
+		}
+
+		response := commands.AppCrashesCommand(req)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -177,20 +429,49 @@ func init() {
 	appsCmd.AddCommand(appsTerminateCmd)
 	appsCmd.AddCommand(appsListCmd)
 	appsCmd.AddCommand(appsInstallCmd)
+	appsCmd.AddCommand(appsVerifyCmd)
 	appsCmd.AddCommand(appsUninstallCmd)
 	appsCmd.AddCommand(appsForegroundCmd)
 	appsCmd.AddCommand(appsPathCmd)
+	appsCmd.AddCommand(appsContainerCmd)
+	appsCmd.AddCommand(appsRunningCmd)
+	appsCmd.AddCommand(appsCrashesCmd)
+	appsCmd.AddCommand(appsStateCmd)
 
 	appsLaunchCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to launch app on")
 	appsLaunchCmd.Flags().StringVar(&locale, "locale", "", "Comma-separated BCP 47 locale tags (e.g., fr-FR,en-GB)")
 	appsLaunchCmd.Flags().StringVar(&activity, "activity", "", "Android activity to launch (e.g. .DebugActivity or com.example/.DebugActivity)")
+	appsLaunchCmd.Flags().BoolVar(&launchWait, "wait", false, "Android: wait for the activity to be drawn and report launch timings (am start -W)")
+	appsLaunchCmd.Flags().StringVar(&appsUser, "user", "", "Launch for this user ID, or current (Android)")
 	appsTerminateCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to terminate app on")
+	appsTerminateCmd.Flags().BoolVar(&terminateAll, "all", false, "Terminate every running app except the agent")
+	appsTerminateCmd.Flags().StringArrayVar(&terminateExclude, "exclude", nil, "Bundle ID to keep running with --all, can be repeated")
 	appsListCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to list apps from")
+	appsListCmd.Flags().StringVar(&appsUser, "user", "", "List the apps of this user ID, or all or current (Android)")
 	appsInstallCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to install app on")
+	appsInstallCmd.Flags().StringVar(&installURL, "url", "", "Download the app from this URL instead of installing a local file")
+	appsInstallCmd.Flags().StringVar(&installSHA256, "sha256", "", "Expected SHA-256 of the app downloaded with --url")
 	appsInstallCmd.Flags().BoolVar(&forceResign, "force-resign", false, "Re-sign the IPA with a local provisioning profile before installing")
-	appsInstallCmd.Flags().StringVar(&provisioningProfile, "provisioning-profile", "", "Path to a .mobileprovision file to use for re-signing")
-	appsInstallCmd.Flags().StringVar(&signingIdentity, "signing-identity", "", "Signing identity name to use for re-signing")
+	appsInstallCmd.Flags().StringVar(&provisioningProfile, "provisioning-profile", "", "Path to a .mobileprovision file to re-sign the IPA with (implies --force-resign)")
+	appsInstallCmd.Flags().StringVar(&signingIdentity, "signing-identity", "", "Signing identity name to re-sign the IPA with (implies --force-resign)")
+	appsInstallCmd.Flags().BoolVarP(&installGrant, "grant-permissions", "g", false, "Grant all runtime permissions on install (Android)")
+	appsInstallCmd.Flags().BoolVarP(&installAllowTest, "test", "t", false, "Allow installing test-only apks (Android)")
+	appsInstallCmd.Flags().BoolVar(&installInstant, "instant", false, "Install as an instant app (Android)")
+	appsInstallCmd.Flags().BoolVarP(&installDowngrade, "downgrade", "d", false, "Allow replacing a newer installed version (Android)")
+	appsInstallCmd.Flags().StringVar(&installUser, "user", "", "Install for this user ID, or all or current (Android)")
+	appsInstallCmd.Flags().StringVar(&installKeystore, "keystore", "", "Keystore to sign the apks built from an .aab with (Android)")
+	appsInstallCmd.Flags().StringVar(&installKeystorePass, "keystore-pass", "", "Password of the --keystore")
+	appsInstallCmd.Flags().StringVar(&installKeyAlias, "key-alias", "", "Alias of the signing key in the --keystore")
+	appsInstallCmd.Flags().StringVar(&installKeyPass, "key-pass", "", "Password of the signing key in the --keystore")
+	appsInstallCmd.Flags().BoolVar(&installProgress, "progress", false, "Report upload and install progress on stderr")
+	appsVerifyCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check the app against")
 	appsUninstallCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to uninstall app from")
+	appsUninstallCmd.Flags().StringVar(&appsUser, "user", "", "Uninstall for this user ID only, or all or current (Android)")
 	appsForegroundCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to get foreground app from")
 	appsPathCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	appsContainerCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	appsRunningCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to list running apps from")
+	appsStateCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to get the app state from")
+	appsCrashesCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to list crashes from")
+	appsCrashesCmd.Flags().BoolVar(&appsCrashesWatch, "watch", false, "Stream new crashes as JSON events until interrupted")
 }
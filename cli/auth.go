@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -23,20 +27,39 @@ const (
 	deviceGrantType    = "urn:ietf:params:oauth:grant-type:device_code"
 
 	authHTTPTimeout = 30 * time.Second
+
+	// authAPIKeyEnvVar holds an API key for 'auth login' to store, which is
+	// never taken on the command line where other users could see it
+	authAPIKeyEnvVar = "MOBILECLI_API_KEY"
 )
 
 var authHTTPClient = &http.Client{Timeout: authHTTPTimeout}
 
 var authProvider string
 
-type deviceCodeRequest struct {
-	ClientID string `json:"client_id"`
+// deviceFlow is an OAuth 2.0 device authorization grant (RFC 8628)
+type deviceFlow struct {
+	clientID string
+	codeURL  string
+	tokenURL string
+
+	// formEncoded posts form bodies as RFC 8628 specifies, rather than the
+	// JSON bodies the mobilenext endpoints take
+	formEncoded bool
 }
 
-type deviceTokenRequest struct {
-	ClientID   string `json:"client_id"`
-	DeviceCode string `json:"device_code"`
-	GrantType  string `json:"grant_type"`
+// post sends the fields of a device flow request as JSON or as a form
+func (f *deviceFlow) post(endpoint string, fields map[string]string) (*http.Response, error) {
+	if !f.formEncoded {
+		reqBody, _ := json.Marshal(fields)
+		return authHTTPClient.Post(endpoint, "application/json", bytes.NewReader(reqBody))
+	}
+
+	form := url.Values{}
+	for key, value := range fields {
+		form.Set(key, value)
+	}
+	return authHTTPClient.PostForm(endpoint, form)
 }
 
 type deviceCodeResponse struct {
@@ -63,19 +86,47 @@ var authCmd = &cobra.Command{
 var authLoginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Log in to your account",
-	Long:  `Authenticates using a device code flow. Displays a URL and code to enter in your browser.`,
+	Long: `Authenticates using a device code flow. Displays a URL and code to enter in your browser.
+
+The provider is read from the auth section of config.json in the mobilecli
+config dir (~/.config/mobilecli/config.json), and defaults to mobilenext:
+
+  mobilenext   log in to mobilenext.ai
+  oidc         log in to a self-hosted OpenID Connect server, configured with
+               issuer_url and client_id (or device_code_url and token_url)
+  apikey       store an API key, read from MOBILECLI_API_KEY or stdin
+
+For example: {"auth": {"provider": "oidc", "issuer_url": "https://sso.example.com/realms/qa", "client_id": "mobilecli"}}`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if authProvider != "mobilenext" {
-			return fmt.Errorf("unsupported provider %q, supported values: \"mobilenext\"", authProvider)
+		config, err := loadAuthConfig()
+		if err != nil {
+			return err
+		}
+
+		if authProvider != "" {
+			config.Provider = authProvider
+		}
+		if authProvider == "" && os.Getenv(authAPIKeyEnvVar) != "" {
+			config.Provider = authProviderAPIKey
 		}
 
-		return runAuthLogin()
+		switch config.Provider {
+		case authProviderMobileNext, authProviderOIDC:
+			flow, err := config.deviceFlow()
+			if err != nil {
+				return err
+			}
+			return runAuthLogin(flow)
+		case authProviderAPIKey:
+			return runAPIKeyLogin(cmd.InOrStdin())
+		default:
+			return fmt.Errorf("unsupported provider %q, supported values: \"mobilenext\", \"oidc\", \"apikey\"", config.Provider)
+		}
 	},
 }
 
-func requestDeviceCode() (*deviceCodeResponse, error) {
-	reqBody, _ := json.Marshal(deviceCodeRequest{ClientID: deviceFlowClientID})
-	resp, err := authHTTPClient.Post(deviceCodeURL, "application/json", bytes.NewReader(reqBody))
+func (f *deviceFlow) requestDeviceCode() (*deviceCodeResponse, error) {
+	resp, err := f.post(f.codeURL, map[string]string{"client_id": f.clientID})
 	if err != nil {
 		return nil, fmt.Errorf("failed to request device code: %w", err)
 	}
@@ -102,7 +153,7 @@ func requestDeviceCode() (*deviceCodeResponse, error) {
 	return &result, nil
 }
 
-func pollForToken(deviceCode string, interval, expiresIn int) (string, error) {
+func (f *deviceFlow) pollForToken(deviceCode string, interval, expiresIn int) (string, error) {
 	pollInterval := time.Duration(interval) * time.Second
 	if pollInterval < 5*time.Second {
 		pollInterval = 5 * time.Second
@@ -112,12 +163,11 @@ func pollForToken(deviceCode string, interval, expiresIn int) (string, error) {
 	for time.Now().Before(deadline) {
 		time.Sleep(pollInterval)
 
-		reqBody, _ := json.Marshal(deviceTokenRequest{
-			ClientID:   deviceFlowClientID,
-			DeviceCode: deviceCode,
-			GrantType:  deviceGrantType,
+		resp, err := f.post(f.tokenURL, map[string]string{
+			"client_id":   f.clientID,
+			"device_code": deviceCode,
+			"grant_type":  deviceGrantType,
 		})
-		resp, err := authHTTPClient.Post(deviceTokenURL, "application/json", bytes.NewReader(reqBody))
 		if err != nil {
 			return "", fmt.Errorf("failed to poll for token: %w", err)
 		}
@@ -154,8 +204,8 @@ func pollForToken(deviceCode string, interval, expiresIn int) (string, error) {
 	return "", fmt.Errorf("device code expired, please try again")
 }
 
-func runAuthLogin() error {
-	codeResp, err := requestDeviceCode()
+func runAuthLogin(flow *deviceFlow) error {
+	codeResp, err := flow.requestDeviceCode()
 	if err != nil {
 		return err
 	}
@@ -164,7 +214,7 @@ func runAuthLogin() error {
 	fmt.Printf("And enter the code: %s\n\n", codeResp.UserCode)
 	fmt.Println("Waiting for authorization...")
 
-	token, err := pollForToken(codeResp.DeviceCode, codeResp.Interval, codeResp.ExpiresIn)
+	token, err := flow.pollForToken(codeResp.DeviceCode, codeResp.Interval, codeResp.ExpiresIn)
 	if err != nil {
 		return err
 	}
@@ -177,6 +227,31 @@ func runAuthLogin() error {
 	return nil
 }
 
+// runAPIKeyLogin stores an API key as the token, reading it from stdin when
+// MOBILECLI_API_KEY isn't set
+func runAPIKeyLogin(stdin io.Reader) error {
+	key := strings.TrimSpace(os.Getenv(authAPIKeyEnvVar))
+	if key == "" {
+		fmt.Fprintln(os.Stderr, "Paste your API key:")
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("failed to read API key: %w", err)
+		}
+		key = strings.TrimSpace(line)
+	}
+
+	if key == "" {
+		return fmt.Errorf("no API key given")
+	}
+
+	if err := storeToken(key); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
+	}
+
+	fmt.Println("API key stored")
+	return nil
+}
+
 var authLogoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Log out of your account",
@@ -213,8 +288,67 @@ var authTokenCmd = &cobra.Command{
 	},
 }
 
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the logged-in identity",
+	Long:  `Shows where the auth token comes from, who it belongs to and when it expires. API keys have no identity or expiry.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token, err := loadToken()
+		if err != nil {
+			if errors.Is(err, keyring.ErrNotFound) {
+				return fmt.Errorf("not logged in, run 'mobilecli auth login' first")
+			}
+			return fmt.Errorf("failed to get auth token: %w", err)
+		}
+
+		fmt.Print(formatAuthStatus(token, tokenSource(), time.Now()))
+		return nil
+	},
+}
+
+// tokenSource describes where loadToken found the token
+func tokenSource() string {
+	switch {
+	case os.Getenv("MOBILECLI_TOKEN") != "":
+		return "MOBILECLI_TOKEN"
+	case insecureStorage:
+		path, _ := credentialsFilePath()
+		return path
+	default:
+		return "keyring"
+	}
+}
+
+// formatAuthStatus describes the token for 'auth status'
+func formatAuthStatus(token, source string, now time.Time) string {
+	var sb strings.Builder
+	identity := parseTokenIdentity(token)
+
+	switch {
+	case identity == nil:
+		fmt.Fprintf(&sb, "Logged in with an API key (from %s)\n", source)
+	case identity.Subject != "":
+		fmt.Fprintf(&sb, "Logged in as %s (from %s)\n", identity.Subject, source)
+	default:
+		fmt.Fprintf(&sb, "Logged in (from %s)\n", source)
+	}
+
+	if identity == nil || identity.Expiry.IsZero() {
+		return sb.String()
+	}
+
+	expiry := identity.Expiry.Local().Format(time.RFC3339)
+	if identity.Expiry.Before(now) {
+		fmt.Fprintf(&sb, "Token expired at %s, run 'mobilecli auth login' again\n", expiry)
+	} else {
+		fmt.Fprintf(&sb, "Token expires at %s (in %s)\n", expiry, identity.Expiry.Sub(now).Round(time.Minute))
+	}
+
+	return sb.String()
+}
+
 func init() {
 	rootCmd.AddCommand(authCmd)
-	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authTokenCmd)
-	authLoginCmd.Flags().StringVar(&authProvider, "provider", "mobilenext", "authentication provider (supported values: \"mobilenext\")")
+	authCmd.AddCommand(authLoginCmd, authLogoutCmd, authTokenCmd, authStatusCmd)
+	authLoginCmd.Flags().StringVar(&authProvider, "provider", "", "authentication provider: \"mobilenext\", \"oidc\" or \"apikey\" (default: from config.json, or \"mobilenext\")")
 }
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// Auth providers 'auth login' can use
+const (
+	// authProviderMobileNext logs in to mobilenext.ai with a device code
+	authProviderMobileNext = "mobilenext"
+
+	// authProviderOIDC logs in with a device code against a self-hosted
+	// OpenID Connect or OAuth 2.0 server
+	authProviderOIDC = "oidc"
+
+	// authProviderAPIKey stores an API key as the token, for self-hosted setups
+	// without an identity provider
+	authProviderAPIKey = "apikey"
+)
+
+// authConfig configures where 'auth login' gets its token from. It is the
+// auth section of config.json, e.g.
+//
+//	{"auth": {"provider": "oidc", "issuer_url": "https://sso.example.com/realms/qa", "client_id": "mobilecli"}}
+//
+// Without one, logins go to mobilenext.ai.
+type authConfig utils.AuthConfig
+
+// loadAuthConfig reads the auth section of config.json, defaulting to
+// mobilenext
+func loadAuthConfig() (*authConfig, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	auth := authConfig(config.Auth)
+	if auth.Provider == "" {
+		auth.Provider = authProviderMobileNext
+	}
+	return &auth, nil
+}
+
+// deviceFlow returns the device code flow for the configured provider
+func (c *authConfig) deviceFlow() (*deviceFlow, error) {
+	switch c.Provider {
+	case authProviderMobileNext:
+		return &deviceFlow{
+			clientID: deviceFlowClientID,
+			codeURL:  deviceCodeURL,
+			tokenURL: deviceTokenURL,
+		}, nil
+
+	case authProviderOIDC:
+		if c.ClientID == "" {
+			return nil, fmt.Errorf("auth.client_id is required in config.json for provider %q", c.Provider)
+		}
+
+		flow := &deviceFlow{
+			clientID:    c.ClientID,
+			codeURL:     c.DeviceCodeURL,
+			tokenURL:    c.TokenURL,
+			formEncoded: true,
+		}
+
+		if flow.codeURL == "" || flow.tokenURL == "" {
+			if c.IssuerURL == "" {
+				return nil, fmt.Errorf("auth.issuer_url, or auth.device_code_url and auth.token_url, are required in config.json for provider %q", c.Provider)
+			}
+
+			discovered, err := discoverOIDCEndpoints(c.IssuerURL)
+			if err != nil {
+				return nil, err
+			}
+
+			if flow.codeURL == "" {
+				flow.codeURL = discovered.DeviceAuthorizationEndpoint
+			}
+			if flow.tokenURL == "" {
+				flow.tokenURL = discovered.TokenEndpoint
+			}
+		}
+
+		if flow.codeURL == "" {
+			return nil, fmt.Errorf("%s does not support the device authorization flow, set auth.device_code_url in config.json", c.IssuerURL)
+		}
+
+		return flow, nil
+
+	default:
+		return nil, fmt.Errorf("provider %q has no device code flow", c.Provider)
+	}
+}
+
+// oidcDiscovery holds the endpoints of an OpenID Connect discovery document
+type oidcDiscovery struct {
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+}
+
+// discoverOIDCEndpoints reads the issuer's OpenID Connect discovery document
+func discoverOIDCEndpoints(issuerURL string) (*oidcDiscovery, error) {
+	url := strings.TrimSuffix(issuerURL, "/") + "/.well-known/openid-configuration"
+	resp, err := authHTTPClient.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint returned %d: %s", resp.StatusCode, string(body))
+	}
+
+	var discovery oidcDiscovery
+	if err := json.Unmarshal(body, &discovery); err != nil {
+		return nil, fmt.Errorf("failed to parse discovery document: %w", err)
+	}
+
+	if discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("discovery document at %s has no token_endpoint", url)
+	}
+
+	return &discovery, nil
+}
+
+// tokenIdentity is what 'auth status' can tell about a token
+type tokenIdentity struct {
+	Subject string
+	Expiry  time.Time // zero when the token doesn't expire or isn't a JWT
+}
+
+// parseTokenIdentity reads the identity and expiry from a JWT's claims,
+// without verifying its signature. Tokens that aren't JWTs, such as API keys,
+// return nil.
+func parseTokenIdentity(token string) *tokenIdentity {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil
+	}
+
+	var claims struct {
+		Email             string  `json:"email"`
+		Username          string  `json:"username"`
+		CognitoUsername   string  `json:"cognito:username"`
+		PreferredUsername string  `json:"preferred_username"`
+		Subject           string  `json:"sub"`
+		Expiry            float64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil
+	}
+
+	identity := &tokenIdentity{}
+	for _, subject := range []string{claims.Email, claims.PreferredUsername, claims.CognitoUsername, claims.Username, claims.Subject} {
+		if subject != "" {
+			identity.Subject = subject
+			break
+		}
+	}
+
+	if claims.Expiry > 0 {
+		identity.Expiry = time.Unix(int64(claims.Expiry), 0)
+	}
+
+	return identity
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// fakeJWT builds an unsigned JWT carrying the given claims
+func fakeJWT(claims string) string {
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+}
+
+func writeAuthConfig(t *testing.T, config string) {
+	t.Helper()
+	path, _ := utils.ConfigFilePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadAuthConfigDefaultsToMobileNext(t *testing.T) {
+	redirectConfigDir(t)
+
+	config, err := loadAuthConfig()
+	if err != nil {
+		t.Fatalf("loadAuthConfig: %v", err)
+	}
+
+	flow, err := config.deviceFlow()
+	if err != nil {
+		t.Fatalf("deviceFlow: %v", err)
+	}
+	if flow.codeURL != deviceCodeURL || flow.tokenURL != deviceTokenURL || flow.formEncoded {
+		t.Fatalf("unexpected default flow: %+v", flow)
+	}
+}
+
+func TestOIDCDeviceFlowDiscoversEndpoints(t *testing.T) {
+	redirectConfigDir(t)
+
+	var srv *httptest.Server
+	var codeForm string
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/realms/qa/.well-known/openid-configuration":
+			w.Write([]byte(`{"device_authorization_endpoint": "` + srv.URL + `/device", "token_endpoint": "` + srv.URL + `/token"}`))
+		case "/device":
+			r.ParseForm()
+			codeForm = r.PostForm.Encode()
+			w.Write([]byte(`{"device_code": "dc-1", "user_code": "ABCD-EFGH", "verification_uri": "https://sso.example.com/device"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	writeAuthConfig(t, `{"auth": {"provider": "oidc", "issuer_url": "`+srv.URL+`/realms/qa/", "client_id": "mobilecli"}}`)
+
+	config, err := loadAuthConfig()
+	if err != nil {
+		t.Fatalf("loadAuthConfig: %v", err)
+	}
+
+	flow, err := config.deviceFlow()
+	if err != nil {
+		t.Fatalf("deviceFlow: %v", err)
+	}
+	if flow.codeURL != srv.URL+"/device" || flow.tokenURL != srv.URL+"/token" || !flow.formEncoded {
+		t.Fatalf("unexpected discovered flow: %+v", flow)
+	}
+
+	code, err := flow.requestDeviceCode()
+	if err != nil {
+		t.Fatalf("requestDeviceCode: %v", err)
+	}
+	if code.DeviceCode != "dc-1" || code.UserCode != "ABCD-EFGH" {
+		t.Fatalf("unexpected device code response: %+v", code)
+	}
+	if codeForm != "client_id=mobilecli" {
+		t.Fatalf("unexpected device code request form: %s", codeForm)
+	}
+}
+
+func TestOIDCDeviceFlowRequiresClientID(t *testing.T) {
+	config := &authConfig{Provider: authProviderOIDC, IssuerURL: "https://sso.example.com"}
+	if _, err := config.deviceFlow(); err == nil {
+		t.Fatal("expected an error without clientId")
+	}
+}
+
+func TestFormatAuthStatus(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	status := formatAuthStatus(fakeJWT(`{"email": "qa@example.com", "sub": "abc", "exp": 1700003600}`), "keyring", now)
+	if !strings.Contains(status, "Logged in as qa@example.com (from keyring)") || !strings.Contains(status, "(in 1h0m0s)") {
+		t.Fatalf("unexpected status: %q", status)
+	}
+
+	status = formatAuthStatus(fakeJWT(`{"cognito:username": "qa", "exp": 1600000000}`), "keyring", now)
+	if !strings.Contains(status, "Logged in as qa") || !strings.Contains(status, "Token expired at") {
+		t.Fatalf("unexpected status for an expired token: %q", status)
+	}
+
+	status = formatAuthStatus("mk_live_0123456789", "MOBILECLI_TOKEN", now)
+	if status != "Logged in with an API key (from MOBILECLI_TOKEN)\n" {
+		t.Fatalf("unexpected status for an API key: %q", status)
+	}
+}
+
+func TestAPIKeyLoginReadsEnv(t *testing.T) {
+	usingInsecureStorage(t)
+	redirectConfigDir(t)
+	clearTokenEnv(t)
+	t.Setenv("MOBILECLI_API_KEY", "mk_live_from_env")
+
+	if err := runAPIKeyLogin(strings.NewReader("")); err != nil {
+		t.Fatalf("runAPIKeyLogin: %v", err)
+	}
+
+	got, err := loadToken()
+	if err != nil {
+		t.Fatalf("loadToken: %v", err)
+	}
+	if got != "mk_live_from_env" {
+		t.Fatalf("loadToken = %q, want the API key from MOBILECLI_API_KEY", got)
+	}
+}
+
+func TestAPIKeyLoginReadsStdin(t *testing.T) {
+	usingInsecureStorage(t)
+	redirectConfigDir(t)
+	clearTokenEnv(t)
+
+	if err := runAPIKeyLogin(strings.NewReader("mk_live_0123456789\n")); err != nil {
+		t.Fatalf("runAPIKeyLogin: %v", err)
+	}
+
+	got, err := loadToken()
+	if err != nil {
+		t.Fatalf("loadToken: %v", err)
+	}
+	if got != "mk_live_0123456789" {
+		t.Fatalf("loadToken = %q, want the API key", got)
+	}
+}
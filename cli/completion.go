@@ -0,0 +1,188 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/spf13/cobra"
+)
+
+// completionCacheTTL is how long the devices listed for shell completion are
+// reused, so repeated <TAB>s don't each wait for adb and simctl
+const completionCacheTTL = 10 * time.Second
+
+// completionDevice is a device as cached for shell completion
+type completionDevice struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+	Type     string `json:"type"`
+}
+
+type completionDeviceCache struct {
+	Time    time.Time          `json:"time"`
+	Devices []completionDevice `json:"devices"`
+}
+
+// completionCachePath returns the file the devices listed for shell
+// completion are cached in
+func completionCachePath() (string, error) {
+	cacheHome, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(cacheHome, "mobilecli", "completion-devices.json"), nil
+}
+
+// completionDevices lists the online devices, from the cache when it is
+// younger than completionCacheTTL
+func completionDevices() []completionDevice {
+	path, pathErr := completionCachePath()
+	if pathErr == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			var cache completionDeviceCache
+			if json.Unmarshal(data, &cache) == nil && time.Since(cache.Time) < completionCacheTTL {
+				return cache.Devices
+			}
+		}
+	}
+
+	infos, err := devices.GetDeviceInfoList(devices.DeviceListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	list := make([]completionDevice, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, completionDevice{
+			ID:       info.ID,
+			Name:     info.Name,
+			Platform: info.Platform,
+			Type:     info.Type,
+		})
+	}
+
+	if pathErr == nil {
+		if data, err := json.Marshal(completionDeviceCache{Time: time.Now(), Devices: list}); err == nil {
+			_ = os.MkdirAll(filepath.Dir(path), 0o755)
+			_ = os.WriteFile(path, data, 0o600)
+		}
+	}
+	return list
+}
+
+// completeDeviceIDs completes --device with the IDs of the connected devices
+// matching --platform and --type, described by their names
+func completeDeviceIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var ids []string
+	for _, device := range completionDevices() {
+		if platform != "" && device.Platform != platform {
+			continue
+		}
+		if deviceType != "" && device.Type != deviceType {
+			continue
+		}
+		if strings.HasPrefix(device.ID, toComplete) {
+			ids = append(ids, device.ID+"\t"+device.Name)
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeBundleIDs completes the bundle ID argument of a command with the
+// apps installed on the selected device
+func completeBundleIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	response := commands.ListAppsCommand(commands.ListAppsRequest{DeviceID: deviceId})
+	apps, ok := response.Data.([]devices.InstalledAppInfo)
+	if response.Status == "error" || !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var bundleIDs []string
+	for _, app := range apps {
+		if !strings.HasPrefix(app.PackageName, toComplete) {
+			continue
+		}
+		if app.AppName != "" {
+			bundleIDs = append(bundleIDs, app.PackageName+"\t"+app.AppName)
+		} else {
+			bundleIDs = append(bundleIDs, app.PackageName)
+		}
+	}
+	return bundleIDs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeButtons completes a button name with the buttons of the selected
+// device's platform, or of every platform when it isn't known
+func completeButtons(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	platforms := []string{"android", "ios"}
+	if platform != "" {
+		platforms = []string{platform}
+	} else if deviceId != "" {
+		for _, device := range completionDevices() {
+			if device.ID == deviceId {
+				platforms = []string{device.Platform}
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, p := range platforms {
+		for _, name := range devices.ButtonsForPlatform(p) {
+			if !seen[name] && strings.HasPrefix(name, strings.ToUpper(toComplete)) {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerCompletions completes --device on every command that has it. It
+// runs once all commands are added, since many define their own --device.
+func registerCompletions(cmd *cobra.Command) {
+	if cmd.Flag("device") != nil {
+		// fails when the flag, inherited from a parent, is already registered
+		_ = cmd.RegisterFlagCompletionFunc("device", completeDeviceIDs)
+	}
+	for _, child := range cmd.Commands() {
+		registerCompletions(child)
+	}
+}
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		appsLaunchCmd,
+		appsTerminateCmd,
+		appsUninstallCmd,
+		appsContainerCmd,
+		appsPathCmd,
+		appsStateCmd,
+		appsCrashesCmd,
+		expectAppInstalledCmd,
+		expectAppForegroundCmd,
+		fsLsCmd,
+		fsMkdirCmd,
+		fsRmCmd,
+	} {
+		cmd.ValidArgsFunction = completeBundleIDs
+	}
+
+	ioButtonCmd.ValidArgsFunction = completeButtons
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func useCompletionFakeDevices(t *testing.T) {
+	t.Setenv(devices.FakeDevicesEnvVar, "2")
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+}
+
+func TestCompleteDeviceIDs(t *testing.T) {
+	useCompletionFakeDevices(t)
+
+	ids, directive := completeDeviceIDs(nil, nil, "")
+	assert.Equal(t, []string{"fake-android-1\tFake Android", "fake-ios-2\tFake iPhone"}, ids)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+
+	ids, _ = completeDeviceIDs(nil, nil, "fake-i")
+	assert.Equal(t, []string{"fake-ios-2\tFake iPhone"}, ids)
+
+	// served from the cache, even once the devices are gone
+	t.Setenv(devices.FakeDevicesEnvVar, "1")
+	ids, _ = completeDeviceIDs(nil, nil, "")
+	assert.Len(t, ids, 2)
+}
+
+func TestCompleteButtons(t *testing.T) {
+	useCompletionFakeDevices(t)
+
+	previous := deviceId
+	deviceId = "fake-ios-2"
+	defer func() { deviceId = previous }()
+
+	names, _ := completeButtons(nil, nil, "vol")
+	assert.Equal(t, []string{"VOLUME_DOWN", "VOLUME_UP"}, names)
+
+	names, _ = completeButtons(nil, nil, "")
+	assert.NotContains(t, names, "BACK")
+	assert.Contains(t, names, "LOCK")
+}
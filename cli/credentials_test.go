@@ -48,6 +48,7 @@ func redirectConfigDir(t *testing.T) {
 func clearTokenEnv(t *testing.T) {
 	t.Helper()
 	t.Setenv("MOBILECLI_TOKEN", "")
+	t.Setenv("MOBILECLI_API_KEY", "")
 }
 
 func TestStoreAndLoadTokenUsesKeyringByDefault(t *testing.T) {
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/daemon"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/server"
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a local daemon that keeps device connections warm",
+	Long: `Runs a long-lived process that serves the JSON-RPC API over a Unix domain socket.
+Device connections, agents and tunnels stay warm between commands, and the CLI
+automatically delegates supported commands to the daemon when it is running.
+Set ` + daemon.DisableEnvVar + `=1 to always run commands in-process.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath, _ := cmd.Flags().GetString("socket")
+		if socketPath == "" {
+			socketPath = daemon.SocketPath()
+		}
+
+		// GetBool cannot fail for defined flags
+		detach, _ := cmd.Flags().GetBool("detach")
+		if detach && !daemon.IsChild() {
+			_, err := daemon.Daemonize()
+			if err != nil {
+				return fmt.Errorf("failed to start daemon: %w", err)
+			}
+
+			fmt.Printf("Daemon spawned, attempting to listen on %s\n", socketPath)
+			return nil
+		}
+
+		daemon.RegisterInvokeMethod()
+		return server.StartUnixServer(socketPath)
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the running daemon",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath, _ := cmd.Flags().GetString("socket")
+		if socketPath == "" {
+			socketPath = daemon.SocketPath()
+		}
+
+		if err := daemon.StopDaemon(socketPath); err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		printJson(commands.NewSuccessResponse(commands.MessageResult{
+			Message: "Daemon shutdown command sent successfully",
+		}))
+		return nil
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the daemon is running",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath, _ := cmd.Flags().GetString("socket")
+		if socketPath == "" {
+			socketPath = daemon.SocketPath()
+		}
+
+		printJson(commands.NewSuccessResponse(map[string]any{
+			"running": daemon.IsRunning(socketPath),
+			"socket":  socketPath,
+		}))
+		return nil
+	},
+}
+
+// daemonSocket returns the socket of a running daemon that commands can be delegated to
+func daemonSocket() (string, bool) {
+	if os.Getenv(daemon.DisableEnvVar) != "" {
+		return "", false
+	}
+
+	// the daemon uses its own adb server and environment, so commands that
+	// select another adb server, key or device, or fake devices, run in-process
+	if adbHost != "" || adbPort != 0 || adbKey != "" || os.Getenv(commands.AndroidSerialEnvVar) != "" || os.Getenv(devices.FakeDevicesEnvVar) != "" {
+		return "", false
+	}
+
+	// tool invocations are only traced, or skipped, in this process
+	if trace || dryRun {
+		return "", false
+	}
+
+	// the daemon logs at its own verbosity, to its own output
+	if verbose > 0 || len(logDebug) > 0 {
+		return "", false
+	}
+
+	// the daemon doesn't see the caller's artifacts directory, and would
+	// write artifacts relative to its own working directory
+	if os.Getenv(commands.ArtifactsDirEnvVar) != "" {
+		return "", false
+	}
+
+	socketPath := daemon.SocketPath()
+	if !daemon.IsRunning(socketPath) {
+		return "", false
+	}
+
+	return socketPath, true
+}
+
+// runCommand runs a command on the remote server given with --remote, or in a
+// running daemon if there is one, otherwise in-process. Commands are recorded
+// into the session being recorded, if any.
+func runCommand[T any](name string, req T, fn func(T) *commands.CommandResponse) *commands.CommandResponse {
+	return commands.RecordSessionCommand(name, req, func() *commands.CommandResponse {
+		return dispatchCommand(name, req, fn)
+	})
+}
+
+// delegationSocket returns the socket of the daemon a command is delegated
+// to, when it is
+func delegationSocket(name string) (string, bool) {
+	// the daemon auto-selects without the caller's --platform, --type and
+	// --label, and runs the tools it resolved rather than the caller's
+	socketPath, ok := daemonSocket()
+	if !ok || !daemon.CanDelegate(name) || commands.AutoSelectNarrowed() || devices.ToolPathsOverridden() {
+		return "", false
+	}
+	return socketPath, true
+}
+
+// dispatchCommand runs a command where runCommand decided to
+func dispatchCommand[T any](name string, req T, fn func(T) *commands.CommandResponse) *commands.CommandResponse {
+	if serverURL := remoteServerURL(); serverURL != "" {
+		return runRemoteCommand(serverURL, name, req)
+	}
+
+	socketPath, ok := delegationSocket(name)
+	if !ok {
+		return fn(req)
+	}
+
+	utils.Verbose("Delegating '%s' to daemon at %s", name, socketPath)
+	response, err := daemon.Invoke(socketPath, name, req)
+	if err != nil {
+		return commands.NewErrorResponse(fmt.Errorf("daemon: %v", err))
+	}
+
+	return response
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+
+	daemonCmd.PersistentFlags().String("socket", "", "Path of the daemon's Unix socket (default: $"+daemon.SocketEnvVar+" or a per-user socket in the temp directory)")
+	daemonCmd.Flags().BoolP("detach", "d", false, "Run daemon in the background")
+}
@@ -2,8 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 
 	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
 	"github.com/spf13/cobra"
 )
 
@@ -35,9 +38,827 @@ var deviceRebootCmd = &cobra.Command{
 var deviceInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Get device info",
-	Long:  `Get detailed information about a connected device, such as OS, version, and screen size.`,
+	Long:  `Get detailed information about a connected device, such as OS, version, and screen size. Real iOS devices also report their activation and supervision state, installed configuration profiles, free storage and battery health under diagnostics.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		response := commands.InfoCommand(deviceId)
+		response := runCommand("info", deviceId, commands.InfoCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var devicePropsCmd = &cobra.Command{
+	Use:   "props",
+	Short: "Get device properties",
+	Long:  `Returns device properties such as manufacturer, model, ABI, API level, build fingerprint, serial, screen density, locale and timezone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.PropertiesRequest{
+			DeviceID: deviceId,
+		}
+
+		response := runCommand("props", req, commands.PropertiesCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceBugreportCmd = &cobra.Command{
+	Use:   "bugreport",
+	Short: "Collect a diagnostics archive from a device",
+	Long:  `Collects platform diagnostics into a single zip archive: adb bugreport on Android, crash reports on iOS devices, and CoreSimulator logs and crash reports on simulators, along with device properties and mobilecli's verbose log of the collection.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.BugReportRequest{
+			DeviceID: deviceId,
+			Output:   bugreportOutput,
+		}
+
+		response := commands.BugReportCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceLockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Lock the device screen",
+	Long:  `Locks the device screen. On Android this turns the screen off.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.LockRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.LockCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Unlock the device screen",
+	Long:  `Wakes the device and dismisses the lock screen. Lock screens protected by a PIN, pattern or password can't be dismissed on Android.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.LockRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.UnlockCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceWakeCmd = &cobra.Command{
+	Use:   "wake",
+	Short: "Turn the device screen on",
+	Long:  `Turns the device screen on without dismissing the lock screen. Unlike pressing the POWER button, it leaves a screen that is already on as it is.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.LockRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.WakeCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceScreenCmd = &cobra.Command{
+	Use:   "screen",
+	Short: "Control the device screen",
+	Long:  `Commands for putting the device screen into a known state.`,
+}
+
+var deviceScreenOffCmd = &cobra.Command{
+	Use:   "off",
+	Short: "Turn the device screen off",
+	Long:  `Turns the device screen off. Unlike pressing the POWER button, it leaves a screen that is already off as it is.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.LockRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.ScreenOffCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceStayAwakeCmd = &cobra.Command{
+	Use:   "stay-awake [on|off]",
+	Short: "Keep the screen on while plugged in",
+	Long:  `Keeps the device screen on while it is plugged in, so long test runs aren't interrupted by the device sleeping. 'off' restores the setting in place before stay-awake was turned on. Simulators never sleep, so this does nothing on them. Real iOS devices can't be kept awake this way and fail with an error; set their Auto-Lock to Never in Settings instead.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "on" && args[0] != "off" {
+			return fmt.Errorf("invalid value '%s', must be 'on' or 'off'", args[0])
+		}
+
+		req := commands.StayAwakeRequest{
+			DeviceID: deviceId,
+			Enabled:  args[0] == "on",
+		}
+
+		response := commands.StayAwakeCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceWindowCmd = &cobra.Command{
+	Use:   "window [show|hide]",
+	Short: "Show or hide the window of an emulator or simulator",
+	Long:  `Shows or hides the window of a running Android emulator, through the emulator console, or brings Simulator.app to the front showing an iOS simulator, and hides it again. Operators can look at a headless CI device on demand, without restarting it.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "show" && args[0] != "hide" {
+			return fmt.Errorf("invalid value '%s', must be 'show' or 'hide'", args[0])
+		}
+
+		req := commands.WindowRequest{
+			DeviceID: deviceId,
+			Visible:  args[0] == "show",
+		}
+
+		response := commands.WindowCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var alertCmd = &cobra.Command{
+	Use:   "alert",
+	Short: "Read and answer the alert on screen",
+	Long:  `Reads, accepts or dismisses the alert on screen on iOS, such as a permission dialog, without tapping coordinates.`,
+}
+
+var alertTextCmd = &cobra.Command{
+	Use:   "text",
+	Short: "Get the text and buttons of the alert on screen",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := commands.AlertTextCommand(commands.AlertRequest{DeviceID: deviceId})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var alertAcceptCmd = &cobra.Command{
+	Use:   "accept",
+	Short: "Accept the alert on screen",
+	Long:  `Accepts the alert on screen by tapping its default button, or the button given with --button, e.g. "Allow While Using App".`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := commands.AcceptAlertCommand(commands.AlertRequest{DeviceID: deviceId, Button: alertButton})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var alertDismissCmd = &cobra.Command{
+	Use:   "dismiss",
+	Short: "Dismiss the alert on screen",
+	Long:  `Dismisses the alert on screen by tapping its cancel button, or the button given with --button, e.g. "Don't Allow".`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := commands.DismissAlertCommand(commands.AlertRequest{DeviceID: deviceId, Button: alertButton})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceOpenSettingsCmd = &cobra.Command{
+	Use:   "open-settings",
+	Short: "Open the Settings app",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := commands.OpenSettingsCommand(commands.SystemUIRequest{DeviceID: deviceId})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceControlCenterCmd = &cobra.Command{
+	Use:   "control-center [open|close]",
+	Short: "Open or close Control Center",
+	Long:  `Opens or closes Control Center on iOS by swiping, or the quick settings panel on Android.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "open" && args[0] != "close" {
+			return fmt.Errorf("invalid value '%s', must be 'open' or 'close'", args[0])
+		}
+
+		response := commands.ControlCenterCommand(commands.SystemUIRequest{DeviceID: deviceId}, args[0] == "open")
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var labelCmd = &cobra.Command{
+	Use:   "label",
+	Short: "Device label commands",
+	Long:  `Commands for attaching key=value labels to devices, e.g. team=payments or pool=smoke. Labels are stored on this host, or on the server with --remote, and can be used to filter 'mobilecli devices --label' and restrict auto-selection with --label.`,
+}
+
+var labelSetCmd = &cobra.Command{
+	Use:   "set [key=value...]",
+	Short: "Set device labels",
+	Long:  `Adds labels to a device, overwriting labels with the same key.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		labels, err := devices.ParseLabels(args)
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return err
+		}
+
+		req := commands.DeviceLabelsRequest{
+			DeviceID: deviceId,
+			Set:      labels,
+		}
+
+		response := runCommand("device.labels", req, commands.DeviceLabelsCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var labelUnsetCmd = &cobra.Command{
+	Use:   "unset [key...]",
+	Short: "Remove device labels",
+	Long:  `Removes labels from a device by key.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.DeviceLabelsRequest{
+			DeviceID: deviceId,
+			Unset:    args,
+		}
+
+		response := runCommand("device.labels", req, commands.DeviceLabelsCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var labelListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List device labels",
+	Long:  `Lists the labels of a device.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.DeviceLabelsRequest{
+			DeviceID: deviceId,
+		}
+
+		response := runCommand("device.labels", req, commands.DeviceLabelsCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var mediaCmd = &cobra.Command{
+	Use:   "media",
+	Short: "Seed the device with photos and videos",
+	Long:  `Commands for adding photos and videos to the device's photo library, e.g. to test photo-picker flows.`,
+}
+
+var mediaAddCmd = &cobra.Command{
+	Use:   "add [file...]",
+	Short: "Add photos and videos to the photo library",
+	Long:  `Adds photos (.jpg, .jpeg, .png, .gif, .heic, .webp) and videos (.mp4, .mov, .m4v, .3gp) to the device's photo library. On iOS simulators they are added with simctl, on Android they are pushed to DCIM and indexed by the media scanner.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.MediaAddRequest{
+			DeviceID: deviceId,
+			Paths:    args,
+		}
+
+		response := commands.MediaAddCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Seed the device with contacts",
+	Long:  `Commands for adding contacts to the device's address book.`,
+}
+
+var contactsAddCmd = &cobra.Command{
+	Use:   "add [file.vcf]",
+	Short: "Import contacts from a vCard file",
+	Long:  `Imports the contacts of a vCard (.vcf) file into the device's address book. On Android the file is opened with the contacts app, which may ask to confirm the import.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.ContactsAddRequest{
+			DeviceID: deviceId,
+			Path:     args[0],
+		}
+
+		response := commands.ContactsAddCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var timeCmd = &cobra.Command{
+	Use:   "time",
+	Short: "Change the device time",
+	Long:  `Commands for changing the time of emulators and simulators, so date-dependent flows such as trials and subscriptions can be tested.`,
+}
+
+var timeSetCmd = &cobra.Command{
+	Use:   "set [time]",
+	Short: "Set the device time",
+	Long: `Sets the device time, e.g. "2025-01-01T09:41:00" in the host's time zone or "2025-01-01T09:41:00Z" in UTC.
+On Android this turns off automatic time and sets the device clock, which requires root ('adb root' works on emulator images without Google Play).
+iOS simulators run on the host's clock, so only the time shown in the status bar changes.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.TimeSetRequest{
+			DeviceID: deviceId,
+			Time:     args[0],
+		}
+
+		response := commands.TimeSetCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var timeSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Go back to the host's time",
+	Long:  `Sets the device clock back to the host's time and turns automatic time back on (Android), or clears the status bar time override (iOS simulators).`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.TimeSyncRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.TimeSyncCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var sensorCmd = &cobra.Command{
+	Use:   "sensor",
+	Short: "Simulate sensor readings",
+	Long:  `Commands for simulating sensor readings on Android emulators, and shaking emulators and iOS simulators, to test features such as shake-to-report or fitness tracking.`,
+}
+
+var sensorSetCmd = &cobra.Command{
+	Use:   "set [sensor] [values]",
+	Short: "Set a sensor",
+	Long: `Holds an emulator sensor at comma-separated values until it is set again, e.g. "acceleration 0,9.8,0".
+Sensors: acceleration, gyroscope, magnetic-field, orientation, temperature, proximity, light, pressure, humidity, heart-rate and their -uncalibrated variants.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		values, err := commands.ParseSensorValues(args[1])
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return err
+		}
+
+		req := commands.SensorSetRequest{
+			DeviceID: deviceId,
+			Sensor:   args[0],
+			Values:   values,
+		}
+
+		response := commands.SensorSetCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var sensorShakeCmd = &cobra.Command{
+	Use:   "shake",
+	Short: "Shake the device",
+	Long:  `Shakes an Android emulator with a burst of accelerations, or an iOS simulator with the Simulator's shake gesture.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SensorShakeRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.SensorShakeCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var sensorPlayCmd = &cobra.Command{
+	Use:   "play [recording]",
+	Short: "Play back a sensor recording",
+	Long: `Plays back a recording with one sample per line, "<milliseconds>,<sensor>,<values>", e.g.:
+
+  0,acceleration,0,9.8,0
+  500,acceleration,3.2,9.1,0.4
+
+Lines starting with # are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SensorPlayRequest{
+			DeviceID: deviceId,
+			Path:     args[0],
+		}
+
+		response := commands.SensorPlayCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var callCmd = &cobra.Command{
+	Use:   "call",
+	Short: "Simulate phone calls",
+	Long:  `Commands for simulating incoming phone calls on Android emulators, to test call-interruption flows.`,
+}
+
+var callIncomingCmd = &cobra.Command{
+	Use:   "incoming",
+	Short: "Simulate an incoming call",
+	Long:  `Makes the emulator ring with a call from --number, until it is answered on the device or ended with 'device call end'.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.CallRequest{
+			DeviceID: deviceId,
+			Number:   phoneNumber,
+		}
+
+		response := commands.CallIncomingCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var callEndCmd = &cobra.Command{
+	Use:   "end",
+	Short: "Hang up a simulated call",
+	Long:  `Hangs up the call from --number, whether it is ringing or was answered.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.CallRequest{
+			DeviceID: deviceId,
+			Number:   phoneNumber,
+		}
+
+		response := commands.CallEndCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var smsCmd = &cobra.Command{
+	Use:   "sms",
+	Short: "Simulate text messages",
+	Long:  `Commands for simulating incoming text messages on Android emulators, to test flows such as one-time codes.`,
+}
+
+var smsSendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Deliver a text message to the device",
+	Long:  `Delivers a text message from --number with --text to the emulator, as if it came from the network.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SMSRequest{
+			DeviceID: deviceId,
+			Number:   phoneNumber,
+			Text:     smsText,
+		}
+
+		response := commands.SMSSendCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var storageCmd = &cobra.Command{
+	Use:   "storage",
+	Short: "Inspect and fill device storage",
+	Long:  `Commands for reporting free space and simulating low storage, by filling the device with a filler file and removing it again.`,
+}
+
+var storageInfoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "Show total, used and free space",
+	Long:  `Reports the space of the data and shared storage volumes on Android, and of the data volume on iOS simulators, which is the host's disk.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.StorageRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.StorageInfoCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var storageFillCmd = &cobra.Command{
+	Use:   "fill",
+	Short: "Fill storage, leaving little space free",
+	Long: `Writes a filler file so only --leave bytes remain free, to test low storage behavior. Remove it with 'device storage free'.
+iOS simulators can't be filled, as their storage is the host's disk.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.StorageFillRequest{
+			DeviceID:  deviceId,
+			LeaveFree: storageLeaveFree,
+		}
+
+		response := commands.StorageFillCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var storageFreeCmd = &cobra.Command{
+	Use:   "free",
+	Short: "Remove the storage filler",
+	Long:  `Removes the filler file written by 'device storage fill'.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.StorageRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.StorageFreeCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "Manage users and work profiles",
+	Long:  `Commands for listing, creating and removing the users of an Android device, including managed (work) profiles. Apps can then be installed, launched, listed and uninstalled for a user with 'apps ... --user'.`,
+}
+
+var usersListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List users and profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.UsersRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.ListUsersCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var usersCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Short: "Create a user or work profile",
+	Long: `Creates a secondary user, a guest with --guest, or a managed (work) profile of
+a user with --profile-of. A work profile is started right away so apps can be
+installed into it.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.CreateUserRequest{
+			DeviceID: deviceId,
+			Name:     args[0],
+			Guest:    userGuest,
+		}
+		if cmd.Flags().Changed("profile-of") {
+			req.ProfileOf = &userProfileOf
+		}
+
+		response := commands.CreateUserCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var usersRemoveCmd = &cobra.Command{
+	Use:   "remove [id]",
+	Short: "Remove a user or work profile",
+	Long:  `Removes a user or work profile with all of its apps and data. The system user 0 can't be removed.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		userID, err := strconv.Atoi(args[0])
+		if err != nil {
+			response := commands.NewErrorResponse(fmt.Errorf("invalid user ID: %s", args[0]))
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		req := commands.RemoveUserRequest{
+			DeviceID: deviceId,
+			UserID:   userID,
+		}
+
+		response := commands.RemoveUserCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceRootCmd = &cobra.Command{
+	Use:   "root",
+	Short: "Check or enable root access",
+	Long:  `Commands for checking whether an Android device gives root access and enabling it, which some diagnostics such as reading app data need.`,
+}
+
+var deviceRootStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether the device is rooted",
+	Long:  `Reports the build type, whether 'adb root' is available and whether adbd runs as root or su is installed.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.RootRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.RootStatusCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var deviceRootEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Restart adbd as root and remount system",
+	Long: `Runs 'adb root' and 'adb remount'. Only userdebug and eng builds, such as emulator images without Google Play, allow it.
+The first remount may disable verity, in which case the device must be rebooted and the command run again.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.RootRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.RootEnableCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var notificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Notification commands",
+	Long:  `Commands for inspecting, tapping and clearing the notifications posted on a device.`,
+}
+
+var notificationsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List posted notifications",
+	Long:  `Lists the notifications currently posted on the device, with their package, title, text and actions. With --clear, notifications are dismissed after they have been listed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.NotificationsRequest{
+			DeviceID: deviceId,
+			Clear:    notificationsClear,
+		}
+
+		response := commands.NotificationsCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var notificationsClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Clear all notifications",
+	Long:  `Dismisses all clearable notifications on the device. On Android releases newer than 14, notifications are snoozed for a day instead, as they can't be cancelled all at once there.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.NotificationClearRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.NotificationClearCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var notificationsTapCmd = &cobra.Command{
+	Use:   "tap [text]",
+	Short: "Tap a notification",
+	Long:  `Opens the notification shade and taps the first notification whose title or text contains the given text.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.NotificationTapRequest{
+			DeviceID: deviceId,
+			Text:     args[0],
+		}
+
+		response := commands.NotificationTapCommand(req)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -55,13 +876,13 @@ var orientationCmd = &cobra.Command{
 var orientationGetCmd = &cobra.Command{
 	Use:   "get",
 	Short: "Get current device orientation",
-	Long:  `Get the current orientation of the device (portrait or landscape).`,
+	Long:  `Get the current orientation of the device (portrait, portraitUpsideDown, landscapeLeft or landscapeRight) and its rotation in quarter turns counter-clockwise from portrait.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.OrientationGetRequest{
 			DeviceID: deviceId,
 		}
 
-		response := commands.OrientationGetCommand(req)
+		response := runCommand("orientation.get", req, commands.OrientationGetCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -74,7 +895,7 @@ var orientationGetCmd = &cobra.Command{
 var orientationSetCmd = &cobra.Command{
 	Use:   "set [orientation]",
 	Short: "Set device orientation",
-	Long:  `Set the device orientation to portrait or landscape.`,
+	Long:  `Set the device orientation to portrait, portraitUpsideDown, landscapeLeft or landscapeRight. landscape is accepted for landscapeLeft.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.OrientationSetRequest{
@@ -82,7 +903,7 @@ var orientationSetCmd = &cobra.Command{
 			Orientation: args[0],
 		}
 
-		response := commands.OrientationSetCommand(req)
+		response := runCommand("orientation.set", req, commands.OrientationSetCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -95,10 +916,14 @@ var orientationSetCmd = &cobra.Command{
 var deviceBootCmd = &cobra.Command{
 	Use:   "boot",
 	Short: "Boot a simulator or emulator",
-	Long:  `Boots a specified offline simulator or emulator.`,
+	Long:  `Boots a specified offline simulator or emulator and waits until it has finished booting. Progress (booting, adb-detected, boot-completed) is reported on stderr.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.BootRequest{
 			DeviceID: deviceId,
+			Timeout:  bootTimeout,
+			OnProgress: func(stage string) {
+				fmt.Fprintf(os.Stderr, "%s\n", stage)
+			},
 		}
 
 		response := commands.BootCommand(req)
@@ -111,6 +936,30 @@ var deviceBootCmd = &cobra.Command{
 	},
 }
 
+var deviceAuthorizeCmd = &cobra.Command{
+	Use:   "authorize",
+	Short: "Check and wait for an Android device to allow USB debugging",
+	Long: `Checks whether an Android device allows USB debugging from this computer. An unauthorized device is listed by 'devices' with the state "unauthorized" and can't be used until its 'Allow USB debugging?' prompt is accepted.
+
+While it's unauthorized, the steps to authorize it are reported on stderr, with the fingerprint of the key the prompt should show, and the command waits up to --wait seconds for the prompt to be accepted.
+
+With --adb-key, the adb server is restarted to authenticate with that key, so a device that already trusts it, such as a preauthorized CI image, comes online without a prompt.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.AuthorizeRequest{
+			DeviceID: deviceId,
+			Wait:     authorizeWait,
+		}
+
+		response := commands.AuthorizeCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		return nil
+	},
+}
+
 var deviceShutdownCmd = &cobra.Command{
 	Use:   "shutdown",
 	Short: "Shutdown a simulator or emulator",
@@ -167,25 +1016,136 @@ func init() {
 	// add device subcommands
 	deviceCmd.AddCommand(deviceRebootCmd)
 	deviceCmd.AddCommand(deviceInfoCmd)
+	deviceCmd.AddCommand(devicePropsCmd)
+	deviceCmd.AddCommand(deviceBugreportCmd)
 	deviceCmd.AddCommand(deviceBootCmd)
+	deviceCmd.AddCommand(deviceAuthorizeCmd)
 	deviceCmd.AddCommand(deviceShutdownCmd)
+	deviceCmd.AddCommand(deviceLockCmd)
+	deviceCmd.AddCommand(deviceUnlockCmd)
+	deviceCmd.AddCommand(deviceWakeCmd)
+	deviceCmd.AddCommand(deviceScreenCmd)
+	deviceScreenCmd.AddCommand(deviceScreenOffCmd)
+	deviceCmd.AddCommand(deviceStayAwakeCmd)
+	deviceCmd.AddCommand(deviceWindowCmd)
+	deviceCmd.AddCommand(notificationsCmd)
 	deviceCmd.AddCommand(orientationCmd)
 	deviceCmd.AddCommand(settingsCmd)
+	deviceCmd.AddCommand(labelCmd)
+	deviceCmd.AddCommand(mediaCmd)
+	deviceCmd.AddCommand(contactsCmd)
+	deviceCmd.AddCommand(timeCmd)
+	deviceCmd.AddCommand(deviceRootCmd)
+	deviceCmd.AddCommand(sensorCmd)
+	deviceCmd.AddCommand(callCmd)
+	deviceCmd.AddCommand(smsCmd)
+	deviceCmd.AddCommand(storageCmd)
+	deviceCmd.AddCommand(usersCmd)
+	deviceCmd.AddCommand(alertCmd)
+	deviceCmd.AddCommand(deviceOpenSettingsCmd)
+	deviceCmd.AddCommand(deviceControlCenterCmd)
+
+	// add alert subcommands
+	alertCmd.AddCommand(alertTextCmd)
+	alertCmd.AddCommand(alertAcceptCmd)
+	alertCmd.AddCommand(alertDismissCmd)
+
+	// add storage subcommands
+	storageCmd.AddCommand(storageInfoCmd)
+	storageCmd.AddCommand(storageFillCmd)
+	storageCmd.AddCommand(storageFreeCmd)
+
+	// add users subcommands
+	usersCmd.AddCommand(usersListCmd)
+	usersCmd.AddCommand(usersCreateCmd)
+	usersCmd.AddCommand(usersRemoveCmd)
+
+	// add call and sms subcommands
+	callCmd.AddCommand(callIncomingCmd)
+	callCmd.AddCommand(callEndCmd)
+	smsCmd.AddCommand(smsSendCmd)
+
+	// add sensor subcommands
+	sensorCmd.AddCommand(sensorSetCmd)
+	sensorCmd.AddCommand(sensorShakeCmd)
+	sensorCmd.AddCommand(sensorPlayCmd)
+
+	// add root subcommands
+	deviceRootCmd.AddCommand(deviceRootStatusCmd)
+	deviceRootCmd.AddCommand(deviceRootEnableCmd)
+
+	// add time subcommands
+	timeCmd.AddCommand(timeSetCmd)
+	timeCmd.AddCommand(timeSyncCmd)
+
+	// add media and contacts subcommands
+	mediaCmd.AddCommand(mediaAddCmd)
+	contactsCmd.AddCommand(contactsAddCmd)
 
 	// add orientation subcommands
 	orientationCmd.AddCommand(orientationGetCmd)
 	orientationCmd.AddCommand(orientationSetCmd)
 
+	// add notifications subcommands
+	notificationsCmd.AddCommand(notificationsListCmd)
+	notificationsCmd.AddCommand(notificationsClearCmd)
+	notificationsCmd.AddCommand(notificationsTapCmd)
+
 	// add settings subcommands
 	settingsCmd.AddCommand(settingsApplyCmd)
 
+	// add label subcommands
+	labelCmd.AddCommand(labelSetCmd)
+	labelCmd.AddCommand(labelUnsetCmd)
+	labelCmd.AddCommand(labelListCmd)
+
 	// device command flags
 	deviceRebootCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to reboot")
 	deviceInfoCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to get info from")
+	devicePropsCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to get properties from")
+	deviceBugreportCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to collect diagnostics from")
+	deviceBugreportCmd.Flags().StringVarP(&bugreportOutput, "output", "o", "", "Output zip file path (default mobilecli-bugreport-<device>-<time>.zip, or a timestamped file in --artifacts-dir)")
 	deviceBootCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to boot")
+	deviceBootCmd.Flags().IntVar(&bootTimeout, "boot-timeout", 120, "seconds to wait for the device to finish booting")
+	deviceAuthorizeCmd.Flags().StringVar(&deviceId, "device", "", "ID or adb serial of the device to authorize")
+	deviceAuthorizeCmd.Flags().IntVar(&authorizeWait, "wait", 60, "seconds to wait for USB debugging to be allowed on the device, 0 to only check")
 	deviceShutdownCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to shutdown")
+	deviceLockCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to lock")
+	deviceUnlockCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to unlock")
+	deviceWakeCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to wake")
+	deviceScreenCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to turn the screen off on")
+	deviceStayAwakeCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to keep awake")
+	deviceWindowCmd.Flags().StringVar(&deviceId, "device", "", "ID of the emulator or simulator to show or hide")
+	notificationsListCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to list notifications from")
+	notificationsListCmd.Flags().BoolVar(&notificationsClear, "clear", false, "clear notifications after listing them")
+	notificationsClearCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to clear notifications on")
+	notificationsTapCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to tap the notification on")
 	orientationGetCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to get orientation from")
 	orientationSetCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to set orientation on")
 	settingsApplyCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to apply settings to")
+	labelCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to label")
+	mediaAddCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to add media to")
+	contactsAddCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to add contacts to")
+	timeCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to change the time of")
+	deviceRootCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to check or enable root on")
+	sensorCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to simulate sensors on")
+	callCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to call")
+	callCmd.PersistentFlags().StringVar(&phoneNumber, "number", "", "phone number the call is from")
+	_ = callCmd.MarkPersistentFlagRequired("number")
+	smsSendCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to send the text message to")
+	smsSendCmd.Flags().StringVar(&phoneNumber, "number", "", "phone number the text message is from")
+	smsSendCmd.Flags().StringVar(&smsText, "text", "", "text of the message")
+	_ = smsSendCmd.MarkFlagRequired("number")
+	_ = smsSendCmd.MarkFlagRequired("text")
+	storageCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to manage the storage of")
+	usersCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to manage the users of")
+	usersCreateCmd.Flags().IntVar(&userProfileOf, "profile-of", 0, "create a managed (work) profile of this user ID")
+	usersCreateCmd.Flags().BoolVar(&userGuest, "guest", false, "create a guest user")
+	storageFillCmd.Flags().StringVar(&storageLeaveFree, "leave", commands.DefaultStorageLeaveFree, "space to leave free, e.g. 500M or 1G")
+	alertCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device to handle the alert on")
+	alertAcceptCmd.Flags().StringVar(&alertButton, "button", "", "label of the button to tap instead of the default one")
+	alertDismissCmd.Flags().StringVar(&alertButton, "button", "", "label of the button to tap instead of the cancel one")
+	deviceOpenSettingsCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to open Settings on")
+	deviceControlCenterCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to open or close control center on")
 	settingsApplyCmd.Flags().StringVar(&settingsAnimations, "animations", "", "Toggle system animations: 'on' or 'off'")
 }
@@ -1,7 +1,12 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/mobile-next/mobilecli/commands"
 	"github.com/mobile-next/mobilecli/devices"
@@ -10,35 +15,226 @@ import (
 
 var (
 	includeOfflineDevices bool
+	includeUnbooted       bool
+	usbOnly               bool
+	networkOnly           bool
+	checkAgents           bool
+	devicesOutput         string
+	backendTimeout        time.Duration
+	partitionTotal        int
+	partitionIndex        int
 )
 
 var devicesCmd = &cobra.Command{
 	Use:   "devices",
 	Short: "List connected devices",
-	Long:  `List all connected iOS and Android devices, both real devices and simulators/emulators.`,
+	Long: `List all connected iOS and Android devices, both real devices and simulators/emulators.
+
+adb, go-ios and simctl are queried at once. One that hasn't answered within
+--backend-timeout is left out, so a half-connected phone can't hold up the
+whole listing, and is reported in the response's warnings.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		opts := devices.DeviceListOptions{
-			IncludeOffline: includeOfflineDevices,
-			Platform:       platform,
-			DeviceType:     deviceType,
+		if devicesOutput != "json" && devicesOutput != "table" {
+			response := commands.NewErrorResponse(fmt.Errorf("invalid output format '%s', must be 'json' or 'table'", devicesOutput))
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
 		}
 
-		token, _ := getFleetToken()
+		// the table's agent column is its reason to exist, so it always probes
+		response := listDevices(checkAgents || devicesOutput == "table")
+		if response.Status == "error" {
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		if devicesOutput == "table" {
+			list, err := responseDevices(response)
+			if err != nil {
+				return err
+			}
+			for _, warning := range responseWarnings(response) {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", warning.Message)
+			}
+			return printDevicesTable(list)
+		}
 
-		response := commands.DevicesCommand(opts, token)
 		printJson(response)
+		return nil
+	},
+}
+
+// listDevices lists the devices matching the listing flags, locally or
+// through the remote server
+func listDevices(probeAgents bool) *commands.CommandResponse {
+	opts := devices.DeviceListOptions{
+		IncludeOffline:  includeOfflineDevices,
+		IncludeUnbooted: includeUnbooted,
+		Platform:        platform,
+		DeviceType:      deviceType,
+		CheckAgents:     probeAgents,
+
+		BackendTimeoutMs: int(backendTimeout.Milliseconds()),
+	}
+
+	if len(deviceLabels) > 0 {
+		labels, err := devices.ParseLabels(deviceLabels)
+		if err != nil {
+			return commands.NewErrorResponse(err)
+		}
+		opts.Labels = labels
+	}
+
+	if usbOnly {
+		opts.Transport = devices.TransportUSB
+	} else if networkOnly {
+		opts.Transport = devices.TransportNetwork
+	}
+
+	if serverURL := remoteServerURL(); serverURL != "" {
+		return runRemoteCommand(serverURL, "devices", opts)
+	}
+
+	token, _ := getFleetToken()
+	return commands.DevicesCommand(opts, token)
+}
+
+var devicesPartitionCmd = &cobra.Command{
+	Use:   "partition",
+	Short: "Print this CI shard's share of the devices",
+	Long: `Deterministically splits the devices matching --platform, --type and --label
+among --total CI shards and prints the devices of shard --index (1-based).
+Every worker seeing the same device pool gets a disjoint share, balanced to
+within one device, without coordinating.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := listDevices(false)
 		if response.Status == "error" {
+			printJson(response)
 			return fmt.Errorf("%s", response.Error)
 		}
+
+		list, err := responseDevices(response)
+		if err != nil {
+			return err
+		}
+
+		shard, err := commands.PartitionDevices(list, partitionTotal, partitionIndex)
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return err
+		}
+
+		printJson(commands.NewSuccessResponse(commands.DevicesPartitionResult{
+			Total:   partitionTotal,
+			Index:   partitionIndex,
+			Devices: shard,
+		}))
 		return nil
 	},
 }
 
+// agentHealth summarizes an agent status for the devices table
+func agentHealth(agent *devices.AgentStatus) string {
+	if agent == nil {
+		return "-"
+	}
+
+	if agent.Error != "" {
+		return "error: " + agent.Error
+	}
+
+	var health []string
+	switch {
+	case agent.Running && agent.Port != 0:
+		health = append(health, fmt.Sprintf("running (port %d)", agent.Port))
+	case agent.Running:
+		health = append(health, "running")
+	case agent.Installed:
+		health = append(health, "installed")
+	default:
+		health = append(health, "not installed")
+	}
+
+	if agent.Tunnel != nil && !*agent.Tunnel {
+		health = append(health, "tunnel down")
+	}
+
+	return strings.Join(health, ", ")
+}
+
+// responseDevices returns the devices of a devices response, which holds JSON
+// when it came from a remote server
+func responseDevices(response *commands.CommandResponse) ([]devices.DeviceInfo, error) {
+	if data, ok := response.Data.(map[string]any); ok {
+		return data["devices"].([]devices.DeviceInfo), nil
+	}
+
+	raw, err := json.Marshal(response.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Devices []devices.DeviceInfo `json:"devices"`
+	}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse devices: %w", err)
+	}
+	return data.Devices, nil
+}
+
+// responseWarnings returns the backends a devices response left out
+func responseWarnings(response *commands.CommandResponse) []devices.BackendWarning {
+	if data, ok := response.Data.(map[string]any); ok {
+		warnings, _ := data["warnings"].([]devices.BackendWarning)
+		return warnings
+	}
+
+	raw, err := json.Marshal(response.Data)
+	if err != nil {
+		return nil
+	}
+
+	var data struct {
+		Warnings []devices.BackendWarning `json:"warnings"`
+	}
+	_ = json.Unmarshal(raw, &data)
+	return data.Warnings
+}
+
+// printDevicesTable prints the devices as an aligned, human-readable table
+func printDevicesTable(list []devices.DeviceInfo) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tNAME\tPLATFORM\tTYPE\tVERSION\tSTATE\tAGENT\tLABELS")
+	for _, d := range list {
+		labels := devices.FormatLabels(d.Labels)
+		if labels == "" {
+			labels = "-"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.ID, d.Name, d.Platform, d.Type, d.Version, d.State, agentHealth(d.Agent), labels)
+	}
+	return w.Flush()
+}
+
 func init() {
 	rootCmd.AddCommand(devicesCmd)
 
 	// devices command flags
-	devicesCmd.Flags().StringVar(&platform, "platform", "", "target platform (ios or android)")
-	devicesCmd.Flags().StringVar(&deviceType, "type", "", "filter by device type (real or simulator/emulator)")
 	devicesCmd.Flags().BoolVar(&includeOfflineDevices, "include-offline", false, "include offline emulators and simulators")
+	devicesCmd.Flags().BoolVar(&includeUnbooted, "include-unbooted", false, "also include simulators that were never booted, with their runtime's availability")
+	devicesCmd.Flags().BoolVar(&usbOnly, "usb-only", false, "only list real devices connected over USB")
+	devicesCmd.Flags().BoolVar(&networkOnly, "network-only", false, "only list real devices connected over the network (Wi-Fi)")
+	devicesCmd.Flags().BoolVar(&checkAgents, "check-agents", false, "probe each device's agent health (installed, running, port, tunnel)")
+	devicesCmd.Flags().StringVarP(&devicesOutput, "output", "o", "json", "output format: json or table (table always checks agents)")
+	devicesCmd.MarkFlagsMutuallyExclusive("usb-only", "network-only")
+	for _, cmd := range []*cobra.Command{devicesCmd, devicesPartitionCmd} {
+		cmd.Flags().DurationVar(&backendTimeout, "backend-timeout", devices.DefaultBackendTimeout, "how long to wait for adb, go-ios or simctl before leaving their devices out")
+	}
+
+	devicesCmd.AddCommand(devicesPartitionCmd)
+	devicesPartitionCmd.Flags().BoolVar(&includeOfflineDevices, "include-offline", false, "include offline emulators and simulators")
+	devicesPartitionCmd.Flags().IntVar(&partitionTotal, "total", 0, "number of shards")
+	devicesPartitionCmd.Flags().IntVar(&partitionIndex, "index", 0, "shard to print devices for, from 1 to --total")
+	_ = devicesPartitionCmd.MarkFlagRequired("total")
+	_ = devicesPartitionCmd.MarkFlagRequired("index")
 }
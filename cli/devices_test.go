@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func TestAgentHealth(t *testing.T) {
+	tunnelDown := false
+	cases := []struct {
+		agent *devices.AgentStatus
+		want  string
+	}{
+		{nil, "-"},
+		{&devices.AgentStatus{}, "not installed"},
+		{&devices.AgentStatus{Installed: true}, "installed"},
+		{&devices.AgentStatus{Installed: true, Running: true}, "running"},
+		{&devices.AgentStatus{Installed: true, Running: true, Port: 8100}, "running (port 8100)"},
+		{&devices.AgentStatus{Installed: true, Tunnel: &tunnelDown}, "installed, tunnel down"},
+		{&devices.AgentStatus{Error: "timed out after 3s"}, "error: timed out after 3s"},
+	}
+
+	for _, c := range cases {
+		if got := agentHealth(c.agent); got != c.want {
+			t.Errorf("agentHealth(%+v) = %q, want %q", c.agent, got, c.want)
+		}
+	}
+}
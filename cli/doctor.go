@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Show which adb, emulator and xcrun mobilecli runs",
+	Long: `Reports the path each platform tool resolves to and where it came from:
+a --adb-path, --emulator-path or --xcrun-path flag, adb_path, emulator_path
+or xcrun_path in config.json, the Android SDK at ANDROID_HOME or its default
+location, or PATH, in that order. Other copies of a tool found in the SDK or
+PATH are listed too, since with several SDKs installed the wrong one may be
+picked.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		printJson(commands.DoctorCommand())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/mobile-next/mobilecli/commands"
 	"github.com/spf13/cobra"
@@ -13,19 +14,64 @@ var dumpCmd = &cobra.Command{
 	Long:  `Perform dump operations like UI tree extraction from devices.`,
 }
 
-var dumpUIFormat string
+var (
+	dumpUIFormat       string
+	dumpUIQuery        string
+	dumpUIMaxDepth     int
+	dumpUIMaxElements  int
+	dumpUIViewportOnly bool
+	dumpUIOutput       string
+
+	dumpUIHandleDialogs string
+)
 
 var dumpUICmd = &cobra.Command{
 	Use:   "ui",
 	Short: "Dump UI tree from a device",
-	Long:  `Starts an agent and dumps the UI tree from the specified device.`,
+	Long: `Starts an agent and dumps the UI tree from the specified device.
+
+With --query, only the elements matching the query are returned, as a flat
+list. Queries combine predicates with AND, OR, NOT and parentheses:
+
+  type, text, label, name, value, placeholder, identifier
+      = equals, != differs, *= contains, ~= matches a regular expression
+  x, y, width, height
+      =, !=, <, <=, >, >=
+
+Quote values with spaces or special characters, e.g.
+  mobilecli dump ui --query "type=Button AND label~='Sign.*'"
+
+Deep hierarchies such as webviews make for huge and slow dumps. --max-depth,
+--max-elements and --viewport-only bound them, and the applied limits are
+returned with the dump.
+
+With -o, or with --artifacts-dir, the dump is written to a file and its path
+is printed instead.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// the dump may be written by the daemon, whose working directory
+		// isn't this one
+		output := dumpUIOutput
+		if output != "" && output != "-" {
+			abs, err := filepath.Abs(output)
+			if err != nil {
+				return fmt.Errorf("invalid output path '%s': %v", output, err)
+			}
+			output = abs
+		}
+
 		req := commands.DumpUIRequest{
-			DeviceID: deviceId,
-			Format:   dumpUIFormat,
+			DeviceID:     deviceId,
+			Format:       dumpUIFormat,
+			Query:        dumpUIQuery,
+			MaxDepth:     dumpUIMaxDepth,
+			MaxElements:  dumpUIMaxElements,
+			ViewportOnly: dumpUIViewportOnly,
+			Output:       output,
+
+			HandleDialogs: dumpUIHandleDialogs,
 		}
 
-		response := commands.DumpUICommand(req)
+		response := runCommand("dump.ui", req, commands.DumpUICommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -44,4 +90,10 @@ func init() {
 	// dump ui command flags
 	dumpUICmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to dump UI tree from")
 	dumpUICmd.Flags().StringVar(&dumpUIFormat, "format", "", "Output format: 'raw' for unprocessed tree from agent (Default: json)")
+	dumpUICmd.Flags().StringVar(&dumpUIQuery, "query", "", "only return elements matching this query, e.g. \"type=Button AND label~='Sign.*'\"")
+	dumpUICmd.Flags().IntVar(&dumpUIMaxDepth, "max-depth", 0, "only traverse this many levels of the view hierarchy (default: all)")
+	dumpUICmd.Flags().IntVar(&dumpUIMaxElements, "max-elements", 0, "return at most this many elements (default: all)")
+	dumpUICmd.Flags().BoolVar(&dumpUIViewportOnly, "viewport-only", false, "skip elements outside of the screen")
+	addHandleDialogsFlag(dumpUICmd, &dumpUIHandleDialogs)
+	dumpUICmd.Flags().StringVarP(&dumpUIOutput, "output", "o", "", "write the dump to this JSON file instead of printing it, or '-' to print it even with --artifacts-dir")
 }
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var expectCmd = &cobra.Command{
+	Use:   "expect",
+	Short: "Assert device state for CI pipelines",
+	Long:  `Checks an expectation about the device and exits 0 if it holds or 1 if it doesn't, printing a JSON explanation either way. With --timeout, the expectation is re-checked until it holds or the timeout expires.`,
+}
+
+// runExpect prints an expectation response and turns a failed expectation into a non-zero exit
+func runExpect(response *commands.CommandResponse) error {
+	printJson(response)
+	if response.Status == "error" {
+		return fmt.Errorf("%s", response.Error)
+	}
+	return nil
+}
+
+var expectElementCmd = &cobra.Command{
+	Use:   "element",
+	Short: "Expect an element to be on screen",
+	Long:  `Expects an element to be on screen, matched either by --text (any of its text, label, value or name) or by an --element selector such as identifier=loginButton.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExpect(commands.ExpectElementCommand(commands.ExpectElementRequest{
+			DeviceID: deviceId,
+			Text:     expectText,
+			Element:  expectElement,
+			Timeout:  int(expectTimeout.Milliseconds()),
+		}))
+	},
+}
+
+var expectAppInstalledCmd = &cobra.Command{
+	Use:   "app-installed [bundle-id]",
+	Short: "Expect an app to be installed",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExpect(commands.ExpectAppInstalledCommand(commands.ExpectAppInstalledRequest{
+			DeviceID: deviceId,
+			BundleID: args[0],
+			Timeout:  int(expectTimeout.Milliseconds()),
+		}))
+	},
+}
+
+var expectAppForegroundCmd = &cobra.Command{
+	Use:   "app-foreground [bundle-id]",
+	Short: "Expect an app to be in the foreground",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExpect(commands.ExpectAppForegroundCommand(commands.ExpectAppForegroundRequest{
+			DeviceID: deviceId,
+			BundleID: args[0],
+			Timeout:  int(expectTimeout.Milliseconds()),
+		}))
+	},
+}
+
+var expectOrientationCmd = &cobra.Command{
+	Use:   "orientation [portrait|portraitUpsideDown|landscapeLeft|landscapeRight|landscape]",
+	Short: "Expect the device to be in an orientation",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExpect(commands.ExpectOrientationCommand(commands.ExpectOrientationRequest{
+			DeviceID:    deviceId,
+			Orientation: args[0],
+			Timeout:     int(expectTimeout.Milliseconds()),
+		}))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(expectCmd)
+
+	expectCmd.AddCommand(expectElementCmd)
+	expectCmd.AddCommand(expectAppInstalledCmd)
+	expectCmd.AddCommand(expectAppForegroundCmd)
+	expectCmd.AddCommand(expectOrientationCmd)
+
+	expectElementCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check")
+	expectElementCmd.Flags().DurationVar(&expectTimeout, "timeout", 0, "keep re-checking for up to this long, e.g. 10s (0 = check once)")
+	expectAppInstalledCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check")
+	expectAppInstalledCmd.Flags().DurationVar(&expectTimeout, "timeout", 0, "keep re-checking for up to this long, e.g. 10s (0 = check once)")
+	expectAppForegroundCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check")
+	expectAppForegroundCmd.Flags().DurationVar(&expectTimeout, "timeout", 0, "keep re-checking for up to this long, e.g. 10s (0 = check once)")
+	expectOrientationCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to check")
+	expectOrientationCmd.Flags().DurationVar(&expectTimeout, "timeout", 0, "keep re-checking for up to this long, e.g. 10s (0 = check once)")
+
+	expectElementCmd.Flags().StringVar(&expectText, "text", "", "text, label, value or name of the element")
+	expectElementCmd.Flags().StringVar(&expectElement, "element", "", "element selector as attribute=value (identifier, label, name, text, value, placeholder, type)")
+	expectElementCmd.MarkFlagsMutuallyExclusive("text", "element")
+	expectElementCmd.MarkFlagsOneRequired("text", "element")
+}
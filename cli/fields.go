@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/commands"
+)
+
+// selectOutputFields keeps only the given fields of a response's data, or of
+// the whole value when it has no data. Fields are dot-paths such as
+// agent.running, and apply to every item of a list, including a list wrapped
+// in a single-member object such as the devices command's {"devices": [...]},
+// whose warnings are kept.
+func selectOutputFields(jsonData []byte, fields []string) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(jsonData))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	var paths [][]string
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			paths = append(paths, strings.Split(field, "."))
+		}
+	}
+
+	// a CommandResponse keeps its field order, and its error when it failed
+	if response, ok := value.(map[string]any); ok && isCommandResponse(response) {
+		status, _ := response["status"].(string)
+		errorMessage, _ := response["error"].(string)
+		var data any
+		if response["data"] != nil {
+			data = selectRecordFields(response["data"], paths)
+		}
+		return json.Marshal(commands.CommandResponse{
+			Status: status,
+			Data:   data,
+			Error:  errorMessage,
+		})
+	}
+	return json.Marshal(selectRecordFields(value, paths))
+}
+
+// isCommandResponse reports whether a decoded object has a status and only
+// the other members of a CommandResponse
+func isCommandResponse(object map[string]any) bool {
+	if _, ok := object["status"]; !ok {
+		return false
+	}
+	for key := range object {
+		if key != "status" && key != "data" && key != "error" {
+			return false
+		}
+	}
+	return true
+}
+
+// selectRecordFields applies paths to each record of a value: the items of a
+// list, of a list wrapped in a single-member object, or else the value itself.
+// The warnings next to a wrapped list, as the devices command reports, are
+// kept as they are.
+func selectRecordFields(value any, paths [][]string) any {
+	if object, ok := value.(map[string]any); ok {
+		warnings, hasWarnings := object["warnings"]
+		members := len(object)
+		if hasWarnings {
+			members--
+		}
+
+		for key, member := range object {
+			list, ok := member.([]any)
+			if members != 1 || key == "warnings" || !ok {
+				continue
+			}
+			if selected, found := selectPaths(list, paths); found {
+				result := map[string]any{key: selected}
+				if hasWarnings {
+					result["warnings"] = warnings
+				}
+				return result
+			}
+		}
+	}
+
+	selected, _ := selectPaths(value, paths)
+	return selected
+}
+
+// selectPaths keeps the paths of value, applying them to each item of a
+// list. It reports whether any path was found.
+func selectPaths(value any, paths [][]string) (any, bool) {
+	switch v := value.(type) {
+	case []any:
+		items := make([]any, 0, len(v))
+		found := false
+		for _, item := range v {
+			selected, ok := selectPaths(item, paths)
+			if ok {
+				found = true
+			}
+			items = append(items, selected)
+		}
+		return items, found || len(v) == 0
+
+	case map[string]any:
+		rest := make(map[string][][]string)
+		whole := make(map[string]bool)
+		for _, path := range paths {
+			if len(path) == 1 {
+				whole[path[0]] = true
+			} else {
+				rest[path[0]] = append(rest[path[0]], path[1:])
+			}
+		}
+
+		selected := make(map[string]any)
+		for key, member := range v {
+			if whole[key] {
+				selected[key] = member
+			} else if subPaths, ok := rest[key]; ok {
+				if sub, found := selectPaths(member, subPaths); found {
+					selected[key] = sub
+				}
+			}
+		}
+		return selected, len(selected) > 0
+
+	default:
+		return nil, false
+	}
+}
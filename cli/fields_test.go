@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectOutputFields(t *testing.T) {
+	response := `{"status":"ok","data":{"devices":[
+		{"id":"emulator-5554","name":"Pixel 8","platform":"android","agent":{"installed":true,"running":false,"port":8100}},
+		{"id":"00008030","name":"iPhone","platform":"ios"}
+	]}}`
+
+	selected, err := selectOutputFields([]byte(response), []string{"id", "agent.running"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"ok","data":{"devices":[
+		{"id":"emulator-5554","agent":{"running":false}},
+		{"id":"00008030"}
+	]}}`, string(selected))
+
+	// the warnings of a listing are kept as they are
+	selected, err = selectOutputFields([]byte(`{"status":"ok","data":{"devices":[{"id":"emulator-5554","name":"Pixel 8"}],"warnings":[{"backend":"ios","message":"timed out"}]}}`), []string{"id"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"ok","data":{"devices":[{"id":"emulator-5554"}],"warnings":[{"backend":"ios","message":"timed out"}]}}`, string(selected))
+
+	// a list and a single object
+	selected, err = selectOutputFields([]byte(`{"status":"ok","data":[{"packageName":"com.example","version":"1.0"}]}`), []string{"packageName"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"ok","data":[{"packageName":"com.example"}]}`, string(selected))
+
+	selected, err = selectOutputFields([]byte(`{"status":"ok","data":{"message":"done","path":"/tmp/a.png"}}`), []string{"path"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"ok","data":{"path":"/tmp/a.png"}}`, string(selected))
+
+	// errors pass through
+	selected, err = selectOutputFields([]byte(`{"status":"error","error":"device not found"}`), []string{"id"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"status":"error","error":"device not found"}`, string(selected))
+}
@@ -1,26 +1,81 @@
 package cli
 
+import (
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
 var (
-	verbose bool
+	verbose  int
+	trace    bool
+	dryRun   bool
+	logDebug []string
 
 	// all commands
 	deviceId string
+	adbHost  string
+	adbPort  int
+	adbKey   string
+
+	// adb, emulator and xcrun overriding ANDROID_HOME, PATH and config.json
+	toolPaths devices.ToolPaths
+
+	// platform, type and key=value labels that narrow auto-selection and
+	// filter devices
+	platform     string
+	deviceType   string
+	deviceLabels []string
+
+	// mobilecli server to send commands to, see remoteServerURL
+	remoteServer string
+
+	// directory that artifacts are written to when no output path is given
+	artifactsDir string
+
+	// dot-paths of the fields JSON output is narrowed to
+	outputFields []string
 
 	// for screenshot command
 	screenshotOutputPath  string
 	screenshotFormat      string
 	screenshotJpegQuality int
+	screenshotRect        string
+	screenshotElement     string
+
+	// for device boot command
+	bootTimeout int
+
+	// for device authorize command
+	authorizeWait int
+
+	// for device bugreport command
+	bugreportOutput string
+
+	// for device notifications list command
+	notificationsClear bool
+
+	// for io text command
+	textClear bool
 
 	// for screencapture command
 	screencaptureFormat string
 
-	// for devices command
-	platform   string
-	deviceType string
-
 	// for apps launch command
-	locale   string
-	activity string
+	locale     string
+	activity   string
+	launchWait bool
+
+	// for apps launch, list and uninstall commands
+	appsUser string
+
+	// for expect commands
+	expectText    string
+	expectElement string
+	expectTimeout time.Duration
+
+	// for apps crashes command
+	appsCrashesWatch bool
 
 	// for agent install command
 	agentForce               bool
@@ -36,4 +91,40 @@ var (
 	// for webview wait command
 	webviewWaitState   string
 	webviewWaitTimeout int
+
+	// for webview list --devtools, webview dump and webview eval --target
+	webviewDevTools bool
+	webviewTarget   string
+
+	// for device call and sms commands
+	phoneNumber string
+	smsText     string
+
+	// for device storage fill command
+	storageLeaveFree string
+
+	// for device users create command
+	userProfileOf int
+	userGuest     bool
+
+	// for device alert accept and dismiss commands
+	alertButton string
+
+	// for update command
+	updateChannel string
+	updateMirror  string
+	updateCheck   bool
+
+	// for guard command
+	guardBundle string
+	guardSettle time.Duration
+
+	// for test run command
+	testRunApk       string
+	testRunTestApk   string
+	testRunXCTestRun string
+	testRunRunner    string
+	testRunClasses   []string
+	testRunArgs      []string
+	testRunJUnit     string
 )
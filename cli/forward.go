@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var forwardCmd = &cobra.Command{
+	Use:   "forward",
+	Short: "Port forwarding commands",
+	Long:  `Commands for inspecting the port forwarders held open to devices' agents.`,
+}
+
+var forwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active port forwarders",
+	Long: `Lists the local ports forwarded to devices' agents. Forwarders are kept by
+the process that opened them, so with a running daemon this lists the daemon's.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.ForwardListRequest{
+			DeviceID: deviceId,
+		}
+
+		response := runCommand("forward.list", req, commands.ForwardListCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(forwardCmd)
+
+	forwardCmd.AddCommand(forwardListCmd)
+
+	forwardListCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to list port forwarders of (default: all devices)")
+}
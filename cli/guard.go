@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var guardCmd = &cobra.Command{
+	Use:   "guard -- <command...> [-- <command...>]",
+	Short: "Fail if an app crashes while commands run",
+	Long: `Snapshots the crash reports of an app, runs the mobilecli commands after --
+and then exits 1 if new crash reports of the app appeared, printing them. Several
+commands are separated by further --, and run in order until one fails.
+
+Wrapped commands run on the guarded device unless they name one with --device.
+Their output goes to stderr, so stdout only carries the guard's result. Crash
+reports are written with a delay, so new ones are looked for until --settle
+has passed.`,
+	Example: `  mobilecli guard --device <device-id> --bundle com.example.app -- io tap 100,200
+  mobilecli guard --bundle com.example.app -- apps launch com.example.app -- io button BACK`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if cmd.ArgsLenAtDash() != 0 || len(args) == 0 {
+			return fmt.Errorf("the commands to guard must follow --")
+		}
+		return nil
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if guardBundle == "" {
+			return fmt.Errorf("--bundle is required")
+		}
+
+		response := commands.GuardCommand(commands.GuardRequest{
+			DeviceID: deviceId,
+			BundleID: guardBundle,
+			Settle:   int(guardSettle.Milliseconds()),
+		}, func(deviceID string) []commands.GuardedCommand {
+			return runGuardedCommands(splitGuardedCommands(args), deviceID)
+		})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+// splitGuardedCommands splits the arguments after the first -- into the
+// commands separated by further --
+func splitGuardedCommands(args []string) [][]string {
+	var guarded [][]string
+	var current []string
+	for _, arg := range args {
+		if arg == "--" {
+			if len(current) > 0 {
+				guarded = append(guarded, current)
+			}
+			current = nil
+			continue
+		}
+		current = append(current, arg)
+	}
+	if len(current) > 0 {
+		guarded = append(guarded, current)
+	}
+	return guarded
+}
+
+// guardedArgs adds --device to a wrapped command that takes one but wasn't
+// given one
+func guardedArgs(args []string, deviceID string) []string {
+	for _, arg := range args {
+		if arg == "--device" || strings.HasPrefix(arg, "--device=") {
+			return args
+		}
+	}
+
+	target, _, err := rootCmd.Find(args)
+	if err != nil || target.Flags().Lookup("device") == nil {
+		return args
+	}
+	return append(append([]string{}, args...), "--device", deviceID)
+}
+
+// runGuardedCommands runs each command with this executable, stopping at the
+// first one that fails
+func runGuardedCommands(guarded [][]string, deviceID string) []commands.GuardedCommand {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = os.Args[0]
+	}
+
+	ran := []commands.GuardedCommand{}
+	for _, args := range guarded {
+		args = guardedArgs(args, deviceID)
+
+		child := exec.Command(executable, args...)
+		child.Stdin = os.Stdin
+		child.Stdout = os.Stderr
+		child.Stderr = os.Stderr
+
+		exitCode := 0
+		if err := child.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				exitCode = exitErr.ExitCode()
+			} else {
+				fmt.Fprintf(os.Stderr, "failed to run '%s': %v\n", strings.Join(args, " "), err)
+				exitCode = -1
+			}
+		}
+
+		ran = append(ran, commands.GuardedCommand{Args: args, ExitCode: exitCode})
+		if exitCode != 0 {
+			break
+		}
+	}
+	return ran
+}
+
+func init() {
+	rootCmd.AddCommand(guardCmd)
+
+	guardCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to guard")
+	guardCmd.Flags().StringVar(&guardBundle, "bundle", "", "Bundle ID or package name of the app to watch for crashes")
+	guardCmd.Flags().DurationVar(&guardSettle, "settle", 3*time.Second, "How long to keep looking for crash reports after the commands ran")
+}
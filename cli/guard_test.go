@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGuardedCommands(t *testing.T) {
+	got := splitGuardedCommands([]string{"io", "tap", "1,2", "--", "io", "button", "BACK", "--"})
+	want := [][]string{{"io", "tap", "1,2"}, {"io", "button", "BACK"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGuardedArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"io", "tap", "1,2"}, []string{"io", "tap", "1,2", "--device", "abc"}},
+		{[]string{"io", "tap", "1,2", "--device", "xyz"}, []string{"io", "tap", "1,2", "--device", "xyz"}},
+		{[]string{"io", "tap", "--device=xyz", "1,2"}, []string{"io", "tap", "--device=xyz", "1,2"}},
+		{[]string{"server", "status"}, []string{"server", "status"}},
+	}
+	for _, tt := range tests {
+		if got := guardedArgs(tt.args, "abc"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("guardedArgs(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -9,17 +9,40 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var normalizedCoords bool
+
+// ensureUnlockedFlag makes io commands wake the device and dismiss its
+// keyguard before interacting
+var ensureUnlockedFlag bool
+
+// handleDialogsFlag makes io commands dismiss system dialogs first, see
+// commands.ParseDialogChoices
+var handleDialogsFlag string
+
+// buttonKeycode is the raw keycode 'io button --keycode' presses
+var buttonKeycode int
+
+const normalizedCoordsHelp = `Coordinates with a decimal point, such as "0.5,0.5", or any coordinates with --normalized, are fractions of the screen's width and height from 0.0 to 1.0 and are converted using the device's screen size.`
+
 var ioCmd = &cobra.Command{
 	Use:   "io",
 	Short: "Input/output operations with devices",
 	Long:  `Perform input/output operations like tapping, pressing buttons, and sending text to devices.`,
 }
 
+var tapShow bool
+
 var ioTapCmd = &cobra.Command{
 	Use:   "tap [x,y]",
 	Short: "Tap on a device screen at the given coordinates",
-	Long:  `Sends a tap event to the specified device at the given x,y coordinates. Coordinates should be provided as a single string "x,y".`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Sends a tap event to the specified device at the given x,y coordinates. Coordinates should be provided as a single string "x,y".
+
+With --show, a screenshot with the tap point marked is saved before tapping, to
+the artifacts directory or the current directory, and its path is returned. On
+Android the touch is also drawn on the device with the pointer location overlay.
+
+` + normalizedCoordsHelp,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		coordsStr := args[0]
 		parts := strings.Split(coordsStr, ",")
@@ -29,6 +52,30 @@ var ioTapCmd = &cobra.Command{
 			return fmt.Errorf("%s", response.Error)
 		}
 
+		if normalizedCoords || isNormalized(parts) {
+			points, err := parseNormalizedPoints(parts)
+			if err != nil {
+				response := commands.NewErrorResponse(err)
+				printJson(response)
+				return fmt.Errorf("%s", response.Error)
+			}
+
+			req := commands.TapRequest{
+				DeviceID:       deviceId,
+				EnsureUnlocked: ensureUnlockedFlag,
+				HandleDialogs:  handleDialogsFlag,
+				Normalized:     points[0],
+				Show:           tapShow,
+			}
+
+			response := runCommand("tap", req, commands.TapCommand)
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
 		y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
 
@@ -39,12 +86,15 @@ var ioTapCmd = &cobra.Command{
 		}
 
 		req := commands.TapRequest{
-			DeviceID: deviceId,
-			X:        x,
-			Y:        y,
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			HandleDialogs:  handleDialogsFlag,
+			X:              x,
+			Y:              y,
+			Show:           tapShow,
 		}
 
-		response := commands.TapCommand(req)
+		response := runCommand("tap", req, commands.TapCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -58,8 +108,10 @@ var longPressDuration int
 var ioLongPressCmd = &cobra.Command{
 	Use:   "longpress [x,y]",
 	Short: "Long press on a device screen at the given coordinates",
-	Long:  `Sends a long press event to the specified device at the given x,y coordinates. Coordinates should be provided as a single string "x,y".`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Sends a long press event to the specified device at the given x,y coordinates. Coordinates should be provided as a single string "x,y".
+
+` + normalizedCoordsHelp,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		coordsStr := args[0]
 		parts := strings.Split(coordsStr, ",")
@@ -69,6 +121,30 @@ var ioLongPressCmd = &cobra.Command{
 			return fmt.Errorf("%s", response.Error)
 		}
 
+		if normalizedCoords || isNormalized(parts) {
+			points, err := parseNormalizedPoints(parts)
+			if err != nil {
+				response := commands.NewErrorResponse(err)
+				printJson(response)
+				return fmt.Errorf("%s", response.Error)
+			}
+
+			req := commands.LongPressRequest{
+				DeviceID:       deviceId,
+				EnsureUnlocked: ensureUnlockedFlag,
+				HandleDialogs:  handleDialogsFlag,
+				Normalized:     points[0],
+				Duration:       longPressDuration,
+			}
+
+			response := runCommand("longpress", req, commands.LongPressCommand)
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
 		y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
 
@@ -79,13 +155,15 @@ var ioLongPressCmd = &cobra.Command{
 		}
 
 		req := commands.LongPressRequest{
-			DeviceID: deviceId,
-			X:        x,
-			Y:        y,
-			Duration: longPressDuration,
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			HandleDialogs:  handleDialogsFlag,
+			X:              x,
+			Y:              y,
+			Duration:       longPressDuration,
 		}
 
-		response := commands.LongPressCommand(req)
+		response := runCommand("longpress", req, commands.LongPressCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -97,15 +175,22 @@ var ioLongPressCmd = &cobra.Command{
 var ioButtonCmd = &cobra.Command{
 	Use:   "button [button_name]",
 	Short: "Press a hardware button on a device",
-	Long:  `Sends a hardware button press event to the specified device (e.g., "HOME", "VOLUME_UP", "VOLUME_DOWN", "POWER"). Button names are case-insensitive.`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Sends a hardware button press event to the specified device (e.g., "HOME", "VOLUME_UP", "VOLUME_DOWN", "POWER"). Button names are case-insensitive.
+
+Not every button exists on every platform, see the README for which ones map where. With --keycode, a raw platform keycode is sent instead of a button name, e.g. --keycode 85 for KEYCODE_MEDIA_PLAY_PAUSE on Android.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.ButtonRequest{
-			DeviceID: deviceId,
-			Button:   args[0],
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			HandleDialogs:  handleDialogsFlag,
+			Keycode:        buttonKeycode,
+		}
+		if len(args) > 0 {
+			req.Button = args[0]
 		}
 
-		response := commands.ButtonCommand(req)
+		response := runCommand("button", req, commands.ButtonCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -117,15 +202,27 @@ var ioButtonCmd = &cobra.Command{
 var ioTextCmd = &cobra.Command{
 	Use:   "text [text]",
 	Short: "Send text input to a device",
-	Long:  `Sends text input to the currently focused element on the specified device.`,
-	Args:  cobra.ExactArgs(1),
+	Long:  `Sends text input to the currently focused element on the specified device. With --clear, the focused element's existing value is deleted first; the text may then be omitted to only clear the field.`,
+	Args:  cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !textClear {
+			return fmt.Errorf("accepts 1 arg(s), received 0")
+		}
+
+		text := ""
+		if len(args) == 1 {
+			text = args[0]
+		}
+
 		req := commands.TextRequest{
-			DeviceID: deviceId,
-			Text:     args[0],
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			HandleDialogs:  handleDialogsFlag,
+			Text:           text,
+			Clear:          textClear,
 		}
 
-		response := commands.TextCommand(req)
+		response := runCommand("text", req, commands.TextCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -143,11 +240,12 @@ Keys name physical keys and are case-insensitive: "cmd+A" is the same as "cmd+a"
 	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.KeysRequest{
-			DeviceID: deviceId,
-			Keys:     args,
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			Keys:           args,
 		}
 
-		response := commands.KeysCommand(req)
+		response := runCommand("keys", req, commands.KeysCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -156,20 +254,82 @@ Keys name physical keys and are case-insensitive: "cmd+A" is the same as "cmd+a"
 	},
 }
 
+var (
+	swipeDuration int
+	swipeDistance int
+	swipeFling    bool
+)
+
+// flingDurationMs is the swipe duration used by --fling
+const flingDurationMs = 100
+
 var ioSwipeCmd = &cobra.Command{
-	Use:   "swipe [x1,y1,x2,y2]",
+	Use:   "swipe [x1,y1,x2,y2 | up|down|left|right]",
 	Short: "Swipe on a device screen from one point to another",
-	Long:  `Sends a swipe gesture to the specified device from coordinates x1,y1 to x2,y2. Coordinates should be provided as a single string "x1,y1,x2,y2".`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Sends a swipe gesture to the specified device from coordinates x1,y1 to x2,y2. Coordinates should be provided as a single string "x1,y1,x2,y2".
+
+Instead of coordinates, a direction of up, down, left or right swipes through the middle of the screen, computed from the device's screen size. The direction is the way the finger moves, so "up" scrolls the content down. Use --distance to set how far it travels and --fling for a quick flick.
+
+` + normalizedCoordsHelp,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		duration := swipeDuration
+		if swipeFling && !cmd.Flags().Changed("duration") {
+			duration = flingDurationMs
+		}
+
+		switch direction := strings.ToLower(strings.TrimSpace(args[0])); direction {
+		case commands.SwipeUp, commands.SwipeDown, commands.SwipeLeft, commands.SwipeRight:
+			req := commands.SwipeRequest{
+				DeviceID:       deviceId,
+				EnsureUnlocked: ensureUnlockedFlag,
+				HandleDialogs:  handleDialogsFlag,
+				Direction:      direction,
+				Distance:       swipeDistance,
+				DurationMs:     duration,
+			}
+
+			response := runCommand("swipe", req, commands.SwipeCommand)
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		coordsStr := args[0]
 		parts := strings.Split(coordsStr, ",")
 		if len(parts) != 4 {
-			response := commands.NewErrorResponse(fmt.Errorf("invalid coordinate format. Expected 'x1,y1,x2,y2', got '%s'", coordsStr))
+			response := commands.NewErrorResponse(fmt.Errorf("invalid coordinate format. Expected 'x1,y1,x2,y2' or a direction of up, down, left or right, got '%s'", coordsStr))
 			printJson(response)
 			return fmt.Errorf("%s", response.Error)
 		}
 
+		if normalizedCoords || isNormalized(parts) {
+			points, err := parseNormalizedPoints(parts)
+			if err != nil {
+				response := commands.NewErrorResponse(err)
+				printJson(response)
+				return fmt.Errorf("%s", response.Error)
+			}
+
+			req := commands.SwipeRequest{
+				DeviceID:       deviceId,
+				EnsureUnlocked: ensureUnlockedFlag,
+				HandleDialogs:  handleDialogsFlag,
+				NormalizedFrom: points[0],
+				NormalizedTo:   points[1],
+				DurationMs:     duration,
+			}
+
+			response := runCommand("swipe", req, commands.SwipeCommand)
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		x1, errX1 := strconv.Atoi(strings.TrimSpace(parts[0]))
 		y1, errY1 := strconv.Atoi(strings.TrimSpace(parts[1]))
 		x2, errX2 := strconv.Atoi(strings.TrimSpace(parts[2]))
@@ -182,14 +342,17 @@ var ioSwipeCmd = &cobra.Command{
 		}
 
 		req := commands.SwipeRequest{
-			DeviceID: deviceId,
-			X1:       x1,
-			Y1:       y1,
-			X2:       x2,
-			Y2:       y2,
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+			HandleDialogs:  handleDialogsFlag,
+			X1:             x1,
+			Y1:             y1,
+			X2:             x2,
+			Y2:             y2,
+			DurationMs:     duration,
 		}
 
-		response := commands.SwipeCommand(req)
+		response := runCommand("swipe", req, commands.SwipeCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
@@ -211,10 +374,57 @@ func init() {
 
 	// io command flags
 	ioTapCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to tap on")
+	ioTapCmd.Flags().BoolVar(&tapShow, "show", false, "save a screenshot with the tap point marked, and show the touch on Android")
 	ioLongPressCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to long press on")
 	ioLongPressCmd.Flags().IntVar(&longPressDuration, "duration", 500, "duration of the long press in milliseconds")
 	ioButtonCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to press button on")
+	ioButtonCmd.Flags().IntVar(&buttonKeycode, "keycode", 0, "press a raw platform keycode instead of a button name (Android only)")
 	ioTextCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to send keys to")
+	ioTextCmd.Flags().BoolVar(&textClear, "clear", false, "Clear the focused element's existing value before typing")
 	ioKeysCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to press keys on")
 	ioSwipeCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to swipe on")
+	ioSwipeCmd.Flags().IntVar(&swipeDuration, "duration", 0, "duration of the swipe in milliseconds, short for a fling and long for a slow drag (0 = platform default)")
+	ioSwipeCmd.Flags().IntVar(&swipeDistance, "distance", 0, "how far a directional swipe travels, as a percentage of the screen (default 60)")
+	ioSwipeCmd.Flags().BoolVar(&swipeFling, "fling", false, "make a quick flick, a shortcut for --duration 100")
+	for _, cmd := range []*cobra.Command{ioTapCmd, ioLongPressCmd, ioSwipeCmd} {
+		cmd.Flags().BoolVar(&normalizedCoords, "normalized", false, "treat coordinates as fractions of the screen size (0.0-1.0)")
+	}
+	for _, cmd := range []*cobra.Command{ioTapCmd, ioLongPressCmd, ioButtonCmd, ioTextCmd, ioKeysCmd, ioSwipeCmd} {
+		cmd.Flags().BoolVar(&ensureUnlockedFlag, "ensure-unlocked", false, "wake the device and dismiss the lock screen first if needed")
+	}
+	for _, cmd := range []*cobra.Command{ioTapCmd, ioLongPressCmd, ioButtonCmd, ioTextCmd, ioSwipeCmd} {
+		addHandleDialogsFlag(cmd, &handleDialogsFlag)
+	}
+}
+
+// addHandleDialogsFlag adds --handle-dialogs to cmd, which uses the default
+// choices when given without a value
+func addHandleDialogsFlag(cmd *cobra.Command, value *string) {
+	cmd.Flags().StringVar(value, "handle-dialogs", "", "dismiss Android ANR, crash and permission dialogs first, optionally tapping these choices, e.g. --handle-dialogs=permission=deny (default "+commands.DefaultDialogChoices+")")
+	cmd.Flags().Lookup("handle-dialogs").NoOptDefVal = "default"
+}
+
+// isNormalized reports whether coordinates are written as screen fractions,
+// e.g. "0.5"
+func isNormalized(parts []string) bool {
+	for _, part := range parts {
+		if strings.Contains(part, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNormalizedPoints parses pairs of screen fractions into points
+func parseNormalizedPoints(parts []string) ([]*commands.NormalizedPoint, error) {
+	points := make([]*commands.NormalizedPoint, 0, len(parts)/2)
+	for i := 0; i+1 < len(parts); i += 2 {
+		x, errX := strconv.ParseFloat(strings.TrimSpace(parts[i]), 64)
+		y, errY := strconv.ParseFloat(strings.TrimSpace(parts[i+1]), 64)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid normalized coordinate values. Expected fractions between 0.0 and 1.0, got '%s,%s'", parts[i], parts[i+1])
+		}
+		points = append(points, &commands.NormalizedPoint{X: x, Y: y})
+	}
+	return points, nil
 }
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/daemon"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// RemoteServerEnvVar sets the remote mobilecli server commands are sent to,
+// as --remote does
+const RemoteServerEnvVar = "MOBILECLI_REMOTE"
+
+// remoteServerURL returns the mobilecli server commands are proxied to, or an
+// empty string to run them locally
+func remoteServerURL() string {
+	if remoteServer != "" {
+		return remoteServer
+	}
+	return os.Getenv(RemoteServerEnvVar)
+}
+
+// checkRemoteAutoSelect refuses a command the remote server would auto-select
+// its device for: the server doesn't see --platform, --type and --label, and
+// would pick among all of its devices. Device listings carry them instead.
+func checkRemoteAutoSelect(name string, req any) error {
+	if name == "devices" || !commands.AutoSelectNarrowed() {
+		return nil
+	}
+
+	var target struct {
+		DeviceID string `json:"deviceId"`
+	}
+	if data, err := json.Marshal(req); err == nil {
+		_ = json.Unmarshal(data, &target)
+	}
+	if target.DeviceID != "" {
+		return nil
+	}
+	return fmt.Errorf("--platform, --type and --label don't narrow auto-selection on a remote server, pick the device with --device")
+}
+
+// runRemoteCommand runs a command on a remote mobilecli server, attaching the
+// stored auth token so device farms behind an auth gateway accept it
+func runRemoteCommand(serverURL, name string, req any) *commands.CommandResponse {
+	if !daemon.CanDelegate(name) {
+		return commands.NewErrorResponse(fmt.Errorf("'%s' can't be run on a remote server", name))
+	}
+	if err := checkRemoteAutoSelect(name, req); err != nil {
+		return commands.NewErrorResponse(err)
+	}
+
+	// not being logged in is fine, the server may not require a token
+	token, _ := loadToken()
+
+	utils.Verbose("Sending '%s' to remote server %s", name, serverURL)
+	response, err := daemon.NewRemoteClient(serverURL, token).Invoke(name, req)
+	if err != nil {
+		return commands.NewErrorResponse(fmt.Errorf("remote: %v", err))
+	}
+
+	return response
+}
+
+// installOnRemoteServer installs an app with a remote mobilecli server. A
+// local app is uploaded to the server first, an app URL is downloaded by it.
+func installOnRemoteServer(serverURL string, req commands.InstallAppRequest) *commands.CommandResponse {
+	if req.Keystore != "" || req.ProvisioningProfile != "" {
+		return commands.NewErrorResponse(fmt.Errorf("--keystore and --provisioning-profile can't be used with a remote server"))
+	}
+	if err := checkRemoteAutoSelect("apps.install", req); err != nil {
+		return commands.NewErrorResponse(err)
+	}
+
+	token, _ := loadToken()
+	client := daemon.NewRemoteClient(serverURL, token)
+
+	params := map[string]any{
+		"deviceId":         req.DeviceID,
+		"url":              req.URL,
+		"sha256":           req.SHA256,
+		"forceResign":      req.ForceResign,
+		"signingIdentity":  req.SigningIdentity,
+		"grantPermissions": req.GrantPermissions,
+		"allowTest":        req.AllowTest,
+		"instant":          req.Instant,
+		"allowDowngrade":   req.AllowDowngrade,
+		"user":             req.User,
+		"keyAlias":         req.KeyAlias,
+		"keystorePassword": req.KeystorePassword,
+		"keyPassword":      req.KeyPassword,
+	}
+
+	if req.Path != "" {
+		utils.Verbose("Uploading %s to remote server %s", req.Path, serverURL)
+		artifact, err := client.Upload(req.Path, func(sent, total int64) {
+			if req.OnProgress != nil {
+				req.OnProgress(devices.InstallProgress{
+					Phase:      devices.InstallPhaseUploading,
+					BytesSent:  sent,
+					TotalBytes: total,
+					Percent:    int(sent * 100 / max(total, 1)),
+				})
+			}
+		})
+		if err != nil {
+			return commands.NewErrorResponse(fmt.Errorf("remote: %v", err))
+		}
+		params["artifactId"] = artifact.ID
+	}
+
+	var result json.RawMessage
+	if err := client.Call("device.apps.install", params, &result); err != nil {
+		return commands.NewErrorResponse(fmt.Errorf("remote: %v", err))
+	}
+
+	if req.OnProgress != nil {
+		req.OnProgress(devices.InstallProgress{Phase: devices.InstallPhaseCompleted, Percent: 100})
+	}
+	return commands.NewSuccessResponse(result)
+}
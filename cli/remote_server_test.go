@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/commands"
+)
+
+func TestCheckRemoteAutoSelect(t *testing.T) {
+	t.Setenv(commands.DevicePlatformEnvVar, "")
+	t.Setenv(commands.DeviceTypeEnvVar, "")
+	t.Setenv(commands.DeviceLabelsEnvVar, "")
+
+	if err := checkRemoteAutoSelect("tap", commands.TapRequest{}); err != nil {
+		t.Errorf("expected auto-selection without filters to be allowed: %v", err)
+	}
+
+	t.Setenv(commands.DevicePlatformEnvVar, "ios")
+	if err := checkRemoteAutoSelect("tap", commands.TapRequest{}); err == nil {
+		t.Error("expected auto-selection narrowed by --platform to fail")
+	}
+	if err := checkRemoteAutoSelect("tap", commands.TapRequest{DeviceID: "device-1"}); err != nil {
+		t.Errorf("expected a given device to be allowed: %v", err)
+	}
+	if err := checkRemoteAutoSelect("devices", struct{}{}); err != nil {
+		t.Errorf("expected listings to carry their filters: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var reverseRemoveAll bool
+
+var reverseCmd = &cobra.Command{
+	Use:   "reverse [device-port:host-port]",
+	Short: "Let a device reach a port on the host",
+	Long: `Makes localhost:<device-port> on the device reach <host-port> on the host, so apps under test can call a mock server running on the automation host. A single port is used on both sides.
+
+On Android this is 'adb reverse', which lasts until it's removed, the device disconnects or the adb server restarts. iOS simulators share the host's network, so the host's ports are reachable at localhost already, on the same port. Real iOS devices can't reach the host over USB, so <device-port> on the host's network interfaces is relayed to <host-port> on its localhost, and apps call the host at the address printed. The relay is kept by the process that made it: the daemon when one is running, otherwise this command, which then runs until interrupted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devicePort, hostPort, err := commands.ParsePortMapping(args[0])
+		if err != nil {
+			return err
+		}
+
+		req := commands.ReverseRequest{
+			DeviceID:   deviceId,
+			DevicePort: devicePort,
+			HostPort:   hostPort,
+		}
+
+		_, delegated := delegationSocket("reverse")
+		inProcess := remoteServerURL() == "" && !delegated
+
+		response := runCommand("reverse", req, commands.ReverseCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		// a relay held by this process ends with it
+		if result, ok := response.Data.(commands.ReverseResult); ok && result.Relay && inProcess {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			fmt.Fprintf(os.Stderr, "Relaying %s to localhost:%d, press ctrl+c to stop\n", result.Address, hostPort)
+			<-ctx.Done()
+		}
+		return nil
+	},
+}
+
+var reverseListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the ports of a device that reach the host",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.ReverseListRequest{
+			DeviceID: deviceId,
+		}
+
+		response := runCommand("reverse.list", req, commands.ReverseListCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var reverseRemoveCmd = &cobra.Command{
+	Use:   "remove [device-port]",
+	Short: "Stop a device port reaching the host",
+	Long:  `Stops a device port reaching the host, or every one with --all.`,
+	Args:  cobra.RangeArgs(0, 1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !reverseRemoveAll {
+			return fmt.Errorf("a device port or --all is required")
+		}
+		if len(args) == 1 && reverseRemoveAll {
+			return fmt.Errorf("a device port and --all cannot be used together")
+		}
+
+		req := commands.ReverseRemoveRequest{
+			DeviceID: deviceId,
+			All:      reverseRemoveAll,
+		}
+		if len(args) == 1 {
+			port, err := strconv.Atoi(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid device port '%s'", args[0])
+			}
+			req.DevicePort = port
+		}
+
+		response := runCommand("reverse.remove", req, commands.ReverseRemoveCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reverseCmd)
+
+	reverseCmd.AddCommand(reverseListCmd)
+	reverseCmd.AddCommand(reverseRemoveCmd)
+
+	reverseCmd.PersistentFlags().StringVar(&deviceId, "device", "", "ID of the device that reaches the host")
+	reverseRemoveCmd.Flags().BoolVar(&reverseRemoveAll, "all", false, "remove every reversed port of the device")
+}
@@ -1,11 +1,16 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 
 	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
 	"github.com/mobile-next/mobilecli/server"
 	"github.com/mobile-next/mobilecli/utils"
 	"github.com/spf13/cobra"
@@ -30,38 +35,128 @@ var rootCmd = &cobra.Command{
   # List all devices including offline ones
   mobilecli devices --include-offline --platform ios --type simulator
 
+  # List only real devices connected over Wi-Fi
+  mobilecli devices --network-only
+
+  # Show devices with their agent health as a table
+  mobilecli devices --output table
+
+  # Print only some fields of the JSON output, e.g. the device IDs
+  mobilecli devices --fields id
+
+  # Print the Android devices of CI shard 1 out of 4
+  mobilecli devices partition --total 4 --index 1 --platform android
+
+  # Label a device, then list or auto-select only devices with that label
+  mobilecli device label set --device <id> team=payments pool=smoke
+  mobilecli devices --label pool=smoke
+  mobilecli screenshot --label pool=smoke
+
+  # Auto-select among iOS simulators only
+  mobilecli screenshot --platform ios --type simulator
+
   # Boot an offline emulator/simulator device
   mobilecli device boot --device <device-id>
 
+  # Boot with a longer timeout for slow emulators
+  mobilecli device boot --device <device-id> --boot-timeout 300
+
   # Shutdown a running emulator/simulator device
   mobilecli device shutdown --device <device-id>
 
   # Reboot a device
   mobilecli device reboot --device <device-id>
 
-  # Get device info (OS, version, screen size)
+  # Get device info (OS, version, screen size, and on iOS supervision, profiles and battery health)
   mobilecli device info --device <device-id>
 
+  # Get device properties (manufacturer, ABI, API level, locale, ...)
+  mobilecli device props --device <device-id>
+
+  # Collect a diagnostics archive for a support ticket
+  mobilecli device bugreport --device <device-id> -o report.zip
+
+  # Lock or unlock the screen
+  mobilecli device lock --device <device-id>
+  mobilecli device unlock --device <device-id>
+
+  # Turn the screen on or off, whatever state it is in
+  mobilecli device wake --device <device-id>
+  mobilecli device screen off --device <device-id>
+
+  # Keep the screen on while plugged in, and restore the original setting
+  mobilecli device stay-awake on --device <device-id>
+  mobilecli device stay-awake off --device <device-id>
+
+  # Show or hide the window of an emulator or simulator
+  mobilecli device window show --device <device-id>
+  mobilecli device window hide --device <device-id>
+
+  # List, tap and clear notifications (Android)
+  mobilecli device notifications list --device <device-id>
+  mobilecli device notifications tap "New message" --device <device-id>
+  mobilecli device notifications clear --device <device-id>
+
+  # Accept a permission alert, or open Control Center
+  mobilecli device alert accept --button "Allow" --device <device-id>
+  mobilecli device control-center open --device <device-id>
+
+  # Seed the photo library and address book, e.g. for photo-picker flows
+  mobilecli device media add --device <device-id> photo.jpg clip.mp4
+  mobilecli device contacts add --device <device-id> contacts.vcf
+
+  # Test date-dependent flows, then go back to the host's time
+  mobilecli device time set --device <device-id> "2025-01-01T09:41:00"
+  mobilecli device time sync --device <device-id>
+
   # Get/set device orientation
   mobilecli device orientation get --device <device-id>
-  mobilecli device orientation set --device <device-id> landscape
+  mobilecli device orientation set --device <device-id> landscapeRight
+
+  # Create a work profile and launch an app in it (Android)
+  mobilecli device users create "Work" --device <device-id> --profile-of 0
+  mobilecli apps launch --device <device-id> com.example.app --user 10
 
 APP MANAGEMENT:
   # Launch an app
   mobilecli apps launch --device <device-id> com.example.app
 
+  # Start a specific Android activity and wait for it to be drawn
+  mobilecli apps launch --device <device-id> com.example.app --activity .DebugActivity --wait
+
   # Terminate an app
   mobilecli apps terminate --device <device-id> com.example.app
 
+  # Terminate every running app between tests, keeping one running
+  mobilecli apps terminate --device <device-id> --all --exclude com.example.keep
+
+  # Check whether an app is installed, running or in the foreground
+  mobilecli apps state --device <device-id> com.example.app
+
   # List installed apps
   mobilecli apps list --device <device-id>
 
   # Get currently foreground app
   mobilecli apps foreground --device <device-id>
 
+  # List running apps with foreground/background/cached state
+  mobilecli apps running --device <device-id>
+
+  # Stream new crashes of an app while a test runs
+  mobilecli apps crashes --device <device-id> --watch com.example.app
+
   # Install an app (.apk for Android, .ipa/.zip for iOS)
   mobilecli apps install --device <device-id> /path/to/app.apk
 
+  # Check an app's ABIs, minimum OS and signing against a device before installing it
+  mobilecli apps verify --device <device-id> /path/to/app.ipa
+
+  # Install an app from artifact storage, reusing the cached download when the checksum matches
+  mobilecli apps install --device <device-id> --url https://example.com/builds/app.apk --sha256 <sha256>
+
+  # Install a split app from an App Bundle, granting its runtime permissions
+  mobilecli apps install --device <device-id> -g app.aab --keystore release.jks --keystore-pass <pass> --key-alias upload
+
   # Uninstall an app
   mobilecli apps uninstall --device <device-id> com.example.app
 
@@ -72,25 +167,64 @@ SCREEN & MEDIA:
   # Take a JPEG screenshot with quality
   mobilecli screenshot --device <device-id> -o screen.jpg -f jpeg -q 85
 
+  # Take a screenshot of a region or a single element
+  mobilecli screenshot --device <device-id> --rect 0,0,390,100 -o header.png
+  mobilecli screenshot --device <device-id> --element "identifier=loginButton" -o button.png
+
+  # Collect artifacts under ./artifacts/<device-id>/ with timestamped names
+  mobilecli screenshot --device <device-id> --artifacts-dir ./artifacts
+  MOBILECLI_ARTIFACTS_DIR=./artifacts mobilecli dump ui --device <device-id>
+
   # Stream screen capture (MJPEG)
   mobilecli screencapture --device <device-id> -f mjpeg | ffplay -
 
+  # Mirror and control a device in a browser window
+  mobilecli view --device <device-id>
+
 INPUT/OUTPUT:
   # Tap at coordinates
   mobilecli io tap --device <device-id> 100,200
 
+  # Tap the middle of the screen, as fractions of its size
+  mobilecli io tap --device <device-id> 0.5,0.5
+
+  # Tap and save a screenshot with the tap point marked
+  mobilecli io tap --device <device-id> 100,200 --show
+
   # Long press at coordinates
   mobilecli io longpress --device <device-id> 100,200
 
   # Swipe from one point to another
   mobilecli io swipe --device <device-id> 100,200,300,400
 
+  # Fast fling vs slow drag
+  mobilecli io swipe --device <device-id> --duration 150 500,1500,500,300
+
+  # Swipe up through the middle of the screen, or flick left across 80% of it
+  mobilecli io swipe --device <device-id> up
+  mobilecli io swipe --device <device-id> --fling --distance 80 left
+
   # Press hardware button (HOME, VOLUME_UP, VOLUME_DOWN, POWER)
   mobilecli io button --device <device-id> HOME
 
   # Send text input
   mobilecli io text --device <device-id> "Hello World"
 
+  # Replace the focused field's value
+  mobilecli io text --device <device-id> --clear "new value"
+
+  # Type on the device from the local keyboard, until ctrl+d
+  mobilecli io type --interactive --device <device-id>
+
+  # Wait for an unauthorized Android device to allow USB debugging
+  mobilecli device authorize --device <device-id>
+
+  # Record the commands that follow, then replay them on another device
+  mobilecli session record login.json --device <device-id>
+  mobilecli io tap --device <device-id> 100,200
+  mobilecli session stop
+  mobilecli session replay login.json --device <other-device-id>
+
 WEBVIEW:
   # List embedded webviews in the foreground app
   mobilecli webview list --device <device-id>
@@ -127,8 +261,25 @@ CRASH REPORTS:
   # Get a specific crash report
   mobilecli device crashes get --device <device-id> <crash-id>
 
+ASSERTIONS (exit 0 if the expectation holds, 1 otherwise):
+  # Wait up to 10s for text to appear
+  mobilecli expect element --device <device-id> --text "Welcome" --timeout 10s
+
+  # Check an app is installed
+  mobilecli expect app-installed --device <device-id> com.example.app
+
+  # Check the device orientation
+  mobilecli expect orientation --device <device-id> landscape
+
+  # Fail if an app crashes while commands run
+  mobilecli guard --device <device-id> --bundle com.example.app -- io tap 100,200 -- io button BACK
+
+  # Run Android instrumentation tests or iOS XCUITests, and write a JUnit report
+  mobilecli test run --device <device-id> --apk app.apk --test-apk app-androidTest.apk --junit report.xml
+  mobilecli test run --device <device-id> --xctestrun MyApp.xctestrun
+
 AGENT:
-  # Check agent installation status
+  # Check agent installation status against the latest release
   mobilecli agent status --device <device-id>
 
   # Install the on-device agent
@@ -140,6 +291,23 @@ AGENT:
   # Install on a real iOS device (requires provisioning profile)
   mobilecli agent install --device <device-id> --provisioning-profile /path/to/profile.mobileprovision
 
+  # Update an outdated agent, or remove it
+  mobilecli agent update --device <device-id>
+  mobilecli agent uninstall --device <device-id>
+
+  # Update mobilecli itself to the latest release, or the latest prerelease
+  mobilecli update
+  mobilecli update --channel beta
+
+PORT FORWARDING:
+  # List the port forwarders held open by the daemon
+  mobilecli forward list
+
+  # Let the device reach a mock server on the host's port 3000 at localhost:8080
+  mobilecli reverse --device <device-id> 8080:3000
+  mobilecli reverse list --device <device-id>
+  mobilecli reverse remove --device <device-id> --all
+
 REMOTE DEVICES:
   # Allocate a remote iOS device
   mobilecli remote allocate --platform ios --version ">=18" --name "iPhone*" --wait
@@ -151,6 +319,9 @@ REMOTE DEVICES:
   mobilecli remote release --device <device-id>
 
 FILESYSTEM:
+  # Find an app's bundle, data and app group containers
+  mobilecli apps container --device <device-id> com.example.app
+
   # List files on the device
   mobilecli fs ls --device <device-id> /sdcard
 
@@ -176,20 +347,57 @@ UTILITIES:
   # Dump UI tree
   mobilecli dump ui --device <device-id>
 
+  # Capture a screenshot and the UI tree of the same screen in one payload
+  mobilecli snapshot --device <device-id>
+
+  # Dump only the elements matching a query
+  mobilecli dump ui --query "type=Button AND label~='Sign.*'"
+
+  # Bound the dump of a deep hierarchy, e.g. a webview
+  mobilecli dump ui --max-depth 20 --max-elements 500 --viewport-only
+
   # Start HTTP server
   mobilecli server start --listen localhost:12000 --cors
 
+  # Start HTTP server that WebDriver clients can drive devices through
+  mobilecli server start --listen localhost:4723 --webdriver
+
+  # Serve JSON-RPC over stdin/stdout for a parent process to embed
+  mobilecli server start --stdio
+
+  # Check that a server is live and ready, e.g. for orchestrators
+  mobilecli server status --listen localhost:12000
+
+  # Keep device connections warm in a background daemon (used automatically by the CLI)
+  mobilecli daemon -d
+
+  # Show which adb, emulator and xcrun are used, and where they came from
+  mobilecli doctor
+
 COMMON FLAGS:
-  --device <id>        Device ID (from 'mobilecli devices' command)
-  -v, --verbose        Enable verbose output
+  --device <id>        Device ID (from 'mobilecli devices' command), defaults to $ANDROID_SERIAL when set
+  --platform <name>    Only auto-select ios or android devices
+  --type <type>        Only auto-select real devices, simulators or emulators
+  --label <key=value>  Only auto-select devices with this label (see 'mobilecli device label')
+  --fields <list>      Only print these fields of the JSON data, e.g. id,name or agent.running
+  --adb-host <host>    Use the adb server on another host, e.g. a device provider
+  --adb-port <port>    Use the adb server on another port (default: $ANDROID_ADB_SERVER_PORT or 5037)
+  --adb-key <path>     Authenticate adb with this private key too, e.g. one preauthorized on a CI image
+  --adb-path <path>    Run this adb, likewise --emulator-path and --xcrun-path (default: config.json, then ANDROID_HOME or PATH)
+  --remote <url>       Send commands to a remote mobilecli server (default: $MOBILECLI_REMOTE)
+  -v, --verbose        Enable verbose output, -vv also logs HTTP bodies and adb arguments
+  --log-debug <list>   Enable debug logs of some subsystems only: wda, adb, tunnel, server
+  --trace              Log every adb/simctl/WDA call with its timing
+  --dry-run            Print the adb/simctl/WDA calls instead of running them
   --help               Show help for any command`,
-	CompletionOptions: cobra.CompletionOptions{
-		HiddenDefaultCmd: true,
-	},
 	Version:       server.Version,
 	SilenceUsage:  true,
 	SilenceErrors: true,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := utils.SetDebugSubsystems(logDebug); err != nil {
+			return err
+		}
+
 		token, _ := getRemoteToken()
 		if token != "" {
 			commands.SetFleetConfig(token)
@@ -199,13 +407,56 @@ COMMON FLAGS:
 }
 
 func initConfig() {
-	utils.SetVerbose(verbose)
+	utils.SetTrace(trace)
+	utils.SetVerboseLevel(verbose)
+	utils.SetDryRun(dryRun)
+	if utils.IsTrace() || dryRun {
+		http.DefaultTransport = utils.TraceTransport(http.DefaultTransport)
+	}
+	devices.SetAdbServer(adbHost, adbPort)
+	devices.SetAdbKey(adbKey)
+	devices.SetToolPathFlags(toolPaths)
+
+	// narrow auto-selection, for commands running in this process
+	if platform != "" {
+		_ = os.Setenv(commands.DevicePlatformEnvVar, platform)
+	}
+	if deviceType != "" {
+		_ = os.Setenv(commands.DeviceTypeEnvVar, deviceType)
+	}
+	if len(deviceLabels) > 0 {
+		_ = os.Setenv(commands.DeviceLabelsEnvVar, strings.Join(deviceLabels, ","))
+	}
+	if artifactsDir != "" {
+		_ = os.Setenv(commands.ArtifactsDirEnvVar, artifactsDir)
+	}
+
+	// commands that can't be proxied to the remote server must not fall back
+	// to local devices, so local device discovery is turned off
+	if remoteServerURL() != "" {
+		_ = os.Setenv("MOBILECLI_REMOTE_ONLY", "1")
+	}
 }
 
 func init() {
 	cobra.OnInitialize(initConfig)
-	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enable verbose output")
+	rootCmd.PersistentFlags().CountVarP(&verbose, "verbose", "v", "enable verbose output, repeat (-vv) to also log HTTP request and response bodies and external commands")
+	rootCmd.PersistentFlags().StringSliceVar(&logDebug, "log-debug", nil, "enable debug logs of some subsystems only, e.g. wda,adb (one of: "+strings.Join(utils.Subsystems, ", ")+")")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "log every external command and HTTP call with its timing")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print the external commands and HTTP calls instead of running them")
 	rootCmd.PersistentFlags().StringVar(&deviceId, "device", "", "Device ID (get from 'mobilecli devices' command)")
+	rootCmd.PersistentFlags().StringVar(&platform, "platform", "", "only list and auto-select devices of this platform (ios or android)")
+	rootCmd.PersistentFlags().StringVar(&deviceType, "type", "", "only list and auto-select devices of this type (real, simulator or emulator)")
+	rootCmd.PersistentFlags().StringArrayVar(&deviceLabels, "label", nil, "only list and auto-select devices with this key=value label, can be repeated (default: $"+commands.DeviceLabelsEnvVar+")")
+	rootCmd.PersistentFlags().StringSliceVar(&outputFields, "fields", nil, "only print these fields of the JSON output's data, as dot-paths applied to each item of a list, e.g. id,name,agent.running")
+	rootCmd.PersistentFlags().StringVar(&artifactsDir, "artifacts-dir", "", "write screenshots, recordings, bug reports and UI dumps without -o to timestamped files under <dir>/<device-id> (default: $"+commands.ArtifactsDirEnvVar+")")
+	rootCmd.PersistentFlags().StringVar(&adbHost, "adb-host", "", "host of the adb server to use (default: localhost)")
+	rootCmd.PersistentFlags().IntVar(&adbPort, "adb-port", 0, "port of the adb server to use (default: $ANDROID_ADB_SERVER_PORT or 5037)")
+	rootCmd.PersistentFlags().StringVar(&adbKey, "adb-key", "", "private key adb authenticates to devices with besides ~/.android/adbkey, e.g. one preauthorized on a CI image")
+	rootCmd.PersistentFlags().StringVar(&toolPaths.Adb, "adb-path", "", "adb to run, instead of the one in ANDROID_HOME or PATH (default: adb_path in config.json)")
+	rootCmd.PersistentFlags().StringVar(&toolPaths.Emulator, "emulator-path", "", "Android emulator to run, instead of the one in ANDROID_HOME or PATH (default: emulator_path in config.json)")
+	rootCmd.PersistentFlags().StringVar(&toolPaths.Xcrun, "xcrun-path", "", "xcrun to run, instead of the one in PATH (default: xcrun_path in config.json)")
+	rootCmd.PersistentFlags().StringVar(&remoteServer, "remote", "", "send commands to a remote mobilecli server, e.g. farm.example.com:12000, with the stored auth token (default: $"+RemoteServerEnvVar+")")
 	rootCmd.PersistentFlags().BoolVar(&insecureStorage, "insecure-storage", false, "store the auth token in a plaintext file instead of the OS keyring (for headless hosts with no keyring)")
 }
 
@@ -214,6 +465,7 @@ func Execute() error {
 	// enable microseconds in logs
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds)
 
+	registerCompletions(rootCmd)
 	return rootCmd.Execute()
 }
 
@@ -223,5 +475,17 @@ func printJson(data any) {
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	if len(outputFields) > 0 {
+		selected, err := selectOutputFields(jsonData, outputFields)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, selected, "", "  "); err != nil {
+			log.Fatal(err)
+		}
+		jsonData = indented.Bytes()
+	}
 	fmt.Println(string(jsonData))
 }
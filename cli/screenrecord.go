@@ -18,10 +18,6 @@ var screenrecordCmd = &cobra.Command{
 	Short: "Record device screen to an MP4 file",
 	Long:  `Records the screen of a connected device (iOS, Android, or simulator) to an MP4 file.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if screenrecordOutput == "" {
-			return fmt.Errorf("--output is required")
-		}
-
 		req := commands.ScreenRecordRequest{
 			DeviceID:   deviceId,
 			OutputPath: screenrecordOutput,
@@ -30,7 +26,7 @@ var screenrecordCmd = &cobra.Command{
 		}
 
 		response := commands.ScreenRecordCommand(req)
-
+		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
 		}
@@ -42,7 +38,7 @@ var screenrecordCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(screenrecordCmd)
 
-	screenrecordCmd.Flags().StringVarP(&screenrecordOutput, "output", "o", "", "Output MP4 file path")
+	screenrecordCmd.Flags().StringVarP(&screenrecordOutput, "output", "o", "", "Output MP4 file path (default: a timestamped file in --artifacts-dir)")
 	screenrecordCmd.Flags().IntVar(&screenrecordTimeLimit, "time-limit", 0, "Max recording duration in seconds (0 = no limit)")
 	screenrecordCmd.Flags().BoolVar(&screenrecordSilent, "silent", false, "Suppress progress output")
 }
@@ -2,8 +2,11 @@ package cli
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/mobile-next/mobilecli/commands"
 	"github.com/mobile-next/mobilecli/devices"
@@ -12,9 +15,15 @@ import (
 )
 
 var (
+	screencaptureQuality int
 	screencaptureScale   float64
 	screencaptureFPS     int
 	screencaptureBitrate int
+
+	screencaptureThermalGuard    bool
+	screencaptureMaxTemperature  float64
+	screencaptureThermalInterval int
+	screencapturePauseWhenHot    bool
 )
 
 const (
@@ -25,13 +34,24 @@ const (
 var screenshotCmd = &cobra.Command{
 	Use:   "screenshot",
 	Short: "Take a screenshot of a connected device",
-	Long:  `Takes a screenshot of a specified device (using its ID) and saves it locally as a PNG file. Supports iOS (real/simulator) and Android (real/emulator).`,
+	Long:  `Takes a screenshot of a specified device (using its ID) and saves it locally as a PNG file. Supports iOS (real/simulator) and Android (real/emulator). Use --rect or --element to capture only a region of the screen.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		req := commands.ScreenshotRequest{
 			DeviceID:   deviceId,
 			Format:     screenshotFormat,
 			Quality:    screenshotJpegQuality,
 			OutputPath: screenshotOutputPath,
+			Element:    screenshotElement,
+		}
+
+		if screenshotRect != "" {
+			rect, err := parseRect(screenshotRect)
+			if err != nil {
+				response := commands.NewErrorResponse(err)
+				printJson(response)
+				return fmt.Errorf("%s", response.Error)
+			}
+			req.Clip = rect
 		}
 
 		response := commands.ScreenshotCommand(req)
@@ -80,10 +100,31 @@ var screencaptureCmd = &cobra.Command{
 			return fmt.Errorf("%s", response.Error)
 		}
 
+		if err := commands.ValidateScreenCaptureOptions(screencaptureQuality, screencaptureScale, screencaptureFPS); err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		thermal, err := commands.NewThermalGuard(screencaptureThermalGuard, screencaptureMaxTemperature, screencaptureThermalInterval, screencapturePauseWhenHot)
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+		if thermal != nil {
+			// stdout carries the capture, events go to stderr as JSON lines
+			thermal.OnEvent = func(event devices.ThermalEvent) {
+				if line, err := json.Marshal(event); err == nil {
+					fmt.Fprintln(os.Stderr, string(line))
+				}
+			}
+		}
+
 		// Find the target device
 		targetDevice, err := commands.FindDeviceOrAutoSelect(deviceId)
 		if err != nil {
-			response := commands.NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+			response := commands.NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 			printJson(response)
 			return fmt.Errorf("%s", response.Error)
 		}
@@ -102,6 +143,11 @@ var screencaptureCmd = &cobra.Command{
 		}
 
 		// set defaults if not provided
+		quality := screencaptureQuality
+		if quality == 0 {
+			quality = devices.DefaultQuality
+		}
+
 		scale := screencaptureScale
 		if scale == 0.0 {
 			scale = devices.DefaultScale
@@ -113,12 +159,13 @@ var screencaptureCmd = &cobra.Command{
 		}
 
 		// Start screen capture and stream to stdout
-		err = targetDevice.StartScreenCapture(devices.ScreenCaptureConfig{
+		err = devices.StartGuardedScreenCapture(targetDevice, devices.ScreenCaptureConfig{
 			Format:  screencaptureFormat,
-			Quality: devices.DefaultQuality,
+			Quality: quality,
 			Scale:   scale,
 			FPS:     fps,
 			Bitrate: screencaptureBitrate,
+			Thermal: thermal,
 			OnProgress: func(message string) {
 				utils.Verbose(message)
 			},
@@ -142,20 +189,51 @@ var screencaptureCmd = &cobra.Command{
 	},
 }
 
+// parseRect parses a rectangle in the form "x,y,width,height"
+func parseRect(s string) (*devices.ScreenElementRect, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("invalid rect format. Expected 'x,y,width,height', got '%s'", s)
+	}
+
+	values := make([]int, len(parts))
+	for i, part := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("invalid rect values. x, y, width and height must be integers. Got '%s'", s)
+		}
+		values[i] = v
+	}
+
+	if values[2] <= 0 || values[3] <= 0 {
+		return nil, fmt.Errorf("rect width and height must be positive. Got '%s'", s)
+	}
+
+	return &devices.ScreenElementRect{X: values[0], Y: values[1], Width: values[2], Height: values[3]}, nil
+}
+
 func init() {
 	rootCmd.AddCommand(screenshotCmd)
 	rootCmd.AddCommand(screencaptureCmd)
 
 	// screenshot command flags
 	screenshotCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to take screenshot from")
-	screenshotCmd.Flags().StringVarP(&screenshotOutputPath, "output", "o", "", "Output file path for screenshot (e.g., screen.png, or '-' for stdout)")
+	screenshotCmd.Flags().StringVarP(&screenshotOutputPath, "output", "o", "", "Output file path for screenshot (e.g., screen.png, or '-' for stdout; default: a timestamped file in the current directory or --artifacts-dir)")
 	screenshotCmd.Flags().StringVarP(&screenshotFormat, "format", "f", "png", "Output format for screenshot (png or jpeg)")
 	screenshotCmd.Flags().IntVarP(&screenshotJpegQuality, "quality", "q", 90, "JPEG quality (1-100, only applies if format is jpeg)")
+	screenshotCmd.Flags().StringVar(&screenshotRect, "rect", "", "Crop to a region in screen coordinates, as 'x,y,width,height'")
+	screenshotCmd.Flags().StringVar(&screenshotElement, "element", "", "Crop to the first element matching a selector (e.g. 'identifier=loginButton')")
+	screenshotCmd.MarkFlagsMutuallyExclusive("rect", "element")
 
 	// screencapture command flags
 	screencaptureCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to capture from")
 	screencaptureCmd.Flags().StringVarP(&screencaptureFormat, "format", "f", "mjpeg", "Output format for screen capture")
-	screencaptureCmd.Flags().Float64Var(&screencaptureScale, "scale", 0, "Scale factor for screen capture (0 for default)")
-	screencaptureCmd.Flags().IntVar(&screencaptureFPS, "fps", 0, "Frames per second for screen capture (0 for default)")
+	screencaptureCmd.Flags().IntVar(&screencaptureQuality, "quality", 0, "JPEG quality for MJPEG capture (1-100, 0 for default)")
+	screencaptureCmd.Flags().Float64Var(&screencaptureScale, "scale", 0, "Scale factor for screen capture (0.1-1.0, 0 for default)")
+	screencaptureCmd.Flags().IntVar(&screencaptureFPS, "fps", 0, "Frames per second for screen capture (1-60, 0 for default)")
 	screencaptureCmd.Flags().IntVar(&screencaptureBitrate, "bitrate", 0, "Bitrate in bits per second for AVC capture (100000-10000000, 0 for default)")
+	screencaptureCmd.Flags().BoolVar(&screencaptureThermalGuard, "thermal-guard", false, "watch the device's temperature and warn on stderr when it gets hot")
+	screencaptureCmd.Flags().Float64Var(&screencaptureMaxTemperature, "max-temperature", 0, "battery temperature in degrees Celsius at which the device counts as hot (default 45, implies --thermal-guard)")
+	screencaptureCmd.Flags().IntVar(&screencaptureThermalInterval, "thermal-interval", 0, "seconds between temperature samples (default 30, implies --thermal-guard)")
+	screencaptureCmd.Flags().BoolVar(&screencapturePauseWhenHot, "pause-when-hot", false, "drop frames while the device is hot, resuming once it has cooled down (implies --thermal-guard)")
 }
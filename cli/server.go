@@ -2,7 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/mobile-next/mobilecli/commands"
 	"github.com/mobile-next/mobilecli/daemon"
 	"github.com/mobile-next/mobilecli/server"
 	"github.com/spf13/cobra"
@@ -19,8 +23,14 @@ var serverCmd = &cobra.Command{
 var serverStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the mobilecli server",
-	Long:  `Starts the mobilecli server.`,
-	Args:  cobra.NoArgs,
+	Long: `Starts the mobilecli server.
+
+With --stdio, the server speaks JSON-RPC over stdin and stdout instead of
+listening on a port, so IDEs and agent frameworks can embed it as a
+subprocess. Each request is a line of JSON, or is framed LSP-style with a
+Content-Length header, and is answered the same way. The server exits when
+stdin is closed.`,
+	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		listenAddr := cmd.Flag("listen").Value.String()
 		if listenAddr == "" {
@@ -29,7 +39,31 @@ var serverStartCmd = &cobra.Command{
 
 		// GetBool/GetString cannot fail for defined flags
 		enableCORS, _ := cmd.Flags().GetBool("cors")
+		enableWebDriver, _ := cmd.Flags().GetBool("webdriver")
 		isDaemon, _ := cmd.Flags().GetBool("daemon")
+		stayAwake, _ := cmd.Flags().GetBool("stay-awake")
+		readyMinDevices, _ := cmd.Flags().GetInt("ready-min-devices")
+		screenshotCacheMs, _ := cmd.Flags().GetInt("screenshot-cache-ms")
+		dumpCacheMs, _ := cmd.Flags().GetInt("dump-cache-ms")
+		stdio, _ := cmd.Flags().GetBool("stdio")
+		handleDialogs, _ := cmd.Flags().GetString("handle-dialogs")
+		grpcAddr, _ := cmd.Flags().GetString("grpc")
+
+		if stdio && isDaemon {
+			return fmt.Errorf("--stdio cannot be used with --daemon")
+		}
+		if stdio && grpcAddr != "" {
+			return fmt.Errorf("--stdio cannot be used with --grpc")
+		}
+
+		var dialogChoices commands.DialogChoices
+		if handleDialogs != "" {
+			var err error
+			dialogChoices, err = commands.ParseDialogChoices(handleDialogs)
+			if err != nil {
+				return err
+			}
+		}
 
 		if isDaemon && !daemon.IsChild() {
 			_, err := daemon.Daemonize()
@@ -41,7 +75,22 @@ var serverStartCmd = &cobra.Command{
 			return nil
 		}
 
-		return server.StartServer(listenAddr, enableCORS)
+		commands.SetStayAwakeEnforced(stayAwake)
+		server.SetReadinessMinDevices(readyMinDevices)
+		server.SetScreenshotCacheTTL(time.Duration(screenshotCacheMs) * time.Millisecond)
+		commands.SetDumpCacheTTL(time.Duration(dumpCacheMs) * time.Millisecond)
+		commands.SetDialogHandler(dialogChoices)
+		server.SetGRPCAddress(grpcAddr)
+		daemon.RegisterInvokeMethod()
+
+		if stdio {
+			// stdout only carries JSON-RPC messages, anything else printed
+			// to it goes to stderr
+			out := os.Stdout
+			os.Stdout = os.Stderr
+			return server.StartStdioServer(os.Stdin, out)
+		}
+		return server.StartServer(listenAddr, enableCORS, enableWebDriver)
 	},
 }
 
@@ -67,17 +116,113 @@ var serverKillCmd = &cobra.Command{
 	},
 }
 
+var serverStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check whether a mobilecli server is live and ready",
+	Long: `Queries the server's /healthz (liveness) and /readyz (readiness) endpoints.
+Readiness requires a reachable adb server when adb is installed, and as many
+online devices as the server was started with --ready-min-devices. Exits with
+an error when the server is not running or not ready.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// GetString cannot fail for defined flags
+		addr, _ := cmd.Flags().GetString("listen")
+		if addr == "" {
+			addr = defaultServerAddress
+		}
+
+		status, err := daemon.GetServerStatus(addr)
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		printJson(commands.NewSuccessResponse(status))
+		if !status.Ready {
+			return fmt.Errorf("server on %s is not ready", addr)
+		}
+		return nil
+	},
+}
+
+// clientFiles are the files 'server clientgen' writes for each language
+var clientFiles = map[string]string{
+	server.ClientLanguageTypeScript: "mobilecli.ts",
+	server.ClientLanguagePython:     "mobilecli_client.py",
+}
+
+var serverClientgenCmd = &cobra.Command{
+	Use:   "clientgen",
+	Short: "Generate TypeScript and Python clients for the server",
+	Long: `Generates client SDKs with a typed method for every JSON-RPC method of the
+server, from the same params structs the server decodes. The TypeScript client
+(mobilecli.ts) uses fetch, the Python client (mobilecli_client.py) only the
+standard library.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// GetString cannot fail for defined flags
+		language, _ := cmd.Flags().GetString("lang")
+		outputDir, _ := cmd.Flags().GetString("output")
+
+		languages := []string{server.ClientLanguageTypeScript, server.ClientLanguagePython}
+		if language != "all" {
+			if _, ok := clientFiles[language]; !ok {
+				return fmt.Errorf("unsupported language '%s', must be 'typescript', 'python' or 'all'", language)
+			}
+			languages = []string{language}
+		}
+
+		if err := os.MkdirAll(outputDir, 0o755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+
+		for _, language := range languages {
+			source, err := server.GenerateClient(language)
+			if err != nil {
+				return err
+			}
+
+			path := filepath.Join(outputDir, clientFiles[language])
+			if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			fmt.Printf("Wrote %s client to %s\n", language, path)
+		}
+
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(serverCmd)
 
 	// add server subcommands
 	serverCmd.AddCommand(serverStartCmd)
 	serverCmd.AddCommand(serverKillCmd)
+	serverCmd.AddCommand(serverStatusCmd)
+	serverCmd.AddCommand(serverClientgenCmd)
 
 	// server start flags
 	serverStartCmd.Flags().String("listen", "", "Address to listen on (e.g., 'localhost:12000' or '0.0.0.0:13000')")
 	serverStartCmd.Flags().Bool("cors", false, "Enable CORS support")
+	serverStartCmd.Flags().Bool("webdriver", false, "Also serve a minimal W3C WebDriver endpoint (at / and /wd/hub) for WebDriver clients")
 	serverStartCmd.Flags().BoolP("daemon", "d", false, "Run server in daemon mode (background)")
+	serverStartCmd.Flags().Int("ready-min-devices", 0, "Report the server as not ready on /readyz until this many devices are online")
+	serverStartCmd.Flags().Int("screenshot-cache-ms", 0, "Serve screenshots of a device from a capture taken within this many milliseconds, until an input command is sent to it (0 disables)")
+	serverStartCmd.Flags().Int("dump-cache-ms", 0, "Reuse a UI dump of a device made within this many milliseconds while its screen looks the same, until an input command is sent to it (0 disables)")
+	serverStartCmd.Flags().String("grpc", "", "Also serve the gRPC API on this address, e.g. ':12002'")
+	serverStartCmd.Flags().Bool("stdio", false, "Serve JSON-RPC over stdin and stdout instead of listening on a port")
+	serverStartCmd.Flags().String("handle-dialogs", "", "Dismiss Android ANR, crash and permission dialogs before input commands and UI dumps, optionally tapping these choices, e.g. --handle-dialogs=anr=close (default "+commands.DefaultDialogChoices+")")
+	serverStartCmd.Flags().Lookup("handle-dialogs").NoOptDefVal = "default"
+	serverStartCmd.Flags().Bool("stay-awake", false, "Keep the screens of devices the server controls on while plugged in, restoring their settings on shutdown (not real iOS devices)")
+
+	// server clientgen flags
+	serverClientgenCmd.Flags().String("lang", "all", "Client language: typescript, python or all")
+	serverClientgenCmd.Flags().StringP("output", "o", ".", "Directory to write the clients to")
+
+	// server status flags
+	serverStatusCmd.Flags().String("listen", "", fmt.Sprintf("Address of server to check (default: %s)", defaultServerAddress))
 
 	// server kill flags
 	serverKillCmd.Flags().String("listen", "", fmt.Sprintf("Address of server to kill (default: %s)", defaultServerAddress))
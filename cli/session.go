@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sessionScreenshots bool
+	sessionTiming      bool
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Record and replay sessions of commands",
+	Long:  `Records the commands run on a device into a session file, and replays it on the same or another device.`,
+}
+
+var sessionRecordCmd = &cobra.Command{
+	Use:   "record [file]",
+	Short: "Start recording the commands that follow into a session file",
+	Long: `Starts recording every command that follows on the device, with its parameters, timestamp and outcome, until 'mobilecli session stop' writes the session file. Each device records its own session, commands on other devices aren't recorded.
+Commands are journaled next to the session file before they run, so a session survives a crash.
+Actions (taps, swipes, text, ...) are captured with screenshots before and after them, saved in a directory next to the session file.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SessionRecordRequest{
+			DeviceID:    deviceId,
+			Output:      args[0],
+			Screenshots: sessionScreenshots,
+		}
+
+		response := commands.SessionRecordCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var sessionStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop recording and write the session file",
+	Long:  `Stops recording the session of a device and writes its session file. The device may be left out when only one session is being recorded.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SessionStopRequest{
+			DeviceID: deviceId,
+		}
+
+		response := commands.SessionStopCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+var sessionReplayCmd = &cobra.Command{
+	Use:   "replay [file]",
+	Short: "Replay the actions of a recorded session",
+	Long:  `Re-runs the actions of a session file on a device, the recorded device by default, stopping at the first action that fails. Read-only commands and commands that failed while recording are skipped.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SessionReplayRequest{
+			Path:     args[0],
+			DeviceID: deviceId,
+			Timing:   sessionTiming,
+		}
+
+		response := commands.SessionReplayCommand(req)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+
+	sessionCmd.AddCommand(sessionRecordCmd)
+	sessionCmd.AddCommand(sessionStopCmd)
+	sessionCmd.AddCommand(sessionReplayCmd)
+
+	sessionRecordCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to record")
+	sessionStopCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device whose session to stop")
+
+	sessionRecordCmd.Flags().BoolVar(&sessionScreenshots, "screenshots", true, "Capture screenshots before and after every action")
+
+	sessionReplayCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to replay on (default: the recorded device)")
+	sessionReplayCmd.Flags().BoolVar(&sessionTiming, "timing", false, "Wait between actions as long as when they were recorded")
+}
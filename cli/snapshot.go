@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotFormat  string
+	snapshotQuality int
+	snapshotQuery   string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Capture a screenshot and the UI tree together",
+	Long:  `Captures a screenshot and dumps the UI tree at the same time, so both describe the same screen, and returns them in one JSON payload with the foreground app and orientation. skewMs reports how far apart the screenshot and the dump completed. Use --query to only include matching elements, see 'mobilecli dump ui --help'.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		req := commands.SnapshotRequest{
+			DeviceID: deviceId,
+			Format:   snapshotFormat,
+			Quality:  snapshotQuality,
+			Query:    snapshotQuery,
+		}
+
+		response := runCommand("snapshot", req, commands.SnapshotCommand)
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to capture")
+	snapshotCmd.Flags().StringVarP(&snapshotFormat, "format", "f", "png", "Screenshot format (png or jpeg)")
+	snapshotCmd.Flags().IntVarP(&snapshotQuality, "quality", "q", 90, "JPEG quality (1-100, only applies if format is jpeg)")
+	snapshotCmd.Flags().StringVar(&snapshotQuery, "query", "", "only include elements matching this query, e.g. \"type=Button\"")
+}
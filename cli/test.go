@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/spf13/cobra"
+)
+
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run tests on devices",
+	Long:  `Commands for running tests on devices.`,
+}
+
+var testRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Run Android instrumentation tests or iOS XCUITests",
+	Long: `On Android, installs the app and test apks, when given, and runs their
+instrumentation tests with 'am instrument'. The instrumentation runner is read
+from the test apk, or given with --runner.
+
+On iOS, runs prebuilt XCUITests from an .xctestrun file, as left by xcodebuild
+build-for-testing, or from a runner app (--test-app MyAppUITests-Runner.app)
+with the app under test (--app). Real devices run them through testmanagerd,
+simulators with xcodebuild test-without-building. On real devices, the apps an
+.xctestrun names must already be installed.
+
+Each test is printed to stderr as a JSON line when it starts and finishes, and
+the JSON result lists every test with its status, duration and failure. Exits 1
+when a test failed or the run ended early, e.g. because the app crashed. With
+--junit, a JUnit XML report is also written for CI systems.`,
+	Example: `  mobilecli test run --device <device-id> --apk app.apk --test-apk app-androidTest.apk
+  mobilecli test run --device <device-id> --test-apk app-androidTest.apk --class com.example.FooTest#testLogin --junit report.xml
+  mobilecli test run --device <device-id> --xctestrun MyApp_iphonesimulator18.0-arm64.xctestrun
+  mobilecli test run --device <device-id> --app MyApp.app --test-app MyAppUITests-Runner.app --class LoginTests/testLogin`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		runnerArgs := make(map[string]string, len(testRunArgs))
+		for _, arg := range testRunArgs {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid --arg '%s', expected key=value", arg)
+			}
+			runnerArgs[key] = value
+		}
+
+		events := json.NewEncoder(os.Stderr)
+		response := commands.TestRunCommand(commands.TestRunRequest{
+			DeviceID:    deviceId,
+			AppPath:     testRunApk,
+			TestAppPath: testRunTestApk,
+			XCTestRun:   testRunXCTestRun,
+			Runner:      testRunRunner,
+			Class:       strings.Join(testRunClasses, ","),
+			Args:        runnerArgs,
+			JUnitPath:   testRunJUnit,
+			OnEvent: func(event devices.TestEvent) {
+				_ = events.Encode(event)
+			},
+		})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	testCmd.AddCommand(testRunCmd)
+
+	testRunCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to run the tests on")
+	testRunCmd.Flags().StringVar(&testRunApk, "apk", "", "App under test, installed before the tests run")
+	testRunCmd.Flags().StringVar(&testRunTestApk, "test-apk", "", "Test apk, installed before the tests run")
+	testRunCmd.Flags().StringVar(&testRunApk, "app", "", "App under test of XCUITests (.app or .ipa)")
+	testRunCmd.Flags().StringVar(&testRunTestApk, "test-app", "", "XCUITest runner app, e.g. MyAppUITests-Runner.app")
+	testRunCmd.Flags().StringVar(&testRunXCTestRun, "xctestrun", "", "XCUITest .xctestrun file, instead of a runner app")
+	testRunCmd.Flags().StringVar(&testRunRunner, "runner", "", "Instrumentation runner as package/class (default: read from the test apk), Android only")
+	testRunCmd.Flags().StringSliceVar(&testRunClasses, "class", nil, "Only run these test classes or Class#method, Class/method on iOS (repeatable)")
+	testRunCmd.Flags().StringArrayVar(&testRunArgs, "arg", nil, "Instrumentation argument as key=value, e.g. size=small (repeatable), Android only")
+	testRunCmd.Flags().StringVar(&testRunJUnit, "junit", "", "Also write a JUnit XML report to this file")
+}
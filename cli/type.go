@@ -0,0 +1,250 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var typeInteractive bool
+
+var ioTypeCmd = &cobra.Command{
+	Use:   "type",
+	Short: "Type on a device from the local keyboard",
+	Long: `With --interactive, forwards keystrokes from the terminal to the device as they are typed: characters are typed into the focused element, and backspace, enter, tab, escape, the arrow keys, home, end, delete, page up and page down are pressed as keys. This fills long forms by hand without an 'io text' invocation per field.
+
+Press ctrl+d or ctrl+c to stop. When stdin isn't a terminal, e.g. a pipe, it's forwarded until it ends.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !typeInteractive {
+			return fmt.Errorf("io type requires --interactive, use 'io text' to send text")
+		}
+
+		session, err := commands.StartTypeSession(commands.TypeRequest{
+			DeviceID:       deviceId,
+			EnsureUnlocked: ensureUnlockedFlag,
+		})
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		err = forwardKeystrokes(os.Stdin, session)
+		if err != nil {
+			response := commands.NewErrorResponse(err)
+			printJson(response)
+			return fmt.Errorf("%s", response.Error)
+		}
+
+		printJson(commands.NewSuccessResponse(session.Result()))
+		return nil
+	},
+}
+
+// forwardKeystrokes reads keystrokes from in and sends them to the device
+// until ctrl+c, ctrl+d or the end of input. A terminal is put into raw mode
+// meanwhile, so keys arrive as they are pressed.
+func forwardKeystrokes(in *os.File, session *commands.TypeSession) error {
+	if utils.IsTerminal(in) {
+		restore, err := utils.MakeTerminalRaw(in)
+		if err != nil {
+			return fmt.Errorf("failed to read keystrokes from the terminal: %v", err)
+		}
+		defer func() { _ = restore() }()
+
+		fmt.Fprintf(os.Stderr, "Typing on device %s, press ctrl+d to stop\n", session.DeviceID())
+	}
+
+	buf := make([]byte, 256)
+	var pending []byte
+	for {
+		n, err := in.Read(buf)
+		if n > 0 {
+			events, rest, done := decodeKeystrokes(append(pending, buf[:n]...))
+			pending = rest
+			for _, event := range events {
+				if err := session.Send(event); err != nil {
+					return err
+				}
+			}
+			if done {
+				return nil
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read keystrokes: %v", err)
+		}
+	}
+}
+
+// Control bytes of a terminal in raw mode
+const (
+	keyCtrlC     = 0x03
+	keyCtrlD     = 0x04
+	keyBackspace = 0x08
+	keyTab       = '\t'
+	keyNewline   = '\n'
+	keyReturn    = '\r'
+	keyEscape    = 0x1b
+	keyDelete    = 0x7f
+)
+
+// escapeSequenceKeys maps the escape sequences terminals send for special
+// keys, without their leading ESC, to the names the devices press
+var escapeSequenceKeys = map[string]string{
+	"[A":  "up",
+	"[B":  "down",
+	"[C":  "right",
+	"[D":  "left",
+	"OA":  "up",
+	"OB":  "down",
+	"OC":  "right",
+	"OD":  "left",
+	"[H":  "home",
+	"[F":  "end",
+	"OH":  "home",
+	"OF":  "end",
+	"[1~": "home",
+	"[7~": "home",
+	"[4~": "end",
+	"[8~": "end",
+	"[3~": "forwarddelete",
+	"[5~": "pageup",
+	"[6~": "pagedown",
+}
+
+// decodeKeystrokes splits raw terminal input into key events, merging runs
+// of characters into one event. It returns the bytes at the end of buf that
+// begin an escape sequence or character the next read completes, and
+// whether ctrl+c or ctrl+d ended the input. Keys devices have no name for
+// are dropped.
+func decodeKeystrokes(buf []byte) (events []commands.KeyEvent, rest []byte, done bool) {
+	var text []rune
+	flush := func() {
+		if len(text) > 0 {
+			events = append(events, commands.KeyEvent{Text: string(text)})
+			text = nil
+		}
+	}
+	press := func(key string) {
+		flush()
+		events = append(events, commands.KeyEvent{Key: key})
+	}
+
+	for i := 0; i < len(buf); {
+		b := buf[i]
+		switch {
+		case b == keyCtrlC || b == keyCtrlD:
+			flush()
+			return events, nil, true
+
+		case b == keyDelete || b == keyBackspace:
+			press("backspace")
+			i++
+
+		case b == keyReturn:
+			press("enter")
+			i++
+			// a pasted windows line ending is one enter
+			if i < len(buf) && buf[i] == keyNewline {
+				i++
+			}
+
+		case b == keyNewline:
+			press("enter")
+			i++
+
+		case b == keyTab:
+			press("tab")
+			i++
+
+		case b == keyEscape:
+			// a lone ESC at the end of a read is the escape key, as terminals
+			// write a whole sequence at once
+			if i+1 == len(buf) {
+				press("escape")
+				i++
+				continue
+			}
+
+			length, complete := escapeSequenceLength(buf[i+1:])
+			if !complete {
+				flush()
+				return events, append([]byte(nil), buf[i:]...), false
+			}
+			if length == 0 {
+				press("escape")
+				i++
+				continue
+			}
+
+			if key, ok := escapeSequenceKeys[string(buf[i+1:i+1+length])]; ok {
+				press(key)
+			}
+			i += 1 + length
+
+		case b < 0x20:
+			// other control keys have no counterpart on the device
+			i++
+
+		default:
+			if !utf8.FullRune(buf[i:]) {
+				flush()
+				return events, append([]byte(nil), buf[i:]...), false
+			}
+			r, size := utf8.DecodeRune(buf[i:])
+			if r != utf8.RuneError && unicode.IsPrint(r) {
+				text = append(text, r)
+			}
+			i += size
+		}
+	}
+
+	flush()
+	return events, nil, false
+}
+
+// escapeSequenceLength returns the length of the escape sequence seq begins
+// with, after its ESC, or 0 when ESC was pressed on its own before seq. It's
+// incomplete when seq ends before the sequence does.
+func escapeSequenceLength(seq []byte) (int, bool) {
+	switch seq[0] {
+	case 'O':
+		// SS3, e.g. ESC O A for up in application cursor mode
+		if len(seq) < 2 {
+			return 0, false
+		}
+		return 2, true
+
+	case '[':
+		// CSI, parameters and intermediates end at a final byte in @ to ~
+		for i := 1; i < len(seq); i++ {
+			if seq[i] >= 0x40 && seq[i] <= 0x7e {
+				return i + 1, true
+			}
+		}
+		return 0, false
+
+	default:
+		return 0, true
+	}
+}
+
+func init() {
+	ioCmd.AddCommand(ioTypeCmd)
+
+	ioTypeCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to type on")
+	ioTypeCmd.Flags().BoolVar(&typeInteractive, "interactive", false, "forward keystrokes from the terminal to the device as they are typed")
+	ioTypeCmd.Flags().BoolVar(&ensureUnlockedFlag, "ensure-unlocked", false, "wake the device and dismiss the lock screen first if needed")
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/commands"
+)
+
+func TestDecodeKeystrokes(t *testing.T) {
+	text := func(s string) commands.KeyEvent { return commands.KeyEvent{Text: s} }
+	key := func(k string) commands.KeyEvent { return commands.KeyEvent{Key: k} }
+
+	cases := []struct {
+		input  string
+		events []commands.KeyEvent
+		rest   string
+		done   bool
+	}{
+		{"hello", []commands.KeyEvent{text("hello")}, "", false},
+		{"héllo wörld", []commands.KeyEvent{text("héllo wörld")}, "", false},
+		{"ab\x7fc\r", []commands.KeyEvent{text("ab"), key("backspace"), text("c"), key("enter")}, "", false},
+		{"a\r\nb\n", []commands.KeyEvent{text("a"), key("enter"), text("b"), key("enter")}, "", false},
+		{"\tx", []commands.KeyEvent{key("tab"), text("x")}, "", false},
+		{"\x1b[A\x1b[B\x1b[C\x1b[D", []commands.KeyEvent{key("up"), key("down"), key("right"), key("left")}, "", false},
+		{"\x1bOA\x1b[H\x1b[4~\x1b[3~", []commands.KeyEvent{key("up"), key("home"), key("end"), key("forwarddelete")}, "", false},
+		{"\x1b", []commands.KeyEvent{key("escape")}, "", false},
+		{"\x1bx", []commands.KeyEvent{key("escape"), text("x")}, "", false},
+		// unknown sequences, e.g. shift+up, are dropped whole
+		{"a\x1b[1;2Ab", []commands.KeyEvent{text("ab")}, "", false},
+		{"a\x1b[", []commands.KeyEvent{text("a")}, "\x1b[", false},
+		{"a\xc3", []commands.KeyEvent{text("a")}, "\xc3", false},
+		{"ab\x04cd", []commands.KeyEvent{text("ab")}, "", true},
+		{"\x03", nil, "", true},
+		{"a\x01b", []commands.KeyEvent{text("ab")}, "", false},
+	}
+
+	for _, c := range cases {
+		events, rest, done := decodeKeystrokes([]byte(c.input))
+		if !reflect.DeepEqual(events, c.events) || string(rest) != c.rest || done != c.done {
+			t.Errorf("decodeKeystrokes(%q) = %v, %q, %v, want %v, %q, %v", c.input, events, rest, done, c.events, c.rest, c.done)
+		}
+	}
+}
+
+func TestDecodeKeystrokesAcrossReads(t *testing.T) {
+	events, rest, _ := decodeKeystrokes([]byte("x\x1b[3"))
+	events2, rest, _ := decodeKeystrokes(append(rest, '~'))
+
+	want := []commands.KeyEvent{{Text: "x"}, {Key: "forwarddelete"}}
+	if got := append(events, events2...); !reflect.DeepEqual(got, want) || len(rest) != 0 {
+		t.Errorf("got %v with %q left, want %v", got, rest, want)
+	}
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/server"
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// mobilecliRepo is the GitHub repository mobilecli is released from
+	mobilecliRepo = "mobile-next/mobilecli"
+
+	// releaseChecksumsAsset is the release asset listing the SHA-256 of the
+	// other assets
+	releaseChecksumsAsset = "checksums.txt"
+)
+
+// UpdateMirrorEnvVar sets a GitHub-compatible releases API that 'update'
+// looks for releases on instead of GitHub, as --mirror does
+const UpdateMirrorEnvVar = "MOBILECLI_UPDATE_MIRROR"
+
+type updateResponse struct {
+	Message         string `json:"message"`
+	Channel         string `json:"channel"`
+	CurrentVersion  string `json:"currentVersion"`
+	LatestVersion   string `json:"latestVersion"`
+	UpdateAvailable bool   `json:"updateAvailable"`
+	Updated         bool   `json:"updated"`
+	Path            string `json:"path,omitempty"`
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update mobilecli to the latest release",
+	Long: `Replaces this mobilecli with the latest release of a channel: stable, or beta which includes prereleases. The release archive for this platform is downloaded, checked against the SHA-256 in the release's checksums.txt, and swapped in with a single rename, so an interrupted update leaves the old binary in place.
+
+Releases are looked up on GitHub, or on a GitHub-compatible mirror set with --mirror or $` + UpdateMirrorEnvVar + `.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if updateChannel != "stable" && updateChannel != "beta" {
+			return fmt.Errorf("invalid channel '%s', must be 'stable' or 'beta'", updateChannel)
+		}
+
+		mirror := updateMirror
+		if mirror == "" {
+			mirror = os.Getenv(UpdateMirrorEnvVar)
+		}
+
+		release, err := utils.GetLatestReleaseFrom(mirror, mobilecliRepo, updateChannel == "beta")
+		if err != nil {
+			return err
+		}
+
+		result := updateResponse{
+			Channel:        updateChannel,
+			CurrentVersion: strings.TrimPrefix(server.Version, "v"),
+			LatestVersion:  strings.TrimPrefix(release.TagName, "v"),
+		}
+		// only a strictly newer release is offered, so a beta or development
+		// build isn't replaced with an older stable release
+		comparison, comparable := utils.CompareSemver(result.LatestVersion, result.CurrentVersion)
+		result.UpdateAvailable = comparable && comparison > 0
+
+		switch {
+		case !comparable:
+			result.Message = fmt.Sprintf("mobilecli %s can't be compared with release %s, install a release to update", result.CurrentVersion, result.LatestVersion)
+		case comparison < 0:
+			result.Message = fmt.Sprintf("mobilecli %s is newer than the latest %s release %s, not downgrading", result.CurrentVersion, updateChannel, result.LatestVersion)
+		case !result.UpdateAvailable:
+			result.Message = fmt.Sprintf("mobilecli %s is up to date", result.CurrentVersion)
+		case updateCheck:
+			result.Message = fmt.Sprintf("mobilecli %s is available, run 'mobilecli update' to install it", result.LatestVersion)
+		default:
+			path, err := installRelease(release)
+			if err != nil {
+				return err
+			}
+			result.Updated = true
+			result.Path = path
+			result.Message = fmt.Sprintf("Updated mobilecli from %s to %s", result.CurrentVersion, result.LatestVersion)
+		}
+
+		printJson(commands.NewSuccessResponse(result))
+		return nil
+	},
+}
+
+// releaseAssetName names the release archive of a platform, as the release
+// workflow zips it
+func releaseAssetName(tag, goos, goarch string) string {
+	if goos == "darwin" {
+		goos = "macos"
+	}
+	return fmt.Sprintf("mobilecli-%s-%s-%s.zip", tag, goos, goarch)
+}
+
+// installRelease downloads a release for this platform, verifies it and
+// replaces the running executable with it, returning the executable's path
+func installRelease(release *utils.GitHubRelease) (string, error) {
+	assetName := releaseAssetName(release.TagName, runtime.GOOS, runtime.GOARCH)
+	assetURL, ok := release.AssetURL(assetName)
+	if !ok {
+		return "", fmt.Errorf("release %s has no build for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	// an unverifiable release is never installed
+	checksumsURL, ok := release.AssetURL(releaseChecksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s to verify the download with", release.TagName, releaseChecksumsAsset)
+	}
+
+	target, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the mobilecli executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(target); err == nil {
+		target = resolved
+	}
+
+	tmpDir, err := os.MkdirTemp("", "mobilecli-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	checksumsPath := filepath.Join(tmpDir, releaseChecksumsAsset)
+	if err := utils.DownloadFile(checksumsURL, checksumsPath); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", releaseChecksumsAsset, err)
+	}
+	checksumsData, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return "", err
+	}
+	expectedHash, ok := utils.ParseChecksums(string(checksumsData))[assetName]
+	if !ok {
+		return "", fmt.Errorf("%s of release %s has no checksum for %s", releaseChecksumsAsset, release.TagName, assetName)
+	}
+
+	archivePath := filepath.Join(tmpDir, assetName)
+	utils.Verbose("downloading %s", assetURL)
+	if err := utils.DownloadFile(assetURL, archivePath); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", assetName, err)
+	}
+
+	actualHash, err := utils.SHA256File(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	if actualHash != expectedHash {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expectedHash, actualHash)
+	}
+	utils.Verbose("checksum verified for %s", assetName)
+
+	extracted, err := utils.Unzip(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(extracted) }()
+
+	binaryName := "mobilecli"
+	if runtime.GOOS == "windows" {
+		binaryName += ".exe"
+	}
+
+	if err := utils.ReplaceExecutable(target, filepath.Join(extracted, binaryName)); err != nil {
+		return "", fmt.Errorf("failed to replace %s: %w", target, err)
+	}
+	return target, nil
+}
+
+func init() {
+	rootCmd.AddCommand(updateCmd)
+
+	updateCmd.Flags().StringVar(&updateChannel, "channel", "stable", "release channel to update from: stable or beta")
+	updateCmd.Flags().StringVar(&updateMirror, "mirror", "", "GitHub-compatible releases API to look for releases on, e.g. https://ghe.example.com/api/v3 (default: $"+UpdateMirrorEnvVar+")")
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, "only report whether an update is available")
+}
@@ -18,7 +18,7 @@ var urlCmd = &cobra.Command{
 			URL:      args[0],
 		}
 
-		response := commands.URLCommand(req)
+		response := runCommand("url", req, commands.URLCommand)
 		printJson(response)
 		if response.Status == "error" {
 			return fmt.Errorf("%s", response.Error)
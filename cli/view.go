@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/daemon"
+	"github.com/mobile-next/mobilecli/server"
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/spf13/cobra"
+)
+
+var viewCmd = &cobra.Command{
+	Use:   "view",
+	Short: "Mirror a device's screen in a browser window",
+	Long: `Opens a browser window showing the device's live screen. Clicks are forwarded as taps, drags as swipes and key presses as text, mapped through the scaling of the stream.
+The viewer is served by the mobilecli server on --listen, which is started in the foreground when it isn't already running.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// GetString/GetBool cannot fail for defined flags
+		listenAddr, _ := cmd.Flags().GetString("listen")
+		openBrowser, _ := cmd.Flags().GetBool("open")
+
+		targetDevice, err := commands.FindDeviceOrAutoSelect(deviceId)
+		if err != nil {
+			return fmt.Errorf("error finding device: %w", err)
+		}
+
+		viewURL := fmt.Sprintf("http://%s/view/%s", listenAddr, url.PathEscape(targetDevice.ID()))
+		open := func() {
+			fmt.Printf("Viewing device %s at %s\n", targetDevice.ID(), viewURL)
+			if !openBrowser {
+				return
+			}
+			if err := utils.OpenBrowser(viewURL); err != nil {
+				utils.Info("failed to open a browser, open %s instead: %v", viewURL, err)
+			}
+		}
+
+		if _, err := daemon.GetServerStatus(listenAddr); err == nil {
+			open()
+			return nil
+		}
+
+		// open the page once the server started below answers
+		go func() {
+			for range 50 {
+				time.Sleep(100 * time.Millisecond)
+				if _, err := daemon.GetServerStatus(listenAddr); err == nil {
+					open()
+					return
+				}
+			}
+		}()
+
+		daemon.RegisterInvokeMethod()
+		return server.StartServer(listenAddr, false, false)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(viewCmd)
+
+	viewCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device to view")
+	viewCmd.Flags().String("listen", defaultServerAddress, "address of the mobilecli server serving the viewer")
+	viewCmd.Flags().Bool("open", true, "open the viewer in the default browser, otherwise only print its URL")
+}
@@ -16,8 +16,20 @@ var webviewCmd = &cobra.Command{
 var webviewListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List embedded webviews on a device",
-	Long:  `Returns all embedded webviews currently visible in the foreground app. Browser apps (Safari, Chrome) are not included.`,
+	Long: `Returns all embedded webviews currently visible in the foreground app. Browser apps (Safari, Chrome) are not included.
+With --devtools, lists the browser tabs and inspectable webviews of all apps instead, which 'webview dump' and 'webview eval --target' inspect over the Chrome DevTools protocol on Android and the WebKit remote inspector on iOS.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if webviewDevTools {
+			response := commands.DevToolsListCommand(commands.DevToolsListRequest{
+				DeviceID: deviceId,
+			})
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		response := commands.WebViewListCommand(commands.WebViewListRequest{
 			DeviceID: deviceId,
 		})
@@ -105,9 +117,28 @@ var webviewForwardCmd = &cobra.Command{
 var webviewEvalCmd = &cobra.Command{
 	Use:   "eval <id> <expression>",
 	Short: "Evaluate JavaScript in a webview",
-	Long:  `Evaluates a JavaScript expression in the context of the specified webview and returns the result.`,
-	Args:  cobra.ExactArgs(2),
+	Long: `Evaluates a JavaScript expression in the context of the specified webview and returns the result.
+With --target, evaluates it in a target from 'webview list --devtools' instead, e.g. a Safari or Chrome tab: mobilecli webview eval --target <target> "document.title"`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if webviewTarget != "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if webviewTarget != "" {
+			response := commands.DevToolsEvaluateCommand(commands.DevToolsEvaluateRequest{
+				DeviceID:   deviceId,
+				TargetID:   webviewTarget,
+				Expression: args[0],
+			})
+			printJson(response)
+			if response.Status == "error" {
+				return fmt.Errorf("%s", response.Error)
+			}
+			return nil
+		}
+
 		response := commands.WebViewEvaluateCommand(commands.WebViewEvaluateRequest{
 			DeviceID:   deviceId,
 			WebViewID:  args[0],
@@ -218,6 +249,26 @@ var webviewQueryCmd = &cobra.Command{
 	},
 }
 
+var webviewDumpCmd = &cobra.Command{
+	Use:   "dump",
+	Short: "Dump the DOM of a browser tab or inspectable webview",
+	Long: `Returns the DOM of a target from 'webview list --devtools', without injecting an agent.
+On Android it is a DevTools node tree including iframes and shadow roots, for Chrome tabs and the webviews of apps that enabled WebView debugging.
+On iOS it is the page's HTML, for Safari tabs and inspectable webviews (Web Inspector must be enabled in Settings > Safari > Advanced on real devices).`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		response := commands.DevToolsDumpCommand(commands.DevToolsDumpRequest{
+			DeviceID: deviceId,
+			TargetID: webviewTarget,
+		})
+		printJson(response)
+		if response.Status == "error" {
+			return fmt.Errorf("%s", response.Error)
+		}
+		return nil
+	},
+}
+
 func init() {
 	rootCmd.AddCommand(webviewCmd)
 
@@ -232,13 +283,16 @@ func init() {
 	webviewCmd.AddCommand(webviewTitleCmd)
 	webviewCmd.AddCommand(webviewContentCmd)
 	webviewCmd.AddCommand(webviewQueryCmd)
+	webviewCmd.AddCommand(webviewDumpCmd)
 
 	webviewListCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	webviewListCmd.Flags().BoolVar(&webviewDevTools, "devtools", false, "list browser tabs and inspectable webviews of all apps, for 'webview dump' and 'webview eval --target'")
 	webviewGotoCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewReloadCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewBackCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewForwardCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewEvalCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	webviewEvalCmd.Flags().StringVar(&webviewTarget, "target", "", "ID of a target from 'webview list --devtools' to evaluate in")
 	webviewWaitCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewWaitCmd.Flags().StringVar(&webviewWaitState, "state", "load", `load state to wait for: "load" or "domcontentloaded"`)
 	webviewWaitCmd.Flags().IntVar(&webviewWaitTimeout, "timeout", 0, "maximum time to wait in milliseconds (0 = default)")
@@ -246,4 +300,7 @@ func init() {
 	webviewTitleCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewContentCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
 	webviewQueryCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	webviewDumpCmd.Flags().StringVar(&deviceId, "device", "", "ID of the device")
+	webviewDumpCmd.Flags().StringVar(&webviewTarget, "target", "", "ID of the target from 'webview list --devtools'")
+	_ = webviewDumpCmd.MarkFlagRequired("target")
 }
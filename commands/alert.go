@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/wda"
+)
+
+// AlertRequest represents the parameters for reading or answering the alert
+// on screen
+type AlertRequest struct {
+	DeviceID string `json:"deviceId"`
+	Button   string `json:"button,omitempty"` // the button to tap, instead of the default one
+}
+
+// AlertResult is the alert on screen, if any
+type AlertResult struct {
+	Shown bool       `json:"shown"`
+	Alert *wda.Alert `json:"alert,omitempty"`
+}
+
+// findAlertHandler finds the device, checks it can handle alerts, then locks
+// it and starts its agent. The caller releases the lock with the returned
+// function.
+func findAlertHandler(deviceID string) (devices.ControllableDevice, devices.AlertHandler, func(), error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	handler, ok := targetDevice.(devices.AlertHandler)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("alerts are not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		unlock()
+		return nil, nil, nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	return targetDevice, handler, unlock, nil
+}
+
+// AlertTextCommand returns the text and buttons of the alert on screen
+func AlertTextCommand(req AlertRequest) *CommandResponse {
+	targetDevice, handler, unlock, err := findAlertHandler(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	alert, err := handler.GetAlert()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to get alert on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(AlertResult{Shown: alert != nil, Alert: alert})
+}
+
+// AcceptAlertCommand accepts the alert on screen, tapping its default button
+// or the one named
+func AcceptAlertCommand(req AlertRequest) *CommandResponse {
+	targetDevice, handler, unlock, err := findAlertHandler(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := handler.AcceptAlert(req.Button); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to accept alert on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Accepted alert on device %s", targetDevice.ID()),
+	})
+}
+
+// DismissAlertCommand dismisses the alert on screen, tapping its cancel
+// button or the one named
+func DismissAlertCommand(req AlertRequest) *CommandResponse {
+	targetDevice, handler, unlock, err := findAlertHandler(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := handler.DismissAlert(req.Button); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to dismiss alert on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Dismissed alert on device %s", targetDevice.ID()),
+	})
+}
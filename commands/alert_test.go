@@ -0,0 +1,63 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+	"github.com/mobile-next/mobilecli/devices/wda"
+)
+
+func TestAlertCommands(t *testing.T) {
+	useFakeDevices(t, 2)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	device := fake.Get("fake-ios-2")
+
+	response := AlertTextCommand(AlertRequest{DeviceID: "fake-ios-2"})
+	if response.Status != "ok" || response.Data.(AlertResult).Shown {
+		t.Fatalf("expected no alert, got %+v", response)
+	}
+	if response := AcceptAlertCommand(AlertRequest{DeviceID: "fake-ios-2"}); response.Status != "error" || !strings.Contains(response.Error, "no alert is shown") {
+		t.Errorf("unexpected response: %s %s", response.Status, response.Error)
+	}
+
+	device.SetAlert(&wda.Alert{Text: "Allow notifications?", Buttons: []string{"Don't Allow", "Allow"}})
+	response = AlertTextCommand(AlertRequest{DeviceID: "fake-ios-2"})
+	if result := response.Data.(AlertResult); !result.Shown || result.Alert.Text != "Allow notifications?" {
+		t.Fatalf("unexpected alert: %+v", result)
+	}
+	if response := AcceptAlertCommand(AlertRequest{DeviceID: "fake-ios-2"}); response.Status != "ok" {
+		t.Fatalf("accept failed: %s", response.Error)
+	}
+
+	device.SetAlert(&wda.Alert{Text: "Delete?", Buttons: []string{"Cancel", "Delete"}})
+	if response := DismissAlertCommand(AlertRequest{DeviceID: "fake-ios-2", Button: "Delete"}); response.Status != "ok" {
+		t.Fatalf("dismiss failed: %s", response.Error)
+	}
+
+	expected := []string{"alert accept Allow", "alert dismiss Delete"}
+	if actions := device.Actions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+}
+
+func TestSystemUICommands(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	for _, response := range []*CommandResponse{
+		ControlCenterCommand(SystemUIRequest{DeviceID: "fake-android-1"}, true),
+		ControlCenterCommand(SystemUIRequest{DeviceID: "fake-android-1"}, false),
+		OpenSettingsCommand(SystemUIRequest{DeviceID: "fake-android-1"}),
+	} {
+		if response.Status != "ok" {
+			t.Fatalf("unexpected error: %s", response.Error)
+		}
+	}
+
+	expected := []string{"control-center open", "control-center close", "launch com.android.settings"}
+	if actions := fake.Get("fake-android-1").Actions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+}
@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/mobile-next/mobilecli/devices"
@@ -15,6 +17,15 @@ type AppRequest struct {
 	BundleID string   `json:"bundleId"`
 	Locales  []string `json:"locales,omitempty"`
 	Activity string   `json:"activity,omitempty"`
+	Wait     bool     `json:"wait,omitempty"`
+	User     string   `json:"user,omitempty"` // Android user to launch for
+}
+
+// LaunchAppResult is returned by LaunchAppCommand. Component and the timings
+// are only reported by devices that can resolve the launched activity.
+type LaunchAppResult struct {
+	Message string `json:"message"`
+	*devices.LaunchResult
 }
 
 // LaunchAppCommand launches an app on the specified device
@@ -25,16 +36,34 @@ func LaunchAppCommand(req AppRequest) *CommandResponse {
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	if _, err := userManagerFor(targetDevice, req.User); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
 	}
+	defer unlock()
+
+	opts := devices.LaunchOptions{Locales: req.Locales, Activity: req.Activity, Wait: req.Wait, User: req.User}
 
-	err = targetDevice.LaunchApp(req.BundleID, devices.LaunchOptions{Locales: req.Locales, Activity: req.Activity})
+	var launched *devices.LaunchResult
+	if launcher, ok := targetDevice.(devices.ComponentLauncher); ok {
+		launched, err = launcher.LaunchAppComponent(req.BundleID, opts)
+	} else {
+		err = targetDevice.LaunchApp(req.BundleID, opts)
+	}
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to launch app on device %s: %v", targetDevice.ID(), err))
 	}
 
-	return NewSuccessResponse(MessageResult{
-		Message: fmt.Sprintf("Launched app '%s' on device %s", req.BundleID, targetDevice.ID()),
+	return NewSuccessResponse(LaunchAppResult{
+		Message:      fmt.Sprintf("Launched app '%s' on device %s", req.BundleID, targetDevice.ID()),
+		LaunchResult: launched,
 	})
 }
 
@@ -46,7 +75,7 @@ func TerminateAppCommand(req AppRequest) *CommandResponse {
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
 	err = targetDevice.TerminateApp(req.BundleID)
@@ -62,16 +91,27 @@ func TerminateAppCommand(req AppRequest) *CommandResponse {
 // ListAppsRequest represents the parameters for listing apps
 type ListAppsRequest struct {
 	DeviceID string `json:"deviceId"`
+	User     string `json:"user,omitempty"` // Android user to list the apps of
 }
 
 // ListAppsCommand lists installed apps on a device
 func ListAppsCommand(req ListAppsRequest) *CommandResponse {
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
-	apps, err := targetDevice.ListApps(true)
+	users, err := userManagerFor(targetDevice, req.User)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	var apps []devices.InstalledAppInfo
+	if users != nil {
+		apps, err = users.ListAppsForUser(req.User, true)
+	} else {
+		apps, err = targetDevice.ListApps(true)
+	}
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to list apps on device %s: %v", targetDevice.ID(), err))
 	}
@@ -88,9 +128,15 @@ type ForegroundAppRequest struct {
 func ForegroundAppCommand(req ForegroundAppRequest) *CommandResponse {
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	// start agent if needed (for WDA)
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -107,12 +153,71 @@ func ForegroundAppCommand(req ForegroundAppRequest) *CommandResponse {
 	return NewSuccessResponse(app)
 }
 
+// RunningAppsRequest represents the parameters for listing running apps
+type RunningAppsRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// RunningAppsCommand lists apps with live processes on a device, along with
+// whether each one is in the foreground, background or cached
+func RunningAppsCommand(req RunningAppsRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	lister, ok := targetDevice.(devices.RunningAppsLister)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("listing running apps is not supported on %s devices", targetDevice.Platform()))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	// start agent if needed (for WDA)
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
+	}
+
+	apps, err := lister.ListRunningApps()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list running apps on device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(apps)
+}
+
 type InstallAppRequest struct {
 	DeviceID            string `json:"deviceId"`
 	Path                string `json:"path"`
+	URL                 string `json:"url,omitempty"`    // download the app instead of installing Path
+	SHA256              string `json:"sha256,omitempty"` // expected checksum of the app at URL
 	ForceResign         bool   `json:"forceResign"`
 	ProvisioningProfile string `json:"provisioningProfile"`
 	SigningIdentity     string `json:"signingIdentity"`
+
+	// Android install options
+	GrantPermissions bool   `json:"grantPermissions,omitempty"`
+	AllowTest        bool   `json:"allowTest,omitempty"`
+	Instant          bool   `json:"instant,omitempty"`
+	AllowDowngrade   bool   `json:"allowDowngrade,omitempty"`
+	User             string `json:"user,omitempty"`
+	Keystore         string `json:"keystore,omitempty"` // signs the apks built from an .aab
+	KeystorePassword string `json:"keystorePassword,omitempty"`
+	KeyAlias         string `json:"keyAlias,omitempty"`
+	KeyPassword      string `json:"keyPassword,omitempty"`
+
+	// OnProgress receives upload and install progress as it happens
+	OnProgress func(progress devices.InstallProgress) `json:"-"`
+
+	// Context, when set, stops the install once done
+	Context context.Context `json:"-"`
 }
 
 // InstallAppResult is returned on a successful install, including the app
@@ -123,25 +228,75 @@ type InstallAppResult struct {
 }
 
 func InstallAppCommand(req InstallAppRequest) *CommandResponse {
-	if req.Path == "" {
-		return NewErrorResponse(fmt.Errorf("path is required"))
+	if req.Path == "" && req.URL == "" {
+		return NewErrorResponse(fmt.Errorf("path or url is required"))
+	}
+
+	if req.Path != "" && req.URL != "" {
+		return NewErrorResponse(fmt.Errorf("path and url are mutually exclusive"))
+	}
+
+	if req.SHA256 != "" && req.URL == "" {
+		return NewErrorResponse(fmt.Errorf("sha256 can only be used with url"))
 	}
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	source := req.Path
+	if req.URL != "" {
+		req.Path, err = utils.DownloadApp(req.URL, req.SHA256)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to download app: %w", err))
+		}
+		source = req.URL
+	}
+
+	if req.Context != nil && req.Context.Err() != nil {
+		return NewErrorResponse(fmt.Errorf("install cancelled: %w", req.Context.Err()))
 	}
 
 	installPath := req.Path
 
-	// re-sign IPA if requested, only for .ipa files on real iOS devices
-	if req.ForceResign {
+	config := devices.InstallConfig{
+		OnProgress:       req.OnProgress,
+		Context:          req.Context,
+		GrantPermissions: req.GrantPermissions,
+		AllowTest:        req.AllowTest,
+		Instant:          req.Instant,
+		AllowDowngrade:   req.AllowDowngrade,
+		User:             req.User,
+		Keystore: devices.KeystoreConfig{
+			Path:        req.Keystore,
+			Password:    req.KeystorePassword,
+			KeyAlias:    req.KeyAlias,
+			KeyPassword: req.KeyPassword,
+		},
+	}
+
+	if req.User != "" {
+		if err := devices.ValidateAndroidUser(req.User); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	ext := strings.ToLower(filepath.Ext(req.Path))
+	if targetDevice.Platform() != "android" && (config.HasAndroidOptions() || ext == ".apks" || ext == ".aab") {
+		return NewErrorResponse(fmt.Errorf(".apks and .aab files and Android install options only work with Android devices"))
+	}
+
+	// re-sign IPA if requested, only for .ipa files on real iOS devices. a
+	// signing identity or provisioning profile implies re-signing.
+	resign := req.ForceResign || req.SigningIdentity != "" || req.ProvisioningProfile != ""
+	if resign {
 		if !strings.HasSuffix(strings.ToLower(req.Path), ".ipa") {
-			return NewErrorResponse(fmt.Errorf("--force-resign only works with .ipa files"))
+			return NewErrorResponse(fmt.Errorf("re-signing only works with .ipa files"))
 		}
 
 		if targetDevice.Platform() != "ios" || targetDevice.DeviceType() != "real" {
-			return NewErrorResponse(fmt.Errorf("--force-resign only works with real iOS devices"))
+			return NewErrorResponse(fmt.Errorf("re-signing only works with real iOS devices"))
 		}
 
 		resignedPath, err := utils.ResignIPA(req.Path, targetDevice.ID(), req.ProvisioningProfile, req.SigningIdentity)
@@ -153,13 +308,26 @@ func InstallAppCommand(req InstallAppRequest) *CommandResponse {
 		installPath = resignedPath
 	}
 
-	err = targetDevice.InstallApp(installPath)
+	unlock, err := LockDevice(targetDevice.ID())
 	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if installer, ok := targetDevice.(devices.ProgressInstaller); ok && (req.OnProgress != nil || config.HasAndroidOptions()) {
+		err = installer.InstallAppWithProgress(installPath, config)
+	} else {
+		err = targetDevice.InstallApp(installPath)
+	}
+	if err != nil {
+		if !resign && isSigningError(err) {
+			return NewErrorResponse(fmt.Errorf("failed to install app on device %s: %w\nthe app is not signed for this device; re-sign it on install with --force-resign, or with --signing-identity and --provisioning-profile", targetDevice.ID(), err))
+		}
 		return NewErrorResponse(fmt.Errorf("failed to install app on device %s: %w", targetDevice.ID(), err))
 	}
 
 	result := InstallAppResult{
-		Message: fmt.Sprintf("Installed app from '%s' on device %s", req.Path, targetDevice.ID()),
+		Message: fmt.Sprintf("Installed app from '%s' on device %s", source, targetDevice.ID()),
 	}
 
 	// metadata extraction is best-effort: a parse failure must not turn a
@@ -173,6 +341,28 @@ func InstallAppCommand(req InstallAppRequest) *CommandResponse {
 	return NewSuccessResponse(result)
 }
 
+// signingErrorMarkers are fragments of the errors installd reports for apps
+// signed for another team or without a profile covering the device
+var signingErrorMarkers = []string{
+	"ApplicationVerificationFailed",
+	"A valid provisioning profile for this executable was not found",
+	"MismatchedApplicationIdentifierEntitlement",
+	"DeviceNotProvisioned",
+	"code signature",
+}
+
+// isSigningError reports whether an install failed because of how the app
+// is signed, which re-signing can fix
+func isSigningError(err error) bool {
+	message := err.Error()
+	for _, marker := range signingErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
 type AppPathRequest struct {
 	DeviceID string `json:"deviceId"`
 	BundleID string `json:"bundleId"`
@@ -198,9 +388,41 @@ func AppPathCommand(req AppPathRequest) *CommandResponse {
 	})
 }
 
+// AppContainersRequest represents the parameters for locating an app's containers
+type AppContainersRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+}
+
+// AppContainersCommand returns the paths of an app's bundle, data and app
+// group containers, for use with the fs commands
+func AppContainersCommand(req AppContainersRequest) *CommandResponse {
+	if req.BundleID == "" {
+		return NewErrorResponse(fmt.Errorf("bundle ID is required"))
+	}
+
+	device, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	provider, ok := device.(devices.AppContainerProvider)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("app containers are not supported on %s %s devices", device.Platform(), device.DeviceType()))
+	}
+
+	containers, err := provider.AppContainers(req.BundleID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to get app containers on device %s: %w", device.ID(), err))
+	}
+
+	return NewSuccessResponse(containers)
+}
+
 type UninstallAppRequest struct {
 	DeviceID    string `json:"deviceId"`
 	PackageName string `json:"packageName"`
+	User        string `json:"user,omitempty"` // uninstall for this Android user only
 }
 
 func UninstallAppCommand(req UninstallAppRequest) *CommandResponse {
@@ -210,10 +432,20 @@ func UninstallAppCommand(req UninstallAppRequest) *CommandResponse {
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	users, err := userManagerFor(targetDevice, req.User)
+	if err != nil {
+		return NewErrorResponse(err)
 	}
 
-	appInfo, err := targetDevice.UninstallApp(req.PackageName)
+	var appInfo *devices.InstalledAppInfo
+	if users != nil {
+		appInfo, err = users.UninstallAppForUser(req.PackageName, req.User)
+	} else {
+		appInfo, err = targetDevice.UninstallApp(req.PackageName)
+	}
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to uninstall app on device %s: %v", targetDevice.ID(), err))
 	}
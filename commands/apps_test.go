@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestInstallFailureData(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	fake.Get("fake-android-1").FailWith("InstallApp", &devices.InstallFailedError{Code: "INSTALL_FAILED_VERSION_DOWNGRADE"})
+
+	response := InstallAppCommand(InstallAppRequest{DeviceID: "fake-android-1", Path: sampleApk})
+	if response.Status != "error" {
+		t.Fatal("expected the install to fail")
+	}
+	if failure, ok := response.Data.(*devices.InstallFailedError); !ok || failure.Code != "INSTALL_FAILED_VERSION_DOWNGRADE" {
+		t.Errorf("expected the failure code in the error data, got %#v", response.Data)
+	}
+}
+
+func TestInstallAndroidOptionsOnIOS(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	response := InstallAppCommand(InstallAppRequest{DeviceID: "fake-ios-2", Path: sampleApk, AllowDowngrade: true})
+	if response.Status != "error" {
+		t.Error("expected --downgrade to be rejected for an ios device")
+	}
+}
+
+func TestAppsForUser(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if response := ListAppsCommand(ListAppsRequest{DeviceID: "fake-android-1", User: "10; reboot"}); response.Status != "error" {
+		t.Error("expected an invalid user to be rejected")
+	}
+	if response := ListAppsCommand(ListAppsRequest{DeviceID: "fake-android-1", User: "10"}); response.Status != "error" {
+		t.Error("expected --user to be rejected for a device without users")
+	}
+	if response := ListAppsCommand(ListAppsRequest{DeviceID: "fake-android-1"}); response.Status != "ok" {
+		t.Errorf("expected listing without a user to work, got %s", response.Error)
+	}
+	if response := CreateUserCommand(CreateUserRequest{DeviceID: "fake-android-1", Name: "Work", Guest: true, ProfileOf: new(int)}); response.Status != "error" {
+		t.Error("expected a guest work profile to be rejected")
+	}
+}
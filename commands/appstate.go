@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// App states reported by AppStateCommand
+const (
+	AppStateNotInstalled = "not-installed"
+	AppStateNotRunning   = "not-running"
+	AppStateForeground   = "foreground"
+	AppStateBackground   = "background"
+	AppStateSuspended    = "suspended"
+)
+
+// AppStateRequest represents the parameters for querying an app's state
+type AppStateRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+}
+
+// AppStateResult describes whether an app is installed, running and in the
+// foreground
+type AppStateResult struct {
+	BundleID   string `json:"bundleId"`
+	State      string `json:"state"`
+	Installed  bool   `json:"installed"`
+	Running    bool   `json:"running"`
+	Foreground bool   `json:"foreground"`
+}
+
+// AppStateCommand reports the state of an app on a device
+func AppStateCommand(req AppStateRequest) *CommandResponse {
+	if req.BundleID == "" {
+		return NewErrorResponse(fmt.Errorf("bundle ID is required"))
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %w", targetDevice.ID(), err))
+	}
+
+	apps, err := targetDevice.ListApps(false)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list apps on device %s: %w", targetDevice.ID(), err))
+	}
+
+	result := AppStateResult{
+		BundleID: req.BundleID,
+		State:    AppStateNotInstalled,
+	}
+	for _, app := range apps {
+		if app.PackageName == req.BundleID {
+			result.Installed = true
+			result.State = AppStateNotRunning
+			break
+		}
+	}
+	if !result.Installed {
+		return NewSuccessResponse(result)
+	}
+
+	// there is no foreground app on the home screen
+	foreground, err := targetDevice.GetForegroundApp()
+	if err != nil {
+		utils.Verbose("No foreground app on device %s: %v", targetDevice.ID(), err)
+	}
+	result.Foreground = foreground != nil && foreground.PackageName == req.BundleID
+
+	if lister, ok := targetDevice.(devices.RunningAppsLister); ok {
+		running, err := lister.ListRunningApps()
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to list running apps on device %s: %w", targetDevice.ID(), err))
+		}
+
+		for _, app := range running {
+			if app.PackageName != req.BundleID {
+				continue
+			}
+			result.Running = true
+			switch app.State {
+			case devices.ProcessStateForeground:
+				result.State = AppStateForeground
+			case devices.ProcessStateCached:
+				result.State = AppStateSuspended
+			default:
+				result.State = AppStateBackground
+			}
+		}
+	}
+
+	// the foreground app is running even where processes can't be listed
+	if result.Foreground {
+		result.Running = true
+		result.State = AppStateForeground
+	}
+
+	return NewSuccessResponse(result)
+}
+
+// TerminateAllAppsRequest represents the parameters for terminating every
+// running app
+type TerminateAllAppsRequest struct {
+	DeviceID string   `json:"deviceId"`
+	Exclude  []string `json:"exclude,omitempty"` // bundle IDs to leave running
+}
+
+// TerminateAllAppsResult lists the apps TerminateAllAppsCommand terminated
+type TerminateAllAppsResult struct {
+	Message    string   `json:"message"`
+	Terminated []string `json:"terminated"`
+}
+
+// isAgentApp reports whether an app is mobilecli's agent, which commands
+// need running
+func isAgentApp(bundleID string) bool {
+	return strings.HasPrefix(bundleID, "com.mobilenext.devicekit") || strings.Contains(bundleID, "WebDriverAgentRunner")
+}
+
+// TerminateAllAppsCommand terminates every running launchable app on a
+// device, except the agent and the excluded apps. Apps that fail to terminate
+// don't stop the others from being terminated.
+func TerminateAllAppsCommand(req TerminateAllAppsRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	lister, ok := targetDevice.(devices.RunningAppsLister)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("listing running apps is not supported on %s devices", targetDevice.Platform()))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %w", targetDevice.ID(), err))
+	}
+
+	// only launchable apps are terminated, so system services keep running
+	launchable, err := targetDevice.ListApps(true)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list apps on device %s: %w", targetDevice.ID(), err))
+	}
+
+	running, err := lister.ListRunningApps()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list running apps on device %s: %w", targetDevice.ID(), err))
+	}
+
+	terminated := []string{}
+	var failures []string
+	for _, app := range running {
+		bundleID := app.PackageName
+		if isAgentApp(bundleID) || slices.Contains(req.Exclude, bundleID) {
+			continue
+		}
+		if !slices.ContainsFunc(launchable, func(a devices.InstalledAppInfo) bool { return a.PackageName == bundleID }) {
+			continue
+		}
+
+		if err := targetDevice.TerminateApp(bundleID); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", bundleID, err))
+			continue
+		}
+		terminated = append(terminated, bundleID)
+	}
+
+	if len(failures) > 0 {
+		return NewErrorResponse(fmt.Errorf("failed to terminate %d of %d apps on device %s: %s", len(failures), len(failures)+len(terminated), targetDevice.ID(), strings.Join(failures, "; ")))
+	}
+
+	return NewSuccessResponse(TerminateAllAppsResult{
+		Message:    fmt.Sprintf("Terminated %d apps on device %s", len(terminated), targetDevice.ID()),
+		Terminated: terminated,
+	})
+}
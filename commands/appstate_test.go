@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func appState(t *testing.T, bundleID string) AppStateResult {
+	t.Helper()
+	response := AppStateCommand(AppStateRequest{DeviceID: "fake-android-1", BundleID: bundleID})
+	if response.Status != "ok" {
+		t.Fatalf("app state failed: %s", response.Error)
+	}
+	return response.Data.(AppStateResult)
+}
+
+func TestAppStateCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	fake.Get("fake-android-1").SetApps([]devices.InstalledAppInfo{
+		{PackageName: "com.android.settings"},
+		{PackageName: "com.example.app"},
+	})
+
+	if state := appState(t, "com.example.missing"); state.State != AppStateNotInstalled || state.Installed {
+		t.Errorf("unexpected state of a missing app: %+v", state)
+	}
+	if state := appState(t, "com.example.app"); state.State != AppStateNotRunning || !state.Installed || state.Running {
+		t.Errorf("unexpected state of an app that isn't running: %+v", state)
+	}
+
+	LaunchAppCommand(AppRequest{DeviceID: "fake-android-1", BundleID: "com.example.app"})
+	if state := appState(t, "com.example.app"); state.State != AppStateForeground || !state.Running || !state.Foreground {
+		t.Errorf("unexpected state of the foreground app: %+v", state)
+	}
+	if state := appState(t, "com.android.settings"); state.State != AppStateBackground || !state.Running || state.Foreground {
+		t.Errorf("unexpected state of a background app: %+v", state)
+	}
+}
+
+func TestTerminateAllAppsCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	device := fake.Get("fake-android-1")
+	device.SetApps([]devices.InstalledAppInfo{
+		{PackageName: "com.android.settings"},
+		{PackageName: "com.example.app"},
+		{PackageName: "com.example.keep"},
+		{PackageName: "com.mobilenext.devicekit"},
+	})
+	for _, app := range []string{"com.example.app", "com.example.keep", "com.mobilenext.devicekit"} {
+		LaunchAppCommand(AppRequest{DeviceID: "fake-android-1", BundleID: app})
+	}
+
+	response := TerminateAllAppsCommand(TerminateAllAppsRequest{DeviceID: "fake-android-1", Exclude: []string{"com.example.keep"}})
+	if response.Status != "ok" {
+		t.Fatalf("terminate all failed: %s", response.Error)
+	}
+
+	result := response.Data.(TerminateAllAppsResult)
+	if !reflect.DeepEqual(result.Terminated, []string{"com.android.settings", "com.example.app"}) {
+		t.Errorf("unexpected terminated apps: %v", result.Terminated)
+	}
+
+	if state := appState(t, "com.example.keep"); !state.Running {
+		t.Errorf("expected the excluded app to keep running: %+v", state)
+	}
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// AppVerifyRequest names an app file to inspect before installing it, and
+// optionally a device to check it against
+type AppVerifyRequest struct {
+	DeviceID string `json:"deviceId,omitempty"`
+	Path     string `json:"path"`
+}
+
+// AppVerifyResult is what an app file says about itself, and whether it can
+// be installed on the device when one was given
+type AppVerifyResult struct {
+	App           *utils.AppPackageInfo     `json:"app"`
+	Compatibility *devices.AppCompatibility `json:"compatibility,omitempty"`
+}
+
+// AppVerifyCommand inspects an .apk, .ipa, .zip or .app without installing
+// it. With a device, an app that can't be installed on it is an error
+// response that still carries the result, so scripts can stop before a slow
+// install that would fail.
+func AppVerifyCommand(req AppVerifyRequest) *CommandResponse {
+	if req.Path == "" {
+		return NewErrorResponse(fmt.Errorf("path is required"))
+	}
+
+	info, err := utils.InspectAppPackage(req.Path)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to inspect app: %w", err))
+	}
+
+	result := AppVerifyResult{App: info}
+	if req.DeviceID == "" {
+		return NewSuccessResponse(result)
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	result.Compatibility = devices.CheckAppCompatibility(targetDevice, info)
+	if !result.Compatibility.Compatible {
+		return &CommandResponse{
+			Status: "error",
+			Error:  fmt.Sprintf("app is not compatible with device %s: %s", targetDevice.ID(), strings.Join(result.Compatibility.Problems, "; ")),
+			Data:   result,
+		}
+	}
+
+	return NewSuccessResponse(result)
+}
@@ -0,0 +1,34 @@
+package commands
+
+import (
+	"testing"
+)
+
+const sampleApk = "../utils/testdata/sample.apk"
+
+func TestAppVerify(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	response := AppVerifyCommand(AppVerifyRequest{Path: sampleApk})
+	if response.Status != "ok" {
+		t.Fatalf("verify failed: %s", response.Error)
+	}
+	result := response.Data.(AppVerifyResult)
+	if result.App.PackageName != "com.example.helloworld" || result.App.Platform != "android" || result.Compatibility != nil {
+		t.Errorf("unexpected result without a device: %+v", result)
+	}
+
+	response = AppVerifyCommand(AppVerifyRequest{DeviceID: "fake-android-1", Path: sampleApk})
+	if response.Status != "ok" {
+		t.Fatalf("expected the apk to be compatible with an android device: %s", response.Error)
+	}
+
+	response = AppVerifyCommand(AppVerifyRequest{DeviceID: "fake-ios-2", Path: sampleApk})
+	if response.Status != "error" {
+		t.Fatal("expected the apk to be incompatible with an ios device")
+	}
+	result = response.Data.(AppVerifyResult)
+	if result.Compatibility == nil || result.Compatibility.Compatible || len(result.Compatibility.Problems) != 1 {
+		t.Errorf("expected one compatibility problem, got %+v", result.Compatibility)
+	}
+}
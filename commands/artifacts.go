@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ArtifactsDirEnvVar names a directory that screenshots, recordings, bug
+// reports and UI dumps are written to when no output path is given, under a
+// subdirectory per device
+const ArtifactsDirEnvVar = "MOBILECLI_ARTIFACTS_DIR"
+
+// artifactTimestampFormat names artifacts by when they were made, down to
+// the millisecond so consecutive artifacts don't collide
+const artifactTimestampFormat = "20060102-150405.000"
+
+// ArtifactPath returns the path of a new artifact of a device, such as
+// <artifacts>/<deviceId>/screenshot-20250101-094100-000.png, creating its
+// directory. It returns "" when no artifacts directory is set.
+func ArtifactPath(deviceID, kind, extension string) (string, error) {
+	dir := os.Getenv(ArtifactsDirEnvVar)
+	if dir == "" {
+		return "", nil
+	}
+
+	deviceDir, err := filepath.Abs(filepath.Join(dir, sanitizeFilename(deviceID)))
+	if err != nil {
+		return "", fmt.Errorf("invalid artifacts directory: %v", err)
+	}
+	if err := os.MkdirAll(deviceDir, 0o750); err != nil {
+		return "", fmt.Errorf("failed to create artifacts directory: %v", err)
+	}
+
+	timestamp := strings.Replace(time.Now().Format(artifactTimestampFormat), ".", "-", 1)
+	return filepath.Join(deviceDir, fmt.Sprintf("%s-%s.%s", kind, timestamp, extension)), nil
+}
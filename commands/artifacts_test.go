@@ -0,0 +1,66 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArtifactPath(t *testing.T) {
+	t.Setenv(ArtifactsDirEnvVar, "")
+	path, err := ArtifactPath("emulator-5554", "screenshot", "png")
+	if err != nil || path != "" {
+		t.Fatalf("expected no path without an artifacts directory, got %q (%v)", path, err)
+	}
+
+	dir := t.TempDir()
+	t.Setenv(ArtifactsDirEnvVar, dir)
+	path, err = ArtifactPath("192.168.1.2:5555", "screenshot", "png")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if filepath.Dir(path) != filepath.Join(dir, "192.168.1.2_5555") {
+		t.Errorf("expected the path in a directory of the device, got %s", path)
+	}
+	name := filepath.Base(path)
+	if !strings.HasPrefix(name, "screenshot-") || !strings.HasSuffix(name, ".png") || strings.Count(name, ".") != 1 {
+		t.Errorf("unexpected file name %s", name)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); err != nil {
+		t.Errorf("expected the device directory to be created: %v", err)
+	}
+}
+
+func TestArtifactsDirDefaultsOutputs(t *testing.T) {
+	useFakeDevices(t, 1)
+	dir := t.TempDir()
+	t.Setenv(ArtifactsDirEnvVar, dir)
+
+	response := ScreenshotCommand(ScreenshotRequest{DeviceID: "fake-android-1", Format: "png"})
+	if response.Status != "ok" {
+		t.Fatalf("screenshot failed: %s", response.Error)
+	}
+	screenshot := response.Data.(ScreenshotResponse)
+	if !strings.HasPrefix(screenshot.FilePath, filepath.Join(dir, "fake-android-1")+string(filepath.Separator)) {
+		t.Errorf("expected the screenshot under the artifacts directory, got %s", screenshot.FilePath)
+	}
+
+	response = DumpUICommand(DumpUIRequest{DeviceID: "fake-android-1"})
+	if response.Status != "ok" {
+		t.Fatalf("dump failed: %s", response.Error)
+	}
+	dump := response.Data.(DumpUIResponse)
+	if dump.FilePath == "" || dump.Elements != nil {
+		t.Fatalf("expected the dump to be written to a file, got %+v", dump)
+	}
+	if data, err := os.ReadFile(dump.FilePath); err != nil || !strings.Contains(string(data), `"elements"`) {
+		t.Errorf("expected the dump file to hold the elements: %v", err)
+	}
+
+	response = DumpUICommand(DumpUIRequest{DeviceID: "fake-android-1", Output: "-"})
+	if dump := response.Data.(DumpUIResponse); dump.FilePath != "" || len(dump.Elements) == 0 {
+		t.Errorf("expected '-' to return the dump, got %+v", dump)
+	}
+}
@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// authorizePollInterval is how often adb is asked whether a device has been
+// authorized
+const authorizePollInterval = time.Second
+
+// adbReconnectTimeout is how long devices get to reconnect after the adb
+// server restarted with the adb key
+const adbReconnectTimeout = 5 * time.Second
+
+// AuthorizeRequest represents the parameters for an authorize command
+type AuthorizeRequest struct {
+	DeviceID string `json:"deviceId"`
+	Wait     int    `json:"wait,omitempty"` // seconds to wait for USB debugging to be allowed, 0 to only check
+}
+
+// AuthorizeResult reports whether adb may use a device, and what to do when
+// it may not
+type AuthorizeResult struct {
+	DeviceID   string `json:"deviceId"`
+	State      string `json:"state"`
+	Authorized bool   `json:"authorized"`
+
+	// AdbKey is the private key given with --adb-key, and KeyFingerprint
+	// the fingerprint of the key the device is asked to allow
+	AdbKey         string `json:"adbKey,omitempty"`
+	KeyFingerprint string `json:"keyFingerprint,omitempty"`
+
+	Steps []string `json:"steps,omitempty"`
+}
+
+// findAdbDevice asks adb for the current state of an Android device, by ID or
+// serial, rather than trusting a cached device whose state may be stale
+func findAdbDevice(deviceID string) (*devices.AndroidDevice, error) {
+	list, err := devices.GetAndroidDevices()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range list {
+		if android, ok := d.(*devices.AndroidDevice); ok && (android.ID() == deviceID || android.AdbSerial() == deviceID) {
+			return android, nil
+		}
+	}
+	return nil, fmt.Errorf("device not found: %s", deviceID)
+}
+
+// waitForAuthorization polls adb until the device with serial is no longer
+// unauthorized, or timeout passes
+func waitForAuthorization(serial string, timeout time.Duration) (*devices.AndroidDevice, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		device, err := findAdbDevice(serial)
+		if err == nil && device.State() != devices.AndroidStateUnauthorized {
+			return device, nil
+		}
+		if time.Now().After(deadline) {
+			return device, err
+		}
+		time.Sleep(authorizePollInterval)
+	}
+}
+
+// authorizeSteps explains how to allow USB debugging from this computer
+func authorizeSteps(deviceID, fingerprint string) []string {
+	steps := []string{
+		fmt.Sprintf("Unlock device %s and accept the 'Allow USB debugging?' prompt, checking 'Always allow from this computer'", deviceID),
+	}
+	if fingerprint != "" {
+		steps = append(steps, fmt.Sprintf("Check that the prompt shows the key fingerprint %s", fingerprint))
+	}
+	return append(steps,
+		"If no prompt shows, replug the device, or turn USB debugging off and on again in Developer options",
+		"If the prompt was denied, choose 'Revoke USB debugging authorizations' in Developer options and try again",
+		"For CI images, add a public key to /data/misc/adb/adb_keys on the device and pass its private key with --adb-key",
+	)
+}
+
+// AuthorizeCommand checks whether an Android device allows USB debugging from
+// this computer, restarting the adb server with the adb key if one is given,
+// and waits for the user to allow it on the device
+func AuthorizeCommand(req AuthorizeRequest) *CommandResponse {
+	if req.DeviceID == "" {
+		return NewErrorResponse(fmt.Errorf("device ID is required, unauthorized devices are listed by 'mobilecli devices'"))
+	}
+	if req.Wait < 0 {
+		return NewErrorResponse(fmt.Errorf("wait must be non-negative, got %d", req.Wait))
+	}
+
+	adbKey := devices.AdbKey()
+	if adbKey != "" {
+		if _, err := os.Stat(adbKey); err != nil {
+			return NewErrorResponse(fmt.Errorf("adb key not found: %v", err))
+		}
+	}
+
+	device, err := findAdbDevice(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+	serial := device.AdbSerial()
+
+	// a running adb server only reads vendor keys when it starts
+	if device.State() == devices.AndroidStateUnauthorized && adbKey != "" {
+		if err := devices.RestartAdbServer(); err != nil {
+			return NewErrorResponse(err)
+		}
+		device, err = waitForAuthorization(serial, adbReconnectTimeout)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("device %s did not reconnect after restarting the adb server: %w", serial, err))
+		}
+	}
+
+	fingerprint, err := devices.AdbKeyFingerprint(devices.AdbPublicKeyPath())
+	if err != nil {
+		utils.Verbose("Failed to read the adb public key: %v", err)
+	}
+
+	if device.State() == devices.AndroidStateUnauthorized && req.Wait > 0 {
+		for _, step := range authorizeSteps(device.ID(), fingerprint) {
+			utils.Info("%s", step)
+		}
+
+		device, err = waitForAuthorization(serial, time.Duration(req.Wait)*time.Second)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("device %s disconnected while waiting for authorization: %w", serial, err))
+		}
+		if device.State() == devices.AndroidStateUnauthorized {
+			return NewErrorResponse(fmt.Errorf("device %s was not authorized within %ds, accept the 'Allow USB debugging?' prompt on it", device.ID(), req.Wait))
+		}
+	}
+
+	result := AuthorizeResult{
+		DeviceID:       device.ID(),
+		State:          device.State(),
+		Authorized:     device.State() != devices.AndroidStateUnauthorized,
+		AdbKey:         adbKey,
+		KeyFingerprint: fingerprint,
+	}
+	if !result.Authorized {
+		result.Steps = authorizeSteps(device.ID(), fingerprint)
+	}
+	return NewSuccessResponse(result)
+}
@@ -0,0 +1,257 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// DevicePlatformEnvVar and DeviceTypeEnvVar narrow auto-selection to devices
+// of a platform (ios, android) or type (real, simulator, emulator)
+const (
+	DevicePlatformEnvVar = "MOBILECLI_DEVICE_PLATFORM"
+	DeviceTypeEnvVar     = "MOBILECLI_DEVICE_TYPE"
+)
+
+// DeviceCandidate describes a device auto-selection could have picked
+type DeviceCandidate struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Platform string `json:"platform"`
+	Type     string `json:"type"`
+}
+
+// AmbiguousDeviceError is returned when several devices could be
+// auto-selected and none of them is the default or last-used device
+type AmbiguousDeviceError struct {
+	Code       string            `json:"code"`
+	Candidates []DeviceCandidate `json:"candidates"`
+}
+
+// ambiguousDeviceCode identifies an AmbiguousDeviceError in error responses
+const ambiguousDeviceCode = "AMBIGUOUS_DEVICE"
+
+func (e *AmbiguousDeviceError) Error() string {
+	ids := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		ids = append(ids, c.ID)
+	}
+	return fmt.Sprintf("multiple devices found (%d), please specify --device with one of: [%s]", len(e.Candidates), strings.Join(ids, ", "))
+}
+
+func newAmbiguousDeviceError(candidates []devices.ControllableDevice) *AmbiguousDeviceError {
+	err := &AmbiguousDeviceError{Code: ambiguousDeviceCode}
+	for _, d := range candidates {
+		err.Candidates = append(err.Candidates, DeviceCandidate{
+			ID:       d.ID(),
+			Name:     d.Name(),
+			Platform: d.Platform(),
+			Type:     d.DeviceType(),
+		})
+	}
+	return err
+}
+
+// errorData returns the structured details of err for an error response, or
+// nil when it has none
+func errorData(err error) any {
+	var ambiguous *AmbiguousDeviceError
+	if errors.As(err, &ambiguous) {
+		return ambiguous
+	}
+	var unsupported *devices.UnsupportedButtonError
+	if errors.As(err, &unsupported) {
+		return unsupported
+	}
+	var installFailed *devices.InstallFailedError
+	if errors.As(err, &installFailed) {
+		return installFailed
+	}
+	return nil
+}
+
+// autoSelectFilter narrows the devices auto-selection picks from
+type autoSelectFilter struct {
+	platform   string
+	deviceType string
+	labels     map[string]string
+}
+
+// loadAutoSelectFilter reads the platform, type and labels to auto-select by
+func loadAutoSelectFilter() (*autoSelectFilter, error) {
+	labels, err := autoSelectLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	return &autoSelectFilter{
+		platform:   os.Getenv(DevicePlatformEnvVar),
+		deviceType: os.Getenv(DeviceTypeEnvVar),
+		labels:     labels,
+	}, nil
+}
+
+// AutoSelectNarrowed reports whether auto-selection is narrowed by platform,
+// type or labels in this process's environment
+func AutoSelectNarrowed() bool {
+	return os.Getenv(DevicePlatformEnvVar) != "" || os.Getenv(DeviceTypeEnvVar) != "" || os.Getenv(DeviceLabelsEnvVar) != ""
+}
+
+// describe returns what the filter restricts, for errors
+func (f *autoSelectFilter) describe() string {
+	var parts []string
+	if f.platform != "" {
+		parts = append(parts, "platform "+f.platform)
+	}
+	if f.deviceType != "" {
+		parts = append(parts, "type "+f.deviceType)
+	}
+	if len(f.labels) > 0 {
+		parts = append(parts, "labels "+devices.FormatLabels(f.labels))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// candidates returns the online devices that match the filter
+func (f *autoSelectFilter) candidates(all []devices.ControllableDevice) ([]devices.ControllableDevice, error) {
+	var labels map[string]map[string]string
+	if len(f.labels) > 0 {
+		var err error
+		labels, err = devices.GetAllDeviceLabels()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matching []devices.ControllableDevice
+	for _, d := range all {
+		if d.State() != "online" {
+			continue
+		}
+		if f.platform != "" && d.Platform() != f.platform {
+			continue
+		}
+		if f.deviceType != "" && d.DeviceType() != f.deviceType {
+			continue
+		}
+		if !devices.MatchesLabels(labels[d.ID()], f.labels) {
+			continue
+		}
+		matching = append(matching, d)
+	}
+	return matching, nil
+}
+
+// preferredDevice picks among several candidates: the configured default
+// device first, then the device last used. It returns nil when neither is a
+// candidate.
+func preferredDevice(candidates []devices.ControllableDevice) devices.ControllableDevice {
+	var preferred []string
+	if config, err := utils.LoadConfig(); err != nil {
+		utils.Verbose("Ignoring config: %v", err)
+	} else if config.DefaultDevice != "" {
+		preferred = append(preferred, config.DefaultDevice)
+	}
+	if lastUsed := lastUsedDeviceID(); lastUsed != "" {
+		preferred = append(preferred, lastUsed)
+	}
+
+	for _, id := range preferred {
+		for _, d := range candidates {
+			if d.ID() == id || matchesAdbSerial(d, id) {
+				utils.Verbose("Auto-selected %s out of %d devices", d.ID(), len(candidates))
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	lastUsedMu     sync.Mutex
+	lastUsedDevice string
+
+	// lastUsedDisabled is set by servers shared by several clients, where
+	// the device one client last used says nothing about what another wants
+	lastUsedDisabled bool
+)
+
+// SetLastUsedDeviceEnabled turns remembering and preferring the last used
+// device on or off
+func SetLastUsedDeviceEnabled(enabled bool) {
+	lastUsedMu.Lock()
+	lastUsedDisabled = !enabled
+	lastUsedMu.Unlock()
+}
+
+// lastUsedDeviceID returns the ID of the device last used, or "" when there
+// is none or last used devices are off
+func lastUsedDeviceID() string {
+	lastUsedMu.Lock()
+	disabled := lastUsedDisabled
+	lastUsedMu.Unlock()
+
+	if disabled {
+		return ""
+	}
+	return loadLastUsedDevice()
+}
+
+// lastUsedDeviceFilePath returns where the ID of the last used device is kept
+func lastUsedDeviceFilePath() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last-device"), nil
+}
+
+// loadLastUsedDevice returns the ID of the device last used, or ""
+func loadLastUsedDevice() string {
+	path, err := lastUsedDeviceFilePath()
+	if err != nil {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// rememberLastUsedDevice persists the ID of the device a command ran on. The
+// file is only written when the device changes.
+func rememberLastUsedDevice(deviceID string) {
+	lastUsedMu.Lock()
+	defer lastUsedMu.Unlock()
+
+	if lastUsedDisabled || deviceID == lastUsedDevice {
+		return
+	}
+	lastUsedDevice = deviceID
+
+	if loadLastUsedDevice() == deviceID {
+		return
+	}
+
+	path, err := lastUsedDeviceFilePath()
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		utils.Verbose("Failed to remember last used device: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(path, []byte(deviceID+"\n"), 0o600); err != nil {
+		utils.Verbose("Failed to remember last used device: %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+func TestAutoSelectAmbiguousDevice(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	_, err := FindDeviceOrAutoSelect("")
+	var ambiguous *AmbiguousDeviceError
+	if !errors.As(err, &ambiguous) {
+		t.Fatalf("expected an AmbiguousDeviceError, got %v", err)
+	}
+	if len(ambiguous.Candidates) != 2 || ambiguous.Candidates[0].ID != "fake-android-1" || ambiguous.Candidates[1].Platform != "ios" {
+		t.Errorf("unexpected candidates: %+v", ambiguous.Candidates)
+	}
+
+	response := NewErrorResponse(err)
+	if response.Data != ambiguous {
+		t.Errorf("expected the error response to carry the candidates, got %+v", response.Data)
+	}
+}
+
+func TestAutoSelectNarrowedByPlatformAndType(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	t.Setenv(DevicePlatformEnvVar, "ios")
+	device, err := FindDeviceOrAutoSelect("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID() != "fake-ios-2" {
+		t.Errorf("auto-selected %s, want fake-ios-2", device.ID())
+	}
+
+	t.Setenv(DeviceTypeEnvVar, "real")
+	if _, err := FindDeviceOrAutoSelect(""); err == nil || err.Error() != "no online devices found with platform ios, type real" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestAutoSelectPrefersDefaultThenLastUsed(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	// selecting a device explicitly makes it the last used one
+	if _, err := FindDeviceOrAutoSelect("fake-ios-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	device, err := FindDeviceOrAutoSelect("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID() != "fake-ios-2" {
+		t.Errorf("auto-selected %s, want the last used fake-ios-2", device.ID())
+	}
+
+	dir, _ := utils.ConfigDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"default_device": "fake-android-1"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	device, err = FindDeviceOrAutoSelect("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID() != "fake-android-1" {
+		t.Errorf("auto-selected %s, want the default fake-android-1", device.ID())
+	}
+}
+
+func TestAutoSelectWithoutLastUsed(t *testing.T) {
+	useFakeDevices(t, 2)
+	SetLastUsedDeviceEnabled(false)
+	t.Cleanup(func() { SetLastUsedDeviceEnabled(true) })
+
+	if _, err := FindDeviceOrAutoSelect("fake-ios-2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if device, err := FindDeviceOrAutoSelect(""); err == nil {
+		t.Errorf("expected an ambiguous device error, auto-selected %s", device.ID())
+	}
+}
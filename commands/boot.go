@@ -1,22 +1,41 @@
 package commands
 
 import (
+	"context"
 	"fmt"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
 )
 
 // BootRequest represents the parameters for a boot command
 type BootRequest struct {
 	DeviceID string `json:"deviceId"`
+	Timeout  int    `json:"timeout,omitempty"` // seconds, 0 for the default
+
+	// OnProgress receives boot stages (devices.BootStage*) as they happen
+	OnProgress func(stage string) `json:"-"`
+
+	// Context, when set, cancels waiting for the boot
+	Context context.Context `json:"-"`
 }
 
 // BootCommand boots the specified simulator or emulator
 func BootCommand(req BootRequest) *CommandResponse {
+	if req.Timeout < 0 {
+		return NewErrorResponse(fmt.Errorf("timeout must be non-negative, got %d", req.Timeout))
+	}
+
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
-	err = targetDevice.Boot()
+	err = targetDevice.Boot(devices.BootConfig{
+		Timeout:    time.Duration(req.Timeout) * time.Second,
+		OnProgress: req.OnProgress,
+		Context:    req.Context,
+	})
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to boot device %s: %v", targetDevice.ID(), err))
 	}
@@ -38,7 +57,7 @@ type ShutdownRequest struct {
 func ShutdownCommand(req ShutdownRequest) *CommandResponse {
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
 	err = targetDevice.Shutdown()
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// BugReportRequest represents the parameters for a bugreport command
+type BugReportRequest struct {
+	DeviceID string `json:"deviceId"`
+	Output   string `json:"output"`
+
+	// OutputDir confines the archive to a directory, Output then only
+	// naming the file, so a server's clients can't write anywhere on its host
+	OutputDir string `json:"-"`
+}
+
+// BugReportResult is returned once the diagnostics archive has been written.
+// Warnings lists collection steps that failed without aborting the report.
+type BugReportResult struct {
+	Path     string   `json:"path"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// bugReportDevice is written to device.json in the archive
+type bugReportDevice struct {
+	Device     devices.DeviceInfo        `json:"device"`
+	Properties *devices.DeviceProperties `json:"properties,omitempty"`
+}
+
+// BugReportCommand collects platform diagnostics, crash logs and mobilecli's
+// own verbose log of the collection into a single zip archive
+func BugReportCommand(req BugReportRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	output, err := bugReportOutput(req, targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	workDir, err := os.MkdirTemp("", "mobilecli-bugreport-*")
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to create temp dir: %v", err))
+	}
+	defer func() { _ = os.RemoveAll(workDir) }()
+
+	var logs bytes.Buffer
+	stopCapture := utils.CaptureLogs(&logs)
+	warnings := collectBugReport(targetDevice, workDir)
+	stopCapture()
+
+	if err := os.WriteFile(filepath.Join(workDir, "mobilecli.log"), logs.Bytes(), 0600); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to write mobilecli log: %v", err))
+	}
+
+	if err := utils.ZipDirectory(workDir, output); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to write bugreport archive: %v", err))
+	}
+
+	return NewSuccessResponse(BugReportResult{
+		Path:     output,
+		Warnings: warnings,
+	})
+}
+
+// bugReportOutput returns the path to write a device's archive to
+func bugReportOutput(req BugReportRequest, deviceID string) (string, error) {
+	defaultName := fmt.Sprintf("mobilecli-bugreport-%s-%s.zip", sanitizeFilename(deviceID), time.Now().Format("20060102-150405"))
+
+	if req.OutputDir != "" {
+		name := req.Output
+		if name == "" {
+			name = defaultName
+		}
+		if filepath.Base(name) != name || name == "." || name == ".." {
+			return "", fmt.Errorf("output must be a file name, not a path: %s", req.Output)
+		}
+		if err := os.MkdirAll(req.OutputDir, 0o700); err != nil {
+			return "", fmt.Errorf("failed to create bugreport directory: %v", err)
+		}
+		return filepath.Join(req.OutputDir, name), nil
+	}
+
+	if req.Output != "" {
+		return req.Output, nil
+	}
+
+	output, err := ArtifactPath(deviceID, "bugreport", "zip")
+	if err != nil {
+		return "", err
+	}
+	if output == "" {
+		output = defaultName
+	}
+	return output, nil
+}
+
+// collectBugReport gathers everything into dir, logging and returning failures
+// as warnings so that a partial report is still produced
+func collectBugReport(device devices.ControllableDevice, dir string) []string {
+	var warnings []string
+	warn := func(format string, args ...any) {
+		message := fmt.Sprintf(format, args...)
+		utils.Verbose("%s", message)
+		warnings = append(warnings, message)
+	}
+
+	utils.Verbose("Collecting bugreport for %s device %s (%s %s)", device.Platform(), device.ID(), device.DeviceType(), device.Version())
+
+	info := bugReportDevice{
+		Device: devices.DeviceInfo{
+			ID:       device.ID(),
+			Name:     device.Name(),
+			Platform: device.Platform(),
+			Type:     device.DeviceType(),
+			Version:  device.Version(),
+			State:    device.State(),
+		},
+	}
+
+	if provider, ok := device.(devices.PropertiesProvider); ok {
+		props, err := provider.Properties()
+		if err != nil {
+			warn("failed to read device properties: %v", err)
+		}
+		info.Properties = props
+	}
+
+	if data, err := json.MarshalIndent(info, "", "  "); err == nil {
+		if err := os.WriteFile(filepath.Join(dir, "device.json"), data, 0600); err != nil {
+			warn("failed to write device.json: %v", err)
+		}
+	}
+
+	collector, ok := device.(devices.BugReportCollector)
+	if !ok {
+		warn("platform diagnostics are not supported on %s devices", device.Platform())
+		return warnings
+	}
+
+	if err := collector.CollectBugReport(dir); err != nil {
+		warn("failed to collect platform diagnostics: %v", err)
+	}
+
+	return warnings
+}
+
+// sanitizeFilename replaces characters that aren't safe in file names
+func sanitizeFilename(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', ' ':
+			return '_'
+		}
+		return r
+	}, name)
+}
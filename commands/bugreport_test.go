@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBugReportOutputConfinedToDir(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := bugReportOutput(BugReportRequest{Output: "report.zip", OutputDir: dir}, "emulator-5554")
+	if err != nil || path != filepath.Join(dir, "report.zip") {
+		t.Errorf("unexpected output %s, %v", path, err)
+	}
+
+	path, err = bugReportOutput(BugReportRequest{OutputDir: dir}, "emulator-5554")
+	if err != nil || filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "mobilecli-bugreport-emulator-5554-") {
+		t.Errorf("unexpected default output %s, %v", path, err)
+	}
+
+	for _, output := range []string{"../report.zip", "/etc/report.zip", ".."} {
+		if _, err := bugReportOutput(BugReportRequest{Output: output, OutputDir: dir}, "emulator-5554"); err == nil {
+			t.Errorf("expected output %s outside the directory to be rejected", output)
+		}
+	}
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"fmt"
+	"os"
 	"strings"
 	"sync"
 
 	"github.com/mobile-next/mobilecli/devices"
+	_ "github.com/mobile-next/mobilecli/devices/fake" // listed when MOBILECLI_FAKE_DEVICES is set
 	"github.com/mobile-next/mobilecli/utils"
 )
 
@@ -50,6 +52,7 @@ func NewSuccessResponse(data any) *CommandResponse {
 func NewErrorResponse(err error) *CommandResponse {
 	return &CommandResponse{
 		Status: "error",
+		Data:   errorData(err),
 		Error:  err.Error(),
 	}
 }
@@ -91,6 +94,10 @@ func getRemoteControllableDevices() []devices.ControllableDevice {
 	return result
 }
 
+// AndroidSerialEnvVar selects the device when no device ID is given, as it
+// does for adb
+const AndroidSerialEnvVar = "ANDROID_SERIAL"
+
 // DeviceCache provides a simple cache for devices to avoid repeated lookups
 var deviceCache = make(map[string]devices.ControllableDevice)
 
@@ -132,8 +139,8 @@ func FindDevice(deviceID string) (devices.ControllableDevice, error) {
 		return device, nil
 	}
 
-	// get all devices including offline ones and find the one we want
-	allDevices, err := devices.GetAllControllableDevices(true)
+	// get all devices including offline and never booted ones and find the one we want
+	allDevices, err := devices.ListAllControllableDevices(true, true)
 	if err != nil {
 		return nil, fmt.Errorf("error getting devices: %w", err)
 	}
@@ -142,10 +149,22 @@ func FindDevice(deviceID string) (devices.ControllableDevice, error) {
 	allDevices = append(allDevices, getRemoteControllableDevices()...)
 
 	for _, d := range allDevices {
-		if d.ID() == deviceID {
+		if d.ID() == deviceID || matchesAdbSerial(d, deviceID) || matchesUSBPath(d, deviceID) {
+			// another device may be plugged into the same USB port later, so
+			// a device found by its port is cached by its ID instead
+			key := deviceID
+			if strings.HasPrefix(deviceID, "usb:") {
+				key = d.ID()
+			}
+
 			mu.Lock()
-			deviceCache[deviceID] = d
+			if cached, ok := deviceCache[key]; ok {
+				mu.Unlock()
+				return cached, nil
+			}
+			deviceCache[key] = d
 			mu.Unlock()
+			enforceStayAwake(d)
 			return d, nil
 		}
 	}
@@ -153,13 +172,57 @@ func FindDevice(deviceID string) (devices.ControllableDevice, error) {
 	return nil, fmt.Errorf("device not found: %s", deviceID)
 }
 
-// FindDeviceOrAutoSelect finds a device by ID, or auto-selects if deviceID is empty
+// matchesAdbSerial reports whether d is an Android device adb knows by serial,
+// so devices can be selected the way adb -s and ANDROID_SERIAL select them
+func matchesAdbSerial(d devices.ControllableDevice, serial string) bool {
+	android, ok := d.(*devices.AndroidDevice)
+	return ok && android.AdbSerial() == serial
+}
+
+// matchesUSBPath reports whether d is plugged into the USB port of a
+// "usb:<path>" device ID, e.g. usb:1-1.4, so scripts can select a device on a
+// hub by where it is plugged in
+func matchesUSBPath(d devices.ControllableDevice, deviceID string) bool {
+	path, ok := strings.CutPrefix(deviceID, "usb:")
+	if !ok || path == "" {
+		return false
+	}
+	locator, ok := d.(devices.USBLocator)
+	return ok && locator.USBPath() == path
+}
+
+// FindDeviceOrAutoSelect finds a device by ID, or auto-selects if deviceID is
+// empty. ANDROID_SERIAL, when set, selects the device instead of auto-selection.
+// The device found is remembered as the last used one.
 func FindDeviceOrAutoSelect(deviceID string) (devices.ControllableDevice, error) {
+	device, err := findDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	rememberLastUsedDevice(device.ID())
+	return device, nil
+}
+
+func findDeviceOrAutoSelect(deviceID string) (devices.ControllableDevice, error) {
 	// if deviceID is provided, use existing logic
 	if deviceID != "" {
 		return FindDevice(deviceID)
 	}
 
+	if serial := os.Getenv(AndroidSerialEnvVar); serial != "" {
+		device, err := FindDevice(serial)
+		if err != nil {
+			return nil, fmt.Errorf("%s is set to '%s' but no such device is connected: %w", AndroidSerialEnvVar, serial, err)
+		}
+		return device, nil
+	}
+
+	filter, err := loadAutoSelectFilter()
+	if err != nil {
+		return nil, err
+	}
+
 	// get all devices for auto-selection
 	allDevices, err := devices.GetAllControllableDevices(false)
 	if err != nil {
@@ -169,45 +232,39 @@ func FindDeviceOrAutoSelect(deviceID string) (devices.ControllableDevice, error)
 	// append remote devices
 	allDevices = append(allDevices, getRemoteControllableDevices()...)
 
-	// filter to only online devices for auto-selection
-	var onlineDevices []devices.ControllableDevice
-	for _, d := range allDevices {
-		if d.State() == "online" {
-			onlineDevices = append(onlineDevices, d)
-		}
+	// only online devices matching --platform, --type and --label can be picked
+	candidates, err := filter.candidates(allDevices)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(onlineDevices) == 0 {
+	if len(candidates) == 0 {
+		if restriction := filter.describe(); restriction != "" {
+			return nil, fmt.Errorf("no online devices found with %s", restriction)
+		}
 		return nil, fmt.Errorf("no online devices found")
 	}
 
-	if len(onlineDevices) > 1 {
-		err = fmt.Errorf("multiple devices found (%d), please specify --device with one of: %s", len(onlineDevices), getDeviceIDList(onlineDevices))
-		return nil, err
+	device := candidates[0]
+	if len(candidates) > 1 {
+		device = preferredDevice(candidates)
+		if device == nil {
+			return nil, newAmbiguousDeviceError(candidates)
+		}
 	}
 
-	// exactly 1 online device - check cache first to reuse existing instance
-	deviceID = onlineDevices[0].ID()
+	// check cache first to reuse existing instance
 	mu.RLock()
-	cachedDevice, exists := deviceCache[deviceID]
+	cachedDevice, exists := deviceCache[device.ID()]
 	mu.RUnlock()
 	if exists {
 		return cachedDevice, nil
 	}
 
 	// not in cache, use the new device instance and cache it
-	device := onlineDevices[0]
 	mu.Lock()
 	deviceCache[device.ID()] = device
 	mu.Unlock()
+	enforceStayAwake(device)
 	return device, nil
 }
-
-// getDeviceIDList returns a comma-separated list of device IDs for error messages
-func getDeviceIDList(devices []devices.ControllableDevice) string {
-	var ids []string
-	for _, d := range devices {
-		ids = append(ids, d.ID())
-	}
-	return fmt.Sprintf("[%s]", strings.Join(ids, ", "))
-}
@@ -1,6 +1,14 @@
 package commands
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
 
 func CrashesListCommand(deviceID string) *CommandResponse {
 	device, err := FindDeviceOrAutoSelect(deviceID)
@@ -32,3 +40,163 @@ func CrashesGetCommand(deviceID string, id string) *CommandResponse {
 		"content": string(content),
 	})
 }
+
+// AppCrashesRequest represents the parameters for listing an app's crash reports
+type AppCrashesRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+}
+
+// crashWatchInterval is how often the device is polled for new crashes
+const crashWatchInterval = 2 * time.Second
+
+// crashMatchesApp reports whether a crash belongs to the app. Android crashes
+// are named after the process (the package, or "package:suffix" for secondary
+// processes); iOS crash reports are named after the app's executable.
+func crashMatchesApp(crash devices.CrashReport, bundleID, executable string) bool {
+	if crash.ProcessName == bundleID || strings.HasPrefix(crash.ProcessName, bundleID+":") {
+		return true
+	}
+	return executable != "" && crash.ProcessName == executable
+}
+
+// appCrashLister lists the crash reports of a single app on a device
+type appCrashLister struct {
+	device     devices.ControllableDevice
+	bundleID   string
+	executable string
+}
+
+func newAppCrashLister(req AppCrashesRequest) (*appCrashLister, error) {
+	if req.BundleID == "" {
+		return nil, fmt.Errorf("bundleId is required")
+	}
+
+	device, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	lister := &appCrashLister{device: device, bundleID: req.BundleID}
+	if resolver, ok := device.(devices.AppExecutableResolver); ok {
+		executable, err := resolver.GetAppExecutable(req.BundleID)
+		if err != nil {
+			// the app may have been uninstalled since it crashed, in which case
+			// only reports named after the bundle id can be matched
+			utils.Verbose("failed to resolve executable of %s: %v", req.BundleID, err)
+		}
+		lister.executable = executable
+	}
+
+	return lister, nil
+}
+
+func (l *appCrashLister) list() ([]devices.CrashReport, error) {
+	crashes, err := l.device.ListCrashReports()
+	if err != nil {
+		return nil, fmt.Errorf("error listing crash reports: %w", err)
+	}
+
+	matching := []devices.CrashReport{}
+	for _, crash := range crashes {
+		if crashMatchesApp(crash, l.bundleID, l.executable) {
+			matching = append(matching, crash)
+		}
+	}
+
+	return matching, nil
+}
+
+// AppCrashesCommand lists crash reports belonging to an app
+func AppCrashesCommand(req AppCrashesRequest) *CommandResponse {
+	lister, err := newAppCrashLister(req)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	crashes, err := lister.list()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(crashes)
+}
+
+// AppCrashSnapshot remembers the crash reports of an app at one point in
+// time, to tell the reports that appear later apart
+type AppCrashSnapshot struct {
+	lister *appCrashLister
+	seen   map[string]bool
+}
+
+// SnapshotAppCrashes lists the crash reports the app has now
+func SnapshotAppCrashes(req AppCrashesRequest) (*AppCrashSnapshot, error) {
+	lister, err := newAppCrashLister(req)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := lister.list()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, crash := range existing {
+		seen[crash.ID] = true
+	}
+	return &AppCrashSnapshot{lister: lister, seen: seen}, nil
+}
+
+// DeviceID returns the ID of the device the snapshot was taken on
+func (s *AppCrashSnapshot) DeviceID() string {
+	return s.lister.device.ID()
+}
+
+// NewCrashes returns the crash reports of the app that appeared since the
+// snapshot, or since the last call, and adds them to the snapshot
+func (s *AppCrashSnapshot) NewCrashes() ([]devices.CrashReport, error) {
+	crashes, err := s.lister.list()
+	if err != nil {
+		return nil, err
+	}
+
+	appeared := []devices.CrashReport{}
+	for _, crash := range crashes {
+		if s.seen[crash.ID] {
+			continue
+		}
+		s.seen[crash.ID] = true
+		appeared = append(appeared, crash)
+	}
+	return appeared, nil
+}
+
+// WatchAppCrashes polls the device until ctx is done, calling onCrash for every
+// crash report of the app that appears after the watch started
+func WatchAppCrashes(ctx context.Context, req AppCrashesRequest, onCrash func(devices.CrashReport)) error {
+	snapshot, err := SnapshotAppCrashes(req)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(crashWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			crashes, err := snapshot.NewCrashes()
+			if err != nil {
+				utils.Verbose("failed to poll crash reports: %v", err)
+				continue
+			}
+
+			for _, crash := range crashes {
+				onCrash(crash)
+			}
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashMatchesApp(t *testing.T) {
+	assert.True(t, crashMatchesApp(devices.CrashReport{ProcessName: "com.example.app"}, "com.example.app", ""))
+	assert.True(t, crashMatchesApp(devices.CrashReport{ProcessName: "com.example.app:remote"}, "com.example.app", ""))
+	assert.True(t, crashMatchesApp(devices.CrashReport{ProcessName: "Example"}, "com.example.app", "Example"))
+
+	assert.False(t, crashMatchesApp(devices.CrashReport{ProcessName: "com.example.app2"}, "com.example.app", ""))
+	assert.False(t, crashMatchesApp(devices.CrashReport{ProcessName: "Example"}, "com.example.app", ""))
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// deviceLockTimeout bounds how long a command waits for another one driving
+// the same device
+const deviceLockTimeout = 2 * time.Minute
+
+// deviceLockPollInterval is how often a lock file held by another process
+// is retried
+const deviceLockPollInterval = 100 * time.Millisecond
+
+// deviceLockNameRe matches the characters of a device ID that can't be part
+// of a lock file name, such as the colon of adb's host:port IDs
+var deviceLockNameRe = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+var (
+	deviceLocksMu sync.Mutex
+	deviceLocks   = map[string]chan struct{}{}
+)
+
+// deviceLock returns the in-memory lock of a device, a channel holding a
+// token while the device is locked
+func deviceLock(deviceID string) chan struct{} {
+	deviceLocksMu.Lock()
+	defer deviceLocksMu.Unlock()
+
+	lock, ok := deviceLocks[deviceID]
+	if !ok {
+		lock = make(chan struct{}, 1)
+		deviceLocks[deviceID] = lock
+	}
+	return lock
+}
+
+// deviceLockFilePath returns the lock file of a device
+func deviceLockFilePath(deviceID string) (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "locks", deviceLockNameRe.ReplaceAllString(deviceID, "_")+".lock"), nil
+}
+
+// lockDeviceFile takes the lock file of a device, waiting until deadline for
+// other mobilecli processes to release it
+func lockDeviceFile(deviceID string, deadline time.Time) (*os.File, error) {
+	path, err := deviceLockFilePath(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// #nosec G304 -- path is built from the config directory and a sanitized device id
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open device lock: %w", err)
+	}
+
+	for {
+		locked, err := utils.TryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("failed to lock device %s: %w", deviceID, err)
+		}
+		if locked {
+			return f, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("device %s is busy with another mobilecli process, gave up after %s", deviceID, deviceLockTimeout)
+		}
+		time.Sleep(deviceLockPollInterval)
+	}
+}
+
+// LockDevice serializes the commands that drive a device through its agent,
+// so concurrent callers don't interleave their agent sessions. It locks
+// within this process, then across mobilecli processes with a lock file, so
+// a server and CLI commands on the same machine take turns too. Only the
+// holder of the in-process lock opens the file, as a process can't wait on
+// its own file lock. The returned function releases the lock.
+func LockDevice(deviceID string) (func(), error) {
+	deadline := time.Now().Add(deviceLockTimeout)
+
+	lock := deviceLock(deviceID)
+	select {
+	case lock <- struct{}{}:
+	case <-time.After(deviceLockTimeout):
+		return nil, fmt.Errorf("device %s is busy with another command, gave up after %s", deviceID, deviceLockTimeout)
+	}
+
+	f, err := lockDeviceFile(deviceID, deadline)
+	if err != nil {
+		<-lock
+		return nil, err
+	}
+
+	return func() {
+		_ = utils.UnlockFile(f)
+		_ = f.Close()
+		<-lock
+	}, nil
+}
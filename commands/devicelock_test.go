@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockDeviceSerializesCallers(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	unlock, err := LockDevice("emulator-5554")
+	require.NoError(t, err)
+
+	acquired := make(chan struct{})
+	go func() {
+		second, err := LockDevice("emulator-5554")
+		assert.NoError(t, err)
+		close(acquired)
+		second()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second caller got the lock while it was held")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// other devices aren't blocked
+	other, err := LockDevice("192.168.1.5:5555")
+	require.NoError(t, err)
+	other()
+
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second caller didn't get the lock after it was released")
+	}
+}
+
+func TestLockDeviceHoldsLockFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	unlock, err := LockDevice("192.168.1.5:5555")
+	require.NoError(t, err)
+
+	path, err := deviceLockFilePath("192.168.1.5:5555")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.5_5555.lock", filepath.Base(path))
+
+	// another process, as far as the lock file is concerned
+	f, err := os.OpenFile(path, os.O_RDWR, 0o600)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	locked, err := utils.TryLockFile(f)
+	require.NoError(t, err)
+	assert.False(t, locked)
+
+	unlock()
+	locked, err = utils.TryLockFile(f)
+	require.NoError(t, err)
+	assert.True(t, locked)
+	require.NoError(t, utils.UnlockFile(f))
+}
@@ -1,16 +1,26 @@
 package commands
 
 import (
+	"cmp"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
 	"github.com/mobile-next/mobilecli/devices"
 	"github.com/mobile-next/mobilecli/utils"
 )
 
-// DevicesCommand lists all connected devices, merging remote devices if a token is provided
+// DevicesCommand lists all connected devices, merging remote devices if a token is provided.
+// Backends that time out are left out and reported in warnings.
 func DevicesCommand(opts devices.DeviceListOptions, token string) *CommandResponse {
-	deviceInfoList, err := devices.GetDeviceInfoList(opts)
+	deviceInfoList, warnings, err := devices.GetDeviceInfoListWithWarnings(opts)
 	if err != nil {
 		return NewErrorResponse(err)
 	}
+	if warnings == nil {
+		warnings = []devices.BackendWarning{}
+	}
 
 	if token != "" {
 		remoteDevices, err := FetchRemoteDevices(token)
@@ -22,6 +32,50 @@ func DevicesCommand(opts devices.DeviceListOptions, token string) *CommandRespon
 	}
 
 	return NewSuccessResponse(map[string]any{
-		"devices": deviceInfoList,
+		"devices":  deviceInfoList,
+		"warnings": warnings,
+	})
+}
+
+// DevicesPartitionResult is one shard of the device pool
+type DevicesPartitionResult struct {
+	Total   int                  `json:"total"`
+	Index   int                  `json:"index"`
+	Devices []devices.DeviceInfo `json:"devices"`
+}
+
+// deviceHash is a stable hash of a device id, the same on every host and run
+func deviceHash(id string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(id))
+	return h.Sum64()
+}
+
+// PartitionDevices returns the devices of shard index (1-based) out of total.
+// Devices are ordered by a hash of their id and dealt round-robin, so shards
+// differ by at most one device and every worker seeing the same pool agrees
+// on the assignment without coordinating. Unauthorized Android devices can't
+// run anything, so they get no shard.
+func PartitionDevices(list []devices.DeviceInfo, total, index int) ([]devices.DeviceInfo, error) {
+	if total < 1 {
+		return nil, fmt.Errorf("total must be at least 1, got %d", total)
+	}
+	if index < 1 || index > total {
+		return nil, fmt.Errorf("index must be between 1 and %d, got %d", total, index)
+	}
+
+	sorted := slices.DeleteFunc(slices.Clone(list), func(d devices.DeviceInfo) bool {
+		return d.State == devices.AndroidStateUnauthorized
 	})
+	slices.SortFunc(sorted, func(a, b devices.DeviceInfo) int {
+		return cmp.Or(cmp.Compare(deviceHash(a.ID), deviceHash(b.ID)), strings.Compare(a.ID, b.ID))
+	})
+
+	shard := []devices.DeviceInfo{}
+	for i, d := range sorted {
+		if i%total == index-1 {
+			shard = append(shard, d)
+		}
+	}
+	return shard, nil
 }
@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func TestPartitionDevices(t *testing.T) {
+	var list []devices.DeviceInfo
+	for i := range 10 {
+		list = append(list, devices.DeviceInfo{ID: fmt.Sprintf("device-%d", i)})
+	}
+
+	seen := map[string]int{}
+	for index := 1; index <= 3; index++ {
+		shard, err := PartitionDevices(list, 3, index)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(shard) < 3 || len(shard) > 4 {
+			t.Errorf("shard %d has %d devices, want 3 or 4", index, len(shard))
+		}
+		for _, d := range shard {
+			seen[d.ID]++
+		}
+
+		// the order devices are listed in must not matter
+		reversed := make([]devices.DeviceInfo, len(list))
+		for i, d := range list {
+			reversed[len(list)-1-i] = d
+		}
+		again, _ := PartitionDevices(reversed, 3, index)
+		if fmt.Sprint(again) != fmt.Sprint(shard) {
+			t.Errorf("shard %d changed with the listing order: %v != %v", index, again, shard)
+		}
+	}
+
+	if len(seen) != len(list) {
+		t.Errorf("%d of %d devices assigned", len(seen), len(list))
+	}
+	for id, count := range seen {
+		if count != 1 {
+			t.Errorf("%s assigned to %d shards", id, count)
+		}
+	}
+
+	unauthorized := append(list, devices.DeviceInfo{ID: "unauthorized", State: devices.AndroidStateUnauthorized})
+	shard, _ := PartitionDevices(unauthorized, 1, 1)
+	if len(shard) != len(list) {
+		t.Errorf("unauthorized device was assigned a shard: %v", shard)
+	}
+
+	for _, c := range []struct{ total, index int }{{0, 1}, {3, 0}, {3, 4}} {
+		if _, err := PartitionDevices(list, c.total, c.index); err == nil {
+			t.Errorf("expected an error for total %d index %d", c.total, c.index)
+		}
+	}
+}
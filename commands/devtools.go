@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// DevToolsListRequest represents the parameters for listing DevTools targets
+type DevToolsListRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// DevToolsDumpRequest represents the parameters for dumping a DevTools target
+type DevToolsDumpRequest struct {
+	DeviceID string `json:"deviceId"`
+	TargetID string `json:"target"`
+}
+
+// DevToolsEvaluateRequest represents the parameters for evaluating
+// JavaScript in a DevTools target
+type DevToolsEvaluateRequest struct {
+	DeviceID   string `json:"deviceId"`
+	TargetID   string `json:"target"`
+	Expression string `json:"expression"`
+}
+
+// DevToolsListResult lists the targets found on a device
+type DevToolsListResult struct {
+	Targets []devices.DevToolsTarget `json:"targets"`
+}
+
+// DevToolsDumpResult is the DOM of a DevTools target: a DOM.getDocument node
+// tree on Android, and the page's HTML on iOS
+type DevToolsDumpResult struct {
+	Target   string `json:"target"`
+	Document any    `json:"document"`
+}
+
+// DevToolsEvaluateResult is the value of an expression evaluated in a target
+type DevToolsEvaluateResult struct {
+	Target string `json:"target"`
+	Value  any    `json:"value"`
+}
+
+// findDevToolsInspector finds the device and checks it supports DevTools
+func findDevToolsInspector(deviceID string) (devices.ControllableDevice, devices.DevToolsInspector, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	inspector, ok := targetDevice.(devices.DevToolsInspector)
+	if !ok {
+		return nil, nil, fmt.Errorf("web inspection is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, inspector, nil
+}
+
+// DevToolsListCommand lists the browser tabs and debuggable webviews that can
+// be inspected over the DevTools protocol
+func DevToolsListCommand(req DevToolsListRequest) *CommandResponse {
+	targetDevice, inspector, err := findDevToolsInspector(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	targets, err := inspector.ListDevToolsTargets()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list devtools targets on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(DevToolsListResult{Targets: targets})
+}
+
+// DevToolsDumpCommand returns the DOM of a DevTools target
+func DevToolsDumpCommand(req DevToolsDumpRequest) *CommandResponse {
+	if req.TargetID == "" {
+		return NewErrorResponse(fmt.Errorf("target is required, see 'webview list --devtools'"))
+	}
+
+	targetDevice, inspector, err := findDevToolsInspector(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	document, err := inspector.DumpDevToolsTarget(req.TargetID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to dump devtools target on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(DevToolsDumpResult{
+		Target:   req.TargetID,
+		Document: document,
+	})
+}
+
+// DevToolsEvaluateCommand runs a JavaScript expression in a DevTools target
+// and returns its value
+func DevToolsEvaluateCommand(req DevToolsEvaluateRequest) *CommandResponse {
+	if req.TargetID == "" {
+		return NewErrorResponse(fmt.Errorf("target is required, see 'webview list --devtools'"))
+	}
+	if req.Expression == "" {
+		return NewErrorResponse(fmt.Errorf("expression is required"))
+	}
+
+	targetDevice, inspector, err := findDevToolsInspector(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	value, err := inspector.EvaluateDevToolsTarget(req.TargetID, req.Expression)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to evaluate in target on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(DevToolsEvaluateResult{
+		Target: req.TargetID,
+		Value:  value,
+	})
+}
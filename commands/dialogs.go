@@ -0,0 +1,271 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// Kinds of system dialogs the dialog handler dismisses
+const (
+	DialogANR        = "anr"
+	DialogCrash      = "crash"
+	DialogPermission = "permission"
+)
+
+// DialogIgnore leaves a kind of dialog on screen
+const DialogIgnore = "ignore"
+
+// DefaultDialogChoices are the spec "default" stands for: wait for an app
+// that isn't responding, close a crashed app and allow permissions
+const DefaultDialogChoices = "anr=wait,crash=close,permission=allow"
+
+// maxHandledDialogs bounds how many dialogs are dismissed before a command,
+// since dismissing one can show another, e.g. a second permission prompt
+const maxHandledDialogs = 5
+
+// systemDialog is an Android system dialog, recognized by the resource-ids
+// of its buttons
+type systemDialog struct {
+	kind string
+
+	// detect are the ids of which any shows the dialog
+	detect []string
+
+	// choices maps a choice to the ids of the buttons that make it, in order
+	// of preference, since which buttons show depends on the permission and
+	// the Android version
+	choices map[string][]string
+}
+
+// systemDialogs are checked in order, as the ANR dialog also has the crash
+// dialog's close button
+var systemDialogs = []systemDialog{
+	{
+		kind:   DialogANR,
+		detect: []string{"aerr_wait"},
+		choices: map[string][]string{
+			"wait":  {"aerr_wait"},
+			"close": {"aerr_close"},
+		},
+	},
+	{
+		kind:   DialogCrash,
+		detect: []string{"aerr_close", "aerr_restart"},
+		choices: map[string][]string{
+			"close":   {"aerr_close"},
+			"restart": {"aerr_restart", "aerr_close"},
+		},
+	},
+	{
+		kind: DialogPermission,
+		detect: []string{
+			"permission_allow_button",
+			"permission_allow_foreground_only_button",
+			"permission_allow_one_time_button",
+			"permission_deny_button",
+		},
+		choices: map[string][]string{
+			"allow":      {"permission_allow_button", "permission_allow_foreground_only_button", "permission_allow_one_time_button"},
+			"foreground": {"permission_allow_foreground_only_button", "permission_allow_button"},
+			"once":       {"permission_allow_one_time_button", "permission_allow_foreground_only_button", "permission_allow_button"},
+			"deny":       {"permission_deny_button", "permission_deny_and_dont_ask_again_button"},
+		},
+	},
+}
+
+// dialogTitleIDs are the ids of the text that names a dialog in the log
+var dialogTitleIDs = []string{"alertTitle", "permission_message"}
+
+// DialogChoices maps a kind of system dialog to the choice the dialog
+// handler taps on it
+type DialogChoices map[string]string
+
+// ParseDialogChoices parses a spec such as "anr=close,permission=deny".
+// Kinds left out keep their choice of DefaultDialogChoices, and "default"
+// alone is DefaultDialogChoices.
+func ParseDialogChoices(spec string) (DialogChoices, error) {
+	choices := DialogChoices{}
+	for _, part := range []string{DefaultDialogChoices, spec} {
+		if part == "default" {
+			continue
+		}
+
+		for _, pair := range strings.Split(part, ",") {
+			kind, choice, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				return nil, fmt.Errorf("invalid dialog choice '%s', expected kind=choice", pair)
+			}
+
+			dialog := findSystemDialog(kind)
+			if dialog == nil {
+				return nil, fmt.Errorf("unknown dialog '%s', expected one of: %s, %s, %s", kind, DialogANR, DialogCrash, DialogPermission)
+			}
+			if _, ok := dialog.choices[choice]; !ok && choice != DialogIgnore {
+				return nil, fmt.Errorf("unknown choice '%s' for %s dialogs, expected one of: %s", choice, kind, strings.Join(dialog.choiceNames(), ", "))
+			}
+			choices[kind] = choice
+		}
+	}
+	return choices, nil
+}
+
+func findSystemDialog(kind string) *systemDialog {
+	for i := range systemDialogs {
+		if systemDialogs[i].kind == kind {
+			return &systemDialogs[i]
+		}
+	}
+	return nil
+}
+
+func (d systemDialog) choiceNames() []string {
+	names := make([]string, 0, len(d.choices)+1)
+	for choice := range d.choices {
+		names = append(names, choice)
+	}
+	sort.Strings(names)
+	return append(names, DialogIgnore)
+}
+
+var (
+	dialogHandlerMu sync.RWMutex
+	dialogHandler   DialogChoices
+)
+
+// SetDialogHandler makes every command that handles dialogs dismiss system
+// dialogs with choices, as if its request asked to. nil disables it.
+func SetDialogHandler(choices DialogChoices) {
+	dialogHandlerMu.Lock()
+	defer dialogHandlerMu.Unlock()
+	dialogHandler = choices
+}
+
+// dialogChoicesFor returns the choices a request's HandleDialogs spec asks
+// for, falling back to the server's, or nil when dialogs are left alone
+func dialogChoicesFor(spec string) (DialogChoices, error) {
+	if spec != "" {
+		return ParseDialogChoices(spec)
+	}
+
+	dialogHandlerMu.RLock()
+	defer dialogHandlerMu.RUnlock()
+	return dialogHandler, nil
+}
+
+// dismissSystemDialogs handles the system dialogs on a device before a
+// command, with the choices of the request's spec or else the server's
+func dismissSystemDialogs(device devices.ControllableDevice, spec string) error {
+	choices, err := dialogChoicesFor(spec)
+	if err != nil {
+		return err
+	}
+	handleSystemDialogs(device, choices)
+	return nil
+}
+
+// elementID returns the name of an element's resource-id without its
+// package, e.g. "aerr_wait" for "android:id/aerr_wait"
+func elementID(element devices.ScreenElement) string {
+	if element.Identifier == nil {
+		return ""
+	}
+	_, name, found := strings.Cut(*element.Identifier, ":id/")
+	if !found {
+		return *element.Identifier
+	}
+	return name
+}
+
+// detectSystemDialog returns the dialog shown among elements, and the element
+// of each id
+func detectSystemDialog(elements []devices.ScreenElement) (*systemDialog, map[string]devices.ScreenElement) {
+	byID := make(map[string]devices.ScreenElement)
+	for _, element := range elements {
+		if id := elementID(element); id != "" {
+			if _, ok := byID[id]; !ok {
+				byID[id] = element
+			}
+		}
+	}
+
+	for i := range systemDialogs {
+		for _, id := range systemDialogs[i].detect {
+			if _, ok := byID[id]; ok {
+				return &systemDialogs[i], byID
+			}
+		}
+	}
+	return nil, byID
+}
+
+// handleSystemDialogs dismisses the ANR, crash and permission dialogs on an
+// Android device with choices, logging each one, until none is left. Failing
+// to check the screen is logged rather than failing the command it precedes.
+func handleSystemDialogs(device devices.ControllableDevice, choices DialogChoices) {
+	if choices == nil || device.Platform() != "android" {
+		return
+	}
+
+	previous := ""
+	for range maxHandledDialogs {
+		elements, err := device.DumpSource()
+		if err != nil {
+			utils.Verbose("Failed to check device %s for system dialogs: %v", device.ID(), err)
+			return
+		}
+
+		dialog, byID := detectSystemDialog(elements)
+		if dialog == nil {
+			return
+		}
+
+		title := ""
+		for _, id := range dialogTitleIDs {
+			if element, ok := byID[id]; ok {
+				title = elementTextValue(element)
+				break
+			}
+		}
+
+		choice := choices[dialog.kind]
+		if choice == DialogIgnore {
+			utils.Info("Left %s dialog on device %s: %q", dialog.kind, device.ID(), title)
+			return
+		}
+
+		// a dialog still showing after its button was tapped would be tapped
+		// forever
+		current := dialog.kind + "\x00" + title
+		if current == previous {
+			utils.Info("The %s dialog on device %s did not go away: %q", dialog.kind, device.ID(), title)
+			return
+		}
+		previous = current
+
+		var button *devices.ScreenElement
+		for _, id := range dialog.choices[choice] {
+			if element, ok := byID[id]; ok {
+				button = &element
+				break
+			}
+		}
+		if button == nil {
+			utils.Info("The %s dialog on device %s has no button to %s: %q", dialog.kind, device.ID(), choice, title)
+			return
+		}
+
+		x := button.Rect.X + button.Rect.Width/2
+		y := button.Rect.Y + button.Rect.Height/2
+		if err := device.Tap(x, y); err != nil {
+			utils.Info("Failed to %s the %s dialog on device %s: %v", choice, dialog.kind, device.ID(), err)
+			return
+		}
+		InvalidateDumpCache(device.ID())
+		utils.Info("Auto-handled %s dialog on device %s: tapped '%s' at (%d,%d): %q", dialog.kind, device.ID(), choice, x, y, title)
+	}
+}
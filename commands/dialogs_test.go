@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dialogButton(id, text string, x, y int) devices.ScreenElement {
+	return devices.ScreenElement{
+		Type:       "android.widget.Button",
+		Text:       strPtr(text),
+		Identifier: strPtr(id),
+		Rect:       devices.ScreenElementRect{X: x, Y: y, Width: 100, Height: 40},
+	}
+}
+
+func TestParseDialogChoices(t *testing.T) {
+	choices, err := ParseDialogChoices("default")
+	require.NoError(t, err)
+	assert.Equal(t, DialogChoices{DialogANR: "wait", DialogCrash: "close", DialogPermission: "allow"}, choices)
+
+	choices, err = ParseDialogChoices("anr=close, permission=ignore")
+	require.NoError(t, err)
+	assert.Equal(t, DialogChoices{DialogANR: "close", DialogCrash: "close", DialogPermission: DialogIgnore}, choices)
+
+	_, err = ParseDialogChoices("permission=maybe")
+	assert.EqualError(t, err, "unknown choice 'maybe' for permission dialogs, expected one of: allow, deny, foreground, once, ignore")
+
+	_, err = ParseDialogChoices("battery=ok")
+	assert.Error(t, err)
+
+	_, err = ParseDialogChoices("anr")
+	assert.Error(t, err)
+}
+
+func TestDetectSystemDialog(t *testing.T) {
+	anr := []devices.ScreenElement{
+		{Type: "android.widget.TextView", Identifier: strPtr("android:id/alertTitle"), Text: strPtr("Example isn't responding")},
+		dialogButton("android:id/aerr_close", "Close app", 0, 0),
+		dialogButton("android:id/aerr_wait", "Wait", 0, 50),
+	}
+	dialog, _ := detectSystemDialog(anr)
+	require.NotNil(t, dialog)
+	assert.Equal(t, DialogANR, dialog.kind)
+
+	dialog, _ = detectSystemDialog(anr[:2])
+	require.NotNil(t, dialog)
+	assert.Equal(t, DialogCrash, dialog.kind)
+
+	dialog, _ = detectSystemDialog([]devices.ScreenElement{dialogButton("com.android.permissioncontroller:id/permission_deny_button", "Don't allow", 0, 0)})
+	require.NotNil(t, dialog)
+	assert.Equal(t, DialogPermission, dialog.kind)
+
+	dialog, _ = detectSystemDialog([]devices.ScreenElement{dialogButton("com.example:id/login", "Log in", 0, 0)})
+	assert.Nil(t, dialog)
+}
+
+func TestTapHandlesDialogs(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	device := fake.Get("fake-android-1")
+
+	device.SetElements([]devices.ScreenElement{
+		{Type: "android.widget.TextView", Identifier: strPtr("com.android.permissioncontroller:id/permission_message"), Text: strPtr("Allow Example to take pictures?")},
+		dialogButton("com.android.permissioncontroller:id/permission_allow_foreground_only_button", "While using the app", 0, 100),
+		dialogButton("com.android.permissioncontroller:id/permission_deny_button", "Don't allow", 0, 200),
+	})
+
+	// without a spec or a server-wide handler, dialogs are left alone
+	require.Equal(t, "ok", TapCommand(TapRequest{DeviceID: "fake-android-1", X: 1, Y: 2}).Status)
+
+	// allow falls back to the foreground-only button; the fake dialog stays
+	// after its button was tapped, which stops the handler
+	require.Equal(t, "ok", TapCommand(TapRequest{DeviceID: "fake-android-1", X: 3, Y: 4, HandleDialogs: "default"}).Status)
+
+	SetDialogHandler(DialogChoices{DialogPermission: "deny"})
+	t.Cleanup(func() { SetDialogHandler(nil) })
+	require.Equal(t, "ok", TapCommand(TapRequest{DeviceID: "fake-android-1", X: 5, Y: 6}).Status)
+
+	expected := []string{"tap 1,2", "tap 50,120", "tap 3,4", "tap 50,220", "tap 5,6"}
+	if actions := device.Actions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+
+	response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 1, Y: 2, HandleDialogs: "anr=later"})
+	assert.Equal(t, "error", response.Status)
+}
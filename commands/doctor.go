@@ -0,0 +1,18 @@
+package commands
+
+import (
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// DoctorResult reports how mobilecli finds the platform tools it runs
+type DoctorResult struct {
+	Tools []devices.ToolPath `json:"tools"`
+}
+
+// DoctorCommand reports the paths adb, the emulator and xcrun resolve to,
+// where each one came from, and other copies that were passed over
+func DoctorCommand() *CommandResponse {
+	return NewSuccessResponse(DoctorResult{
+		Tools: devices.ResolveToolPaths(),
+	})
+}
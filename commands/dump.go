@@ -1,7 +1,11 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/mobile-next/mobilecli/devices"
 )
 
@@ -9,22 +13,111 @@ import (
 type DumpUIRequest struct {
 	DeviceID string `json:"deviceId"`
 	Format   string `json:"format"`
+	Query    string `json:"query,omitempty"` // see ElementQuery, json format only
+
+	// limits for deep hierarchies, json format only
+	MaxDepth     int  `json:"maxDepth,omitempty"`
+	MaxElements  int  `json:"maxElements,omitempty"`
+	ViewportOnly bool `json:"viewportOnly,omitempty"`
+
+	// HandleDialogs dismisses system dialogs before dumping, see
+	// ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
+
+	// Output is a file to write the dump to, "-" to return it even when an
+	// artifacts directory is set, or empty to write it to the artifacts
+	// directory if one is set and return it otherwise
+	Output string `json:"output,omitempty"`
 }
 
 // DumpUIResponse represents the response for a dump UI command
 type DumpUIResponse struct {
 	Elements []devices.ScreenElement `json:"elements,omitempty"`
 	RawData  any                     `json:"rawData,omitempty"`
+	Limits   *DumpUILimits           `json:"limits,omitempty"`
+	FilePath string                  `json:"filePath,omitempty"` // where the dump was written, instead of returned
+
+	// Cached is true when the dump was reused from an earlier one of the
+	// same screen, see SetDumpCacheTTL
+	Cached bool `json:"cached,omitempty"`
+}
+
+// DumpUILimits echoes the limits a dump was made with
+type DumpUILimits struct {
+	MaxDepth     int  `json:"maxDepth,omitempty"`
+	MaxElements  int  `json:"maxElements,omitempty"`
+	ViewportOnly bool `json:"viewportOnly,omitempty"`
+
+	// Truncated is true when the limits left elements out of the dump
+	Truncated bool `json:"truncated"`
+}
+
+// dumpLimitedSource dumps the UI tree within limits, natively on devices that
+// support it and by pruning the full tree on others
+func dumpLimitedSource(targetDevice devices.ControllableDevice, limits devices.DumpLimits) ([]devices.ScreenElement, bool, error) {
+	var elements []devices.ScreenElement
+	var err error
+	if dumper, ok := targetDevice.(devices.LimitedSourceDumper); ok {
+		elements, err = dumper.DumpSourceWithLimits(limits)
+	} else {
+		elements, err = targetDevice.DumpSource()
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var screen *devices.ScreenSize
+	if limits.ViewportOnly {
+		screen, err = deviceScreenSize(targetDevice)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	elements, truncated := devices.LimitElements(elements, limits, screen)
+	return elements, truncated, nil
 }
 
 // DumpUICommand starts an agent and dumps the UI tree from the specified device
 func DumpUICommand(req DumpUIRequest) *CommandResponse {
+	if req.MaxDepth < 0 || req.MaxElements < 0 {
+		return NewErrorResponse(fmt.Errorf("maxDepth and maxElements cannot be negative"))
+	}
+
+	limits := devices.DumpLimits{
+		MaxDepth:     req.MaxDepth,
+		MaxElements:  req.MaxElements,
+		ViewportOnly: req.ViewportOnly,
+	}
+	if req.Format == "raw" && !limits.IsZero() {
+		return NewErrorResponse(fmt.Errorf("dump limits cannot be used with the raw format"))
+	}
+
+	var query *ElementQuery
+	if req.Query != "" {
+		if req.Format == "raw" {
+			return NewErrorResponse(fmt.Errorf("query cannot be used with the raw format"))
+		}
+
+		var err error
+		query, err = ParseElementQuery(req.Query)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
 	// Find the target device
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	// Start agent if needed
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -33,29 +126,99 @@ func DumpUICommand(req DumpUIRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %w", targetDevice.ID(), err))
 	}
 
-	var response DumpUIResponse
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
 
-	// Check if raw format is requested
-	if req.Format == "raw" {
-		rawData, err := targetDevice.DumpSourceRaw()
-		if err != nil {
-			return NewErrorResponse(fmt.Errorf("failed to dump raw UI from device %s: %w", targetDevice.ID(), err))
-		}
+	key := dumpCacheKey{deviceID: targetDevice.ID(), raw: req.Format == "raw", limits: limits}
+	dump, cached, err := dumps.get(key, targetDevice, func() (uiDump, error) {
+		return dumpUI(targetDevice, key.raw, limits)
+	})
+	if err != nil {
+		return NewErrorResponse(err)
+	}
 
+	var response DumpUIResponse
+	if key.raw {
 		response = DumpUIResponse{
-			RawData: rawData,
+			RawData: dump.rawData,
 		}
 	} else {
-		// Dump UI tree from the device
-		elements, err := targetDevice.DumpSource()
-		if err != nil {
-			return NewErrorResponse(fmt.Errorf("failed to dump UI from device %s: %w", targetDevice.ID(), err))
+		elements := dump.elements
+		if query != nil {
+			elements = query.Filter(elements)
 		}
 
 		response = DumpUIResponse{
 			Elements: elements,
 		}
+		if !limits.IsZero() {
+			response.Limits = &DumpUILimits{
+				MaxDepth:     limits.MaxDepth,
+				MaxElements:  limits.MaxElements,
+				ViewportOnly: limits.ViewportOnly,
+				Truncated:    dump.truncated,
+			}
+		}
+	}
+	response.Cached = cached
+
+	output := req.Output
+	if output == "" {
+		output, err = ArtifactPath(targetDevice.ID(), "dump-ui", "json")
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+	if output != "" && output != "-" {
+		path, err := writeDumpUI(response, output)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		return NewSuccessResponse(DumpUIResponse{Limits: response.Limits, Cached: cached, FilePath: path})
 	}
 
 	return NewSuccessResponse(response)
 }
+
+// dumpUI dumps the raw or parsed UI tree of a device
+func dumpUI(targetDevice devices.ControllableDevice, raw bool, limits devices.DumpLimits) (uiDump, error) {
+	if raw {
+		rawData, err := targetDevice.DumpSourceRaw()
+		if err != nil {
+			return uiDump{}, fmt.Errorf("failed to dump raw UI from device %s: %w", targetDevice.ID(), err)
+		}
+		return uiDump{rawData: rawData}, nil
+	}
+
+	var elements []devices.ScreenElement
+	var truncated bool
+	var err error
+	if limits.IsZero() {
+		elements, err = targetDevice.DumpSource()
+	} else {
+		elements, truncated, err = dumpLimitedSource(targetDevice, limits)
+	}
+	if err != nil {
+		return uiDump{}, fmt.Errorf("failed to dump UI from device %s: %w", targetDevice.ID(), err)
+	}
+	return uiDump{elements: elements, truncated: truncated}, nil
+}
+
+// writeDumpUI writes a dump to a file as JSON and returns its absolute path
+func writeDumpUI(response DumpUIResponse, output string) (string, error) {
+	path, err := filepath.Abs(output)
+	if err != nil {
+		return "", fmt.Errorf("invalid output path: %v", err)
+	}
+
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode UI dump: %v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+	return path, nil
+}
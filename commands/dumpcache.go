@@ -0,0 +1,141 @@
+package commands
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// dumpCacheKey identifies a cached dump: the same device, format and limits
+// dump the same screen the same way
+type dumpCacheKey struct {
+	deviceID string
+	raw      bool
+	limits   devices.DumpLimits
+}
+
+// uiDump is a dump before the query filters it
+type uiDump struct {
+	elements  []devices.ScreenElement
+	truncated bool
+	rawData   any
+}
+
+type cachedDump struct {
+	dump        uiDump
+	fingerprint string
+	dumpedAt    time.Time
+}
+
+// dumpCache reuses a dump while the screen's fingerprint is unchanged, so
+// agents dumping a static screen back to back don't each wait seconds for a
+// snapshot. Input commands invalidate a device's dumps by bumping its
+// generation.
+type dumpCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	entries     map[dumpCacheKey]*cachedDump
+	generations map[string]uint64
+}
+
+var dumps = &dumpCache{
+	entries:     make(map[dumpCacheKey]*cachedDump),
+	generations: make(map[string]uint64),
+}
+
+// SetDumpCacheTTL makes UI dumps of a device reuse a dump made within ttl
+// when the screen looks the same. 0 disables the cache.
+func SetDumpCacheTTL(ttl time.Duration) {
+	dumps.mu.Lock()
+	defer dumps.mu.Unlock()
+	dumps.ttl = ttl
+	clear(dumps.entries)
+}
+
+// InvalidateDumpCache drops the cached UI dumps of a device, or of every
+// device when deviceID is empty
+func InvalidateDumpCache(deviceID string) {
+	dumps.mu.Lock()
+	defer dumps.mu.Unlock()
+
+	if deviceID == "" {
+		for id := range dumps.generations {
+			dumps.generations[id]++
+		}
+		clear(dumps.entries)
+		return
+	}
+
+	dumps.generations[deviceID]++
+	for key := range dumps.entries {
+		if key.deviceID == deviceID {
+			delete(dumps.entries, key)
+		}
+	}
+}
+
+// screenFingerprint returns the device's own cheap fingerprint of the screen
+// when it has one, and a thumbnail of a screenshot otherwise. Devices without
+// one pay for a full screenshot on every dump, which is still far quicker
+// than a snapshot of the accessibility tree.
+func screenFingerprint(targetDevice devices.ControllableDevice) (string, error) {
+	if fingerprinter, ok := targetDevice.(devices.ScreenFingerprinter); ok {
+		fingerprint, err := fingerprinter.ScreenFingerprint()
+		if err == nil {
+			return fingerprint, nil
+		}
+		utils.Verbose("screen fingerprint of %s failed, using a screenshot: %v", targetDevice.ID(), err)
+	}
+
+	screenshot, err := targetDevice.TakeScreenshot()
+	if err != nil {
+		return "", err
+	}
+	return utils.PngFingerprint(screenshot), nil
+}
+
+// get returns a dump of key, made with dump unless one made within the ttl
+// has the same screen fingerprint. The caller holds the device's lock.
+func (c *dumpCache) get(key dumpCacheKey, targetDevice devices.ControllableDevice, dump func() (uiDump, error)) (uiDump, bool, error) {
+	c.mu.Lock()
+	ttl := c.ttl
+	generation := c.generations[key.deviceID]
+	c.mu.Unlock()
+
+	if ttl <= 0 {
+		result, err := dump()
+		return result, false, err
+	}
+
+	// the fingerprint is taken before the dump, so a screen changing during
+	// the dump leaves a fingerprint the next one won't match
+	fingerprint, err := screenFingerprint(targetDevice)
+	if err != nil {
+		utils.Verbose("not caching UI dump of %s, fingerprint failed: %v", key.deviceID, err)
+		result, err := dump()
+		return result, false, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && entry.fingerprint == fingerprint && time.Since(entry.dumpedAt) <= ttl {
+		return entry.dump, true, nil
+	}
+
+	result, err := dump()
+	if err != nil {
+		return uiDump{}, false, err
+	}
+
+	// an input command during the dump makes it stale before it is cached
+	c.mu.Lock()
+	if c.generations[key.deviceID] == generation {
+		c.entries[key] = &cachedDump{dump: result, fingerprint: fingerprint, dumpedAt: time.Now()}
+	}
+	c.mu.Unlock()
+
+	return result, false, nil
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+// dumpLabels dumps the UI of a device and returns the labels of its elements
+// and whether the dump was cached
+func dumpLabels(t *testing.T, deviceID string) ([]string, bool) {
+	t.Helper()
+	response := DumpUICommand(DumpUIRequest{DeviceID: deviceID, Output: "-"})
+	if response.Status != "ok" {
+		t.Fatalf("dump failed: %s", response.Error)
+	}
+
+	dump := response.Data.(DumpUIResponse)
+	var labels []string
+	for _, element := range dump.Elements {
+		if element.Label != nil {
+			labels = append(labels, *element.Label)
+		}
+	}
+	return labels, dump.Cached
+}
+
+func setFakeLabel(device *fake.Device, label string) {
+	device.SetElements([]devices.ScreenElement{{Type: "Button", Label: &label}})
+}
+
+func TestDumpUICache(t *testing.T) {
+	useFakeDevices(t, 1)
+	t.Cleanup(func() { SetDumpCacheTTL(0) })
+
+	if _, err := FindDeviceOrAutoSelect("fake-android-1"); err != nil {
+		t.Fatalf("fake device not found: %v", err)
+	}
+	device := fake.Get("fake-android-1")
+
+	// off by default
+	setFakeLabel(device, "first")
+	dumpLabels(t, "fake-android-1")
+	setFakeLabel(device, "second")
+	if labels, cached := dumpLabels(t, "fake-android-1"); cached || labels[0] != "second" {
+		t.Fatalf("expected a fresh dump with the cache off, got %v cached=%v", labels, cached)
+	}
+
+	SetDumpCacheTTL(time.Minute)
+	dumpLabels(t, "fake-android-1")
+
+	// the screen looks the same, so the earlier dump is reused
+	setFakeLabel(device, "third")
+	if labels, cached := dumpLabels(t, "fake-android-1"); !cached || labels[0] != "second" {
+		t.Fatalf("expected the cached dump, got %v cached=%v", labels, cached)
+	}
+
+	// a changed screen is dumped again
+	device.SetScreenshot([]byte("changed"))
+	if labels, cached := dumpLabels(t, "fake-android-1"); cached || labels[0] != "third" {
+		t.Fatalf("expected a fresh dump of the changed screen, got %v cached=%v", labels, cached)
+	}
+
+	// as is the screen after an input command
+	setFakeLabel(device, "fourth")
+	InvalidateDumpCache("fake-android-1")
+	if labels, cached := dumpLabels(t, "fake-android-1"); cached || labels[0] != "fourth" {
+		t.Fatalf("expected a fresh dump after input, got %v cached=%v", labels, cached)
+	}
+}
+
+// fingerprintedDevice is a fake device with a cheap screen fingerprint
+type fingerprintedDevice struct {
+	*fake.Device
+	fingerprint string
+}
+
+func (d *fingerprintedDevice) ScreenFingerprint() (string, error) {
+	return d.fingerprint, nil
+}
+
+func TestDumpUICacheUsesScreenFingerprint(t *testing.T) {
+	SetDumpCacheTTL(time.Minute)
+	t.Cleanup(func() { SetDumpCacheTTL(0) })
+
+	// a screenshot would fail, so only the fingerprint can be used
+	device := &fingerprintedDevice{Device: fake.New("fake-android-fingerprint", "android"), fingerprint: "first"}
+	device.FailWith("TakeScreenshot", errors.New("no screenshots"))
+
+	dumped := 0
+	dump := func() (uiDump, error) {
+		dumped++
+		return uiDump{}, nil
+	}
+	key := dumpCacheKey{deviceID: device.ID()}
+
+	for range 2 {
+		if _, _, err := dumps.get(key, device, dump); err != nil {
+			t.Fatalf("dump failed: %v", err)
+		}
+	}
+	if dumped != 1 {
+		t.Fatalf("expected the second dump to be cached, dumped %d times", dumped)
+	}
+
+	device.fingerprint = "second"
+	if _, cached, err := dumps.get(key, device, dump); err != nil || cached {
+		t.Fatalf("expected a fresh dump of the changed screen, cached=%v err=%v", cached, err)
+	}
+}
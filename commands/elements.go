@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// ElementSelector matches a screen element by a single attribute, e.g. "identifier=loginButton"
+type ElementSelector struct {
+	Attribute string
+	Value     string
+}
+
+var elementSelectorAttributes = []string{"identifier", "label", "name", "text", "value", "placeholder", "type"}
+
+// ParseElementSelector parses an "attribute=value" selector string
+func ParseElementSelector(s string) (ElementSelector, error) {
+	attr, value, found := strings.Cut(s, "=")
+	if !found || attr == "" || value == "" {
+		return ElementSelector{}, fmt.Errorf("invalid element selector '%s', expected attribute=value", s)
+	}
+
+	attr = strings.ToLower(strings.TrimSpace(attr))
+	for _, supported := range elementSelectorAttributes {
+		if attr == supported {
+			return ElementSelector{Attribute: attr, Value: value}, nil
+		}
+	}
+
+	return ElementSelector{}, fmt.Errorf("unsupported element attribute '%s', supported attributes are: %s", attr, strings.Join(elementSelectorAttributes, ", "))
+}
+
+// Matches reports whether the element matches the selector
+func (s ElementSelector) Matches(element devices.ScreenElement) bool {
+	var field *string
+	switch s.Attribute {
+	case "identifier":
+		field = element.Identifier
+	case "label":
+		field = element.Label
+	case "name":
+		field = element.Name
+	case "text":
+		field = element.Text
+	case "value":
+		field = element.Value
+	case "placeholder":
+		field = element.Placeholder
+	case "type":
+		return element.Type == s.Value
+	}
+
+	return field != nil && *field == s.Value
+}
+
+// FindElement returns the first element (depth-first, including children) matching the selector
+func FindElement(elements []devices.ScreenElement, selector ElementSelector) *devices.ScreenElement {
+	for i := range elements {
+		if selector.Matches(elements[i]) {
+			return &elements[i]
+		}
+
+		if found := FindElement(elements[i].Children, selector); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// FindFocusedElement returns the first element (depth-first, including children) reporting focus
+func FindFocusedElement(elements []devices.ScreenElement) *devices.ScreenElement {
+	for i := range elements {
+		if elements[i].Focused != nil && *elements[i].Focused {
+			return &elements[i]
+		}
+
+		if found := FindFocusedElement(elements[i].Children); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// elementTextValue returns the text currently entered in an element, treating a
+// value that only echoes the placeholder as empty
+func elementTextValue(element devices.ScreenElement) string {
+	value := element.Value
+	if value == nil {
+		value = element.Text
+	}
+	if value == nil {
+		return ""
+	}
+
+	if element.Placeholder != nil && *value == *element.Placeholder {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,88 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestParseElementSelector(t *testing.T) {
+	selector, err := ParseElementSelector("identifier=loginButton")
+	require.NoError(t, err)
+	assert.Equal(t, ElementSelector{Attribute: "identifier", Value: "loginButton"}, selector)
+
+	selector, err = ParseElementSelector("Label=Sign in=now")
+	require.NoError(t, err)
+	assert.Equal(t, ElementSelector{Attribute: "label", Value: "Sign in=now"}, selector)
+
+	_, err = ParseElementSelector("loginButton")
+	assert.Error(t, err)
+
+	_, err = ParseElementSelector("color=red")
+	assert.Error(t, err)
+
+	_, err = ParseElementSelector("identifier=")
+	assert.Error(t, err)
+}
+
+func TestFindElement(t *testing.T) {
+	elements := []devices.ScreenElement{
+		{
+			Type:  "Window",
+			Label: strPtr("root"),
+			Children: []devices.ScreenElement{
+				{Type: "Button", Identifier: strPtr("loginButton"), Rect: devices.ScreenElementRect{X: 10, Y: 20, Width: 30, Height: 40}},
+			},
+		},
+		{Type: "Button", Text: strPtr("Cancel")},
+	}
+
+	found := FindElement(elements, ElementSelector{Attribute: "identifier", Value: "loginButton"})
+	require.NotNil(t, found)
+	assert.Equal(t, 30, found.Rect.Width)
+
+	found = FindElement(elements, ElementSelector{Attribute: "text", Value: "Cancel"})
+	require.NotNil(t, found)
+	assert.Equal(t, "Button", found.Type)
+
+	assert.Nil(t, FindElement(elements, ElementSelector{Attribute: "name", Value: "missing"}))
+}
+
+func TestFindFocusedElement(t *testing.T) {
+	focused := true
+	elements := []devices.ScreenElement{
+		{Type: "Button", Label: strPtr("Back")},
+		{
+			Type: "Other",
+			Children: []devices.ScreenElement{
+				{Type: "TextField", Value: strPtr("hello"), Focused: &focused},
+			},
+		},
+	}
+
+	found := FindFocusedElement(elements)
+	require.NotNil(t, found)
+	assert.Equal(t, "TextField", found.Type)
+
+	assert.Nil(t, FindFocusedElement(elements[:1]))
+}
+
+func TestClearTextKeys(t *testing.T) {
+	field := &devices.ScreenElement{Text: strPtr("héllo")}
+	keys := clearTextKeys("android", field)
+	require.Len(t, keys, 6)
+	assert.Equal(t, "end", keys[0].Key)
+	assert.Equal(t, "backspace", keys[5].Key)
+
+	// a value that only echoes the hint is empty
+	hint := &devices.ScreenElement{Text: strPtr("Email"), Placeholder: strPtr("Email")}
+	assert.Empty(t, clearTextKeys("android", hint))
+
+	// without a focused element iOS still selects all and deletes
+	keys = clearTextKeys("ios", nil)
+	assert.Equal(t, []devices.KeyCombo{{Key: "a", Modifiers: []string{"command"}}, {Key: "backspace"}}, keys)
+}
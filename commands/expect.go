@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// expectPollInterval is how often an expectation is re-checked until its timeout
+const expectPollInterval = 500 * time.Millisecond
+
+// ExpectResult explains the outcome of an expectation. It is the data of both
+// the success and the failure response, so callers can see what was observed.
+type ExpectResult struct {
+	Passed      bool   `json:"passed"`
+	Expectation string `json:"expectation"`
+	Actual      any    `json:"actual,omitempty"`
+	Message     string `json:"message"`
+}
+
+// expectCheck evaluates an expectation once, returning whether it holds and
+// what was actually observed
+type expectCheck func() (bool, any, error)
+
+// runExpectation re-checks an expectation until it holds or the timeout (in
+// milliseconds, 0 to check once) expires. A failed expectation is an error
+// response that still carries the ExpectResult.
+func runExpectation(expectation string, timeoutMs int, check expectCheck) *CommandResponse {
+	if timeoutMs < 0 {
+		return NewErrorResponse(fmt.Errorf("timeout must be non-negative, got %d", timeoutMs))
+	}
+
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		passed, actual, err := check()
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+
+		if passed {
+			return NewSuccessResponse(ExpectResult{
+				Passed:      true,
+				Expectation: expectation,
+				Actual:      actual,
+				Message:     fmt.Sprintf("expected %s", expectation),
+			})
+		}
+
+		if !time.Now().Before(deadline) {
+			message := fmt.Sprintf("expected %s", expectation)
+			if timeoutMs > 0 {
+				message = fmt.Sprintf("expected %s within %dms", expectation, timeoutMs)
+			}
+
+			return &CommandResponse{
+				Status: "error",
+				Error:  message,
+				Data: ExpectResult{
+					Passed:      false,
+					Expectation: expectation,
+					Actual:      actual,
+					Message:     message,
+				},
+			}
+		}
+
+		time.Sleep(expectPollInterval)
+	}
+}
+
+// findExpectDevice finds the device and starts its agent, which UI expectations
+// need, holding the device lock while the agent starts
+func findExpectDevice(deviceID string) (devices.ControllableDevice, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	return targetDevice, nil
+}
+
+// lockedCheck takes the device lock for each check rather than for the whole
+// wait, so the commands an expectation waits on can still drive the device
+func lockedCheck(deviceID string, check expectCheck) expectCheck {
+	return func() (bool, any, error) {
+		unlock, err := LockDevice(deviceID)
+		if err != nil {
+			return false, nil, err
+		}
+		defer unlock()
+
+		return check()
+	}
+}
+
+// ExpectElementRequest represents the parameters for expecting an element on screen.
+// Text matches any of the element's text, label, value or name; Element is an
+// "attribute=value" selector. Exactly one of them is required.
+type ExpectElementRequest struct {
+	DeviceID string `json:"deviceId"`
+	Text     string `json:"text,omitempty"`
+	Element  string `json:"element,omitempty"`
+	Timeout  int    `json:"timeout,omitempty"` // milliseconds
+}
+
+// elementHasText reports whether any of the element's visible strings equals text
+func elementHasText(element devices.ScreenElement, text string) bool {
+	for _, field := range []*string{element.Text, element.Label, element.Value, element.Name} {
+		if field != nil && *field == text {
+			return true
+		}
+	}
+	return false
+}
+
+// findElementWithText returns the first element (depth-first, including children) showing text
+func findElementWithText(elements []devices.ScreenElement, text string) *devices.ScreenElement {
+	for i := range elements {
+		if elementHasText(elements[i], text) {
+			return &elements[i]
+		}
+
+		if found := findElementWithText(elements[i].Children, text); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// ExpectElementCommand expects an element to be on screen
+func ExpectElementCommand(req ExpectElementRequest) *CommandResponse {
+	if (req.Text == "") == (req.Element == "") {
+		return NewErrorResponse(fmt.Errorf("exactly one of text or element is required"))
+	}
+
+	find := func(elements []devices.ScreenElement) *devices.ScreenElement {
+		return findElementWithText(elements, req.Text)
+	}
+	expectation := fmt.Sprintf("element with text '%s' on screen", req.Text)
+
+	if req.Element != "" {
+		selector, err := ParseElementSelector(req.Element)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+
+		find = func(elements []devices.ScreenElement) *devices.ScreenElement {
+			return FindElement(elements, selector)
+		}
+		expectation = fmt.Sprintf("element %s on screen", req.Element)
+	}
+
+	targetDevice, err := findExpectDevice(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return runExpectation(expectation, req.Timeout, lockedCheck(targetDevice.ID(), func() (bool, any, error) {
+		elements, err := targetDevice.DumpSource()
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to dump UI on device %s: %v", targetDevice.ID(), err)
+		}
+
+		if element := find(elements); element != nil {
+			return true, element, nil
+		}
+		return false, nil, nil
+	}))
+}
+
+// ExpectAppInstalledRequest represents the parameters for expecting an installed app
+type ExpectAppInstalledRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+	Timeout  int    `json:"timeout,omitempty"` // milliseconds
+}
+
+// ExpectAppInstalledCommand expects an app to be installed
+func ExpectAppInstalledCommand(req ExpectAppInstalledRequest) *CommandResponse {
+	if req.BundleID == "" {
+		return NewErrorResponse(fmt.Errorf("bundleId is required"))
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	expectation := fmt.Sprintf("app %s installed", req.BundleID)
+	return runExpectation(expectation, req.Timeout, func() (bool, any, error) {
+		apps, err := targetDevice.ListApps(false)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to list apps on device %s: %v", targetDevice.ID(), err)
+		}
+
+		for _, app := range apps {
+			if app.PackageName == req.BundleID {
+				return true, app, nil
+			}
+		}
+		return false, nil, nil
+	})
+}
+
+// ExpectAppForegroundRequest represents the parameters for expecting the foreground app
+type ExpectAppForegroundRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+	Timeout  int    `json:"timeout,omitempty"` // milliseconds
+}
+
+// ExpectAppForegroundCommand expects an app to be in the foreground
+func ExpectAppForegroundCommand(req ExpectAppForegroundRequest) *CommandResponse {
+	if req.BundleID == "" {
+		return NewErrorResponse(fmt.Errorf("bundleId is required"))
+	}
+
+	targetDevice, err := findExpectDevice(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	expectation := fmt.Sprintf("app %s in the foreground", req.BundleID)
+	return runExpectation(expectation, req.Timeout, lockedCheck(targetDevice.ID(), func() (bool, any, error) {
+		app, err := targetDevice.GetForegroundApp()
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to get foreground app on device %s: %v", targetDevice.ID(), err)
+		}
+
+		return app.PackageName == req.BundleID, app, nil
+	}))
+}
+
+// ExpectOrientationRequest represents the parameters for expecting an orientation
+type ExpectOrientationRequest struct {
+	DeviceID    string `json:"deviceId"`
+	Orientation string `json:"orientation"`
+	Timeout     int    `json:"timeout,omitempty"` // milliseconds
+}
+
+// ExpectOrientationCommand expects the device to be in an orientation.
+// "landscape" is met by either landscape orientation.
+func ExpectOrientationCommand(req ExpectOrientationRequest) *CommandResponse {
+	expected, err := devices.NormalizeOrientation(req.Orientation)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	targetDevice, err := findExpectDevice(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	expectation := fmt.Sprintf("orientation %s", req.Orientation)
+	return runExpectation(expectation, req.Timeout, lockedCheck(targetDevice.ID(), func() (bool, any, error) {
+		orientation, err := targetDevice.GetOrientation()
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to get orientation: %v", err)
+		}
+
+		if req.Orientation == devices.OrientationLandscape {
+			return devices.IsLandscape(orientation), orientation, nil
+		}
+		return orientation == expected, orientation, nil
+	}))
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExpectationPasses(t *testing.T) {
+	response := runExpectation("orientation portrait", 0, func() (bool, any, error) {
+		return true, "portrait", nil
+	})
+
+	assert.Equal(t, "ok", response.Status)
+	assert.Equal(t, true, response.Data.(ExpectResult).Passed)
+}
+
+func TestRunExpectationRetriesUntilTimeout(t *testing.T) {
+	calls := 0
+	response := runExpectation("orientation landscape", 600, func() (bool, any, error) {
+		calls++
+		return false, "portrait", nil
+	})
+
+	require.Equal(t, "error", response.Status)
+	assert.Equal(t, "expected orientation landscape within 600ms", response.Error)
+	assert.Equal(t, "portrait", response.Data.(ExpectResult).Actual)
+	assert.GreaterOrEqual(t, calls, 2)
+}
+
+func TestFindElementWithText(t *testing.T) {
+	elements := []devices.ScreenElement{
+		{Type: "Other", Children: []devices.ScreenElement{
+			{Type: "StaticText", Label: strPtr("Welcome")},
+		}},
+	}
+
+	found := findElementWithText(elements, "Welcome")
+	require.NotNil(t, found)
+	assert.Equal(t, "StaticText", found.Type)
+	assert.Nil(t, findElementWithText(elements, "Goodbye"))
+}
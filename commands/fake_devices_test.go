@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+// useFakeDevices lists count fake devices instead of real ones for the
+// duration of the test
+func useFakeDevices(t *testing.T, count int) {
+	t.Setenv(devices.FakeDevicesEnvVar, strconv.Itoa(count))
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	fake.Reset()
+
+	lastUsedMu.Lock()
+	lastUsedDevice = ""
+	lastUsedMu.Unlock()
+
+	mu.Lock()
+	deviceCache = make(map[string]devices.ControllableDevice)
+	pruneMisses = make(map[string]int)
+	mu.Unlock()
+
+	t.Cleanup(func() {
+		fake.Reset()
+		mu.Lock()
+		deviceCache = make(map[string]devices.ControllableDevice)
+		mu.Unlock()
+	})
+}
+
+func TestFakeDevicesAreListed(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	list, err := devices.GetDeviceInfoList(devices.DeviceListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var ids []string
+	for _, d := range list {
+		ids = append(ids, d.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"fake-android-1", "fake-ios-2"}) {
+		t.Errorf("unexpected devices: %v", ids)
+	}
+}
+
+func TestFakeDeviceCommands(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	if response := TapCommand(TapRequest{DeviceID: "fake-ios-2", X: 10, Y: 20}); response.Status != "ok" {
+		t.Fatalf("tap failed: %s", response.Error)
+	}
+
+	if response := LaunchAppCommand(AppRequest{DeviceID: "fake-ios-2", BundleID: "com.apple.Preferences"}); response.Status != "ok" {
+		t.Fatalf("launch failed: %s", response.Error)
+	}
+
+	if response := LaunchAppCommand(AppRequest{DeviceID: "fake-ios-2", BundleID: "com.example.missing"}); response.Status != "error" {
+		t.Errorf("expected launching an app that isn't installed to fail")
+	}
+
+	actions := fake.Get("fake-ios-2").Actions()
+	if !reflect.DeepEqual(actions, []string{"tap 10,20", "launch com.apple.Preferences"}) {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+
+	if actions := fake.Get("fake-android-1").Actions(); len(actions) != 0 {
+		t.Errorf("expected no actions on the other device, got %v", actions)
+	}
+}
+
+func TestFakeDeviceErrorInjection(t *testing.T) {
+	useFakeDevices(t, 2)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	fake.Get("fake-android-1").FailWith("Tap", errors.New("device is locked"))
+
+	response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 1, Y: 1})
+	if response.Status != "error" || !strings.Contains(response.Error, "device is locked") {
+		t.Errorf("expected the injected error, got %+v", response)
+	}
+}
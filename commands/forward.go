@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"sort"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// ForwardListRequest represents the parameters for listing port forwarders.
+// An empty DeviceID lists the forwarders of every device.
+type ForwardListRequest struct {
+	DeviceID string `json:"deviceId,omitempty"`
+}
+
+// ForwardListResponse lists the port forwarders held open by this process
+type ForwardListResponse struct {
+	Forwards []devices.PortForward `json:"forwards"`
+}
+
+// ForwardListCommand lists the port forwarders of the devices this process
+// has used. Forwarders only live as long as the process, so this is mostly
+// useful against a running server.
+func ForwardListCommand(req ForwardListRequest) *CommandResponse {
+	mu.RLock()
+	var listers []devices.PortForwardLister
+	for id, device := range deviceCache {
+		if req.DeviceID != "" && id != req.DeviceID {
+			continue
+		}
+		if lister, ok := device.(devices.PortForwardLister); ok {
+			listers = append(listers, lister)
+		}
+	}
+	mu.RUnlock()
+
+	forwards := []devices.PortForward{}
+	for _, lister := range listers {
+		forwards = append(forwards, lister.PortForwards()...)
+	}
+
+	sort.Slice(forwards, func(i, j int) bool {
+		if forwards[i].DeviceID != forwards[j].DeviceID {
+			return forwards[i].DeviceID < forwards[j].DeviceID
+		}
+		return forwards[i].LocalPort < forwards[j].LocalPort
+	})
+
+	return NewSuccessResponse(ForwardListResponse{
+		Forwards: forwards,
+	})
+}
+
+// pruneMissLimit is how many listings in a row a cached device must be
+// missing from before it's pruned, so one failed adb or usbmux poll doesn't
+// tear down the forwarders of a device that's still attached
+const pruneMissLimit = 3
+
+// pruneMisses counts the listings in a row each cached device was missing
+// from, guarded by mu
+var pruneMisses = make(map[string]int)
+
+// PruneDisconnectedDevices drops cached devices that are no longer connected
+// and releases their resources, so their port forwarders don't outlive them
+func PruneDisconnectedDevices() {
+	// a backend that timed out leaves its devices out though they're still
+	// attached, so only a complete listing tells which were disconnected
+	connected, warnings := devices.ListAllControllableDevicesWithWarnings(false, false)
+	if len(warnings) > 0 {
+		utils.Verbose("not pruning devices, the listing is incomplete: %s", warnings[0].Message)
+		return
+	}
+
+	online := make(map[string]bool, len(connected))
+	for _, d := range connected {
+		if d.State() == "online" {
+			online[d.ID()] = true
+		}
+	}
+
+	var pruned []devices.ControllableDevice
+	mu.Lock()
+	for id := range pruneMisses {
+		if _, cached := deviceCache[id]; !cached {
+			delete(pruneMisses, id)
+		}
+	}
+	for id, device := range deviceCache {
+		if _, remote := device.(*devices.RemoteDevice); remote || online[device.ID()] {
+			delete(pruneMisses, id)
+			continue
+		}
+
+		pruneMisses[id]++
+		if pruneMisses[id] < pruneMissLimit {
+			continue
+		}
+		pruned = append(pruned, device)
+		delete(deviceCache, id)
+		delete(pruneMisses, id)
+	}
+	mu.Unlock()
+
+	for _, device := range pruned {
+		utils.Verbose("device %s disconnected, releasing its resources", device.ID())
+		if cleaner, ok := device.(interface{ Cleanup() error }); ok {
+			if err := cleaner.Cleanup(); err != nil {
+				utils.Verbose("failed to clean up device %s: %v", device.ID(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"testing"
+)
+
+func TestPruneDisconnectedDevicesWaitsForRepeatedMisses(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if _, err := FindDevice("fake-android-1"); err != nil {
+		t.Fatalf("failed to find device: %v", err)
+	}
+
+	cached := func() bool {
+		mu.RLock()
+		defer mu.RUnlock()
+		_, ok := deviceCache["fake-android-1"]
+		return ok
+	}
+
+	// still listed, so never pruned
+	PruneDisconnectedDevices()
+	if !cached() {
+		t.Fatalf("a connected device was pruned")
+	}
+
+	// gone from the listing, e.g. a failed adb poll
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "1")
+	for i := 1; i < pruneMissLimit; i++ {
+		PruneDisconnectedDevices()
+		if !cached() {
+			t.Fatalf("device pruned after %d missed listings", i)
+		}
+	}
+
+	PruneDisconnectedDevices()
+	if cached() {
+		t.Errorf("device not pruned after %d missed listings", pruneMissLimit)
+	}
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// guardPollInterval is how often the device is checked for new crash reports
+// while a guard settles
+const guardPollInterval = 500 * time.Millisecond
+
+// GuardRequest represents the parameters for running commands while watching
+// an app for crashes
+type GuardRequest struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+
+	// Settle is how long, in milliseconds, to keep looking for crash reports
+	// after the commands ran, since crash reports are written with a delay
+	Settle int `json:"settle,omitempty"`
+}
+
+// GuardedCommand is a command run by a guard and how it exited
+type GuardedCommand struct {
+	Args     []string `json:"args"`
+	ExitCode int      `json:"exitCode"`
+}
+
+// GuardResult reports whether the app crashed while the guarded commands ran
+type GuardResult struct {
+	Passed   bool                  `json:"passed"`
+	DeviceID string                `json:"deviceId"`
+	BundleID string                `json:"bundleId"`
+	Commands []GuardedCommand      `json:"commands"`
+	Crashes  []devices.CrashReport `json:"crashes"`
+	Message  string                `json:"message"`
+}
+
+// GuardCommand snapshots the crash reports of an app, calls run and then
+// fails if new crash reports of the app appeared. run returns the commands
+// it ran, stopping at the first one that fails. The response is an error
+// carrying the GuardResult when the app crashed or a command failed.
+func GuardCommand(req GuardRequest, run func(deviceID string) []GuardedCommand) *CommandResponse {
+	if req.Settle < 0 {
+		return NewErrorResponse(fmt.Errorf("settle must be non-negative, got %d", req.Settle))
+	}
+
+	snapshot, err := SnapshotAppCrashes(AppCrashesRequest{DeviceID: req.DeviceID, BundleID: req.BundleID})
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	result := GuardResult{
+		DeviceID: snapshot.DeviceID(),
+		BundleID: req.BundleID,
+		Commands: run(snapshot.DeviceID()),
+	}
+
+	deadline := time.Now().Add(time.Duration(req.Settle) * time.Millisecond)
+	for {
+		crashes, err := snapshot.NewCrashes()
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		result.Crashes = append(result.Crashes, crashes...)
+
+		if len(result.Crashes) > 0 || !time.Now().Before(deadline) {
+			break
+		}
+		time.Sleep(guardPollInterval)
+	}
+	if result.Crashes == nil {
+		result.Crashes = []devices.CrashReport{}
+	}
+
+	var failed *GuardedCommand
+	for i := range result.Commands {
+		if result.Commands[i].ExitCode != 0 {
+			failed = &result.Commands[i]
+			break
+		}
+	}
+
+	switch {
+	case len(result.Crashes) > 0:
+		ids := make([]string, 0, len(result.Crashes))
+		for _, crash := range result.Crashes {
+			ids = append(ids, crash.ID)
+		}
+		result.Message = fmt.Sprintf("%s crashed during the guarded commands: %s", req.BundleID, strings.Join(ids, ", "))
+	case failed != nil:
+		result.Message = fmt.Sprintf("'%s' exited with code %d", strings.Join(failed.Args, " "), failed.ExitCode)
+	default:
+		result.Passed = true
+		result.Message = fmt.Sprintf("%s did not crash during the guarded commands", req.BundleID)
+		return NewSuccessResponse(result)
+	}
+
+	return &CommandResponse{
+		Status: "error",
+		Error:  result.Message,
+		Data:   result,
+	}
+}
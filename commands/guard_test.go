@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestGuardCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if _, err := FindDeviceOrAutoSelect("fake-android-1"); err != nil {
+		t.Fatalf("fake device not found: %v", err)
+	}
+	device := fake.Get("fake-android-1")
+	device.AddCrashReport("com.example.app-2025-01-01-094100.ips", []byte("earlier"))
+
+	req := GuardRequest{DeviceID: "fake-android-1", BundleID: "com.example.app"}
+	tap := GuardedCommand{Args: []string{"io", "tap", "1,2"}}
+
+	response := GuardCommand(req, func(deviceID string) []GuardedCommand {
+		if deviceID != "fake-android-1" {
+			t.Errorf("unexpected device %s", deviceID)
+		}
+		// crashes of other apps don't fail the guard
+		device.AddCrashReport("com.other.app-2025-01-01-094200.ips", []byte("other"))
+		return []GuardedCommand{tap}
+	})
+	if response.Status != "ok" {
+		t.Fatalf("expected the guard to pass, got %s", response.Error)
+	}
+
+	response = GuardCommand(req, func(string) []GuardedCommand {
+		device.AddCrashReport("com.example.app-2025-01-01-094300.ips", []byte("crash"))
+		return []GuardedCommand{tap}
+	})
+	if response.Status != "error" {
+		t.Fatal("expected the guard to fail on a new crash")
+	}
+	result := response.Data.(GuardResult)
+	if result.Passed || len(result.Crashes) != 1 || result.Crashes[0].ID != "com.example.app-2025-01-01-094300.ips" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	response = GuardCommand(req, func(string) []GuardedCommand {
+		return []GuardedCommand{{Args: []string{"io", "tap", "1,2"}, ExitCode: 1}}
+	})
+	if response.Status != "error" || response.Error != "'io tap 1,2' exited with code 1" {
+		t.Errorf("expected the failed command to fail the guard, got %s %s", response.Status, response.Error)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
 )
 
 // DeviceInfoResponse represents the response for a device info command
@@ -14,9 +15,15 @@ type DeviceInfoResponse struct {
 func InfoCommand(deviceID string) *CommandResponse {
 	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
 	})
@@ -29,9 +36,60 @@ func InfoCommand(deviceID string) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("error getting device info: %v", err))
 	}
 
+	// screen and foreground app state are best effort, a device that is
+	// locked or mid-transition still has info worth returning
+	if locker, ok := targetDevice.(devices.ScreenLocker); ok && info.ScreenState == "" {
+		state, err := locker.ScreenState()
+		if err != nil {
+			utils.Verbose("failed to get screen state: %v", err)
+		}
+		info.ScreenState = state
+	}
+
+	if info.ForegroundApp == nil {
+		foreground, err := targetDevice.GetForegroundApp()
+		if err != nil {
+			utils.Verbose("failed to get foreground app: %v", err)
+		}
+		info.ForegroundApp = foreground
+	}
+
+	if provider, ok := targetDevice.(devices.DiagnosticsProvider); ok && info.Diagnostics == nil {
+		diagnostics, err := provider.Diagnostics()
+		if err != nil {
+			utils.Verbose("failed to get diagnostics: %v", err)
+		}
+		info.Diagnostics = diagnostics
+	}
+
 	response := DeviceInfoResponse{
 		Device: info,
 	}
 
 	return NewSuccessResponse(response)
 }
+
+// PropertiesRequest represents the parameters for the device properties command
+type PropertiesRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// PropertiesCommand returns a curated set of device properties
+func PropertiesCommand(req PropertiesRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	provider, ok := targetDevice.(devices.PropertiesProvider)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("device properties are not supported on %s devices", targetDevice.Platform()))
+	}
+
+	props, err := provider.Properties()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to get properties of device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(props)
+}
@@ -3,6 +3,7 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/mobile-next/mobilecli/devices"
 	"github.com/mobile-next/mobilecli/devices/wda"
@@ -13,6 +14,20 @@ type TapRequest struct {
 	DeviceID string `json:"deviceId"`
 	X        int    `json:"x"`
 	Y        int    `json:"y"`
+
+	// Normalized gives the point as fractions of the screen size instead
+	// of X and Y
+	Normalized *NormalizedPoint `json:"normalized,omitempty"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+
+	// HandleDialogs dismisses system dialogs first, see ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
+
+	// Show saves a screenshot with the tap point marked before tapping, and
+	// shows the touch on devices with a pointer location overlay
+	Show bool `json:"show,omitempty"`
 }
 
 // LongPressRequest represents the parameters for a long press command
@@ -21,18 +36,44 @@ type LongPressRequest struct {
 	X        int    `json:"x"`
 	Y        int    `json:"y"`
 	Duration int    `json:"duration"`
+
+	// Normalized gives the point as fractions of the screen size instead
+	// of X and Y
+	Normalized *NormalizedPoint `json:"normalized,omitempty"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+
+	// HandleDialogs dismisses system dialogs first, see ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
 }
 
 // TextRequest represents the parameters for a text input command
 type TextRequest struct {
 	DeviceID string `json:"deviceId"`
 	Text     string `json:"text"`
+	Clear    bool   `json:"clear,omitempty"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+
+	// HandleDialogs dismisses system dialogs first, see ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
 }
 
 // ButtonRequest represents the parameters for a button press command
 type ButtonRequest struct {
 	DeviceID string `json:"deviceId"`
 	Button   string `json:"button"`
+
+	// Keycode presses a raw platform keycode instead of a named Button
+	Keycode int `json:"keycode,omitempty"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+
+	// HandleDialogs dismisses system dialogs first, see ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
 }
 
 // GestureRequest represents the parameters for a gesture command
@@ -48,19 +89,65 @@ type SwipeRequest struct {
 	Y1       int    `json:"y1"`
 	X2       int    `json:"x2"`
 	Y2       int    `json:"y2"`
+
+	// DurationMs controls the swipe speed: short for a fling, long for a slow
+	// drag. 0 uses the platform default.
+	DurationMs int `json:"durationMs,omitempty"`
+
+	// Direction swipes up, down, left or right through the middle of the
+	// screen instead of between explicit coordinates. It is the direction the
+	// finger moves, so "up" scrolls the content down.
+	Direction string `json:"direction,omitempty"`
+
+	// Distance is how far a directional swipe travels, as a percentage of
+	// the screen's width or height. 0 uses defaultSwipeDistance.
+	Distance int `json:"distance,omitempty"`
+
+	// NormalizedFrom and NormalizedTo give the start and end as fractions of
+	// the screen size instead of X1, Y1, X2 and Y2
+	NormalizedFrom *NormalizedPoint `json:"normalizedFrom,omitempty"`
+	NormalizedTo   *NormalizedPoint `json:"normalizedTo,omitempty"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+
+	// HandleDialogs dismisses system dialogs first, see ParseDialogChoices
+	HandleDialogs string `json:"handleDialogs,omitempty"`
 }
 
+// Swipe directions accepted by SwipeRequest.Direction
+const (
+	SwipeUp    = "up"
+	SwipeDown  = "down"
+	SwipeLeft  = "left"
+	SwipeRight = "right"
+)
+
+// defaultSwipeDistance is the percentage of the screen a directional swipe
+// travels when no distance is given
+const defaultSwipeDistance = 60
+
 // TapCommand performs a tap operation on the specified device
 func TapCommand(req TapRequest) *CommandResponse {
-	if req.X < 0 || req.Y < 0 {
+	if req.Normalized != nil {
+		if err := req.Normalized.validate(); err != nil {
+			return NewErrorResponse(err)
+		}
+	} else if req.X < 0 || req.Y < 0 {
 		return NewErrorResponse(fmt.Errorf("x and y coordinates must be non-negative, got x=%d, y=%d", req.X, req.Y))
 	}
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
 	})
@@ -68,26 +155,71 @@ func TapCommand(req TapRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if req.Normalized != nil {
+		size, err := deviceScreenSize(targetDevice)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		req.X, req.Y = req.Normalized.resolve(size)
+	}
+
+	message := fmt.Sprintf("Tapped on device %s at (%d,%d)", targetDevice.ID(), req.X, req.Y)
+	if !req.Show {
+		err = targetDevice.Tap(req.X, req.Y)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to tap on device %s: %v", targetDevice.ID(), err))
+		}
+		return NewSuccessResponse(MessageResult{Message: message})
+	}
+
+	screenshot, err := saveTapScreenshot(targetDevice, req.X, req.Y)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	hide := showPointerLocation(targetDevice)
 	err = targetDevice.Tap(req.X, req.Y)
+	hide()
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to tap on device %s: %v", targetDevice.ID(), err))
 	}
 
-	return NewSuccessResponse(MessageResult{
-		Message: fmt.Sprintf("Tapped on device %s at (%d,%d)", targetDevice.ID(), req.X, req.Y),
+	return NewSuccessResponse(TapResult{
+		Message:    message,
+		Screenshot: screenshot,
 	})
 }
 
 // LongPressCommand performs a long press operation on the specified device
 func LongPressCommand(req LongPressRequest) *CommandResponse {
-	if req.X < 0 || req.Y < 0 {
+	if req.Normalized != nil {
+		if err := req.Normalized.validate(); err != nil {
+			return NewErrorResponse(err)
+		}
+	} else if req.X < 0 || req.Y < 0 {
 		return NewErrorResponse(fmt.Errorf("x and y coordinates must be non-negative, got x=%d, y=%d", req.X, req.Y))
 	}
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
 	}
+	defer unlock()
 
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -96,6 +228,24 @@ func LongPressCommand(req LongPressRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if req.Normalized != nil {
+		size, err := deviceScreenSize(targetDevice)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		req.X, req.Y = req.Normalized.resolve(size)
+	}
+
 	err = targetDevice.LongPress(req.X, req.Y, req.Duration)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to long press on device %s: %v", targetDevice.ID(), err))
@@ -108,14 +258,20 @@ func LongPressCommand(req LongPressRequest) *CommandResponse {
 
 // TextCommand sends text input to the specified device
 func TextCommand(req TextRequest) *CommandResponse {
-	if req.Text == "" {
+	if req.Text == "" && !req.Clear {
 		return NewErrorResponse(fmt.Errorf("text is required"))
 	}
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
 	}
+	defer unlock()
 
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -124,6 +280,29 @@ func TextCommand(req TextRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if req.Clear {
+		err = clearFocusedText(targetDevice)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to clear text on device %s: %v", targetDevice.ID(), err))
+		}
+	}
+
+	if req.Text == "" {
+		return NewSuccessResponse(MessageResult{
+			Message: fmt.Sprintf("Cleared text on device %s", targetDevice.ID()),
+		})
+	}
+
 	err = targetDevice.SendKeys(req.Text)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to send text to device %s: %v", targetDevice.ID(), err))
@@ -134,17 +313,87 @@ func TextCommand(req TextRequest) *CommandResponse {
 	})
 }
 
-// ButtonCommand presses a hardware button on the specified device
+// clearFocusedText deletes the value of the focused element. On Android the
+// cursor is moved to the end and the value is deleted one character at a time,
+// since select-all needs key combinations (Android 12+). On iOS the value is
+// selected and deleted; if the agent doesn't report focus, it falls back to
+// clearing whatever field has keyboard focus.
+func clearFocusedText(device devices.ControllableDevice) error {
+	elements, err := device.DumpSource()
+	if err != nil {
+		return fmt.Errorf("failed to find focused element: %v", err)
+	}
+
+	focused := FindFocusedElement(elements)
+	if focused == nil && device.Platform() != "ios" {
+		return fmt.Errorf("no focused element found")
+	}
+
+	keys := clearTextKeys(device.Platform(), focused)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return device.PressKeys(keys)
+}
+
+// clearTextKeys returns the key presses that delete the focused element's value
+func clearTextKeys(platform string, focused *devices.ScreenElement) []devices.KeyCombo {
+	length := -1
+	if focused != nil {
+		length = len([]rune(elementTextValue(*focused)))
+		if length == 0 {
+			return nil
+		}
+	}
+
+	if platform == "ios" {
+		return []devices.KeyCombo{
+			{Key: "a", Modifiers: []string{"command"}},
+			{Key: "backspace"},
+		}
+	}
+
+	keys := []devices.KeyCombo{{Key: "end"}}
+	for i := 0; i < length; i++ {
+		keys = append(keys, devices.KeyCombo{Key: "backspace"})
+	}
+	return keys
+}
+
+// ButtonCommand presses a hardware button, or a raw keycode, on the
+// specified device
 func ButtonCommand(req ButtonRequest) *CommandResponse {
-	if req.Button == "" {
-		return NewErrorResponse(fmt.Errorf("button name is required"))
+	if req.Button == "" && req.Keycode == 0 {
+		return NewErrorResponse(fmt.Errorf("button name or keycode is required"))
+	}
+	if req.Button != "" && req.Keycode != 0 {
+		return NewErrorResponse(fmt.Errorf("button name and keycode cannot be used together"))
+	}
+	if req.Keycode < 0 {
+		return NewErrorResponse(fmt.Errorf("keycode must be positive, got %d", req.Keycode))
 	}
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	var keycodePresser devices.KeycodePresser
+	if req.Keycode != 0 {
+		var ok bool
+		keycodePresser, ok = targetDevice.(devices.KeycodePresser)
+		if !ok {
+			return NewErrorResponse(devices.NewUnsupportedButtonError(fmt.Sprintf("keycode %d", req.Keycode), targetDevice.Platform()))
+		}
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
 	})
@@ -152,13 +401,34 @@ func ButtonCommand(req ButtonRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
-	err = targetDevice.PressButton(req.Button)
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if keycodePresser != nil {
+		if err := keycodePresser.PressKeycode(req.Keycode); err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to press keycode on device %s: %w", targetDevice.ID(), err))
+		}
+
+		return NewSuccessResponse(MessageResult{
+			Message: fmt.Sprintf("Pressed keycode %d on device %s", req.Keycode, targetDevice.ID()),
+		})
+	}
+
+	button := strings.ToUpper(req.Button)
+	err = targetDevice.PressButton(button)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("failed to press button on device %s: %v", targetDevice.ID(), err))
+		return NewErrorResponse(fmt.Errorf("failed to press button on device %s: %w", targetDevice.ID(), err))
 	}
 
 	return NewSuccessResponse(MessageResult{
-		Message: fmt.Sprintf("Pressed button '%s' on device %s", req.Button, targetDevice.ID()),
+		Message: fmt.Sprintf("Pressed button '%s' on device %s", button, targetDevice.ID()),
 	})
 }
 
@@ -170,9 +440,15 @@ func GestureCommand(req GestureRequest) *CommandResponse {
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
 	})
@@ -207,11 +483,38 @@ func GestureCommand(req GestureRequest) *CommandResponse {
 
 // SwipeCommand performs a swipe operation on the specified device
 func SwipeCommand(req SwipeRequest) *CommandResponse {
+	if req.DurationMs < 0 {
+		return NewErrorResponse(fmt.Errorf("durationMs must be non-negative, got %d", req.DurationMs))
+	}
+
+	if req.Direction != "" {
+		if err := validateSwipeDirection(req.Direction, req.Distance); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if (req.NormalizedFrom == nil) != (req.NormalizedTo == nil) {
+		return NewErrorResponse(fmt.Errorf("normalizedFrom and normalizedTo must be given together"))
+	}
+	for _, point := range []*NormalizedPoint{req.NormalizedFrom, req.NormalizedTo} {
+		if point != nil {
+			if err := point.validate(); err != nil {
+				return NewErrorResponse(err)
+			}
+		}
+	}
+
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
 	})
@@ -219,7 +522,31 @@ func SwipeCommand(req SwipeRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
-	err = targetDevice.Swipe(req.X1, req.Y1, req.X2, req.Y2)
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	if err := dismissSystemDialogs(targetDevice, req.HandleDialogs); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if req.Direction != "" || req.NormalizedFrom != nil {
+		size, err := deviceScreenSize(targetDevice)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+
+		if req.Direction != "" {
+			req.X1, req.Y1, req.X2, req.Y2 = swipeCoordinates(req.Direction, req.Distance, size.Width, size.Height)
+		} else {
+			req.X1, req.Y1 = req.NormalizedFrom.resolve(size)
+			req.X2, req.Y2 = req.NormalizedTo.resolve(size)
+		}
+	}
+
+	err = targetDevice.Swipe(req.X1, req.Y1, req.X2, req.Y2, req.DurationMs)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to swipe on device %s: %v", targetDevice.ID(), err))
 	}
@@ -228,3 +555,46 @@ func SwipeCommand(req SwipeRequest) *CommandResponse {
 		Message: fmt.Sprintf("Swiped on device %s from (%d,%d) to (%d,%d)", targetDevice.ID(), req.X1, req.Y1, req.X2, req.Y2),
 	})
 }
+
+// validateSwipeDirection checks a directional swipe's direction and distance
+func validateSwipeDirection(direction string, distance int) error {
+	switch direction {
+	case SwipeUp, SwipeDown, SwipeLeft, SwipeRight:
+	default:
+		return fmt.Errorf("invalid swipe direction '%s'. Expected one of: up, down, left, right", direction)
+	}
+
+	if distance < 0 || distance > 100 {
+		return fmt.Errorf("distance must be a percentage between 1 and 100, got %d", distance)
+	}
+
+	return nil
+}
+
+// swipeCoordinates returns the start and end of a swipe in the given
+// direction, centered on a screen of width x height and travelling distance
+// percent of it
+func swipeCoordinates(direction string, distance, width, height int) (x1, y1, x2, y2 int) {
+	if distance == 0 {
+		distance = defaultSwipeDistance
+	}
+
+	centerX, centerY := width/2, height/2
+	halfX := width * distance / 200
+	halfY := height * distance / 200
+
+	// keep a 100% swipe inside the screen
+	halfX = min(halfX, centerX-1)
+	halfY = min(halfY, centerY-1)
+
+	switch direction {
+	case SwipeUp:
+		return centerX, centerY + halfY, centerX, centerY - halfY
+	case SwipeDown:
+		return centerX, centerY - halfY, centerX, centerY + halfY
+	case SwipeLeft:
+		return centerX + halfX, centerY, centerX - halfX, centerY
+	default:
+		return centerX - halfX, centerY, centerX + halfX, centerY
+	}
+}
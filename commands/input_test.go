@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"bytes"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestSwipeCoordinates(t *testing.T) {
+	tests := []struct {
+		direction string
+		distance  int
+		expected  [4]int
+	}{
+		{SwipeUp, 0, [4]int{540, 1920, 540, 480}},
+		{SwipeDown, 50, [4]int{540, 600, 540, 1800}},
+		{SwipeLeft, 80, [4]int{972, 1200, 108, 1200}},
+		{SwipeRight, 100, [4]int{1, 1200, 1079, 1200}},
+	}
+
+	for _, tt := range tests {
+		x1, y1, x2, y2 := swipeCoordinates(tt.direction, tt.distance, 1080, 2400)
+		if got := [4]int{x1, y1, x2, y2}; got != tt.expected {
+			t.Errorf("swipe %s %d%%: expected %v, got %v", tt.direction, tt.distance, tt.expected, got)
+		}
+	}
+}
+
+func TestDirectionalSwipe(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	response := SwipeCommand(SwipeRequest{DeviceID: "fake-android-1", Direction: SwipeUp, DurationMs: 100})
+	if response.Status != "ok" {
+		t.Fatalf("swipe failed: %s", response.Error)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expected := []string{"swipe 540,1920 540,480 100ms"}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+
+	if response := SwipeCommand(SwipeRequest{DeviceID: "fake-android-1", Direction: "sideways"}); response.Status != "error" {
+		t.Error("expected an invalid direction to fail")
+	}
+}
+
+func TestNormalizedCoordinates(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if response := TapCommand(TapRequest{DeviceID: "fake-android-1", Normalized: &NormalizedPoint{X: 0.5, Y: 0.25}}); response.Status != "ok" {
+		t.Fatalf("tap failed: %s", response.Error)
+	}
+
+	response := SwipeCommand(SwipeRequest{
+		DeviceID:       "fake-android-1",
+		NormalizedFrom: &NormalizedPoint{X: 0, Y: 1},
+		NormalizedTo:   &NormalizedPoint{X: 1, Y: 0},
+	})
+	if response.Status != "ok" {
+		t.Fatalf("swipe failed: %s", response.Error)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expected := []string{"tap 540,600", "swipe 0,2399 1079,0 0ms"}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+
+	if response := TapCommand(TapRequest{DeviceID: "fake-android-1", Normalized: &NormalizedPoint{X: 1.5, Y: 0.5}}); response.Status != "error" {
+		t.Error("expected a point off the screen to fail")
+	}
+
+	if response := SwipeCommand(SwipeRequest{DeviceID: "fake-android-1", NormalizedFrom: &NormalizedPoint{}}); response.Status != "error" {
+		t.Error("expected normalizedFrom without normalizedTo to fail")
+	}
+}
+
+func TestButtonKeycode(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if response := ButtonCommand(ButtonRequest{DeviceID: "fake-android-1", Keycode: 85}); response.Status != "ok" {
+		t.Fatalf("keycode failed: %s", response.Error)
+	}
+	if response := ButtonCommand(ButtonRequest{DeviceID: "fake-android-1", Button: "home"}); response.Status != "ok" {
+		t.Fatalf("button failed: %s", response.Error)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expected := []string{"keycode 85", "button HOME"}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+
+	response := ButtonCommand(ButtonRequest{DeviceID: "fake-android-1", Button: "HOME", Keycode: 3})
+	if response.Status != "error" {
+		t.Error("expected a button and a keycode together to fail")
+	}
+}
+
+func TestButtonKeycodeUnsupported(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	response := ButtonCommand(ButtonRequest{DeviceID: "fake-ios-2", Keycode: 85})
+	if response.Status != "error" {
+		t.Fatal("expected a keycode to fail on iOS")
+	}
+	if unsupported, ok := response.Data.(*devices.UnsupportedButtonError); !ok || unsupported.Code != devices.UnsupportedOnPlatformCode {
+		t.Errorf("expected a structured error, got %#v", response.Data)
+	}
+}
+
+func TestTapShow(t *testing.T) {
+	useFakeDevices(t, 1)
+	dir := t.TempDir()
+	t.Setenv(ArtifactsDirEnvVar, dir)
+
+	response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 100, Y: 200, Show: true})
+	if response.Status != "ok" {
+		t.Fatalf("tap failed: %s", response.Error)
+	}
+
+	result := response.Data.(TapResult)
+	if !strings.HasPrefix(result.Screenshot, filepath.Join(dir, "fake-android-1", "tap-")) {
+		t.Errorf("expected the screenshot under the artifacts directory, got %s", result.Screenshot)
+	}
+
+	data, err := os.ReadFile(result.Screenshot)
+	if err != nil {
+		t.Fatalf("failed to read screenshot: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected a PNG screenshot: %v", err)
+	}
+	if r, g, _, _ := img.At(100, 200).RGBA(); r>>8 != 255 || g>>8 != 0 {
+		t.Errorf("expected the tap point to be marked")
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	if !reflect.DeepEqual(actions, []string{"tap 100,200"}) {
+		t.Errorf("expected a single tap, got %v", actions)
+	}
+}
@@ -11,6 +11,9 @@ import (
 type KeysRequest struct {
 	DeviceID string   `json:"deviceId"`
 	Keys     []string `json:"keys"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
 }
 
 // modifierAliases maps user-facing modifier names to their canonical form
@@ -85,8 +88,14 @@ func KeysCommand(req KeysRequest) *CommandResponse {
 
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
 	}
+	defer unlock()
 
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -95,6 +104,12 @@ func KeysCommand(req KeysRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
 	}
 
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
 	err = targetDevice.PressKeys(combos)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to press keys on device %s: %v", targetDevice.ID(), err))
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// DeviceLabelsEnvVar restricts auto-selection to devices with these labels,
+// e.g. "pool=smoke,team=payments"
+const DeviceLabelsEnvVar = "MOBILECLI_DEVICE_LABELS"
+
+// DeviceLabelsRequest sets and removes labels of a device. With neither, it
+// returns the device's labels.
+type DeviceLabelsRequest struct {
+	DeviceID string            `json:"deviceId"`
+	Set      map[string]string `json:"set,omitempty"`
+	Unset    []string          `json:"unset,omitempty"`
+}
+
+// DeviceLabelsResult holds a device's labels after a DeviceLabelsCommand
+type DeviceLabelsResult struct {
+	DeviceID string            `json:"deviceId"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// DeviceLabelsCommand updates or reads the labels of a device. Labels are
+// stored where the command runs, so with a server they live on the server.
+func DeviceLabelsCommand(req DeviceLabelsRequest) *CommandResponse {
+	if req.DeviceID == "" {
+		return NewErrorResponse(fmt.Errorf("deviceId is required"))
+	}
+
+	for key := range req.Set {
+		if key == "" {
+			return NewErrorResponse(fmt.Errorf("label keys cannot be empty"))
+		}
+	}
+
+	var labels map[string]string
+	var err error
+	if len(req.Set) == 0 && len(req.Unset) == 0 {
+		labels, err = devices.GetDeviceLabels(req.DeviceID)
+	} else {
+		labels, err = devices.UpdateDeviceLabels(req.DeviceID, req.Set, req.Unset)
+	}
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if labels == nil {
+		labels = map[string]string{}
+	}
+
+	return NewSuccessResponse(DeviceLabelsResult{
+		DeviceID: req.DeviceID,
+		Labels:   labels,
+	})
+}
+
+// autoSelectLabels returns the labels auto-selection is restricted to
+func autoSelectLabels() (map[string]string, error) {
+	value := os.Getenv(DeviceLabelsEnvVar)
+	if value == "" {
+		return nil, nil
+	}
+
+	labels, err := devices.ParseLabels([]string{value})
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", DeviceLabelsEnvVar, err)
+	}
+	return labels, nil
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func TestAutoSelectRestrictedToLabels(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	response := DeviceLabelsCommand(DeviceLabelsRequest{DeviceID: "fake-ios-2", Set: map[string]string{"pool": "smoke"}})
+	if response.Status != "ok" {
+		t.Fatalf("setting labels failed: %s", response.Error)
+	}
+
+	t.Setenv(DeviceLabelsEnvVar, "pool=smoke")
+	device, err := FindDeviceOrAutoSelect("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if device.ID() != "fake-ios-2" {
+		t.Errorf("auto-selected %s, want fake-ios-2", device.ID())
+	}
+
+	t.Setenv(DeviceLabelsEnvVar, "pool=nightly")
+	if _, err := FindDeviceOrAutoSelect(""); err == nil || !strings.Contains(err.Error(), "pool=nightly") {
+		t.Errorf("expected no devices with pool=nightly, got %v", err)
+	}
+
+	list, err := devices.GetDeviceInfoList(devices.DeviceListOptions{Labels: map[string]string{"pool": "smoke"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != "fake-ios-2" || list[0].Labels["pool"] != "smoke" {
+		t.Errorf("unexpected labeled devices: %+v", list)
+	}
+}
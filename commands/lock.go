@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// LockRequest represents the parameters for locking or unlocking a device
+type LockRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// findScreenLocker finds the device, checks it supports locking, then locks it
+// for this command and starts its agent. The caller releases the lock with the
+// returned function.
+func findScreenLocker(deviceID string) (devices.ControllableDevice, devices.ScreenLocker, func(), error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	locker, ok := targetDevice.(devices.ScreenLocker)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("locking is not supported on %s devices", targetDevice.Platform())
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		unlock()
+		return nil, nil, nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	return targetDevice, locker, unlock, nil
+}
+
+// LockCommand locks the device screen
+func LockCommand(req LockRequest) *CommandResponse {
+	targetDevice, locker, unlock, err := findScreenLocker(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := locker.Lock(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to lock device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Locked device %s", targetDevice.ID()),
+	})
+}
+
+// UnlockCommand wakes and unlocks the device screen
+func UnlockCommand(req LockRequest) *CommandResponse {
+	targetDevice, locker, unlock, err := findScreenLocker(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := locker.Unlock(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to unlock device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Unlocked device %s", targetDevice.ID()),
+	})
+}
+
+// findScreenWaker finds the device, checks it can turn its screen on and off,
+// then locks it for this command and starts its agent. The caller releases the
+// lock with the returned function.
+func findScreenWaker(deviceID string) (devices.ControllableDevice, devices.ScreenWaker, func(), error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	waker, ok := targetDevice.(devices.ScreenWaker)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("waking the screen is not supported on %s devices", targetDevice.Platform())
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		unlock()
+		return nil, nil, nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	return targetDevice, waker, unlock, nil
+}
+
+// WakeCommand turns the device screen on, leaving the keyguard in place
+func WakeCommand(req LockRequest) *CommandResponse {
+	targetDevice, waker, unlock, err := findScreenWaker(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := waker.Wake(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to wake device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Woke device %s", targetDevice.ID()),
+	})
+}
+
+// ScreenOffCommand turns the device screen off
+func ScreenOffCommand(req LockRequest) *CommandResponse {
+	targetDevice, waker, unlock, err := findScreenWaker(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := waker.ScreenOff(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to turn off screen of device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Turned off screen of device %s", targetDevice.ID()),
+	})
+}
+
+// ensureUnlocked wakes the device and dismisses its keyguard unless the
+// screen is already on, so input reaches the app rather than the lock screen.
+// The device's agent must be running.
+func ensureUnlocked(device devices.ControllableDevice) error {
+	locker, ok := device.(devices.ScreenLocker)
+	if !ok {
+		return fmt.Errorf("unlocking is not supported on %s devices", device.Platform())
+	}
+
+	state, err := locker.ScreenState()
+	if err != nil {
+		return fmt.Errorf("failed to get screen state of device %s: %v", device.ID(), err)
+	}
+	if state == devices.ScreenStateOn {
+		return nil
+	}
+
+	if err := locker.Unlock(); err != nil {
+		return fmt.Errorf("failed to unlock device %s: %v", device.ID(), err)
+	}
+	return nil
+}
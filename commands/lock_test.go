@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestWakeAndScreenOff(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if response := ScreenOffCommand(LockRequest{DeviceID: "fake-android-1"}); response.Status != "ok" {
+		t.Fatalf("screen off failed: %s", response.Error)
+	}
+	if response := WakeCommand(LockRequest{DeviceID: "fake-android-1"}); response.Status != "ok" {
+		t.Fatalf("wake failed: %s", response.Error)
+	}
+
+	device := fake.Get("fake-android-1")
+	if state, _ := device.ScreenState(); state != devices.ScreenStateLocked {
+		t.Errorf("expected the keyguard after waking, got %s", state)
+	}
+
+	expected := []string{"screen off", "wake"}
+	if actions := device.Actions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+}
+
+func TestEnsureUnlocked(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+	device := fake.Get("fake-android-1")
+
+	// an unlocked device is left as it is
+	if response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 1, Y: 2, EnsureUnlocked: true}); response.Status != "ok" {
+		t.Fatalf("tap failed: %s", response.Error)
+	}
+
+	device.SetScreenState(devices.ScreenStateOff)
+	if response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 3, Y: 4, EnsureUnlocked: true}); response.Status != "ok" {
+		t.Fatalf("tap failed: %s", response.Error)
+	}
+
+	expected := []string{"tap 1,2", "unlock", "tap 3,4"}
+	if actions := device.Actions(); !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+}
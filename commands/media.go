@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// MediaAddRequest represents the parameters for adding photos and videos
+type MediaAddRequest struct {
+	DeviceID string   `json:"deviceId"`
+	Paths    []string `json:"paths"`
+}
+
+// MediaAddResult lists the media added to a device
+type MediaAddResult struct {
+	Message string   `json:"message"`
+	Photos  []string `json:"photos"`
+	Videos  []string `json:"videos"`
+}
+
+// ContactsAddRequest represents the parameters for importing contacts
+type ContactsAddRequest struct {
+	DeviceID string `json:"deviceId"`
+	Path     string `json:"path"` // vCard (.vcf) file
+}
+
+// findMediaImporter finds the device and checks it can be seeded with media
+func findMediaImporter(deviceID string) (devices.ControllableDevice, devices.MediaImporter, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	importer, ok := targetDevice.(devices.MediaImporter)
+	if !ok {
+		return nil, nil, fmt.Errorf("adding media is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, importer, nil
+}
+
+// checkLocalFile returns the absolute path of a local file, or an error when
+// it isn't a readable file
+func checkLocalFile(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %s: %w", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory", path)
+	}
+	return absPath, nil
+}
+
+// MediaAddCommand adds photos and videos to a device's photo library
+func MediaAddCommand(req MediaAddRequest) *CommandResponse {
+	if len(req.Paths) == 0 {
+		return NewErrorResponse(fmt.Errorf("at least one photo or video is required"))
+	}
+
+	result := MediaAddResult{Photos: []string{}, Videos: []string{}}
+	paths := make([]string, 0, len(req.Paths))
+	for _, path := range req.Paths {
+		kind, err := devices.MediaKind(path)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+
+		absPath, err := checkLocalFile(path)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		paths = append(paths, absPath)
+
+		if kind == devices.MediaKindVideo {
+			result.Videos = append(result.Videos, filepath.Base(path))
+		} else {
+			result.Photos = append(result.Photos, filepath.Base(path))
+		}
+	}
+
+	targetDevice, importer, err := findMediaImporter(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := importer.AddMedia(paths); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to add media to device %s: %w", targetDevice.ID(), err))
+	}
+
+	result.Message = fmt.Sprintf("Added %d photos and %d videos to device %s", len(result.Photos), len(result.Videos), targetDevice.ID())
+	return NewSuccessResponse(result)
+}
+
+// ContactsAddCommand imports the contacts of a vCard file into a device's
+// address book
+func ContactsAddCommand(req ContactsAddRequest) *CommandResponse {
+	if req.Path == "" {
+		return NewErrorResponse(fmt.Errorf("a vCard (.vcf) file is required"))
+	}
+	if ext := strings.ToLower(filepath.Ext(req.Path)); ext != ".vcf" && ext != ".vcard" {
+		return NewErrorResponse(fmt.Errorf("unsupported contacts file %s, expected a vCard (.vcf) file", filepath.Base(req.Path)))
+	}
+
+	absPath, err := checkLocalFile(req.Path)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	targetDevice, importer, err := findMediaImporter(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := importer.AddContacts(absPath); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to add contacts to device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Imported contacts from %s to device %s", filepath.Base(req.Path), targetDevice.ID()),
+	})
+}
@@ -0,0 +1,76 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+// writeFiles creates empty files with the given names in a temp directory
+func writeFiles(t *testing.T, names ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("data"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestMediaAddCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	paths := writeFiles(t, "photo.JPG", "clip.mp4")
+	response := MediaAddCommand(MediaAddRequest{DeviceID: "fake-android-1", Paths: paths})
+	if response.Status != "ok" {
+		t.Fatalf("media add failed: %s", response.Error)
+	}
+
+	result := response.Data.(MediaAddResult)
+	if !reflect.DeepEqual(result.Photos, []string{"photo.JPG"}) || !reflect.DeepEqual(result.Videos, []string{"clip.mp4"}) {
+		t.Errorf("unexpected result %+v", result)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	if len(actions) != 1 || actions[0] != "addmedia "+strings.Join(paths, " ") {
+		t.Errorf("unexpected actions %v", actions)
+	}
+
+	unsupported := writeFiles(t, "notes.txt")
+	if response := MediaAddCommand(MediaAddRequest{DeviceID: "fake-android-1", Paths: unsupported}); response.Status != "error" || !strings.Contains(response.Error, "unsupported media file") {
+		t.Errorf("expected an unsupported file error, got %+v", response)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.png")
+	if response := MediaAddCommand(MediaAddRequest{DeviceID: "fake-android-1", Paths: []string{missing}}); response.Status != "error" {
+		t.Error("expected a missing file to fail")
+	}
+}
+
+func TestContactsAddCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	paths := writeFiles(t, "contacts.vcf", "contacts.csv")
+	response := ContactsAddCommand(ContactsAddRequest{DeviceID: "fake-android-1", Path: paths[0]})
+	if response.Status != "ok" {
+		t.Fatalf("contacts add failed: %s", response.Error)
+	}
+	if actions := fake.Get("fake-android-1").Actions(); len(actions) != 1 || actions[0] != "addcontacts "+paths[0] {
+		t.Errorf("unexpected actions %v", actions)
+	}
+
+	if response := ContactsAddCommand(ContactsAddRequest{DeviceID: "fake-android-1", Path: paths[1]}); response.Status != "error" {
+		t.Error("expected a non-vCard file to fail")
+	}
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// NormalizedPoint is a point given as fractions of the screen's width and
+// height, from 0.0 to 1.0, so (0.5, 0.5) is the middle of the screen. It lets
+// callers working from scaled-down screenshots skip device coordinate math.
+type NormalizedPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// validate checks that the point lies on the screen
+func (p *NormalizedPoint) validate() error {
+	if p.X < 0 || p.X > 1 || p.Y < 0 || p.Y > 1 {
+		return fmt.Errorf("normalized coordinates must be between 0.0 and 1.0, got x=%g, y=%g", p.X, p.Y)
+	}
+	return nil
+}
+
+// resolve converts the point to device coordinates on a screen of the given
+// size, keeping 1.0 on the last row or column
+func (p *NormalizedPoint) resolve(size *devices.ScreenSize) (int, int) {
+	x := min(int(p.X*float64(size.Width)+0.5), size.Width-1)
+	y := min(int(p.Y*float64(size.Height)+0.5), size.Height-1)
+	return x, y
+}
+
+// deviceScreenSize returns the screen size in the coordinates Tap and Swipe
+// take: points on iOS and pixels on Android, as reported by Info()
+func deviceScreenSize(device devices.ControllableDevice) (*devices.ScreenSize, error) {
+	info, err := device.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screen size of device %s: %v", device.ID(), err)
+	}
+
+	if info.ScreenSize == nil || info.ScreenSize.Width <= 0 || info.ScreenSize.Height <= 0 {
+		return nil, fmt.Errorf("device %s did not report a screen size", device.ID())
+	}
+
+	return info.ScreenSize, nil
+}
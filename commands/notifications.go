@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// notificationShadeTimeout is how long to wait for a notification to appear in
+// the opened shade before giving up on tapping it
+const notificationShadeTimeout = 5 * time.Second
+
+// NotificationsRequest represents the parameters for listing notifications.
+// With Clear, notifications are dismissed after they have been listed.
+type NotificationsRequest struct {
+	DeviceID string `json:"deviceId"`
+	Clear    bool   `json:"clear,omitempty"`
+}
+
+// NotificationsResponse lists the notifications posted on the device
+type NotificationsResponse struct {
+	Notifications []devices.Notification `json:"notifications"`
+}
+
+// NotificationClearRequest represents the parameters for clearing notifications
+type NotificationClearRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// NotificationTapRequest represents the parameters for tapping a notification.
+// Text matches a substring of the notification's title or text.
+type NotificationTapRequest struct {
+	DeviceID string `json:"deviceId"`
+	Text     string `json:"text"`
+}
+
+// findNotificationManager finds the device and checks it supports notifications
+func findNotificationManager(deviceID string) (devices.ControllableDevice, devices.NotificationManager, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	manager, ok := targetDevice.(devices.NotificationManager)
+	if !ok {
+		return nil, nil, fmt.Errorf("notifications are not supported on %s devices", targetDevice.Platform())
+	}
+
+	return targetDevice, manager, nil
+}
+
+// NotificationsCommand lists the notifications posted on the device
+func NotificationsCommand(req NotificationsRequest) *CommandResponse {
+	targetDevice, manager, err := findNotificationManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	notifications, err := manager.ListNotifications()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list notifications on device %s: %v", targetDevice.ID(), err))
+	}
+
+	if req.Clear {
+		if err := manager.ClearNotifications(); err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to clear notifications on device %s: %v", targetDevice.ID(), err))
+		}
+	}
+
+	return NewSuccessResponse(NotificationsResponse{
+		Notifications: notifications,
+	})
+}
+
+// NotificationClearCommand dismisses all clearable notifications
+func NotificationClearCommand(req NotificationClearRequest) *CommandResponse {
+	targetDevice, manager, err := findNotificationManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := manager.ClearNotifications(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to clear notifications on device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: "Notifications cleared",
+	})
+}
+
+// findNotification returns the first notification whose title or text contains text
+func findNotification(notifications []devices.Notification, text string) *devices.Notification {
+	for i := range notifications {
+		if strings.Contains(notifications[i].Title, text) || strings.Contains(notifications[i].Text, text) {
+			return &notifications[i]
+		}
+	}
+	return nil
+}
+
+// NotificationTapCommand opens the notification shade and taps the first
+// notification matching the request's text
+func NotificationTapCommand(req NotificationTapRequest) *CommandResponse {
+	if req.Text == "" {
+		return NewErrorResponse(fmt.Errorf("text is required"))
+	}
+
+	targetDevice, manager, err := findNotificationManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	notifications, err := manager.ListNotifications()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list notifications on device %s: %v", targetDevice.ID(), err))
+	}
+
+	notification := findNotification(notifications, req.Text)
+	if notification == nil {
+		return NewErrorResponse(fmt.Errorf("no notification matching '%s' on device %s", req.Text, targetDevice.ID()))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
+	}
+
+	if err := manager.OpenNotificationShade(); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	// the shade shows the title, so look for that first and fall back to the text
+	var element *devices.ScreenElement
+	deadline := time.Now().Add(notificationShadeTimeout)
+	for element == nil {
+		elements, err := targetDevice.DumpSource()
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to dump UI on device %s: %v", targetDevice.ID(), err))
+		}
+
+		for _, text := range []string{notification.Title, notification.Text} {
+			if element == nil && text != "" {
+				element = findElementWithText(elements, text)
+			}
+		}
+
+		if element == nil {
+			if !time.Now().Before(deadline) {
+				return NewErrorResponse(fmt.Errorf("notification '%s' not found in the notification shade", notification.Title))
+			}
+			time.Sleep(expectPollInterval)
+		}
+	}
+
+	x := element.Rect.X + element.Rect.Width/2
+	y := element.Rect.Y + element.Rect.Height/2
+	if err := targetDevice.Tap(x, y); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to tap notification on device %s: %v", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(notification)
+}
@@ -17,9 +17,12 @@ type OrientationSetRequest struct {
 	Orientation string `json:"orientation"`
 }
 
-// OrientationResponse represents the response containing orientation information
+// OrientationResponse represents the response containing orientation information.
+// Rotation is the number of quarter turns counter-clockwise from portrait, the
+// value Android keeps in user_rotation.
 type OrientationResponse struct {
 	Orientation string `json:"orientation"`
+	Rotation    int    `json:"rotation"`
 }
 
 // OrientationGetCommand gets the current device orientation
@@ -29,6 +32,12 @@ func OrientationGetCommand(req OrientationGetRequest) *CommandResponse {
 		return NewErrorResponse(err)
 	}
 
+	unlock, err := LockDevice(device.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	// start agent if needed
 	err = device.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -44,6 +53,7 @@ func OrientationGetCommand(req OrientationGetRequest) *CommandResponse {
 
 	response := OrientationResponse{
 		Orientation: orientation,
+		Rotation:    devices.OrientationRotation(orientation),
 	}
 
 	return NewSuccessResponse(response)
@@ -51,9 +61,9 @@ func OrientationGetCommand(req OrientationGetRequest) *CommandResponse {
 
 // OrientationSetCommand sets the device orientation
 func OrientationSetCommand(req OrientationSetRequest) *CommandResponse {
-	// validate orientation value
-	if req.Orientation != "portrait" && req.Orientation != "landscape" {
-		return NewErrorResponse(fmt.Errorf("invalid orientation value '%s', must be 'portrait' or 'landscape'", req.Orientation))
+	orientation, err := devices.NormalizeOrientation(req.Orientation)
+	if err != nil {
+		return NewErrorResponse(err)
 	}
 
 	device, err := FindDeviceOrAutoSelect(req.DeviceID)
@@ -61,6 +71,12 @@ func OrientationSetCommand(req OrientationSetRequest) *CommandResponse {
 		return NewErrorResponse(err)
 	}
 
+	unlock, err := LockDevice(device.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	// start agent if needed
 	err = device.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -69,13 +85,14 @@ func OrientationSetCommand(req OrientationSetRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", device.ID(), err))
 	}
 
-	err = device.SetOrientation(req.Orientation)
+	err = device.SetOrientation(orientation)
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("failed to set orientation: %v", err))
 	}
 
 	response := OrientationResponse{
-		Orientation: req.Orientation,
+		Orientation: orientation,
+		Rotation:    devices.OrientationRotation(orientation),
 	}
 
 	return NewSuccessResponse(response)
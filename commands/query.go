@@ -0,0 +1,370 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// ElementQuery filters dumped UI elements. Queries combine predicates with
+// AND, OR, NOT and parentheses, e.g.
+//
+//	type=Button AND label~='Sign.*'
+//	(text*=Continue OR identifier=next) AND NOT y>2000
+//
+// String attributes (type, text, label, name, value, placeholder, identifier)
+// support = (equals), != (differs), *= (contains) and ~= (regular expression).
+// Rect attributes (x, y, width, height) support =, !=, <, <=, > and >=.
+type ElementQuery struct {
+	root queryNode
+}
+
+// queryNode is a parsed query expression
+type queryNode interface {
+	matches(element devices.ScreenElement) bool
+}
+
+type queryAnd struct{ left, right queryNode }
+type queryOr struct{ left, right queryNode }
+type queryNot struct{ node queryNode }
+
+func (q queryAnd) matches(e devices.ScreenElement) bool {
+	return q.left.matches(e) && q.right.matches(e)
+}
+func (q queryOr) matches(e devices.ScreenElement) bool {
+	return q.left.matches(e) || q.right.matches(e)
+}
+func (q queryNot) matches(e devices.ScreenElement) bool { return !q.node.matches(e) }
+
+var queryStringAttributes = []string{"type", "text", "label", "name", "value", "placeholder", "identifier"}
+var queryRectAttributes = []string{"x", "y", "width", "height"}
+
+// queryPredicate compares one attribute of an element
+type queryPredicate struct {
+	attribute string
+	operator  string
+	value     string
+	number    int
+	pattern   *regexp.Regexp
+}
+
+func (p queryPredicate) matches(e devices.ScreenElement) bool {
+	switch p.attribute {
+	case "x":
+		return p.compare(e.Rect.X)
+	case "y":
+		return p.compare(e.Rect.Y)
+	case "width":
+		return p.compare(e.Rect.Width)
+	case "height":
+		return p.compare(e.Rect.Height)
+	}
+
+	var field *string
+	switch p.attribute {
+	case "type":
+		field = &e.Type
+	case "text":
+		field = e.Text
+	case "label":
+		field = e.Label
+	case "name":
+		field = e.Name
+	case "value":
+		field = e.Value
+	case "placeholder":
+		field = e.Placeholder
+	case "identifier":
+		field = e.Identifier
+	}
+
+	// elements without the attribute only match !=
+	if field == nil {
+		return p.operator == "!="
+	}
+
+	switch p.operator {
+	case "=":
+		return *field == p.value
+	case "!=":
+		return *field != p.value
+	case "*=":
+		return strings.Contains(*field, p.value)
+	case "~=":
+		return p.pattern.MatchString(*field)
+	}
+	return false
+}
+
+func (p queryPredicate) compare(n int) bool {
+	switch p.operator {
+	case "=":
+		return n == p.number
+	case "!=":
+		return n != p.number
+	case "<":
+		return n < p.number
+	case "<=":
+		return n <= p.number
+	case ">":
+		return n > p.number
+	case ">=":
+		return n >= p.number
+	}
+	return false
+}
+
+// ParseElementQuery parses a query, see ElementQuery for its grammar
+func ParseElementQuery(query string) (*ElementQuery, error) {
+	tokens, err := tokenizeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+
+	p := &queryParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("invalid query: unexpected '%s'", p.peek().text)
+	}
+
+	return &ElementQuery{root: root}, nil
+}
+
+// Filter returns the elements, including nested children, that match the
+// query. Matches are returned as a flat list without their children.
+func (q *ElementQuery) Filter(elements []devices.ScreenElement) []devices.ScreenElement {
+	matched := []devices.ScreenElement{}
+	var walk func([]devices.ScreenElement)
+	walk = func(elements []devices.ScreenElement) {
+		for _, element := range elements {
+			if q.root.matches(element) {
+				match := element
+				match.Children = nil
+				matched = append(matched, match)
+			}
+			walk(element.Children)
+		}
+	}
+	walk(elements)
+	return matched
+}
+
+// queryTokenKind classifies query tokens
+type queryTokenKind int
+
+const (
+	queryEnd queryTokenKind = iota
+	queryWord
+	queryString
+	queryOperator
+	queryParen
+)
+
+type queryToken struct {
+	kind queryTokenKind
+	text string
+}
+
+// tokenizeQuery splits a query into words, quoted strings, operators and
+// parentheses
+func tokenizeQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(' || r == ')':
+			tokens = append(tokens, queryToken{queryParen, string(r)})
+			i++
+
+		case r == '\'' || r == '"':
+			var value strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) && runes[j+1] == r {
+					j++
+				}
+				value.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("invalid query: unterminated string starting at %d", i)
+			}
+			tokens = append(tokens, queryToken{queryString, value.String()})
+			i = j + 1
+
+		case strings.ContainsRune("=!~*<>", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' {
+				op += "="
+			}
+			switch op {
+			case "=", "!=", "~=", "*=", "<", "<=", ">", ">=":
+			default:
+				return nil, fmt.Errorf("invalid query: unknown operator '%s'", op)
+			}
+			tokens = append(tokens, queryToken{queryOperator, op})
+			i += len(op)
+
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune("()=!~*<>'\"", runes[j]) {
+				j++
+			}
+			tokens = append(tokens, queryToken{queryWord, string(runes[i:j])})
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+// queryParser is a recursive descent parser over query tokens
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+}
+
+func (p *queryParser) done() bool { return p.pos >= len(p.tokens) }
+
+func (p *queryParser) peek() queryToken {
+	if p.done() {
+		return queryToken{kind: queryEnd}
+	}
+	return p.tokens[p.pos]
+}
+
+// keyword reports whether the next token is the given case-insensitive keyword
+func (p *queryParser) keyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == queryWord && strings.EqualFold(t.text, keyword)
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.keyword("NOT") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{node}, nil
+	}
+
+	if t := p.peek(); t.kind == queryParen && t.text == "(" {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t.kind != queryParen || t.text != ")" {
+			return nil, fmt.Errorf("invalid query: missing ')'")
+		}
+		p.pos++
+		return node, nil
+	}
+
+	return p.parsePredicate()
+}
+
+func (p *queryParser) parsePredicate() (queryNode, error) {
+	if p.done() {
+		return nil, fmt.Errorf("invalid query: expected a predicate, got end of query")
+	}
+
+	attr := p.peek()
+	if attr.kind != queryWord {
+		return nil, fmt.Errorf("invalid query: expected an attribute, got '%s'", attr.text)
+	}
+	p.pos++
+
+	op := p.peek()
+	if op.kind != queryOperator {
+		return nil, fmt.Errorf("invalid query: expected an operator after '%s'", attr.text)
+	}
+	p.pos++
+
+	value := p.peek()
+	if value.kind != queryWord && value.kind != queryString {
+		return nil, fmt.Errorf("invalid query: expected a value after '%s%s'", attr.text, op.text)
+	}
+	p.pos++
+
+	predicate := queryPredicate{
+		attribute: strings.ToLower(attr.text),
+		operator:  op.text,
+		value:     value.text,
+	}
+
+	switch {
+	case slices.Contains(queryRectAttributes, predicate.attribute):
+		if op.text == "~=" || op.text == "*=" {
+			return nil, fmt.Errorf("invalid query: '%s' is not supported for %s", op.text, predicate.attribute)
+		}
+		n, err := strconv.Atoi(value.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %s must be compared to a number, got '%s'", predicate.attribute, value.text)
+		}
+		predicate.number = n
+
+	case slices.Contains(queryStringAttributes, predicate.attribute):
+		switch op.text {
+		case "<", "<=", ">", ">=":
+			return nil, fmt.Errorf("invalid query: '%s' is not supported for %s", op.text, predicate.attribute)
+		case "~=":
+			pattern, err := regexp.Compile(value.text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query: bad regular expression '%s': %w", value.text, err)
+			}
+			predicate.pattern = pattern
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid query: unknown attribute '%s', supported attributes are: %s", attr.text, strings.Join(append(append([]string{}, queryStringAttributes...), queryRectAttributes...), ", "))
+	}
+
+	return predicate, nil
+}
@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func queryIDs(t *testing.T, query string, elements []devices.ScreenElement) []string {
+	t.Helper()
+	q, err := ParseElementQuery(query)
+	require.NoError(t, err)
+
+	ids := []string{}
+	for _, e := range q.Filter(elements) {
+		assert.Nil(t, e.Children)
+		ids = append(ids, *e.Identifier)
+	}
+	return ids
+}
+
+func TestElementQueryFilter(t *testing.T) {
+	elements := []devices.ScreenElement{
+		{
+			Type: "Window", Identifier: strPtr("window"),
+			Rect: devices.ScreenElementRect{Width: 1080, Height: 2400},
+			Children: []devices.ScreenElement{
+				{Type: "Button", Identifier: strPtr("signIn"), Label: strPtr("Sign in"), Rect: devices.ScreenElementRect{X: 100, Y: 2100, Width: 300, Height: 120}},
+				{Type: "Button", Identifier: strPtr("signUp"), Label: strPtr("Sign up"), Rect: devices.ScreenElementRect{X: 500, Y: 2100, Width: 300, Height: 120}},
+				{Type: "Button", Identifier: strPtr("help"), Label: strPtr("Help"), Rect: devices.ScreenElementRect{X: 900, Y: 100, Width: 80, Height: 80}},
+				{Type: "TextField", Identifier: strPtr("email"), Placeholder: strPtr("Email address")},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"signIn", "signUp"}, queryIDs(t, "type=Button AND label~='Sign.*'", elements))
+	assert.Equal(t, []string{"signUp", "help"}, queryIDs(t, "label='Sign up' or identifier=help", elements))
+	assert.Equal(t, []string{"help"}, queryIDs(t, "type=Button AND NOT (y>=2000)", elements))
+	assert.Equal(t, []string{"email"}, queryIDs(t, `placeholder*="Email"`, elements))
+	assert.Equal(t, []string{"window", "help", "email"}, queryIDs(t, "label!='Sign in' AND width!=300", elements))
+	assert.Equal(t, []string{}, queryIDs(t, "text=anything", elements))
+}
+
+func TestParseElementQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"",
+		"type",
+		"type=",
+		"color=red",
+		"x~=100",
+		"label>5",
+		"width=wide",
+		"label~='('",
+		"(type=Button",
+		"type=Button AND",
+		"type=Button label=OK",
+		"label='unterminated",
+		"type!Button",
+	} {
+		_, err := ParseElementQuery(query)
+		assert.Error(t, err, "query %q", query)
+	}
+}
@@ -13,7 +13,7 @@ type RebootRequest struct {
 func RebootCommand(req RebootRequest) *CommandResponse {
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
 	err = targetDevice.Reboot()
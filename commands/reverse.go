@@ -0,0 +1,209 @@
+package commands
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// ReverseRequest represents the parameters for a reverse command, which
+// makes DevicePort on the device reach HostPort on the host
+type ReverseRequest struct {
+	DeviceID   string `json:"deviceId"`
+	DevicePort int    `json:"devicePort"`
+	HostPort   int    `json:"hostPort"`
+}
+
+// ReverseListRequest represents the parameters for listing reversed ports
+type ReverseListRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// ReverseRemoveRequest represents the parameters for removing a reversed
+// port, or every one with All
+type ReverseRemoveRequest struct {
+	DeviceID   string `json:"deviceId"`
+	DevicePort int    `json:"devicePort,omitempty"`
+	All        bool   `json:"all,omitempty"`
+}
+
+// ReverseResult reports a reversed port. Relay is set when the port is
+// relayed by this process, such as on real iOS devices, which can't reach the
+// host over USB: apps then call the host at Address, and the relay lasts as
+// long as the process does.
+type ReverseResult struct {
+	Message string `json:"message"`
+	Relay   bool   `json:"relay,omitempty"`
+	Address string `json:"address,omitempty"`
+}
+
+// ReverseListResponse lists a device's reversed ports
+type ReverseListResponse struct {
+	Reverses []devices.PortReverse `json:"reverses"`
+}
+
+// ParsePortMapping parses "device-port:host-port", or a single port used on
+// both sides, e.g. "8080"
+func ParsePortMapping(spec string) (int, int, error) {
+	deviceSpec, hostSpec, found := strings.Cut(spec, ":")
+	if !found {
+		hostSpec = deviceSpec
+	}
+
+	devicePort, err := parsePort(deviceSpec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port mapping '%s', expected device-port:host-port: %w", spec, err)
+	}
+	hostPort, err := parsePort(hostSpec)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port mapping '%s', expected device-port:host-port: %w", spec, err)
+	}
+	return devicePort, hostPort, nil
+}
+
+func parsePort(spec string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(spec))
+	if err != nil {
+		return 0, fmt.Errorf("invalid port '%s'", spec)
+	}
+	return port, checkPort(port)
+}
+
+func checkPort(port int) error {
+	if port < 1 || port > 65535 {
+		return fmt.Errorf("port must be between 1 and 65535, got %d", port)
+	}
+	return nil
+}
+
+// hostAddresses returns the host's non-loopback IPv4 addresses, which a
+// device on the same network reaches it at
+func hostAddresses() []string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+
+	var hosts []string
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() || ipNet.IP.To4() == nil {
+			continue
+		}
+		hosts = append(hosts, ipNet.IP.String())
+	}
+	return hosts
+}
+
+// findPortReverser finds the device of a reverse command, locks it and starts
+// its agent. The caller releases the lock with the returned function.
+func findPortReverser(deviceID string) (devices.ControllableDevice, devices.PortReverser, func(), error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	reverser, ok := targetDevice.(devices.PortReverser)
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("reverse port forwarding is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		unlock()
+		return nil, nil, nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	return targetDevice, reverser, unlock, nil
+}
+
+// ReverseCommand makes a port on the device reach a port on the host, so apps
+// under test can call a mock server the automation host runs
+func ReverseCommand(req ReverseRequest) *CommandResponse {
+	if err := checkPort(req.DevicePort); err != nil {
+		return NewErrorResponse(fmt.Errorf("invalid device port: %w", err))
+	}
+	if err := checkPort(req.HostPort); err != nil {
+		return NewErrorResponse(fmt.Errorf("invalid host port: %w", err))
+	}
+
+	targetDevice, reverser, unlock, err := findPortReverser(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := reverser.ReversePort(req.DevicePort, req.HostPort); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to reverse port %d on device %s: %w", req.DevicePort, targetDevice.ID(), err))
+	}
+
+	// real iOS devices reach a relay on the host's network interfaces
+	if targetDevice.Platform() == "ios" && targetDevice.DeviceType() == "real" {
+		address := fmt.Sprintf("<host-address>:%d", req.DevicePort)
+		if hosts := hostAddresses(); len(hosts) > 0 {
+			address = fmt.Sprintf("%s:%d", hosts[0], req.DevicePort)
+		}
+		return NewSuccessResponse(ReverseResult{
+			Message: fmt.Sprintf("Apps on device %s reach port %d on the host at %s", targetDevice.ID(), req.HostPort, address),
+			Relay:   true,
+			Address: address,
+		})
+	}
+
+	return NewSuccessResponse(ReverseResult{
+		Message: fmt.Sprintf("localhost:%d on device %s reaches port %d on the host", req.DevicePort, targetDevice.ID(), req.HostPort),
+	})
+}
+
+// ReverseListCommand lists the ports of a device that reach the host
+func ReverseListCommand(req ReverseListRequest) *CommandResponse {
+	_, reverser, unlock, err := findPortReverser(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	reverses, err := reverser.ListReverses()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(ReverseListResponse{
+		Reverses: reverses,
+	})
+}
+
+// ReverseRemoveCommand stops a device port, or all of them, reaching the host
+func ReverseRemoveCommand(req ReverseRemoveRequest) *CommandResponse {
+	if req.All == (req.DevicePort != 0) {
+		return NewErrorResponse(fmt.Errorf("either a device port or all is required"))
+	}
+
+	targetDevice, reverser, unlock, err := findPortReverser(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := reverser.RemoveReverse(req.DevicePort); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to remove reversed port on device %s: %w", targetDevice.ID(), err))
+	}
+
+	message := fmt.Sprintf("Removed reversed port %d on device %s", req.DevicePort, targetDevice.ID())
+	if req.All {
+		message = fmt.Sprintf("Removed all reversed ports on device %s", targetDevice.ID())
+	}
+	return NewSuccessResponse(MessageResult{
+		Message: message,
+	})
+}
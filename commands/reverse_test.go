@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestParsePortMapping(t *testing.T) {
+	cases := []struct {
+		spec                 string
+		devicePort, hostPort int
+	}{
+		{"8080:8080", 8080, 8080},
+		{"8080:3000", 8080, 3000},
+		{"9000", 9000, 9000},
+	}
+	for _, c := range cases {
+		devicePort, hostPort, err := ParsePortMapping(c.spec)
+		if err != nil || devicePort != c.devicePort || hostPort != c.hostPort {
+			t.Errorf("ParsePortMapping(%q) = %d, %d, %v, want %d, %d", c.spec, devicePort, hostPort, err, c.devicePort, c.hostPort)
+		}
+	}
+
+	for _, spec := range []string{"", "http", "8080:", "0:8080", "8080:70000", "1:2:3"} {
+		if _, _, err := ParsePortMapping(spec); err == nil {
+			t.Errorf("ParsePortMapping(%q) should fail", spec)
+		}
+	}
+}
+
+func TestReverseCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	if response := ReverseCommand(ReverseRequest{DeviceID: "fake-android-1", DevicePort: 8080, HostPort: 3000}); response.Status != "ok" {
+		t.Fatalf("reverse failed: %s", response.Error)
+	}
+	if response := ReverseCommand(ReverseRequest{DeviceID: "fake-android-1", DevicePort: 9000, HostPort: 9000}); response.Status != "ok" {
+		t.Fatalf("reverse failed: %s", response.Error)
+	}
+
+	response := ReverseListCommand(ReverseListRequest{DeviceID: "fake-android-1"})
+	if response.Status != "ok" {
+		t.Fatalf("reverse list failed: %s", response.Error)
+	}
+	expected := []devices.PortReverse{
+		{DeviceID: "fake-android-1", DevicePort: 8080, HostPort: 3000},
+		{DeviceID: "fake-android-1", DevicePort: 9000, HostPort: 9000},
+	}
+	if reverses := response.Data.(ReverseListResponse).Reverses; !reflect.DeepEqual(reverses, expected) {
+		t.Errorf("expected %v, got %v", expected, reverses)
+	}
+
+	if response := ReverseRemoveCommand(ReverseRemoveRequest{DeviceID: "fake-android-1", DevicePort: 8080}); response.Status != "ok" {
+		t.Fatalf("reverse remove failed: %s", response.Error)
+	}
+	if response := ReverseRemoveCommand(ReverseRemoveRequest{DeviceID: "fake-android-1", All: true}); response.Status != "ok" {
+		t.Fatalf("reverse remove --all failed: %s", response.Error)
+	}
+	if response := ReverseRemoveCommand(ReverseRemoveRequest{DeviceID: "fake-android-1"}); response.Status != "error" {
+		t.Errorf("expected an error without a port or all")
+	}
+	if response := ReverseCommand(ReverseRequest{DeviceID: "fake-android-1", DevicePort: 0, HostPort: 3000}); response.Status != "error" {
+		t.Errorf("expected an error for port 0")
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expectedActions := []string{"reverse 8080 3000", "reverse 9000 9000", "reverse remove 8080", "reverse remove 0"}
+	if !reflect.DeepEqual(actions, expectedActions) {
+		t.Errorf("expected %v, got %v", expectedActions, actions)
+	}
+}
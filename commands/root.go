@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// RootRequest represents the parameters for checking or enabling root
+type RootRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// RootResult describes a device's root access
+type RootResult struct {
+	Message string `json:"message,omitempty"`
+	*devices.RootStatus
+}
+
+// findRootController finds the device and checks it can report root access
+func findRootController(deviceID string) (devices.ControllableDevice, devices.RootController, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	root, ok := targetDevice.(devices.RootController)
+	if !ok {
+		return nil, nil, fmt.Errorf("root access is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, root, nil
+}
+
+// RootStatusCommand reports whether a device runs a debuggable build and
+// gives root access
+func RootStatusCommand(req RootRequest) *CommandResponse {
+	targetDevice, root, err := findRootController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	status, err := root.RootStatus()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to get root status of device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(RootResult{RootStatus: status})
+}
+
+// RootEnableCommand restarts adbd as root and remounts the system partitions
+func RootEnableCommand(req RootRequest) *CommandResponse {
+	targetDevice, root, err := findRootController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	status, err := root.EnableRoot()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to enable root on device %s: %w", targetDevice.ID(), err))
+	}
+
+	message := fmt.Sprintf("Enabled root and remounted system on device %s", targetDevice.ID())
+	if status.RebootRequired {
+		message = fmt.Sprintf("Enabled root on device %s, reboot it and run 'device root enable' again to remount system", targetDevice.ID())
+	}
+
+	return NewSuccessResponse(RootResult{Message: message, RootStatus: status})
+}
@@ -1,8 +1,76 @@
 package commands
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// screen capture parameter ranges, 0 selects the default for each
+const (
+	minScreenCaptureQuality = 1
+	maxScreenCaptureQuality = 100
+	minScreenCaptureScale   = 0.1
+	maxScreenCaptureScale   = 1.0
+	minScreenCaptureFPS     = 1
+	maxScreenCaptureFPS     = 60
+	minMaxTemperature       = 20.0
+	maxMaxTemperature       = 100.0
+)
+
 type ScreenCaptureRequest struct {
 	DeviceID string  `json:"deviceId"`
 	Format   string  `json:"format"`
 	Quality  int     `json:"quality,omitempty"`
 	Scale    float64 `json:"scale,omitempty"`
+	FPS      int     `json:"fps,omitempty"`
+
+	// ThermalGuard watches the device's temperature during the capture, see
+	// NewThermalGuard. MaxTemperature, ThermalInterval or PauseWhenHot turn
+	// it on too.
+	ThermalGuard    bool    `json:"thermalGuard,omitempty"`
+	MaxTemperature  float64 `json:"maxTemperature,omitempty"`  // degrees Celsius
+	ThermalInterval int     `json:"thermalInterval,omitempty"` // seconds between samples
+	PauseWhenHot    bool    `json:"pauseWhenHot,omitempty"`
+}
+
+// NewThermalGuard returns the guard a capture asked for, or nil when it
+// didn't ask for one. A zero maxTemperature or interval means the default.
+func NewThermalGuard(enabled bool, maxTemperature float64, intervalSeconds int, pause bool) (*devices.ThermalGuard, error) {
+	if !enabled && maxTemperature == 0 && intervalSeconds == 0 && !pause {
+		return nil, nil
+	}
+
+	if maxTemperature != 0 && (maxTemperature < minMaxTemperature || maxTemperature > maxMaxTemperature) {
+		return nil, fmt.Errorf("max temperature must be between %.0f and %.0f degrees, got %g", minMaxTemperature, maxMaxTemperature, maxTemperature)
+	}
+
+	if intervalSeconds < 0 {
+		return nil, fmt.Errorf("thermal interval must be positive, got %d", intervalSeconds)
+	}
+
+	return &devices.ThermalGuard{
+		MaxTemperature: maxTemperature,
+		Interval:       time.Duration(intervalSeconds) * time.Second,
+		Pause:          pause,
+	}, nil
+}
+
+// ValidateScreenCaptureOptions checks quality, scale and frame rate are in
+// range. A zero value means the default and is always valid.
+func ValidateScreenCaptureOptions(quality int, scale float64, fps int) error {
+	if quality != 0 && (quality < minScreenCaptureQuality || quality > maxScreenCaptureQuality) {
+		return fmt.Errorf("quality must be between %d and %d, got %d", minScreenCaptureQuality, maxScreenCaptureQuality, quality)
+	}
+
+	if scale != 0 && (scale < minScreenCaptureScale || scale > maxScreenCaptureScale) {
+		return fmt.Errorf("scale must be between %.1f and %.1f, got %g", minScreenCaptureScale, maxScreenCaptureScale, scale)
+	}
+
+	if fps != 0 && (fps < minScreenCaptureFPS || fps > maxScreenCaptureFPS) {
+		return fmt.Errorf("fps must be between %d and %d, got %d", minScreenCaptureFPS, maxScreenCaptureFPS, fps)
+	}
+
+	return nil
 }
@@ -0,0 +1,19 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScreenCaptureOptions(t *testing.T) {
+	assert.NoError(t, ValidateScreenCaptureOptions(0, 0, 0))
+	assert.NoError(t, ValidateScreenCaptureOptions(1, 0.1, 1))
+	assert.NoError(t, ValidateScreenCaptureOptions(100, 1.0, 60))
+
+	assert.EqualError(t, ValidateScreenCaptureOptions(101, 0, 0), "quality must be between 1 and 100, got 101")
+	assert.EqualError(t, ValidateScreenCaptureOptions(-1, 0, 0), "quality must be between 1 and 100, got -1")
+	assert.EqualError(t, ValidateScreenCaptureOptions(0, 1.5, 0), "scale must be between 0.1 and 1.0, got 1.5")
+	assert.EqualError(t, ValidateScreenCaptureOptions(0, 0.05, 0), "scale must be between 0.1 and 1.0, got 0.05")
+	assert.EqualError(t, ValidateScreenCaptureOptions(0, 0, 120), "fps must be between 1 and 60, got 120")
+}
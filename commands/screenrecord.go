@@ -36,12 +36,30 @@ func ScreenRecordCommand(req ScreenRecordRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
+	if req.OutputPath == "" {
+		req.OutputPath, err = ArtifactPath(targetDevice.ID(), "screenrecord", "mp4")
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		if req.OutputPath == "" {
+			return NewErrorResponse(fmt.Errorf("output path is required, or set an artifacts directory"))
+		}
+	}
+
+	// the lock only covers starting the agent, holding it for the whole
+	// recording would block every other command on the device
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		OnProgress: func(message string) {
 			utils.Verbose(message)
 		},
 		Hook: GetShutdownHook(),
 	})
+	unlock()
 	if err != nil {
 		return NewErrorResponse(fmt.Errorf("error starting agent: %w", err))
 	}
@@ -14,10 +14,12 @@ import (
 
 // ScreenshotRequest represents the parameters for taking a screenshot
 type ScreenshotRequest struct {
-	DeviceID   string `json:"deviceId"`
-	Format     string `json:"format,omitempty"`     // "png" or "jpeg"
-	Quality    int    `json:"quality,omitempty"`    // 1-100, only used for JPEG
-	OutputPath string `json:"outputPath,omitempty"` // file path, "-" for stdout, or empty for default naming
+	DeviceID   string                     `json:"deviceId"`
+	Format     string                     `json:"format,omitempty"`     // "png" or "jpeg"
+	Quality    int                        `json:"quality,omitempty"`    // 1-100, only used for JPEG
+	OutputPath string                     `json:"outputPath,omitempty"` // file path, "-" for stdout, or empty for default naming
+	Clip       *devices.ScreenElementRect `json:"clip,omitempty"`       // crop to this rectangle, in screen coordinates
+	Element    string                     `json:"element,omitempty"`    // crop to the element matching this selector, e.g. "identifier=loginButton"
 }
 
 // ScreenshotResponse represents the response for a screenshot command
@@ -32,7 +34,7 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 	// Find the target device
 	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
 	if err != nil {
-		return NewErrorResponse(fmt.Errorf("error finding device: %v", err))
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
 	}
 
 	// Set default format
@@ -46,6 +48,18 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("invalid format '%s'. Supported formats are 'png' and 'jpeg'", req.Format))
 	}
 
+	if req.Clip != nil && req.Element != "" {
+		return NewErrorResponse(fmt.Errorf("clip and element cannot be used together"))
+	}
+
+	var selector ElementSelector
+	if req.Element != "" {
+		selector, err = ParseElementSelector(req.Element)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
 	// Validate JPEG quality
 	if req.Format == "jpeg" {
 		if req.Quality < 1 || req.Quality > 100 {
@@ -53,6 +67,12 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 		}
 	}
 
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
 	// Start agent if needed
 	err = targetDevice.StartAgent(devices.StartAgentConfig{
 		Hook: GetShutdownHook(),
@@ -67,6 +87,28 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 		return NewErrorResponse(fmt.Errorf("error taking screenshot: %v", err))
 	}
 
+	// Crop to the requested region, resolving the element's rect if needed
+	clip := req.Clip
+	if req.Element != "" {
+		elements, err := targetDevice.DumpSource()
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to dump UI from device %s: %v", targetDevice.ID(), err))
+		}
+
+		element := FindElement(elements, selector)
+		if element == nil {
+			return NewErrorResponse(fmt.Errorf("no element found matching '%s'", req.Element))
+		}
+		clip = &element.Rect
+	}
+
+	if clip != nil {
+		imageBytes, err = cropScreenshot(targetDevice, imageBytes, *clip)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("error cropping screenshot: %v", err))
+		}
+	}
+
 	// Convert to JPEG if requested
 	if req.Format == "jpeg" {
 		convertedBytes, err := utils.ConvertPngToJpeg(imageBytes, req.Quality)
@@ -93,14 +135,17 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 				return NewErrorResponse(fmt.Errorf("invalid output path: %v", err))
 			}
 		} else {
+			finalPath, err = ArtifactPath(targetDevice.ID(), "screenshot", screenshotExtension(req.Format))
+			if err != nil {
+				return NewErrorResponse(err)
+			}
+		}
+
+		if finalPath == "" {
 			// Default filename generation
 			timestamp := time.Now().Format("20060102150405")
 			safeDeviceID := strings.ReplaceAll(targetDevice.ID(), ":", "_")
-			extension := "png"
-			if req.Format == "jpeg" {
-				extension = "jpg"
-			}
-			fileName := fmt.Sprintf("screenshot-%s-%s.%s", safeDeviceID, timestamp, extension)
+			fileName := fmt.Sprintf("screenshot-%s-%s.%s", safeDeviceID, timestamp, screenshotExtension(req.Format))
 			finalPath, err = filepath.Abs("./" + fileName)
 			if err != nil {
 				return NewErrorResponse(fmt.Errorf("error creating default path: %v", err))
@@ -118,3 +163,27 @@ func ScreenshotCommand(req ScreenshotRequest) *CommandResponse {
 
 	return NewSuccessResponse(response)
 }
+
+// screenshotExtension returns the file extension of a screenshot format
+func screenshotExtension(format string) string {
+	if format == "jpeg" {
+		return "jpg"
+	}
+	return "png"
+}
+
+// cropScreenshot crops a PNG screenshot to a rect given in screen coordinates.
+// Screen coordinates are scaled to image pixels using the device's screen scale
+// (e.g. 3x on retina iPhones, 1x on Android).
+func cropScreenshot(device devices.ControllableDevice, imageBytes []byte, rect devices.ScreenElementRect) ([]byte, error) {
+	scale := 1
+	info, err := device.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device info: %v", err)
+	}
+	if info.ScreenSize != nil && info.ScreenSize.Scale > 0 {
+		scale = info.ScreenSize.Scale
+	}
+
+	return utils.CropPng(imageBytes, rect.X*scale, rect.Y*scale, rect.Width*scale, rect.Height*scale)
+}
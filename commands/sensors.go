@@ -0,0 +1,171 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// SensorSetRequest represents the parameters for setting a sensor
+type SensorSetRequest struct {
+	DeviceID string    `json:"deviceId"`
+	Sensor   string    `json:"sensor"`
+	Values   []float64 `json:"values"`
+}
+
+// SensorShakeRequest represents the parameters for shaking a device
+type SensorShakeRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// SensorPlayRequest represents the parameters for playing back a sensor
+// recording
+type SensorPlayRequest struct {
+	DeviceID string `json:"deviceId"`
+	Path     string `json:"path"`
+}
+
+// sensorSample is a line of a sensor recording
+type sensorSample struct {
+	At     time.Duration
+	Sensor string
+	Values []float64
+}
+
+// ParseSensorValues parses comma-separated sensor values such as "0,9.8,0"
+func ParseSensorValues(value string) ([]float64, error) {
+	var values []float64
+	for field := range strings.SplitSeq(value, ",") {
+		v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sensor value '%s', expected numbers such as 0,9.8,0", field)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// parseSensorRecording parses a recording with one sample per line,
+// "<milliseconds>,<sensor>,<values>", e.g. "500,acceleration,0,9.8,0".
+// Empty lines and lines starting with # are skipped.
+func parseSensorRecording(content string) ([]sensorSample, error) {
+	var samples []sensorSample
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, ",", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d: expected <milliseconds>,<sensor>,<values>", i+1)
+		}
+
+		ms, err := strconv.ParseInt(strings.TrimSpace(fields[0]), 10, 64)
+		if err != nil || ms < 0 {
+			return nil, fmt.Errorf("line %d: invalid time '%s'", i+1, fields[0])
+		}
+		at := time.Duration(ms) * time.Millisecond
+		if len(samples) > 0 && at < samples[len(samples)-1].At {
+			return nil, fmt.Errorf("line %d: samples must be in time order", i+1)
+		}
+
+		values, err := ParseSensorValues(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		samples = append(samples, sensorSample{At: at, Sensor: strings.TrimSpace(fields[1]), Values: values})
+	}
+
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("recording has no samples")
+	}
+	return samples, nil
+}
+
+// findSensorController finds the device and checks its sensors can be simulated
+func findSensorController(deviceID string) (devices.ControllableDevice, devices.SensorController, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	sensors, ok := targetDevice.(devices.SensorController)
+	if !ok {
+		return nil, nil, fmt.Errorf("sensor simulation is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, sensors, nil
+}
+
+// SensorSetCommand holds a sensor of the device at the given values
+func SensorSetCommand(req SensorSetRequest) *CommandResponse {
+	if req.Sensor == "" {
+		return NewErrorResponse(fmt.Errorf("sensor is required"))
+	}
+
+	targetDevice, sensors, err := findSensorController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := sensors.SetSensor(req.Sensor, req.Values); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to set sensor on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Set sensor %s on device %s", req.Sensor, targetDevice.ID()),
+	})
+}
+
+// SensorShakeCommand shakes the device
+func SensorShakeCommand(req SensorShakeRequest) *CommandResponse {
+	targetDevice, sensors, err := findSensorController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := sensors.Shake(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to shake device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Shook device %s", targetDevice.ID()),
+	})
+}
+
+// SensorPlayCommand plays back a sensor recording, setting each sample at
+// its time from the start of the playback
+func SensorPlayCommand(req SensorPlayRequest) *CommandResponse {
+	content, err := os.ReadFile(req.Path)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to read recording: %w", err))
+	}
+
+	samples, err := parseSensorRecording(string(content))
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("invalid recording %s: %w", req.Path, err))
+	}
+
+	targetDevice, sensors, err := findSensorController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	start := time.Now()
+	for _, sample := range samples {
+		time.Sleep(time.Until(start.Add(sample.At)))
+		if err := sensors.SetSensor(sample.Sensor, sample.Values); err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to set sensor on device %s at %s: %w", targetDevice.ID(), sample.At, err))
+		}
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Played %d sensor samples on device %s", len(samples), targetDevice.ID()),
+	})
+}
@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestParseSensorRecording(t *testing.T) {
+	samples, err := parseSensorRecording("# shake\n0,acceleration,0,9.8,0\n\n50, acceleration, 25,9.8,0\n100,light,300\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []sensorSample{
+		{At: 0, Sensor: "acceleration", Values: []float64{0, 9.8, 0}},
+		{At: 50 * time.Millisecond, Sensor: "acceleration", Values: []float64{25, 9.8, 0}},
+		{At: 100 * time.Millisecond, Sensor: "light", Values: []float64{300}},
+	}
+	if !reflect.DeepEqual(samples, want) {
+		t.Errorf("unexpected samples: %+v", samples)
+	}
+
+	for _, content := range []string{"", "0,acceleration", "x,light,1", "100,light,1\n50,light,2", "0,light,bright"} {
+		if _, err := parseSensorRecording(content); err == nil {
+			t.Errorf("expected an error for %q", content)
+		}
+	}
+}
+
+func TestSensorPlayCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false)
+
+	path := filepath.Join(t.TempDir(), "recording.csv")
+	if err := os.WriteFile(path, []byte("0,acceleration,0,9.8,0\n10,light,300\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	response := SensorPlayCommand(SensorPlayRequest{DeviceID: "fake-android-1", Path: path})
+	if response.Status != "ok" {
+		t.Fatalf("play failed: %s", response.Error)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	if !reflect.DeepEqual(actions, []string{"sensor acceleration [0 9.8 0]", "sensor light [300]"}) {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+}
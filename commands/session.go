@@ -0,0 +1,633 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// sessionVersion is the version of the session file format
+const sessionVersion = 1
+
+// SessionEntry is one command run while a session was being recorded
+type SessionEntry struct {
+	ID               string          `json:"id"`
+	Command          string          `json:"command"`
+	Request          json.RawMessage `json:"request"`
+	StartedAt        time.Time       `json:"startedAt"`
+	DurationMs       int64           `json:"durationMs"`
+	Status           string          `json:"status"` // "ok", "error", or "incomplete" when the command never finished
+	Error            string          `json:"error,omitempty"`
+	ScreenshotBefore string          `json:"screenshotBefore,omitempty"` // relative to the session file
+	ScreenshotAfter  string          `json:"screenshotAfter,omitempty"`
+}
+
+// Session is a recorded session, replayable with SessionReplayCommand
+type Session struct {
+	Version   int            `json:"version"`
+	DeviceID  string         `json:"deviceId"`
+	StartedAt time.Time      `json:"startedAt"`
+	StoppedAt time.Time      `json:"stoppedAt"`
+	Entries   []SessionEntry `json:"entries"`
+}
+
+// activeSession is what 'session record' leaves behind in the config
+// directory, one per device, for the commands that follow on that device to
+// find
+type activeSession struct {
+	Path        string    `json:"path"`
+	DeviceID    string    `json:"deviceId"`
+	Screenshots bool      `json:"screenshots"`
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+// sessionJournalRecord is one line of the journal a session is recorded to.
+// A command is written before it runs and again with its outcome, so
+// commands interrupted by a crash are still in the session.
+type sessionJournalRecord struct {
+	SessionEntry
+	Done bool `json:"done,omitempty"`
+}
+
+// sessionCommands are the commands a session replays. Read-only commands are
+// recorded, but there is nothing to replay for them.
+var sessionCommands = map[string]func(request json.RawMessage, deviceID string) *CommandResponse{
+	"tap":               sessionCommand(TapCommand),
+	"longpress":         sessionCommand(LongPressCommand),
+	"swipe":             sessionCommand(SwipeCommand),
+	"text":              sessionCommand(TextCommand),
+	"button":            sessionCommand(ButtonCommand),
+	"keys":              sessionCommand(KeysCommand),
+	"url":               sessionCommand(URLCommand),
+	"orientation.set":   sessionCommand(OrientationSetCommand),
+	"apps.launch":       sessionCommand(LaunchAppCommand),
+	"apps.terminate":    sessionCommand(TerminateAppCommand),
+	"apps.terminateAll": sessionCommand(TerminateAllAppsCommand),
+}
+
+// sessionCommand adapts a command to run a recorded request, on deviceID
+// when it is given
+func sessionCommand[T any](fn func(T) *CommandResponse) func(json.RawMessage, string) *CommandResponse {
+	return func(request json.RawMessage, deviceID string) *CommandResponse {
+		fields := map[string]any{}
+		if err := json.Unmarshal(request, &fields); err != nil {
+			return NewErrorResponse(fmt.Errorf("invalid request: %w", err))
+		}
+		if deviceID != "" {
+			fields["deviceId"] = deviceID
+		}
+
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("invalid request: %w", err))
+		}
+
+		var req T
+		if err := json.Unmarshal(data, &req); err != nil {
+			return NewErrorResponse(fmt.Errorf("invalid request: %w", err))
+		}
+		return fn(req)
+	}
+}
+
+// activeSessionsDir returns the directory active sessions are kept in
+func activeSessionsDir() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// activeSessionPath returns where the active session of a device is kept
+func activeSessionPath(deviceID string) (string, error) {
+	dir, err := activeSessionsDir()
+	if err != nil {
+		return "", err
+	}
+
+	// device IDs may hold ':' and '/', e.g. adb serials of network devices
+	name := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, deviceID)
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// readActiveSession reads an active session file, or returns nil when there
+// is none
+func readActiveSession(path string) (*activeSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read active session: %w", err)
+	}
+
+	var session activeSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to parse active session %s: %w", path, err)
+	}
+	return &session, nil
+}
+
+// loadActiveSession returns the session being recorded on a device, or nil
+func loadActiveSession(deviceID string) (*activeSession, error) {
+	path, err := activeSessionPath(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := readActiveSession(path)
+	if err != nil || session == nil || session.DeviceID != deviceID {
+		return nil, err
+	}
+	return session, nil
+}
+
+// listActiveSessions returns the sessions being recorded, on any device
+func listActiveSessions() ([]*activeSession, error) {
+	dir, err := activeSessionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+
+	var sessions []*activeSession
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		session, err := readActiveSession(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			utils.Verbose("Skipping active session %s: %v", entry.Name(), err)
+			continue
+		}
+		if session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// findActiveSession returns the session being recorded on the device a
+// command targets, resolving deviceID the way the command will, or nil
+func findActiveSession(deviceID string) (*activeSession, error) {
+	sessions, err := listActiveSessions()
+	if err != nil || len(sessions) == 0 {
+		return nil, err
+	}
+
+	for _, session := range sessions {
+		if deviceID != "" && session.DeviceID == deviceID {
+			return session, nil
+		}
+	}
+
+	// an auto-selected device, or one given by serial or USB port
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range sessions {
+		if session.DeviceID == targetDevice.ID() {
+			return session, nil
+		}
+	}
+	return nil, nil
+}
+
+// journalPath returns the journal a session file is recorded to
+func journalPath(sessionPath string) string {
+	return sessionPath + ".journal"
+}
+
+// screenshotsDir returns the directory of a session file's screenshots
+func screenshotsDir(sessionPath string) string {
+	return strings.TrimSuffix(sessionPath, filepath.Ext(sessionPath)) + "-screenshots"
+}
+
+// SessionRecordRequest represents the parameters for recording a session
+type SessionRecordRequest struct {
+	DeviceID    string `json:"deviceId"`
+	Output      string `json:"output"`
+	Screenshots bool   `json:"screenshots"` // capture screenshots before and after every action
+}
+
+// SessionRecordResult describes a session that started recording
+type SessionRecordResult struct {
+	Message  string `json:"message"`
+	Path     string `json:"path"`
+	DeviceID string `json:"deviceId"`
+}
+
+// SessionRecordCommand starts recording the commands that follow on a device
+// into a session file, until SessionStopCommand. Each device records its own
+// session.
+func SessionRecordCommand(req SessionRecordRequest) *CommandResponse {
+	if req.Output == "" {
+		return NewErrorResponse(fmt.Errorf("output path is required"))
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	active, err := loadActiveSession(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	if active != nil {
+		return NewErrorResponse(fmt.Errorf("a session is already being recorded on device %s to %s, stop it first", active.DeviceID, active.Path))
+	}
+
+	path, err := filepath.Abs(req.Output)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("invalid output path: %w", err))
+	}
+
+	// an empty journal, so stopping a session without commands works
+	if err := os.WriteFile(journalPath(path), nil, 0o600); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to create session journal: %w", err))
+	}
+
+	active = &activeSession{
+		Path:        path,
+		DeviceID:    targetDevice.ID(),
+		Screenshots: req.Screenshots,
+		StartedAt:   time.Now(),
+	}
+
+	data, err := json.MarshalIndent(active, "", "  ")
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to encode active session: %w", err))
+	}
+
+	activePath, err := activeSessionPath(active.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(activePath), 0o700); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to create config directory: %w", err))
+	}
+	if err := os.WriteFile(activePath, data, 0o600); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to save active session: %w", err))
+	}
+
+	return NewSuccessResponse(SessionRecordResult{
+		Message:  fmt.Sprintf("Recording session on device %s to %s", active.DeviceID, path),
+		Path:     path,
+		DeviceID: active.DeviceID,
+	})
+}
+
+// SessionStopRequest represents the parameters for stopping a session
+type SessionStopRequest struct {
+	DeviceID string `json:"deviceId"` // the device whose session to stop, optional when only one is recorded
+}
+
+// SessionStopResult describes a session that stopped recording
+type SessionStopResult struct {
+	Message string `json:"message"`
+	Path    string `json:"path"`
+	Entries int    `json:"entries"`
+}
+
+// stoppingSession returns the session SessionStopCommand stops: the one of
+// the device given, or the only one being recorded
+func stoppingSession(deviceID string) (*activeSession, error) {
+	if deviceID != "" {
+		// the device may be gone by the time its session is stopped
+		active, err := loadActiveSession(deviceID)
+		if err != nil || active != nil {
+			return active, err
+		}
+
+		targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+		if err != nil {
+			return nil, fmt.Errorf("no session is being recorded on device %s", deviceID)
+		}
+		active, err = loadActiveSession(targetDevice.ID())
+		if err != nil {
+			return nil, err
+		}
+		if active == nil {
+			return nil, fmt.Errorf("no session is being recorded on device %s", targetDevice.ID())
+		}
+		return active, nil
+	}
+
+	sessions, err := listActiveSessions()
+	if err != nil {
+		return nil, err
+	}
+	switch len(sessions) {
+	case 0:
+		return nil, fmt.Errorf("no session is being recorded")
+	case 1:
+		return sessions[0], nil
+	}
+
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.DeviceID)
+	}
+	sort.Strings(ids)
+	return nil, fmt.Errorf("sessions are being recorded on devices %s, pass the device whose session to stop", strings.Join(ids, ", "))
+}
+
+// SessionStopCommand stops recording a device's session and writes the
+// session file from its journal
+func SessionStopCommand(req SessionStopRequest) *CommandResponse {
+	active, err := stoppingSession(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	entries, err := readSessionJournal(journalPath(active.Path))
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	session := Session{
+		Version:   sessionVersion,
+		DeviceID:  active.DeviceID,
+		StartedAt: active.StartedAt,
+		StoppedAt: time.Now(),
+		Entries:   entries,
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to encode session: %w", err))
+	}
+	if err := os.WriteFile(active.Path, data, 0o600); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to write session: %w", err))
+	}
+
+	activePath, err := activeSessionPath(active.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	if err := os.Remove(activePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return NewErrorResponse(fmt.Errorf("failed to clear active session: %w", err))
+	}
+	_ = os.Remove(journalPath(active.Path))
+
+	return NewSuccessResponse(SessionStopResult{
+		Message: fmt.Sprintf("Recorded %d commands to %s", len(entries), active.Path),
+		Path:    active.Path,
+		Entries: len(entries),
+	})
+}
+
+// readSessionJournal merges the records of a journal into entries, in the
+// order the commands started
+func readSessionJournal(path string) ([]SessionEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read session journal: %w", err)
+	}
+
+	byID := map[string]*SessionEntry{}
+	var order []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record sessionJournalRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// a line cut short by a crash
+			utils.Verbose("Skipping invalid session journal line: %v", err)
+			continue
+		}
+
+		// a command's outcome is always written after the command itself
+		if record.Done {
+			if entry, ok := byID[record.ID]; ok {
+				entry.DurationMs = record.DurationMs
+				entry.Status = record.Status
+				entry.Error = record.Error
+				entry.ScreenshotAfter = record.ScreenshotAfter
+			}
+			continue
+		}
+
+		entry := record.SessionEntry
+		byID[record.ID] = &entry
+		order = append(order, record.ID)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read session journal: %w", err)
+	}
+
+	entries := make([]SessionEntry, 0, len(order))
+	for _, id := range order {
+		entry := byID[id]
+		if entry.Status == "" {
+			entry.Status = "incomplete"
+		}
+		entries = append(entries, *entry)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedAt.Before(entries[j].StartedAt)
+	})
+	return entries, nil
+}
+
+// appendSessionJournal writes a record to the journal of a session
+func appendSessionJournal(active *activeSession, record sessionJournalRecord) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		utils.Verbose("Failed to encode session journal record: %v", err)
+		return
+	}
+
+	f, err := os.OpenFile(journalPath(active.Path), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		utils.Verbose("Failed to open session journal: %v", err)
+		return
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		utils.Verbose("Failed to write session journal: %v", err)
+	}
+}
+
+// sessionScreenshot saves a screenshot of the session's device, returning
+// its path relative to the session file, or "" when it couldn't be taken
+func sessionScreenshot(active *activeSession, deviceID, name string) string {
+	dir := screenshotsDir(active.Path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		utils.Verbose("Failed to create session screenshots directory: %v", err)
+		return ""
+	}
+
+	if deviceID == "" {
+		deviceID = active.DeviceID
+	}
+
+	path := filepath.Join(dir, name+".png")
+	response := ScreenshotCommand(ScreenshotRequest{DeviceID: deviceID, OutputPath: path})
+	if response.Status == "error" {
+		utils.Verbose("Session screenshot failed: %s", response.Error)
+		return ""
+	}
+
+	return filepath.Join(filepath.Base(dir), name+".png")
+}
+
+// RecordSessionCommand runs a command with run, recording it into the session
+// being recorded on the command's device, if any. Actions are captured with
+// screenshots before and after them when the session asked for screenshots.
+func RecordSessionCommand(name string, request any, run func() *CommandResponse) *CommandResponse {
+	data, err := json.Marshal(request)
+	if err != nil {
+		utils.Verbose("Not recording '%s': %v", name, err)
+		return run()
+	}
+
+	var target struct {
+		DeviceID string `json:"deviceId"`
+	}
+	_ = json.Unmarshal(data, &target)
+
+	active, err := findActiveSession(target.DeviceID)
+	if err != nil {
+		utils.Verbose("Not recording '%s': %v", name, err)
+	}
+	if active == nil {
+		return run()
+	}
+
+	startedAt := time.Now()
+	entry := SessionEntry{
+		ID:        fmt.Sprintf("%d-%d", startedAt.UnixNano(), os.Getpid()),
+		Command:   name,
+		Request:   data,
+		StartedAt: startedAt,
+	}
+
+	_, isAction := sessionCommands[name]
+	screenshots := isAction && active.Screenshots
+	if screenshots {
+		entry.ScreenshotBefore = sessionScreenshot(active, target.DeviceID, entry.ID+"-before")
+	}
+	appendSessionJournal(active, sessionJournalRecord{SessionEntry: entry})
+
+	response := run()
+
+	done := SessionEntry{
+		ID:         entry.ID,
+		DurationMs: time.Since(startedAt).Milliseconds(),
+		Status:     response.Status,
+		Error:      response.Error,
+	}
+	if screenshots {
+		done.ScreenshotAfter = sessionScreenshot(active, target.DeviceID, entry.ID+"-after")
+	}
+	appendSessionJournal(active, sessionJournalRecord{SessionEntry: done, Done: true})
+
+	return response
+}
+
+// SessionReplayRequest represents the parameters for replaying a session
+type SessionReplayRequest struct {
+	Path     string `json:"path"`
+	DeviceID string `json:"deviceId"` // the device to replay on, the recorded device by default
+	Timing   bool   `json:"timing"`   // wait between commands as long as when they were recorded
+}
+
+// SessionReplayResult describes a replayed session
+type SessionReplayResult struct {
+	Message  string `json:"message"`
+	Replayed int    `json:"replayed"`
+	Skipped  int    `json:"skipped"`
+}
+
+// SessionReplayCommand re-runs the actions of a recorded session, stopping at
+// the first one that fails. Read-only commands and commands that failed when
+// recorded are skipped.
+func SessionReplayCommand(req SessionReplayRequest) *CommandResponse {
+	data, err := os.ReadFile(req.Path)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to read session: %w", err))
+	}
+
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to parse session %s: %w", req.Path, err))
+	}
+	if session.Version != sessionVersion {
+		return NewErrorResponse(fmt.Errorf("unsupported session version %d", session.Version))
+	}
+
+	deviceID := req.DeviceID
+	if deviceID == "" {
+		deviceID = session.DeviceID
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	replayed, skipped := 0, 0
+	var previous *SessionEntry
+	for i := range session.Entries {
+		entry := &session.Entries[i]
+
+		run, ok := sessionCommands[entry.Command]
+		if !ok || entry.Status != "ok" {
+			skipped++
+			continue
+		}
+
+		if req.Timing && previous != nil {
+			gap := entry.StartedAt.Sub(previous.StartedAt) - time.Duration(previous.DurationMs)*time.Millisecond
+			if gap > 0 {
+				time.Sleep(gap)
+			}
+		}
+		previous = entry
+
+		utils.Verbose("Replaying %s %s", entry.Command, string(entry.Request))
+		response := run(entry.Request, targetDevice.ID())
+		if response.Status == "error" {
+			return NewErrorResponse(fmt.Errorf("replaying command %d (%s) failed: %s", i+1, entry.Command, response.Error))
+		}
+		replayed++
+	}
+
+	return NewSuccessResponse(SessionReplayResult{
+		Message:  fmt.Sprintf("Replayed %d commands on device %s", replayed, targetDevice.ID()),
+		Replayed: replayed,
+		Skipped:  skipped,
+	})
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestSessionRecordAndReplay(t *testing.T) {
+	useFakeDevices(t, 2)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	path := filepath.Join(t.TempDir(), "session.json")
+
+	response := SessionRecordCommand(SessionRecordRequest{DeviceID: "fake-android-1", Output: path, Screenshots: true})
+	if response.Status != "ok" {
+		t.Fatalf("record failed: %s", response.Error)
+	}
+	if response := SessionRecordCommand(SessionRecordRequest{DeviceID: "fake-android-1", Output: path}); response.Status != "error" {
+		t.Error("expected recording a second session to fail")
+	}
+
+	record := func(name string, req any, run func() *CommandResponse) {
+		t.Helper()
+		if response := RecordSessionCommand(name, req, run); response.Status != "ok" {
+			t.Fatalf("%s failed: %s", name, response.Error)
+		}
+	}
+
+	tap := TapRequest{DeviceID: "fake-android-1", X: 10, Y: 20}
+	record("tap", tap, func() *CommandResponse { return TapCommand(tap) })
+	orientation := OrientationGetRequest{DeviceID: "fake-android-1"}
+	record("orientation.get", orientation, func() *CommandResponse { return OrientationGetCommand(orientation) })
+	text := TextRequest{DeviceID: "fake-android-1", Text: "hello"}
+	record("text", text, func() *CommandResponse { return TextCommand(text) })
+
+	// commands on other devices aren't recorded
+	other := TapRequest{DeviceID: "fake-ios-2", X: 1, Y: 2}
+	record("tap", other, func() *CommandResponse { return TapCommand(other) })
+
+	response = SessionStopCommand(SessionStopRequest{})
+	if response.Status != "ok" {
+		t.Fatalf("stop failed: %s", response.Error)
+	}
+	if stopped := response.Data.(SessionStopResult); stopped.Entries != 3 {
+		t.Errorf("expected 3 recorded commands, got %d", stopped.Entries)
+	}
+	if response := SessionStopCommand(SessionStopRequest{}); response.Status != "error" {
+		t.Error("expected stopping without a session to fail")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("session file not written: %v", err)
+	}
+	var session Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		t.Fatalf("invalid session file: %v", err)
+	}
+
+	var names []string
+	for _, entry := range session.Entries {
+		names = append(names, entry.Command)
+		if entry.Status != "ok" {
+			t.Errorf("%s: expected status ok, got %q", entry.Command, entry.Status)
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"tap", "orientation.get", "text"}) {
+		t.Errorf("unexpected recorded commands %v", names)
+	}
+
+	// only actions get screenshots
+	first := session.Entries[0]
+	if first.ScreenshotBefore == "" || first.ScreenshotAfter == "" {
+		t.Errorf("expected screenshots around the tap, got %+v", first)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), first.ScreenshotAfter)); err != nil {
+		t.Errorf("screenshot not saved: %v", err)
+	}
+	if session.Entries[1].ScreenshotBefore != "" {
+		t.Errorf("expected no screenshots around orientation.get, got %+v", session.Entries[1])
+	}
+
+	response = SessionReplayCommand(SessionReplayRequest{Path: path, DeviceID: "fake-ios-2"})
+	if response.Status != "ok" {
+		t.Fatalf("replay failed: %s", response.Error)
+	}
+	result := response.Data.(SessionReplayResult)
+	if result.Replayed != 2 || result.Skipped != 1 {
+		t.Errorf("expected 2 replayed and 1 skipped, got %+v", result)
+	}
+
+	actions := fake.Get("fake-ios-2").Actions()
+	if !reflect.DeepEqual(actions, []string{"tap 1,2", "tap 10,20", "type hello"}) {
+		t.Errorf("unexpected replayed actions %v", actions)
+	}
+}
+
+func TestReadSessionJournalIncomplete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json.journal")
+	journal := `{"id":"1","command":"tap","request":{},"startedAt":"2026-01-01T00:00:00Z"}
+{"id":"1","status":"ok","durationMs":5,"startedAt":"0001-01-01T00:00:00Z","request":null,"command":"","done":true}
+{"id":"2","command":"swipe","request":{},"startedAt":"2026-01-01T00:00:01Z"}
+{"id":"3","comm`
+	if err := os.WriteFile(path, []byte(journal), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := readSessionJournal(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %+v", entries)
+	}
+	if entries[0].Status != "ok" || entries[0].DurationMs != 5 {
+		t.Errorf("unexpected finished entry %+v", entries[0])
+	}
+	if entries[1].Status != "incomplete" {
+		t.Errorf("expected the interrupted command to be incomplete, got %+v", entries[1])
+	}
+}
+
+func TestSessionsPerDevice(t *testing.T) {
+	useFakeDevices(t, 2)
+	_, _ = devices.GetAllControllableDevices(false)
+
+	dir := t.TempDir()
+	for _, id := range []string{"fake-android-1", "fake-ios-2"} {
+		response := SessionRecordCommand(SessionRecordRequest{DeviceID: id, Output: filepath.Join(dir, id+".json")})
+		if response.Status != "ok" {
+			t.Fatalf("record on %s failed: %s", id, response.Error)
+		}
+	}
+
+	tap := TapRequest{DeviceID: "fake-ios-2", X: 1, Y: 2}
+	RecordSessionCommand("tap", tap, func() *CommandResponse { return TapCommand(tap) })
+
+	if response := SessionStopCommand(SessionStopRequest{}); response.Status != "error" {
+		t.Error("expected stopping without a device to fail while two sessions are recorded")
+	}
+
+	expected := map[string]int{"fake-android-1": 0, "fake-ios-2": 1}
+	for id, entries := range expected {
+		response := SessionStopCommand(SessionStopRequest{DeviceID: id})
+		if response.Status != "ok" {
+			t.Fatalf("stop on %s failed: %s", id, response.Error)
+		}
+		if stopped := response.Data.(SessionStopResult); stopped.Entries != entries {
+			t.Errorf("%s: expected %d recorded commands, got %d", id, entries, stopped.Entries)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// SnapshotRequest represents the parameters for a snapshot
+type SnapshotRequest struct {
+	DeviceID string `json:"deviceId"`
+	Format   string `json:"format,omitempty"`  // "png" or "jpeg"
+	Quality  int    `json:"quality,omitempty"` // 1-100, only used for JPEG
+	Query    string `json:"query,omitempty"`   // only include elements matching this ElementQuery
+}
+
+// SnapshotResponse holds a screenshot and the UI elements on screen with it
+type SnapshotResponse struct {
+	Format        string                     `json:"format"`
+	Screenshot    string                     `json:"screenshot"` // base64 encoded image data
+	Elements      []devices.ScreenElement    `json:"elements"`
+	ForegroundApp *devices.ForegroundAppInfo `json:"foregroundApp,omitempty"`
+	Orientation   string                     `json:"orientation,omitempty"`
+	CapturedAt    time.Time                  `json:"capturedAt"`
+
+	// SkewMs is how far apart the screenshot and the UI dump completed, the
+	// window in which the screen could have changed between them
+	SkewMs int64 `json:"skewMs"`
+}
+
+// SnapshotCommand captures a screenshot and dumps the UI concurrently, so
+// both describe the same screen, along with the foreground app and
+// orientation
+func SnapshotCommand(req SnapshotRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	if req.Format == "" {
+		req.Format = "png"
+	}
+
+	req.Format = strings.ToLower(req.Format)
+	if req.Format != "png" && req.Format != "jpeg" {
+		return NewErrorResponse(fmt.Errorf("invalid format '%s'. Supported formats are 'png' and 'jpeg'", req.Format))
+	}
+
+	if req.Format == "jpeg" && (req.Quality < 1 || req.Quality > 100) {
+		req.Quality = 90
+	}
+
+	var query *ElementQuery
+	if req.Query != "" {
+		query, err = ParseElementQuery(req.Query)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %w", targetDevice.ID(), err))
+	}
+
+	var (
+		wg sync.WaitGroup
+
+		imageBytes    []byte
+		screenshotErr error
+		screenshotAt  time.Time
+
+		elements []devices.ScreenElement
+		dumpErr  error
+		dumpAt   time.Time
+
+		foregroundApp *devices.ForegroundAppInfo
+		orientation   string
+	)
+
+	// the screenshot and the dump start together, the rest is stable enough
+	// to read alongside them
+	wg.Add(4)
+	go func() {
+		defer wg.Done()
+		imageBytes, screenshotErr = targetDevice.TakeScreenshot()
+		screenshotAt = time.Now()
+	}()
+	go func() {
+		defer wg.Done()
+		elements, dumpErr = targetDevice.DumpSource()
+		dumpAt = time.Now()
+	}()
+	go func() {
+		defer wg.Done()
+		app, err := targetDevice.GetForegroundApp()
+		if err != nil {
+			utils.Verbose("Snapshot without foreground app: %v", err)
+			return
+		}
+		foregroundApp = app
+	}()
+	go func() {
+		defer wg.Done()
+		value, err := targetDevice.GetOrientation()
+		if err != nil {
+			utils.Verbose("Snapshot without orientation: %v", err)
+			return
+		}
+		orientation = value
+	}()
+	wg.Wait()
+
+	if screenshotErr != nil {
+		return NewErrorResponse(fmt.Errorf("error taking screenshot: %w", screenshotErr))
+	}
+	if dumpErr != nil {
+		return NewErrorResponse(fmt.Errorf("failed to dump UI from device %s: %w", targetDevice.ID(), dumpErr))
+	}
+
+	if req.Format == "jpeg" {
+		imageBytes, err = utils.ConvertPngToJpeg(imageBytes, req.Quality)
+		if err != nil {
+			return NewErrorResponse(fmt.Errorf("error converting to JPEG: %w", err))
+		}
+	}
+
+	if query != nil {
+		elements = query.Filter(elements)
+	}
+	if elements == nil {
+		elements = []devices.ScreenElement{}
+	}
+
+	capturedAt := screenshotAt
+	if dumpAt.Before(capturedAt) {
+		capturedAt = dumpAt
+	}
+
+	return NewSuccessResponse(SnapshotResponse{
+		Format:        req.Format,
+		Screenshot:    base64.StdEncoding.EncodeToString(imageBytes),
+		Elements:      elements,
+		ForegroundApp: foregroundApp,
+		Orientation:   orientation,
+		CapturedAt:    capturedAt,
+		SkewMs:        screenshotAt.Sub(dumpAt).Abs().Milliseconds(),
+	})
+}
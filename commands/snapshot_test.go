@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestSnapshotCommand(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	device := fake.Get("fake-android-1")
+	device.SetElements([]devices.ScreenElement{
+		{Type: "Button", Label: strPtr("Sign in")},
+		{Type: "TextView", Text: strPtr("Welcome")},
+	})
+
+	response := SnapshotCommand(SnapshotRequest{DeviceID: "fake-android-1", Query: "type=Button"})
+	if response.Status != "ok" {
+		t.Fatalf("snapshot failed: %s", response.Error)
+	}
+
+	snapshot := response.Data.(SnapshotResponse)
+	if snapshot.Format != "png" || snapshot.Orientation == "" || snapshot.ForegroundApp == nil {
+		t.Errorf("unexpected snapshot: %+v", snapshot)
+	}
+	if len(snapshot.Elements) != 1 || *snapshot.Elements[0].Label != "Sign in" {
+		t.Errorf("unexpected elements: %+v", snapshot.Elements)
+	}
+
+	image, err := base64.StdEncoding.DecodeString(snapshot.Screenshot)
+	if err != nil || len(image) < 8 || string(image[1:4]) != "PNG" {
+		t.Errorf("expected a base64 PNG screenshot")
+	}
+}
+
+func TestSnapshotCommandFailsWithoutDump(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	fake.Get("fake-android-1").FailWith("DumpSource", errors.New("agent crashed"))
+
+	response := SnapshotCommand(SnapshotRequest{DeviceID: "fake-android-1"})
+	if response.Status != "error" {
+		t.Fatalf("expected the snapshot to fail without a UI dump")
+	}
+}
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// StayAwakeRequest represents the parameters for keeping a device awake
+type StayAwakeRequest struct {
+	DeviceID string `json:"deviceId"`
+	Enabled  bool   `json:"enabled"`
+}
+
+// StayAwakeCommand keeps the device screen on while it is plugged in, or
+// restores the original setting
+func StayAwakeCommand(req StayAwakeRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	controller, ok := targetDevice.(devices.StayAwakeController)
+	if !ok {
+		return NewErrorResponse(stayAwakeUnsupportedError(targetDevice))
+	}
+
+	if err := controller.SetStayAwake(req.Enabled); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to set stay-awake on device %s: %v", targetDevice.ID(), err))
+	}
+
+	state := "off"
+	if req.Enabled {
+		state = "on"
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Stay-awake is %s on device %s", state, targetDevice.ID()),
+	})
+}
+
+// stayAwakeUnsupportedError explains why a device can't be kept awake. Real
+// iOS devices have no setting or service to keep the screen on remotely, so
+// their Auto-Lock has to be turned off on the device.
+func stayAwakeUnsupportedError(device devices.ControllableDevice) error {
+	if device.Platform() == "ios" && device.DeviceType() == "real" {
+		return fmt.Errorf("stay-awake is not supported on real iOS devices, set Auto-Lock to Never in Settings > Display & Brightness on device %s instead", device.ID())
+	}
+	return fmt.Errorf("stay-awake is not supported on %s %s devices", device.Platform(), device.DeviceType())
+}
+
+var (
+	stayAwakeMu       sync.Mutex
+	stayAwakeEnforced bool
+	stayAwakeDevices  = make(map[string]bool)
+)
+
+// SetStayAwakeEnforced makes every device found by a command stay awake, until
+// the shutdown hook restores their original settings. The server uses this so
+// devices it controls don't sleep during long test runs.
+func SetStayAwakeEnforced(enforced bool) {
+	stayAwakeMu.Lock()
+	stayAwakeEnforced = enforced
+	stayAwakeMu.Unlock()
+}
+
+// enforceStayAwake keeps a newly found device awake when enforcement is on.
+// Failures are logged and never fail the command that found the device.
+func enforceStayAwake(device devices.ControllableDevice) {
+	stayAwakeMu.Lock()
+	defer stayAwakeMu.Unlock()
+
+	if !stayAwakeEnforced || stayAwakeDevices[device.ID()] || device.State() != "online" {
+		return
+	}
+
+	controller, ok := device.(devices.StayAwakeController)
+	if !ok {
+		// warn once, the device won't be kept awake on later commands either
+		utils.Info("Device %s will not be kept awake: %v", device.ID(), stayAwakeUnsupportedError(device))
+		stayAwakeDevices[device.ID()] = true
+		return
+	}
+
+	if err := controller.SetStayAwake(true); err != nil {
+		utils.Verbose("failed to keep device %s awake: %v", device.ID(), err)
+		return
+	}
+	stayAwakeDevices[device.ID()] = true
+
+	if hook := GetShutdownHook(); hook != nil {
+		hook.Register("stay-awake "+device.ID(), func() error {
+			return controller.SetStayAwake(false)
+		})
+	}
+}
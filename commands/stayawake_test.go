@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestStayAwakeEnforced(t *testing.T) {
+	useFakeDevices(t, 2)
+
+	hook := devices.NewShutdownHook()
+	previous := GetShutdownHook()
+	SetShutdownHook(hook)
+	SetStayAwakeEnforced(true)
+	t.Cleanup(func() {
+		SetStayAwakeEnforced(false)
+		SetShutdownHook(previous)
+		stayAwakeMu.Lock()
+		stayAwakeDevices = make(map[string]bool)
+		stayAwakeMu.Unlock()
+	})
+
+	for i := 0; i < 2; i++ {
+		if response := TapCommand(TapRequest{DeviceID: "fake-android-1", X: 1, Y: 2}); response.Status != "ok" {
+			t.Fatalf("tap failed: %s", response.Error)
+		}
+	}
+
+	if err := hook.Shutdown(); err != nil {
+		t.Fatalf("shutdown failed: %v", err)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expected := []string{"stay-awake on", "tap 1,2", "tap 1,2", "stay-awake off"}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+}
+
+// realIOSDevice stands in for a real iOS device
+type realIOSDevice struct {
+	*fake.Device
+}
+
+func (d realIOSDevice) DeviceType() string { return "real" }
+
+func TestStayAwakeUnsupportedOnRealIOS(t *testing.T) {
+	err := stayAwakeUnsupportedError(realIOSDevice{fake.New("00008110-000A", "ios")})
+	if !strings.Contains(err.Error(), "Auto-Lock") {
+		t.Errorf("expected the error to point at Auto-Lock, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// StorageRequest represents the parameters for inspecting or freeing storage
+type StorageRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// StorageFillRequest represents the parameters for filling storage
+type StorageFillRequest struct {
+	DeviceID string `json:"deviceId"`
+
+	// LeaveFree is how much space to leave free, e.g. "100M" or "1G",
+	// DefaultStorageLeaveFree when empty
+	LeaveFree string `json:"leaveFree,omitempty"`
+}
+
+// DefaultStorageLeaveFree is left free by StorageFillCommand by default, low
+// enough for apps to hit their low storage paths but enough for the system
+// to keep running
+const DefaultStorageLeaveFree = "100M"
+
+// StorageInfoResult lists a device's storage volumes
+type StorageInfoResult struct {
+	Volumes []devices.StorageVolume `json:"volumes"`
+}
+
+// byteSizeUnits are the suffixes ParseByteSize accepts, in powers of 1024
+var byteSizeUnits = map[string]int64{
+	"":  1,
+	"B": 1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+// ParseByteSize parses a size such as "512", "100M" or "1.5G"
+func ParseByteSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+
+	unit := ""
+	if s != "" && strings.Contains("KMGT", s[len(s)-1:]) {
+		unit = s[len(s)-1:]
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s', expected e.g. 500M or 1G", value)
+	}
+	return int64(n * float64(byteSizeUnits[unit])), nil
+}
+
+// findStorageController finds the device and checks its storage can be managed
+func findStorageController(deviceID string) (devices.ControllableDevice, devices.StorageController, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	storage, ok := targetDevice.(devices.StorageController)
+	if !ok {
+		return nil, nil, fmt.Errorf("storage management is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, storage, nil
+}
+
+// StorageInfoCommand reports the total, used and free space of a device's
+// volumes
+func StorageInfoCommand(req StorageRequest) *CommandResponse {
+	targetDevice, storage, err := findStorageController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	volumes, err := storage.StorageInfo()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to get storage of device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(StorageInfoResult{Volumes: volumes})
+}
+
+// StorageFillCommand writes a filler file so only LeaveFree bytes remain
+// free, to simulate low storage
+func StorageFillCommand(req StorageFillRequest) *CommandResponse {
+	if req.LeaveFree == "" {
+		req.LeaveFree = DefaultStorageLeaveFree
+	}
+
+	leaveFree, err := ParseByteSize(req.LeaveFree)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	targetDevice, storage, err := findStorageController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	result, err := storage.FillStorage(leaveFree)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to fill storage of device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(result)
+}
+
+// StorageFreeCommand removes the filler file written by StorageFillCommand
+func StorageFreeCommand(req StorageRequest) *CommandResponse {
+	targetDevice, storage, err := findStorageController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := storage.FreeStorage(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to free storage of device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Removed the storage filler from device %s", targetDevice.ID()),
+	})
+}
@@ -0,0 +1,25 @@
+package commands
+
+import "testing"
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{
+		"512":  512,
+		"100M": 100 << 20,
+		"1G":   1 << 30,
+		"1.5g": 3 << 29,
+		"2GB":  2 << 30,
+		"4KiB": 4 << 10,
+	}
+	for value, want := range cases {
+		if got, err := ParseByteSize(value); err != nil || got != want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", value, got, err, want)
+		}
+	}
+
+	for _, value := range []string{"", "M", "-1G", "lots"} {
+		if _, err := ParseByteSize(value); err == nil {
+			t.Errorf("expected an error for %q", value)
+		}
+	}
+}
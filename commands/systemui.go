@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// SystemUIRequest represents the parameters for opening or closing system UI
+type SystemUIRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// OpenSettingsCommand opens the Settings app
+func OpenSettingsCommand(req SystemUIRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	opener, ok := targetDevice.(devices.SettingsOpener)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("opening Settings is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType()))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	if err := opener.OpenSettings(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to open Settings on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Opened Settings on device %s", targetDevice.ID()),
+	})
+}
+
+// ControlCenterCommand opens or closes Control Center on iOS, or quick
+// settings on Android
+func ControlCenterCommand(req SystemUIRequest, open bool) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	controller, ok := targetDevice.(devices.ControlCenterController)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("control center is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType()))
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err))
+	}
+
+	if open {
+		if err := controller.OpenControlCenter(); err != nil {
+			return NewErrorResponse(fmt.Errorf("failed to open control center on device %s: %w", targetDevice.ID(), err))
+		}
+		return NewSuccessResponse(MessageResult{
+			Message: fmt.Sprintf("Opened control center on device %s", targetDevice.ID()),
+		})
+	}
+
+	if err := controller.CloseControlCenter(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to close control center on device %s: %w", targetDevice.ID(), err))
+	}
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Closed control center on device %s", targetDevice.ID()),
+	})
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// tapShowOverlayDuration is how long the pointer location overlay stays on
+// after a tap made with --show, so the touch can be seen on the device
+const tapShowOverlayDuration = time.Second
+
+// TapResult is the result of a tap, with the annotated screenshot of a tap
+// made with Show
+type TapResult struct {
+	Message    string `json:"message"`
+	Screenshot string `json:"screenshot,omitempty"`
+}
+
+// saveTapScreenshot takes a screenshot with the tap point marked on it, saved
+// to the artifacts directory or the current directory, and returns its path
+func saveTapScreenshot(device devices.ControllableDevice, x, y int) (string, error) {
+	imageBytes, err := device.TakeScreenshot()
+	if err != nil {
+		return "", fmt.Errorf("error taking screenshot: %v", err)
+	}
+
+	scale := 1
+	info, err := device.Info()
+	if err != nil {
+		return "", fmt.Errorf("failed to get device info: %v", err)
+	}
+	if info.ScreenSize != nil && info.ScreenSize.Scale > 0 {
+		scale = info.ScreenSize.Scale
+	}
+
+	imageBytes, err = utils.MarkPoint(imageBytes, x*scale, y*scale)
+	if err != nil {
+		return "", fmt.Errorf("error marking tap point: %v", err)
+	}
+
+	path, err := ArtifactPath(device.ID(), "tap", "png")
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		timestamp := time.Now().Format("20060102150405")
+		safeDeviceID := strings.ReplaceAll(device.ID(), ":", "_")
+		path, err = filepath.Abs(fmt.Sprintf("./tap-%s-%s.png", safeDeviceID, timestamp))
+		if err != nil {
+			return "", fmt.Errorf("invalid output path: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(path, imageBytes, 0o600); err != nil {
+		return "", fmt.Errorf("failed to write file: %v", err)
+	}
+	return path, nil
+}
+
+// showPointerLocation turns on the device's pointer location overlay, if it
+// has one, and returns a function that turns it back off a moment after the
+// tap. Failing to turn it on is not an error, the screenshot still shows the tap.
+func showPointerLocation(device devices.ControllableDevice) func() {
+	controller, ok := device.(devices.PointerLocationController)
+	if !ok {
+		return func() {}
+	}
+
+	enabled, err := controller.PointerLocation()
+	if err != nil || enabled {
+		return func() {}
+	}
+
+	if err := controller.SetPointerLocation(true); err != nil {
+		utils.Verbose("failed to show pointer location on device %s: %v", device.ID(), err)
+		return func() {}
+	}
+
+	return func() {
+		time.Sleep(tapShowOverlayDuration)
+		if err := controller.SetPointerLocation(false); err != nil {
+			utils.Verbose("failed to hide pointer location on device %s: %v", device.ID(), err)
+		}
+	}
+}
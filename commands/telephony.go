@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// CallRequest represents the parameters for simulating a call
+type CallRequest struct {
+	DeviceID string `json:"deviceId"`
+	Number   string `json:"number"`
+}
+
+// SMSRequest represents the parameters for simulating a text message
+type SMSRequest struct {
+	DeviceID string `json:"deviceId"`
+	Number   string `json:"number"`
+	Text     string `json:"text"`
+}
+
+// findTelephonySimulator finds the device and checks it can simulate calls
+// and text messages
+func findTelephonySimulator(deviceID string) (devices.ControllableDevice, devices.TelephonySimulator, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	telephony, ok := targetDevice.(devices.TelephonySimulator)
+	if !ok {
+		return nil, nil, fmt.Errorf("simulating calls and text messages is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, telephony, nil
+}
+
+// CallIncomingCommand makes the device ring with a call from a number
+func CallIncomingCommand(req CallRequest) *CommandResponse {
+	if req.Number == "" {
+		return NewErrorResponse(fmt.Errorf("number is required"))
+	}
+
+	targetDevice, telephony, err := findTelephonySimulator(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := telephony.IncomingCall(req.Number); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to call device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Calling device %s from %s", targetDevice.ID(), req.Number),
+	})
+}
+
+// CallEndCommand hangs up a simulated call
+func CallEndCommand(req CallRequest) *CommandResponse {
+	if req.Number == "" {
+		return NewErrorResponse(fmt.Errorf("number is required"))
+	}
+
+	targetDevice, telephony, err := findTelephonySimulator(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := telephony.EndCall(req.Number); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to end call on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Ended call from %s on device %s", req.Number, targetDevice.ID()),
+	})
+}
+
+// SMSSendCommand delivers a text message to the device
+func SMSSendCommand(req SMSRequest) *CommandResponse {
+	if req.Number == "" {
+		return NewErrorResponse(fmt.Errorf("number is required"))
+	}
+	if req.Text == "" {
+		return NewErrorResponse(fmt.Errorf("text is required"))
+	}
+
+	targetDevice, telephony, err := findTelephonySimulator(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := telephony.ReceiveSMS(req.Number, req.Text); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to send text message to device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Sent text message from %s to device %s", req.Number, targetDevice.ID()),
+	})
+}
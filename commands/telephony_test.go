@@ -0,0 +1,32 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestTelephonyCommands(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false)
+
+	if response := CallIncomingCommand(CallRequest{DeviceID: "fake-android-1", Number: "5551234"}); response.Status != "ok" {
+		t.Fatalf("call failed: %s", response.Error)
+	}
+	if response := CallEndCommand(CallRequest{DeviceID: "fake-android-1", Number: "5551234"}); response.Status != "ok" {
+		t.Fatalf("end call failed: %s", response.Error)
+	}
+	if response := SMSSendCommand(SMSRequest{DeviceID: "fake-android-1", Number: "5551234", Text: "code 123456"}); response.Status != "ok" {
+		t.Fatalf("sms failed: %s", response.Error)
+	}
+	if response := SMSSendCommand(SMSRequest{DeviceID: "fake-android-1", Number: "5551234"}); response.Status != "error" {
+		t.Errorf("expected a text message without text to fail")
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	if !reflect.DeepEqual(actions, []string{"call 5551234", "endcall 5551234", "sms 5551234 code 123456"}) {
+		t.Errorf("unexpected actions: %v", actions)
+	}
+}
@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// TestRunRequest represents the parameters for running instrumentation or
+// XCTest UI tests
+type TestRunRequest struct {
+	DeviceID    string            `json:"deviceId"`
+	AppPath     string            `json:"appPath,omitempty"`     // the app under test, installed first when given
+	TestAppPath string            `json:"testAppPath,omitempty"` // the test apk or XCTest runner app, installed first when given
+	XCTestRun   string            `json:"xctestrun,omitempty"`   // an .xctestrun file, instead of a runner app
+	Runner      string            `json:"runner,omitempty"`      // package/runner, read from the test apk when empty
+	Class       string            `json:"class,omitempty"`       // classes or Class#method to run, comma separated
+	Args        map[string]string `json:"args,omitempty"`        // further runner arguments
+	JUnitPath   string            `json:"junitPath,omitempty"`   // where to write a JUnit XML report
+
+	// OnEvent, when set, is called as each test starts and finishes
+	OnEvent func(event devices.TestEvent) `json:"-"`
+}
+
+// TestRunResult is the outcome of a test run, and where its report was written
+type TestRunResult struct {
+	*devices.TestResults
+	JUnitPath string `json:"junitPath,omitempty"`
+}
+
+// isXCTestRun tells whether a request runs XCTest UI tests rather than
+// instrumentation tests
+func (req TestRunRequest) isXCTestRun() bool {
+	return req.XCTestRun != "" || filepath.Ext(strings.TrimRight(req.TestAppPath, "/")) == ".app"
+}
+
+// TestRunCommand runs instrumentation tests on Android, installing the app
+// and test apks first, or XCTest UI tests on iOS from an .xctestrun or a
+// runner app. The response is an error carrying the TestRunResult when a
+// test failed or the run ended early.
+func TestRunCommand(req TestRunRequest) *CommandResponse {
+	xctest := req.isXCTestRun()
+	if xctest && (req.Runner != "" || len(req.Args) > 0) {
+		return NewErrorResponse(fmt.Errorf("an instrumentation runner and arguments only apply to Android instrumentation tests, not to XCTest UI tests"))
+	}
+
+	runner := req.Runner
+	if runner == "" && !xctest {
+		if req.TestAppPath == "" {
+			return NewErrorResponse(fmt.Errorf("a test apk or an instrumentation runner is required"))
+		}
+
+		var err error
+		runner, err = utils.ParseApkInstrumentation(req.TestAppPath)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	var run func() (*devices.TestResults, error)
+	if xctest {
+		xctestRunner, ok := targetDevice.(devices.XCTestRunner)
+		if !ok {
+			return NewErrorResponse(fmt.Errorf("XCTest UI tests are not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType()))
+		}
+
+		config := devices.XCTestConfig{
+			XCTestRun: req.XCTestRun,
+			RunnerApp: req.TestAppPath,
+			AppPath:   req.AppPath,
+			OnEvent:   req.OnEvent,
+		}
+		if req.Class != "" {
+			config.OnlyTesting = strings.Split(req.Class, ",")
+		}
+		run = func() (*devices.TestResults, error) { return xctestRunner.RunXCTest(config) }
+	} else {
+		instrumentation, ok := targetDevice.(devices.InstrumentationRunner)
+		if !ok {
+			return NewErrorResponse(fmt.Errorf("instrumentation tests are not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType()))
+		}
+
+		config := devices.InstrumentationConfig{
+			Runner:  runner,
+			Class:   req.Class,
+			Args:    req.Args,
+			OnEvent: req.OnEvent,
+		}
+		run = func() (*devices.TestResults, error) { return instrumentation.RunInstrumentation(config) }
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	defer unlock()
+
+	// XCTest runs install their apps themselves
+	if !xctest {
+		for _, path := range []string{req.AppPath, req.TestAppPath} {
+			if path == "" {
+				continue
+			}
+			if err := installTestApk(targetDevice, path); err != nil {
+				return NewErrorResponse(fmt.Errorf("failed to install %s on device %s: %w", filepath.Base(path), targetDevice.ID(), err))
+			}
+		}
+	}
+
+	testResults, err := run()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	result := TestRunResult{TestResults: testResults}
+	if req.JUnitPath != "" {
+		result.JUnitPath, err = writeJUnitReport(testResults, req.JUnitPath)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+	}
+
+	switch {
+	case !testResults.Complete:
+		return &CommandResponse{Status: "error", Error: testResults.Message, Data: result}
+	case testResults.Failed > 0:
+		message := fmt.Sprintf("%d of %d tests failed", testResults.Failed, testResults.Total)
+		return &CommandResponse{Status: "error", Error: message, Data: result}
+	}
+	return NewSuccessResponse(result)
+}
+
+// installTestApk installs an apk, allowing test-only apks
+func installTestApk(targetDevice devices.ControllableDevice, path string) error {
+	if installer, ok := targetDevice.(devices.ProgressInstaller); ok {
+		return installer.InstallAppWithProgress(path, devices.InstallConfig{AllowTest: true})
+	}
+	return targetDevice.InstallApp(path)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// junitSeconds formats milliseconds as JUnit's seconds
+func junitSeconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
+
+// junitReport builds a JUnit report with a suite per test class. A run that
+// ended early is an error of the report.
+func junitReport(result *devices.TestResults) junitTestSuites {
+	report := junitTestSuites{
+		Name:     result.Runner,
+		Tests:    result.Total,
+		Failures: result.Failed,
+		Skipped:  result.Skipped,
+		Time:     junitSeconds(result.Duration),
+	}
+	if !result.Complete {
+		report.Errors = 1
+	}
+
+	suites := make(map[string]int)
+	durations := make(map[string]int64)
+	for _, test := range result.Tests {
+		index, ok := suites[test.Class]
+		if !ok {
+			index = len(report.Suites)
+			suites[test.Class] = index
+			report.Suites = append(report.Suites, junitTestSuite{Name: test.Class})
+		}
+		suite := &report.Suites[index]
+
+		testCase := junitTestCase{ClassName: test.Class, Name: test.Name, Time: junitSeconds(test.Duration)}
+		switch test.Status {
+		case devices.TestStatusFailed:
+			message, _, _ := strings.Cut(test.Failure, "\n")
+			testCase.Failure = &junitFailure{Message: message, Body: test.Failure}
+			suite.Failures++
+		case devices.TestStatusIgnored, devices.TestStatusSkipped:
+			testCase.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+		durations[test.Class] += test.Duration
+	}
+	for i := range report.Suites {
+		report.Suites[i].Time = junitSeconds(durations[report.Suites[i].Name])
+	}
+
+	return report
+}
+
+// writeJUnitReport writes a JUnit XML report of a run and returns its
+// absolute path
+func writeJUnitReport(result *devices.TestResults, output string) (string, error) {
+	path, err := filepath.Abs(output)
+	if err != nil {
+		return "", fmt.Errorf("invalid output path: %v", err)
+	}
+
+	data, err := xml.MarshalIndent(junitReport(result), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JUnit report: %v", err)
+	}
+
+	if err := os.WriteFile(path, append([]byte(xml.Header), append(data, '\n')...), 0o644); err != nil {
+		return "", fmt.Errorf("error writing file: %v", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func TestWriteJUnitReport(t *testing.T) {
+	result := &devices.TestResults{
+		Runner: "com.example.test/androidx.test.runner.AndroidJUnitRunner",
+		Tests: []devices.TestCaseResult{
+			{Class: "com.example.FooTest", Name: "testPasses", Status: "passed", Duration: 120},
+			{Class: "com.example.FooTest", Name: "testFails", Status: "failed", Duration: 80, Failure: "java.lang.AssertionError: boom\n\tat com.example.FooTest.testFails(FooTest.java:21)"},
+			{Class: "com.example.BarTest", Name: "testIgnored", Status: "ignored"},
+		},
+		Total:    3,
+		Passed:   1,
+		Failed:   1,
+		Skipped:  1,
+		Duration: 1500,
+		Complete: true,
+	}
+
+	path, err := writeJUnitReport(result, filepath.Join(t.TempDir(), "report.xml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if !strings.HasPrefix(string(data), xml.Header) {
+		t.Error("expected an XML header")
+	}
+
+	var report junitTestSuites
+	if err := xml.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if report.Tests != 3 || report.Failures != 1 || report.Skipped != 1 || report.Errors != 0 || report.Time != "1.500" {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if len(report.Suites) != 2 || report.Suites[0].Name != "com.example.FooTest" || report.Suites[0].Tests != 2 || report.Suites[0].Time != "0.200" {
+		t.Fatalf("unexpected suites: %+v", report.Suites)
+	}
+
+	failed := report.Suites[0].Cases[1]
+	if failed.Failure == nil || failed.Failure.Message != "java.lang.AssertionError: boom" || !strings.Contains(failed.Failure.Body, "FooTest.java:21") {
+		t.Errorf("unexpected failure: %+v", failed.Failure)
+	}
+	if report.Suites[1].Cases[0].Skipped == nil {
+		t.Error("expected the ignored test to be skipped")
+	}
+}
+
+func TestTestRunCommandRequiresRunner(t *testing.T) {
+	response := TestRunCommand(TestRunRequest{DeviceID: "fake-android-1"})
+	if response.Status != "error" || !strings.Contains(response.Error, "instrumentation runner is required") {
+		t.Errorf("unexpected response: %s %s", response.Status, response.Error)
+	}
+}
+
+func TestTestRunCommandUnsupportedDevice(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	response := TestRunCommand(TestRunRequest{DeviceID: "fake-android-1", Runner: "com.example.test/androidx.test.runner.AndroidJUnitRunner"})
+	if response.Status != "error" || !strings.Contains(response.Error, "not supported") {
+		t.Errorf("unexpected response: %s %s", response.Status, response.Error)
+	}
+}
+
+func TestTestRunCommandXCTestUnsupportedDevice(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	response := TestRunCommand(TestRunRequest{DeviceID: "fake-android-1", TestAppPath: "MyAppUITests-Runner.app"})
+	if response.Status != "error" || !strings.Contains(response.Error, "XCTest UI tests are not supported") {
+		t.Errorf("unexpected response: %s %s", response.Status, response.Error)
+	}
+}
+
+func TestTestRunCommandXCTestRejectsInstrumentationOptions(t *testing.T) {
+	response := TestRunCommand(TestRunRequest{DeviceID: "fake-ios-1", XCTestRun: "MyApp.xctestrun", Args: map[string]string{"size": "small"}})
+	if response.Status != "error" || !strings.Contains(response.Error, "only apply to Android instrumentation tests") {
+		t.Errorf("unexpected response: %s %s", response.Status, response.Error)
+	}
+}
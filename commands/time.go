@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// timeLayouts are the layouts device time can be given in. Times without a
+// zone are in the host's local time.
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDeviceTime parses a time such as "2025-01-01T09:41:00"
+func ParseDeviceTime(value string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time '%s', expected e.g. 2025-01-01T09:41:00 or 2025-01-01T09:41:00Z", value)
+}
+
+// TimeSetRequest represents the parameters for setting a device's time
+type TimeSetRequest struct {
+	DeviceID string `json:"deviceId"`
+	Time     string `json:"time"`
+}
+
+// TimeSyncRequest represents the parameters for syncing a device's time
+type TimeSyncRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// TimeSetResult describes a changed device time
+type TimeSetResult struct {
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+
+	// Scope is "system" when the device clock changed, or "statusBar" when
+	// only the time shown in the status bar did
+	Scope string `json:"scope"`
+}
+
+// findClockController finds the device and checks its time can be changed
+func findClockController(deviceID string) (devices.ControllableDevice, devices.ClockController, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	clock, ok := targetDevice.(devices.ClockController)
+	if !ok {
+		return nil, nil, fmt.Errorf("changing the time is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, clock, nil
+}
+
+// TimeSetCommand sets a device's time. iOS simulators run on the host's
+// clock, so only their status bar time changes.
+func TimeSetCommand(req TimeSetRequest) *CommandResponse {
+	if req.Time == "" {
+		return NewErrorResponse(fmt.Errorf("time is required"))
+	}
+
+	t, err := ParseDeviceTime(req.Time)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	targetDevice, clock, err := findClockController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	scope, err := clock.SetTime(t)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to set time on device %s: %w", targetDevice.ID(), err))
+	}
+
+	message := fmt.Sprintf("Set time on device %s to %s", targetDevice.ID(), t.Format(time.RFC3339))
+	if scope == devices.ClockScopeStatusBar {
+		message = fmt.Sprintf("Set status bar time on device %s to %s, the device clock follows the host", targetDevice.ID(), t.Format(time.RFC3339))
+	}
+
+	return NewSuccessResponse(TimeSetResult{
+		Message: message,
+		Time:    t,
+		Scope:   scope,
+	})
+}
+
+// TimeSyncCommand returns a device to the host's time
+func TimeSyncCommand(req TimeSyncRequest) *CommandResponse {
+	targetDevice, clock, err := findClockController(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := clock.SyncTime(); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to sync time on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Synced time on device %s with the host", targetDevice.ID()),
+	})
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestParseDeviceTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"2025-01-01T09:41:00Z", time.Date(2025, 1, 1, 9, 41, 0, 0, time.UTC)},
+		{"2025-01-01T09:41:00+02:00", time.Date(2025, 1, 1, 7, 41, 0, 0, time.UTC)},
+		{"2025-01-01T09:41:00", time.Date(2025, 1, 1, 9, 41, 0, 0, time.Local)},
+		{"2025-01-01 09:41", time.Date(2025, 1, 1, 9, 41, 0, 0, time.Local)},
+		{"2025-01-01", time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDeviceTime(tt.value)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.value, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	if _, err := ParseDeviceTime("tomorrow"); err == nil {
+		t.Error("expected an invalid time to fail")
+	}
+}
+
+func TestTimeCommands(t *testing.T) {
+	useFakeDevices(t, 1)
+	_, _ = devices.GetAllControllableDevices(false) // creates the fake devices
+
+	response := TimeSetCommand(TimeSetRequest{DeviceID: "fake-android-1", Time: "2025-01-01T09:41:00Z"})
+	if response.Status != "ok" {
+		t.Fatalf("time set failed: %s", response.Error)
+	}
+	if result := response.Data.(TimeSetResult); result.Scope != devices.ClockScopeSystem {
+		t.Errorf("unexpected scope %q", result.Scope)
+	}
+
+	if response := TimeSyncCommand(TimeSyncRequest{DeviceID: "fake-android-1"}); response.Status != "ok" {
+		t.Fatalf("time sync failed: %s", response.Error)
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	if !reflect.DeepEqual(actions, []string{"settime 2025-01-01T09:41:00Z", "synctime"}) {
+		t.Errorf("unexpected actions %v", actions)
+	}
+
+	if response := TimeSetCommand(TimeSetRequest{DeviceID: "fake-android-1", Time: "soon"}); response.Status != "error" {
+		t.Error("expected an invalid time to fail")
+	}
+}
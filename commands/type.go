@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// TypeRequest represents the parameters for an interactive typing session
+type TypeRequest struct {
+	DeviceID string `json:"deviceId"`
+
+	// EnsureUnlocked wakes the device and dismisses its keyguard first
+	EnsureUnlocked bool `json:"ensureUnlocked,omitempty"`
+}
+
+// KeyEvent is a keystroke typed on the local keyboard: either text to type,
+// or a named key such as "backspace" or "up" to press
+type KeyEvent struct {
+	Text string
+	Key  string
+}
+
+// TypeSession forwards keystrokes to a device as they are typed. The device
+// is found and its agent started once, so each keystroke costs only the
+// input itself.
+type TypeSession struct {
+	device  devices.ControllableDevice
+	typed   int
+	pressed int
+}
+
+// TypeResult summarizes a finished typing session
+type TypeResult struct {
+	DeviceID   string `json:"deviceId"`
+	Characters int    `json:"characters"`
+	Keys       int    `json:"keys"`
+}
+
+// StartTypeSession finds the device and prepares it to receive keystrokes
+func StartTypeSession(req TypeRequest) (*TypeSession, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	unlock, err := LockDevice(targetDevice.ID())
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: GetShutdownHook(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start agent on device %s: %v", targetDevice.ID(), err)
+	}
+
+	if req.EnsureUnlocked {
+		if err := ensureUnlocked(targetDevice); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TypeSession{device: targetDevice}, nil
+}
+
+// DeviceID returns the ID of the device keystrokes are sent to
+func (s *TypeSession) DeviceID() string {
+	return s.device.ID()
+}
+
+// Send types a keystroke's text or presses its key on the device
+func (s *TypeSession) Send(event KeyEvent) error {
+	unlock, err := LockDevice(s.device.ID())
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	defer InvalidateDumpCache(s.device.ID())
+
+	if event.Key != "" {
+		if err := s.device.PressKeys([]devices.KeyCombo{{Key: event.Key}}); err != nil {
+			return fmt.Errorf("failed to press %s on device %s: %v", event.Key, s.device.ID(), err)
+		}
+		s.pressed++
+		return nil
+	}
+
+	if event.Text == "" {
+		return nil
+	}
+	if err := s.device.SendKeys(event.Text); err != nil {
+		return fmt.Errorf("failed to send keys to device %s: %v", s.device.ID(), err)
+	}
+	s.typed += len([]rune(event.Text))
+	return nil
+}
+
+// Result summarizes what the session has typed so far
+func (s *TypeSession) Result() TypeResult {
+	return TypeResult{
+		DeviceID:   s.device.ID(),
+		Characters: s.typed,
+		Keys:       s.pressed,
+	}
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestTypeSession(t *testing.T) {
+	useFakeDevices(t, 1)
+
+	session, err := StartTypeSession(TypeRequest{DeviceID: "fake-android-1"})
+	if err != nil {
+		t.Fatalf("failed to start typing session: %v", err)
+	}
+
+	events := []KeyEvent{{Text: "héllo"}, {Key: "backspace"}, {Text: "o"}, {Key: "enter"}, {}}
+	for _, event := range events {
+		if err := session.Send(event); err != nil {
+			t.Fatalf("failed to send %+v: %v", event, err)
+		}
+	}
+
+	actions := fake.Get("fake-android-1").Actions()
+	expected := []string{"type héllo", "keys backspace", "type o", "keys enter"}
+	if !reflect.DeepEqual(actions, expected) {
+		t.Errorf("expected %v, got %v", expected, actions)
+	}
+
+	result := session.Result()
+	if result.DeviceID != "fake-android-1" || result.Characters != 6 || result.Keys != 2 {
+		t.Errorf("unexpected result %+v", result)
+	}
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// UsersRequest represents the parameters for listing a device's users
+type UsersRequest struct {
+	DeviceID string `json:"deviceId"`
+}
+
+// CreateUserRequest represents the parameters for creating a user. With
+// ProfileOf a managed (work) profile of that user is created instead.
+type CreateUserRequest struct {
+	DeviceID  string `json:"deviceId"`
+	Name      string `json:"name"`
+	ProfileOf *int   `json:"profileOf,omitempty"`
+	Guest     bool   `json:"guest,omitempty"`
+}
+
+// RemoveUserRequest represents the parameters for removing a user
+type RemoveUserRequest struct {
+	DeviceID string `json:"deviceId"`
+	UserID   int    `json:"userId"`
+}
+
+// UsersResult lists a device's users and profiles
+type UsersResult struct {
+	Users []devices.DeviceUser `json:"users"`
+}
+
+// findUserManager finds the device and checks it has users to manage
+func findUserManager(deviceID string) (devices.ControllableDevice, devices.UserManager, error) {
+	targetDevice, err := FindDeviceOrAutoSelect(deviceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error finding device: %w", err)
+	}
+
+	users, ok := targetDevice.(devices.UserManager)
+	if !ok {
+		return nil, nil, fmt.Errorf("multiple users are not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType())
+	}
+
+	return targetDevice, users, nil
+}
+
+// userManagerFor checks that a device can act for the given user, which
+// needs no support when no user is given
+func userManagerFor(device devices.ControllableDevice, user string) (devices.UserManager, error) {
+	if user == "" {
+		return nil, nil
+	}
+
+	if err := devices.ValidateAndroidUser(user); err != nil {
+		return nil, err
+	}
+
+	users, ok := device.(devices.UserManager)
+	if !ok {
+		return nil, fmt.Errorf("users are not supported on %s %s devices", device.Platform(), device.DeviceType())
+	}
+	return users, nil
+}
+
+// ListUsersCommand lists the users and profiles of a device
+func ListUsersCommand(req UsersRequest) *CommandResponse {
+	targetDevice, users, err := findUserManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	list, err := users.ListUsers()
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to list users on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(UsersResult{Users: list})
+}
+
+// CreateUserCommand creates a secondary user, a guest or a managed profile
+func CreateUserCommand(req CreateUserRequest) *CommandResponse {
+	if req.Name == "" {
+		return NewErrorResponse(fmt.Errorf("name is required"))
+	}
+	if req.ProfileOf != nil && req.Guest {
+		return NewErrorResponse(fmt.Errorf("profileOf and guest cannot be used together"))
+	}
+
+	targetDevice, users, err := findUserManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	user, err := users.CreateUser(req.Name, devices.CreateUserOptions{
+		ProfileOf: req.ProfileOf,
+		Guest:     req.Guest,
+	})
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to create user on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(user)
+}
+
+// RemoveUserCommand removes a user or profile with its apps and data
+func RemoveUserCommand(req RemoveUserRequest) *CommandResponse {
+	targetDevice, users, err := findUserManager(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := users.RemoveUser(req.UserID); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to remove user on device %s: %w", targetDevice.ID(), err))
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Removed user %d from device %s", req.UserID, targetDevice.ID()),
+	})
+}
@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// WindowRequest represents the parameters for showing or hiding the host
+// window of an emulator or simulator
+type WindowRequest struct {
+	DeviceID string `json:"deviceId"`
+	Visible  bool   `json:"visible"`
+}
+
+// WindowCommand shows or hides the window of a running emulator or
+// simulator, e.g. to look at a headless CI device without restarting it
+func WindowCommand(req WindowRequest) *CommandResponse {
+	targetDevice, err := FindDeviceOrAutoSelect(req.DeviceID)
+	if err != nil {
+		return NewErrorResponse(fmt.Errorf("error finding device: %w", err))
+	}
+
+	controller, ok := targetDevice.(devices.WindowController)
+	if !ok {
+		return NewErrorResponse(fmt.Errorf("window control is not supported on %s %s devices", targetDevice.Platform(), targetDevice.DeviceType()))
+	}
+
+	if err := controller.SetWindowVisible(req.Visible); err != nil {
+		return NewErrorResponse(fmt.Errorf("failed to change the window of device %s: %v", targetDevice.ID(), err))
+	}
+
+	state := "hidden"
+	if req.Visible {
+		state = "shown"
+	}
+
+	return NewSuccessResponse(MessageResult{
+		Message: fmt.Sprintf("Window of device %s is %s", targetDevice.ID(), state),
+	})
+}
@@ -52,8 +52,9 @@ func IsChild() bool {
 	return os.Getenv(DaemonEnvVar) == "1"
 }
 
-// KillServer connects to the server and sends a shutdown command via JSON-RPC
-func KillServer(addr string) error {
+// serverURL turns a listen address, such as "12000", ":12000" or
+// "localhost:12000", into the server's base URL
+func serverURL(addr string) string {
 	// normalize address to match server's format
 	// if no colon, assume it's a bare port number
 	if !strings.Contains(addr, ":") {
@@ -69,7 +70,12 @@ func KillServer(addr string) error {
 	}
 
 	// prepend http:// scheme
-	addr = "http://" + addr
+	return "http://" + addr
+}
+
+// KillServer connects to the server and sends a shutdown command via JSON-RPC
+func KillServer(addr string) error {
+	addr = serverURL(addr)
 
 	// create JSON-RPC request
 	reqBody := server.JSONRPCRequest{
@@ -107,3 +113,52 @@ func KillServer(addr string) error {
 
 	return resp.Body.Close()
 }
+
+// ServerStatus is what 'server status' reports about a running server
+type ServerStatus struct {
+	Live   bool                             `json:"live"`
+	Ready  bool                             `json:"ready"`
+	Checks map[string]server.ReadinessCheck `json:"checks,omitempty"`
+}
+
+// GetServerStatus queries the server's /healthz and /readyz endpoints. A
+// server that doesn't answer is an error; one that answers but isn't ready
+// is not.
+func GetServerStatus(addr string) (*ServerStatus, error) {
+	addr = serverURL(addr)
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	resp, err := client.Get(addr + "/healthz")
+	if err != nil {
+		if strings.Contains(err.Error(), "connection refused") {
+			return nil, fmt.Errorf("server is not running on %s", addr)
+		}
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned error: %s", resp.Status)
+	}
+
+	status := &ServerStatus{Live: true}
+
+	resp, err = client.Get(addr + "/readyz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check readiness: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusServiceUnavailable {
+		return nil, fmt.Errorf("server returned error for readiness: %s", resp.Status)
+	}
+
+	var readiness server.ReadinessStatus
+	if err := json.NewDecoder(resp.Body).Decode(&readiness); err != nil {
+		return nil, fmt.Errorf("failed to decode readiness: %w", err)
+	}
+
+	status.Ready = readiness.Ready
+	status.Checks = readiness.Checks
+	return status, nil
+}
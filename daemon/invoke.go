@@ -0,0 +1,161 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/server"
+)
+
+// InvokeMethod is the daemon-only JSON-RPC method the CLI uses to run a command
+// inside the daemon process. It returns the complete CommandResponse, so the
+// CLI output is identical whether or not a daemon is running.
+const InvokeMethod = "daemon.invoke"
+
+// InvokeParams represents the parameters for the daemon.invoke request
+type InvokeParams struct {
+	Command string          `json:"command"`
+	Request json.RawMessage `json:"request"`
+}
+
+type commandFunc func(request json.RawMessage) (*commands.CommandResponse, error)
+
+// command adapts a typed command function to accept a JSON-encoded request
+func command[T any](fn func(T) *commands.CommandResponse) commandFunc {
+	return func(request json.RawMessage) (*commands.CommandResponse, error) {
+		var req T
+		if err := json.Unmarshal(request, &req); err != nil {
+			return nil, fmt.Errorf("invalid request: %w", err)
+		}
+		return fn(req), nil
+	}
+}
+
+// delegatedCommands are the latency-sensitive commands the CLI forwards to a
+// running daemon, where device connections and agents are already warm.
+var delegatedCommands = map[string]commandFunc{
+	"tap":               command(commands.TapCommand),
+	"longpress":         command(commands.LongPressCommand),
+	"swipe":             command(commands.SwipeCommand),
+	"text":              command(commands.TextCommand),
+	"button":            command(commands.ButtonCommand),
+	"keys":              command(commands.KeysCommand),
+	"info":              command(commands.InfoCommand),
+	"props":             command(commands.PropertiesCommand),
+	"orientation.get":   command(commands.OrientationGetCommand),
+	"orientation.set":   command(commands.OrientationSetCommand),
+	"dump.ui":           command(commands.DumpUICommand),
+	"snapshot":          command(commands.SnapshotCommand),
+	"url":               command(commands.URLCommand),
+	"apps.launch":       command(commands.LaunchAppCommand),
+	"apps.terminate":    command(commands.TerminateAppCommand),
+	"apps.terminateAll": command(commands.TerminateAllAppsCommand),
+	"apps.state":        command(commands.AppStateCommand),
+	"apps.foreground":   command(commands.ForegroundAppCommand),
+	"apps.running":      command(commands.RunningAppsCommand),
+	"forward.list":      command(commands.ForwardListCommand),
+	"reverse":           command(commands.ReverseCommand),
+	"reverse.list":      command(commands.ReverseListCommand),
+	"reverse.remove":    command(commands.ReverseRemoveCommand),
+	"devices":           command(listDevices),
+	"device.labels":     command(commands.DeviceLabelsCommand),
+}
+
+// readOnlyCommands only read from their device. The others may change what
+// is on screen, so the server's cached screenshots and UI dumps of their
+// device are dropped once they have run.
+var readOnlyCommands = map[string]bool{
+	"info":            true,
+	"props":           true,
+	"orientation.get": true,
+	"dump.ui":         true,
+	"snapshot":        true,
+	"apps.state":      true,
+	"apps.foreground": true,
+	"apps.running":    true,
+	"forward.list":    true,
+	"reverse.list":    true,
+	"devices":         true,
+	"device.labels":   true,
+}
+
+// listDevices lists the devices of the daemon or server, without fleet devices
+func listDevices(opts devices.DeviceListOptions) *commands.CommandResponse {
+	return commands.DevicesCommand(opts, "")
+}
+
+// RegisterInvokeMethod adds daemon.invoke to the server's method registry. The
+// daemon registers it, as does 'server start' so CLIs run with --remote can
+// invoke commands on it.
+func RegisterInvokeMethod() {
+	server.RegisterMethod(InvokeMethod, handleInvoke)
+}
+
+func handleInvoke(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: command, request")
+	}
+
+	var invokeParams InvokeParams
+	if err := json.Unmarshal(params, &invokeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: command, request", err)
+	}
+
+	fn, ok := delegatedCommands[invokeParams.Command]
+	if !ok {
+		return nil, fmt.Errorf("command '%s' cannot be delegated to the daemon", invokeParams.Command)
+	}
+
+	if !readOnlyCommands[invokeParams.Command] {
+		defer func() {
+			var target struct {
+				DeviceID string `json:"deviceId"`
+			}
+			_ = json.Unmarshal(invokeParams.Request, &target)
+			server.InvalidateScreenshotCache(target.DeviceID)
+			commands.InvalidateDumpCache(target.DeviceID)
+		}()
+	}
+
+	return fn(invokeParams.Request)
+}
+
+// CanDelegate returns true if the daemon knows how to run the given command
+func CanDelegate(name string) bool {
+	_, ok := delegatedCommands[name]
+	return ok
+}
+
+// Invoke runs a command in the daemon listening on socketPath
+func Invoke(socketPath, name string, request any) (*commands.CommandResponse, error) {
+	return NewClient(socketPath).Invoke(name, request)
+}
+
+// Invoke runs a command in the daemon or server the client talks to
+func (c *Client) Invoke(name string, request any) (*commands.CommandResponse, error) {
+	var result struct {
+		Status string          `json:"status"`
+		Data   json.RawMessage `json:"data,omitempty"`
+		Error  string          `json:"error,omitempty"`
+	}
+
+	err := c.Call(InvokeMethod, map[string]any{
+		"command": name,
+		"request": request,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &commands.CommandResponse{
+		Status: result.Status,
+		Error:  result.Error,
+	}
+	if len(result.Data) > 0 {
+		response.Data = result.Data
+	}
+
+	return response, nil
+}
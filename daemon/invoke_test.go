@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandAdapter(t *testing.T) {
+	var got commands.TapRequest
+	fn := command(func(req commands.TapRequest) *commands.CommandResponse {
+		got = req
+		return commands.NewSuccessResponse(commands.OK)
+	})
+
+	response, err := fn(json.RawMessage(`{"deviceId":"abc","x":10,"y":20}`))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response.Status)
+	assert.Equal(t, commands.TapRequest{DeviceID: "abc", X: 10, Y: 20}, got)
+
+	_, err = fn(json.RawMessage(`{"x":"not-a-number"}`))
+	assert.Error(t, err)
+}
+
+func TestHandleInvoke_UnknownCommand(t *testing.T) {
+	_, err := handleInvoke(json.RawMessage(`{"command":"devices.list","request":{}}`))
+	assert.ErrorContains(t, err, "cannot be delegated")
+}
+
+func TestHandleInvoke_MissingParams(t *testing.T) {
+	_, err := handleInvoke(nil)
+	assert.Error(t, err)
+}
+
+func TestSocketPath_EnvOverride(t *testing.T) {
+	t.Setenv(SocketEnvVar, "/tmp/custom.sock")
+	assert.Equal(t, "/tmp/custom.sock", SocketPath())
+}
+
+func TestRemoteClientInvoke(t *testing.T) {
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		path = r.URL.Path
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":{"status":"ok","data":{"message":"tapped"}}}`))
+	}))
+	defer srv.Close()
+
+	response, err := NewRemoteClient(srv.URL+"/", "tok-remote").Invoke("tap", commands.TapRequest{X: 1, Y: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", response.Status)
+	assert.JSONEq(t, `{"message":"tapped"}`, string(response.Data.(json.RawMessage)))
+	assert.Equal(t, "Bearer tok-remote", auth)
+	assert.Equal(t, "/rpc", path)
+}
+
+func TestRemoteClientUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	_, err := NewRemoteClient(srv.URL, "").Invoke("tap", commands.TapRequest{})
+	assert.ErrorContains(t, err, "auth login")
+}
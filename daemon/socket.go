@@ -0,0 +1,169 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/server"
+)
+
+const (
+	// SocketEnvVar overrides the default daemon socket path
+	SocketEnvVar = "MOBILECLI_DAEMON_SOCKET"
+
+	// DisableEnvVar prevents the CLI from delegating commands to a running daemon
+	DisableEnvVar = "MOBILECLI_NO_DAEMON"
+
+	// probeTimeout bounds how long the CLI waits when checking for a daemon
+	probeTimeout = 100 * time.Millisecond
+)
+
+// SocketPath returns the Unix socket path the daemon listens on
+func SocketPath() string {
+	if path := os.Getenv(SocketEnvVar); path != "" {
+		return path
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("mobilecli-%d.sock", os.Getuid()))
+}
+
+// IsRunning returns true if a daemon is accepting connections on socketPath
+func IsRunning(socketPath string) bool {
+	conn, err := net.DialTimeout("unix", socketPath, probeTimeout)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// Client sends JSON-RPC requests to a daemon over its Unix socket, or to a
+// remote mobilecli server over HTTP
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+	name       string
+	token      string
+	nextID     int
+}
+
+// NewClient creates a client for the daemon listening on socketPath
+func NewClient(socketPath string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+		// the host is ignored, the transport always dials the socket
+		endpoint: "http://daemon/rpc",
+		name:     "daemon",
+	}
+}
+
+// NewRemoteClient creates a client for the mobilecli server at serverURL, e.g.
+// "farm.example.com:12000" or "https://farm.example.com". The token, when
+// set, is sent as a bearer token with every request.
+func NewRemoteClient(serverURL, token string) *Client {
+	endpoint := strings.TrimSuffix(serverURL, "/")
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	if !strings.HasSuffix(endpoint, "/rpc") {
+		endpoint += "/rpc"
+	}
+
+	return &Client{
+		httpClient: http.DefaultClient,
+		endpoint:   endpoint,
+		name:       serverURL,
+		token:      token,
+	}
+}
+
+// Call invokes method with params and unmarshals the JSON-RPC result into result.
+// If result is nil, the response result is discarded.
+func (c *Client) Call(method string, params any, result any) error {
+	c.nextID++
+
+	var rawParams json.RawMessage
+	if params != nil {
+		var err error
+		rawParams, err = json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to marshal params: %w", err)
+		}
+	}
+
+	reqBody, err := json.Marshal(server.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  rawParams,
+		ID:      c.nextID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", c.name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%s rejected the request (%s), run 'mobilecli auth login' or set MOBILECLI_TOKEN", c.name, resp.Status)
+	}
+
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+			Data    any    `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.name, err)
+	}
+
+	if rpcResp.Error != nil {
+		if data, ok := rpcResp.Error.Data.(string); ok && data != "" {
+			return fmt.Errorf("%s", data)
+		}
+		return fmt.Errorf("%s", rpcResp.Error.Message)
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// StopDaemon asks the daemon listening on socketPath to shut down
+func StopDaemon(socketPath string) error {
+	if !IsRunning(socketPath) {
+		return fmt.Errorf("daemon is not running on %s", socketPath)
+	}
+
+	return NewClient(socketPath).Call("server.shutdown", nil, nil)
+}
@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/server"
+)
+
+// uploadChunkSize is how much of a file each upload request sends, so a
+// failed request only has to resend one chunk
+const uploadChunkSize = 32 << 20
+
+// Upload sends a file to the server's POST /upload endpoint in chunks and
+// returns the uploaded artifact, whose ID device.apps.install accepts.
+// onProgress, when set, is called after each chunk.
+func (c *Client) Upload(path string, onProgress func(sent, total int64)) (*server.UploadedArtifact, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	base := strings.TrimSuffix(c.endpoint, "/rpc") + "/upload"
+	target := base + "?filename=" + url.QueryEscape(filepath.Base(path))
+
+	var artifact *server.UploadedArtifact
+	for sent := int64(0); artifact == nil || sent < info.Size(); {
+		chunk := io.NewSectionReader(file, sent, min(uploadChunkSize, info.Size()-sent))
+		artifact, err = c.uploadChunk(target, chunk)
+		if err != nil {
+			return nil, err
+		}
+
+		if artifact.Size <= sent && sent < info.Size() {
+			return nil, fmt.Errorf("%s stopped accepting the upload at %d bytes", c.name, sent)
+		}
+		sent = artifact.Size
+		if onProgress != nil {
+			onProgress(sent, info.Size())
+		}
+		target = fmt.Sprintf("%s/%s?offset=%d", base, url.PathEscape(artifact.ID), sent)
+	}
+
+	return artifact, nil
+}
+
+// uploadChunk sends one chunk of an upload
+func (c *Client) uploadChunk(target string, chunk *io.SectionReader) (*server.UploadedArtifact, error) {
+	req, err := http.NewRequest(http.MethodPost, target, chunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = chunk.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload to %s: %w", c.name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("%s rejected the upload (%s), run 'mobilecli auth login' or set MOBILECLI_TOKEN", c.name, resp.Status)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&failure); err != nil || failure.Error == "" {
+			return nil, fmt.Errorf("%s rejected the upload: %s", c.name, resp.Status)
+		}
+		return nil, fmt.Errorf("%s rejected the upload: %s", c.name, failure.Error)
+	}
+
+	var artifact server.UploadedArtifact
+	if err := json.NewDecoder(resp.Body).Decode(&artifact); err != nil {
+		return nil, fmt.Errorf("failed to decode %s response: %w", c.name, err)
+	}
+	return &artifact, nil
+}
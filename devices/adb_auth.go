@@ -0,0 +1,124 @@
+package devices
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// AndroidStateUnauthorized is the state of an Android device that hasn't
+// allowed USB debugging from this computer yet
+const AndroidStateUnauthorized = "unauthorized"
+
+// adbKey is a private key adb authenticates with besides its own
+var adbKey struct {
+	mu   sync.RWMutex
+	path string
+}
+
+// SetAdbKey makes adb authenticate with a private key besides its own
+// ~/.android/adbkey, such as a key preauthorized on a CI image. adb reads it
+// from ADB_VENDOR_KEYS when its server starts, so a server that's already
+// running has to be restarted to use it, see RestartAdbServer.
+func SetAdbKey(path string) {
+	adbKey.mu.Lock()
+	defer adbKey.mu.Unlock()
+
+	adbKey.path = path
+}
+
+// AdbKey returns the private key given to SetAdbKey, if any
+func AdbKey() string {
+	adbKey.mu.RLock()
+	defer adbKey.mu.RUnlock()
+
+	return adbKey.path
+}
+
+// applyAdbKey passes the adb key to an adb command in ADB_VENDOR_KEYS, ahead
+// of the keys already listed there
+func applyAdbKey(cmd *exec.Cmd) {
+	key := AdbKey()
+	if key == "" {
+		return
+	}
+
+	keys := key
+	if existing := os.Getenv("ADB_VENDOR_KEYS"); existing != "" {
+		keys += string(os.PathListSeparator) + existing
+	}
+	cmd.Env = append(os.Environ(), "ADB_VENDOR_KEYS="+keys)
+}
+
+// AdbPublicKeyPath returns the public key a device is asked to authorize:
+// the one next to the adb key, or else adb's own
+func AdbPublicKeyPath() string {
+	if key := AdbKey(); key != "" {
+		return key + ".pub"
+	}
+
+	if dir := os.Getenv("ANDROID_USER_HOME"); dir != "" {
+		return filepath.Join(dir, "adbkey.pub")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".android", "adbkey.pub")
+}
+
+// AdbKeyFingerprint returns the fingerprint of an adb public key, the way
+// Android shows it in its "Allow USB debugging?" prompt
+func AdbKeyFingerprint(publicKeyPath string) (string, error) {
+	data, err := os.ReadFile(publicKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	// the key is base64, followed by user@host
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("adb public key %s is empty", publicKeyPath)
+	}
+	key, err := base64.StdEncoding.DecodeString(fields[0])
+	if err != nil {
+		return "", fmt.Errorf("adb public key %s is invalid: %v", publicKeyPath, err)
+	}
+
+	digest := md5.Sum(key)
+	parts := make([]string, len(digest))
+	for i, b := range digest {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// RestartAdbServer restarts the local adb server, so it loads the adb key
+// given to SetAdbKey. Every device reconnects, and one that authorizes the
+// key comes back online.
+func RestartAdbServer() error {
+	adbServer.mu.RLock()
+	host := adbServer.host
+	adbServer.mu.RUnlock()
+	if host != "" {
+		return fmt.Errorf("cannot restart the adb server at %s, start it with ADB_VENDOR_KEYS set instead", host)
+	}
+
+	utils.Verbose("Restarting the adb server to load %s", AdbKey())
+
+	// kill-server fails when no server is running, which is fine
+	_, _ = utils.CombinedOutput(adbCommand("kill-server"))
+
+	output, err := utils.CombinedOutput(adbCommand("start-server"))
+	if err != nil {
+		return fmt.Errorf("failed to start the adb server: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
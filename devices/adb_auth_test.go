@@ -0,0 +1,51 @@
+package devices
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdbDevicesOutputUnauthorized(t *testing.T) {
+	output := "List of devices attached\nR58M123ABC unauthorized usb:1-1.4 transport_id:4\n0123456789 offline transport_id:5\n"
+
+	devices := parseAdbDevicesOutput(output)
+	if assert.Len(t, devices, 1) {
+		d := devices[0].(*AndroidDevice)
+		assert.Equal(t, "R58M123ABC", d.ID())
+		assert.Equal(t, AndroidStateUnauthorized, d.State())
+		assert.Equal(t, "real", d.DeviceType())
+		assert.Equal(t, "1-1.4", d.USBPath())
+		assert.Error(t, d.StartAgent(StartAgentConfig{}))
+	}
+}
+
+func TestAdbKeyFingerprint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "adbkey.pub")
+	assert.NoError(t, os.WriteFile(path, []byte("QUJDRA== ci@builder\n"), 0o600))
+
+	fingerprint, err := AdbKeyFingerprint(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "CB:08:CA:4A:7B:B5:F9:68:3C:19:13:3A:84:87:2C:A7", fingerprint)
+
+	assert.NoError(t, os.WriteFile(path, []byte("not base64!"), 0o600))
+	_, err = AdbKeyFingerprint(path)
+	assert.Error(t, err)
+}
+
+func TestApplyAdbKey(t *testing.T) {
+	t.Cleanup(func() { SetAdbKey("") })
+	t.Setenv("ADB_VENDOR_KEYS", "/keys/other")
+
+	cmd := exec.Command("adb")
+	applyAdbKey(cmd)
+	assert.Nil(t, cmd.Env)
+
+	SetAdbKey("/keys/ci")
+	applyAdbKey(cmd)
+	assert.Contains(t, cmd.Env, "ADB_VENDOR_KEYS=/keys/ci"+string(os.PathListSeparator)+"/keys/other")
+	assert.Equal(t, "/keys/ci.pub", AdbPublicKeyPath())
+}
@@ -0,0 +1,76 @@
+package devices
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// adbServer selects the adb server that adb commands talk to. The zero value
+// uses adb's own defaults: localhost, and ANDROID_ADB_SERVER_PORT or 5037.
+var adbServer struct {
+	mu   sync.RWMutex
+	host string
+	port int
+}
+
+// SetAdbServer points adb commands at a non-default adb server, such as one
+// forwarded from a device provider into a container. An empty host or a zero
+// port keeps adb's default for that part.
+func SetAdbServer(host string, port int) {
+	adbServer.mu.Lock()
+	defer adbServer.mu.Unlock()
+
+	adbServer.host = host
+	adbServer.port = port
+}
+
+// adbServerArgs returns the adb global options selecting the configured server
+func adbServerArgs() []string {
+	adbServer.mu.RLock()
+	defer adbServer.mu.RUnlock()
+
+	var args []string
+	if adbServer.host != "" {
+		args = append(args, "-H", adbServer.host)
+	}
+	if adbServer.port != 0 {
+		args = append(args, "-P", strconv.Itoa(adbServer.port))
+	}
+	return args
+}
+
+// adbCommand builds an adb command against the configured adb server
+func adbCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command(getAdbPath(), append(adbServerArgs(), args...)...)
+	applyAdbKey(cmd)
+	return cmd
+}
+
+// adbCommandContext is adbCommand bound to ctx
+func adbCommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, getAdbPath(), append(adbServerArgs(), args...)...)
+	applyAdbKey(cmd)
+	return cmd
+}
+
+// AdbInstalled reports whether an adb binary can be found
+func AdbInstalled() bool {
+	_, err := exec.LookPath(getAdbPath())
+	return err == nil
+}
+
+// PingAdbServer checks that adb can talk to its server, starting the server
+// if it isn't running yet
+func PingAdbServer(ctx context.Context) error {
+	output, err := utils.CombinedOutput(adbCommandContext(ctx, "devices"))
+	if err != nil {
+		return fmt.Errorf("adb server is not reachable: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
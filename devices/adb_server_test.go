@@ -0,0 +1,24 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdbServerArgs(t *testing.T) {
+	t.Cleanup(func() { SetAdbServer("", 0) })
+
+	SetAdbServer("", 0)
+	assert.Empty(t, adbServerArgs())
+
+	SetAdbServer("adb.provider.internal", 0)
+	assert.Equal(t, []string{"-H", "adb.provider.internal"}, adbServerArgs())
+
+	SetAdbServer("", 5038)
+	assert.Equal(t, []string{"-P", "5038"}, adbServerArgs())
+
+	SetAdbServer("10.0.0.2", 15037)
+	cmd := adbCommand("-s", "emulator-5554", "shell", "true")
+	assert.Equal(t, []string{"-H", "10.0.0.2", "-P", "15037", "-s", "emulator-5554", "shell", "true"}, cmd.Args[1:])
+}
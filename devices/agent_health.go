@@ -0,0 +1,136 @@
+package devices
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices/wda"
+)
+
+// DefaultAgentCheckTimeout bounds how long a single device's agent check may take
+const DefaultAgentCheckTimeout = 3 * time.Second
+
+// AgentStatus reports the health of a device's on-device agent
+type AgentStatus struct {
+	Installed bool   `json:"installed"`
+	Running   bool   `json:"running"`
+	Port      int    `json:"port,omitempty"`
+	Tunnel    *bool  `json:"tunnel,omitempty"` // only for real iOS devices that need a tunnel (iOS 17+)
+	Error     string `json:"error,omitempty"`
+}
+
+// AgentHealthChecker is implemented by devices that can report the health of
+// their on-device agent without starting it.
+type AgentHealthChecker interface {
+	CheckAgent() *AgentStatus
+}
+
+// CheckAgents probes the agents of all devices concurrently. Devices that
+// don't answer within timeout are reported with an error rather than
+// holding up the listing.
+func CheckAgents(devices []ControllableDevice, timeout time.Duration) []*AgentStatus {
+	statuses := make([]*AgentStatus, len(devices))
+
+	var wg sync.WaitGroup
+	for i, device := range devices {
+		checker, ok := device.(AgentHealthChecker)
+		if !ok || device.State() == "offline" || device.State() == AndroidStateUnauthorized {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := make(chan *AgentStatus, 1)
+			go func() { result <- checker.CheckAgent() }()
+
+			select {
+			case status := <-result:
+				statuses[i] = status
+			case <-time.After(timeout):
+				statuses[i] = &AgentStatus{Error: fmt.Sprintf("timed out after %s", timeout)}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// CheckAgent reports whether DeviceKit is installed. DeviceKit is launched on
+// demand for each command, so an installed agent counts as running.
+func (d *AndroidDevice) CheckAgent() *AgentStatus {
+	installed := d.isDeviceKitInstalled()
+	return &AgentStatus{
+		Installed: installed,
+		Running:   installed,
+	}
+}
+
+// CheckAgent reports whether the agent is installed, reachable through this
+// process's port forwarder, and whether the device's tunnel is up
+func (d *IOSDevice) CheckAgent() *AgentStatus {
+	status := &AgentStatus{}
+
+	if d.requiresTunnel() {
+		tunnel := d.tunnelManager != nil && d.tunnelManager.IsTunnelRunning()
+		status.Tunnel = &tunnel
+	}
+
+	d.mu.Lock()
+	if d.portForwarderWda != nil && d.portForwarderWda.IsRunning() {
+		status.Port, _ = d.portForwarderWda.GetPorts()
+	}
+	client := d.wdaClient
+	d.mu.Unlock()
+
+	if status.Port != 0 && client != nil {
+		if _, err := client.GetStatus(); err == nil {
+			status.Running = true
+			status.Installed = true
+			return status
+		}
+	}
+
+	apps, err := d.ListApps(true)
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to list apps: %v", err)
+		return status
+	}
+
+	for _, app := range apps {
+		if strings.HasSuffix(app.PackageName, agentRunnerBundleID) {
+			status.Installed = true
+			break
+		}
+	}
+
+	return status
+}
+
+// CheckAgent reports whether the agent is installed and answering on the
+// port it was launched with
+func (s *SimulatorDevice) CheckAgent() *AgentStatus {
+	status := &AgentStatus{}
+
+	if port, err := s.getWdaPort(); err == nil {
+		status.Port = port
+		if _, err := wda.NewWdaClient(fmt.Sprintf("localhost:%d", port)).GetStatus(); err == nil {
+			status.Running = true
+			status.Installed = true
+			return status
+		}
+	}
+
+	bundleID, err := s.findInstalledAgentBundleID()
+	if err != nil {
+		status.Error = fmt.Sprintf("failed to list apps: %v", err)
+		return status
+	}
+	status.Installed = bundleID != ""
+
+	return status
+}
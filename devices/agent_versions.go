@@ -0,0 +1,183 @@
+package devices
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// AgentPins pins the agent versions mobilecli installs and starts. They are
+// read from config.json in mobilecli's configuration directory, e.g.
+//
+//	{"wda_version": "0.0.20", "devicekit_version": "1.2.4"}
+//
+// Without a pin, 'agent install' installs the version this mobilecli was
+// released with, and any installed version is started. A pinned version is
+// only installed when the sha256 of its download is pinned in
+// agent_checksums too.
+type AgentPins struct {
+	// WDAVersion pins the iOS agent, the DeviceKit WebDriverAgent runner
+	WDAVersion string
+
+	// DeviceKitVersion pins DeviceKit for Android
+	DeviceKitVersion string
+
+	// Checksums maps the file names of pinned agent downloads, e.g.
+	// "devicekit.apk", to their sha256
+	Checksums map[string]string
+}
+
+// agentOSRange is the range of OS versions an agent release supports. Max
+// is compared on its own components only, "26" allowing any 26.x.
+type agentOSRange struct {
+	Min string
+	Max string
+}
+
+// agentCompatibility lists the OS versions each agent release supports, by
+// platform and agent version. Releases missing from it aren't checked.
+var agentCompatibility = map[string]map[string]agentOSRange{
+	"ios": {
+		"0.0.20": {Min: "15.0", Max: "26"},
+	},
+	"android": {
+		"1.2.4": {Min: "7.0", Max: "16"},
+	},
+}
+
+// LoadAgentPins reads the agent pins, which are empty when config.json
+// doesn't exist
+func LoadAgentPins() (AgentPins, error) {
+	config, err := utils.LoadConfig()
+	if err != nil {
+		return AgentPins{}, err
+	}
+
+	return AgentPins{
+		WDAVersion:       strings.TrimPrefix(strings.TrimSpace(config.WDAVersion), "v"),
+		DeviceKitVersion: strings.TrimPrefix(strings.TrimSpace(config.DeviceKitVersion), "v"),
+		Checksums:        config.AgentChecksums,
+	}, nil
+}
+
+// ForPlatform returns the version pinned for a platform's agent, or ""
+func (p AgentPins) ForPlatform(platform string) string {
+	switch platform {
+	case "ios":
+		return p.WDAVersion
+	case "android":
+		return p.DeviceKitVersion
+	default:
+		return ""
+	}
+}
+
+// ChecksumFor returns the sha256 pinned for an agent download, or ""
+func (p AgentPins) ChecksumFor(filename string) string {
+	return strings.ToLower(strings.TrimSpace(p.Checksums[filename]))
+}
+
+// agentPinKey names the config.json key pinning a platform's agent
+func agentPinKey(platform string) string {
+	if platform == "android" {
+		return "devicekit_version"
+	}
+	return "wda_version"
+}
+
+// agentOSName names a platform's OS in messages
+func agentOSName(platform string) string {
+	if platform == "android" {
+		return "Android"
+	}
+	return "iOS"
+}
+
+// IsAgentDowngrade reports whether installing version would replace a newer
+// installed agent
+func IsAgentDowngrade(installed, version string) bool {
+	return installed != "" && compareVersions(installed, version) > 0
+}
+
+// compareVersions compares dotted numeric versions component by component,
+// missing components counting as 0 and non-numeric suffixes being ignored
+func compareVersions(a, b string) int {
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < max(len(as), len(bs)); i++ {
+		var x, y int
+		if i < len(as) {
+			x = leadingInt(as[i])
+		}
+		if i < len(bs) {
+			y = leadingInt(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// leadingInt parses the digits a version component starts with
+func leadingInt(s string) int {
+	end := 0
+	for end < len(s) && s[end] >= '0' && s[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(s[:end])
+	return n
+}
+
+// truncateVersion keeps the first n components of a version
+func truncateVersion(version string, n int) string {
+	parts := strings.Split(version, ".")
+	if len(parts) > n {
+		parts = parts[:n]
+	}
+	return strings.Join(parts, ".")
+}
+
+// CheckAgentCompatibility fails when a device's OS version is outside the
+// range an agent version supports
+func CheckAgentCompatibility(platform, agentVersion, osVersion string) error {
+	supported, ok := agentCompatibility[platform][agentVersion]
+	if !ok || osVersion == "" {
+		utils.Verbose("no compatibility information for agent %s on %s %s, not checking it", agentVersion, agentOSName(platform), osVersion)
+		return nil
+	}
+
+	tooOld := compareVersions(osVersion, supported.Min) < 0
+	tooNew := supported.Max != "" && compareVersions(truncateVersion(osVersion, len(strings.Split(supported.Max, "."))), supported.Max) > 0
+	if !tooOld && !tooNew {
+		return nil
+	}
+
+	osName := agentOSName(platform)
+	message := fmt.Sprintf("agent version %s supports %s %s to %s.x, but the device runs %s %s", agentVersion, osName, supported.Min, supported.Max, osName, osVersion)
+	if tooOld {
+		return fmt.Errorf("%s; pin an older agent in %s of config.json that supports it", message, agentPinKey(platform))
+	}
+	return fmt.Errorf("%s; upgrade mobilecli or pin a newer agent in %s of config.json, then run 'mobilecli agent update'", message, agentPinKey(platform))
+}
+
+// CheckAgentVersion fails when the agent installed on a device isn't the
+// pinned version, or doesn't support the device's OS version
+func CheckAgentVersion(device ControllableDevice, installedVersion string) error {
+	pins, err := LoadAgentPins()
+	if err != nil {
+		return err
+	}
+
+	platform := device.Platform()
+	if pinned := pins.ForPlatform(platform); pinned != "" && installedVersion != pinned {
+		return fmt.Errorf("installed agent version %s doesn't match %s %s pinned in config.json, run 'mobilecli agent install --force --device %s' to install the pinned version", installedVersion, agentPinKey(platform), pinned, device.ID())
+	}
+
+	return CheckAgentCompatibility(platform, installedVersion, device.Version())
+}
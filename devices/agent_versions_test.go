@@ -0,0 +1,62 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAgentPins(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	pins, err := LoadAgentPins()
+	require.NoError(t, err)
+	assert.Equal(t, AgentPins{}, pins)
+
+	path, err := utils.ConfigFilePath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(`{"wda_version": "v9.15.1", "devicekit_version": "1.4.0", "agent_checksums": {"devicekit.apk": " ABC123 "}}`), 0o600))
+
+	pins, err = LoadAgentPins()
+	require.NoError(t, err)
+	assert.Equal(t, "9.15.1", pins.ForPlatform("ios"))
+	assert.Equal(t, "1.4.0", pins.ForPlatform("android"))
+	assert.Equal(t, "abc123", pins.ChecksumFor("devicekit.apk"))
+	assert.Equal(t, "", pins.ChecksumFor("devicekit-ios-runner.ipa"))
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+	_, err = LoadAgentPins()
+	assert.ErrorContains(t, err, "failed to parse config")
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, compareVersions("17.0", "17"))
+	assert.Equal(t, -1, compareVersions("9.3", "15.0"))
+	assert.Equal(t, 1, compareVersions("17.0.1", "17.0"))
+	assert.Equal(t, 1, compareVersions("16-beta", "15"))
+
+	assert.True(t, IsAgentDowngrade("1.3.0", "1.2.4"))
+	assert.False(t, IsAgentDowngrade("1.2.0", "1.2.4"))
+	assert.False(t, IsAgentDowngrade("", "1.2.4"))
+}
+
+func TestCheckAgentCompatibility(t *testing.T) {
+	assert.NoError(t, CheckAgentCompatibility("ios", "0.0.20", "17.4"))
+	assert.NoError(t, CheckAgentCompatibility("ios", "0.0.20", "26.1"))
+	assert.NoError(t, CheckAgentCompatibility("android", "1.2.4", "14"))
+
+	err := CheckAgentCompatibility("ios", "0.0.20", "27.0")
+	assert.ErrorContains(t, err, "supports iOS 15.0 to 26.x, but the device runs iOS 27.0")
+	assert.ErrorContains(t, err, "pin a newer agent in wda_version")
+
+	err = CheckAgentCompatibility("android", "1.2.4", "6.0")
+	assert.ErrorContains(t, err, "pin an older agent in devicekit_version")
+
+	// releases missing from the matrix aren't checked
+	assert.NoError(t, CheckAgentCompatibility("ios", "9.15.1", "27.0"))
+}
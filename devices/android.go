@@ -2,7 +2,6 @@ package devices
 
 import (
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
@@ -27,9 +26,14 @@ type AndroidDevice struct {
 	id          string
 	name        string
 	version     string
-	state       string // "online" or "offline"
+	state       string // "online", "offline" or AndroidStateUnauthorized
 	transportID string // adb transport ID (e.g., "emulator-5554"), only set for online devices
 	model       string
+
+	// usbPath is the USB port path adb reports, e.g. "1-1.4", and
+	// adbTransportID the numeric transport ID of the adb server's connection
+	usbPath        string
+	adbTransportID string
 }
 
 func (d *AndroidDevice) ID() string {
@@ -61,6 +65,33 @@ func (d *AndroidDevice) State() string {
 	return d.state
 }
 
+// Transport returns how a real device is connected to adb. Devices connected
+// with 'adb connect' or wireless debugging have a host:port or mDNS serial.
+// Emulators have no transport.
+func (d *AndroidDevice) Transport() string {
+	if d.DeviceType() != "real" {
+		return ""
+	}
+
+	if strings.Contains(d.transportID, ":") || strings.Contains(d.transportID, "._adb-tls-connect.") {
+		return TransportNetwork
+	}
+
+	return TransportUSB
+}
+
+// USBPath returns the USB port path the device is plugged into, as adb
+// reports it, or "" when it isn't connected over USB
+func (d *AndroidDevice) USBPath() string {
+	return d.usbPath
+}
+
+// AdbTransportID returns the transport ID adb numbers the device's
+// connection with, which 'adb -t' selects it by
+func (d *AndroidDevice) AdbTransportID() string {
+	return d.adbTransportID
+}
+
 func getAndroidSdkPath() string {
 	sdkPath := os.Getenv("ANDROID_HOME")
 	if sdkPath != "" {
@@ -102,34 +133,11 @@ func getAndroidSdkPath() string {
 }
 
 func getAdbPath() string {
-	sdkPath := getAndroidSdkPath()
-	if sdkPath != "" {
-		adbPath := filepath.Join(sdkPath, "platform-tools", "adb")
-		if runtime.GOOS == "windows" {
-			adbPath += ".exe"
-		}
-
-		return adbPath
-	}
-
-	// best effort, look in path
-	return "adb"
+	return resolveAdbPath().Path
 }
 
 func getEmulatorPath() string {
-	sdkPath := getAndroidSdkPath()
-	if sdkPath != "" {
-		emulatorPath := filepath.Join(sdkPath, "emulator", "emulator")
-		if runtime.GOOS == "windows" {
-			emulatorPath += ".exe"
-		}
-		if _, err := os.Stat(emulatorPath); err == nil {
-			return emulatorPath
-		}
-	}
-
-	// best effort, look in path
-	return "emulator"
+	return resolveEmulatorPath().Path
 }
 
 // getAdbIdentifier returns the correct device identifier for adb commands
@@ -141,18 +149,52 @@ func (d *AndroidDevice) getAdbIdentifier() string {
 	return d.id
 }
 
+// AdbSerial returns the serial adb knows the device by, which for emulators
+// differs from the device ID (the AVD name)
+func (d *AndroidDevice) AdbSerial() string {
+	return d.getAdbIdentifier()
+}
+
 func (d *AndroidDevice) runAdbCommand(args ...string) ([]byte, error) {
 	deviceID := d.getAdbIdentifier()
 	cmdArgs := append([]string{"-s", deviceID}, args...)
-	cmd := exec.Command(getAdbPath(), cmdArgs...)
-	return cmd.CombinedOutput()
+	cmd := adbCommand(cmdArgs...)
+	return utils.CombinedOutput(cmd)
+}
+
+// longCommandTimeout bounds adb commands, such as installs, transfers and bug
+// reports, that can take longer than the default command timeout
+const longCommandTimeout = 30 * time.Minute
+
+// runAdbCommandTimeout is runAdbCommand for commands that need a timeout
+// other than the default command timeout
+func (d *AndroidDevice) runAdbCommandTimeout(timeout time.Duration, args ...string) ([]byte, error) {
+	deviceID := d.getAdbIdentifier()
+	cmdArgs := append([]string{"-s", deviceID}, args...)
+	cmd := adbCommand(cmdArgs...)
+	return utils.CombinedOutput(utils.WithTimeout(cmd, timeout))
 }
 
 func (d *AndroidDevice) runAdbCommandContext(ctx context.Context, args ...string) ([]byte, error) {
 	deviceID := d.getAdbIdentifier()
 	cmdArgs := append([]string{"-s", deviceID}, args...)
-	cmd := exec.CommandContext(ctx, getAdbPath(), cmdArgs...)
-	return cmd.CombinedOutput()
+	cmd := adbCommandContext(ctx, cmdArgs...)
+	return utils.CombinedOutput(cmd)
+}
+
+// runEmulatorCommand runs an emulator console command, e.g. "sensor set",
+// failing on real devices and when the console answers KO, which it does
+// with a zero exit code
+func (d *AndroidDevice) runEmulatorCommand(args ...string) error {
+	if d.DeviceType() != "emulator" {
+		return fmt.Errorf("'%s' is only available on emulators", strings.Join(args[:min(2, len(args))], " "))
+	}
+
+	output, err := d.runAdbCommand(append([]string{"emu"}, args...)...)
+	if err != nil || strings.Contains(string(output), "KO") {
+		return fmt.Errorf("emulator console: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
 }
 
 // getDisplayCount counts the number of displays on the device
@@ -307,8 +349,9 @@ func parseResolveActivityOutput(output string) string {
 	return ""
 }
 
-func (d *AndroidDevice) resolveLauncherActivity(bundleID string) (string, error) {
-	output, err := d.runAdbCommand("shell", "cmd", "package", "resolve-activity", "--brief", bundleID)
+func (d *AndroidDevice) resolveLauncherActivity(bundleID, user string) (string, error) {
+	args := append([]string{"shell", "cmd", "package", "resolve-activity"}, userArgs(user)...)
+	output, err := d.runAdbCommand(append(args, "--brief", bundleID)...)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve launcher activity for %s: %w\nOutput: %s", bundleID, err, string(output))
 	}
@@ -319,17 +362,55 @@ func (d *AndroidDevice) resolveLauncherActivity(bundleID string) (string, error)
 	return component, nil
 }
 
+// parseAmStartOutput reads the result of 'am start', which exits with 0
+// even when the activity couldn't be started. With -W it also reports the
+// activity that was started and how long that took.
+func parseAmStartOutput(component, output string) (*LaunchResult, error) {
+	result := &LaunchResult{Component: component}
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Error":
+			return nil, fmt.Errorf("%s", value)
+		case "Status":
+			if value != "ok" {
+				return nil, fmt.Errorf("launch status %s", value)
+			}
+		case "Activity":
+			result.Component = value
+		case "TotalTime":
+			result.TotalTime, _ = strconv.Atoi(value)
+		case "WaitTime":
+			result.WaitTime, _ = strconv.Atoi(value)
+		}
+	}
+	return result, nil
+}
+
 func (d *AndroidDevice) LaunchApp(bundleID string, opts LaunchOptions) error {
+	_, err := d.LaunchAppComponent(bundleID, opts)
+	return err
+}
+
+// LaunchAppComponent launches the given activity, or the app's launcher
+// activity, and reports the component that was started
+func (d *AndroidDevice) LaunchAppComponent(bundleID string, opts LaunchOptions) (*LaunchResult, error) {
 	if len(opts.Locales) > 0 {
 		for _, l := range opts.Locales {
 			if !validLocaleTag.MatchString(l) {
-				return fmt.Errorf("invalid locale tag: %q", l)
+				return nil, fmt.Errorf("invalid locale tag: %q", l)
 			}
 		}
 		localeArg := strings.Join(opts.Locales, ",")
-		output, err := d.runAdbCommand("shell", "cmd", "locale", "set-app-locales", bundleID, "--locales", localeArg)
+		args := append([]string{"shell", "cmd", "locale", "set-app-locales", bundleID}, userArgs(opts.User)...)
+		output, err := d.runAdbCommand(append(args, "--locales", localeArg)...)
 		if err != nil {
-			return fmt.Errorf("failed to set app locales for %s: %w\nOutput: %s", bundleID, err, string(output))
+			return nil, fmt.Errorf("failed to set app locales for %s: %w\nOutput: %s", bundleID, err, string(output))
 		}
 	}
 
@@ -338,18 +419,29 @@ func (d *AndroidDevice) LaunchApp(bundleID string, opts LaunchOptions) error {
 	if opts.Activity != "" {
 		component, err = buildLaunchComponent(bundleID, opts.Activity)
 	} else {
-		component, err = d.resolveLauncherActivity(bundleID)
+		component, err = d.resolveLauncherActivity(bundleID, opts.User)
 	}
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	args := append([]string{"shell", "am", "start"}, userArgs(opts.User)...)
+	if opts.Wait {
+		args = append(args, "-W")
 	}
+	args = append(args, "-n", component)
 
-	output, err := d.runAdbCommand("shell", "am", "start", "-n", component)
+	output, err := d.runAdbCommand(args...)
 	if err != nil {
-		return fmt.Errorf("failed to launch app %s: %w\nOutput: %s", bundleID, err, string(output))
+		return nil, fmt.Errorf("failed to launch app %s: %w\nOutput: %s", bundleID, err, string(output))
 	}
 
-	return nil
+	result, err := parseAmStartOutput(component, string(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to launch %s: %w", component, err)
+	}
+
+	return result, nil
 }
 
 func (d *AndroidDevice) TerminateApp(bundleID string) error {
@@ -412,9 +504,17 @@ func (d *AndroidDevice) LongPress(x, y, duration int) error {
 	return nil
 }
 
-// Swipe simulates a swipe gesture from (x1, y1) to (x2, y2) on the Android device with 1000ms duration.
-func (d *AndroidDevice) Swipe(x1, y1, x2, y2 int) error {
-	_, err := d.runAdbCommand("shell", "input", "swipe", fmt.Sprintf("%d", x1), fmt.Sprintf("%d", y1), fmt.Sprintf("%d", x2), fmt.Sprintf("%d", y2), "1000")
+// defaultAndroidSwipeDuration is used when Swipe is called without a duration
+const defaultAndroidSwipeDuration = 1000
+
+// Swipe simulates a swipe gesture from (x1, y1) to (x2, y2) on the Android device,
+// taking duration milliseconds, or defaultAndroidSwipeDuration when it's 0.
+func (d *AndroidDevice) Swipe(x1, y1, x2, y2, duration int) error {
+	if duration <= 0 {
+		duration = defaultAndroidSwipeDuration
+	}
+
+	_, err := d.runAdbCommand("shell", "input", "swipe", fmt.Sprintf("%d", x1), fmt.Sprintf("%d", y1), fmt.Sprintf("%d", x2), fmt.Sprintf("%d", y2), fmt.Sprintf("%d", duration))
 	if err != nil {
 		return err
 	}
@@ -422,40 +522,61 @@ func (d *AndroidDevice) Swipe(x1, y1, x2, y2 int) error {
 	return nil
 }
 
-// Gesture performs a sequence of touch actions on the Android device
+// Gesture performs a sequence of touch actions on the Android device. Single
+// strokes are replayed with 'input swipe' so their duration is honored exactly;
+// other gestures are injected as one shell script to avoid per-action adb latency.
 func (d *AndroidDevice) Gesture(actions []wda.TapAction) error {
+	if swipe, ok := gestureAsSwipe(actions); ok {
+		_, err := d.runAdbCommand("shell", "input", "swipe", fmt.Sprintf("%d", swipe.x1), fmt.Sprintf("%d", swipe.y1), fmt.Sprintf("%d", swipe.x2), fmt.Sprintf("%d", swipe.y2), fmt.Sprintf("%d", swipe.duration))
+		if err != nil {
+			return fmt.Errorf("failed to execute gesture: %v", err)
+		}
+		return nil
+	}
 
-	x := 0
-	y := 0
+	script, err := d.gestureScript(actions)
+	if err != nil {
+		return err
+	}
 
-	for _, action := range actions {
-		var cmd []string
+	output, err := d.runAdbCommand("shell", script)
+	if err != nil {
+		return fmt.Errorf("failed to execute gesture: %v\nOutput: %s", err, string(output))
+	}
 
-		if action.Type == "pause" {
-			time.Sleep(time.Duration(action.Duration) * time.Millisecond)
-			continue
-		}
+	return nil
+}
 
-		switch action.Type {
-		case "pointerDown":
-			cmd = []string{"shell", "input", "touchscreen", "motionevent", "down", fmt.Sprintf("%d", x), fmt.Sprintf("%d", y)}
-		case "pointerMove":
-			x = action.X
-			y = action.Y
-			cmd = []string{"shell", "input", "touchscreen", "motionevent", "move", fmt.Sprintf("%d", action.X), fmt.Sprintf("%d", action.Y)}
-		case "pointerUp":
-			cmd = []string{"shell", "input", "touchscreen", "motionevent", "up", fmt.Sprintf("%d", x), fmt.Sprintf("%d", y)}
-		default:
-			return fmt.Errorf("unsupported gesture action type: %s", action.Type)
-		}
+// adbDevicesEntry is a line of 'adb devices -l'
+type adbDevicesEntry struct {
+	serial      string
+	state       string
+	usbPath     string
+	transportID string
+}
 
-		_, err := d.runAdbCommand(cmd...)
-		if err != nil {
-			return fmt.Errorf("failed to execute gesture action %s: %v", action.Type, err)
-		}
+// parseAdbDevicesLine parses a line of 'adb devices -l', such as
+// "R58M123ABC device usb:1-1.4 product:a52q model:SM_A525F transport_id:3"
+func parseAdbDevicesLine(line string) (adbDevicesEntry, bool) {
+	parts := strings.Fields(line)
+	if len(parts) < 2 {
+		return adbDevicesEntry{}, false
 	}
 
-	return nil
+	entry := adbDevicesEntry{serial: parts[0], state: parts[1]}
+	for _, part := range parts[2:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "usb":
+			entry.usbPath = value
+		case "transport_id":
+			entry.transportID = value
+		}
+	}
+	return entry, true
 }
 
 func parseAdbDevicesOutput(output string) []ControllableDevice {
@@ -463,32 +584,49 @@ func parseAdbDevicesOutput(output string) []ControllableDevice {
 
 	lines := strings.Split(output, "\n")
 	for i := 1; i < len(lines); i++ {
-		line := strings.TrimSpace(lines[i])
-		parts := strings.Fields(line)
-		if len(parts) == 2 {
-			transportID := parts[0]
-			status := parts[1]
-			if status == "device" {
-				deviceID := transportID
-
-				// for emulators, use AVD name as the consistent ID
-				if strings.HasPrefix(transportID, "emulator-") {
-					avdName := getAVDName(transportID)
-					if avdName != "" {
-						deviceID = avdName
-					}
-				}
+		entry, ok := parseAdbDevicesLine(lines[i])
+		if !ok {
+			continue
+		}
+
+		// a device that hasn't allowed USB debugging can't be asked for its
+		// name or version, but is listed so it isn't mistaken for missing
+		if entry.state == AndroidStateUnauthorized {
+			devices = append(devices, &AndroidDevice{
+				id:             entry.serial,
+				transportID:    entry.serial,
+				name:           entry.serial,
+				state:          AndroidStateUnauthorized,
+				usbPath:        entry.usbPath,
+				adbTransportID: entry.transportID,
+			})
+			continue
+		}
+		if entry.state != "device" {
+			continue
+		}
+
+		transportID := entry.serial
+		deviceID := transportID
 
-				devices = append(devices, &AndroidDevice{
-					id:          deviceID,
-					transportID: transportID,
-					name:        getAndroidDeviceName(transportID),
-					version:     getAndroidDeviceVersion(transportID),
-					state:       "online",
-					model:       getAndroidDeviceModel(transportID),
-				})
+		// for emulators, use AVD name as the consistent ID
+		if strings.HasPrefix(transportID, "emulator-") {
+			avdName := getAVDName(transportID)
+			if avdName != "" {
+				deviceID = avdName
 			}
 		}
+
+		devices = append(devices, &AndroidDevice{
+			id:             deviceID,
+			transportID:    transportID,
+			name:           getAndroidDeviceName(transportID),
+			version:        getAndroidDeviceVersion(transportID),
+			state:          "online",
+			model:          getAndroidDeviceModel(transportID),
+			usbPath:        entry.usbPath,
+			adbTransportID: entry.transportID,
+		})
 	}
 
 	return devices
@@ -496,8 +634,8 @@ func parseAdbDevicesOutput(output string) []ControllableDevice {
 
 // getAVDName returns the AVD name for an emulator, or empty string if not an emulator
 func getAVDName(transportID string) string {
-	avdCmd := exec.Command(getAdbPath(), "-s", transportID, "shell", "getprop", "ro.boot.qemu.avd_name")
-	avdOutput, err := avdCmd.CombinedOutput()
+	avdCmd := adbCommand("-s", transportID, "shell", "getprop", "ro.boot.qemu.avd_name")
+	avdOutput, err := utils.CombinedOutput(avdCmd)
 	if err == nil && len(avdOutput) > 0 {
 		avdName := strings.TrimSpace(string(avdOutput))
 		return avdName
@@ -515,8 +653,8 @@ func getAndroidDeviceName(deviceID string) string {
 	}
 
 	// for real devices, try getting device name from settings
-	nameCmd := exec.Command(getAdbPath(), "-s", deviceID, "shell", "settings", "get", "global", "device_name")
-	nameOutput, err := nameCmd.CombinedOutput()
+	nameCmd := adbCommand("-s", deviceID, "shell", "settings", "get", "global", "device_name")
+	nameOutput, err := utils.CombinedOutput(nameCmd)
 	if err == nil && len(nameOutput) > 0 {
 		name := strings.TrimSpace(string(nameOutput))
 		// settings returns "null" if the value is not set
@@ -526,8 +664,8 @@ func getAndroidDeviceName(deviceID string) string {
 	}
 
 	// fall back to product model
-	modelCmd := exec.Command(getAdbPath(), "-s", deviceID, "shell", "getprop", "ro.product.model")
-	modelOutput, err := modelCmd.CombinedOutput()
+	modelCmd := adbCommand("-s", deviceID, "shell", "getprop", "ro.product.model")
+	modelOutput, err := utils.CombinedOutput(modelCmd)
 	if err == nil && len(modelOutput) > 0 {
 		return strings.TrimSpace(string(modelOutput))
 	}
@@ -536,8 +674,8 @@ func getAndroidDeviceName(deviceID string) string {
 }
 
 func getAndroidDeviceModel(deviceID string) string {
-	modelCmd := exec.Command(getAdbPath(), "-s", deviceID, "shell", "getprop", "ro.product.model")
-	modelOutput, err := modelCmd.CombinedOutput()
+	modelCmd := adbCommand("-s", deviceID, "shell", "getprop", "ro.product.model")
+	modelOutput, err := utils.CombinedOutput(modelCmd)
 	if err == nil && len(modelOutput) > 0 {
 		return strings.TrimSpace(string(modelOutput))
 	}
@@ -546,8 +684,8 @@ func getAndroidDeviceModel(deviceID string) string {
 }
 
 func getAndroidDeviceVersion(deviceID string) string {
-	versionCmd := exec.Command(getAdbPath(), "-s", deviceID, "shell", "getprop", "ro.build.version.release")
-	versionOutput, err := versionCmd.CombinedOutput()
+	versionCmd := adbCommand("-s", deviceID, "shell", "getprop", "ro.build.version.release")
+	versionOutput, err := utils.CombinedOutput(versionCmd)
 	if err == nil && len(versionOutput) > 0 {
 		return strings.TrimSpace(string(versionOutput))
 	}
@@ -557,12 +695,12 @@ func getAndroidDeviceVersion(deviceID string) string {
 
 // GetAndroidDevices retrieves a list of connected Android devices
 func GetAndroidDevices() ([]ControllableDevice, error) {
-	command := exec.Command(getAdbPath(), "devices")
-	output, err := command.CombinedOutput()
+	command := adbCommand("devices", "-l")
+	output, err := utils.CombinedOutput(command)
 	if err != nil {
 		status := command.ProcessState.ExitCode()
 		if status < 0 {
-			utils.Verbose("Failed running 'adb devices', is ANDROID_HOME set correctly?")
+			utils.Debug(utils.SubsystemADB, "Failed running 'adb devices', is ANDROID_HOME set correctly?")
 			return []ControllableDevice{}, nil
 		}
 
@@ -578,6 +716,9 @@ func (d *AndroidDevice) StartAgent(config StartAgentConfig) error {
 	if d.state == "offline" {
 		return fmt.Errorf("device is offline, use 'mobilecli device boot --device %s' to start the emulator", d.id)
 	}
+	if d.state == AndroidStateUnauthorized {
+		return fmt.Errorf("device is unauthorized, allow USB debugging on it or use 'mobilecli device authorize --device %s'", d.id)
+	}
 
 	// android doesn't need an agent to be started for online devices
 	return nil
@@ -590,13 +731,17 @@ func matchesAVDName(avdName, deviceName string) bool {
 }
 
 // waitForEmulatorBootComplete waits for an emulator to appear and be fully booted
-func (d *AndroidDevice) waitForEmulatorBootComplete(ctx context.Context, avdName string) (string, error) {
+func (d *AndroidDevice) waitForEmulatorBootComplete(ctx context.Context, avdName string, config BootConfig) (string, error) {
 	ticker := time.NewTicker(2 * time.Second)
 	defer ticker.Stop()
 
+	adbDetected := false
 	for {
 		select {
 		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return "", fmt.Errorf("timed out after %s waiting for emulator to boot", config.timeout())
+			}
 			return "", fmt.Errorf("emulator boot cancelled: %w", ctx.Err())
 		case <-ticker.C:
 			// check if emulator is in device list
@@ -613,6 +758,11 @@ func (d *AndroidDevice) waitForEmulatorBootComplete(ctx context.Context, avdName
 						// found our emulator, check if it's fully booted
 						// need to get the transport ID for the boot check
 						if androidDev, ok := device.(*AndroidDevice); ok {
+							if !adbDetected {
+								adbDetected = true
+								config.progress(BootStageAdbDetected)
+							}
+
 							transportID := androidDev.transportID
 							if transportID == "" {
 								transportID = androidDev.id
@@ -630,19 +780,24 @@ func (d *AndroidDevice) waitForEmulatorBootComplete(ctx context.Context, avdName
 }
 
 // Boot launches an offline Android emulator and waits for it to be ready
-func (d *AndroidDevice) Boot() error {
+func (d *AndroidDevice) Boot(config BootConfig) error {
 	if d.state != "offline" {
 		return fmt.Errorf("emulator is already running")
 	}
-	utils.Verbose("Starting Android emulator: %s", d.id)
+	utils.Debug(utils.SubsystemADB, "Starting Android emulator: %s", d.id)
+	config.progress(BootStageBooting)
 
 	// create context with timeout for the boot wait process
-	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	ctx, cancel := context.WithTimeout(config.context(), config.timeout())
 	defer cancel()
 
-	// launch emulator in background without context (so it persists after function returns)
+	// launch emulator in background without context (so it persists after function returns),
+	// keeping the tail of its output to explain boot failures
+	output := newOutputTail(bootOutputLines)
 	cmd := exec.Command(getEmulatorPath(), "-netdelay", "none", "-netspeed", "full", "-avd", d.id, "-qt-hide-window")
-	err := cmd.Start()
+	cmd.Stdout = output
+	cmd.Stderr = output
+	err := utils.Start(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to start emulator: %w", err)
 	}
@@ -651,35 +806,39 @@ func (d *AndroidDevice) Boot() error {
 	go func() {
 		<-ctx.Done()
 		if cmd.Process != nil && ctx.Err() == context.DeadlineExceeded {
-			utils.Verbose("Boot timeout exceeded, killing emulator process")
+			utils.Debug(utils.SubsystemADB, "Boot timeout exceeded, killing emulator process")
 			_ = cmd.Process.Kill()
 		}
 	}()
 
-	utils.Verbose("Waiting for emulator to boot...")
+	utils.Debug(utils.SubsystemADB, "Waiting for emulator to boot...")
 
 	// wait for emulator to boot and get its actual device ID
-	deviceID, err := d.waitForEmulatorBootComplete(ctx, d.id)
+	deviceID, err := d.waitForEmulatorBootComplete(ctx, d.id, config)
 	if err != nil {
 		// if boot failed, kill the emulator process
 		if cmd.Process != nil {
 			_ = cmd.Process.Kill()
 		}
+		if lastLines := output.String(); lastLines != "" {
+			return fmt.Errorf("%w\nlast emulator output:\n%s", err, lastLines)
+		}
 		return err
 	}
 
-	utils.Verbose("Emulator booted successfully with transport ID: %s", deviceID)
+	utils.Debug(utils.SubsystemADB, "Emulator booted successfully with transport ID: %s", deviceID)
 	// update our transport ID to the actual emulator-XXXX ID
 	// the device ID (d.id) is already set to the AVD name and should not change
 	d.transportID = deviceID
 	d.state = "online"
+	config.progress(BootStageCompleted)
 	return nil
 }
 
 // checkBootComplete checks if an emulator has finished booting
 func (d *AndroidDevice) checkBootComplete(deviceID string) (bool, error) {
-	cmd := exec.Command(getAdbPath(), "-s", deviceID, "shell", "getprop", "sys.boot_completed")
-	output, err := cmd.CombinedOutput()
+	cmd := adbCommand("-s", deviceID, "shell", "getprop", "sys.boot_completed")
+	output, err := utils.CombinedOutput(cmd)
 	if err != nil {
 		return false, err
 	}
@@ -688,25 +847,9 @@ func (d *AndroidDevice) checkBootComplete(deviceID string) (bool, error) {
 }
 
 func (d *AndroidDevice) PressButton(key string) error {
-	keyMap := map[string]string{
-		"HOME":        "KEYCODE_HOME",
-		"BACK":        "KEYCODE_BACK",
-		"VOLUME_UP":   "KEYCODE_VOLUME_UP",
-		"VOLUME_DOWN": "KEYCODE_VOLUME_DOWN",
-		"ENTER":       "KEYCODE_ENTER",
-		"DPAD_CENTER": "KEYCODE_DPAD_CENTER",
-		"DPAD_UP":     "KEYCODE_DPAD_UP",
-		"DPAD_DOWN":   "KEYCODE_DPAD_DOWN",
-		"DPAD_LEFT":   "KEYCODE_DPAD_LEFT",
-		"DPAD_RIGHT":  "KEYCODE_DPAD_RIGHT",
-		"BACKSPACE":   "KEYCODE_DEL",
-		"APP_SWITCH":  "KEYCODE_APP_SWITCH",
-		"POWER":       "KEYCODE_POWER",
-	}
-
-	keycode, exists := keyMap[key]
-	if !exists {
-		return fmt.Errorf("AndroidDevice: unsupported button key: %s", key)
+	keycode, err := androidButtonKeycode(key)
+	if err != nil {
+		return err
 	}
 
 	output, err := d.runAdbCommand("shell", "input", "keyevent", keycode)
@@ -717,6 +860,16 @@ func (d *AndroidDevice) PressButton(key string) error {
 	return nil
 }
 
+// PressKeycode sends a raw Android keycode, for keys with no button name
+func (d *AndroidDevice) PressKeycode(keycode int) error {
+	output, err := d.runAdbCommand("shell", "input", "keyevent", strconv.Itoa(keycode))
+	if err != nil {
+		return fmt.Errorf("AndroidDevice: failed to press keycode %d: %v\nOutput: %s", keycode, err, string(output))
+	}
+
+	return nil
+}
+
 // androidModifierKeycodes maps canonical modifier names to Android keycodes
 var androidModifierKeycodes = map[string]string{
 	"command": "KEYCODE_META_LEFT",
@@ -868,31 +1021,7 @@ func (d *AndroidDevice) SendKeys(text string) error {
 		return err
 	}
 
-	// try sending over clipboard if DeviceKit is installed
-	if d.isDeviceKitInstalled() {
-		// ensure clipboard is always cleared, even on failure
-		defer func() {
-			_, _ = d.runAdbCommand("shell", "am", "broadcast", "-a", "devicekit.clipboard.clear", "-n", "com.mobilenext.devicekit/.ClipboardBroadcastReceiver")
-		}()
-
-		// encode text as base64
-		base64Text := base64.StdEncoding.EncodeToString([]byte(text))
-
-		// send clipboard over and immediately paste it
-		_, err := d.runAdbCommand("shell", "am", "broadcast", "-a", "devicekit.clipboard.set", "-e", "encoding", "base64", "-e", "text", base64Text, "-n", "com.mobilenext.devicekit/.ClipboardBroadcastReceiver")
-		if err != nil {
-			return fmt.Errorf("failed to set clipboard: %w", err)
-		}
-
-		_, err = d.runAdbCommand("shell", "input", "keyevent", "KEYCODE_PASTE")
-		if err != nil {
-			return fmt.Errorf("failed to paste: %w", err)
-		}
-
-		return nil
-	}
-
-	return fmt.Errorf("non-ASCII text is not supported on Android, please install mobilenext devicekit, see https://github.com/mobile-next/devicekit-android")
+	return d.sendNonAsciiKeys(text)
 }
 
 func (d *AndroidDevice) OpenURL(url string) error {
@@ -905,14 +1034,20 @@ func (d *AndroidDevice) OpenURL(url string) error {
 }
 
 func (d *AndroidDevice) ListApps(onlyLaunchable bool) ([]InstalledAppInfo, error) {
-	if onlyLaunchable {
-		return d.listLaunchableApps()
+	return d.ListAppsForUser("", onlyLaunchable)
+}
+
+// userArgs returns the --user flag for a user, none for the default user
+func userArgs(user string) []string {
+	if user == "" {
+		return nil
 	}
-	return d.listAllPackages()
+	return []string{"--user", user}
 }
 
-func (d *AndroidDevice) listLaunchableApps() ([]InstalledAppInfo, error) {
-	output, err := d.runAdbCommand("shell", "cmd", "package", "query-activities", "-a", "android.intent.action.MAIN", "-c", "android.intent.category.LAUNCHER")
+func (d *AndroidDevice) listLaunchableApps(user string) ([]InstalledAppInfo, error) {
+	args := append([]string{"shell", "cmd", "package", "query-activities"}, userArgs(user)...)
+	output, err := d.runAdbCommand(append(args, "-a", "android.intent.action.MAIN", "-c", "android.intent.category.LAUNCHER")...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query launcher activities: %v", err)
 	}
@@ -943,8 +1078,8 @@ func (d *AndroidDevice) listLaunchableApps() ([]InstalledAppInfo, error) {
 	return apps, nil
 }
 
-func (d *AndroidDevice) listAllPackages() ([]InstalledAppInfo, error) {
-	output, err := d.runAdbCommand("shell", "pm", "list", "packages")
+func (d *AndroidDevice) listAllPackages(user string) ([]InstalledAppInfo, error) {
+	output, err := d.runAdbCommand(append([]string{"shell", "pm", "list", "packages"}, userArgs(user)...)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list packages: %w", err)
 	}
@@ -1040,38 +1175,95 @@ func (d *AndroidDevice) GetForegroundApp() (*ForegroundAppInfo, error) {
 	}, nil
 }
 
-func (d *AndroidDevice) Info() (*FullDeviceInfo, error) {
+// surfaceOrientationRe matches the display rotation 'dumpsys input' reports,
+// e.g. "SurfaceOrientation: 1"
+var surfaceOrientationRe = regexp.MustCompile(`SurfaceOrientation:\s*([0-3])`)
 
+// parseSurfaceOrientation returns the display rotation in 'dumpsys input'
+// output, in quarter turns
+func parseSurfaceOrientation(output string) (int, bool) {
+	matches := surfaceOrientationRe.FindStringSubmatch(output)
+	if matches == nil {
+		return 0, false
+	}
+	rotation, err := strconv.Atoi(matches[1])
+	return rotation, err == nil
+}
+
+// displayRotation returns the rotation the display is in, which auto-rotate
+// may have set rather than user_rotation, in quarter turns
+func (d *AndroidDevice) displayRotation() int {
+	if output, err := d.runAdbCommand("shell", "dumpsys", "input"); err == nil {
+		if rotation, ok := parseSurfaceOrientation(string(output)); ok {
+			return rotation
+		}
+	}
+
+	orientation, err := d.GetOrientation()
+	if err != nil {
+		return 0
+	}
+	return OrientationRotation(orientation)
+}
+
+// rotateScreenSize turns the natural, portrait size 'wm size' reports into
+// the size of the screen as rotated
+func rotateScreenSize(width, height, rotation int) (int, int) {
+	if rotation%2 == 1 {
+		return height, width
+	}
+	return width, height
+}
+
+// naturalScreenSize returns the unrotated, portrait screen size 'wm size'
+// reports
+func (d *AndroidDevice) naturalScreenSize() (int, int, error) {
 	// run adb shell wm size
 	output, err := d.runAdbCommand("shell", "wm", "size")
 	if err != nil {
-		return nil, fmt.Errorf("failed to get screen size: %v", err)
+		return 0, 0, fmt.Errorf("failed to get screen size: %v", err)
 	}
 
 	// split result by space, and then take 2nd argument split by "x"
 	screenSize := strings.Split(string(output), " ")
 	pair := strings.Trim(screenSize[len(screenSize)-1], "\r\n")
 	parts := strings.SplitN(pair, "x", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("failed to get screen size: unexpected output %q", string(output))
+	}
 
 	widthInt, err := strconv.Atoi(parts[0])
 	if err != nil {
-		return nil, fmt.Errorf("failed to get screen size: %v", err)
+		return 0, 0, fmt.Errorf("failed to get screen size: %v", err)
 	}
 
 	heightInt, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return nil, fmt.Errorf("failed to get screen size: %v", err)
+		return 0, 0, fmt.Errorf("failed to get screen size: %v", err)
 	}
 
+	return widthInt, heightInt, nil
+}
+
+func (d *AndroidDevice) Info() (*FullDeviceInfo, error) {
+	widthInt, heightInt, err := d.naturalScreenSize()
+	if err != nil {
+		return nil, err
+	}
+
+	// taps and swipes are in the coordinates of the rotated screen
+	widthInt, heightInt = rotateScreenSize(widthInt, heightInt, d.displayRotation())
+
 	return &FullDeviceInfo{
 		DeviceInfo: DeviceInfo{
-			ID:       d.ID(),
-			Name:     d.Name(),
-			Platform: d.Platform(),
-			Type:     d.DeviceType(),
-			Version:  d.Version(),
-			State:    d.State(),
-			Model:    d.model,
+			ID:        d.ID(),
+			Name:      d.Name(),
+			Platform:  d.Platform(),
+			Type:      d.DeviceType(),
+			Version:   d.Version(),
+			State:     d.State(),
+			Model:     d.model,
+			Transport: d.Transport(),
 		},
 		ScreenSize: &ScreenSize{
 			Width:  widthInt,
@@ -1120,7 +1312,7 @@ func (d *AndroidDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 		config.OnProgress("Installing Agent")
 	}
 
-	utils.Verbose("Ensuring DeviceKit is installed...")
+	utils.Debug(utils.SubsystemADB, "Ensuring DeviceKit is installed...")
 	err := d.EnsureDeviceKitInstalled()
 	if err != nil {
 		return fmt.Errorf("failed to ensure DeviceKit is installed: %v", err)
@@ -1142,22 +1334,23 @@ func (d *AndroidDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 		config.OnProgress("Starting Agent")
 	}
 
-	utils.Verbose("Starting %s with app path: %s", serverClass, appPath)
+	utils.Debug(utils.SubsystemADB, "Starting %s with app path: %s", serverClass, appPath)
 	cmdArgs := append([]string{"-s", d.getAdbIdentifier()}, "exec-out", fmt.Sprintf("CLASSPATH=%s", appPath), "app_process", "/system/bin", serverClass, "--quality", fmt.Sprintf("%d", config.Quality), "--scale", fmt.Sprintf("%.2f", config.Scale), "--fps", fmt.Sprintf("%d", config.FPS))
 
 	// bitrate only applies to AvcServer
 	if config.Format == "avc" && config.Bitrate > 0 {
 		cmdArgs = append(cmdArgs, "--bitrate", fmt.Sprintf("%d", config.Bitrate))
 	}
-	utils.Verbose("Running command: %s %s", getAdbPath(), strings.Join(cmdArgs, " "))
-	cmd := exec.Command(getAdbPath(), cmdArgs...)
+	utils.Debug(utils.SubsystemADB, "Running command: %s %s", getAdbPath(), strings.Join(cmdArgs, " "))
+	// cancelling the context kills adb, which ends the device-side server too
+	cmd := adbCommandContext(config.context(), cmdArgs...)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("failed to create stdout pipe: %v", err)
 	}
 
-	if err := cmd.Start(); err != nil {
+	if err := utils.Start(cmd); err != nil {
 		return fmt.Errorf("failed to start %s: %v", serverClass, err)
 	}
 
@@ -1178,6 +1371,7 @@ func (d *AndroidDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 	}
 
 	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
 	return nil
 }
 
@@ -1197,8 +1391,8 @@ func (d *AndroidDevice) ScreenRecord(localOutput string, timeLimit int, stopChan
 	}
 	args = append(args, remotePath)
 
-	utils.Verbose("Running: %s %s", getAdbPath(), strings.Join(args, " "))
-	cmd := exec.Command(getAdbPath(), args...)
+	utils.Debug(utils.SubsystemADB, "Running: %s %s", getAdbPath(), strings.Join(args, " "))
+	cmd := adbCommand(args...)
 
 	// handle Ctrl+C / stop: signal the on-device screenrecord process so it
 	// finalizes the MP4. signaling the local adb client does not propagate to
@@ -1206,7 +1400,7 @@ func (d *AndroidDevice) ScreenRecord(localOutput string, timeLimit int, stopChan
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	if err := cmd.Start(); err != nil {
+	if err := utils.Start(cmd); err != nil {
 		signal.Stop(sigChan)
 		return fmt.Errorf("failed to start screenrecord: %w", err)
 	}
@@ -1228,8 +1422,8 @@ func (d *AndroidDevice) ScreenRecord(localOutput string, timeLimit int, stopChan
 	close(sigChan)
 
 	// pull the recording from device
-	utils.Verbose("Pulling recording from device...")
-	pullOutput, err := d.runAdbCommand("pull", remotePath, localOutput)
+	utils.Debug(utils.SubsystemADB, "Pulling recording from device...")
+	pullOutput, err := d.runAdbCommandTimeout(longCommandTimeout, "pull", remotePath, localOutput)
 	if err != nil {
 		return fmt.Errorf("failed to pull recording: %w\n%s", err, string(pullOutput))
 	}
@@ -1247,18 +1441,18 @@ func (d *AndroidDevice) ScreenRecord(localOutput string, timeLimit int, stopChan
 func (d *AndroidDevice) signalRemoteScreenRecord(remotePath string) {
 	out, err := d.runAdbCommand("shell", "pgrep", "-f", remotePath)
 	if err != nil {
-		utils.Verbose("failed to find remote screenrecord process: %v", err)
+		utils.Debug(utils.SubsystemADB, "failed to find remote screenrecord process: %v", err)
 		return
 	}
 
 	pids := strings.Fields(string(out))
 	if len(pids) == 0 {
-		utils.Verbose("no remote screenrecord process found for %s", remotePath)
+		utils.Debug(utils.SubsystemADB, "no remote screenrecord process found for %s", remotePath)
 		return
 	}
 
 	if _, err := d.runAdbCommand(append([]string{"shell", "kill", "-INT"}, pids...)...); err != nil {
-		utils.Verbose("failed to signal remote screenrecord: %v", err)
+		utils.Debug(utils.SubsystemADB, "failed to signal remote screenrecord: %v", err)
 	}
 }
 
@@ -1285,16 +1479,34 @@ func (d *AndroidDevice) EnsureDeviceKitInstalled() error {
 
 	if appPath != "" {
 		// already installed, we have a path to .apk
-		return nil
+		version, err := d.GetAppVersion(packageName)
+		if err != nil {
+			utils.Debug(utils.SubsystemADB, "failed to get DeviceKit version: %v", err)
+		}
+		return CheckAgentVersion(d, version)
 	}
 
-	utils.Verbose("DeviceKit not installed, downloading and installing...")
-
-	downloadURL, err := utils.GetLatestReleaseDownloadURL("mobile-next/devicekit-android")
+	pins, err := LoadAgentPins()
 	if err != nil {
-		return fmt.Errorf("failed to get download URL: %v", err)
+		return err
 	}
-	utils.Verbose("Downloading APK from: %s", downloadURL)
+
+	var downloadURL string
+	if pinned := pins.DeviceKitVersion; pinned != "" {
+		// never fall back to the latest release when a version is pinned
+		if err := CheckAgentCompatibility("android", pinned, d.Version()); err != nil {
+			return err
+		}
+		utils.Debug(utils.SubsystemADB, "DeviceKit not installed, downloading and installing pinned version %s...", pinned)
+		downloadURL = fmt.Sprintf("https://github.com/mobile-next/devicekit-android/releases/download/%s/devicekit.apk", pinned)
+	} else {
+		utils.Debug(utils.SubsystemADB, "DeviceKit not installed, downloading and installing...")
+		downloadURL, err = utils.GetLatestReleaseDownloadURL("mobile-next/devicekit-android")
+		if err != nil {
+			return fmt.Errorf("failed to get download URL: %v", err)
+		}
+	}
+	utils.Debug(utils.SubsystemADB, "Downloading APK from: %s", downloadURL)
 
 	tempDir, err := os.MkdirTemp("", "devicekit-android-*")
 	if err != nil {
@@ -1308,7 +1520,7 @@ func (d *AndroidDevice) EnsureDeviceKitInstalled() error {
 		return fmt.Errorf("failed to download APK: %v", err)
 	}
 
-	utils.Verbose("Installing APK...")
+	utils.Debug(utils.SubsystemADB, "Installing APK...")
 	if err := d.installPackage(apkPath); err != nil {
 		return fmt.Errorf("failed to install APK: %v", err)
 	}
@@ -1322,7 +1534,7 @@ func (d *AndroidDevice) EnsureDeviceKitInstalled() error {
 		return fmt.Errorf("package %s was not installed successfully", packageName)
 	}
 
-	utils.Verbose("DeviceKit successfully installed")
+	utils.Debug(utils.SubsystemADB, "DeviceKit successfully installed")
 	return nil
 }
 
@@ -1587,7 +1799,7 @@ func (d *AndroidDevice) DumpSourceRaw() (any, error) {
 	if jsonStr, err := d.getDeviceKitDump(); err == nil {
 		return jsonStr, nil
 	} else {
-		utils.Verbose("devicekit dump unavailable, falling back to uiautomator: %v", err)
+		utils.Debug(utils.SubsystemADB, "devicekit dump unavailable, falling back to uiautomator: %v", err)
 	}
 
 	xmlContent, err := d.getUiAutomatorDump()
@@ -1599,10 +1811,19 @@ func (d *AndroidDevice) DumpSourceRaw() (any, error) {
 }
 
 func (d *AndroidDevice) DumpSource() ([]ScreenElement, error) {
+	return d.DumpSourceWithLimits(DumpLimits{})
+}
+
+// DumpSourceWithLimits dumps the UI tree, traversing the view hierarchy only
+// down to limits.MaxDepth
+func (d *AndroidDevice) DumpSourceWithLimits(limits DumpLimits) ([]ScreenElement, error) {
 	if nodes, err := d.getDeviceKitNodes(); err == nil {
+		if limits.MaxDepth > 0 {
+			nodes = truncateDeviceKitNodes(nodes, limits.MaxDepth)
+		}
 		return collectDeviceKitElements(nodes), nil
 	} else {
-		utils.Verbose("devicekit dump unavailable, falling back to uiautomator: %v", err)
+		utils.Debug(utils.SubsystemADB, "devicekit dump unavailable, falling back to uiautomator: %v", err)
 	}
 
 	xmlContent, err := d.getUiAutomatorDump()
@@ -1615,20 +1836,44 @@ func (d *AndroidDevice) DumpSource() ([]ScreenElement, error) {
 		return nil, fmt.Errorf("failed to parse uiautomator XML: %w", err)
 	}
 
-	return d.collectElements(uiXml.RootNode), nil
+	root := uiXml.RootNode
+	if limits.MaxDepth > 0 {
+		root = truncateUiAutomatorNode(root, limits.MaxDepth)
+	}
+	return d.collectElements(root), nil
 }
 
-func (d *AndroidDevice) InstallApp(path string) error {
-	output, err := d.runAdbCommand("install", "-r", path)
-	if err != nil {
-		return fmt.Errorf("failed to install app: %v\nOutput: %s", err, string(output))
+// truncateUiAutomatorNode drops the nodes below depth levels of the tree
+func truncateUiAutomatorNode(node uiAutomatorXmlNode, depth int) uiAutomatorXmlNode {
+	if depth <= 1 {
+		node.Nodes = nil
+		return node
 	}
 
-	if strings.Contains(string(output), "Success") {
-		return nil
+	children := make([]uiAutomatorXmlNode, len(node.Nodes))
+	for i, child := range node.Nodes {
+		children[i] = truncateUiAutomatorNode(child, depth-1)
 	}
+	node.Nodes = children
+	return node
+}
 
-	return fmt.Errorf("installation failed: %s", string(output))
+// truncateDeviceKitNodes drops the nodes below depth levels of the trees
+func truncateDeviceKitNodes(nodes []deviceKitNode, depth int) []deviceKitNode {
+	truncated := make([]deviceKitNode, len(nodes))
+	for i, node := range nodes {
+		if depth <= 1 {
+			node.Children = nil
+		} else {
+			node.Children = truncateDeviceKitNodes(node.Children, depth-1)
+		}
+		truncated[i] = node
+	}
+	return truncated
+}
+
+func (d *AndroidDevice) InstallApp(path string) error {
+	return d.installAppFile(path, InstallConfig{})
 }
 
 func (d *AndroidDevice) UninstallApp(packageName string) (*InstalledAppInfo, error) {
@@ -1661,33 +1906,23 @@ func (d *AndroidDevice) GetOrientation() (string, error) {
 		return "", fmt.Errorf("failed to parse orientation value '%s': %v", rotationStr, err)
 	}
 
-	// convert Android rotation values to string
-	switch rotation {
-	case 0, 2:
-		return "portrait", nil
-	case 1, 3:
-		return "landscape", nil
-	default:
-		return "portrait", nil // default to portrait
+	orientation, err := RotationOrientation(rotation)
+	if err != nil {
+		return OrientationPortrait, nil // default to portrait
 	}
+	return orientation, nil
 }
 
 // SetOrientation sets the device orientation
 func (d *AndroidDevice) SetOrientation(orientation string) error {
-	if orientation != "portrait" && orientation != "landscape" {
-		return fmt.Errorf("invalid orientation value '%s', must be 'portrait' or 'landscape'", orientation)
-	}
-
-	var androidRotation int
-	switch orientation {
-	case "portrait":
-		androidRotation = 0
-	case "landscape":
-		androidRotation = 1 // landscape left
+	orientation, err := NormalizeOrientation(orientation)
+	if err != nil {
+		return err
 	}
+	androidRotation := OrientationRotation(orientation)
 
 	// disable auto-rotation first
-	_, err := d.runAdbCommand("shell", "settings", "put", "system", "accelerometer_rotation", "0")
+	_, err = d.runAdbCommand("shell", "settings", "put", "system", "accelerometer_rotation", "0")
 	if err != nil {
 		return fmt.Errorf("failed to disable auto-rotation: %v", err)
 	}
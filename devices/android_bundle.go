@@ -0,0 +1,283 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// BundletoolEnvVar points at the bundletool jar or executable used to build
+// apks from an .aab; bundletool on PATH is used when it is not set
+const BundletoolEnvVar = "BUNDLETOOL"
+
+// apksABISplits maps the ABI names bundletool uses in split file names to
+// the ABI names a device reports in ro.product.cpu.abilist
+var apksABISplits = map[string]string{
+	"armeabi":     "armeabi",
+	"armeabi_v7a": "armeabi-v7a",
+	"arm64_v8a":   "arm64-v8a",
+	"x86":         "x86",
+	"x86_64":      "x86_64",
+	"mips":        "mips",
+	"mips64":      "mips64",
+}
+
+// installFlags returns the 'adb install' flags for the Android install options
+func (c InstallConfig) installFlags() []string {
+	flags := []string{"-r"}
+	if c.GrantPermissions {
+		flags = append(flags, "-g")
+	}
+	if c.AllowTest {
+		flags = append(flags, "-t")
+	}
+	if c.Instant {
+		flags = append(flags, "--instant")
+	}
+	if c.AllowDowngrade {
+		flags = append(flags, "-d")
+	}
+	if c.User != "" {
+		flags = append(flags, "--user", c.User)
+	}
+	return flags
+}
+
+// installAppFile installs an .apk, an .apks archive or an .aab bundle
+func (d *AndroidDevice) installAppFile(path string, config InstallConfig) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".apks":
+		return d.installApks(path, config)
+	case ".aab":
+		return d.installBundle(path, config)
+	default:
+		return d.adbInstall([]string{path}, config)
+	}
+}
+
+// adbInstall installs one apk with 'adb install', or the apks of a split
+// app together with 'adb install-multiple'
+func (d *AndroidDevice) adbInstall(paths []string, config InstallConfig) error {
+	command := "install"
+	if len(paths) > 1 {
+		command = "install-multiple"
+	}
+
+	args := append([]string{command}, config.installFlags()...)
+	args = append(args, paths...)
+
+	output, err := d.runAdbCommandTimeout(longCommandTimeout, args...)
+	return installResult(output, err)
+}
+
+// installApks installs the apks of a bundletool .apks archive that match
+// the device's ABIs
+func (d *AndroidDevice) installApks(path string, config InstallConfig) error {
+	dir, err := utils.Unzip(path)
+	if err != nil {
+		return fmt.Errorf("failed to extract apks: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var names []string
+	err = filepath.Walk(dir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(file), ".apk") {
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read apks: %w", err)
+	}
+
+	output, err := d.runAdbCommand("shell", "getprop", "ro.product.cpu.abilist")
+	if err != nil {
+		return fmt.Errorf("failed to get device ABIs: %w", err)
+	}
+
+	var abis []string
+	for _, abi := range strings.Split(strings.TrimSpace(string(output)), ",") {
+		if abi != "" {
+			abis = append(abis, abi)
+		}
+	}
+
+	selected, err := selectApksSplits(names, abis, config.Instant)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, len(selected))
+	for i, name := range selected {
+		paths[i] = filepath.Join(dir, filepath.FromSlash(name))
+	}
+
+	utils.Debug(utils.SubsystemADB, "installing splits: %s", strings.Join(selected, ", "))
+	return d.adbInstall(paths, config)
+}
+
+// selectApksSplits picks the apks to install from the entries of a
+// bundletool .apks archive. A universal apk is installed on its own;
+// otherwise all splits are installed except ABI splits other than the
+// device's most preferred ABI each module provides.
+func selectApksSplits(names []string, abis []string, instant bool) ([]string, error) {
+	for _, name := range names {
+		if name == "universal.apk" {
+			return []string{name}, nil
+		}
+	}
+
+	dir := "splits/"
+	if instant {
+		dir = "instant/"
+	}
+
+	var splits []string
+	for _, name := range names {
+		if strings.HasPrefix(name, dir) {
+			splits = append(splits, name)
+		}
+	}
+
+	if len(splits) == 0 {
+		return nil, fmt.Errorf("no apks found in %s of the archive", strings.TrimSuffix(dir, "/"))
+	}
+
+	abiRank := map[string]int{}
+	for i, abi := range abis {
+		abiRank[abi] = i
+	}
+
+	// the preferred ABI split of each module, by index into splits
+	bestABI := map[string]int{}
+	var selected []string
+	for i, split := range splits {
+		module, abi, ok := splitABI(split)
+		if !ok {
+			selected = append(selected, split)
+			continue
+		}
+
+		rank, supported := abiRank[abi]
+		if len(abis) > 0 && !supported {
+			continue
+		}
+
+		best, seen := bestABI[module]
+		if !seen {
+			bestABI[module] = i
+			continue
+		}
+
+		_, bestAbi, _ := splitABI(splits[best])
+		if rank < abiRank[bestAbi] {
+			bestABI[module] = i
+		}
+	}
+
+	for _, i := range bestABI {
+		selected = append(selected, splits[i])
+	}
+
+	sort.Strings(selected)
+	return selected, nil
+}
+
+// splitABI returns the module and device ABI of an ABI split named like
+// splits/base-arm64_v8a.apk
+func splitABI(name string) (string, string, bool) {
+	base := strings.TrimSuffix(filepath.Base(name), ".apk")
+	i := strings.LastIndex(base, "-")
+	if i < 0 {
+		return "", "", false
+	}
+
+	abi, ok := apksABISplits[base[i+1:]]
+	return base[:i], abi, ok
+}
+
+// installBundle builds an .apks archive from an .aab with bundletool and
+// installs it
+func (d *AndroidDevice) installBundle(path string, config InstallConfig) error {
+	tmpDir, err := os.MkdirTemp("", "mobilecli-aab-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	apksPath := filepath.Join(tmpDir, "app.apks")
+	args := []string{"build-apks", "--bundle=" + path, "--output=" + apksPath}
+	if ks := config.Keystore; ks.Path != "" {
+		args = append(args, "--ks="+ks.Path)
+		if ks.Password != "" {
+			passwordFile, err := writePasswordFile(tmpDir, "ks-pass", ks.Password)
+			if err != nil {
+				return err
+			}
+			args = append(args, "--ks-pass=file:"+passwordFile)
+		}
+		if ks.KeyAlias != "" {
+			args = append(args, "--ks-key-alias="+ks.KeyAlias)
+		}
+		if ks.KeyPassword != "" {
+			passwordFile, err := writePasswordFile(tmpDir, "key-pass", ks.KeyPassword)
+			if err != nil {
+				return err
+			}
+			args = append(args, "--key-pass=file:"+passwordFile)
+		}
+	}
+
+	cmd, err := bundletoolCommand(args...)
+	if err != nil {
+		return err
+	}
+
+	utils.Debug(utils.SubsystemADB, "building apks from %s", path)
+	if output, err := utils.CombinedOutput(utils.WithTimeout(cmd, longCommandTimeout)); err != nil {
+		return fmt.Errorf("bundletool failed to build apks: %v\nOutput: %s", err, string(output))
+	}
+
+	return d.installApks(apksPath, config)
+}
+
+// writePasswordFile writes a keystore password for bundletool to read, so it
+// isn't on the command line where other users of the host can see it
+func writePasswordFile(dir, name, password string) (string, error) {
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(password), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write keystore password: %w", err)
+	}
+	return path, nil
+}
+
+// bundletoolCommand returns a command running bundletool, which may be a
+// jar run with java or an executable wrapper
+func bundletoolCommand(args ...string) (*exec.Cmd, error) {
+	bundletool := os.Getenv(BundletoolEnvVar)
+	if bundletool == "" {
+		path, err := exec.LookPath("bundletool")
+		if err != nil {
+			return nil, fmt.Errorf("installing an .aab requires bundletool, install it or set %s to its path", BundletoolEnvVar)
+		}
+		bundletool = path
+	}
+
+	if strings.HasSuffix(strings.ToLower(bundletool), ".jar") {
+		return exec.Command("java", append([]string{"-jar", bundletool}, args...)...), nil
+	}
+
+	return exec.Command(bundletool, args...), nil
+}
@@ -0,0 +1,142 @@
+package devices
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdb records the commands run through it and answers them with output
+type fakeAdb struct {
+	utils.ExecRunner
+	output string
+	args   [][]string
+}
+
+func (f *fakeAdb) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	f.args = append(f.args, cmd.Args[1:])
+	return []byte(f.output), nil
+}
+
+func TestSelectApksSplits(t *testing.T) {
+	names := []string{
+		"toc.pb",
+		"splits/base-master.apk",
+		"splits/base-arm64_v8a.apk",
+		"splits/base-armeabi_v7a.apk",
+		"splits/base-x86_64.apk",
+		"splits/base-xxhdpi.apk",
+		"splits/base-en.apk",
+		"splits/camera-master.apk",
+		"splits/camera-armeabi_v7a.apk",
+		"instant/base-master.apk",
+	}
+
+	selected, err := selectApksSplits(names, []string{"arm64-v8a", "armeabi-v7a", "armeabi"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"splits/base-arm64_v8a.apk",
+		"splits/base-en.apk",
+		"splits/base-master.apk",
+		"splits/base-xxhdpi.apk",
+		"splits/camera-armeabi_v7a.apk",
+		"splits/camera-master.apk",
+	}, selected)
+
+	selected, err = selectApksSplits(names, []string{"arm64-v8a"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"instant/base-master.apk"}, selected)
+}
+
+func TestSelectApksSplitsUniversal(t *testing.T) {
+	selected, err := selectApksSplits([]string{"toc.pb", "universal.apk"}, []string{"x86_64"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"universal.apk"}, selected)
+}
+
+func TestSelectApksSplitsEmpty(t *testing.T) {
+	_, err := selectApksSplits([]string{"standalones/standalone-x86.apk"}, nil, false)
+	assert.Error(t, err)
+}
+
+func TestInstallConfigFlags(t *testing.T) {
+	assert.Equal(t, []string{"-r"}, InstallConfig{}.installFlags())
+	assert.Equal(t, []string{"-r", "-g", "-t", "--instant"}, InstallConfig{GrantPermissions: true, AllowTest: true, Instant: true}.installFlags())
+	assert.Equal(t, []string{"-r", "-d", "--user", "10"}, InstallConfig{AllowDowngrade: true, User: "10"}.installFlags())
+	assert.False(t, InstallConfig{}.HasAndroidOptions())
+	assert.True(t, InstallConfig{User: "0"}.HasAndroidOptions())
+	assert.True(t, InstallConfig{Keystore: KeystoreConfig{Path: "release.jks"}}.HasAndroidOptions())
+}
+
+func TestAdbInstall(t *testing.T) {
+	adb := &fakeAdb{output: "Performing Streamed Install\nSuccess\n"}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "Pixel_8", transportID: "emulator-5554"}
+	require.NoError(t, d.adbInstall([]string{"base.apk"}, InstallConfig{GrantPermissions: true}))
+	require.NoError(t, d.adbInstall([]string{"base.apk", "split.apk"}, InstallConfig{}))
+
+	assert.Equal(t, [][]string{
+		{"-s", "emulator-5554", "install", "-r", "-g", "base.apk"},
+		{"-s", "emulator-5554", "install-multiple", "-r", "base.apk", "split.apk"},
+	}, adb.args)
+
+	adb.output = "Failure [INSTALL_FAILED_OLDER_SDK]"
+	err := d.adbInstall([]string{"base.apk"}, InstallConfig{})
+	assert.ErrorContains(t, err, "INSTALL_FAILED_OLDER_SDK")
+}
+
+// fakeBundletool reads the password files bundletool is given while they
+// exist
+type fakeBundletool struct {
+	utils.ExecRunner
+	args      []string
+	passwords map[string]string
+}
+
+func (f *fakeBundletool) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	f.args = cmd.Args[1:]
+	for _, arg := range f.args {
+		if name, path, ok := strings.Cut(arg, "=file:"); ok {
+			data, _ := os.ReadFile(path)
+			f.passwords[name] = string(data)
+		}
+	}
+	return nil, nil
+}
+
+func TestInstallBundlePasswordsNotOnCommandLine(t *testing.T) {
+	t.Setenv(BundletoolEnvVar, "bundletool")
+	bundletool := &fakeBundletool{passwords: map[string]string{}}
+	previous := utils.SetCommandRunner(bundletool)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "Pixel_8", transportID: "emulator-5554"}
+	_ = d.installBundle("app.aab", InstallConfig{Keystore: KeystoreConfig{Path: "release.jks", Password: "store-secret", KeyAlias: "release", KeyPassword: "key-secret"}})
+
+	for _, arg := range bundletool.args {
+		assert.NotContains(t, arg, "secret")
+	}
+	assert.Equal(t, map[string]string{"--ks-pass": "store-secret", "--key-pass": "key-secret"}, bundletool.passwords)
+}
+
+func TestParseInstallFailure(t *testing.T) {
+	failure := parseInstallFailure("Performing Streamed Install\nadb: failed to install app.apk: Failure [INSTALL_FAILED_VERSION_DOWNGRADE: Downgrade detected: Update version code 1 is older than current 2]\n")
+	require.NotNil(t, failure)
+	assert.Equal(t, "INSTALL_FAILED_VERSION_DOWNGRADE", failure.Code)
+	assert.Equal(t, "Downgrade detected: Update version code 1 is older than current 2", failure.Message)
+	assert.Contains(t, failure.Hint, "--downgrade")
+
+	failure = parseInstallFailure("Failure [INSTALL_PARSE_FAILED_NO_CERTIFICATES]")
+	require.NotNil(t, failure)
+	assert.Equal(t, "INSTALL_PARSE_FAILED_NO_CERTIFICATES", failure.Code)
+	assert.Empty(t, failure.Message)
+
+	assert.Nil(t, parseInstallFailure("Performing Streamed Install\nSuccess\n"))
+}
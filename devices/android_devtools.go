@@ -0,0 +1,290 @@
+package devices
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// DevToolsTarget is a page, tab or webview that can be inspected over the
+// Chrome DevTools protocol or the WebKit remote inspector
+type DevToolsTarget struct {
+	// ID is "<socket>:<target id>" on Android, see parseDevToolsTargetID,
+	// and "<app id>/<page id>" on iOS, see webInspectorTargetID
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	URL   string `json:"url"`
+
+	// Socket is the devtools socket of the browser or app, e.g.
+	// chrome_devtools_remote or webview_devtools_remote_<pid>
+	Socket   string `json:"socket,omitempty"`
+	BundleID string `json:"bundleId,omitempty"`
+	PID      int    `json:"pid,omitempty"`
+}
+
+// devToolsSocketRe matches the abstract sockets of Chrome, Chromium based
+// browsers and debuggable webviews in /proc/net/unix
+var devToolsSocketRe = regexp.MustCompile(`@(\S*devtools_remote(?:_(\d+))?)$`)
+
+// devToolsTimeout bounds DevTools HTTP and protocol calls
+const devToolsTimeout = 10 * time.Second
+
+// parseDevToolsSockets returns the devtools sockets listed in /proc/net/unix,
+// with the pid of webview sockets
+func parseDevToolsSockets(procNetUnix string) map[string]int {
+	sockets := map[string]int{}
+	for line := range strings.SplitSeq(procNetUnix, "\n") {
+		matches := devToolsSocketRe.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		pid, _ := strconv.Atoi(matches[2])
+		sockets[matches[1]] = pid
+	}
+	return sockets
+}
+
+// parseDevToolsTargetID splits a target ID into its socket and the target's
+// own ID
+func parseDevToolsTargetID(targetID string) (string, string, error) {
+	socket, id, found := strings.Cut(targetID, ":")
+	if !found || socket == "" || id == "" {
+		return "", "", fmt.Errorf("invalid target '%s', expected an id from 'webview list --devtools'", targetID)
+	}
+	return socket, id, nil
+}
+
+// forwardDevToolsSocket returns a host TCP port forwarded to a devtools
+// socket, reusing an existing forward
+func (d *AndroidDevice) forwardDevToolsSocket(socket string) (int, error) {
+	target := "localabstract:" + socket
+	if port := d.findForward(target); port != 0 {
+		return port, nil
+	}
+
+	out, err := d.runAdbCommand("forward", "tcp:0", target)
+	if err != nil {
+		return 0, fmt.Errorf("adb forward %s: %s: %w", socket, strings.TrimSpace(string(out)), err)
+	}
+	port, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected adb forward output %q: %w", strings.TrimSpace(string(out)), err)
+	}
+	return port, nil
+}
+
+// devToolsPage is an entry of the /json/list endpoint
+type devToolsPage struct {
+	ID                   string `json:"id"`
+	Type                 string `json:"type"`
+	Title                string `json:"title"`
+	URL                  string `json:"url"`
+	WebSocketDebuggerURL string `json:"webSocketDebuggerUrl"`
+}
+
+func listDevToolsPages(port int) ([]devToolsPage, error) {
+	client := &http.Client{Timeout: devToolsTimeout}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/json/list", port))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("devtools returned %s", resp.Status)
+	}
+
+	var pages []devToolsPage
+	if err := json.NewDecoder(resp.Body).Decode(&pages); err != nil {
+		return nil, fmt.Errorf("invalid devtools target list: %w", err)
+	}
+	return pages, nil
+}
+
+// ListDevToolsTargets lists the Chrome tabs and debuggable webviews of the
+// device, found through their devtools sockets
+func (d *AndroidDevice) ListDevToolsTargets() ([]DevToolsTarget, error) {
+	out, err := d.runAdbCommand("shell", "cat", "/proc/net/unix")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list devtools sockets: %w", err)
+	}
+
+	targets := []DevToolsTarget{}
+	for socket, pid := range parseDevToolsSockets(string(out)) {
+		port, err := d.forwardDevToolsSocket(socket)
+		if err != nil {
+			utils.Debug(utils.SubsystemADB, "skipping devtools socket %s: %v", socket, err)
+			continue
+		}
+
+		pages, err := listDevToolsPages(port)
+		if err != nil {
+			utils.Debug(utils.SubsystemADB, "skipping devtools socket %s: %v", socket, err)
+			continue
+		}
+
+		bundleID := ""
+		if pid != 0 {
+			if cmdline, err := d.runAdbCommand("shell", "cat", fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil {
+				bundleID = strings.TrimRight(strings.SplitN(string(cmdline), "\x00", 2)[0], "\x00")
+			}
+		}
+
+		for _, page := range pages {
+			targets = append(targets, DevToolsTarget{
+				ID:       socket + ":" + page.ID,
+				Type:     page.Type,
+				Title:    page.Title,
+				URL:      page.URL,
+				Socket:   socket,
+				BundleID: bundleID,
+				PID:      pid,
+			})
+		}
+	}
+
+	slices.SortStableFunc(targets, func(a, b DevToolsTarget) int {
+		return strings.Compare(a.Socket, b.Socket)
+	})
+	return targets, nil
+}
+
+// devToolsDebuggerURL returns the websocket URL of a target, through the
+// forward of its devtools socket
+func (d *AndroidDevice) devToolsDebuggerURL(targetID string) (string, error) {
+	socket, pageID, err := parseDevToolsTargetID(targetID)
+	if err != nil {
+		return "", err
+	}
+
+	port, err := d.forwardDevToolsSocket(socket)
+	if err != nil {
+		return "", err
+	}
+
+	pages, err := listDevToolsPages(port)
+	if err != nil {
+		return "", fmt.Errorf("failed to list devtools targets: %w", err)
+	}
+
+	wsURL := ""
+	for _, page := range pages {
+		if page.ID == pageID {
+			wsURL = page.WebSocketDebuggerURL
+		}
+	}
+	if wsURL == "" {
+		return "", fmt.Errorf("target %s not found or already being debugged", targetID)
+	}
+
+	// the browser reports its own host, reach it through the forward instead
+	u, err := url.Parse(wsURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid debugger url %s: %w", wsURL, err)
+	}
+	u.Host = fmt.Sprintf("127.0.0.1:%d", port)
+	return u.String(), nil
+}
+
+// DumpDevToolsTarget returns the DOM of a target, with the documents of its
+// frames and shadow roots, as a DevTools DOM.getDocument node tree
+func (d *AndroidDevice) DumpDevToolsTarget(targetID string) (any, error) {
+	wsURL, err := d.devToolsDebuggerURL(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := callDevTools(wsURL, "DOM.getDocument", map[string]any{"depth": -1, "pierce": true})
+	if err != nil {
+		return nil, err
+	}
+
+	var document struct {
+		Root any `json:"root"`
+	}
+	if err := json.Unmarshal(result, &document); err != nil {
+		return nil, fmt.Errorf("invalid DOM.getDocument result: %w", err)
+	}
+	return document.Root, nil
+}
+
+// EvaluateDevToolsTarget runs a JavaScript expression in a target and returns
+// its value
+func (d *AndroidDevice) EvaluateDevToolsTarget(targetID, expression string) (any, error) {
+	wsURL, err := d.devToolsDebuggerURL(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := callDevTools(wsURL, "Runtime.evaluate", map[string]any{"expression": expression, "returnByValue": true, "awaitPromise": true})
+	if err != nil {
+		return nil, err
+	}
+
+	var evaluation struct {
+		Result struct {
+			Value any `json:"value"`
+		} `json:"result"`
+		ExceptionDetails *struct {
+			Text      string `json:"text"`
+			Exception struct {
+				Description string `json:"description"`
+			} `json:"exception"`
+		} `json:"exceptionDetails"`
+	}
+	if err := json.Unmarshal(result, &evaluation); err != nil {
+		return nil, fmt.Errorf("invalid Runtime.evaluate result: %w", err)
+	}
+	if evaluation.ExceptionDetails != nil {
+		return nil, fmt.Errorf("javascript error: %s", cmp.Or(evaluation.ExceptionDetails.Exception.Description, evaluation.ExceptionDetails.Text))
+	}
+	return evaluation.Result.Value, nil
+}
+
+// callDevTools sends a single DevTools protocol command and returns its
+// result, skipping the events sent meanwhile
+func callDevTools(wsURL, method string, params map[string]any) (json.RawMessage, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: devToolsTimeout}
+	conn, _, err := dialer.Dial(wsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to devtools: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetWriteDeadline(time.Now().Add(devToolsTimeout))
+	if err := conn.WriteJSON(map[string]any{"id": 1, "method": method, "params": params}); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(devToolsTimeout))
+	for {
+		var message struct {
+			ID     int             `json:"id"`
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := conn.ReadJSON(&message); err != nil {
+			return nil, fmt.Errorf("failed to read %s response: %w", method, err)
+		}
+		if message.ID != 1 {
+			continue
+		}
+		if message.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, message.Error.Message)
+		}
+		return message.Result, nil
+	}
+}
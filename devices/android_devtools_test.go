@@ -0,0 +1,37 @@
+package devices
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDevToolsSockets(t *testing.T) {
+	procNetUnix := `Num       RefCount Protocol Flags    Type St Inode Path
+0000000000000000: 00000002 00000000 00010000 0001 01 31045 @chrome_devtools_remote
+0000000000000000: 00000002 00000000 00010000 0001 01 52210 @webview_devtools_remote_4321
+0000000000000000: 00000002 00000000 00010000 0001 01 12345 /dev/socket/logd
+0000000000000000: 00000003 00000000 00000000 0001 03 52211 @webview_devtools_remote_4321
+`
+
+	sockets := parseDevToolsSockets(procNetUnix)
+	expected := map[string]int{
+		"chrome_devtools_remote":       0,
+		"webview_devtools_remote_4321": 4321,
+	}
+	if !reflect.DeepEqual(sockets, expected) {
+		t.Errorf("expected %v, got %v", expected, sockets)
+	}
+}
+
+func TestParseDevToolsTargetID(t *testing.T) {
+	socket, id, err := parseDevToolsTargetID("webview_devtools_remote_4321:7A1D9E")
+	if err != nil || socket != "webview_devtools_remote_4321" || id != "7A1D9E" {
+		t.Errorf("unexpected socket %q, id %q, err %v", socket, id, err)
+	}
+
+	for _, invalid := range []string{"", "7A1D9E", ":7A1D9E", "chrome_devtools_remote:"} {
+		if _, _, err := parseDevToolsTargetID(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,6 +12,7 @@ import (
 
 	"al.essio.dev/pkg/shellescape"
 	"github.com/google/uuid"
+	"github.com/mobile-next/mobilecli/utils"
 )
 
 // androidPackageName extracts the package name from a /data/user/<uid>/<package>/... path.
@@ -39,14 +40,44 @@ func (d *AndroidDevice) buildShellCommand(remotePath string, parts ...string) (s
 	return "run-as " + shellescape.Quote(pkg) + " " + cmd, nil
 }
 
+// AppContainers returns the directory of an app's apks and its data
+// directory. The data directory is only accessible for debuggable apps,
+// through run-as.
+func (d *AndroidDevice) AppContainers(packageName string) (*AppContainers, error) {
+	apkPath, err := d.GetAppPath(packageName)
+	if err != nil {
+		return nil, err
+	}
+	if apkPath == "" {
+		return nil, fmt.Errorf("app %s is not installed", packageName)
+	}
+
+	dataDir, err := d.GetAppContainerPath(packageName)
+	if err != nil {
+		return nil, err
+	}
+
+	// older adb versions don't pass on the exit code, so check for run-as
+	// complaining too
+	runAs := shellescape.QuoteCommand([]string{"run-as", packageName, "true"})
+	output, runAsErr := d.runAdbCommand("shell", runAs)
+
+	return &AppContainers{
+		BundleID:       packageName,
+		Bundle:         path.Dir(apkPath),
+		Data:           dataDir,
+		DataAccessible: runAsErr == nil && !strings.Contains(string(output), "run-as:"),
+	}, nil
+}
+
 func (d *AndroidDevice) PushFile(localPath, remotePath string) error {
 	if !strings.HasPrefix(remotePath, "/data/user/") {
-		_, err := d.runAdbCommand("push", localPath, remotePath)
+		_, err := d.runAdbCommandTimeout(longCommandTimeout, "push", localPath, remotePath)
 		return err
 	}
 
 	tmpPath := fmt.Sprintf("/data/local/tmp/mobilecli-%s", uuid.NewString())
-	if _, err := d.runAdbCommand("push", localPath, tmpPath); err != nil {
+	if _, err := d.runAdbCommandTimeout(longCommandTimeout, "push", localPath, tmpPath); err != nil {
 		return fmt.Errorf("push to tmp failed: %w", err)
 	}
 
@@ -77,18 +108,30 @@ func (d *AndroidDevice) PullFile(remotePath, localPath string) error {
 	// exec-out (instead of shell) bypasses the PTY, preserving binary bytes on Windows
 	// and keeping stderr separate so we can surface it on failure
 	deviceID := d.getAdbIdentifier()
-	cmd := exec.Command(getAdbPath(), "-s", deviceID, "exec-out", shellCmd)
+	cmd := adbCommand("-s", deviceID, "exec-out", shellCmd)
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
-	data, err := cmd.Output()
+
+	// stream into the file rather than buffering files of any size in memory
+	file, err := os.OpenFile(localPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
+		return err
+	}
+	cmd.Stdout = file
+
+	err = utils.Run(utils.WithTimeout(cmd, longCommandTimeout))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(localPath)
 		msg := strings.TrimSpace(stderr.String())
 		if msg != "" {
 			return fmt.Errorf("pull failed: %w: %s", err, msg)
 		}
 		return fmt.Errorf("pull failed: %w", err)
 	}
-	return os.WriteFile(localPath, data, 0644)
+	return nil
 }
 
 func (d *AndroidDevice) ListFiles(bundleID, remotePath string) ([]FileEntry, error) {
@@ -1,6 +1,12 @@
 package devices
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 func Test_androidParseLsLine(t *testing.T) {
 	tests := []struct {
@@ -153,3 +159,25 @@ func Test_buildShellCommand_quotesUntrustedInput(t *testing.T) {
 		t.Errorf("got %q, want prefix %q", cmd, wantPrefix)
 	}
 }
+
+func TestAndroidAppContainers(t *testing.T) {
+	adb := &fakeAdb{output: "package:/data/app/~~a1/com.example.app-b2/base.apk\n    dataDir=/data/user/0/com.example.app\n"}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	containers, err := d.AppContainers("com.example.app")
+	require.NoError(t, err)
+	assert.Equal(t, &AppContainers{
+		BundleID:       "com.example.app",
+		Bundle:         "/data/app/~~a1/com.example.app-b2",
+		Data:           "/data/user/0/com.example.app",
+		DataAccessible: true,
+	}, containers)
+	assert.Equal(t, []string{"-s", "emulator-5554", "shell", "run-as com.example.app true"}, adb.args[2])
+
+	adb.output = "run-as: package not debuggable: com.example.app\n"
+	d = &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	_, err = d.AppContainers("com.example.app")
+	assert.Error(t, err, "expected an error without a dataDir")
+}
@@ -0,0 +1,329 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/mobile-next/mobilecli/devices/wda"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// androidSwipe is a single-stroke gesture that 'input swipe' can replay with
+// the framework's own event timing
+type androidSwipe struct {
+	x1, y1, x2, y2 int
+	duration       int // milliseconds
+}
+
+// gestureAsSwipe recognizes the common single-stroke shape: an optional
+// positioning move, pointerDown, one move, pointerUp. The move's duration
+// becomes the swipe duration, so flings keep their velocity. A pause before
+// the move holds the finger still, e.g. a long press then drag, which 'input
+// swipe' can't do, so anything else returns false.
+func gestureAsSwipe(actions []wda.TapAction) (androidSwipe, bool) {
+	var swipe androidSwipe
+	x, y := 0, 0
+	i := 0
+
+	if i < len(actions) && actions[i].Type == "pointerMove" {
+		x, y = actions[i].X, actions[i].Y
+		i++
+	}
+
+	if i >= len(actions) || actions[i].Type != "pointerDown" {
+		return swipe, false
+	}
+	i++
+
+	// a zero pause doesn't hold the finger
+	if i < len(actions) && actions[i].Type == "pause" && actions[i].Duration == 0 {
+		i++
+	}
+
+	if i >= len(actions) || actions[i].Type != "pointerMove" {
+		return swipe, false
+	}
+	move := actions[i]
+	i++
+
+	if i != len(actions)-1 || actions[i].Type != "pointerUp" {
+		return swipe, false
+	}
+
+	return androidSwipe{x1: x, y1: y, x2: move.X, y2: move.Y, duration: move.Duration}, true
+}
+
+// gestureMoveInterval is how often, in milliseconds, a held pointer reports
+// its position while moving, about one frame at 60Hz
+const gestureMoveInterval = 16
+
+// motioneventMoveInterval is how long, in milliseconds, one 'input
+// touchscreen motionevent' takes to start on the device, so the fallback
+// script spaces its moves that far apart instead of sleeping
+const motioneventMoveInterval = 200
+
+// gesturePoint is a position the pointer passes through while moving
+type gesturePoint struct {
+	x, y int
+}
+
+// interpolateMove splits a move of duration milliseconds into one step per
+// interval, so the pointer travels through the positions in between rather
+// than jumping to the target and releasing with no velocity
+func interpolateMove(fromX, fromY, toX, toY, duration, interval int) []gesturePoint {
+	steps := duration / interval
+	if steps < 1 {
+		steps = 1
+	}
+
+	points := make([]gesturePoint, 0, steps)
+	for i := 1; i <= steps; i++ {
+		points = append(points, gesturePoint{
+			x: fromX + (toX-fromX)*i/steps,
+			y: fromY + (toY-fromY)*i/steps,
+		})
+	}
+	return points
+}
+
+// sleepLine returns an on-device sleep of ms milliseconds
+func sleepLine(ms int) string {
+	return fmt.Sprintf("sleep %.3f", float64(ms)/1000)
+}
+
+// gestureScript compiles the actions for the device's touchscreen, writing
+// the events with sendevent so the whole gesture runs without starting a JVM
+// per event. Without a touchscreen we can identify it falls back to 'input
+// touchscreen motionevent'.
+func (d *AndroidDevice) gestureScript(actions []wda.TapAction) (string, error) {
+	screen, err := d.touchscreen()
+	if err != nil {
+		utils.Debug(utils.SubsystemADB, "falling back to input motionevent for gesture: %v", err)
+		return compileGestureScript(actions)
+	}
+
+	return compileSendeventScript(actions, screen)
+}
+
+// touchscreen finds the touchscreen's event device and maps it onto the
+// screen as it's rotated now
+func (d *AndroidDevice) touchscreen() (androidTouchscreen, error) {
+	output, err := d.runAdbCommand("shell", "getevent", "-pl")
+	if err != nil {
+		return androidTouchscreen{}, fmt.Errorf("failed to list input devices: %v", err)
+	}
+
+	screen, ok := parseTouchscreen(string(output))
+	if !ok {
+		return androidTouchscreen{}, fmt.Errorf("no multi-touch screen found")
+	}
+
+	screen.width, screen.height, err = d.naturalScreenSize()
+	if err != nil {
+		return androidTouchscreen{}, err
+	}
+	screen.rotation = d.displayRotation()
+
+	return screen, nil
+}
+
+// linux input event types and codes sendevent writes
+const (
+	evSyn           = 0
+	evKey           = 1
+	evAbs           = 3
+	synReport       = 0
+	btnTouch        = 330
+	absMTSlot       = 47
+	absMTPositionX  = 53
+	absMTPositionY  = 54
+	absMTTrackingID = 57
+)
+
+// gestureTrackingID identifies the injected contact to the input core
+const gestureTrackingID = 1
+
+// androidTouchscreen is a multi-touch event device and the screen it covers
+type androidTouchscreen struct {
+	path          string
+	minX, maxX    int
+	minY, maxY    int
+	width, height int // natural, portrait screen size
+	rotation      int // quarter turns, as displayRotation returns
+}
+
+// touchscreenDeviceRe matches the start of a device in 'getevent -pl' output,
+// e.g. "add device 2: /dev/input/event2"
+var touchscreenDeviceRe = regexp.MustCompile(`^add device \d+:\s*(\S+)`)
+
+// touchscreenAxisRe matches an absolute axis in 'getevent -pl' output, e.g.
+// "ABS_MT_POSITION_X : value 0, min 0, max 1079, fuzz 0, flat 0, resolution 0"
+var touchscreenAxisRe = regexp.MustCompile(`(ABS_MT_SLOT|ABS_MT_POSITION_X|ABS_MT_POSITION_Y)\s*:.*\bmin (-?\d+), max (-?\d+)`)
+
+// parseTouchscreen returns the first device in 'getevent -pl' output that
+// reports multi-touch slots and positions, which is the type B protocol
+// compileSendeventScript speaks
+func parseTouchscreen(output string) (androidTouchscreen, bool) {
+	var screen androidTouchscreen
+	var hasSlot, hasX, hasY bool
+
+	found := func() bool {
+		return screen.path != "" && hasSlot && hasX && hasY
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if matches := touchscreenDeviceRe.FindStringSubmatch(line); matches != nil {
+			if found() {
+				return screen, true
+			}
+			screen = androidTouchscreen{path: matches[1]}
+			hasSlot, hasX, hasY = false, false, false
+			continue
+		}
+
+		matches := touchscreenAxisRe.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		lo, _ := strconv.Atoi(matches[2])
+		hi, _ := strconv.Atoi(matches[3])
+		switch matches[1] {
+		case "ABS_MT_SLOT":
+			hasSlot = true
+		case "ABS_MT_POSITION_X":
+			screen.minX, screen.maxX, hasX = lo, hi, hi > lo
+		case "ABS_MT_POSITION_Y":
+			screen.minY, screen.maxY, hasY = lo, hi, hi > lo
+		}
+	}
+
+	return screen, found()
+}
+
+// scale maps a point on the rotated screen onto the touchscreen's axes,
+// which follow the panel's natural orientation
+func (s androidTouchscreen) scale(x, y int) (int, int) {
+	nx, ny := x, y
+	switch s.rotation {
+	case 1:
+		nx, ny = s.width-1-y, x
+	case 2:
+		nx, ny = s.width-1-x, s.height-1-y
+	case 3:
+		nx, ny = y, s.height-1-x
+	}
+
+	axis := func(v, size, lo, hi int) int {
+		if size <= 1 {
+			return lo
+		}
+		v = lo + v*(hi-lo)/(size-1)
+		return max(lo, min(v, hi))
+	}
+
+	return axis(nx, s.width, s.minX, s.maxX), axis(ny, s.height, s.minY, s.maxY)
+}
+
+// compileSendeventScript turns the actions into a single shell script of
+// sendevent writes to the touchscreen, so the whole gesture is injected with
+// one adb round trip. Moves report their position every gestureMoveInterval
+// across their duration, which keeps drag and fling velocity.
+func compileSendeventScript(actions []wda.TapAction, screen androidTouchscreen) (string, error) {
+	var lines []string
+	x, y := 0, 0
+	pressed := false
+
+	event := func(eventType, code, value int) {
+		lines = append(lines, fmt.Sprintf("sendevent %s %d %d %d", shellescape.Quote(screen.path), eventType, code, value))
+	}
+	position := func(x, y int) {
+		ax, ay := screen.scale(x, y)
+		event(evAbs, absMTPositionX, ax)
+		event(evAbs, absMTPositionY, ay)
+	}
+
+	for _, action := range actions {
+		switch action.Type {
+		case "pause":
+			if action.Duration > 0 {
+				lines = append(lines, sleepLine(action.Duration))
+			}
+		case "pointerDown":
+			pressed = true
+			event(evAbs, absMTSlot, 0)
+			event(evAbs, absMTTrackingID, gestureTrackingID)
+			position(x, y)
+			event(evKey, btnTouch, 1)
+			event(evSyn, synReport, 0)
+		case "pointerMove":
+			// a move before pointerDown only positions the pointer
+			if !pressed {
+				x, y = action.X, action.Y
+				continue
+			}
+			points := interpolateMove(x, y, action.X, action.Y, action.Duration, gestureMoveInterval)
+			step := action.Duration / len(points)
+			for _, point := range points {
+				position(point.x, point.y)
+				event(evSyn, synReport, 0)
+				if step > 0 {
+					lines = append(lines, sleepLine(step))
+				}
+			}
+			x, y = action.X, action.Y
+		case "pointerUp":
+			pressed = false
+			event(evAbs, absMTSlot, 0)
+			event(evAbs, absMTTrackingID, -1)
+			event(evKey, btnTouch, 0)
+			event(evSyn, synReport, 0)
+		default:
+			return "", fmt.Errorf("unsupported gesture action type: %s", action.Type)
+		}
+	}
+
+	return strings.Join(lines, "; "), nil
+}
+
+// compileGestureScript turns the actions into a single shell script of
+// 'input touchscreen motionevent' calls, for devices where we can't write to
+// the touchscreen. Each call starts a JVM, which takes about as long as
+// motioneventMoveInterval, so moves are split into that many steps and that
+// startup paces them instead of a sleep.
+func compileGestureScript(actions []wda.TapAction) (string, error) {
+	var lines []string
+	x, y := 0, 0
+	pressed := false
+
+	for _, action := range actions {
+		switch action.Type {
+		case "pause":
+			if action.Duration > 0 {
+				lines = append(lines, sleepLine(action.Duration))
+			}
+		case "pointerDown":
+			pressed = true
+			lines = append(lines, fmt.Sprintf("input touchscreen motionevent down %d %d", x, y))
+		case "pointerMove":
+			// a move before pointerDown only positions the pointer
+			if pressed {
+				for _, point := range interpolateMove(x, y, action.X, action.Y, action.Duration, motioneventMoveInterval) {
+					lines = append(lines, fmt.Sprintf("input touchscreen motionevent move %d %d", point.x, point.y))
+				}
+			}
+			x, y = action.X, action.Y
+		case "pointerUp":
+			pressed = false
+			lines = append(lines, fmt.Sprintf("input touchscreen motionevent up %d %d", x, y))
+		default:
+			return "", fmt.Errorf("unsupported gesture action type: %s", action.Type)
+		}
+	}
+
+	return strings.Join(lines, "; "), nil
+}
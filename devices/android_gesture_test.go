@@ -0,0 +1,160 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices/wda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGestureAsSwipeRecognizesFling(t *testing.T) {
+	swipe, ok := gestureAsSwipe([]wda.TapAction{
+		{Type: "pointerMove", X: 500, Y: 1500},
+		{Type: "pointerDown"},
+		{Type: "pointerMove", X: 500, Y: 300, Duration: 80},
+		{Type: "pointerUp"},
+	})
+
+	require.True(t, ok)
+	assert.Equal(t, androidSwipe{x1: 500, y1: 1500, x2: 500, y2: 300, duration: 80}, swipe)
+}
+
+func TestGestureAsSwipeRejectsPauseBeforeMove(t *testing.T) {
+	// a long press then drag has to hold the finger still before moving
+	_, ok := gestureAsSwipe([]wda.TapAction{
+		{Type: "pointerMove", X: 500, Y: 1500},
+		{Type: "pointerDown"},
+		{Type: "pause", Duration: 800},
+		{Type: "pointerMove", X: 500, Y: 300, Duration: 80},
+		{Type: "pointerUp"},
+	})
+
+	assert.False(t, ok)
+}
+
+func TestGestureAsSwipeRejectsMultiSegmentStrokes(t *testing.T) {
+	_, ok := gestureAsSwipe([]wda.TapAction{
+		{Type: "pointerMove", X: 100, Y: 100},
+		{Type: "pointerDown"},
+		{Type: "pointerMove", X: 200, Y: 200, Duration: 100},
+		{Type: "pointerMove", X: 300, Y: 100, Duration: 100},
+		{Type: "pointerUp"},
+	})
+
+	assert.False(t, ok)
+}
+
+const geteventTouchscreenOutput = `add device 1: /dev/input/event1
+  name:     "gpio-keys"
+  events:
+    KEY (0001): KEY_VOLUMEDOWN        KEY_VOLUMEUP
+add device 2: /dev/input/event2
+  name:     "sec_touchscreen"
+  events:
+    KEY (0001): BTN_TOUCH
+    ABS (0003): ABS_MT_SLOT           : value 0, min 0, max 9, fuzz 0, flat 0, resolution 0
+                ABS_MT_TOUCH_MAJOR    : value 0, min 0, max 255, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_X     : value 0, min 0, max 4095, fuzz 0, flat 0, resolution 0
+                ABS_MT_POSITION_Y     : value 0, min 0, max 8191, fuzz 0, flat 0, resolution 0
+                ABS_MT_TRACKING_ID    : value 0, min 0, max 65535, fuzz 0, flat 0, resolution 0
+  input props:
+    INPUT_PROP_DIRECT
+`
+
+func TestParseTouchscreen(t *testing.T) {
+	screen, ok := parseTouchscreen(geteventTouchscreenOutput)
+
+	require.True(t, ok)
+	assert.Equal(t, androidTouchscreen{path: "/dev/input/event2", maxX: 4095, maxY: 8191}, screen)
+}
+
+func TestParseTouchscreenRequiresMultiTouch(t *testing.T) {
+	_, ok := parseTouchscreen("add device 1: /dev/input/event1\n  name:     \"gpio-keys\"\n")
+	assert.False(t, ok)
+}
+
+func TestTouchscreenScaleFollowsRotation(t *testing.T) {
+	screen := androidTouchscreen{maxX: 999, maxY: 1999, width: 1000, height: 2000}
+
+	x, y := screen.scale(100, 200)
+	assert.Equal(t, []int{100, 200}, []int{x, y})
+
+	// landscape, with the panel's natural top to the left
+	screen.rotation = 1
+	x, y = screen.scale(100, 200)
+	assert.Equal(t, []int{799, 100}, []int{x, y})
+}
+
+func TestInterpolateMove(t *testing.T) {
+	points := interpolateMove(0, 0, 160, 80, 64, 16)
+
+	assert.Equal(t, []gesturePoint{{40, 20}, {80, 40}, {120, 60}, {160, 80}}, points)
+}
+
+func TestInterpolateMoveWithoutDuration(t *testing.T) {
+	points := interpolateMove(0, 0, 160, 80, 0, 16)
+
+	assert.Equal(t, []gesturePoint{{160, 80}}, points)
+}
+
+func TestCompileSendeventScriptInterpolatesMoves(t *testing.T) {
+	screen := androidTouchscreen{path: "/dev/input/event2", maxX: 999, maxY: 1999, width: 1000, height: 2000}
+
+	script, err := compileSendeventScript([]wda.TapAction{
+		{Type: "pointerMove", X: 100, Y: 1000},
+		{Type: "pointerDown"},
+		{Type: "pointerMove", X: 100, Y: 520, Duration: 48},
+		{Type: "pointerUp"},
+	}, screen)
+	require.NoError(t, err)
+
+	lines := strings.Split(script, "; ")
+	assert.Equal(t, []string{
+		"sendevent /dev/input/event2 3 47 0",
+		"sendevent /dev/input/event2 3 57 1",
+		"sendevent /dev/input/event2 3 53 100",
+		"sendevent /dev/input/event2 3 54 1000",
+		"sendevent /dev/input/event2 1 330 1",
+		"sendevent /dev/input/event2 0 0 0",
+		// the 48ms move reports three positions along the way, 16ms apart
+		"sendevent /dev/input/event2 3 53 100",
+		"sendevent /dev/input/event2 3 54 840",
+		"sendevent /dev/input/event2 0 0 0",
+		"sleep 0.016",
+		"sendevent /dev/input/event2 3 53 100",
+		"sendevent /dev/input/event2 3 54 680",
+		"sendevent /dev/input/event2 0 0 0",
+		"sleep 0.016",
+		"sendevent /dev/input/event2 3 53 100",
+		"sendevent /dev/input/event2 3 54 520",
+		"sendevent /dev/input/event2 0 0 0",
+		"sleep 0.016",
+		"sendevent /dev/input/event2 3 47 0",
+		"sendevent /dev/input/event2 3 57 -1",
+		"sendevent /dev/input/event2 1 330 0",
+		"sendevent /dev/input/event2 0 0 0",
+	}, lines)
+}
+
+func TestCompileGestureScript(t *testing.T) {
+	script, err := compileGestureScript([]wda.TapAction{
+		{Type: "pointerMove", X: 100, Y: 200},
+		{Type: "pointerDown"},
+		{Type: "pause", Duration: 500},
+		{Type: "pointerMove", X: 300, Y: 400, Duration: 400},
+		{Type: "pointerUp"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "input touchscreen motionevent down 100 200; sleep 0.500; input touchscreen motionevent move 200 300; input touchscreen motionevent move 300 400; input touchscreen motionevent up 300 400", script)
+}
+
+func TestCompileGestureScriptRejectsUnknownActions(t *testing.T) {
+	_, err := compileGestureScript([]wda.TapAction{{Type: "scroll"}})
+	assert.Error(t, err)
+
+	_, err = compileSendeventScript([]wda.TapAction{{Type: "scroll"}}, androidTouchscreen{})
+	assert.Error(t, err)
+}
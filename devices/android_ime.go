@@ -0,0 +1,149 @@
+package devices
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// AndroidTextInputEnvVar selects how non-ASCII text is typed on Android:
+// "devicekit" (clipboard paste through DeviceKit), "adbkeyboard" (broadcast to
+// an ADBKeyBoard-compatible IME) or "auto" (the default, DeviceKit first). It
+// takes precedence over android_text_input in config.json.
+const AndroidTextInputEnvVar = "MOBILECLI_ANDROID_TEXT_INPUT"
+
+const (
+	androidTextInputAuto        = "auto"
+	androidTextInputDeviceKit   = "devicekit"
+	androidTextInputAdbKeyboard = "adbkeyboard"
+)
+
+const (
+	adbKeyboardPackage = "com.android.adbkeyboard"
+	adbKeyboardIME     = "com.android.adbkeyboard/.AdbIME"
+)
+
+// androidTextInputMode returns the configured text input mode
+func androidTextInputMode() (string, error) {
+	source := AndroidTextInputEnvVar
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv(AndroidTextInputEnvVar)))
+	if mode == "" {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return "", err
+		}
+		source, mode = "android_text_input in config.json", strings.ToLower(strings.TrimSpace(config.AndroidTextInput))
+	}
+
+	switch mode {
+	case "":
+		return androidTextInputAuto, nil
+	case androidTextInputAuto, androidTextInputDeviceKit, androidTextInputAdbKeyboard:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid %s value '%s', expected one of: auto, devicekit, adbkeyboard", source, mode)
+	}
+}
+
+// isAdbKeyboardInstalled checks if an ADBKeyBoard-compatible IME is installed on the device
+func (d *AndroidDevice) isAdbKeyboardInstalled() bool {
+	appPath, err := d.GetAppPath(adbKeyboardPackage)
+	return err == nil && appPath != ""
+}
+
+// sendKeysWithDeviceKit sets the clipboard through DeviceKit and pastes it
+func (d *AndroidDevice) sendKeysWithDeviceKit(text string) error {
+	// ensure clipboard is always cleared, even on failure
+	defer func() {
+		_, _ = d.runAdbCommand("shell", "am", "broadcast", "-a", "devicekit.clipboard.clear", "-n", "com.mobilenext.devicekit/.ClipboardBroadcastReceiver")
+	}()
+
+	// encode text as base64
+	base64Text := base64.StdEncoding.EncodeToString([]byte(text))
+
+	// send clipboard over and immediately paste it
+	_, err := d.runAdbCommand("shell", "am", "broadcast", "-a", "devicekit.clipboard.set", "-e", "encoding", "base64", "-e", "text", base64Text, "-n", "com.mobilenext.devicekit/.ClipboardBroadcastReceiver")
+	if err != nil {
+		return fmt.Errorf("failed to set clipboard: %w", err)
+	}
+
+	_, err = d.runAdbCommand("shell", "input", "keyevent", "KEYCODE_PASTE")
+	if err != nil {
+		return fmt.Errorf("failed to paste: %w", err)
+	}
+
+	return nil
+}
+
+// sendKeysWithAdbKeyboard switches to the ADBKeyBoard IME, types the text
+// through its base64 broadcast and restores the previous input method.
+func (d *AndroidDevice) sendKeysWithAdbKeyboard(text string) error {
+	output, err := d.runAdbCommand("shell", "settings", "get", "secure", "default_input_method")
+	if err != nil {
+		return fmt.Errorf("failed to get current input method: %w", err)
+	}
+	previousIME := strings.TrimSpace(string(output))
+
+	if previousIME != adbKeyboardIME {
+		if output, err := d.runAdbCommand("shell", "ime", "enable", adbKeyboardIME); err != nil {
+			return fmt.Errorf("failed to enable %s: %w\nOutput: %s", adbKeyboardIME, err, string(output))
+		}
+
+		if output, err := d.runAdbCommand("shell", "ime", "set", adbKeyboardIME); err != nil {
+			return fmt.Errorf("failed to select %s: %w\nOutput: %s", adbKeyboardIME, err, string(output))
+		}
+
+		defer func() {
+			if previousIME == "" || previousIME == "null" {
+				return
+			}
+			if _, err := d.runAdbCommand("shell", "ime", "set", previousIME); err != nil {
+				utils.Debug(utils.SubsystemADB, "failed to restore input method %s: %v", previousIME, err)
+			}
+		}()
+	}
+
+	base64Text := base64.StdEncoding.EncodeToString([]byte(text))
+	output, err = d.runAdbCommand("shell", "am", "broadcast", "-a", "ADB_INPUT_B64", "--es", "msg", base64Text)
+	if err != nil {
+		return fmt.Errorf("failed to send text to %s: %w\nOutput: %s", adbKeyboardIME, err, string(output))
+	}
+
+	return nil
+}
+
+// sendNonAsciiKeys types text that 'adb shell input' can't, using the
+// configured input mode
+func (d *AndroidDevice) sendNonAsciiKeys(text string) error {
+	mode, err := androidTextInputMode()
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case androidTextInputDeviceKit:
+		if !d.isDeviceKitInstalled() {
+			return fmt.Errorf("non-ASCII text input via devicekit requires mobilenext devicekit, see https://github.com/mobile-next/devicekit-android")
+		}
+		return d.sendKeysWithDeviceKit(text)
+
+	case androidTextInputAdbKeyboard:
+		if !d.isAdbKeyboardInstalled() {
+			return fmt.Errorf("non-ASCII text input via adbkeyboard requires an ADBKeyBoard-compatible IME (%s) to be installed", adbKeyboardPackage)
+		}
+		return d.sendKeysWithAdbKeyboard(text)
+	}
+
+	if d.isDeviceKitInstalled() {
+		return d.sendKeysWithDeviceKit(text)
+	}
+
+	if d.isAdbKeyboardInstalled() {
+		return d.sendKeysWithAdbKeyboard(text)
+	}
+
+	return fmt.Errorf("non-ASCII text is not supported on Android, please install mobilenext devicekit (https://github.com/mobile-next/devicekit-android) or an ADBKeyBoard-compatible IME (%s)", adbKeyboardPackage)
+}
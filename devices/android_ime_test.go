@@ -0,0 +1,48 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAndroidTextInputMode(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(AndroidTextInputEnvVar, "")
+	mode, err := androidTextInputMode()
+	require.NoError(t, err)
+	assert.Equal(t, androidTextInputAuto, mode)
+
+	t.Setenv(AndroidTextInputEnvVar, "ADBKeyboard")
+	mode, err = androidTextInputMode()
+	require.NoError(t, err)
+	assert.Equal(t, androidTextInputAdbKeyboard, mode)
+
+	t.Setenv(AndroidTextInputEnvVar, "clipboard")
+	_, err = androidTextInputMode()
+	assert.Error(t, err)
+}
+
+func TestAndroidTextInputModeConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(AndroidTextInputEnvVar, "")
+
+	configPath, err := utils.ConfigFilePath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"android_text_input": "adbkeyboard"}`), 0o644))
+
+	mode, err := androidTextInputMode()
+	require.NoError(t, err)
+	assert.Equal(t, androidTextInputAdbKeyboard, mode)
+
+	// the environment variable takes precedence
+	t.Setenv(AndroidTextInputEnvVar, "devicekit")
+	mode, err = androidTextInputMode()
+	require.NoError(t, err)
+	assert.Equal(t, androidTextInputDeviceKit, mode)
+}
@@ -0,0 +1,246 @@
+package devices
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// instrumentation status codes, as reported by 'am instrument -r'
+const (
+	instrumentationStart             = 1
+	instrumentationOK                = 0
+	instrumentationError             = -1
+	instrumentationFailure           = -2
+	instrumentationIgnored           = -3
+	instrumentationAssumptionFailure = -4
+
+	// instrumentationResultOK is the INSTRUMENTATION_CODE of a finished run,
+	// Activity.RESULT_OK
+	instrumentationResultOK = -1
+)
+
+// InstrumentationConfig configures a run of instrumentation tests
+type InstrumentationConfig struct {
+	Runner string            // package/runner component, e.g. com.example.test/androidx.test.runner.AndroidJUnitRunner
+	Class  string            // only run these classes or methods, comma separated (-e class)
+	Args   map[string]string // further runner arguments (-e key value)
+
+	// OnEvent, when set, is called as each test starts and finishes
+	OnEvent func(event TestEvent)
+}
+
+// InstrumentationRunner is implemented by devices that can run
+// instrumentation tests
+type InstrumentationRunner interface {
+	RunInstrumentation(config InstrumentationConfig) (*TestResults, error)
+}
+
+// instrumentationParser parses the output of 'am instrument -r' line by line.
+// Status blocks of key=value pairs, whose values may span lines, end with an
+// INSTRUMENTATION_STATUS_CODE; the run ends with INSTRUMENTATION_CODE.
+type instrumentationParser struct {
+	now     func() time.Time
+	onEvent func(TestEvent)
+
+	status  map[string]string
+	result  map[string]string
+	values  map[string]string // the block the last key was added to
+	lastKey string
+
+	started   map[string]time.Time
+	tests     []TestCaseResult
+	numTests  int
+	code      *int
+	aborted   string
+	startedAt time.Time
+}
+
+func newInstrumentationParser(now func() time.Time, onEvent func(TestEvent)) *instrumentationParser {
+	return &instrumentationParser{
+		now:       now,
+		onEvent:   onEvent,
+		status:    make(map[string]string),
+		result:    make(map[string]string),
+		started:   make(map[string]time.Time),
+		startedAt: now(),
+	}
+}
+
+// parseKeyValue adds a "key=value" line to a block
+func (p *instrumentationParser) parseKeyValue(values map[string]string, pair string) {
+	key, value, _ := strings.Cut(pair, "=")
+	values[key] = value
+	p.values = values
+	p.lastKey = key
+}
+
+func (p *instrumentationParser) line(line string) {
+	switch {
+	case strings.HasPrefix(line, "INSTRUMENTATION_STATUS: "):
+		p.parseKeyValue(p.status, strings.TrimPrefix(line, "INSTRUMENTATION_STATUS: "))
+	case strings.HasPrefix(line, "INSTRUMENTATION_STATUS_CODE: "):
+		code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "INSTRUMENTATION_STATUS_CODE: ")))
+		if err == nil {
+			p.statusCode(code)
+		}
+		p.status = make(map[string]string)
+		p.values = nil
+	case strings.HasPrefix(line, "INSTRUMENTATION_RESULT: "):
+		p.parseKeyValue(p.result, strings.TrimPrefix(line, "INSTRUMENTATION_RESULT: "))
+	case strings.HasPrefix(line, "INSTRUMENTATION_CODE: "):
+		if code, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "INSTRUMENTATION_CODE: "))); err == nil {
+			p.code = &code
+		}
+		p.values = nil
+	case strings.HasPrefix(line, "INSTRUMENTATION_FAILED: "):
+		p.aborted = "instrumentation failed: " + strings.TrimPrefix(line, "INSTRUMENTATION_FAILED: ")
+		p.values = nil
+	case strings.HasPrefix(line, "INSTRUMENTATION_ABORTED: "):
+		p.aborted = "instrumentation aborted: " + strings.TrimPrefix(line, "INSTRUMENTATION_ABORTED: ")
+		p.values = nil
+	default:
+		// the continuation of a multi-line value, such as a stack trace
+		if p.values != nil {
+			p.values[p.lastKey] += "\n" + line
+		}
+	}
+}
+
+// statusCode handles the end of a status block
+func (p *instrumentationParser) statusCode(code int) {
+	class, name := p.status["class"], p.status["test"]
+	if n, err := strconv.Atoi(p.status["numtests"]); err == nil {
+		p.numTests = n
+	}
+	if class == "" && name == "" {
+		return
+	}
+
+	key := class + "#" + name
+	if code == instrumentationStart {
+		p.started[key] = p.now()
+		testStarted(p.onEvent, class, name)
+		return
+	}
+
+	test := TestCaseResult{Class: class, Name: name}
+	switch code {
+	case instrumentationOK:
+		test.Status = TestStatusPassed
+	case instrumentationIgnored:
+		test.Status = TestStatusIgnored
+	case instrumentationAssumptionFailure:
+		test.Status = TestStatusSkipped
+	case instrumentationFailure, instrumentationError:
+		test.Status = TestStatusFailed
+		test.Failure = strings.TrimSpace(p.status["stack"])
+	default:
+		return
+	}
+	if started, ok := p.started[key]; ok {
+		test.Duration = p.now().Sub(started).Milliseconds()
+		delete(p.started, key)
+	}
+
+	p.tests = append(p.tests, test)
+	testFinished(p.onEvent, test)
+}
+
+// finish summarizes the run once the output has ended
+func (p *instrumentationParser) finish(runner string) *TestResults {
+	result := &TestResults{
+		Runner:   runner,
+		Tests:    p.tests,
+		Duration: p.now().Sub(p.startedAt).Milliseconds(),
+	}
+	result.countTests(p.numTests)
+
+	// a test that started but never finished is where the app crashed
+	var running []string
+	for key := range p.started {
+		running = append(running, key)
+	}
+	sort.Strings(running)
+
+	switch {
+	case p.aborted != "":
+		result.Message = p.aborted
+	case p.code == nil:
+		result.Message = "instrumentation ended without a result"
+	case p.result["shortMsg"] != "":
+		result.Message = strings.TrimSuffix(p.result["shortMsg"], ".")
+	case *p.code != instrumentationResultOK:
+		result.Message = fmt.Sprintf("instrumentation ended with code %d", *p.code)
+	}
+	if len(running) > 0 {
+		if result.Message == "" {
+			result.Message = "instrumentation ended"
+		}
+		result.Message += " while running " + strings.Join(running, ", ")
+	}
+	result.Complete = result.Message == ""
+
+	return result
+}
+
+// instrumentCommand builds the 'am instrument' command line for a config.
+// adb shell joins its arguments into one device shell command line, so each
+// value is quoted.
+func instrumentCommand(config InstrumentationConfig) []string {
+	args := []string{"am", "instrument", "-w", "-r"}
+	if config.Class != "" {
+		args = append(args, "-e", "class", shellescape.Quote(config.Class))
+	}
+	keys := make([]string, 0, len(config.Args))
+	for key := range config.Args {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		args = append(args, "-e", shellescape.Quote(key), shellescape.Quote(config.Args[key]))
+	}
+	return append(args, shellescape.Quote(config.Runner))
+}
+
+// RunInstrumentation runs instrumentation tests with 'am instrument -w -r',
+// parsing the results as they stream in. Both the app and the test apk must
+// be installed.
+func (d *AndroidDevice) RunInstrumentation(config InstrumentationConfig) (*TestResults, error) {
+	if config.Runner == "" {
+		return nil, fmt.Errorf("instrumentation runner is required, e.g. com.example.test/androidx.test.runner.AndroidJUnitRunner")
+	}
+
+	args := append([]string{"-s", d.getAdbIdentifier(), "shell"}, instrumentCommand(config)...)
+
+	utils.Debug(utils.SubsystemADB, "Running: %s %s", getAdbPath(), strings.Join(args, " "))
+	cmd := adbCommand(args...)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	cmd.Stderr = cmd.Stdout
+
+	if err := utils.Start(cmd); err != nil {
+		return nil, fmt.Errorf("failed to start instrumentation: %w", err)
+	}
+
+	parser := newInstrumentationParser(time.Now, config.OnEvent)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		parser.line(strings.TrimRight(scanner.Text(), "\r"))
+	}
+
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("failed to run instrumentation: %w", err)
+	}
+	return parser.finish(config.Runner), nil
+}
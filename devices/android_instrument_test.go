@@ -0,0 +1,128 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const instrumentationOutput = `INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: id=AndroidJUnitRunner
+INSTRUMENTATION_STATUS: numtests=3
+INSTRUMENTATION_STATUS: stream=
+com.example.FooTest:
+INSTRUMENTATION_STATUS: test=testPasses
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=1
+INSTRUMENTATION_STATUS: id=AndroidJUnitRunner
+INSTRUMENTATION_STATUS: numtests=3
+INSTRUMENTATION_STATUS: stream=.
+INSTRUMENTATION_STATUS: test=testPasses
+INSTRUMENTATION_STATUS_CODE: 0
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=2
+INSTRUMENTATION_STATUS: numtests=3
+INSTRUMENTATION_STATUS: test=testFails
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=2
+INSTRUMENTATION_STATUS: numtests=3
+INSTRUMENTATION_STATUS: stack=java.lang.AssertionError: expected:<1> but was:<2>
+	at org.junit.Assert.fail(Assert.java:89)
+	at com.example.FooTest.testFails(FooTest.java:21)
+
+INSTRUMENTATION_STATUS: stream=
+Error in testFails(com.example.FooTest):
+INSTRUMENTATION_STATUS: test=testFails
+INSTRUMENTATION_STATUS_CODE: -2
+INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: current=3
+INSTRUMENTATION_STATUS: numtests=3
+INSTRUMENTATION_STATUS: test=testIgnored
+INSTRUMENTATION_STATUS_CODE: -3
+INSTRUMENTATION_RESULT: stream=
+
+Time: 0.52
+
+FAILURES!!!
+Tests run: 2,  Failures: 1
+
+INSTRUMENTATION_CODE: -1
+`
+
+func parseInstrumentation(output string) (*TestResults, []TestCaseResult) {
+	clock := time.Date(2025, 1, 1, 9, 41, 0, 0, time.UTC)
+	now := func() time.Time {
+		clock = clock.Add(100 * time.Millisecond)
+		return clock
+	}
+
+	var streamed []TestCaseResult
+	parser := newInstrumentationParser(now, func(event TestEvent) {
+		if event.Event == "finished" {
+			streamed = append(streamed, event.Test)
+		}
+	})
+	for _, line := range strings.Split(output, "\n") {
+		parser.line(line)
+	}
+	return parser.finish("com.example.test/androidx.test.runner.AndroidJUnitRunner"), streamed
+}
+
+func TestInstrumentationParser(t *testing.T) {
+	result, streamed := parseInstrumentation(instrumentationOutput)
+
+	require.Len(t, result.Tests, 3)
+	assert.Equal(t, result.Tests, streamed)
+	assert.Equal(t, TestCaseResult{Class: "com.example.FooTest", Name: "testPasses", Status: "passed", Duration: 100}, result.Tests[0])
+
+	failed := result.Tests[1]
+	assert.Equal(t, "failed", failed.Status)
+	assert.Equal(t, "java.lang.AssertionError: expected:<1> but was:<2>\n\tat org.junit.Assert.fail(Assert.java:89)\n\tat com.example.FooTest.testFails(FooTest.java:21)", failed.Failure)
+	assert.Equal(t, "ignored", result.Tests[2].Status)
+
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.True(t, result.Complete)
+	assert.Empty(t, result.Message)
+}
+
+func TestInstrumentationParserCrash(t *testing.T) {
+	output := `INSTRUMENTATION_STATUS: class=com.example.FooTest
+INSTRUMENTATION_STATUS: numtests=2
+INSTRUMENTATION_STATUS: test=testCrashes
+INSTRUMENTATION_STATUS_CODE: 1
+INSTRUMENTATION_RESULT: shortMsg=Process crashed.
+INSTRUMENTATION_CODE: 0
+`
+	result, _ := parseInstrumentation(output)
+	assert.False(t, result.Complete)
+	assert.Equal(t, "Process crashed while running com.example.FooTest#testCrashes", result.Message)
+	assert.Equal(t, 2, result.Total)
+	assert.Empty(t, result.Tests)
+
+	result, _ = parseInstrumentation("INSTRUMENTATION_FAILED: com.example.test/androidx.test.runner.AndroidJUnitRunner\n")
+	assert.False(t, result.Complete)
+	assert.Equal(t, "instrumentation failed: com.example.test/androidx.test.runner.AndroidJUnitRunner", result.Message)
+}
+
+func TestInstrumentCommandQuotesValues(t *testing.T) {
+	args := instrumentCommand(InstrumentationConfig{
+		Runner: "com.example.test/androidx.test.runner.AndroidJUnitRunner",
+		Class:  "com.example.FooTest#testBar",
+		Args:   map[string]string{"user": "a b; reboot"},
+	})
+	assert.Equal(t, []string{
+		"am", "instrument", "-w", "-r",
+		"-e", "class", "'com.example.FooTest#testBar'",
+		"-e", "user", "'a b; reboot'",
+		"com.example.test/androidx.test.runner.AndroidJUnitRunner",
+	}, args)
+}
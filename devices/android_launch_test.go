@@ -1,6 +1,9 @@
 package devices
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func Test_parseResolveActivityOutput(t *testing.T) {
 	tests := []struct {
@@ -124,3 +127,37 @@ func Test_buildLaunchComponent(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseAmStartOutput(t *testing.T) {
+	output := `Starting: Intent { cmp=com.example.app/.MainActivity }
+Status: ok
+LaunchState: COLD
+Activity: com.example.app/.HomeActivity
+TotalTime: 512
+WaitTime: 530
+Complete
+`
+	result, err := parseAmStartOutput("com.example.app/.MainActivity", output)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *result != (LaunchResult{Component: "com.example.app/.HomeActivity", TotalTime: 512, WaitTime: 530}) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	result, err = parseAmStartOutput("com.example.app/.MainActivity", "Starting: Intent { cmp=com.example.app/.MainActivity }\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Component != "com.example.app/.MainActivity" || result.TotalTime != 0 {
+		t.Fatalf("unexpected result without -W: %+v", result)
+	}
+
+	_, err = parseAmStartOutput("com.example.app/.Missing", `Starting: Intent { cmp=com.example.app/.Missing }
+Error type 3
+Error: Activity class {com.example.app/com.example.app.Missing} does not exist.
+`)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("expected the am start error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package devices
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// androidMediaDir is where added photos and videos are pushed, a DCIM folder
+// so gallery apps and photo pickers list them
+const androidMediaDir = "/sdcard/DCIM/mobilecli"
+
+// androidContactsDir is where vCard files are pushed before they are imported
+const androidContactsDir = "/sdcard/Download"
+
+// AddMedia pushes photos and videos to the device and has the media scanner
+// index them, so they show up in the gallery and photo pickers
+func (d *AndroidDevice) AddMedia(paths []string) error {
+	if _, err := d.runAdbCommand("shell", "mkdir", "-p", androidMediaDir); err != nil {
+		return fmt.Errorf("failed to create %s: %w", androidMediaDir, err)
+	}
+
+	for _, localPath := range paths {
+		remotePath := path.Join(androidMediaDir, filepath.Base(localPath))
+		if err := d.PushFile(localPath, remotePath); err != nil {
+			return fmt.Errorf("failed to push %s: %w", localPath, err)
+		}
+
+		// indexes the file up to Android 10
+		if _, err := d.runAdbCommand("shell", "am", "broadcast", "-a", "android.intent.action.MEDIA_SCANNER_SCAN_FILE", "-d", "file://"+remotePath); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", remotePath, err)
+		}
+	}
+
+	// Android 11 and later ignore the broadcast, but rescan on request
+	if _, err := d.runAdbCommand("shell", "content", "call", "--uri", "content://media", "--method", "scan_volume", "--arg", "external_primary"); err != nil {
+		utils.Debug(utils.SubsystemADB, "Media volume scan failed, relying on the scan broadcast: %v", err)
+	}
+
+	return nil
+}
+
+// AddContacts pushes a vCard file to the device and opens it with the
+// contacts app, which imports it. Some contacts apps ask to confirm the
+// import, or which account to import to.
+func (d *AndroidDevice) AddContacts(vcfPath string) error {
+	remotePath := path.Join(androidContactsDir, filepath.Base(vcfPath))
+	if err := d.PushFile(vcfPath, remotePath); err != nil {
+		return fmt.Errorf("failed to push %s: %w", vcfPath, err)
+	}
+
+	output, err := d.runAdbCommand("shell", "am", "start", "-a", "android.intent.action.VIEW", "-d", "file://"+remotePath, "-t", "text/x-vcard")
+	if err != nil {
+		return fmt.Errorf("failed to open %s with the contacts app: %w", remotePath, err)
+	}
+	if _, err := parseAmStartOutput("", string(output)); err != nil {
+		return fmt.Errorf("failed to open %s with the contacts app: %w", remotePath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"al.essio.dev/pkg/shellescape"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// INotificationManager's transaction codes differ between releases; these
+// are the API levels on which transaction 1 is cancelAllNotifications
+const (
+	cancelAllTransactionMinSDK = 21
+	cancelAllTransactionMaxSDK = 34
+)
+
+// notificationSnoozeDuration is how long notifications are snoozed for on
+// releases where they can't be cancelled all at once
+const notificationSnoozeDuration = 24 * time.Hour
+
+var (
+	// notificationRecordLine matches the header of a posted notification, e.g.
+	// "NotificationRecord(0x0a1b2c3d: pkg=com.example user=UserHandle{0} id=1 tag=null importance=3 key=0|com.example|1|null|10150: Notification(...))"
+	notificationRecordLine = regexp.MustCompile(`^NotificationRecord\(0x[0-9a-f]+: pkg=(\S+) .*\bkey=(\S+?):? Notification\(`)
+
+	// notificationExtraLine matches a title or text extra, e.g. "android.title=String (Hello)"
+	notificationExtraLine = regexp.MustCompile(`^android\.(title|text|bigText)=\w+ \((.*)\)$`)
+
+	// notificationActionLine matches an action, e.g. `[0] "Reply" -> PendingIntent{...}`
+	notificationActionLine = regexp.MustCompile(`^\[\d+\] "(.*)" ->`)
+)
+
+// parseDumpsysNotifications parses the output of 'dumpsys notification --noredact'
+// into the posted notifications. Records listed more than once are reported once.
+func parseDumpsysNotifications(output string) []Notification {
+	notifications := []Notification{}
+	seen := make(map[string]bool)
+	var current *Notification
+	inActions := false
+
+	flush := func() {
+		if current != nil && !seen[current.Key] {
+			seen[current.Key] = true
+			notifications = append(notifications, *current)
+		}
+		current = nil
+		inActions = false
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if matches := notificationRecordLine.FindStringSubmatch(trimmed); matches != nil {
+			flush()
+			current = &Notification{
+				Key:     matches[2],
+				Package: matches[1],
+			}
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		// records end at the next section header, which isn't indented as deeply
+		if trimmed != "" && strings.HasSuffix(trimmed, ":") && !strings.Contains(trimmed, "=") {
+			flush()
+			continue
+		}
+
+		switch {
+		case trimmed == "actions={":
+			inActions = true
+		case inActions && trimmed == "}":
+			inActions = false
+		case inActions:
+			if matches := notificationActionLine.FindStringSubmatch(trimmed); matches != nil {
+				current.Actions = append(current.Actions, matches[1])
+			}
+		default:
+			if matches := notificationExtraLine.FindStringSubmatch(trimmed); matches != nil {
+				switch matches[1] {
+				case "title":
+					current.Title = matches[2]
+				case "text":
+					current.Text = matches[2]
+				case "bigText":
+					// prefer the expanded text, which isn't ellipsized
+					current.Text = matches[2]
+				}
+			}
+		}
+	}
+	flush()
+
+	return notifications
+}
+
+// ListNotifications returns the notifications currently posted on the device
+func (d *AndroidDevice) ListNotifications() ([]Notification, error) {
+	output, err := d.runAdbCommand("shell", "dumpsys", "notification", "--noredact")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump notifications: %v", err)
+	}
+
+	return parseDumpsysNotifications(string(output)), nil
+}
+
+// serviceCallSucceeded reports whether 'service call' output is a reply
+// without an exception, e.g. "Result: Parcel(00000000    '....')"
+func serviceCallSucceeded(output string) bool {
+	return strings.Contains(output, "Result: Parcel(00000000")
+}
+
+// apiLevel returns the device's API level, or 0 if it can't be read
+func (d *AndroidDevice) apiLevel() int {
+	output, err := d.runAdbCommand("shell", "getprop", "ro.build.version.sdk")
+	if err != nil {
+		return 0
+	}
+	level, _ := strconv.Atoi(strings.TrimSpace(string(output)))
+	return level
+}
+
+// ClearNotifications dismisses all clearable notifications. On releases where
+// cancelAllNotifications' transaction code is known it's called directly;
+// elsewhere each notification is snoozed with 'cmd notification snooze'.
+func (d *AndroidDevice) ClearNotifications() error {
+	level := d.apiLevel()
+	if level >= cancelAllTransactionMinSDK && level <= cancelAllTransactionMaxSDK {
+		output, err := d.runAdbCommand("shell", "service", "call", "notification", "1")
+		if err == nil && serviceCallSucceeded(string(output)) {
+			return nil
+		}
+		utils.Verbose("cancelAllNotifications failed, snoozing notifications instead: %v %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return d.snoozeNotifications()
+}
+
+// snoozeNotifications hides every posted notification for
+// notificationSnoozeDuration
+func (d *AndroidDevice) snoozeNotifications() error {
+	notifications, err := d.ListNotifications()
+	if err != nil {
+		return err
+	}
+
+	// keys contain '|', which the device shell would take for a pipe
+	duration := strconv.FormatInt(notificationSnoozeDuration.Milliseconds(), 10)
+	for _, notification := range notifications {
+		output, err := d.runAdbCommand("shell", "cmd", "notification", "snooze", "--for", duration, shellescape.Quote(notification.Key))
+		if err != nil || strings.Contains(string(output), "Unknown command") {
+			return fmt.Errorf("failed to clear notifications: %v\nOutput: %s", err, string(output))
+		}
+	}
+
+	return nil
+}
+
+// OpenNotificationShade expands the notification shade
+func (d *AndroidDevice) OpenNotificationShade() error {
+	output, err := d.runAdbCommand("shell", "cmd", "statusbar", "expand-notifications")
+	if err != nil {
+		return fmt.Errorf("failed to open notification shade: %v\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package devices
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleDumpsysNotification = `Current Notification Manager state:
+  Notification List:
+    NotificationRecord(0x0a1b2c3d: pkg=com.example.chat user=UserHandle{0} id=7 tag=null importance=4 key=0|com.example.chat|7|null|10150: Notification(channel=messages shortcut=null contentView=null vibrate=null sound=null defaults=0x0 flags=0x10 color=0x00000000 actions=2 vis=PRIVATE))
+      uid=10150 userId=0
+      opPkg=com.example.chat
+      icon=Icon(typ=RESOURCE pkg=com.example.chat id=0x7f080001)
+      extras={
+        android.title=String (Alice)
+        android.text=String (See you at 5?)
+        android.appInfo=ApplicationInfo (ApplicationInfo{4a2b1c0 com.example.chat})
+        android.showWhen=Boolean (true)
+      }
+      actions={
+        [0] "Reply" -> PendingIntent{8f3e2a1: PendingIntentRecord{c1d2e3f com.example.chat broadcastIntent}}
+        [1] "Mark as read" -> PendingIntent{1a2b3c4: PendingIntentRecord{5d6e7f8 com.example.chat broadcastIntent}}
+      }
+    NotificationRecord(0x0b2c3d4e: pkg=android user=UserHandle{-1} id=17303299 tag=null importance=2 key=-1|android|17303299|null|1000: Notification(channel=DEVELOPER_IMPORTANT shortcut=null contentView=null vibrate=null sound=null defaults=0x0 flags=0x2 color=0xff607d8b vis=PUBLIC))
+      uid=1000 userId=-1
+      extras={
+        android.title=String (USB debugging connected)
+        android.text=String (Tap to turn off USB debugging)
+        android.bigText=String (Tap to turn off USB debugging. Select to disable USB debugging.)
+      }
+  Snoozed notifications:
+    NotificationRecord(0x0c3d4e5f: pkg=com.example.chat user=UserHandle{0} id=7 tag=null importance=4 key=0|com.example.chat|7|null|10150: Notification(channel=messages))
+`
+
+func TestParseDumpsysNotifications(t *testing.T) {
+	notifications := parseDumpsysNotifications(sampleDumpsysNotification)
+
+	assert.Equal(t, []Notification{
+		{
+			Key:     "0|com.example.chat|7|null|10150",
+			Package: "com.example.chat",
+			Title:   "Alice",
+			Text:    "See you at 5?",
+			Actions: []string{"Reply", "Mark as read"},
+		},
+		{
+			Key:     "-1|android|17303299|null|1000",
+			Package: "android",
+			Title:   "USB debugging connected",
+			Text:    "Tap to turn off USB debugging. Select to disable USB debugging.",
+		},
+	}, notifications)
+}
+
+func TestParseDumpsysNotificationsEmpty(t *testing.T) {
+	notifications := parseDumpsysNotifications("Current Notification Manager state:\n  Notification List:\n")
+	assert.Empty(t, notifications)
+	assert.NotNil(t, notifications)
+}
+
+// scriptedAdb answers adb commands with the output of the first script entry
+// their arguments contain
+type scriptedAdb struct {
+	utils.ExecRunner
+	script map[string]string
+	args   []string
+}
+
+func (f *scriptedAdb) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	args := strings.Join(cmd.Args[3:], " ")
+	f.args = append(f.args, args)
+	for command, output := range f.script {
+		if strings.Contains(args, command) {
+			return []byte(output), nil
+		}
+	}
+	return nil, nil
+}
+
+func TestClearNotificationsCancelsAll(t *testing.T) {
+	adb := &scriptedAdb{script: map[string]string{
+		"getprop":      "33\n",
+		"service call": "Result: Parcel(00000000    '....')\n",
+	}}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	assert.NoError(t, d.ClearNotifications())
+	assert.Equal(t, []string{"shell getprop ro.build.version.sdk", "shell service call notification 1"}, adb.args)
+}
+
+func TestClearNotificationsSnoozesOnUnknownReleases(t *testing.T) {
+	adb := &scriptedAdb{script: map[string]string{
+		"getprop": "35\n",
+		"dumpsys": sampleDumpsysNotification,
+	}}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	assert.NoError(t, d.ClearNotifications())
+	assert.Equal(t, []string{
+		"shell getprop ro.build.version.sdk",
+		"shell dumpsys notification --noredact",
+		"shell cmd notification snooze --for 86400000 '0|com.example.chat|7|null|10150'",
+		"shell cmd notification snooze --for 86400000 '-1|android|17303299|null|1000'",
+	}, adb.args)
+}
+
+func TestServiceCallSucceeded(t *testing.T) {
+	assert.True(t, serviceCallSucceeded("Result: Parcel(00000000    '....')\n"))
+	assert.False(t, serviceCallSucceeded("Result: Parcel(ffffffff 0000004c 00610053 'S.e.c.u.r.i.t.y.')\n"))
+}
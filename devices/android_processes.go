@@ -0,0 +1,83 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// lruProcessLine matches entries of the "Process LRU list" section of
+// 'dumpsys activity processes', e.g.
+//
+//	Proc #10: fg     T/A/TOP  LCM  t: 0 12345:com.example.app/u0a123 (top-activity)
+//	Proc # 5: cch+75 B/ /CEM  ---  t: 0 23456:com.example.app:remote/u0a123 (cch-empty)
+var lruProcessLine = regexp.MustCompile(`^\s*(?:Proc|PERS)\s*#\s*\d+:\s+(\S+)\s+(\S+)\s+.*?\s(\d+):([^/\s]+)/\S+`)
+
+// parseAndroidProcessState maps the oom adjustment and process state columns to
+// foreground, background or cached
+func parseAndroidProcessState(adj, schedAndProcState string) string {
+	procState := schedAndProcState[strings.LastIndex(schedAndProcState, "/")+1:]
+
+	switch {
+	case procState == "TOP":
+		return ProcessStateForeground
+	case strings.HasPrefix(adj, "cch") || strings.HasPrefix(procState, "C"):
+		return ProcessStateCached
+	default:
+		return ProcessStateBackground
+	}
+}
+
+// parseAndroidRunningApps parses 'dumpsys activity processes' output, keeping only
+// processes that belong to one of the given installed packages
+func parseAndroidRunningApps(output string, installed map[string]bool) []RunningAppInfo {
+	processes := make(map[string][]RunningProcess)
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := lruProcessLine.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		pid, err := strconv.Atoi(matches[3])
+		if err != nil {
+			continue
+		}
+
+		// secondary processes are named "<package>:<suffix>"
+		processName := matches[4]
+		packageName, _, _ := strings.Cut(processName, ":")
+		if !installed[packageName] {
+			continue
+		}
+
+		processes[packageName] = append(processes[packageName], RunningProcess{
+			PID:   pid,
+			Name:  processName,
+			State: parseAndroidProcessState(matches[1], matches[2]),
+		})
+	}
+
+	return groupRunningProcesses(processes)
+}
+
+// ListRunningApps returns installed apps that currently have running processes
+func (d *AndroidDevice) ListRunningApps() ([]RunningAppInfo, error) {
+	packages, err := d.listAllPackages("")
+	if err != nil {
+		return nil, err
+	}
+
+	installed := make(map[string]bool, len(packages))
+	for _, p := range packages {
+		installed[p.PackageName] = true
+	}
+
+	output, err := d.runAdbCommand("shell", "dumpsys", "activity", "processes")
+	if err != nil {
+		return nil, fmt.Errorf("failed to dump activity processes: %v", err)
+	}
+
+	return parseAndroidRunningApps(string(output), installed), nil
+}
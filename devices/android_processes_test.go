@@ -0,0 +1,51 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dumpsysActivityProcessesOutput = `ACTIVITY MANAGER RUNNING PROCESSES (dumpsys activity processes)
+  All known processes:
+  *APP* UID 10123 ProcessRecord{1a2b3c 12345:com.example.app/u0a123}
+    user #0 uid=10123 gids={50123, 20123, 9997}
+  Process LRU list (sorted by oom_adj, 6 total, non-act at 1, non-svc at 1):
+    PERS #5: sys    F/ /PER  LCM  t: 0 1234:system/1000 (fixed)
+    Proc # 4: fg     T/A/TOP  LCM  t: 0 12345:com.example.app/u0a123 (top-activity)
+    Proc # 3: prcp   F/ /IMPF ---  t: 0 2222:com.android.inputmethod.latin/u0a111 (service)
+    Proc # 2: cch+5  B/ /CEM  ---  t: 0 23456:com.example.app:remote/u0a123 (cch-empty)
+    Proc # 1: cch+15 B/ /CAC  ---  t: 0 3333:com.example.other/u0a124 (cch-act)
+    Proc # 0: vis    F/ /BFGS ---  t: 0 4444:com.android.systemui/u0a100 (service)
+  PID mappings:
+`
+
+func TestParseAndroidRunningApps(t *testing.T) {
+	installed := map[string]bool{
+		"com.example.app":               true,
+		"com.example.other":             true,
+		"com.android.inputmethod.latin": true,
+		"com.example.notrunning":        true,
+	}
+
+	apps := parseAndroidRunningApps(dumpsysActivityProcessesOutput, installed)
+	require.Len(t, apps, 3)
+
+	assert.Equal(t, "com.example.app", apps[0].PackageName)
+	assert.Equal(t, ProcessStateForeground, apps[0].State)
+	require.Len(t, apps[0].Processes, 2)
+	assert.Equal(t, RunningProcess{PID: 12345, Name: "com.example.app", State: ProcessStateForeground}, apps[0].Processes[0])
+	assert.Equal(t, RunningProcess{PID: 23456, Name: "com.example.app:remote", State: ProcessStateCached}, apps[0].Processes[1])
+
+	assert.Equal(t, "com.android.inputmethod.latin", apps[1].PackageName)
+	assert.Equal(t, ProcessStateBackground, apps[1].State)
+
+	assert.Equal(t, "com.example.other", apps[2].PackageName)
+	assert.Equal(t, ProcessStateCached, apps[2].State)
+}
+
+func TestParseAndroidRunningApps_Empty(t *testing.T) {
+	apps := parseAndroidRunningApps("", map[string]bool{"com.example.app": true})
+	assert.Empty(t, apps)
+}
@@ -0,0 +1,68 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// getpropLine matches a line of 'getprop' output, e.g. "[ro.product.model]: [Pixel 8]"
+var getpropLine = regexp.MustCompile(`^\[([^\]]+)\]: \[(.*)\]$`)
+
+// parseGetprop parses the output of 'getprop' into a map
+func parseGetprop(output string) map[string]string {
+	props := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		matches := getpropLine.FindStringSubmatch(strings.TrimSpace(line))
+		if matches != nil {
+			props[matches[1]] = matches[2]
+		}
+	}
+	return props
+}
+
+// firstProp returns the first non-empty value among the given property keys
+func firstProp(props map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := props[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// androidPropertiesFromGetprop maps raw system properties to DeviceProperties
+func androidPropertiesFromGetprop(props map[string]string) *DeviceProperties {
+	apiLevel, _ := strconv.Atoi(props["ro.build.version.sdk"])
+	density, _ := strconv.Atoi(firstProp(props, "ro.sf.lcd_density", "qemu.sf.lcd_density"))
+
+	var abis []string
+	if abilist := props["ro.product.cpu.abilist"]; abilist != "" {
+		abis = strings.Split(abilist, ",")
+	}
+
+	return &DeviceProperties{
+		Manufacturer:     props["ro.product.manufacturer"],
+		Model:            props["ro.product.model"],
+		ABI:              props["ro.product.cpu.abi"],
+		ABIs:             abis,
+		APILevel:         apiLevel,
+		OSVersion:        props["ro.build.version.release"],
+		BuildFingerprint: props["ro.build.fingerprint"],
+		Serial:           firstProp(props, "ro.serialno", "ro.boot.serialno"),
+		ScreenDensity:    density,
+		Locale:           firstProp(props, "persist.sys.locale", "ro.product.locale"),
+		Timezone:         props["persist.sys.timezone"],
+	}
+}
+
+// Properties reads all system properties with a single 'getprop' call
+func (d *AndroidDevice) Properties() (*DeviceProperties, error) {
+	output, err := d.runAdbCommand("shell", "getprop")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system properties: %v", err)
+	}
+
+	return androidPropertiesFromGetprop(parseGetprop(string(output))), nil
+}
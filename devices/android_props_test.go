@@ -0,0 +1,46 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleGetpropOutput = `[persist.sys.locale]: [en-US]
+[persist.sys.timezone]: [Europe/Berlin]
+[ro.build.fingerprint]: [google/shiba/shiba:14/AP1A.240305.019/11334212:user/release-keys]
+[ro.build.version.release]: [14]
+[ro.build.version.sdk]: [34]
+[ro.product.cpu.abi]: [arm64-v8a]
+[ro.product.cpu.abilist]: [arm64-v8a,armeabi-v7a,armeabi]
+[ro.product.manufacturer]: [Google]
+[ro.product.model]: [Pixel 8]
+[ro.serialno]: [3A281FDJH001ZP]
+[ro.sf.lcd_density]: [420]
+[ro.boot.serialno]: []
+`
+
+func TestAndroidPropertiesFromGetprop(t *testing.T) {
+	props := androidPropertiesFromGetprop(parseGetprop(sampleGetpropOutput))
+
+	assert.Equal(t, &DeviceProperties{
+		Manufacturer:     "Google",
+		Model:            "Pixel 8",
+		ABI:              "arm64-v8a",
+		ABIs:             []string{"arm64-v8a", "armeabi-v7a", "armeabi"},
+		APILevel:         34,
+		OSVersion:        "14",
+		BuildFingerprint: "google/shiba/shiba:14/AP1A.240305.019/11334212:user/release-keys",
+		Serial:           "3A281FDJH001ZP",
+		ScreenDensity:    420,
+		Locale:           "en-US",
+		Timezone:         "Europe/Berlin",
+	}, props)
+}
+
+func TestAndroidPropertiesFallsBackForEmulatorDensity(t *testing.T) {
+	props := androidPropertiesFromGetprop(parseGetprop("[qemu.sf.lcd_density]: [440]\n[ro.product.locale]: [fr-FR]\n"))
+
+	assert.Equal(t, 440, props.ScreenDensity)
+	assert.Equal(t, "fr-FR", props.Locale)
+}
@@ -0,0 +1,74 @@
+package devices
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReversePort makes devicePort on the device reach hostPort on the host, so
+// apps can call a server the host runs at localhost:devicePort. It lasts
+// until removed, the device disconnects or the adb server restarts.
+func (d *AndroidDevice) ReversePort(devicePort, hostPort int) error {
+	output, err := d.runAdbCommand("reverse", fmt.Sprintf("tcp:%d", devicePort), fmt.Sprintf("tcp:%d", hostPort))
+	if err != nil {
+		return fmt.Errorf("adb reverse failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// RemoveReverse stops reversing devicePort, or every port when it's 0
+func (d *AndroidDevice) RemoveReverse(devicePort int) error {
+	args := []string{"reverse", "--remove-all"}
+	if devicePort != 0 {
+		args = []string{"reverse", "--remove", fmt.Sprintf("tcp:%d", devicePort)}
+	}
+
+	output, err := d.runAdbCommand(args...)
+	if err != nil {
+		return fmt.Errorf("adb reverse --remove failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ListReverses returns the device's reversed TCP ports, by port
+func (d *AndroidDevice) ListReverses() ([]PortReverse, error) {
+	output, err := d.runAdbCommand("reverse", "--list")
+	if err != nil {
+		return nil, fmt.Errorf("adb reverse --list failed: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseAdbReverseList(d.id, string(output)), nil
+}
+
+// parseAdbReverseList parses 'adb reverse --list', a line per port such as
+// "UsbFfs tcp:8080 tcp:3000". Other kinds of sockets, such as
+// localabstract, are left out.
+func parseAdbReverseList(deviceID, output string) []PortReverse {
+	reverses := []PortReverse{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+
+		devicePort, errDevice := parseTCPSocket(fields[1])
+		hostPort, errHost := parseTCPSocket(fields[2])
+		if errDevice != nil || errHost != nil {
+			continue
+		}
+		reverses = append(reverses, PortReverse{DeviceID: deviceID, DevicePort: devicePort, HostPort: hostPort})
+	}
+
+	sort.Slice(reverses, func(i, j int) bool { return reverses[i].DevicePort < reverses[j].DevicePort })
+	return reverses
+}
+
+// parseTCPSocket parses an adb socket spec such as "tcp:8080"
+func parseTCPSocket(spec string) (int, error) {
+	port, ok := strings.CutPrefix(spec, "tcp:")
+	if !ok {
+		return 0, fmt.Errorf("not a tcp socket: %s", spec)
+	}
+	return strconv.Atoi(port)
+}
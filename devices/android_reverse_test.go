@@ -0,0 +1,18 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAdbReverseList(t *testing.T) {
+	output := "UsbFfs tcp:9000 tcp:9000\nUsbFfs tcp:8080 tcp:3000\nhost-19 localabstract:chrome_devtools_remote tcp:9222\n\n"
+
+	assert.Equal(t, []PortReverse{
+		{DeviceID: "R58M123ABC", DevicePort: 8080, HostPort: 3000},
+		{DeviceID: "R58M123ABC", DevicePort: 9000, HostPort: 9000},
+	}, parseAdbReverseList("R58M123ABC", output))
+
+	assert.Equal(t, []PortReverse{}, parseAdbReverseList("R58M123ABC", ""))
+}
@@ -0,0 +1,96 @@
+package devices
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RootStatus describes whether a device gives root access, to adbd or
+// through su
+type RootStatus struct {
+	// BuildType is ro.build.type: "user" on production builds, "userdebug"
+	// or "eng" on builds where 'adb root' works
+	BuildType  string `json:"buildType"`
+	Debuggable bool   `json:"debuggable"`
+
+	// AdbRoot is true when adbd runs as root, after 'adb root'
+	AdbRoot bool `json:"adbRoot"`
+	Su      bool `json:"su"`
+	Rooted  bool `json:"rooted"`
+
+	// RootAvailable is true when 'adb root' can be used
+	RootAvailable bool `json:"rootAvailable"`
+
+	// Remounted is set by EnableRoot, RebootRequired when remounting
+	// disabled verity and the device must reboot and remount again
+	Remounted      bool `json:"remounted,omitempty"`
+	RebootRequired bool `json:"rebootRequired,omitempty"`
+}
+
+// adbRootTimeout bounds waiting for adbd to come back after restarting as root
+const adbRootTimeout = 30 * time.Second
+
+// rootStatusFromProps fills the build related fields of a RootStatus
+func rootStatusFromProps(props map[string]string) *RootStatus {
+	status := &RootStatus{
+		BuildType:  props["ro.build.type"],
+		Debuggable: props["ro.debuggable"] == "1",
+	}
+	status.RootAvailable = status.Debuggable && status.BuildType != "user"
+	return status
+}
+
+// RootStatus reports the build type and whether adbd runs as root or su is
+// available
+func (d *AndroidDevice) RootStatus() (*RootStatus, error) {
+	output, err := d.runAdbCommand("shell", "getprop")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system properties: %w", err)
+	}
+	status := rootStatusFromProps(parseGetprop(string(output)))
+
+	output, err = d.runAdbCommand("shell", "id", "-u")
+	status.AdbRoot = err == nil && strings.TrimSpace(string(output)) == "0"
+
+	output, err = d.runAdbCommand("shell", "su", "0", "id", "-u")
+	status.Su = err == nil && strings.TrimSpace(string(output)) == "0"
+
+	status.Rooted = status.AdbRoot || status.Su
+	return status, nil
+}
+
+// EnableRoot restarts adbd as root and remounts the system partitions
+// read-write, which only userdebug and eng builds allow
+func (d *AndroidDevice) EnableRoot() (*RootStatus, error) {
+	status, err := d.RootStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	if !status.AdbRoot {
+		if !status.RootAvailable {
+			return nil, fmt.Errorf("adb root is not available on production builds (ro.build.type=%s), use a userdebug build or an emulator image without Google Play", status.BuildType)
+		}
+
+		output, err := d.runAdbCommand("root")
+		if err != nil || strings.Contains(string(output), "cannot run as root") {
+			return nil, fmt.Errorf("adb root failed: %s", strings.TrimSpace(string(output)))
+		}
+
+		// adbd restarts, so the device briefly goes away
+		if output, err := d.runAdbCommandTimeout(adbRootTimeout, "wait-for-device"); err != nil {
+			return nil, fmt.Errorf("device did not come back after adb root: %w: %s", err, strings.TrimSpace(string(output)))
+		}
+		status.AdbRoot = true
+		status.Rooted = true
+	}
+
+	output, err := d.runAdbCommand("remount")
+	if err != nil {
+		return nil, fmt.Errorf("adb remount failed: %s", strings.TrimSpace(string(output)))
+	}
+	status.RebootRequired = strings.Contains(strings.ToLower(string(output)), "reboot")
+	status.Remounted = !status.RebootRequired
+	return status, nil
+}
@@ -0,0 +1,24 @@
+package devices
+
+import "testing"
+
+func TestRootStatusFromProps(t *testing.T) {
+	cases := []struct {
+		buildType  string
+		debuggable string
+		want       bool
+	}{
+		{"user", "0", false},
+		{"user", "1", false},
+		{"userdebug", "1", true},
+		{"eng", "1", true},
+		{"userdebug", "0", false},
+	}
+
+	for _, c := range cases {
+		status := rootStatusFromProps(map[string]string{"ro.build.type": c.buildType, "ro.debuggable": c.debuggable})
+		if status.RootAvailable != c.want {
+			t.Errorf("%s build with ro.debuggable=%s: rootAvailable = %v, want %v", c.buildType, c.debuggable, status.RootAvailable, c.want)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// wakefulnessLine matches the power manager's wakefulness, e.g. "mWakefulness=Awake"
+	wakefulnessLine = regexp.MustCompile(`mWakefulness=(\w+)`)
+
+	// displayPowerLine matches the display state on older releases, e.g. "Display Power: state=ON"
+	displayPowerLine = regexp.MustCompile(`Display Power: state=(\w+)`)
+
+	// keyguardShowingLine matches any of the flags 'dumpsys window' uses across
+	// releases to report that the keyguard is showing
+	keyguardShowingLine = regexp.MustCompile(`\b(mShowingLockscreen|mDreamingLockscreen|isStatusBarKeyguard|mKeyguardShowing)=true\b`)
+
+	// windowStateLine matches what 'dumpsys window displays' reports about the
+	// focused window and app, the keyboard's target and the display rotation
+	windowStateLine = regexp.MustCompile(`(?m)\b(?:mCurrentFocus|mFocusedApp|mInputMethodTarget|mImeLayeringTarget)=.*$|\bmRotation=\d`)
+)
+
+// parseAndroidScreenOn reports whether 'dumpsys power' says the screen is on
+func parseAndroidScreenOn(output string) (bool, error) {
+	if matches := wakefulnessLine.FindStringSubmatch(output); matches != nil {
+		return matches[1] == "Awake", nil
+	}
+
+	if matches := displayPowerLine.FindStringSubmatch(output); matches != nil {
+		return matches[1] == "ON", nil
+	}
+
+	return false, fmt.Errorf("screen state not found in dumpsys power output")
+}
+
+// parseAndroidKeyguardShowing reports whether 'dumpsys window' says the keyguard is showing
+func parseAndroidKeyguardShowing(output string) bool {
+	return keyguardShowingLine.MatchString(output)
+}
+
+// parseAndroidWindowState returns the window state lines of 'dumpsys window
+// displays' output, which change when another window, activity or keyboard
+// takes the screen
+func parseAndroidWindowState(output string) (string, error) {
+	if !strings.Contains(output, "mCurrentFocus=") {
+		return "", fmt.Errorf("focused window not found in dumpsys window output")
+	}
+
+	lines := windowStateLine.FindAllString(output, -1)
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// ScreenFingerprint returns the focused window and activity, keyboard target
+// and rotation, which a single dumpsys reports far quicker than a screenshot
+// can be taken
+func (d *AndroidDevice) ScreenFingerprint() (string, error) {
+	output, err := d.runAdbCommand("shell", "dumpsys", "window", "displays")
+	if err != nil {
+		return "", fmt.Errorf("failed to get window displays: %v", err)
+	}
+
+	return parseAndroidWindowState(string(output))
+}
+
+// ScreenState reports whether the screen is on, off or showing the keyguard
+func (d *AndroidDevice) ScreenState() (string, error) {
+	output, err := d.runAdbCommand("shell", "dumpsys", "power")
+	if err != nil {
+		return "", fmt.Errorf("failed to get power state: %v", err)
+	}
+
+	on, err := parseAndroidScreenOn(string(output))
+	if err != nil {
+		return "", err
+	}
+
+	if !on {
+		return ScreenStateOff, nil
+	}
+
+	output, err = d.runAdbCommand("shell", "dumpsys", "window")
+	if err != nil {
+		return "", fmt.Errorf("failed to get window state: %v", err)
+	}
+
+	if parseAndroidKeyguardShowing(string(output)) {
+		return ScreenStateLocked, nil
+	}
+
+	return ScreenStateOn, nil
+}
+
+// Wake turns the screen on, leaving the keyguard up. It does nothing when
+// the screen is already on.
+func (d *AndroidDevice) Wake() error {
+	output, err := d.runAdbCommand("shell", "input", "keyevent", "KEYCODE_WAKEUP")
+	if err != nil {
+		return fmt.Errorf("failed to wake device: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// ScreenOff turns the screen off. It does nothing when the screen is
+// already off.
+func (d *AndroidDevice) ScreenOff() error {
+	output, err := d.runAdbCommand("shell", "input", "keyevent", "KEYCODE_SLEEP")
+	if err != nil {
+		return fmt.Errorf("failed to turn screen off: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// Lock turns the screen off, which shows the keyguard when it is next woken
+func (d *AndroidDevice) Lock() error {
+	return d.ScreenOff()
+}
+
+// swipeUpKeyguard swipes up from the bottom of the screen, which dismisses
+// an insecure keyguard on releases that ignore KEYCODE_MENU
+func (d *AndroidDevice) swipeUpKeyguard() error {
+	info, err := d.Info()
+	if err != nil {
+		return err
+	}
+
+	width, height := info.ScreenSize.Width, info.ScreenSize.Height
+	return d.Swipe(width/2, height*9/10, width/2, height*3/10, 300)
+}
+
+// Unlock wakes the screen and dismisses an insecure keyguard. A keyguard
+// protected by a PIN, pattern or password can't be dismissed and is an error.
+func (d *AndroidDevice) Unlock() error {
+	if err := d.Wake(); err != nil {
+		return err
+	}
+
+	// KEYCODE_MENU dismisses the keyguard on most releases, 'wm
+	// dismiss-keyguard' or swiping it up on the rest
+	for _, dismiss := range []func() error{
+		func() error { return d.sendKeyguardCommand("shell", "input", "keyevent", "KEYCODE_MENU") },
+		func() error { return d.sendKeyguardCommand("shell", "wm", "dismiss-keyguard") },
+		d.swipeUpKeyguard,
+	} {
+		state, err := d.ScreenState()
+		if err != nil {
+			return err
+		}
+
+		if state == ScreenStateOn {
+			return nil
+		}
+
+		if err := dismiss(); err != nil {
+			return fmt.Errorf("failed to dismiss keyguard: %v", err)
+		}
+
+		// give the keyguard time to animate away
+		time.Sleep(500 * time.Millisecond)
+	}
+
+	state, err := d.ScreenState()
+	if err != nil {
+		return err
+	}
+
+	if state != ScreenStateOn {
+		return fmt.Errorf("device is still %s, a PIN, pattern or password may be set", state)
+	}
+
+	return nil
+}
+
+// sendKeyguardCommand runs an adb command that dismisses the keyguard
+func (d *AndroidDevice) sendKeyguardCommand(args ...string) error {
+	output, err := d.runAdbCommand(args...)
+	if err != nil {
+		return fmt.Errorf("%v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// stayOnBackupPath is where SetStayAwake saves stay_on_while_plugged_in before
+// changing it, so it can be restored by a later invocation
+const stayOnBackupPath = "/data/local/tmp/.mobilecli-stayon"
+
+// parseStayOnValue validates a stay_on_while_plugged_in value, a bitmask of the
+// power sources (AC, USB, wireless, dock) that keep the screen on
+func parseStayOnValue(output string) (string, error) {
+	value := strings.TrimSpace(output)
+	if value == "null" || value == "" {
+		return "0", nil
+	}
+
+	if _, err := strconv.Atoi(value); err != nil {
+		return "", fmt.Errorf("unexpected stay_on_while_plugged_in value '%s'", value)
+	}
+
+	return value, nil
+}
+
+// SetStayAwake keeps the screen on while the device is plugged in. The
+// original setting is saved on the device the first time, and put back when
+// stay-awake is turned off.
+func (d *AndroidDevice) SetStayAwake(enabled bool) error {
+	backup, err := d.runAdbCommand("shell", "cat", stayOnBackupPath, "2>/dev/null")
+	hasBackup := err == nil && strings.TrimSpace(string(backup)) != ""
+
+	if !enabled {
+		if !hasBackup {
+			output, err := d.runAdbCommand("shell", "svc", "power", "stayon", "false")
+			if err != nil {
+				return fmt.Errorf("failed to disable stay-awake: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+			}
+			return nil
+		}
+
+		original, err := parseStayOnValue(string(backup))
+		if err != nil {
+			return err
+		}
+
+		output, err := d.runAdbCommand("shell", "settings", "put", "global", "stay_on_while_plugged_in", original)
+		if err != nil {
+			return fmt.Errorf("failed to restore stay_on_while_plugged_in: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+		}
+
+		_, _ = d.runAdbCommand("shell", "rm", "-f", stayOnBackupPath)
+		return nil
+	}
+
+	if !hasBackup {
+		output, err := d.runAdbCommand("shell", "settings", "get", "global", "stay_on_while_plugged_in")
+		if err != nil {
+			return fmt.Errorf("failed to read stay_on_while_plugged_in: %v", err)
+		}
+
+		original, err := parseStayOnValue(string(output))
+		if err != nil {
+			return err
+		}
+
+		output, err = d.runAdbCommand("shell", "echo", original, ">", stayOnBackupPath)
+		if err != nil {
+			return fmt.Errorf("failed to save stay_on_while_plugged_in: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	output, err := d.runAdbCommand("shell", "svc", "power", "stayon", "true")
+	if err != nil {
+		return fmt.Errorf("failed to enable stay-awake: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// PointerLocation reports whether the developer option showing the pointer
+// location overlay is on
+func (d *AndroidDevice) PointerLocation() (bool, error) {
+	output, err := d.runAdbCommand("shell", "settings", "get", "system", "pointer_location")
+	if err != nil {
+		return false, fmt.Errorf("failed to read pointer_location: %v", err)
+	}
+	return strings.TrimSpace(string(output)) == "1", nil
+}
+
+// SetPointerLocation turns the pointer location overlay on or off, which
+// draws touches and their coordinates over every app
+func (d *AndroidDevice) SetPointerLocation(enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+
+	output, err := d.runAdbCommand("shell", "settings", "put", "system", "pointer_location", value)
+	if err != nil {
+		return fmt.Errorf("failed to set pointer_location: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SetWindowVisible shows or hides the window of a running emulator through
+// its console. Emulators booted by mobilecli start with their window hidden.
+func (d *AndroidDevice) SetWindowVisible(visible bool) error {
+	action := "hide"
+	if visible {
+		action = "show"
+	}
+	return d.runEmulatorCommand("window", action)
+}
@@ -0,0 +1,87 @@
+package devices
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAndroidScreenOn(t *testing.T) {
+	on, err := parseAndroidScreenOn("POWER MANAGER (dumpsys power)\n\nPower Manager State:\n  mDirty=0x0\n  mWakefulness=Awake\n  mWakefulnessChanging=false\n")
+	assert.NoError(t, err)
+	assert.True(t, on)
+
+	on, err = parseAndroidScreenOn("  mWakefulness=Dozing\n")
+	assert.NoError(t, err)
+	assert.False(t, on)
+
+	on, err = parseAndroidScreenOn("Display Power: state=OFF\n")
+	assert.NoError(t, err)
+	assert.False(t, on)
+
+	_, err = parseAndroidScreenOn("unexpected output\n")
+	assert.Error(t, err)
+}
+
+func TestParseAndroidKeyguardShowing(t *testing.T) {
+	assert.True(t, parseAndroidKeyguardShowing("    mShowingLockscreen=true mShowingDream=false mDreamingLockscreen=false"))
+	assert.True(t, parseAndroidKeyguardShowing("  KeyguardController:\n    mKeyguardShowing=true\n    mAodShowing=false\n"))
+	assert.False(t, parseAndroidKeyguardShowing("  KeyguardController:\n    mKeyguardShowing=false\n    mAodShowing=false\n"))
+	assert.False(t, parseAndroidKeyguardShowing("    mShowingLockscreen=false mShowingDream=false mDreamingLockscreen=false"))
+}
+
+func TestParseAndroidWindowState(t *testing.T) {
+	output := `WINDOW MANAGER DISPLAY CONTENTS (dumpsys window displays)
+  Display: mDisplayId=0 rootTasks=3
+    init=1080x2400 420dpi cur=1080x2400 app=1080x2274 rng=1080x1017-2274x2211
+    mLayoutSeq=412
+    mDisplayRotation=ROTATION_0 mRotation=0 mLastOrientation=-1
+  mCurrentFocus=Window{4c2a9e8 u0 com.android.settings/com.android.settings.Settings}
+  mFocusedApp=ActivityRecord{8f1b2c7 u0 com.android.settings/.Settings t12}
+    mImeLayeringTarget=Window{4c2a9e8 u0 com.android.settings/com.android.settings.Settings}
+`
+	state, err := parseAndroidWindowState(output)
+	assert.NoError(t, err)
+	assert.Equal(t, "mRotation=0\n"+
+		"mCurrentFocus=Window{4c2a9e8 u0 com.android.settings/com.android.settings.Settings}\n"+
+		"mFocusedApp=ActivityRecord{8f1b2c7 u0 com.android.settings/.Settings t12}\n"+
+		"mImeLayeringTarget=Window{4c2a9e8 u0 com.android.settings/com.android.settings.Settings}", state)
+
+	// a new activity of the same app changes the state
+	other, err := parseAndroidWindowState(strings.Replace(output, "8f1b2c7", "1d3e5f9", 1))
+	assert.NoError(t, err)
+	assert.NotEqual(t, state, other)
+
+	_, err = parseAndroidWindowState("unexpected output\n")
+	assert.Error(t, err)
+}
+
+func TestParseStayOnValue(t *testing.T) {
+	value, err := parseStayOnValue("3\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "3", value)
+
+	value, err = parseStayOnValue("null\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "0", value)
+
+	_, err = parseStayOnValue("Error: unknown setting\n")
+	assert.Error(t, err)
+}
+
+func TestAndroidSetWindowVisible(t *testing.T) {
+	adb := &fakeAdb{output: "OK\n"}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	assert.NoError(t, d.SetWindowVisible(true))
+	assert.NoError(t, d.SetWindowVisible(false))
+	assert.Equal(t, []string{"-s", "emulator-5554", "emu", "window", "show"}, adb.args[0])
+	assert.Equal(t, []string{"-s", "emulator-5554", "emu", "window", "hide"}, adb.args[1])
+
+	real := &AndroidDevice{id: "R5CT1234", transportID: "R5CT1234"}
+	assert.Error(t, real.SetWindowVisible(true))
+}
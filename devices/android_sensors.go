@@ -0,0 +1,73 @@
+package devices
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emulatorSensors are the sensors the emulator console can set, with the
+// number of values each takes
+var emulatorSensors = map[string]int{
+	"acceleration":                3,
+	"gyroscope":                   3,
+	"magnetic-field":              3,
+	"orientation":                 3,
+	"temperature":                 1,
+	"proximity":                   1,
+	"light":                       1,
+	"pressure":                    1,
+	"humidity":                    1,
+	"magnetic-field-uncalibrated": 3,
+	"gyroscope-uncalibrated":      3,
+	"acceleration-uncalibrated":   3,
+	"heart-rate":                  1,
+	"rgbc-light":                  4,
+	"wrist-tilt":                  1,
+}
+
+// emulatorSensorArgs formats values the way the emulator console takes them,
+// e.g. "0:9.8:0"
+func emulatorSensorArgs(name string, values []float64) (string, error) {
+	count, ok := emulatorSensors[name]
+	if !ok {
+		return "", fmt.Errorf("unknown sensor '%s'", name)
+	}
+	if len(values) != count {
+		return "", fmt.Errorf("sensor %s takes %d values, got %d", name, count, len(values))
+	}
+
+	formatted := make([]string, len(values))
+	for i, v := range values {
+		formatted[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(formatted, ":"), nil
+}
+
+// SetSensor sets a sensor of the emulator through its console, until it is
+// set again
+func (d *AndroidDevice) SetSensor(name string, values []float64) error {
+	args, err := emulatorSensorArgs(name, values)
+	if err != nil {
+		return err
+	}
+	return d.runEmulatorCommand("sensor", "set", name, args)
+}
+
+// shakeAccelerations is a burst of sideways accelerations, strong enough for
+// the usual shake detectors
+var shakeAccelerations = [][]float64{
+	{25, 9.8, 0}, {-25, 9.8, 0}, {25, 9.8, 0}, {-25, 9.8, 0}, {0, 9.8, 0},
+}
+
+// Shake plays a short burst of accelerations
+func (d *AndroidDevice) Shake() error {
+	for _, acceleration := range shakeAccelerations {
+		if err := d.SetSensor("acceleration", acceleration); err != nil {
+			return err
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	return nil
+}
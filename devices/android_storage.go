@@ -0,0 +1,54 @@
+package devices
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// androidFillerDir is writable by the shell user and lives on /data, the
+// volume apps install to and store their data on
+const androidFillerDir = "/data/local/tmp"
+
+// StorageInfo reports the space of the data and shared storage volumes
+func (d *AndroidDevice) StorageInfo() ([]StorageVolume, error) {
+	output, err := d.runAdbCommand("shell", "df", "-k", "/data", "/sdcard")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage info: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseDfOutput(string(output))
+}
+
+// FillStorage allocates a filler file on /data, leaving leaveFree bytes
+func (d *AndroidDevice) FillStorage(leaveFree int64) (*StorageFillResult, error) {
+	output, err := d.runAdbCommand("shell", "df", "-k", "/data")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage info: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	volumes, err := parseDfOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := fillerSize(volumes[0].FreeBytes, leaveFree)
+	if err != nil {
+		return nil, err
+	}
+
+	filler := path.Join(androidFillerDir, storageFillerName)
+	if output, err := d.runAdbCommandTimeout(longCommandTimeout, "shell", "fallocate", "-l", strconv.FormatInt(size, 10), filler); err != nil {
+		return nil, fmt.Errorf("failed to allocate %s: %w: %s", filler, err, strings.TrimSpace(string(output)))
+	}
+
+	return &StorageFillResult{Path: filler, FilledBytes: size, FreeBytes: leaveFree}, nil
+}
+
+// FreeStorage removes the filler file
+func (d *AndroidDevice) FreeStorage() error {
+	filler := path.Join(androidFillerDir, storageFillerName)
+	if output, err := d.runAdbCommand("shell", "rm", "-f", filler); err != nil {
+		return fmt.Errorf("failed to remove %s: %w: %s", filler, err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package devices
+
+import "fmt"
+
+// OpenControlCenter expands quick settings, Android's panel of toggles
+func (d *AndroidDevice) OpenControlCenter() error {
+	output, err := d.runAdbCommand("shell", "cmd", "statusbar", "expand-settings")
+	if err != nil {
+		return fmt.Errorf("failed to open quick settings: %v\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// CloseControlCenter collapses quick settings and the notification shade
+func (d *AndroidDevice) CloseControlCenter() error {
+	output, err := d.runAdbCommand("shell", "cmd", "statusbar", "collapse")
+	if err != nil {
+		return fmt.Errorf("failed to close quick settings: %v\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
+
+// OpenSettings opens the Settings app
+func (d *AndroidDevice) OpenSettings() error {
+	output, err := d.runAdbCommand("shell", "am", "start", "-a", "android.settings.SETTINGS")
+	if err != nil {
+		return fmt.Errorf("failed to open Settings: %v\nOutput: %s", err, string(output))
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// phoneNumberRe matches the numbers the emulator's modem accepts
+var phoneNumberRe = regexp.MustCompile(`^\+?[0-9#*]+$`)
+
+func checkPhoneNumber(number string) error {
+	if !phoneNumberRe.MatchString(number) {
+		return fmt.Errorf("invalid phone number '%s', expected digits such as 5551234", number)
+	}
+	return nil
+}
+
+// IncomingCall makes the emulator's modem ring with a call from number
+func (d *AndroidDevice) IncomingCall(number string) error {
+	if err := checkPhoneNumber(number); err != nil {
+		return err
+	}
+	return d.runEmulatorCommand("gsm", "call", number)
+}
+
+// EndCall hangs up a call from or to number
+func (d *AndroidDevice) EndCall(number string) error {
+	if err := checkPhoneNumber(number); err != nil {
+		return err
+	}
+	return d.runEmulatorCommand("gsm", "cancel", number)
+}
+
+// ReceiveSMS delivers a text message from number
+func (d *AndroidDevice) ReceiveSMS(number, text string) error {
+	if err := checkPhoneNumber(number); err != nil {
+		return err
+	}
+	return d.runEmulatorCommand("sms", "send", number, text)
+}
@@ -0,0 +1,65 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// batteryTemperatureRe matches the temperature in 'dumpsys battery', in
+	// tenths of a degree Celsius
+	batteryTemperatureRe = regexp.MustCompile(`(?m)^\s*temperature:\s*(-?\d+)`)
+
+	// thermalStatusRe matches the status in 'dumpsys thermalservice', which
+	// exists from Android 10
+	thermalStatusRe = regexp.MustCompile(`(?m)^\s*Thermal Status:\s*(\d+)`)
+)
+
+// parseBatteryTemperature returns the battery temperature in 'dumpsys
+// battery' output, in degrees Celsius
+func parseBatteryTemperature(output string) (float64, error) {
+	matches := batteryTemperatureRe.FindStringSubmatch(output)
+	if matches == nil {
+		return 0, fmt.Errorf("no battery temperature in dumpsys output")
+	}
+	tenths, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid battery temperature %q: %w", matches[1], err)
+	}
+	return float64(tenths) / 10, nil
+}
+
+// parseThermalStatus returns the status in 'dumpsys thermalservice' output,
+// or "" when there is none
+func parseThermalStatus(output string) string {
+	matches := thermalStatusRe.FindStringSubmatch(output)
+	if matches == nil {
+		return ""
+	}
+	status, err := strconv.Atoi(matches[1])
+	if err != nil || status >= len(thermalStatuses) {
+		return ""
+	}
+	return thermalStatuses[status]
+}
+
+// ReadThermal returns the battery temperature and, from Android 10, the
+// thermal status
+func (d *AndroidDevice) ReadThermal() (*ThermalReading, error) {
+	output, err := d.runAdbCommand("shell", "dumpsys", "battery")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read battery state: %w", err)
+	}
+
+	temperature, err := parseBatteryTemperature(string(output))
+	if err != nil {
+		return nil, err
+	}
+
+	reading := &ThermalReading{BatteryTemperature: temperature}
+	if output, err := d.runAdbCommand("shell", "dumpsys", "thermalservice"); err == nil {
+		reading.ThermalStatus = parseThermalStatus(string(output))
+	}
+	return reading, nil
+}
@@ -0,0 +1,98 @@
+package devices
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// androidRootCommand returns the prefix that runs a shell command as root:
+// nothing when adbd runs as root (adb root), "su 0" on images that ship su,
+// or an error when the device isn't rooted
+func (d *AndroidDevice) androidRootCommand() ([]string, error) {
+	output, err := d.runAdbCommand("shell", "id", "-u")
+	if err == nil && strings.TrimSpace(string(output)) == "0" {
+		return nil, nil
+	}
+
+	output, err = d.runAdbCommand("shell", "su", "0", "id", "-u")
+	if err == nil && strings.TrimSpace(string(output)) == "0" {
+		return []string{"su", "0"}, nil
+	}
+
+	return nil, fmt.Errorf("changing the time requires root, run 'adb root' first (emulator images without Google Play allow it)")
+}
+
+// setSystemTime sets the device clock, as root with the root prefix
+func (d *AndroidDevice) setSystemTime(root []string, t time.Time) error {
+	// toybox date takes MMDDhhmmCCYY.ss
+	args := append([]string{"shell"}, root...)
+	args = append(args, "date", "-u", t.UTC().Format("010215042006.05"))
+	if output, err := d.runAdbCommand(args...); err != nil {
+		return fmt.Errorf("failed to set time: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	// lets apps and the status bar pick up the new time
+	if _, err := d.runAdbCommand("shell", "am", "broadcast", "-a", "android.intent.action.TIME_SET"); err != nil {
+		return fmt.Errorf("failed to broadcast the time change: %w", err)
+	}
+	return nil
+}
+
+// SetTime turns off automatic time, which would revert it, and sets the
+// device clock. Automatic time is only turned off once the device is known to
+// allow setting the clock, and is turned back on if setting it fails.
+func (d *AndroidDevice) SetTime(t time.Time) (string, error) {
+	root, err := d.androidRootCommand()
+	if err != nil {
+		return "", err
+	}
+
+	output, err := d.runAdbCommand("shell", "settings", "get", "global", "auto_time")
+	if err != nil {
+		return "", fmt.Errorf("failed to read automatic time: %w", err)
+	}
+	previous := strings.TrimSpace(string(output))
+
+	if _, err := d.runAdbCommand("shell", "settings", "put", "global", "auto_time", "0"); err != nil {
+		d.restoreAutoTime(previous)
+		return "", fmt.Errorf("failed to turn off automatic time: %w", err)
+	}
+
+	if err := d.setSystemTime(root, t); err != nil {
+		d.restoreAutoTime(previous)
+		return "", err
+	}
+	return ClockScopeSystem, nil
+}
+
+// restoreAutoTime puts automatic time back to what 'settings get' read, on
+// when it was unset
+func (d *AndroidDevice) restoreAutoTime(previous string) {
+	if previous != "0" {
+		previous = "1"
+	}
+	if output, err := d.runAdbCommand("shell", "settings", "put", "global", "auto_time", previous); err != nil {
+		utils.Verbose("Failed to restore automatic time on %s: %v: %s", d.ID(), err, strings.TrimSpace(string(output)))
+	}
+}
+
+// SyncTime sets the device clock to the host's and turns automatic time back
+// on. Emulators without network time keep the host's time.
+func (d *AndroidDevice) SyncTime() error {
+	root, err := d.androidRootCommand()
+	if err != nil {
+		return err
+	}
+
+	if err := d.setSystemTime(root, time.Now()); err != nil {
+		return err
+	}
+
+	if _, err := d.runAdbCommand("shell", "settings", "put", "global", "auto_time", "1"); err != nil {
+		return fmt.Errorf("failed to turn on automatic time: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAndroidDeviceTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		transportID string
+		state       string
+		expected    string
+	}{
+		{"usb serial", "R58M123ABC", "online", TransportUSB},
+		{"adb connect", "192.168.1.20:5555", "online", TransportNetwork},
+		{"wireless debugging", "adb-R58M123ABC-xYz._adb-tls-connect._tcp", "online", TransportNetwork},
+		{"emulator", "emulator-5554", "online", ""},
+		{"offline emulator", "", "offline", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &AndroidDevice{transportID: tt.transportID, state: tt.state}
+			assert.Equal(t, tt.expected, d.Transport())
+		})
+	}
+}
+
+func TestParseAdbDevicesLine(t *testing.T) {
+	entry, ok := parseAdbDevicesLine("R58M123ABC             device usb:1-1.4 product:a52qnsxx model:SM_A525F device:a52q transport_id:3")
+	assert.True(t, ok)
+	assert.Equal(t, adbDevicesEntry{serial: "R58M123ABC", state: "device", usbPath: "1-1.4", transportID: "3"}, entry)
+
+	entry, ok = parseAdbDevicesLine("192.168.1.20:5555      device product:a52qnsxx model:SM_A525F device:a52q transport_id:7")
+	assert.True(t, ok)
+	assert.Equal(t, adbDevicesEntry{serial: "192.168.1.20:5555", state: "device", transportID: "7"}, entry)
+
+	entry, ok = parseAdbDevicesLine("R58M123ABC unauthorized usb:1-1.4 transport_id:4")
+	assert.True(t, ok)
+	assert.Equal(t, "unauthorized", entry.state)
+
+	_, ok = parseAdbDevicesLine("")
+	assert.False(t, ok)
+}
@@ -0,0 +1,190 @@
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"al.essio.dev/pkg/shellescape"
+)
+
+// User types reported in DeviceUser.Type
+const (
+	UserTypePrimary        = "primary"
+	UserTypeSecondary      = "secondary"
+	UserTypeGuest          = "guest"
+	UserTypeManagedProfile = "managedProfile"
+	UserTypeSystem         = "system"
+)
+
+// Android UserInfo flags, from android.content.pm.UserInfo
+const (
+	userFlagPrimary        = 0x1
+	userFlagGuest          = 0x4
+	userFlagManagedProfile = 0x20
+	userFlagFull           = 0x400
+	userFlagSystem         = 0x800
+)
+
+// DeviceUser is a user or profile on an Android device, such as the owner
+// or a work profile
+type DeviceUser struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Running bool   `json:"running"`
+}
+
+// CreateUserOptions are the options of UserManager.CreateUser. ProfileOf
+// creates a managed (work) profile of that user instead of a secondary user.
+type CreateUserOptions struct {
+	ProfileOf *int
+	Guest     bool
+}
+
+var (
+	// userInfoLine matches a user of 'pm list users', e.g. "UserInfo{10:Work profile:1030} running"
+	userInfoLine = regexp.MustCompile(`UserInfo\{(\d+):(.*):([0-9a-fA-F]+)\}(\s+running)?`)
+
+	// createdUserLine matches the result of 'pm create-user', e.g. "Success: created user id 10"
+	createdUserLine = regexp.MustCompile(`created user id (\d+)`)
+
+	// androidUser matches the values 'pm' and 'am' take for --user
+	androidUser = regexp.MustCompile(`^(\d+|all|current)$`)
+)
+
+// ValidateAndroidUser checks a --user value: a user ID, "all" or "current"
+func ValidateAndroidUser(user string) error {
+	if !androidUser.MatchString(user) {
+		return fmt.Errorf("invalid user '%s', expected a user ID, all or current", user)
+	}
+	return nil
+}
+
+// parseAndroidUsers parses the output of 'pm list users'
+func parseAndroidUsers(output string) []DeviceUser {
+	var users []DeviceUser
+	for _, line := range strings.Split(output, "\n") {
+		matches := userInfoLine.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+
+		id, _ := strconv.Atoi(matches[1])
+		flags, _ := strconv.ParseInt(matches[3], 16, 64)
+		users = append(users, DeviceUser{
+			ID:      id,
+			Name:    matches[2],
+			Type:    androidUserType(flags),
+			Running: matches[4] != "",
+		})
+	}
+	return users
+}
+
+// androidUserType names the kind of user UserInfo flags describe
+func androidUserType(flags int64) string {
+	switch {
+	case flags&userFlagManagedProfile != 0:
+		return UserTypeManagedProfile
+	case flags&userFlagGuest != 0:
+		return UserTypeGuest
+	case flags&userFlagPrimary != 0:
+		return UserTypePrimary
+	case flags&userFlagSystem != 0 && flags&userFlagFull == 0:
+		return UserTypeSystem
+	default:
+		return UserTypeSecondary
+	}
+}
+
+// ListUsers lists the device's users and profiles
+func (d *AndroidDevice) ListUsers() ([]DeviceUser, error) {
+	output, err := d.runAdbCommand("shell", "pm", "list", "users")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseAndroidUsers(string(output)), nil
+}
+
+// CreateUser creates a secondary user, a guest, or a managed profile. A
+// managed profile is started, so apps can be installed and launched in it.
+func (d *AndroidDevice) CreateUser(name string, opts CreateUserOptions) (*DeviceUser, error) {
+	args := []string{"shell", "pm", "create-user"}
+	if opts.ProfileOf != nil {
+		args = append(args, "--profileOf", strconv.Itoa(*opts.ProfileOf), "--managed")
+	}
+	if opts.Guest {
+		args = append(args, "--guest")
+	}
+	// the name is a single argument to the device's shell
+	args = append(args, shellescape.Quote(name))
+
+	output, err := d.runAdbCommand(args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	matches := createdUserLine.FindStringSubmatch(string(output))
+	if matches == nil {
+		return nil, fmt.Errorf("failed to create user: %s", strings.TrimSpace(string(output)))
+	}
+	id, _ := strconv.Atoi(matches[1])
+
+	if opts.ProfileOf != nil {
+		if output, err := d.runAdbCommand("shell", "am", "start-user", strconv.Itoa(id)); err != nil {
+			return nil, fmt.Errorf("failed to start profile %d: %w: %s", id, err, strings.TrimSpace(string(output)))
+		}
+	}
+
+	users, err := d.ListUsers()
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		if user.ID == id {
+			return &user, nil
+		}
+	}
+	return &DeviceUser{ID: id, Name: name}, nil
+}
+
+// RemoveUser removes a user or profile and all of its apps and data
+func (d *AndroidDevice) RemoveUser(id int) error {
+	if id == 0 {
+		return fmt.Errorf("user 0 can't be removed")
+	}
+
+	output, err := d.runAdbCommand("shell", "pm", "remove-user", strconv.Itoa(id))
+	if err != nil {
+		return fmt.Errorf("failed to remove user %d: %w: %s", id, err, strings.TrimSpace(string(output)))
+	}
+	if !strings.Contains(string(output), "Success") {
+		return fmt.Errorf("failed to remove user %d: %s", id, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// ListAppsForUser lists the apps installed for a user, see ListApps
+func (d *AndroidDevice) ListAppsForUser(user string, onlyLaunchable bool) ([]InstalledAppInfo, error) {
+	if onlyLaunchable {
+		return d.listLaunchableApps(user)
+	}
+	return d.listAllPackages(user)
+}
+
+// UninstallAppForUser uninstalls an app for one user only, leaving it
+// installed for the others
+func (d *AndroidDevice) UninstallAppForUser(packageName, user string) (*InstalledAppInfo, error) {
+	output, err := d.runAdbCommand("shell", "pm", "uninstall", "--user", user, packageName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to uninstall app: %v\nOutput: %s", err, string(output))
+	}
+
+	if !strings.Contains(string(output), "Success") {
+		return nil, fmt.Errorf("uninstallation failed: %s", string(output))
+	}
+
+	return &InstalledAppInfo{PackageName: packageName}, nil
+}
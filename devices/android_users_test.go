@@ -0,0 +1,49 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePmListUsers = `Users:
+	UserInfo{0:Owner:c13} running
+	UserInfo{10:Work profile:1030} running
+	UserInfo{11:Guest:414}
+	UserInfo{12:Test: QA:410}
+`
+
+func TestParseAndroidUsers(t *testing.T) {
+	assert.Equal(t, []DeviceUser{
+		{ID: 0, Name: "Owner", Type: UserTypePrimary, Running: true},
+		{ID: 10, Name: "Work profile", Type: UserTypeManagedProfile, Running: true},
+		{ID: 11, Name: "Guest", Type: UserTypeGuest},
+		{ID: 12, Name: "Test: QA", Type: UserTypeSecondary},
+	}, parseAndroidUsers(samplePmListUsers))
+}
+
+func TestValidateAndroidUser(t *testing.T) {
+	for _, user := range []string{"0", "10", "all", "current"} {
+		assert.NoError(t, ValidateAndroidUser(user), user)
+	}
+	for _, user := range []string{"", "-1", "10;reboot", "owner"} {
+		assert.Error(t, ValidateAndroidUser(user), user)
+	}
+}
+
+func TestCreateManagedProfile(t *testing.T) {
+	adb := &fakeAdb{output: "Success: created user id 10\n" + samplePmListUsers}
+	previous := utils.SetCommandRunner(adb)
+	defer utils.SetCommandRunner(previous)
+
+	d := &AndroidDevice{id: "emulator-5554", transportID: "emulator-5554"}
+	owner := 0
+	user, err := d.CreateUser("Work profile", CreateUserOptions{ProfileOf: &owner})
+	require.NoError(t, err)
+	assert.Equal(t, &DeviceUser{ID: 10, Name: "Work profile", Type: UserTypeManagedProfile, Running: true}, user)
+
+	assert.Equal(t, []string{"-s", "emulator-5554", "shell", "pm", "create-user", "--profileOf", "0", "--managed", "'Work profile'"}, adb.args[0])
+	assert.Equal(t, []string{"-s", "emulator-5554", "shell", "am", "start-user", "10"}, adb.args[1])
+}
@@ -174,7 +174,7 @@ func agentRequestWithTimeout(port int, method string, params map[string]any, tim
 
 	start := time.Now()
 	defer func() {
-		utils.Verbose("agentRequest method=%s payloadBytes=%d elapsed=%s", method, len(payload), time.Since(start))
+		utils.Debug(utils.SubsystemADB, "agentRequest method=%s payloadBytes=%d elapsed=%s", method, len(payload), time.Since(start))
 	}()
 
 	client := &http.Client{Timeout: timeout}
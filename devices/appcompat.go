@@ -0,0 +1,120 @@
+package devices
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// AppCompatibility is whether an app file can be installed on a device, with
+// the reasons it can't
+type AppCompatibility struct {
+	DeviceID   string   `json:"deviceId"`
+	Compatible bool     `json:"compatible"`
+	Problems   []string `json:"problems,omitempty"`
+}
+
+// CheckAppCompatibility checks an app file against a device's platform, OS
+// version and architectures, and for real iOS devices against the app's
+// provisioning profile, catching what would otherwise fail a slow install
+func CheckAppCompatibility(device ControllableDevice, info *utils.AppPackageInfo) *AppCompatibility {
+	props := &DeviceProperties{OSVersion: device.Version()}
+	if provider, ok := device.(PropertiesProvider); ok {
+		if deviceProps, err := provider.Properties(); err != nil {
+			utils.Verbose("failed to read properties of device %s: %v", device.ID(), err)
+		} else {
+			props = deviceProps
+		}
+	}
+
+	problems := appCompatibilityProblems(info, device.Platform(), device.DeviceType(), device.ID(), props, time.Now())
+	return &AppCompatibility{
+		DeviceID:   device.ID(),
+		Compatible: len(problems) == 0,
+		Problems:   problems,
+	}
+}
+
+func appCompatibilityProblems(info *utils.AppPackageInfo, platform, deviceType, deviceID string, props *DeviceProperties, now time.Time) []string {
+	if info.Platform != platform {
+		return []string{fmt.Sprintf("app is for %s, device %s is %s", info.Platform, deviceID, platform)}
+	}
+
+	if platform == "android" {
+		return androidAppCompatibilityProblems(info, deviceID, props)
+	}
+	return iosAppCompatibilityProblems(info, deviceType, deviceID, props, now)
+}
+
+func androidAppCompatibilityProblems(info *utils.AppPackageInfo, deviceID string, props *DeviceProperties) []string {
+	var problems []string
+
+	if minSDK, err := strconv.Atoi(info.MinOSVersion); err == nil && props.APILevel > 0 && props.APILevel < minSDK {
+		problems = append(problems, fmt.Sprintf("app requires API level %d, device %s has API level %d", minSDK, deviceID, props.APILevel))
+	}
+
+	// apps without native libraries run on any ABI
+	deviceABIs := props.ABIs
+	if len(deviceABIs) == 0 && props.ABI != "" {
+		deviceABIs = []string{props.ABI}
+	}
+	if len(info.ABIs) > 0 && len(deviceABIs) > 0 && !slices.ContainsFunc(info.ABIs, func(abi string) bool {
+		return slices.Contains(deviceABIs, abi)
+	}) {
+		problems = append(problems, fmt.Sprintf("app has native libraries for %s only, device %s supports %s", strings.Join(info.ABIs, ", "), deviceID, strings.Join(deviceABIs, ", ")))
+	}
+
+	return problems
+}
+
+func iosAppCompatibilityProblems(info *utils.AppPackageInfo, deviceType, deviceID string, props *DeviceProperties, now time.Time) []string {
+	var problems []string
+
+	if info.MinOSVersion != "" && props.OSVersion != "" && compareVersions(props.OSVersion, info.MinOSVersion) < 0 {
+		problems = append(problems, fmt.Sprintf("app requires iOS %s, device %s runs iOS %s", info.MinOSVersion, deviceID, props.OSVersion))
+	}
+
+	simulator := deviceType == "simulator"
+	wantPlatform := "iPhoneOS"
+	if simulator {
+		wantPlatform = "iPhoneSimulator"
+	}
+	if len(info.SupportedPlatforms) > 0 && !slices.Contains(info.SupportedPlatforms, wantPlatform) {
+		problems = append(problems, fmt.Sprintf("app is built for %s, device %s needs a build for %s", strings.Join(info.SupportedPlatforms, ", "), deviceID, wantPlatform))
+	}
+
+	// simulators run apps on the host CPU, devices need an arm64 build
+	if len(info.ABIs) > 0 {
+		wantABIs := []string{"arm64", "arm64e"}
+		if simulator {
+			wantABIs = []string{props.ABI}
+		}
+		if (!simulator || props.ABI != "") && !slices.ContainsFunc(info.ABIs, func(abi string) bool {
+			return slices.Contains(wantABIs, abi)
+		}) {
+			problems = append(problems, fmt.Sprintf("app executable is built for %s, device %s needs %s", strings.Join(info.ABIs, ", "), deviceID, strings.Join(wantABIs, " or ")))
+		}
+	}
+
+	if simulator {
+		return problems
+	}
+
+	profile := info.Signing.Profile
+	switch {
+	case !info.Signing.Signed:
+		problems = append(problems, "app is not signed")
+	case profile == nil:
+		problems = append(problems, "app has no embedded provisioning profile")
+	case profile.ExpirationDate.Before(now):
+		problems = append(problems, fmt.Sprintf("provisioning profile %q expired on %s", profile.Name, profile.ExpirationDate.Format(time.DateOnly)))
+	case !profile.ProvisionsAllDevices && len(profile.ProvisionedDevices) > 0 && !slices.Contains(profile.ProvisionedDevices, deviceID):
+		problems = append(problems, fmt.Sprintf("device %s is not in provisioning profile %q", deviceID, profile.Name))
+	}
+
+	return problems
+}
@@ -0,0 +1,62 @@
+package devices
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAndroidAppCompatibility(t *testing.T) {
+	app := &utils.AppPackageInfo{Platform: "android", MinOSVersion: "26", ABIs: []string{"x86_64"}}
+	props := &DeviceProperties{APILevel: 24, ABIs: []string{"arm64-v8a", "armeabi-v7a"}}
+
+	problems := appCompatibilityProblems(app, "android", "real", "pixel", props, time.Now())
+	assert.Equal(t, []string{
+		"app requires API level 26, device pixel has API level 24",
+		"app has native libraries for x86_64 only, device pixel supports arm64-v8a, armeabi-v7a",
+	}, problems)
+
+	// an app without native libraries runs on any ABI
+	app.ABIs = nil
+	props.APILevel = 34
+	assert.Empty(t, appCompatibilityProblems(app, "android", "real", "pixel", props, time.Now()))
+}
+
+func TestAppCompatibilityPlatformMismatch(t *testing.T) {
+	app := &utils.AppPackageInfo{Platform: "ios"}
+	problems := appCompatibilityProblems(app, "android", "emulator", "emulator-5554", &DeviceProperties{}, time.Now())
+	assert.Equal(t, []string{"app is for ios, device emulator-5554 is android"}, problems)
+}
+
+func TestIOSAppCompatibility(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	app := &utils.AppPackageInfo{
+		Platform:           "ios",
+		MinOSVersion:       "17.0",
+		SupportedPlatforms: []string{"iPhoneOS"},
+		ABIs:               []string{"arm64"},
+		Signing: utils.AppSigningInfo{
+			Signed: true,
+			Profile: &utils.ProvisioningProfileInfo{
+				Name:               "Dev",
+				ExpirationDate:     now.AddDate(0, 1, 0),
+				ProvisionedDevices: []string{"00008110-000A"},
+			},
+		},
+	}
+
+	assert.Empty(t, iosAppCompatibilityProblems(app, "real", "00008110-000A", &DeviceProperties{OSVersion: "17.5"}, now))
+
+	assert.Equal(t, []string{
+		"app requires iOS 17.0, device 00008110-000B runs iOS 16.4",
+		`device 00008110-000B is not in provisioning profile "Dev"`,
+	}, iosAppCompatibilityProblems(app, "real", "00008110-000B", &DeviceProperties{OSVersion: "16.4"}, now))
+
+	// a device build can't go on a simulator, whatever its signing
+	assert.Equal(t, []string{
+		"app is built for iPhoneOS, device sim needs a build for iPhoneSimulator",
+		"app executable is built for arm64, device sim needs x86_64",
+	}, iosAppCompatibilityProblems(app, "simulator", "sim", &DeviceProperties{OSVersion: "17.5", ABI: "x86_64"}, now))
+}
@@ -0,0 +1,60 @@
+package devices
+
+import (
+	"strings"
+	"sync"
+)
+
+// bootOutputLines is how many trailing lines of emulator output are kept for
+// boot failure diagnostics
+const bootOutputLines = 20
+
+// outputTail is an io.Writer that keeps only the last few lines written to it
+type outputTail struct {
+	mu       sync.Mutex
+	maxLines int
+	lines    []string
+	partial  string
+}
+
+func newOutputTail(maxLines int) *outputTail {
+	return &outputTail{maxLines: maxLines}
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	parts := strings.Split(t.partial+string(p), "\n")
+	t.partial = parts[len(parts)-1]
+
+	for _, line := range parts[:len(parts)-1] {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		t.lines = append(t.lines, line)
+	}
+
+	if len(t.lines) > t.maxLines {
+		t.lines = t.lines[len(t.lines)-t.maxLines:]
+	}
+
+	return len(p), nil
+}
+
+// String returns the kept lines, including an unterminated last line
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lines := t.lines
+	if t.partial != "" {
+		lines = append(append([]string{}, lines...), t.partial)
+		if len(lines) > t.maxLines {
+			lines = lines[len(lines)-t.maxLines:]
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
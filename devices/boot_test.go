@@ -0,0 +1,28 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputTailKeepsLastLines(t *testing.T) {
+	tail := newOutputTail(2)
+
+	_, _ = tail.Write([]byte("INFO | starting\nINFO | loading"))
+	_, _ = tail.Write([]byte(" snapshot\r\n\nERROR | x86 emulation requires hardware acceleration\n"))
+
+	assert.Equal(t, "INFO | loading snapshot\nERROR | x86 emulation requires hardware acceleration", tail.String())
+}
+
+func TestOutputTailIncludesPartialLine(t *testing.T) {
+	tail := newOutputTail(2)
+
+	_, _ = tail.Write([]byte("one\ntwo\nthree"))
+
+	assert.Equal(t, "two\nthree", tail.String())
+}
+
+func TestBootConfigDefaultsTimeout(t *testing.T) {
+	assert.Equal(t, DefaultBootTimeout, BootConfig{}.timeout())
+}
@@ -0,0 +1,106 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/danielpaulus/go-ios/ios/crashreport"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// copyDir recursively copies the files in src into dst
+func copyDir(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, relPath)
+
+		if entry.IsDir() {
+			return os.MkdirAll(target, 0750)
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		return utils.CopyFile(path, target)
+	})
+}
+
+// CollectBugReport runs 'adb bugreport', which bundles logcat, dumpsys,
+// tombstones and ANR traces into a zip
+func (d *AndroidDevice) CollectBugReport(dir string) error {
+	utils.Verbose("Collecting adb bugreport from %s, this can take a few minutes", d.ID())
+
+	output, err := d.runAdbCommandTimeout(longCommandTimeout, "bugreport", filepath.Join(dir, "bugreport.zip"))
+	if err != nil {
+		return fmt.Errorf("adb bugreport failed: %v\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
+// CollectBugReport downloads all crash reports from the device. Triggering a
+// sysdiagnose isn't supported over lockdown, so it is not included.
+func (d *IOSDevice) CollectBugReport(dir string) error {
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return fmt.Errorf("failed to get device: %w", err)
+	}
+
+	crashesDir := filepath.Join(dir, "crashes")
+	if err := os.MkdirAll(crashesDir, 0750); err != nil {
+		return err
+	}
+
+	utils.Verbose("Downloading crash reports from %s", d.Udid)
+	if err := crashreport.DownloadReports(device, "*", crashesDir); err != nil {
+		return fmt.Errorf("failed to download crash reports: %w", err)
+	}
+
+	return nil
+}
+
+// CollectBugReport copies the simulator's CoreSimulator log directory and the
+// host's diagnostic reports, where simulator app crashes are written
+func (s *SimulatorDevice) CollectBugReport(dir string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	logsDir := filepath.Join(homeDir, "Library", "Logs", "CoreSimulator", s.UDID)
+	if _, err := os.Stat(logsDir); err == nil {
+		utils.Verbose("Copying simulator logs from %s", logsDir)
+		if err := copyDir(logsDir, filepath.Join(dir, "simulator-logs")); err != nil {
+			return fmt.Errorf("failed to copy simulator logs: %w", err)
+		}
+	} else {
+		utils.Verbose("No simulator logs found at %s", logsDir)
+	}
+
+	crashes, err := s.ListCrashReports()
+	if err != nil {
+		return err
+	}
+
+	crashesDir := filepath.Join(dir, "crashes")
+	if err := os.MkdirAll(crashesDir, 0750); err != nil {
+		return err
+	}
+
+	for _, crash := range crashes {
+		if err := utils.CopyFile(filepath.Join(diagnosticReportsDir, crash.ID), filepath.Join(crashesDir, crash.ID)); err != nil {
+			utils.Verbose("Failed to copy crash report %s: %v", crash.ID, err)
+		}
+	}
+
+	return nil
+}
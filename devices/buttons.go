@@ -0,0 +1,122 @@
+package devices
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mobile-next/mobilecli/devices/wda"
+)
+
+// buttonMapping is what a button name presses on each platform. A button
+// with no mapping for a platform is unsupported there.
+type buttonMapping struct {
+	androidKeycode string // a KEYCODE_* sent with 'input keyevent'
+	iosButton      string // a hardware button of the iOS agent
+	iosKey         string // a named key pressed through the iOS agent's keyboard
+	iosText        string // text typed through the iOS agent's keyboard
+}
+
+// buttons maps the button names accepted by PressButton
+var buttons = map[string]buttonMapping{
+	"HOME":        {androidKeycode: "KEYCODE_HOME", iosButton: "home"},
+	"BACK":        {androidKeycode: "KEYCODE_BACK"},
+	"POWER":       {androidKeycode: "KEYCODE_POWER"},
+	"LOCK":        {iosButton: "lock"},
+	"VOLUME_UP":   {androidKeycode: "KEYCODE_VOLUME_UP", iosButton: "volumeUp"},
+	"VOLUME_DOWN": {androidKeycode: "KEYCODE_VOLUME_DOWN", iosButton: "volumeDown"},
+	"MUTE":        {androidKeycode: "KEYCODE_VOLUME_MUTE"},
+	"ENTER":       {androidKeycode: "KEYCODE_ENTER", iosText: "\n"},
+	"BACKSPACE":   {androidKeycode: "KEYCODE_DEL", iosKey: "backspace"},
+	"TAB":         {androidKeycode: "KEYCODE_TAB", iosKey: "tab"},
+	"ESCAPE":      {androidKeycode: "KEYCODE_ESCAPE", iosKey: "escape"},
+	"PAGE_UP":     {androidKeycode: "KEYCODE_PAGE_UP", iosKey: "pageup"},
+	"PAGE_DOWN":   {androidKeycode: "KEYCODE_PAGE_DOWN", iosKey: "pagedown"},
+	"MENU":        {androidKeycode: "KEYCODE_MENU"},
+	"SEARCH":      {androidKeycode: "KEYCODE_SEARCH"},
+	"CAMERA":      {androidKeycode: "KEYCODE_CAMERA"},
+	"APP_SWITCH":  {androidKeycode: "KEYCODE_APP_SWITCH"},
+	"DPAD_CENTER": {androidKeycode: "KEYCODE_DPAD_CENTER"},
+	"DPAD_UP":     {androidKeycode: "KEYCODE_DPAD_UP"},
+	"DPAD_DOWN":   {androidKeycode: "KEYCODE_DPAD_DOWN"},
+	"DPAD_LEFT":   {androidKeycode: "KEYCODE_DPAD_LEFT"},
+	"DPAD_RIGHT":  {androidKeycode: "KEYCODE_DPAD_RIGHT"},
+}
+
+// UnsupportedOnPlatformCode identifies an UnsupportedButtonError in error
+// responses
+const UnsupportedOnPlatformCode = "UNSUPPORTED_ON_PLATFORM"
+
+// UnsupportedButtonError is returned when a button, or a raw keycode, can't
+// be pressed on a device's platform
+type UnsupportedButtonError struct {
+	Code      string   `json:"code"`
+	Button    string   `json:"button"`
+	Platform  string   `json:"platform"`
+	Supported []string `json:"supported"`
+}
+
+func (e *UnsupportedButtonError) Error() string {
+	return fmt.Sprintf("button %s is unsupported on %s", e.Button, e.Platform)
+}
+
+// NewUnsupportedButtonError returns the error for a button that can't be
+// pressed on a platform
+func NewUnsupportedButtonError(button, platform string) *UnsupportedButtonError {
+	return &UnsupportedButtonError{
+		Code:      UnsupportedOnPlatformCode,
+		Button:    button,
+		Platform:  platform,
+		Supported: ButtonsForPlatform(platform),
+	}
+}
+
+// ButtonsForPlatform returns the button names that can be pressed on a
+// platform, sorted
+func ButtonsForPlatform(platform string) []string {
+	var names []string
+	for name, mapping := range buttons {
+		if mapping.supports(platform) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m buttonMapping) supports(platform string) bool {
+	switch platform {
+	case "android":
+		return m.androidKeycode != ""
+	case "ios":
+		return m.iosButton != "" || m.iosKey != "" || m.iosText != ""
+	default:
+		return false
+	}
+}
+
+// androidButtonKeycode returns the Android keycode a button presses
+func androidButtonKeycode(button string) (string, error) {
+	mapping, ok := buttons[button]
+	if !ok || !mapping.supports("android") {
+		return "", NewUnsupportedButtonError(button, "android")
+	}
+	return mapping.androidKeycode, nil
+}
+
+// pressIOSButton presses a button through the iOS agent, as a hardware button
+// or, for keys with no hardware equivalent, on the keyboard
+func pressIOSButton(client *wda.WdaClient, button string) error {
+	mapping, ok := buttons[button]
+	if !ok || !mapping.supports("ios") {
+		return NewUnsupportedButtonError(button, "ios")
+	}
+
+	switch {
+	case mapping.iosText != "":
+		return client.SendKeys(mapping.iosText)
+	case mapping.iosKey != "":
+		return client.PressKeys([]wda.KeyCombo{{Key: mapping.iosKey}})
+	default:
+		return client.PressButton(mapping.iosButton)
+	}
+}
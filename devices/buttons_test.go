@@ -0,0 +1,48 @@
+package devices
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestAndroidButtonKeycode(t *testing.T) {
+	for button, expected := range map[string]string{
+		"HOME":      "KEYCODE_HOME",
+		"MUTE":      "KEYCODE_VOLUME_MUTE",
+		"BACKSPACE": "KEYCODE_DEL",
+		"PAGE_DOWN": "KEYCODE_PAGE_DOWN",
+	} {
+		keycode, err := androidButtonKeycode(button)
+		if err != nil || keycode != expected {
+			t.Errorf("%s: expected %s, got %q (%v)", button, expected, keycode, err)
+		}
+	}
+
+	_, err := androidButtonKeycode("LOCK")
+	var unsupported *UnsupportedButtonError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected LOCK to be unsupported on Android, got %v", err)
+	}
+	if unsupported.Code != UnsupportedOnPlatformCode || unsupported.Platform != "android" || !slices.Contains(unsupported.Supported, "HOME") {
+		t.Errorf("unexpected error: %+v", unsupported)
+	}
+}
+
+func TestButtonsForPlatform(t *testing.T) {
+	ios := ButtonsForPlatform("ios")
+	for _, button := range []string{"HOME", "LOCK", "ENTER", "TAB", "ESCAPE"} {
+		if !slices.Contains(ios, button) {
+			t.Errorf("expected %s to be supported on iOS", button)
+		}
+	}
+	for _, button := range []string{"BACK", "MENU", "CAMERA", "DPAD_UP"} {
+		if slices.Contains(ios, button) {
+			t.Errorf("expected %s to be unsupported on iOS", button)
+		}
+	}
+
+	if !slices.IsSorted(ios) {
+		t.Errorf("expected sorted buttons, got %v", ios)
+	}
+}
@@ -1,10 +1,12 @@
 package devices
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
 	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/mobile-next/mobilecli/devices/wda"
@@ -59,13 +61,19 @@ const (
 	DefaultFramerate = 30
 )
 
-func buildMjpegURL(port, fps int, scale float64) string {
+// buildMjpegURL returns the agent's MJPEG stream URL. Parameters at their zero
+// value (or scale at 1.0) are left out so the agent applies its own defaults.
+func buildMjpegURL(port, fps, quality int, scale float64) string {
 	url := fmt.Sprintf("http://localhost:%d/mjpeg", port)
 	sep := "?"
 	if fps > 0 {
 		url += fmt.Sprintf("%sfps=%d", sep, fps)
 		sep = "&"
 	}
+	if quality > 0 {
+		url += fmt.Sprintf("%squality=%d", sep, quality)
+		sep = "&"
+	}
 	scalePercent := int(scale * 100)
 	if scalePercent > 0 && scalePercent != 100 {
 		url += fmt.Sprintf("%sscale=%d", sep, scalePercent)
@@ -75,6 +83,7 @@ func buildMjpegURL(port, fps int, scale float64) string {
 
 // ScreenCaptureConfig contains configuration for screen capture operations
 type ScreenCaptureConfig struct {
+	Context    context.Context // optional, cancelling it stops the capture immediately
 	Format     string
 	Quality    int
 	Scale      float64
@@ -82,6 +91,15 @@ type ScreenCaptureConfig struct {
 	Bitrate    int                  // bitrate in bits per second, only applies to AVC (0 for default)
 	OnProgress func(message string) // optional progress callback
 	OnData     func([]byte) bool    // data callback - return false to stop
+	Thermal    *ThermalGuard        // optional, only applies through StartGuardedScreenCapture
+}
+
+// context returns the capture's context, never nil
+func (c ScreenCaptureConfig) context() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
 }
 
 // StartAgentConfig contains configuration for agent startup operations
@@ -90,6 +108,107 @@ type StartAgentConfig struct {
 	Hook       *ShutdownHook        // optional shutdown hook for cleanup tracking
 }
 
+// Boot progress stages reported through BootConfig.OnProgress
+const (
+	BootStageBooting     = "booting"
+	BootStageAdbDetected = "adb-detected"
+	BootStageCompleted   = "boot-completed"
+)
+
+// DefaultBootTimeout is used when BootConfig.Timeout is zero
+const DefaultBootTimeout = 120 * time.Second
+
+// BootConfig contains configuration for booting simulators and emulators
+type BootConfig struct {
+	Timeout    time.Duration        // 0 for DefaultBootTimeout
+	OnProgress func(message string) // optional progress callback, receives BootStage* values
+	Context    context.Context      // optional, cancelling it stops waiting for the boot
+}
+
+func (c BootConfig) context() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
+}
+
+func (c BootConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return DefaultBootTimeout
+	}
+	return c.Timeout
+}
+
+func (c BootConfig) progress(stage string) {
+	if c.OnProgress != nil {
+		c.OnProgress(stage)
+	}
+}
+
+// Install progress phases reported through InstallConfig.OnProgress
+const (
+	InstallPhaseUploading  = "uploading"
+	InstallPhaseInstalling = "installing"
+	InstallPhaseCompleted  = "completed"
+)
+
+// InstallProgress reports how far an app install has got. Bytes are known
+// while uploading, Percent only where the platform reports it.
+type InstallProgress struct {
+	Phase      string `json:"phase"`
+	BytesSent  int64  `json:"bytesSent,omitempty"`
+	TotalBytes int64  `json:"totalBytes,omitempty"`
+	Percent    int    `json:"percent,omitempty"`
+}
+
+// InstallConfig contains configuration for installing an app
+type InstallConfig struct {
+	OnProgress func(progress InstallProgress) // optional progress callback
+	Context    context.Context                // optional, stops the install when done
+
+	// Android only
+	GrantPermissions bool           // grant all runtime permissions (adb install -g)
+	AllowTest        bool           // allow test-only apks (adb install -t)
+	Instant          bool           // install as an instant app (adb install --instant)
+	AllowDowngrade   bool           // replace a newer installed version (adb install -d)
+	User             string         // install for this user ID, or "all" or "current" (adb install --user)
+	Keystore         KeystoreConfig // signs the apks built from an .aab
+}
+
+// KeystoreConfig is the keystore bundletool signs apks built from an .aab
+// with. When Path is empty bundletool falls back to the debug keystore.
+type KeystoreConfig struct {
+	Path        string
+	Password    string
+	KeyAlias    string
+	KeyPassword string
+}
+
+// HasAndroidOptions reports whether any Android-only install option is set
+func (c InstallConfig) HasAndroidOptions() bool {
+	return c.GrantPermissions || c.AllowTest || c.Instant || c.AllowDowngrade || c.User != "" || c.Keystore != KeystoreConfig{}
+}
+
+func (c InstallConfig) progress(progress InstallProgress) {
+	if c.OnProgress != nil {
+		c.OnProgress(progress)
+	}
+}
+
+// cancelled returns the error of the install's context once it's done
+func (c InstallConfig) cancelled() error {
+	if c.Context == nil {
+		return nil
+	}
+	return c.Context.Err()
+}
+
+// ProgressInstaller is implemented by devices that can report progress while
+// installing an app, which matters for multi-gigabyte apps
+type ProgressInstaller interface {
+	InstallAppWithProgress(path string, config InstallConfig) error
+}
+
 // ScreenElementRect represents the rectangle coordinates and dimensions
 // Re-export types for backward compatibility
 type ScreenElementRect = types.ScreenElementRect
@@ -105,10 +224,26 @@ type FileEntry struct {
 }
 
 // LaunchOptions carries optional parameters for launching an app.
-// Activity is Android-only; passing it to an iOS device is an error.
+// Activity is Android-only; passing it to an iOS device is an error. Wait is
+// Android-only too, iOS launches always wait for the app.
 type LaunchOptions struct {
 	Locales  []string
 	Activity string
+	Wait     bool   // wait for the activity to be drawn (am start -W)
+	User     string // Android user to launch for (am start --user)
+}
+
+// LaunchResult describes the activity a launch started
+type LaunchResult struct {
+	Component string `json:"component"`
+	TotalTime int    `json:"totalTime,omitempty"` // ms until the activity was drawn, with Wait
+	WaitTime  int    `json:"waitTime,omitempty"`  // ms until am start returned, with Wait
+}
+
+// ComponentLauncher is implemented by devices that can report the component
+// a launch resolved to
+type ComponentLauncher interface {
+	LaunchAppComponent(bundleID string, opts LaunchOptions) (*LaunchResult, error)
 }
 
 type ControllableDevice interface {
@@ -121,11 +256,11 @@ type ControllableDevice interface {
 
 	TakeScreenshot() ([]byte, error)
 	Reboot() error
-	Boot() error     // boot simulator/emulator
-	Shutdown() error // shutdown simulator/emulator
+	Boot(config BootConfig) error // boot simulator/emulator
+	Shutdown() error              // shutdown simulator/emulator
 	Tap(x, y int) error
 	LongPress(x, y, duration int) error
-	Swipe(x1, y1, x2, y2 int) error
+	Swipe(x1, y1, x2, y2, duration int) error // duration in milliseconds, 0 for the platform default
 	Gesture(actions []wda.TapAction) error
 	StartAgent(config StartAgentConfig) error
 	SendKeys(text string) error
@@ -155,12 +290,296 @@ type ControllableDevice interface {
 	GetAppContainerPath(bundleID string) (string, error)
 }
 
+// AppContainers are the paths of an app's containers on a device, which the
+// fs commands accept as remote paths when DataAccessible is set
+type AppContainers struct {
+	BundleID string `json:"bundleId"`
+
+	// Bundle is the installed app: the .app bundle on iOS, the directory of
+	// the apks on Android
+	Bundle string `json:"bundle,omitempty"`
+
+	// Data is the app's data container, /data/user/<user>/<package> on Android
+	Data string `json:"data,omitempty"`
+
+	// Groups maps the app's app group identifiers to their shared containers
+	Groups map[string]string `json:"groups,omitempty"`
+
+	// DataAccessible reports whether the data container can be read and
+	// written, which on Android needs a debuggable app
+	DataAccessible bool `json:"dataAccessible"`
+}
+
+// AppContainerProvider is implemented by devices that can locate all of an
+// app's containers
+type AppContainerProvider interface {
+	AppContainers(bundleID string) (*AppContainers, error)
+}
+
+// Transports a real device can be connected to the host with
+const (
+	TransportUSB     = "usb"
+	TransportNetwork = "network"
+)
+
+// TransportAware is implemented by devices that know how they are connected to
+// the host. Simulators and emulators don't implement it.
+type TransportAware interface {
+	Transport() string
+}
+
+// USBLocator is implemented by devices that know the USB port they are
+// plugged into, so a device can be found on a hub by its port
+type USBLocator interface {
+	USBPath() string
+}
+
+// Process states reported by RunningAppsLister, ordered from most to least active
+const (
+	ProcessStateForeground = "foreground"
+	ProcessStateBackground = "background"
+	ProcessStateCached     = "cached"
+)
+
+// RunningProcess is a single process belonging to a running app
+type RunningProcess struct {
+	PID   int    `json:"pid"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+// RunningAppInfo describes an app with at least one running process. State is
+// the most active state of any of its processes.
+type RunningAppInfo struct {
+	PackageName string           `json:"packageName"`
+	State       string           `json:"state"`
+	Processes   []RunningProcess `json:"processes"`
+}
+
+// RunningAppsLister is implemented by devices that can report running app processes.
+type RunningAppsLister interface {
+	ListRunningApps() ([]RunningAppInfo, error)
+}
+
+// DeviceProperties is a curated set of hardware, build and locale properties.
+// Fields that a platform doesn't expose are left empty.
+type DeviceProperties struct {
+	Manufacturer     string   `json:"manufacturer,omitempty"`
+	Model            string   `json:"model,omitempty"`
+	ABI              string   `json:"abi,omitempty"`
+	ABIs             []string `json:"abis,omitempty"`
+	APILevel         int      `json:"apiLevel,omitempty"`
+	OSVersion        string   `json:"osVersion,omitempty"`
+	BuildFingerprint string   `json:"buildFingerprint,omitempty"`
+	Serial           string   `json:"serial,omitempty"`
+	ScreenDensity    int      `json:"screenDensity,omitempty"`
+	Locale           string   `json:"locale,omitempty"`
+	Timezone         string   `json:"timezone,omitempty"`
+}
+
+// PropertiesProvider is implemented by devices that can report DeviceProperties.
+type PropertiesProvider interface {
+	Properties() (*DeviceProperties, error)
+}
+
+// Notification is a notification currently posted on the device
+type Notification struct {
+	Key     string   `json:"key"`
+	Package string   `json:"package"`
+	Title   string   `json:"title,omitempty"`
+	Text    string   `json:"text,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+}
+
+// NotificationManager is implemented by devices that can inspect and clear
+// posted notifications and open the notification shade.
+type NotificationManager interface {
+	ListNotifications() ([]Notification, error)
+	ClearNotifications() error
+	OpenNotificationShade() error
+}
+
+// Screen states reported by ScreenLocker
+const (
+	ScreenStateOn     = "on"
+	ScreenStateOff    = "off"
+	ScreenStateLocked = "locked"
+)
+
+// ScreenLocker is implemented by devices that can report whether the screen is
+// on, off or locked, and lock or unlock it.
+type ScreenLocker interface {
+	ScreenState() (string, error)
+	Lock() error
+	Unlock() error
+}
+
+// ScreenFingerprinter is implemented by devices that can tell what's on screen
+// more cheaply than a screenshot, e.g. by the focused window. Equal
+// fingerprints mean the same screen is most likely still up.
+type ScreenFingerprinter interface {
+	ScreenFingerprint() (string, error)
+}
+
+// AlertHandler is implemented by devices that can read and answer the alert
+// on screen, such as a permission dialog
+type AlertHandler interface {
+	GetAlert() (*wda.Alert, error)
+	AcceptAlert(button string) error
+	DismissAlert(button string) error
+}
+
+// ControlCenterController is implemented by devices with a panel of quick
+// settings: Control Center on iOS, quick settings on Android
+type ControlCenterController interface {
+	OpenControlCenter() error
+	CloseControlCenter() error
+}
+
+// SettingsOpener is implemented by devices that can open their Settings app
+type SettingsOpener interface {
+	OpenSettings() error
+}
+
+// KeycodePresser is implemented by devices that can press a raw platform
+// keycode, such as Android's KEYCODE_MEDIA_PLAY_PAUSE (85)
+type KeycodePresser interface {
+	PressKeycode(keycode int) error
+}
+
+// ScreenWaker is implemented by devices whose screen can be turned on and
+// off explicitly, unlike the POWER button, which toggles it
+type ScreenWaker interface {
+	Wake() error
+	ScreenOff() error
+}
+
+// StayAwakeController is implemented by devices that can keep the screen on
+// while plugged in. Turning it off restores the setting in place before it was
+// turned on.
+type StayAwakeController interface {
+	SetStayAwake(enabled bool) error
+}
+
+// WindowController is implemented by emulators and simulators whose window
+// on the host can be shown and hidden while they run, to look at a device
+// booted without one
+type WindowController interface {
+	SetWindowVisible(visible bool) error
+}
+
+// PointerLocationController is implemented by devices that can overlay the
+// touches they receive on the screen, to debug where taps land
+type PointerLocationController interface {
+	PointerLocation() (bool, error)
+	SetPointerLocation(enabled bool) error
+}
+
+// UserManager is implemented by devices with multiple users and work
+// profiles, whose apps can be listed and uninstalled per user
+type UserManager interface {
+	ListUsers() ([]DeviceUser, error)
+	CreateUser(name string, opts CreateUserOptions) (*DeviceUser, error)
+	RemoveUser(id int) error
+	ListAppsForUser(user string, onlyLaunchable bool) ([]InstalledAppInfo, error)
+	UninstallAppForUser(packageName, user string) (*InstalledAppInfo, error)
+}
+
+// MediaImporter is implemented by devices that can be seeded with photos,
+// videos and contacts, e.g. for apps with photo-picker flows
+type MediaImporter interface {
+	AddMedia(paths []string) error
+	AddContacts(vcfPath string) error
+}
+
+// Clock scopes reported by ClockController.SetTime
+const (
+	ClockScopeSystem    = "system"    // the device clock, the time apps read
+	ClockScopeStatusBar = "statusBar" // only the time shown in the status bar
+)
+
+// ClockController is implemented by devices whose time can be changed, so
+// date-dependent flows such as trials and subscriptions can be tested.
+// SetTime reports whether it changed the device clock or only the status bar.
+// SyncTime goes back to the host's time.
+type ClockController interface {
+	SetTime(t time.Time) (string, error)
+	SyncTime() error
+}
+
+// SensorController is implemented by devices whose sensors can be simulated,
+// e.g. to test shake-to-report or fitness features. SetSensor holds a sensor
+// at the given values until it is set again.
+type SensorController interface {
+	SetSensor(name string, values []float64) error
+	Shake() error
+}
+
+// TelephonySimulator is implemented by devices that can simulate incoming
+// calls and text messages, to test OTP and call-interruption flows
+type TelephonySimulator interface {
+	IncomingCall(number string) error
+	EndCall(number string) error
+	ReceiveSMS(number, text string) error
+}
+
+// StorageController is implemented by devices whose storage can be inspected
+// and filled, to test low-storage behavior. FillStorage writes a filler file
+// leaving leaveFree bytes free, FreeStorage removes it.
+type StorageController interface {
+	StorageInfo() ([]StorageVolume, error)
+	FillStorage(leaveFree int64) (*StorageFillResult, error)
+	FreeStorage() error
+}
+
+// ThermalReader is implemented by devices that report their temperature,
+// which ThermalGuard watches during screen captures
+type ThermalReader interface {
+	ReadThermal() (*ThermalReading, error)
+}
+
+// RootController is implemented by devices that can report and enable root
+// access, which some diagnostics such as reading app data need. EnableRoot
+// fails on production builds.
+type RootController interface {
+	RootStatus() (*RootStatus, error)
+	EnableRoot() (*RootStatus, error)
+}
+
+// AppExecutableResolver is implemented by devices whose crash reports are named
+// after the app's executable rather than its bundle identifier.
+type AppExecutableResolver interface {
+	GetAppExecutable(bundleID string) (string, error)
+}
+
+// BugReportCollector is implemented by devices that can gather platform
+// diagnostics (bug reports, system and crash logs) into a directory.
+type BugReportCollector interface {
+	CollectBugReport(dir string) error
+}
+
+// LogStreamer is implemented by devices that can stream their system log.
+// onLine is called with each new line until it returns false or ctx is done.
+type LogStreamer interface {
+	StreamLogs(ctx context.Context, onLine func(line string) bool) error
+}
+
 // AnimationConfigurable is implemented by devices that can toggle system
 // animations. Devices that don't implement it are treated as a no-op by callers.
 type AnimationConfigurable interface {
 	SetAnimationsEnabled(enabled bool) error
 }
 
+// DevToolsInspector is implemented by devices whose browsers and debuggable
+// webviews can be inspected over a remote inspector protocol, the Chrome
+// DevTools protocol on Android and the WebKit remote inspector on iOS,
+// including webviews of hybrid apps that UI tree dumps don't see into.
+type DevToolsInspector interface {
+	ListDevToolsTargets() ([]DevToolsTarget, error)
+	DumpDevToolsTarget(targetID string) (any, error)
+	EvaluateDevToolsTarget(targetID, expression string) (any, error)
+}
+
 // WebViewable is implemented by devices that support webview inspection and control.
 type WebViewable interface {
 	ListWebViews() ([]WebViewInfo, error)
@@ -173,92 +592,221 @@ type WebViewable interface {
 	WebViewWaitForLoadState(webviewID, state string, timeoutMs int) error
 }
 
-// GetAllControllableDevices aggregates all known devices with options
+// PortForward is a local port forwarded to a port on the device
+type PortForward struct {
+	DeviceID   string `json:"deviceId"`
+	Name       string `json:"name"`
+	LocalPort  int    `json:"localPort"`
+	DevicePort int    `json:"devicePort"`
+}
+
+// PortForwardLister is implemented by devices that keep port forwarders open
+// for their agents.
+type PortForwardLister interface {
+	PortForwards() []PortForward
+}
+
+// PortReverse is a port on a device that reaches a port on the host
+type PortReverse struct {
+	DeviceID   string `json:"deviceId"`
+	DevicePort int    `json:"devicePort"`
+	HostPort   int    `json:"hostPort"`
+}
+
+// PortReverser is implemented by devices whose apps can reach services on
+// the host through a port of their own, such as a mock server the automation
+// host runs
+type PortReverser interface {
+	ReversePort(devicePort, hostPort int) error
+
+	// RemoveReverse stops forwarding a device port, or every one when
+	// devicePort is 0
+	RemoveReverse(devicePort int) error
+
+	ListReverses() ([]PortReverse, error)
+}
+
+// FakeDevicesEnvVar sets how many in-memory fake devices are listed instead
+// of real devices, for testing without hardware
+const FakeDevicesEnvVar = "MOBILECLI_FAKE_DEVICES"
+
+// fakeDevices lists fake devices, registered by the devices/fake package
+var fakeDevices func(count int) []ControllableDevice
+
+// RegisterFakeDevices sets the function that lists fake devices when
+// FakeDevicesEnvVar is set
+func RegisterFakeDevices(list func(count int) []ControllableDevice) {
+	fakeDevices = list
+}
+
+// DefaultBackendTimeout is how long listing devices waits for each backend,
+// such as adb or go-ios, before leaving its devices out
+const DefaultBackendTimeout = 10 * time.Second
+
+// BackendWarning reports a backend whose devices are missing from a listing
+// because it didn't answer in time, or a listed device that can't be used
+// yet, such as an unauthorized Android device
+type BackendWarning struct {
+	Backend string `json:"backend"`
+	Message string `json:"message"`
+}
+
+// deviceBackend lists the devices of one platform tool
+type deviceBackend struct {
+	name string
+	list func() ([]ControllableDevice, error)
+}
+
+// GetAllControllableDevices aggregates all known devices with options,
+// leaving out simulators that were never booted
 func GetAllControllableDevices(includeOffline bool) ([]ControllableDevice, error) {
+	return ListAllControllableDevices(includeOffline, false)
+}
 
-	var allDevices []ControllableDevice
+// ListAllControllableDevices aggregates all known devices, including
+// simulators that were never booted when includeUnbooted is true. Backends
+// that don't answer within DefaultBackendTimeout are left out.
+func ListAllControllableDevices(includeOffline, includeUnbooted bool) ([]ControllableDevice, error) {
+	allDevices, warnings := ListAllControllableDevicesWithWarnings(includeOffline, includeUnbooted)
+	for _, warning := range warnings {
+		utils.Verbose("Warning: %s", warning.Message)
+	}
+	return allDevices, nil
+}
+
+// ListAllControllableDevicesWithWarnings is ListAllControllableDevices, also
+// returning the backends that timed out, whose devices are missing from the
+// listing though they may still be connected
+func ListAllControllableDevicesWithWarnings(includeOffline, includeUnbooted bool) ([]ControllableDevice, []BackendWarning) {
+	return listControllableDevices(includeOffline, includeUnbooted, DefaultBackendTimeout)
+}
 
+// listControllableDevices queries every backend at once, so one that hangs,
+// e.g. go-ios on a half-connected phone, only costs its own devices. A
+// backend still running after timeout is left running and reported.
+func listControllableDevices(includeOffline, includeUnbooted bool, timeout time.Duration) ([]ControllableDevice, []BackendWarning) {
 	if os.Getenv("MOBILECLI_REMOTE_ONLY") != "" {
-		return allDevices, nil
+		return nil, nil
 	}
 
-	startTotal := time.Now()
+	if count, err := strconv.Atoi(os.Getenv(FakeDevicesEnvVar)); err == nil && count > 0 && fakeDevices != nil {
+		return fakeDevices(count), nil
+	}
 
-	// get Android devices
-	startAndroid := time.Now()
-	androidDevices, err := GetAndroidDevices()
-	androidDuration := time.Since(startAndroid).Milliseconds()
-	androidCount := 0
-	if err != nil {
-		utils.Verbose("Warning: Failed to get Android devices: %v", err)
-	} else {
-		androidCount = len(androidDevices)
-		allDevices = append(allDevices, androidDevices...)
-	}
-
-	// get offline Android emulators if requested
-	offlineAndroidCount := 0
-	offlineAndroidDuration := int64(0)
-	if includeOffline {
-		// build map of online device IDs for quick lookup
-		onlineDeviceIDs := make(map[string]bool)
-		for _, device := range androidDevices {
-			onlineDeviceIDs[device.ID()] = true
-		}
+	backends := []deviceBackend{
+		{name: "android", list: func() ([]ControllableDevice, error) {
+			androidDevices, err := GetAndroidDevices()
+			if err != nil || !includeOffline {
+				return androidDevices, err
+			}
 
-		startOfflineAndroid := time.Now()
-		offlineEmulators, err := getOfflineAndroidEmulators(onlineDeviceIDs)
-		offlineAndroidDuration = time.Since(startOfflineAndroid).Milliseconds()
-		if err != nil {
-			utils.Verbose("Warning: Failed to get offline Android emulators: %v", err)
-		} else {
-			offlineAndroidCount = len(offlineEmulators)
-			allDevices = append(allDevices, offlineEmulators...)
-		}
+			// build map of online device IDs for quick lookup
+			onlineDeviceIDs := make(map[string]bool)
+			for _, device := range androidDevices {
+				onlineDeviceIDs[device.ID()] = true
+			}
+
+			offlineEmulators, err := getOfflineAndroidEmulators(onlineDeviceIDs)
+			if err != nil {
+				utils.Verbose("Warning: Failed to get offline Android emulators: %v", err)
+				return androidDevices, nil
+			}
+			return append(androidDevices, offlineEmulators...), nil
+		}},
+		{name: "ios", list: func() ([]ControllableDevice, error) {
+			iosDevices, err := ListIOSDevices()
+			if err != nil {
+				return nil, err
+			}
+
+			list := make([]ControllableDevice, 0, len(iosDevices))
+			for i := range iosDevices {
+				list = append(list, &iosDevices[i])
+			}
+			return list, nil
+		}},
+		{name: "simulators", list: func() ([]ControllableDevice, error) {
+			// all simulators, not just booted ones
+			sims, err := GetSimulators()
+			if err != nil {
+				return nil, err
+			}
+
+			// only include simulators that have been booted at least once,
+			// unless asked for all of them
+			if !includeUnbooted {
+				sims = filterSimulatorsByDownloadsDirectory(sims)
+			}
+
+			list := make([]ControllableDevice, 0, len(sims))
+			for _, sim := range sims {
+				list = append(list, &SimulatorDevice{
+					Simulator: sim,
+					wdaClient: nil,
+				})
+			}
+			return list, nil
+		}},
 	}
 
-	// get iOS real devices
-	startIOS := time.Now()
-	iosDevices, err := ListIOSDevices()
-	iosDuration := time.Since(startIOS).Milliseconds()
-	iosCount := 0
-	if err != nil {
-		utils.Verbose("Warning: Failed to get iOS real devices: %v", err)
-	} else {
-		iosCount = len(iosDevices)
-		for i := range iosDevices {
-			allDevices = append(allDevices, &iosDevices[i])
-		}
+	return listBackends(backends, timeout)
+}
+
+// listBackends runs the backends concurrently and collects their devices in
+// order, giving up on those that haven't finished when timeout has passed
+func listBackends(backends []deviceBackend, timeout time.Duration) ([]ControllableDevice, []BackendWarning) {
+	type backendResult struct {
+		devices []ControllableDevice
+		err     error
 	}
 
-	// get iOS simulator devices (all simulators, not just booted ones)
-	startSimulators := time.Now()
-	sims, err := GetSimulators()
-	simulatorsDuration := time.Since(startSimulators).Milliseconds()
-	simulatorsCount := 0
-	if err != nil {
-		utils.Verbose("Warning: Failed to get iOS simulators: %v", err)
-	} else {
-		// filter to only include simulators that have been booted at least once
-		filteredSims := filterSimulatorsByDownloadsDirectory(sims)
-		simulatorsCount = len(filteredSims)
-		for _, sim := range filteredSims {
-			allDevices = append(allDevices, &SimulatorDevice{
-				Simulator: sim,
-				wdaClient: nil,
-			})
-		}
+	results := make([]chan backendResult, len(backends))
+	for i, backend := range backends {
+		results[i] = make(chan backendResult, 1)
+		go func() {
+			list, err := backend.list()
+			results[i] <- backendResult{devices: list, err: err}
+		}()
 	}
 
-	totalDuration := time.Since(startTotal).Milliseconds()
+	var allDevices []ControllableDevice
+	var warnings []BackendWarning
+	deadline := time.After(timeout)
+	timedOut := false
+	for i, backend := range backends {
+		var result backendResult
+		if timedOut {
+			select {
+			case result = <-results[i]:
+			default:
+				warnings = append(warnings, backendTimeoutWarning(backend.name, timeout))
+				continue
+			}
+		} else {
+			select {
+			case result = <-results[i]:
+			case <-deadline:
+				timedOut = true
+				warnings = append(warnings, backendTimeoutWarning(backend.name, timeout))
+				continue
+			}
+		}
 
-	// log all timing stats in one verbose message
-	if false {
-		utils.Verbose("GetAllControllableDevices completed in %dms: android=%dms (%d devices), offline_android=%dms (%d devices), ios=%dms (%d devices), simulators=%dms (%d devices)",
-			totalDuration, androidDuration, androidCount, offlineAndroidDuration, offlineAndroidCount, iosDuration, iosCount, simulatorsDuration, simulatorsCount)
+		if result.err != nil {
+			utils.Verbose("Warning: Failed to get %s devices: %v", backend.name, result.err)
+			continue
+		}
+		allDevices = append(allDevices, result.devices...)
 	}
 
-	return allDevices, nil
+	return allDevices, warnings
+}
+
+func backendTimeoutWarning(backend string, timeout time.Duration) BackendWarning {
+	return BackendWarning{
+		Backend: backend,
+		Message: fmt.Sprintf("listing %s devices timed out after %s, they are left out", backend, timeout),
+	}
 }
 
 // DeviceInfo represents the JSON-friendly device information
@@ -267,6 +815,17 @@ type DeviceListOptions struct {
 	IncludeOffline bool
 	Platform       string
 	DeviceType     string
+	Transport      string            // TransportUSB, TransportNetwork, or empty for all
+	CheckAgents    bool              // probe each device's agent health, see CheckAgents
+	Labels         map[string]string // only list devices with all of these labels
+
+	// IncludeUnbooted lists simulators that were never booted, which implies
+	// IncludeOffline, and describes the runtime of every simulator
+	IncludeUnbooted bool
+
+	// BackendTimeoutMs is how long to wait for each backend before leaving
+	// its devices out, 0 for DefaultBackendTimeout
+	BackendTimeoutMs int
 }
 
 type DeviceProvider struct {
@@ -275,14 +834,29 @@ type DeviceProvider struct {
 }
 
 type DeviceInfo struct {
-	ID       string          `json:"id"`
-	Name     string          `json:"name"`
-	Platform string          `json:"platform"`
-	Type     string          `json:"type"`
-	Version  string          `json:"version"`
-	State    string          `json:"state"`
-	Model    string          `json:"model"`
-	Provider json.RawMessage `json:"provider,omitempty"`
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Platform  string          `json:"platform"`
+	Type      string          `json:"type"`
+	Version   string          `json:"version"`
+	State     string          `json:"state"`
+	Model     string          `json:"model"`
+	Transport string          `json:"transport,omitempty"`
+	Provider  json.RawMessage `json:"provider,omitempty"`
+	Agent     *AgentStatus    `json:"agent,omitempty"`
+
+	// USBPath is the USB port path of a device plugged in over USB, e.g.
+	// "1-1.4", which 'usb:1-1.4' selects the device by
+	USBPath string `json:"usbPath,omitempty"`
+
+	// AdbTransportID is the transport ID of an Android device's adb connection
+	AdbTransportID string `json:"adbTransportId,omitempty"`
+
+	// Labels are the user-defined labels of the device, see UpdateDeviceLabels
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Simulator is set for simulators when listed with IncludeUnbooted
+	Simulator *SimulatorDetails `json:"simulator,omitempty"`
 }
 
 func (d *DeviceInfo) ProviderType() string {
@@ -315,18 +889,91 @@ type ScreenSize struct {
 
 type FullDeviceInfo struct {
 	DeviceInfo
-	ScreenSize *ScreenSize `json:"screenSize"`
+	ScreenSize    *ScreenSize        `json:"screenSize"`
+	ScreenState   string             `json:"screenState,omitempty"`
+	ForegroundApp *ForegroundAppInfo `json:"foregroundApp,omitempty"`
+	Diagnostics   *DeviceDiagnostics `json:"diagnostics,omitempty"`
+}
+
+// DeviceDiagnostics is the management state and health of a device, which
+// lab tooling checks to spot misconfigured devices
+type DeviceDiagnostics struct {
+	ActivationState string                 `json:"activationState,omitempty"`
+	Supervised      bool                   `json:"supervised"`
+	Organization    string                 `json:"organization,omitempty"`
+	Storage         *StorageVolume         `json:"storage,omitempty"`
+	Battery         *BatteryHealth         `json:"battery,omitempty"`
+	Profiles        []ConfigurationProfile `json:"profiles,omitempty"`
+}
+
+// BatteryHealth is the charge and wear of a device's battery
+type BatteryHealth struct {
+	Level              int  `json:"level"`
+	Charging           bool `json:"charging"`
+	CycleCount         int  `json:"cycleCount,omitempty"`
+	MaxCapacityPercent int  `json:"maxCapacityPercent,omitempty"`
+}
+
+// ConfigurationProfile is a configuration profile installed on a device, such
+// as an MDM enrollment
+type ConfigurationProfile struct {
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+	Active     bool   `json:"active"`
+	Removable  bool   `json:"removable"`
+}
+
+// DiagnosticsProvider is implemented by devices that report DeviceDiagnostics
+type DiagnosticsProvider interface {
+	Diagnostics() (*DeviceDiagnostics, error)
 }
 
 // GetDeviceInfoList returns a list of DeviceInfo for all connected devices
 func GetDeviceInfoList(opts DeviceListOptions) ([]DeviceInfo, error) {
+	list, warnings, err := GetDeviceInfoListWithWarnings(opts)
+	for _, warning := range warnings {
+		utils.Verbose("Warning: %s", warning.Message)
+	}
+	return list, err
+}
+
+// GetDeviceInfoListWithWarnings returns a list of DeviceInfo for all
+// connected devices, and the backends that timed out and are left out
+func GetDeviceInfoListWithWarnings(opts DeviceListOptions) ([]DeviceInfo, []BackendWarning, error) {
 	startTime := time.Now()
-	devices, err := GetAllControllableDevices(opts.IncludeOffline)
+	if opts.IncludeUnbooted {
+		opts.IncludeOffline = true
+	}
+	if opts.BackendTimeoutMs < 0 {
+		return nil, nil, fmt.Errorf("backend timeout must be non-negative, got %d", opts.BackendTimeoutMs)
+	}
+
+	timeout := DefaultBackendTimeout
+	if opts.BackendTimeoutMs > 0 {
+		timeout = time.Duration(opts.BackendTimeoutMs) * time.Millisecond
+	}
+	devices, warnings := listControllableDevices(opts.IncludeOffline, opts.IncludeUnbooted, timeout)
+
+	var runtimes map[string]simulatorRuntime
+	if opts.IncludeUnbooted && opts.Platform != "android" {
+		var err error
+		runtimes, err = getSimulatorRuntimes()
+		if err != nil {
+			utils.Verbose("Failed to list simulator runtimes: %v", err)
+		}
+	}
+
+	// a broken labels file only matters when filtering by labels
+	labels, err := GetAllDeviceLabels()
 	if err != nil {
-		return nil, fmt.Errorf("error getting devices: %w", err)
+		if len(opts.Labels) > 0 {
+			return nil, nil, err
+		}
+		utils.Verbose("Ignoring device labels: %v", err)
 	}
 
 	deviceInfoList := make([]DeviceInfo, 0, len(devices))
+	listed := make([]ControllableDevice, 0, len(devices))
 	for _, d := range devices {
 		state := d.State()
 
@@ -345,8 +992,36 @@ func GetDeviceInfoList(opts DeviceListOptions) ([]DeviceInfo, error) {
 			continue
 		}
 
+		// filter by transport if specified, devices without a transport never match
+		transport := ""
+		if t, ok := d.(TransportAware); ok {
+			transport = t.Transport()
+		}
+		if opts.Transport != "" && transport != opts.Transport {
+			continue
+		}
+
+		// filter by labels if specified
+		if !MatchesLabels(labels[d.ID()], opts.Labels) {
+			continue
+		}
+
+		if state == AndroidStateUnauthorized {
+			warnings = append(warnings, BackendWarning{
+				Backend: "android",
+				Message: fmt.Sprintf("device %s is unauthorized, allow USB debugging on it or run 'mobilecli device authorize --device %s'", d.ID(), d.ID()),
+			})
+		}
+
+		usbPath := ""
+		if l, ok := d.(USBLocator); ok {
+			usbPath = l.USBPath()
+		}
+
 		// get model for devices
+		var simulator *SimulatorDetails
 		model := ""
+		adbTransportID := ""
 		if d.Platform() == "ios" {
 			if d.DeviceType() == "real" {
 				if iosDevice, ok := d.(*IOSDevice); ok {
@@ -355,27 +1030,43 @@ func GetDeviceInfoList(opts DeviceListOptions) ([]DeviceInfo, error) {
 			} else if d.DeviceType() == "simulator" {
 				if simDevice, ok := d.(*SimulatorDevice); ok {
 					model = simDevice.Simulator.DeviceType
+					if opts.IncludeUnbooted {
+						simulator = simulatorDetails(simDevice.Simulator, runtimes)
+					}
 				}
 			}
 		} else if d.Platform() == "android" {
 			if androidDevice, ok := d.(*AndroidDevice); ok {
 				model = androidDevice.model
+				adbTransportID = androidDevice.adbTransportID
 			}
 		}
 
 		deviceInfoList = append(deviceInfoList, DeviceInfo{
-			ID:       d.ID(),
-			Name:     d.Name(),
-			Platform: d.Platform(),
-			Type:     d.DeviceType(),
-			Version:  d.Version(),
-			State:    state,
-			Model:    model,
+			ID:             d.ID(),
+			Name:           d.Name(),
+			Platform:       d.Platform(),
+			Type:           d.DeviceType(),
+			Version:        d.Version(),
+			State:          state,
+			Model:          model,
+			Transport:      transport,
+			USBPath:        usbPath,
+			AdbTransportID: adbTransportID,
+			Labels:         labels[d.ID()],
+			Simulator:      simulator,
 		})
+		listed = append(listed, d)
+	}
+
+	if opts.CheckAgents {
+		for i, status := range CheckAgents(listed, DefaultAgentCheckTimeout) {
+			deviceInfoList[i].Agent = status
+		}
 	}
 	utils.Verbose("GetDeviceInfoList took %s", time.Since(startTime))
 
-	return deviceInfoList, nil
+	return deviceInfoList, warnings, nil
 }
 
 // InstalledAppInfo represents information about an installed application.
@@ -0,0 +1,39 @@
+package devices
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListBackendsLeavesOutHungBackends(t *testing.T) {
+	hung := make(chan struct{})
+	t.Cleanup(func() { close(hung) })
+
+	device := &SimulatorDevice{Simulator: Simulator{UDID: "sim-1"}}
+	backends := []deviceBackend{
+		{name: "android", list: func() ([]ControllableDevice, error) {
+			return nil, errors.New("adb not found")
+		}},
+		{name: "ios", list: func() ([]ControllableDevice, error) {
+			<-hung
+			return nil, nil
+		}},
+		{name: "simulators", list: func() ([]ControllableDevice, error) {
+			return []ControllableDevice{device}, nil
+		}},
+	}
+
+	start := time.Now()
+	list, warnings := listBackends(backends, 50*time.Millisecond)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// a failing backend is only logged, a hung one is reported
+	assert.Equal(t, []ControllableDevice{device}, list)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "ios", warnings[0].Backend)
+	assert.Equal(t, "listing ios devices timed out after 50ms, they are left out", warnings[0].Message)
+}
@@ -0,0 +1,71 @@
+package devices
+
+import "github.com/mobile-next/mobilecli/types"
+
+// DumpLimits bound the UI tree of a dump, for deep hierarchies such as
+// webviews whose full dumps are huge and slow. Zero values mean no limit.
+type DumpLimits struct {
+	// MaxDepth is how many levels of the view hierarchy are traversed
+	MaxDepth int
+
+	// MaxElements is how many elements are returned, in document order
+	MaxElements int
+
+	// ViewportOnly skips the elements outside of the screen
+	ViewportOnly bool
+}
+
+// IsZero reports whether no limit is set
+func (l DumpLimits) IsZero() bool {
+	return l == DumpLimits{}
+}
+
+// LimitedSourceDumper is implemented by devices that apply dump limits while
+// collecting the UI tree, rather than after the full tree was collected
+type LimitedSourceDumper interface {
+	DumpSourceWithLimits(limits DumpLimits) ([]ScreenElement, error)
+}
+
+// LimitElements applies dump limits to a collected UI tree. Elements outside
+// of screen are dropped with ViewportOnly, their children on the screen
+// taking their place. It reports whether any element was left out.
+func LimitElements(elements []ScreenElement, limits DumpLimits, screen *ScreenSize) ([]ScreenElement, bool) {
+	remaining := limits.MaxElements
+	truncated := false
+
+	var limit func(elements []ScreenElement, depth int) []ScreenElement
+	limit = func(elements []ScreenElement, depth int) []ScreenElement {
+		var kept []ScreenElement
+		for _, element := range elements {
+			if limits.MaxElements > 0 && remaining == 0 {
+				truncated = true
+				break
+			}
+
+			if limits.ViewportOnly && screen != nil && !onScreen(element.Rect, screen) {
+				truncated = true
+				kept = append(kept, limit(element.Children, depth)...)
+				continue
+			}
+
+			remaining--
+			if limits.MaxDepth > 0 && depth >= limits.MaxDepth {
+				if len(element.Children) > 0 {
+					truncated = true
+				}
+				element.Children = nil
+			} else {
+				element.Children = limit(element.Children, depth+1)
+			}
+			kept = append(kept, element)
+		}
+		return kept
+	}
+
+	return limit(elements, 1), truncated
+}
+
+// onScreen reports whether an element is at least partly visible on screen
+func onScreen(rect types.ScreenElementRect, screen *ScreenSize) bool {
+	return rect.X < screen.Width && rect.Y < screen.Height && rect.X+rect.Width > 0 && rect.Y+rect.Height > 0
+}
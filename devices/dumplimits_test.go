@@ -0,0 +1,90 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/mobile-next/mobilecli/types"
+)
+
+func limitsTestTree() []ScreenElement {
+	rect := func(x, y int) types.ScreenElementRect {
+		return types.ScreenElementRect{X: x, Y: y, Width: 100, Height: 100}
+	}
+
+	return []ScreenElement{
+		{
+			Type: "WebView",
+			Rect: rect(0, 0),
+			Children: []ScreenElement{
+				{Type: "Button", Rect: rect(0, 0)},
+				{Type: "StaticText", Rect: rect(0, 2000)},
+			},
+		},
+		{
+			Type:     "Other",
+			Rect:     rect(0, 3000),
+			Children: []ScreenElement{{Type: "Image", Rect: rect(0, 500)}},
+		},
+	}
+}
+
+func TestLimitElementsMaxDepth(t *testing.T) {
+	elements, truncated := LimitElements(limitsTestTree(), DumpLimits{MaxDepth: 1}, nil)
+	if !truncated {
+		t.Error("expected the dump to be truncated")
+	}
+	if len(elements) != 2 || elements[0].Children != nil || elements[1].Children != nil {
+		t.Errorf("expected only the top level, got %+v", elements)
+	}
+}
+
+func TestLimitElementsViewportOnly(t *testing.T) {
+	screen := &ScreenSize{Width: 400, Height: 800}
+	elements, truncated := LimitElements(limitsTestTree(), DumpLimits{ViewportOnly: true}, screen)
+	if !truncated {
+		t.Error("expected the dump to be truncated")
+	}
+
+	// the off-screen text is dropped, and the image on screen takes the
+	// place of its off-screen parent
+	if len(elements) != 2 || elements[1].Type != "Image" {
+		t.Fatalf("unexpected elements %+v", elements)
+	}
+	if len(elements[0].Children) != 1 || elements[0].Children[0].Type != "Button" {
+		t.Errorf("expected only the button in the webview, got %+v", elements[0].Children)
+	}
+}
+
+func TestLimitElementsMaxElements(t *testing.T) {
+	elements, truncated := LimitElements(limitsTestTree(), DumpLimits{MaxElements: 2}, nil)
+	if !truncated {
+		t.Error("expected the dump to be truncated")
+	}
+	if len(elements) != 1 || len(elements[0].Children) != 1 || elements[0].Children[0].Type != "Button" {
+		t.Errorf("expected the webview and its button, got %+v", elements)
+	}
+
+	if _, truncated := LimitElements(limitsTestTree(), DumpLimits{MaxElements: 5}, nil); truncated {
+		t.Error("expected a limit above the element count not to truncate")
+	}
+}
+
+func TestTruncateUiAutomatorNode(t *testing.T) {
+	tree := uiAutomatorXmlNode{
+		Class: "android.widget.FrameLayout",
+		Nodes: []uiAutomatorXmlNode{
+			{
+				Class: "android.widget.LinearLayout",
+				Nodes: []uiAutomatorXmlNode{{Class: "android.widget.Button"}},
+			},
+		},
+	}
+
+	truncated := truncateUiAutomatorNode(tree, 2)
+	if len(truncated.Nodes) != 1 || truncated.Nodes[0].Nodes != nil {
+		t.Errorf("expected two levels, got %+v", truncated)
+	}
+	if len(tree.Nodes[0].Nodes) != 1 {
+		t.Error("expected the original tree to be left intact")
+	}
+}
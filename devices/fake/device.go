@@ -0,0 +1,865 @@
+// Package fake provides in-memory devices, so the commands and server layers
+// can be exercised without real hardware, simulators or emulators. They are
+// listed instead of real devices when MOBILECLI_FAKE_DEVICES is set to the
+// number of fake devices wanted.
+package fake
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/wda"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// Device is an in-memory devices.ControllableDevice. It records every action
+// it is asked to perform, and its screen, apps and UI tree can be set up by
+// tests, as can an error for any method.
+type Device struct {
+	mu sync.Mutex
+
+	id         string
+	name       string
+	platform   string
+	deviceType string
+	version    string
+	state      string
+
+	width, height int
+	screenshot    []byte
+	elements      []devices.ScreenElement
+	apps          []devices.InstalledAppInfo
+	foreground    string
+	running       map[string]bool
+	orientation   string
+	screenState   string
+	files         map[string][]byte
+	crashes       map[string][]byte
+	alert         *wda.Alert
+	errors        map[string]error
+	actions       []string
+	reverses      map[int]int
+	logs          []string
+}
+
+// New returns an online fake device of the given platform, "android" or
+// "ios", with a settings app and a small UI tree on its screen
+func New(id, platform string) *Device {
+	d := &Device{
+		id:          id,
+		platform:    platform,
+		state:       "online",
+		orientation: "portrait",
+		screenState: devices.ScreenStateOn,
+		files:       map[string][]byte{},
+		crashes:     map[string][]byte{},
+		errors:      map[string]error{},
+		reverses:    map[int]int{},
+	}
+
+	if platform == "ios" {
+		d.name = "Fake iPhone"
+		d.deviceType = "simulator"
+		d.version = "17.5"
+		d.width, d.height = 393, 852
+		d.apps = []devices.InstalledAppInfo{{PackageName: "com.apple.Preferences", AppName: "Settings", Version: "1.0"}}
+	} else {
+		d.name = "Fake Android"
+		d.deviceType = "emulator"
+		d.version = "14"
+		d.width, d.height = 1080, 2400
+		d.apps = []devices.InstalledAppInfo{{PackageName: "com.android.settings", AppName: "Settings", Version: "14"}}
+	}
+	d.foreground = d.apps[0].PackageName
+	d.running = map[string]bool{d.foreground: true}
+
+	label := "OK"
+	placeholder := "Search"
+	d.elements = []devices.ScreenElement{
+		{Type: "Button", Label: &label, Rect: devices.ScreenElementRect{X: 40, Y: 200, Width: 200, Height: 80}},
+		{Type: "TextField", Placeholder: &placeholder, Rect: devices.ScreenElementRect{X: 40, Y: 100, Width: 300, Height: 60}},
+	}
+
+	return d
+}
+
+// SetScreenshot sets the image TakeScreenshot and screen capture return,
+// instead of a blank screen
+func (d *Device) SetScreenshot(image []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.screenshot = image
+}
+
+// SetElements sets the UI tree DumpSource returns
+func (d *Device) SetElements(elements []devices.ScreenElement) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.elements = elements
+}
+
+// SetApps sets the installed apps
+func (d *Device) SetApps(apps []devices.InstalledAppInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.apps = apps
+}
+
+// SetScreenState sets the screen state, one of the devices.ScreenState*
+// values
+func (d *Device) SetScreenState(state string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.screenState = state
+}
+
+// AddCrashReport adds a crash report ListCrashReports lists
+func (d *Device) AddCrashReport(id string, report []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.crashes[id] = report
+}
+
+// SetAlert sets the alert GetAlert returns, nil for none
+func (d *Device) SetAlert(alert *wda.Alert) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.alert = alert
+}
+
+// SetLogs sets the lines StreamLogs streams
+func (d *Device) SetLogs(lines []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.logs = lines
+}
+
+// FailWith makes the named method, e.g. "Tap", return err; a nil err
+// clears it
+func (d *Device) FailWith(method string, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err == nil {
+		delete(d.errors, method)
+		return
+	}
+	d.errors[method] = err
+}
+
+// Actions returns the actions performed on the device so far, such as
+// "tap 100,200" or "launch com.example.app"
+func (d *Device) Actions() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.actions...)
+}
+
+// do records an action, unless an error was injected for method
+func (d *Device) do(method, format string, args ...any) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.doLocked(method, format, args...)
+}
+
+func (d *Device) doLocked(method, format string, args ...any) error {
+	if err := d.errors[method]; err != nil {
+		return err
+	}
+	d.actions = append(d.actions, fmt.Sprintf(format, args...))
+	return nil
+}
+
+func (d *Device) fail(method string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.errors[method]
+}
+
+func (d *Device) ID() string         { return d.id }
+func (d *Device) Name() string       { return d.name }
+func (d *Device) Platform() string   { return d.platform }
+func (d *Device) DeviceType() string { return d.deviceType }
+func (d *Device) Version() string    { return d.version }
+
+func (d *Device) State() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+func (d *Device) TakeScreenshot() ([]byte, error) {
+	if err := d.fail("TakeScreenshot"); err != nil {
+		return nil, err
+	}
+	return d.screen()
+}
+
+// screen returns the screenshot set with SetScreenshot, or a blank PNG the
+// size of the screen
+func (d *Device) screen() ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.screenshot == nil {
+		img := image.NewGray(image.Rect(0, 0, d.width, d.height))
+		for i := range img.Pix {
+			img.Pix[i] = color.Gray{Y: 0xee}.Y
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		d.screenshot = buf.Bytes()
+	}
+	return d.screenshot, nil
+}
+
+func (d *Device) Reboot() error {
+	return d.do("Reboot", "reboot")
+}
+
+func (d *Device) Boot(config devices.BootConfig) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked("Boot", "boot"); err != nil {
+		return err
+	}
+	d.state = "online"
+	return nil
+}
+
+func (d *Device) Shutdown() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked("Shutdown", "shutdown"); err != nil {
+		return err
+	}
+	d.state = "offline"
+	return nil
+}
+
+func (d *Device) Tap(x, y int) error {
+	return d.do("Tap", "tap %d,%d", x, y)
+}
+
+func (d *Device) LongPress(x, y, duration int) error {
+	return d.do("LongPress", "longpress %d,%d %dms", x, y, duration)
+}
+
+func (d *Device) Swipe(x1, y1, x2, y2, duration int) error {
+	return d.do("Swipe", "swipe %d,%d %d,%d %dms", x1, y1, x2, y2, duration)
+}
+
+func (d *Device) Gesture(actions []wda.TapAction) error {
+	return d.do("Gesture", "gesture %d actions", len(actions))
+}
+
+func (d *Device) StartAgent(config devices.StartAgentConfig) error {
+	return d.fail("StartAgent")
+}
+
+func (d *Device) SendKeys(text string) error {
+	return d.do("SendKeys", "type %s", text)
+}
+
+func (d *Device) PressKeys(combos []devices.KeyCombo) error {
+	keys := make([]string, len(combos))
+	for i, combo := range combos {
+		keys[i] = strings.Join(append(append([]string(nil), combo.Modifiers...), combo.Key), "+")
+	}
+	return d.do("PressKeys", "keys %s", strings.Join(keys, " "))
+}
+
+func (d *Device) PressButton(key string) error {
+	return d.do("PressButton", "button %s", key)
+}
+
+// PressKeycode records pressing a raw keycode, which only Android supports
+func (d *Device) PressKeycode(keycode int) error {
+	if d.platform != "android" {
+		return devices.NewUnsupportedButtonError(fmt.Sprintf("keycode %d", keycode), d.platform)
+	}
+	return d.do("PressKeycode", "keycode %d", keycode)
+}
+
+func (d *Device) LaunchApp(bundleID string, opts devices.LaunchOptions) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.findApp(bundleID) < 0 {
+		return fmt.Errorf("app %s is not installed", bundleID)
+	}
+	if err := d.doLocked("LaunchApp", "launch %s", bundleID); err != nil {
+		return err
+	}
+	d.foreground = bundleID
+	d.running[bundleID] = true
+	return nil
+}
+
+func (d *Device) TerminateApp(bundleID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.doLocked("TerminateApp", "terminate %s", bundleID); err != nil {
+		return err
+	}
+	if d.foreground == bundleID {
+		d.foreground = ""
+	}
+	delete(d.running, bundleID)
+	return nil
+}
+
+func (d *Device) OpenURL(url string) error {
+	return d.do("OpenURL", "open %s", url)
+}
+
+func (d *Device) findApp(bundleID string) int {
+	for i, app := range d.apps {
+		if app.PackageName == bundleID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *Device) ListApps(onlyLaunchable bool) ([]devices.InstalledAppInfo, error) {
+	if err := d.fail("ListApps"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]devices.InstalledAppInfo(nil), d.apps...), nil
+}
+
+func (d *Device) GetForegroundApp() (*devices.ForegroundAppInfo, error) {
+	if err := d.fail("GetForegroundApp"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if i := d.findApp(d.foreground); i >= 0 {
+		app := d.apps[i]
+		return &devices.ForegroundAppInfo{PackageName: app.PackageName, AppName: app.AppName, Version: app.Version}, nil
+	}
+	return nil, fmt.Errorf("no app in the foreground")
+}
+
+// SetTime records the time the device is set to
+func (d *Device) SetTime(t time.Time) (string, error) {
+	if err := d.do("SetTime", "settime %s", t.UTC().Format(time.RFC3339)); err != nil {
+		return "", err
+	}
+	return devices.ClockScopeSystem, nil
+}
+
+// SyncTime records going back to the host's time
+func (d *Device) SyncTime() error {
+	return d.do("SyncTime", "synctime")
+}
+
+// SetSensor records the sensor values the device is set to
+func (d *Device) SetSensor(name string, values []float64) error {
+	return d.do("SetSensor", "sensor %s %v", name, values)
+}
+
+// Shake records shaking the device
+func (d *Device) Shake() error {
+	return d.do("Shake", "shake")
+}
+
+// IncomingCall records a simulated incoming call
+func (d *Device) IncomingCall(number string) error {
+	return d.do("IncomingCall", "call %s", number)
+}
+
+// EndCall records hanging up a simulated call
+func (d *Device) EndCall(number string) error {
+	return d.do("EndCall", "endcall %s", number)
+}
+
+// ReceiveSMS records a simulated text message
+func (d *Device) ReceiveSMS(number, text string) error {
+	return d.do("ReceiveSMS", "sms %s %s", number, text)
+}
+
+// AddMedia records the media added to the device
+func (d *Device) AddMedia(paths []string) error {
+	return d.do("AddMedia", "addmedia %s", strings.Join(paths, " "))
+}
+
+// AddContacts records the contacts file imported to the device
+func (d *Device) AddContacts(vcfPath string) error {
+	return d.do("AddContacts", "addcontacts %s", vcfPath)
+}
+
+// ListRunningApps lists the apps launched and not terminated since, with the
+// foreground app in the foreground state and the others in the background
+func (d *Device) ListRunningApps() ([]devices.RunningAppInfo, error) {
+	if err := d.fail("ListRunningApps"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var running []devices.RunningAppInfo
+	for _, app := range d.apps {
+		if !d.running[app.PackageName] {
+			continue
+		}
+		state := devices.ProcessStateBackground
+		if app.PackageName == d.foreground {
+			state = devices.ProcessStateForeground
+		}
+		running = append(running, devices.RunningAppInfo{
+			PackageName: app.PackageName,
+			State:       state,
+			Processes:   []devices.RunningProcess{{PID: 1000 + len(running), Name: app.PackageName, State: state}},
+		})
+	}
+	return running, nil
+}
+
+// InstallApp installs the app at path, taking its identifier from the app's
+// metadata when it can be parsed and from its file name otherwise
+func (d *Device) InstallApp(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to stat app: %w", err)
+	}
+
+	app := devices.InstalledAppInfo{PackageName: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	if meta, err := utils.ParseAppMetadata(path); err == nil && meta.PackageName != "" {
+		app = devices.InstalledAppInfo{PackageName: meta.PackageName, Version: meta.Version}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.doLocked("InstallApp", "install %s", app.PackageName); err != nil {
+		return err
+	}
+	if i := d.findApp(app.PackageName); i >= 0 {
+		d.apps[i] = app
+	} else {
+		d.apps = append(d.apps, app)
+	}
+	return nil
+}
+
+func (d *Device) UninstallApp(packageName string) (*devices.InstalledAppInfo, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	i := d.findApp(packageName)
+	if i < 0 {
+		return nil, fmt.Errorf("app %s is not installed", packageName)
+	}
+	if err := d.doLocked("UninstallApp", "uninstall %s", packageName); err != nil {
+		return nil, err
+	}
+
+	app := d.apps[i]
+	d.apps = append(d.apps[:i], d.apps[i+1:]...)
+	if d.foreground == packageName {
+		d.foreground = ""
+	}
+	delete(d.running, packageName)
+	return &app, nil
+}
+
+func (d *Device) Info() (*devices.FullDeviceInfo, error) {
+	if err := d.fail("Info"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return &devices.FullDeviceInfo{
+		DeviceInfo: devices.DeviceInfo{
+			ID:       d.id,
+			Name:     d.name,
+			Platform: d.platform,
+			Type:     d.deviceType,
+			Version:  d.version,
+			State:    d.state,
+			Model:    "fake",
+		},
+		ScreenSize: &devices.ScreenSize{Width: d.width, Height: d.height, Scale: 1},
+	}, nil
+}
+
+// StartScreenCapture sends the screenshot as a frame at the requested frame
+// rate until the callback or the context stops it
+func (d *Device) StartScreenCapture(config devices.ScreenCaptureConfig) error {
+	if err := d.fail("StartScreenCapture"); err != nil {
+		return err
+	}
+
+	frame, err := d.screen()
+	if err != nil {
+		return err
+	}
+
+	fps := config.FPS
+	if fps <= 0 {
+		fps = 10
+	}
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer ticker.Stop()
+
+	done := make(<-chan struct{})
+	if config.Context != nil {
+		done = config.Context.Done()
+	}
+
+	for {
+		if config.OnData != nil && !config.OnData(frame) {
+			return nil
+		}
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamLogs streams the lines set with SetLogs, then waits for the context
+// to be done
+func (d *Device) StreamLogs(ctx context.Context, onLine func(line string) bool) error {
+	if err := d.fail("StreamLogs"); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	lines := append([]string(nil), d.logs...)
+	d.mu.Unlock()
+
+	for _, line := range lines {
+		if !onLine(line) {
+			return nil
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (d *Device) DumpSource() ([]devices.ScreenElement, error) {
+	if err := d.fail("DumpSource"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]devices.ScreenElement(nil), d.elements...), nil
+}
+
+func (d *Device) DumpSourceRaw() (any, error) {
+	return d.DumpSource()
+}
+
+func (d *Device) GetOrientation() (string, error) {
+	if err := d.fail("GetOrientation"); err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.orientation, nil
+}
+
+func (d *Device) SetOrientation(orientation string) error {
+	orientation, err := devices.NormalizeOrientation(orientation)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked("SetOrientation", "orientation %s", orientation); err != nil {
+		return err
+	}
+	d.orientation = orientation
+	return nil
+}
+
+func (d *Device) ScreenState() (string, error) {
+	if err := d.fail("ScreenState"); err != nil {
+		return "", err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.screenState, nil
+}
+
+func (d *Device) Lock() error {
+	return d.setScreenState("Lock", "lock", devices.ScreenStateLocked)
+}
+
+func (d *Device) Unlock() error {
+	return d.setScreenState("Unlock", "unlock", devices.ScreenStateOn)
+}
+
+// Wake turns the screen on, showing the keyguard if it was off
+func (d *Device) Wake() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked("Wake", "wake"); err != nil {
+		return err
+	}
+	if d.screenState == devices.ScreenStateOff {
+		d.screenState = devices.ScreenStateLocked
+	}
+	return nil
+}
+
+func (d *Device) ScreenOff() error {
+	return d.setScreenState("ScreenOff", "screen off", devices.ScreenStateOff)
+}
+
+func (d *Device) setScreenState(method, action, state string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked(method, "%s", action); err != nil {
+		return err
+	}
+	d.screenState = state
+	return nil
+}
+
+func (d *Device) GetAlert() (*wda.Alert, error) {
+	if err := d.fail("GetAlert"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.alert, nil
+}
+
+func (d *Device) AcceptAlert(button string) error {
+	return d.answerAlert("AcceptAlert", "accept", button)
+}
+
+func (d *Device) DismissAlert(button string) error {
+	return d.answerAlert("DismissAlert", "dismiss", button)
+}
+
+// answerAlert closes the alert, tapping its named button or its last button
+// to accept and its first to dismiss
+func (d *Device) answerAlert(method, action, button string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.alert == nil {
+		return fmt.Errorf("no alert is shown")
+	}
+
+	if button == "" && len(d.alert.Buttons) > 0 {
+		button = d.alert.Buttons[0]
+		if action == "accept" {
+			button = d.alert.Buttons[len(d.alert.Buttons)-1]
+		}
+	}
+	if err := d.doLocked(method, "alert %s %s", action, button); err != nil {
+		return err
+	}
+	d.alert = nil
+	return nil
+}
+
+func (d *Device) OpenControlCenter() error {
+	return d.do("OpenControlCenter", "control-center open")
+}
+
+func (d *Device) CloseControlCenter() error {
+	return d.do("CloseControlCenter", "control-center close")
+}
+
+// ReversePort records a device port reaching a host port
+func (d *Device) ReversePort(devicePort, hostPort int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.doLocked("ReversePort", "reverse %d %d", devicePort, hostPort); err != nil {
+		return err
+	}
+	d.reverses[devicePort] = hostPort
+	return nil
+}
+
+// RemoveReverse drops a reversed device port, or all of them for 0
+func (d *Device) RemoveReverse(devicePort int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.doLocked("RemoveReverse", "reverse remove %d", devicePort); err != nil {
+		return err
+	}
+	if devicePort == 0 {
+		d.reverses = map[int]int{}
+		return nil
+	}
+	if _, ok := d.reverses[devicePort]; !ok {
+		return fmt.Errorf("port %d is not reversed", devicePort)
+	}
+	delete(d.reverses, devicePort)
+	return nil
+}
+
+// ListReverses returns the reversed device ports, by port
+func (d *Device) ListReverses() ([]devices.PortReverse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reverses := make([]devices.PortReverse, 0, len(d.reverses))
+	for devicePort, hostPort := range d.reverses {
+		reverses = append(reverses, devices.PortReverse{DeviceID: d.id, DevicePort: devicePort, HostPort: hostPort})
+	}
+	sort.Slice(reverses, func(i, j int) bool { return reverses[i].DevicePort < reverses[j].DevicePort })
+	return reverses, nil
+}
+
+// OpenSettings brings the settings app to the foreground
+func (d *Device) OpenSettings() error {
+	if d.platform == "ios" {
+		return d.LaunchApp("com.apple.Preferences", devices.LaunchOptions{})
+	}
+	return d.LaunchApp("com.android.settings", devices.LaunchOptions{})
+}
+
+func (d *Device) SetStayAwake(enabled bool) error {
+	if enabled {
+		return d.do("SetStayAwake", "stay-awake on")
+	}
+	return d.do("SetStayAwake", "stay-awake off")
+}
+
+func (d *Device) ListCrashReports() ([]devices.CrashReport, error) {
+	if err := d.fail("ListCrashReports"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ids := make([]string, 0, len(d.crashes))
+	for id := range d.crashes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return devices.ParseCrashReports(ids), nil
+}
+
+func (d *Device) GetCrashReport(id string) ([]byte, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	report, ok := d.crashes[id]
+	if !ok {
+		return nil, fmt.Errorf("crash report %s not found", id)
+	}
+	return report, nil
+}
+
+func (d *Device) PushFile(localPath, remotePath string) error {
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doLocked("PushFile", "push %s", remotePath); err != nil {
+		return err
+	}
+	d.files[remotePath] = data
+	return nil
+}
+
+func (d *Device) PullFile(remotePath, localPath string) error {
+	d.mu.Lock()
+	data, ok := d.files[remotePath]
+	err := d.errors["PullFile"]
+	d.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no such file: %s", remotePath)
+	}
+	return os.WriteFile(localPath, data, 0o644)
+}
+
+func (d *Device) ListFiles(bundleID, remotePath string) ([]devices.FileEntry, error) {
+	if err := d.fail("ListFiles"); err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	prefix := strings.TrimSuffix(remotePath, "/") + "/"
+	var entries []devices.FileEntry
+	for path, data := range d.files {
+		if strings.HasPrefix(path, prefix) && !strings.Contains(path[len(prefix):], "/") {
+			entries = append(entries, devices.FileEntry{Name: path[len(prefix):], Path: path, Size: int64(len(data))})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries, nil
+}
+
+func (d *Device) Mkdir(bundleID, remotePath string, parents bool) error {
+	return d.do("Mkdir", "mkdir %s", remotePath)
+}
+
+func (d *Device) Rm(bundleID, remotePath string, recursive bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if err := d.doLocked("Rm", "rm %s", remotePath); err != nil {
+		return err
+	}
+	for path := range d.files {
+		if path == remotePath || (recursive && strings.HasPrefix(path, strings.TrimSuffix(remotePath, "/")+"/")) {
+			delete(d.files, path)
+		}
+	}
+	return nil
+}
+
+func (d *Device) GetAppContainerPath(bundleID string) (string, error) {
+	if !d.hasApp(bundleID) {
+		return "", fmt.Errorf("app %s is not installed", bundleID)
+	}
+	if d.platform == "ios" {
+		return "/fake/Containers/Data/Application/" + bundleID, nil
+	}
+	return "/data/data/" + bundleID, nil
+}
+
+func (d *Device) hasApp(bundleID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.findApp(bundleID) >= 0
+}
@@ -0,0 +1,60 @@
+package fake
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+var (
+	registryMu sync.Mutex
+	registry   []*Device
+)
+
+func init() {
+	devices.RegisterFakeDevices(List)
+}
+
+// List returns count fake devices, alternating between Android emulators and
+// iOS simulators. The same devices are returned on every call, so what is
+// done to them persists between commands.
+func List(count int) []devices.ControllableDevice {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for i := len(registry); i < count; i++ {
+		platform := "android"
+		if i%2 == 1 {
+			platform = "ios"
+		}
+		registry = append(registry, New(fmt.Sprintf("fake-%s-%d", platform, i+1), platform))
+	}
+
+	list := make([]devices.ControllableDevice, count)
+	for i := range list {
+		list[i] = registry[i]
+	}
+	return list
+}
+
+// Get returns the fake device with the given ID, for tests to set it up or
+// inspect what was done to it
+func Get(id string) *Device {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, d := range registry {
+		if d.id == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// Reset discards all fake devices and what was done to them
+func Reset() {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = nil
+}
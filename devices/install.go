@@ -0,0 +1,278 @@
+package devices
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/danielpaulus/go-ios/ios/zipconduit"
+	"github.com/mobile-next/mobilecli/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// installProgressInterval throttles byte progress reports while uploading
+const installProgressInterval = 250 * time.Millisecond
+
+// installProgressReader reports the bytes read from an app file as upload
+// progress, and the switch to installing once it has all been read
+type installProgressReader struct {
+	reader     io.Reader
+	total      int64
+	sent       int64
+	config     InstallConfig
+	lastReport time.Time
+}
+
+func (r *installProgressReader) Read(p []byte) (int, error) {
+	// ends the upload, and with it the install, once cancelled
+	if err := r.config.cancelled(); err != nil {
+		return 0, err
+	}
+
+	n, err := r.reader.Read(p)
+	r.sent += int64(n)
+
+	if err == io.EOF {
+		r.config.progress(InstallProgress{Phase: InstallPhaseUploading, BytesSent: r.sent, TotalBytes: r.total, Percent: 100})
+		r.config.progress(InstallProgress{Phase: InstallPhaseInstalling})
+	} else if time.Since(r.lastReport) >= installProgressInterval {
+		r.lastReport = time.Now()
+		r.config.progress(InstallProgress{Phase: InstallPhaseUploading, BytesSent: r.sent, TotalBytes: r.total, Percent: r.percent()})
+	}
+
+	return n, err
+}
+
+func (r *installProgressReader) percent() int {
+	if r.total <= 0 {
+		return 0
+	}
+	return int(r.sent * 100 / r.total)
+}
+
+// InstallFailedError is an install the Android package manager rejected,
+// with its failure code, e.g. INSTALL_FAILED_VERSION_DOWNGRADE, and a hint
+// for the failures an install option or a known fix gets past
+type InstallFailedError struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+func (e *InstallFailedError) Error() string {
+	message := "installation failed: " + e.Code
+	if e.Message != "" {
+		message += ": " + e.Message
+	}
+	if e.Hint != "" {
+		message += " (" + e.Hint + ")"
+	}
+	return message
+}
+
+// installFailureCode matches the reason in package manager output such as
+// "Failure [INSTALL_FAILED_VERSION_DOWNGRADE: Downgrade detected: ...]"
+var installFailureCode = regexp.MustCompile(`\b(INSTALL_(?:PARSE_)?FAILED_[A-Z0-9_]+)(?::\s*([^\]\r\n]*))?`)
+
+// installFailureHints are fixes for common install failures
+var installFailureHints = map[string]string{
+	"INSTALL_FAILED_VERSION_DOWNGRADE":    "a newer version is installed, install with --downgrade to replace it",
+	"INSTALL_FAILED_UPDATE_INCOMPATIBLE":  "the installed app is signed with another key, uninstall it first",
+	"INSTALL_FAILED_OLDER_SDK":            "the device's API level is below the app's minSdkVersion",
+	"INSTALL_FAILED_NO_MATCHING_ABIS":     "the app has no native libraries for the device's ABIs",
+	"INSTALL_FAILED_TEST_ONLY":            "the app is test-only, install with --test",
+	"INSTALL_FAILED_INSUFFICIENT_STORAGE": "the device is out of storage",
+	"INSTALL_FAILED_USER_RESTRICTED":      "installing apps is restricted for this user",
+}
+
+// parseInstallFailure returns the failure reported in package manager
+// output, or nil if it reports none
+func parseInstallFailure(output string) *InstallFailedError {
+	matches := installFailureCode.FindStringSubmatch(output)
+	if matches == nil {
+		return nil
+	}
+
+	return &InstallFailedError{
+		Code:    matches[1],
+		Message: strings.TrimSpace(matches[2]),
+		Hint:    installFailureHints[matches[1]],
+	}
+}
+
+// installResult checks the output of an Android install, returning an
+// InstallFailedError when the package manager names why it failed
+func installResult(output []byte, err error) error {
+	if failure := parseInstallFailure(string(output)); failure != nil {
+		return failure
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to install app: %v\nOutput: %s", err, string(output))
+	}
+
+	if !strings.Contains(string(output), "Success") {
+		return fmt.Errorf("installation failed: %s", string(output))
+	}
+
+	return nil
+}
+
+// installWithPhases runs an install that can't report progress of its own
+// between the installing and completed phases
+func installWithPhases(config InstallConfig, install func() error) error {
+	if err := config.cancelled(); err != nil {
+		return err
+	}
+
+	config.progress(InstallProgress{Phase: InstallPhaseInstalling})
+	if err := install(); err != nil {
+		return err
+	}
+	config.progress(InstallProgress{Phase: InstallPhaseCompleted, Percent: 100})
+	return nil
+}
+
+// InstallAppWithProgress installs with 'adb install' unless progress is
+// asked for, in which case the apk is streamed into the package manager
+// itself so the bytes pushed can be reported. Devices whose package manager
+// can't take a streamed install fall back to 'adb install'. Split apps from
+// an .apks or .aab only report phases.
+func (d *AndroidDevice) InstallAppWithProgress(path string, config InstallConfig) error {
+	if config.OnProgress == nil {
+		return installWithPhases(config, func() error { return d.installAppFile(path, config) })
+	}
+
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".apks" || ext == ".aab" {
+		return installWithPhases(config, func() error { return d.installAppFile(path, config) })
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open app: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat app: %w", err)
+	}
+
+	utils.Verbose("streaming %s into the package manager to report install progress", path)
+	reader := &installProgressReader{reader: file, total: info.Size(), config: config}
+	config.progress(InstallProgress{Phase: InstallPhaseUploading, TotalBytes: info.Size()})
+
+	args := append([]string{"-s", d.getAdbIdentifier(), "exec-in", "cmd", "package", "install"}, config.installFlags()...)
+	cmd := adbCommand(append(args, "-S", strconv.FormatInt(info.Size(), 10))...)
+	cmd.Stdin = reader
+	output, err := utils.CombinedOutput(utils.WithTimeout(cmd, longCommandTimeout))
+
+	if err := config.cancelled(); err != nil {
+		return err
+	}
+
+	if reader.sent == 0 {
+		utils.Verbose("streamed install not available, falling back to adb install: %v %s", err, strings.TrimSpace(string(output)))
+		return installWithPhases(config, func() error { return d.adbInstall([]string{path}, config) })
+	}
+
+	if err := installResult(output, err); err != nil {
+		return err
+	}
+
+	config.progress(InstallProgress{Phase: InstallPhaseCompleted, Percent: 100})
+	return nil
+}
+
+// zipconduitLogMu serializes installs that capture zipconduit's progress,
+// which it only reports through the global logger
+var zipconduitLogMu sync.Mutex
+
+// zipconduitProgressHook turns zipconduit's "installing" log entries into
+// install progress
+type zipconduitProgressHook struct {
+	config InstallConfig
+}
+
+func (h zipconduitProgressHook) Levels() []log.Level {
+	return []log.Level{log.InfoLevel}
+}
+
+func (h zipconduitProgressHook) Fire(entry *log.Entry) error {
+	if percent, ok := entry.Data["percentComplete"].(int); ok {
+		h.config.progress(InstallProgress{Phase: InstallPhaseInstalling, Percent: percent})
+	}
+	return nil
+}
+
+// InstallAppWithProgress installs like InstallApp, reporting the device's
+// own install percentage once the app has been sent
+func (d *IOSDevice) InstallAppWithProgress(path string, config InstallConfig) error {
+	// ensure tunnel is running for iOS 17+
+	err := d.startTunnel()
+	if err != nil {
+		return fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	svc, err := zipconduit.New(device)
+	if err != nil {
+		return fmt.Errorf("zipconduit failed: %w", err)
+	}
+	defer func() { _ = svc.Close() }()
+
+	var total int64
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		total = info.Size()
+	}
+	config.progress(InstallProgress{Phase: InstallPhaseUploading, TotalBytes: total})
+
+	if config.OnProgress == nil {
+		log.SetLevel(log.WarnLevel)
+	} else {
+		// capture zipconduit's info-level progress without printing it, and
+		// put the logger back as it was afterwards
+		zipconduitLogMu.Lock()
+		logger := log.StandardLogger()
+		previousHooks := logger.ReplaceHooks(log.LevelHooks{})
+		previousOut := logger.Out
+		previousLevel := logger.GetLevel()
+		logger.AddHook(zipconduitProgressHook{config: config})
+		logger.SetOutput(io.Discard)
+		logger.SetLevel(log.InfoLevel)
+		defer func() {
+			logger.SetLevel(previousLevel)
+			logger.SetOutput(previousOut)
+			logger.ReplaceHooks(previousHooks)
+			zipconduitLogMu.Unlock()
+		}()
+	}
+
+	if err := config.cancelled(); err != nil {
+		return err
+	}
+
+	err = svc.SendFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to install app: %w", err)
+	}
+
+	config.progress(InstallProgress{Phase: InstallPhaseCompleted, Percent: 100})
+	return nil
+}
+
+// InstallAppWithProgress installs like InstallApp; simctl reports no
+// progress, so only the phases are reported
+func (s SimulatorDevice) InstallAppWithProgress(path string, config InstallConfig) error {
+	return installWithPhases(config, func() error { return s.InstallApp(path) })
+}
@@ -0,0 +1,55 @@
+package devices
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallProgressReader(t *testing.T) {
+	var reports []InstallProgress
+	reader := &installProgressReader{
+		reader: bytes.NewReader(make([]byte, 1000)),
+		total:  1000,
+		config: InstallConfig{OnProgress: func(p InstallProgress) { reports = append(reports, p) }},
+	}
+
+	n, err := io.Copy(io.Discard, reader)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), n)
+
+	require.GreaterOrEqual(t, len(reports), 2)
+	assert.Equal(t, InstallProgress{Phase: InstallPhaseUploading, BytesSent: 1000, TotalBytes: 1000, Percent: 100}, reports[len(reports)-2])
+	assert.Equal(t, InstallProgress{Phase: InstallPhaseInstalling}, reports[len(reports)-1])
+}
+
+func TestInstallWithPhases(t *testing.T) {
+	var phases []string
+	config := InstallConfig{OnProgress: func(p InstallProgress) { phases = append(phases, p.Phase) }}
+
+	require.NoError(t, installWithPhases(config, func() error { return nil }))
+	assert.Equal(t, []string{InstallPhaseInstalling, InstallPhaseCompleted}, phases)
+
+	phases = nil
+	assert.Error(t, installWithPhases(config, func() error { return io.ErrUnexpectedEOF }))
+	assert.Equal(t, []string{InstallPhaseInstalling}, phases)
+}
+
+func TestInstallCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	config := InstallConfig{Context: ctx}
+
+	reader := &installProgressReader{reader: bytes.NewReader(make([]byte, 1000)), total: 1000, config: config}
+	_, err := io.Copy(io.Discard, reader)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	installed := false
+	err = installWithPhases(config, func() error { installed = true; return nil })
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, installed)
+}
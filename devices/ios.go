@@ -22,7 +22,6 @@ import (
 	"github.com/danielpaulus/go-ios/ios/instruments"
 	"github.com/danielpaulus/go-ios/ios/testmanagerd"
 	"github.com/danielpaulus/go-ios/ios/tunnel"
-	"github.com/danielpaulus/go-ios/ios/zipconduit"
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/mobile-next/mobilecli/devices/ios"
 	"github.com/mobile-next/mobilecli/devices/wda"
@@ -72,6 +71,8 @@ type IOSDevice struct {
 	OSVersion   string `json:"Version"`
 	ProductType string `json:"ProductType"`
 
+	transport              string     // TransportUSB or TransportNetwork
+	usbPath                string     // USB port path, when plugged in over USB
 	mu                     sync.Mutex // protects fields below
 	tunnelManager          *ios.TunnelManager
 	wdaClient              *wda.WdaClient
@@ -81,6 +82,7 @@ type IOSDevice struct {
 	portForwarderMjpeg     *ios.PortForwarder
 	portForwarderDeviceKit *ios.PortForwarder // devicekit http forwarder
 	portForwarderAvc       *ios.PortForwarder // devicekit h264 stream forwarder
+	reverses               map[int]*hostRelay // by device port
 }
 
 func (d IOSDevice) ID() string {
@@ -107,6 +109,17 @@ func (d IOSDevice) State() string {
 	return "online"
 }
 
+// Transport returns how the device is connected to the host, usb or network (Wi-Fi)
+func (d *IOSDevice) Transport() string {
+	return d.transport
+}
+
+// USBPath returns the USB port path the device is plugged into, on macOS, or
+// "" when it isn't known
+func (d *IOSDevice) USBPath() string {
+	return d.usbPath
+}
+
 func getDeviceInfo(deviceEntry goios.DeviceEntry) (IOSDevice, error) {
 	log.SetLevel(log.WarnLevel)
 
@@ -143,7 +156,13 @@ func getDeviceInfo(deviceEntry goios.DeviceEntry) (IOSDevice, error) {
 		DeviceName:  deviceName,
 		OSVersion:   osVersion,
 		ProductType: productType,
+		transport:   TransportUSB,
+	}
+
+	if ios.IsNetworkEntry(deviceEntry) {
+		device.transport = TransportNetwork
 	}
+	device.usbPath = ios.USBPath(deviceEntry)
 
 	tunnelManager, err := ios.NewTunnelManager(udid)
 	if err != nil {
@@ -164,8 +183,11 @@ func ListIOSDevices() ([]IOSDevice, error) {
 		return []IOSDevice{}, fmt.Errorf("failed getting device list: %w", err)
 	}
 
-	devices := make([]IOSDevice, len(deviceList.DeviceList))
-	for i, deviceEntry := range deviceList.DeviceList {
+	// devices paired over Wi-Fi and also plugged in are listed twice by usbmuxd
+	entries := ios.PreferredEntries(deviceList.DeviceList)
+
+	devices := make([]IOSDevice, len(entries))
+	for i, deviceEntry := range entries {
 		device, err := getDeviceInfo(deviceEntry)
 		if err != nil {
 			return []IOSDevice{}, fmt.Errorf("failed to get device info: %w", err)
@@ -203,7 +225,7 @@ func (d IOSDevice) Reboot() error {
 	return nil
 }
 
-func (d IOSDevice) Boot() error {
+func (d IOSDevice) Boot(config BootConfig) error {
 	return fmt.Errorf("boot is not supported for real iOS devices")
 }
 
@@ -219,8 +241,8 @@ func (d IOSDevice) LongPress(x, y, duration int) error {
 	return d.wdaClient.LongPress(x, y, duration)
 }
 
-func (d IOSDevice) Swipe(x1, y1, x2, y2 int) error {
-	return d.wdaClient.Swipe(x1, y1, x2, y2)
+func (d IOSDevice) Swipe(x1, y1, x2, y2, duration int) error {
+	return d.wdaClient.Swipe(x1, y1, x2, y2, duration)
 }
 
 func (d IOSDevice) Gesture(actions []wda.TapAction) error {
@@ -297,6 +319,10 @@ func (d *IOSDevice) Cleanup() error {
 		errs = append(errs, err)
 	}
 
+	if err := d.cleanupReverses(); err != nil {
+		errs = append(errs, err)
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("device cleanup failed with %d error(s): %v", len(errs), errs)
 	}
@@ -315,8 +341,9 @@ func (d *IOSDevice) hasResourcesToCleanup() bool {
 	hasHTTPPort := d.portForwarderDeviceKit != nil && d.portForwarderDeviceKit.IsRunning()
 	hasStreamPort := d.portForwarderAvc != nil && d.portForwarderAvc.IsRunning()
 	hasTunnel := d.tunnelManager != nil && d.tunnelManager.IsTunnelRunning()
+	hasReverses := len(d.reverses) > 0
 
-	return hasWda || hasWdaPort || hasMjpegPort || hasHTTPPort || hasStreamPort || hasTunnel
+	return hasWda || hasWdaPort || hasMjpegPort || hasHTTPPort || hasStreamPort || hasTunnel || hasReverses
 }
 
 // cleanupWDA cancels the WebDriverAgent context
@@ -350,6 +377,7 @@ func (d *IOSDevice) cleanupPortForwarders() error {
 		if err := wdaForwarder.Stop(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to stop WDA port forwarder: %w", err))
 		}
+		wda.Pool.Remove(d.Udid)
 	}
 
 	if mjpegForwarder != nil && mjpegForwarder.IsRunning() {
@@ -488,10 +516,12 @@ func (d *IOSDevice) StartAgent(config StartAgentConfig) error {
 		// check if agent is installed. the runner bundle id can carry a signing/team
 		// prefix when re-signed, so match on suffix rather than exact equality.
 		agentBundleId := ""
+		agentVersion := ""
 		for _, app := range apps {
 			if strings.HasSuffix(app.PackageName, agentRunnerBundleID) {
 				utils.Verbose("agent is installed, launching it")
 				agentBundleId = app.PackageName
+				agentVersion = app.Version
 				break
 			}
 		}
@@ -500,6 +530,10 @@ func (d *IOSDevice) StartAgent(config StartAgentConfig) error {
 			return fmt.Errorf("agent is not installed, use 'mobilecli agent install --device %s --provisioning-profile <path>' to install it", d.ID())
 		}
 
+		if err := CheckAgentVersion(d, agentVersion); err != nil {
+			return err
+		}
+
 		if config.OnProgress != nil {
 			config.OnProgress("Starting tunnel")
 		}
@@ -516,20 +550,21 @@ func (d *IOSDevice) StartAgent(config StartAgentConfig) error {
 		d.mu.Unlock()
 
 		if needsPortForwarder {
-			port, err := findAvailablePortInRange(portRangeStart, portRangeEnd)
-			if err != nil {
-				return fmt.Errorf("failed to find available port: %w", err)
-			}
-
 			forwarder := ios.NewPortForwarder(d.ID())
-			err = forwarder.Forward(port, deviceKitHTTPPort)
+			port, err := forwardToAvailablePort(forwarder, deviceKitHTTPPort)
 			if err != nil {
 				return fmt.Errorf("failed to forward port: %w", err)
 			}
 
+			// connections through the old forwarder are gone, and a new
+			// client is created without checking the agent
+			wda.Pool.Remove(d.ID())
+			client := wda.Pool.Get(d.ID(), fmt.Sprintf("http://localhost:%d", port))
+
 			d.mu.Lock()
+			stopForwarder(d.portForwarderWda)
 			d.portForwarderWda = forwarder
-			d.wdaClient = wda.NewWdaClient(fmt.Sprintf("http://localhost:%d", port))
+			d.wdaClient = client
 			d.mu.Unlock()
 
 			utils.Verbose("WDA port forwarder set up on port %d", port)
@@ -539,12 +574,19 @@ func (d *IOSDevice) StartAgent(config StartAgentConfig) error {
 			d.mu.Unlock()
 			utils.Verbose("WDA port forwarder already running on port %d", srcPort)
 
-			// ensure wdaClient is set if not already
+			// ensure wdaClient is set if not already, getting it outside the
+			// lock since the pool may check the agent's health
 			d.mu.Lock()
-			if d.wdaClient == nil {
-				d.wdaClient = wda.NewWdaClient(fmt.Sprintf("http://localhost:%d", srcPort))
-			}
+			hasClient := d.wdaClient != nil
 			d.mu.Unlock()
+			if !hasClient {
+				client := wda.Pool.Get(d.ID(), fmt.Sprintf("http://localhost:%d", srcPort))
+				d.mu.Lock()
+				if d.wdaClient == nil {
+					d.wdaClient = client
+				}
+				d.mu.Unlock()
+			}
 		}
 
 		// check if wda is already running, now that we have a port forwarder set up
@@ -582,7 +624,7 @@ func (d *IOSDevice) StartAgent(config StartAgentConfig) error {
 
 				if activeApp.BundleID == agentBundleId {
 					utils.Verbose("agent is active, pressing HOME to background it")
-					_ = d.wdaClient.PressButton("HOME")
+					_ = d.wdaClient.PressButton("home")
 					time.Sleep(1 * time.Second)
 				}
 			}
@@ -653,7 +695,7 @@ func (d *IOSDevice) LaunchTestRunner(bundleID, testRunnerBundleID, xctestConfig
 }
 
 func (d *IOSDevice) PressButton(key string) error {
-	return d.wdaClient.PressButton(key)
+	return pressIOSButton(d.wdaClient, key)
 }
 
 func deviceWithRsdProvider(device goios.DeviceEntry, udid string, address string, rsdPort int) (goios.DeviceEntry, error) {
@@ -684,7 +726,7 @@ func deviceWithRsdProvider(device goios.DeviceEntry, udid string, address string
 func (d IOSDevice) getEnhancedDevice() (goios.DeviceEntry, error) {
 	const userspaceTunnelHost = "localhost"
 
-	device, err := goios.GetDevice(d.Udid)
+	device, err := ios.GetDeviceEntry(d.Udid)
 	if err != nil {
 		return goios.DeviceEntry{}, fmt.Errorf("device not found: %s: %w", d.Udid, err)
 	}
@@ -839,6 +881,149 @@ func (d IOSDevice) TerminateApp(bundleID string) error {
 	return fmt.Errorf("process of %s not found", bundleID)
 }
 
+// GetAppExecutable returns the executable name of an installed app
+func (d *IOSDevice) GetAppExecutable(bundleID string) (string, error) {
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return "", fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	svc, err := installationproxy.New(device)
+	if err != nil {
+		return "", fmt.Errorf("installationproxy failed: %w", err)
+	}
+	defer func() { svc.Close() }()
+
+	apps, err := svc.BrowseAllApps()
+	if err != nil {
+		return "", fmt.Errorf("browsing apps failed: %w", err)
+	}
+
+	for _, app := range apps {
+		if app.CFBundleIdentifier() == bundleID {
+			return app.CFBundleExecutable(), nil
+		}
+	}
+
+	return "", fmt.Errorf("app %s is not installed", bundleID)
+}
+
+// ListRunningApps matches the instruments process list against installed app
+// executables. iOS doesn't expose a cached state, so apps are either the active
+// app (foreground) or background.
+func (d *IOSDevice) ListRunningApps() ([]RunningAppInfo, error) {
+	log.SetLevel(log.WarnLevel)
+
+	// ensure tunnel is running for iOS 17+
+	err := d.startTunnel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	svc, err := installationproxy.New(device)
+	if err != nil {
+		return nil, fmt.Errorf("installationproxy failed: %w", err)
+	}
+	defer func() { svc.Close() }()
+
+	apps, err := svc.BrowseAllApps()
+	if err != nil {
+		return nil, fmt.Errorf("browsing apps failed: %w", err)
+	}
+
+	bundleByExecutable := make(map[string]string, len(apps))
+	for _, app := range apps {
+		bundleByExecutable[app.CFBundleExecutable()] = app.CFBundleIdentifier()
+	}
+
+	service, err := instruments.NewDeviceInfoService(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed opening deviceInfoService for getting process list: %w", err)
+	}
+	defer func() { service.Close() }()
+
+	processList, err := service.ProcessList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get process list: %w", err)
+	}
+
+	activeBundleID := ""
+	if activeApp, err := d.wdaClient.GetActiveAppInfo(); err == nil {
+		activeBundleID = activeApp.BundleID
+	} else {
+		utils.Verbose("failed to get active app, reporting all apps as background: %v", err)
+	}
+
+	processes := make(map[string][]RunningProcess)
+	for _, p := range processList {
+		bundleID, ok := bundleByExecutable[p.Name]
+		if !ok {
+			continue
+		}
+
+		state := ProcessStateBackground
+		if bundleID == activeBundleID {
+			state = ProcessStateForeground
+		}
+
+		processes[bundleID] = append(processes[bundleID], RunningProcess{
+			PID:   int(p.Pid),
+			Name:  p.Name,
+			State: state,
+		})
+	}
+
+	return groupRunningProcesses(processes), nil
+}
+
+// Properties reads lockdown values. The locale lives in the international
+// domain and is left empty if it can't be read.
+func (d *IOSDevice) Properties() (*DeviceProperties, error) {
+	log.SetLevel(log.WarnLevel)
+
+	deviceEntry, err := ios.GetDeviceEntry(d.Udid)
+	if err != nil {
+		return nil, err
+	}
+
+	lockdown, err := goios.ConnectLockdownWithSession(deviceEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to lockdown: %w", err)
+	}
+	defer lockdown.Close()
+
+	allValues, err := lockdown.GetValues()
+	if err != nil {
+		return nil, fmt.Errorf("failed getting values for device %s: %w", d.Udid, err)
+	}
+
+	values := allValues.Value
+	props := &DeviceProperties{
+		Manufacturer:     "Apple",
+		Model:            values.ProductType,
+		ABI:              values.CPUArchitecture,
+		OSVersion:        values.ProductVersion,
+		BuildFingerprint: values.BuildVersion,
+		Serial:           values.SerialNumber,
+		Timezone:         values.TimeZone,
+	}
+
+	if locale, err := lockdown.GetValueForDomain("Locale", "com.apple.international"); err == nil {
+		if localeStr, ok := locale.(string); ok {
+			props.Locale = localeStr
+		}
+	} else {
+		utils.Verbose("failed to read locale: %v", err)
+	}
+
+	return props, nil
+}
+
 func (d IOSDevice) SendKeys(text string) error {
 	return d.wdaClient.SendKeys(text)
 }
@@ -924,6 +1109,39 @@ func (d *IOSDevice) GetForegroundApp() (*ForegroundAppInfo, error) {
 	}, nil
 }
 
+// ScreenState reports whether the device is locked. The agent can't tell a
+// screen that is off from one that is locked, so it is reported as locked.
+func (d *IOSDevice) ScreenState() (string, error) {
+	locked, err := d.wdaClient.IsLocked()
+	if err != nil {
+		return "", err
+	}
+
+	if locked {
+		return ScreenStateLocked, nil
+	}
+	return ScreenStateOn, nil
+}
+
+func (d *IOSDevice) Lock() error {
+	return d.wdaClient.Lock()
+}
+
+func (d *IOSDevice) Unlock() error {
+	return d.wdaClient.Unlock()
+}
+
+// Wake turns the screen on through WebDriverAgent's unlock, iOS having no
+// way to wake the screen without leaving the lock screen
+func (d *IOSDevice) Wake() error {
+	return d.wdaClient.Unlock()
+}
+
+// ScreenOff turns the screen off, which locks the device
+func (d *IOSDevice) ScreenOff() error {
+	return d.wdaClient.Lock()
+}
+
 func (d IOSDevice) Info() (*FullDeviceInfo, error) {
 	wdaSize, err := d.wdaClient.GetWindowSize()
 	if err != nil {
@@ -932,13 +1150,14 @@ func (d IOSDevice) Info() (*FullDeviceInfo, error) {
 
 	return &FullDeviceInfo{
 		DeviceInfo: DeviceInfo{
-			ID:       d.ID(),
-			Name:     d.Name(),
-			Platform: d.Platform(),
-			Type:     d.DeviceType(),
-			Version:  d.Version(),
-			State:    d.State(),
-			Model:    d.ProductType,
+			ID:        d.ID(),
+			Name:      d.Name(),
+			Platform:  d.Platform(),
+			Type:      d.DeviceType(),
+			Version:   d.Version(),
+			State:     d.State(),
+			Model:     d.ProductType,
+			Transport: d.Transport(),
 		},
 		ScreenSize: &ScreenSize{
 			Width:  wdaSize.ScreenSize.Width,
@@ -1053,6 +1272,10 @@ func (d *IOSDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 			_ = conn.Close()
 			utils.Verbose("stream closed by user")
 			return nil
+		case <-config.context().Done():
+			_ = conn.Close()
+			utils.Verbose("stream cancelled")
+			return nil
 		case err := <-done:
 			utils.Verbose("stream ended")
 			return err
@@ -1062,7 +1285,7 @@ func (d *IOSDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 	// mjpeg is served on the same port as the agent HTTP server at /mjpeg
 	d.mu.Lock()
 	wdaPort, _ := d.portForwarderWda.GetPorts()
-	mjpegURL := buildMjpegURL(wdaPort, config.FPS, config.Scale)
+	mjpegURL := buildMjpegURL(wdaPort, config.FPS, config.Quality, config.Scale)
 	d.mjpegClient = mjpeg.NewWdaMjpegClient(mjpegURL)
 	d.mu.Unlock()
 
@@ -1070,43 +1293,25 @@ func (d *IOSDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 		config.OnProgress("Starting video stream")
 	}
 
-	return d.mjpegClient.StartScreenCapture(config.Format, config.OnData)
+	return d.mjpegClient.StartScreenCaptureContext(config.context(), config.Format, config.OnData)
 }
 
 func (d IOSDevice) DumpSource() ([]ScreenElement, error) {
 	return d.wdaClient.GetSourceElements()
 }
 
+// DumpSourceWithLimits dumps the UI tree with the agent's snapshots bounded
+// to limits.MaxDepth, which keeps deep webviews from timing out
+func (d *IOSDevice) DumpSourceWithLimits(limits DumpLimits) ([]ScreenElement, error) {
+	return d.wdaClient.GetSourceElementsWithDepth(limits.MaxDepth)
+}
+
 func (d IOSDevice) DumpSourceRaw() (any, error) {
 	return d.wdaClient.GetSourceRaw()
 }
 
 func (d IOSDevice) InstallApp(path string) error {
-	log.SetLevel(log.WarnLevel)
-
-	// ensure tunnel is running for iOS 17+
-	err := d.startTunnel()
-	if err != nil {
-		return fmt.Errorf("failed to start tunnel: %w", err)
-	}
-
-	device, err := d.getEnhancedDevice()
-	if err != nil {
-		return fmt.Errorf("failed to get enhanced device connection: %w", err)
-	}
-
-	svc, err := zipconduit.New(device)
-	if err != nil {
-		return fmt.Errorf("zipconduit failed: %w", err)
-	}
-	defer func() { _ = svc.Close() }()
-
-	err = svc.SendFile(path)
-	if err != nil {
-		return fmt.Errorf("failed to install app: %w", err)
-	}
-
-	return nil
+	return d.InstallAppWithProgress(path, InstallConfig{})
 }
 
 func (d IOSDevice) UninstallApp(packageName string) (*InstalledAppInfo, error) {
@@ -1299,13 +1504,8 @@ func (d *IOSDevice) ensureDeviceKitPortForwarders() (*DeviceKitInfo, error) {
 	d.mu.Unlock()
 
 	if !hasHTTPForwarder {
-		httpPort, err = findAvailablePortInRange(portRangeStart, portRangeEnd)
-		if err != nil {
-			return nil, fmt.Errorf("failed to find available port for HTTP: %w", err)
-		}
-
 		forwarder := ios.NewPortForwarder(d.ID())
-		err = forwarder.Forward(httpPort, deviceKitHTTPPort)
+		httpPort, err = forwardToAvailablePort(forwarder, deviceKitHTTPPort)
 		if err != nil {
 			return nil, fmt.Errorf("failed to forward HTTP port: %w", err)
 		}
@@ -1326,19 +1526,11 @@ func (d *IOSDevice) ensureDeviceKitPortForwarders() (*DeviceKitInfo, error) {
 	d.mu.Unlock()
 
 	if !hasStreamForwarder {
-		streamPort, err = findAvailablePortInRange(portRangeStart, portRangeEnd)
-		if err != nil {
-			if !hasHTTPForwarder {
-				_ = d.portForwarderDeviceKit.Stop()
-			}
-			return nil, fmt.Errorf("failed to find available port for stream: %w", err)
-		}
-
 		d.mu.Lock()
 		d.portForwarderAvc = ios.NewPortForwarder(d.ID())
 		d.mu.Unlock()
 
-		err = d.portForwarderAvc.Forward(streamPort, deviceKitStreamPort)
+		streamPort, err = forwardToAvailablePort(d.portForwarderAvc, deviceKitStreamPort)
 		if err != nil {
 			if !hasHTTPForwarder {
 				_ = d.portForwarderDeviceKit.Stop()
@@ -1456,33 +1648,24 @@ func (d *IOSDevice) StartDeviceKit(hook *ShutdownHook) (*DeviceKitInfo, error) {
 		return nil, fmt.Errorf("DeviceKit main app not found. Please install devicekit-ios on the device")
 	}
 
-	// Find available local port for HTTP forwarding and bind immediately.
-	localHTTPPort, err := findAvailablePortInRange(portRangeStart, portRangeEnd)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find available port for HTTP: %w", err)
-	}
-
+	// a half-started previous session may have left one forwarder running
 	d.mu.Lock()
+	stopForwarder(d.portForwarderDeviceKit)
+	stopForwarder(d.portForwarderAvc)
 	d.portForwarderDeviceKit = ios.NewPortForwarder(d.ID())
 	d.mu.Unlock()
 
-	err = d.portForwarderDeviceKit.Forward(localHTTPPort, deviceKitHTTPPort)
+	localHTTPPort, err := forwardToAvailablePort(d.portForwarderDeviceKit, deviceKitHTTPPort)
 	if err != nil {
 		return nil, fmt.Errorf("failed to forward HTTP port: %w", err)
 	}
 	utils.Verbose("Port forwarding started: localhost:%d -> device:%d (HTTP)", localHTTPPort, deviceKitHTTPPort)
-	// Find available local port for stream forwarding after HTTP is bound.
-	localStreamPort, err := findAvailablePortInRange(portRangeStart, portRangeEnd)
-	if err != nil {
-		_ = d.portForwarderDeviceKit.Stop()
-		return nil, fmt.Errorf("failed to find available port for stream: %w", err)
-	}
 
 	d.mu.Lock()
 	d.portForwarderAvc = ios.NewPortForwarder(d.ID())
 	d.mu.Unlock()
 
-	err = d.portForwarderAvc.Forward(localStreamPort, deviceKitStreamPort)
+	localStreamPort, err := forwardToAvailablePort(d.portForwarderAvc, deviceKitStreamPort)
 	if err != nil {
 		// clean up HTTP forwarder on failure
 		_ = d.portForwarderDeviceKit.Stop()
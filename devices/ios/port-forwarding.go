@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"sync"
 
-	goios "github.com/danielpaulus/go-ios/ios"
 	"github.com/danielpaulus/go-ios/ios/forward"
 	"github.com/mobile-next/mobilecli/utils"
 )
@@ -42,7 +41,7 @@ func (pf *PortForwarder) Forward(srcPort, dstPort int) error {
 	pf.srcPort = srcPort
 	pf.dstPort = dstPort
 
-	device, err := goios.GetDevice(pf.udid)
+	device, err := GetDeviceEntry(pf.udid)
 	if err != nil {
 		return fmt.Errorf("failed to get device %s: %w", pf.udid, err)
 	}
@@ -53,7 +52,7 @@ func (pf *PortForwarder) Forward(srcPort, dstPort int) error {
 	}
 
 	pf.connListener = connListener
-	utils.Verbose("Port forwarding started from %d to %d", srcPort, dstPort)
+	utils.Debug(utils.SubsystemTunnel, "Port forwarding started from %d to %d", srcPort, dstPort)
 
 	return nil
 }
@@ -68,10 +67,10 @@ func (pf *PortForwarder) Stop() error {
 
 	err := pf.connListener.Close()
 	if err != nil {
-		utils.Verbose("Error stopping port forwarding %d->%d: %v", pf.srcPort, pf.dstPort, err)
+		utils.Debug(utils.SubsystemTunnel, "Error stopping port forwarding %d->%d: %v", pf.srcPort, pf.dstPort, err)
 	}
 
-	utils.Verbose("Stopping port forwarding %d->%d", pf.srcPort, pf.dstPort)
+	utils.Debug(utils.SubsystemTunnel, "Stopping port forwarding %d->%d", pf.srcPort, pf.dstPort)
 	pf.connListener = nil
 	pf.srcPort = 0
 	pf.dstPort = 0
@@ -0,0 +1,91 @@
+package ios
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	goios "github.com/danielpaulus/go-ios/ios"
+)
+
+const (
+	// usbmuxd reports the connection type of each device entry as one of these
+	connectionTypeUSB     = "USB"
+	connectionTypeNetwork = "Network"
+)
+
+// IsNetworkEntry returns true if usbmuxd reaches this device entry over Wi-Fi
+func IsNetworkEntry(entry goios.DeviceEntry) bool {
+	return entry.Properties.ConnectionType == connectionTypeNetwork
+}
+
+// USBPath returns the USB port path of a device entry plugged in over USB,
+// or "" when it isn't known
+func USBPath(entry goios.DeviceEntry) string {
+	if IsNetworkEntry(entry) {
+		return ""
+	}
+	return usbPathFromLocationID(runtime.GOOS, entry.Properties.LocationID)
+}
+
+// usbPathFromLocationID formats a macOS USB location ID as "<bus>-<port>.<port>",
+// the way Linux names USB ports. The top byte of a location ID is the bus and
+// each following nibble the port on the next hub, down to the first 0. Other
+// platforms' usbmuxd report a bus and device address instead, which isn't a
+// port path.
+func usbPathFromLocationID(goos string, locationID int) string {
+	if goos != "darwin" || locationID == 0 {
+		return ""
+	}
+
+	var ports []string
+	for shift := 20; shift >= 0; shift -= 4 {
+		port := (locationID >> shift) & 0xf
+		if port == 0 {
+			break
+		}
+		ports = append(ports, strconv.Itoa(port))
+	}
+	return fmt.Sprintf("%d-%s", (locationID>>24)&0xff, strings.Join(ports, "."))
+}
+
+// PreferredEntries collapses the usbmuxd device list to a single entry per udid.
+// A device that is both plugged in and paired over Wi-Fi shows up twice; the USB
+// entry is preferred since it is faster and more reliable.
+func PreferredEntries(entries []goios.DeviceEntry) []goios.DeviceEntry {
+	result := make([]goios.DeviceEntry, 0, len(entries))
+	indexByUdid := make(map[string]int)
+
+	for _, entry := range entries {
+		udid := entry.Properties.SerialNumber
+		i, seen := indexByUdid[udid]
+		if !seen {
+			indexByUdid[udid] = len(result)
+			result = append(result, entry)
+			continue
+		}
+
+		if IsNetworkEntry(result[i]) && entry.Properties.ConnectionType == connectionTypeUSB {
+			result[i] = entry
+		}
+	}
+
+	return result
+}
+
+// GetDeviceEntry returns the usbmuxd entry for udid, preferring USB over network
+func GetDeviceEntry(udid string) (goios.DeviceEntry, error) {
+	deviceList, err := goios.ListDevices()
+	if err != nil {
+		return goios.DeviceEntry{}, fmt.Errorf("failed getting device list: %w", err)
+	}
+
+	for _, entry := range PreferredEntries(deviceList.DeviceList) {
+		if entry.Properties.SerialNumber == udid {
+			return entry, nil
+		}
+	}
+
+	return goios.DeviceEntry{}, fmt.Errorf("device %s is not attached to this host", udid)
+}
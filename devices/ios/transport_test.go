@@ -0,0 +1,49 @@
+package ios
+
+import (
+	"testing"
+
+	goios "github.com/danielpaulus/go-ios/ios"
+	"github.com/stretchr/testify/assert"
+)
+
+func entry(udid, connectionType string, deviceID int) goios.DeviceEntry {
+	return goios.DeviceEntry{
+		DeviceID: deviceID,
+		Properties: goios.DeviceProperties{
+			SerialNumber:   udid,
+			ConnectionType: connectionType,
+		},
+	}
+}
+
+func TestPreferredEntries_PrefersUSB(t *testing.T) {
+	entries := PreferredEntries([]goios.DeviceEntry{
+		entry("aaa", "Network", 1),
+		entry("bbb", "Network", 2),
+		entry("aaa", "USB", 3),
+	})
+
+	assert.Len(t, entries, 2)
+	assert.Equal(t, "aaa", entries[0].Properties.SerialNumber)
+	assert.Equal(t, 3, entries[0].DeviceID)
+	assert.False(t, IsNetworkEntry(entries[0]))
+	assert.True(t, IsNetworkEntry(entries[1]))
+}
+
+func TestPreferredEntries_KeepsFirstUSB(t *testing.T) {
+	entries := PreferredEntries([]goios.DeviceEntry{
+		entry("aaa", "USB", 1),
+		entry("aaa", "Network", 2),
+	})
+
+	assert.Len(t, entries, 1)
+	assert.Equal(t, 1, entries[0].DeviceID)
+}
+
+func TestUSBPathFromLocationID(t *testing.T) {
+	assert.Equal(t, "20-1", usbPathFromLocationID("darwin", 0x14100000))
+	assert.Equal(t, "1-1.4.2", usbPathFromLocationID("darwin", 0x01142000))
+	assert.Equal(t, "", usbPathFromLocationID("darwin", 0))
+	assert.Equal(t, "", usbPathFromLocationID("linux", 0x14100000))
+}
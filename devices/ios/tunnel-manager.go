@@ -85,7 +85,7 @@ func (tm *TunnelManager) StartTunnelWithCallback(onProcessDied func(error)) erro
 			return
 		}
 
-		utils.Verbose("Tunnel manager started for device %s", tm.udid)
+		utils.Debug(utils.SubsystemTunnel, "Tunnel manager started for device %s", tm.udid)
 
 		// Keep updating tunnels periodically to handle device connects/disconnects
 		ticker := time.NewTicker(5 * time.Second)
@@ -129,10 +129,10 @@ func (tm *TunnelManager) StopTunnel() error {
 	// Close the tunnel manager to clean up all tunnels
 	err := tm.tunnelMgr.Close()
 	if err != nil {
-		utils.Verbose("Error closing tunnel manager: %v", err)
+		utils.Debug(utils.SubsystemTunnel, "Error closing tunnel manager: %v", err)
 	}
 
-	utils.Verbose("Stopping tunnel manager for device %s", tm.udid)
+	utils.Debug(utils.SubsystemTunnel, "Stopping tunnel manager for device %s", tm.udid)
 	tm.updateCtx = nil
 	tm.tunnelCancel = nil
 
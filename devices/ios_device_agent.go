@@ -237,7 +237,7 @@ func injectServerViaLLDB(localProxyPort int) (int, error) {
 		"-o", "detach",
 		"-o", "quit",
 	)
-	out, err := cmd.CombinedOutput()
+	out, err := utils.CombinedOutput(cmd)
 	utils.Verbose("LLDB finished (err=%v), output:\n%s", err, out)
 	if err != nil {
 		return 0, fmt.Errorf("lldb: %w\noutput:\n%s", err, out)
@@ -0,0 +1,153 @@
+package devices
+
+import (
+	"fmt"
+
+	goios "github.com/danielpaulus/go-ios/ios"
+	"github.com/danielpaulus/go-ios/ios/diagnostics"
+	"github.com/danielpaulus/go-ios/ios/mcinstall"
+	"github.com/mobile-next/mobilecli/devices/ios"
+	"github.com/mobile-next/mobilecli/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// diskUsageDomain is the lockdown domain reporting the device's storage
+const diskUsageDomain = "com.apple.disk_usage"
+
+// Diagnostics reports the activation, supervision and configuration profile
+// state of the device, its free space and battery health. Each part is best
+// effort, a part that can't be read is left out.
+func (d *IOSDevice) Diagnostics() (*DeviceDiagnostics, error) {
+	log.SetLevel(log.WarnLevel)
+
+	deviceEntry, err := ios.GetDeviceEntry(d.Udid)
+	if err != nil {
+		return nil, err
+	}
+
+	lockdown, err := goios.ConnectLockdownWithSession(deviceEntry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to lockdown: %w", err)
+	}
+	defer lockdown.Close()
+
+	result := &DeviceDiagnostics{}
+
+	if state, err := lockdown.GetValue("ActivationState"); err == nil {
+		result.ActivationState, _ = state.(string)
+	} else {
+		utils.Verbose("failed to read activation state: %v", err)
+	}
+
+	total, totalErr := lockdown.GetValueForDomain("TotalDataCapacity", diskUsageDomain)
+	free, freeErr := lockdown.GetValueForDomain("TotalDataAvailable", diskUsageDomain)
+	if totalErr == nil && freeErr == nil {
+		result.Storage = diskUsage(total, free)
+	} else {
+		utils.Verbose("failed to read disk usage: %v %v", totalErr, freeErr)
+	}
+
+	if conn, err := mcinstall.New(deviceEntry); err == nil {
+		if config, err := conn.GetCloudConfiguration(); err == nil {
+			result.Supervised, result.Organization = parseCloudConfiguration(config)
+		} else {
+			utils.Verbose("failed to read cloud configuration: %v", err)
+		}
+		if profiles, err := conn.HandleList(); err == nil {
+			result.Profiles = configurationProfiles(profiles)
+		} else {
+			utils.Verbose("failed to list configuration profiles: %v", err)
+		}
+		_ = conn.Close()
+	} else {
+		utils.Verbose("failed to connect to the profile service: %v", err)
+	}
+
+	if battery, err := d.readBatteryRegistry(); err == nil {
+		result.Battery = batteryHealth(battery)
+	} else {
+		utils.Verbose("failed to read battery health: %v", err)
+	}
+
+	return result, nil
+}
+
+// readBatteryRegistry reads the battery's IORegistry entry
+func (d *IOSDevice) readBatteryRegistry() (diagnostics.IORegistry, error) {
+	if err := d.startTunnel(); err != nil {
+		return diagnostics.IORegistry{}, fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return diagnostics.IORegistry{}, fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	conn, err := diagnostics.New(device)
+	if err != nil {
+		return diagnostics.IORegistry{}, fmt.Errorf("failed to connect to diagnostics: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	return conn.Battery()
+}
+
+// diskUsage builds the storage of the data volume from lockdown's byte counts
+func diskUsage(total, free any) *StorageVolume {
+	totalBytes, ok := total.(uint64)
+	if !ok {
+		return nil
+	}
+	freeBytes, ok := free.(uint64)
+	if !ok {
+		return nil
+	}
+
+	return &StorageVolume{
+		Path:       "/private/var",
+		TotalBytes: int64(totalBytes),
+		UsedBytes:  int64(totalBytes - min(freeBytes, totalBytes)),
+		FreeBytes:  int64(freeBytes),
+	}
+}
+
+// parseCloudConfiguration returns whether a device is supervised, and the
+// organization supervising it, from its MCInstall cloud configuration
+func parseCloudConfiguration(config map[string]any) (bool, string) {
+	supervised, _ := config["IsSupervised"].(bool)
+	organization, _ := config["OrganizationName"].(string)
+	return supervised, organization
+}
+
+// configurationProfiles lists the installed configuration profiles
+func configurationProfiles(profiles []mcinstall.ProfileInfo) []ConfigurationProfile {
+	result := make([]ConfigurationProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		result = append(result, ConfigurationProfile{
+			Identifier: profile.Identifier,
+			Name:       profile.Metadata.PayloadDisplayName,
+			Active:     profile.Manifest.IsActive,
+			Removable:  !profile.Metadata.PayloadRemovalDisallowed,
+		})
+	}
+	return result
+}
+
+// batteryHealth derives the battery's health, its full charge capacity as a
+// percentage of its design capacity, from the battery's IORegistry entry
+func batteryHealth(registry diagnostics.IORegistry) *BatteryHealth {
+	health := &BatteryHealth{
+		Level:      registry.CurrentCapacity,
+		Charging:   registry.IsCharging,
+		CycleCount: int(registry.CycleCount),
+	}
+
+	maxCapacity := registry.NominalChargeCapacity
+	if maxCapacity == 0 {
+		maxCapacity = registry.AppleRawMaxCapacity
+	}
+	if registry.DesignCapacity > 0 && maxCapacity > 0 {
+		health.MaxCapacityPercent = int(maxCapacity * 100 / registry.DesignCapacity)
+	}
+	return health
+}
@@ -0,0 +1,53 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/danielpaulus/go-ios/ios/diagnostics"
+	"github.com/danielpaulus/go-ios/ios/mcinstall"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCloudConfiguration(t *testing.T) {
+	supervised, organization := parseCloudConfiguration(map[string]any{
+		"IsSupervised":     true,
+		"OrganizationName": "Example Labs",
+	})
+	assert.True(t, supervised)
+	assert.Equal(t, "Example Labs", organization)
+
+	supervised, organization = parseCloudConfiguration(map[string]any{})
+	assert.False(t, supervised)
+	assert.Equal(t, "", organization)
+}
+
+func TestConfigurationProfiles(t *testing.T) {
+	profile := mcinstall.ProfileInfo{Identifier: "com.example.mdm"}
+	profile.Manifest.IsActive = true
+	profile.Metadata.PayloadDisplayName = "Example MDM"
+	profile.Metadata.PayloadRemovalDisallowed = true
+
+	assert.Equal(t, []ConfigurationProfile{
+		{Identifier: "com.example.mdm", Name: "Example MDM", Active: true, Removable: false},
+	}, configurationProfiles([]mcinstall.ProfileInfo{profile}))
+}
+
+func TestBatteryHealth(t *testing.T) {
+	health := batteryHealth(diagnostics.IORegistry{
+		CurrentCapacity:       76,
+		IsCharging:            true,
+		CycleCount:            412,
+		DesignCapacity:        3200,
+		NominalChargeCapacity: 2752,
+	})
+	assert.Equal(t, &BatteryHealth{Level: 76, Charging: true, CycleCount: 412, MaxCapacityPercent: 86}, health)
+
+	// without a design capacity the health isn't known
+	health = batteryHealth(diagnostics.IORegistry{CurrentCapacity: 50})
+	assert.Equal(t, 0, health.MaxCapacityPercent)
+}
+
+func TestDiskUsage(t *testing.T) {
+	assert.Equal(t, &StorageVolume{Path: "/private/var", TotalBytes: 128, UsedBytes: 96, FreeBytes: 32}, diskUsage(uint64(128), uint64(32)))
+	assert.Nil(t, diskUsage("128", uint64(32)))
+}
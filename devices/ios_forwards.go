@@ -0,0 +1,75 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices/ios"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// forwardAttempts bounds how many free-looking local ports are tried when the
+// chosen one is taken between the check and the bind, by another device's
+// forwarder or another process
+const forwardAttempts = 5
+
+// forwardToAvailablePort starts forwarder from the first local port in range
+// that can actually be bound, returning that port
+func forwardToAvailablePort(forwarder *ios.PortForwarder, devicePort int) (int, error) {
+	var lastErr error
+	next := portRangeStart
+	for attempt := 0; attempt < forwardAttempts; attempt++ {
+		port, err := findAvailablePortInRange(next, portRangeEnd)
+		if err != nil {
+			return 0, err
+		}
+
+		if lastErr = forwarder.Forward(port, devicePort); lastErr == nil {
+			return port, nil
+		}
+
+		utils.Verbose("Failed to forward local port %d, trying next: %v", port, lastErr)
+		next = port + 1
+	}
+
+	return 0, fmt.Errorf("failed to forward to device port %d after %d attempts: %w", devicePort, forwardAttempts, lastErr)
+}
+
+// stopForwarder stops forwarder if it is running
+func stopForwarder(forwarder *ios.PortForwarder) {
+	if forwarder != nil && forwarder.IsRunning() {
+		_ = forwarder.Stop()
+	}
+}
+
+// PortForwards lists the port forwarders this process holds open for the device
+func (d *IOSDevice) PortForwards() []PortForward {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	forwarders := []struct {
+		name      string
+		forwarder *ios.PortForwarder
+	}{
+		{"agent", d.portForwarderWda},
+		{"mjpeg", d.portForwarderMjpeg},
+		{"devicekit", d.portForwarderDeviceKit},
+		{"avc", d.portForwarderAvc},
+	}
+
+	forwards := []PortForward{}
+	for _, f := range forwarders {
+		if f.forwarder == nil || !f.forwarder.IsRunning() {
+			continue
+		}
+
+		localPort, devicePort := f.forwarder.GetPorts()
+		forwards = append(forwards, PortForward{
+			DeviceID:   d.Udid,
+			Name:       f.name,
+			LocalPort:  localPort,
+			DevicePort: devicePort,
+		})
+	}
+
+	return forwards
+}
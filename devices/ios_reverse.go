@@ -0,0 +1,146 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// relayDialTimeout bounds connecting a relayed connection to the host port
+const relayDialTimeout = 5 * time.Second
+
+// hostRelay accepts connections on a port of the host's network interfaces
+// and relays each to a port on the host's localhost. Real iOS devices can't
+// reach the host over USB, so apps reach a server the host only serves on
+// localhost, such as a mock server, at the host's network address instead.
+type hostRelay struct {
+	listener   net.Listener
+	devicePort int
+	hostPort   int
+}
+
+// startHostRelay relays listenPort on every interface to hostPort on localhost
+func startHostRelay(listenPort, hostPort int) (*hostRelay, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", listenPort))
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on port %d: %w", listenPort, err)
+	}
+
+	relay := &hostRelay{listener: listener, devicePort: listenPort, hostPort: hostPort}
+	go relay.serve()
+	return relay, nil
+}
+
+func (r *hostRelay) serve() {
+	for {
+		conn, err := r.listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			utils.Verbose("Relay of port %d failed to accept: %v", r.devicePort, err)
+			continue
+		}
+		go r.relay(conn)
+	}
+}
+
+// relay copies between conn and the host port until either side closes
+func (r *hostRelay) relay(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	target, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", r.hostPort), relayDialTimeout)
+	if err != nil {
+		utils.Verbose("Relay of port %d failed to reach localhost:%d: %v", r.devicePort, r.hostPort, err)
+		return
+	}
+	defer func() { _ = target.Close() }()
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func (r *hostRelay) close() error {
+	return r.listener.Close()
+}
+
+// ReversePort relays devicePort on the host's network interfaces to hostPort
+// on localhost, which apps reach at the host's network address. The relay
+// lasts as long as this process, or until it's removed.
+func (d *IOSDevice) ReversePort(devicePort, hostPort int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if existing, ok := d.reverses[devicePort]; ok {
+		_ = existing.close()
+		delete(d.reverses, devicePort)
+	}
+
+	relay, err := startHostRelay(devicePort, hostPort)
+	if err != nil {
+		return err
+	}
+
+	if d.reverses == nil {
+		d.reverses = make(map[int]*hostRelay)
+	}
+	d.reverses[devicePort] = relay
+	return nil
+}
+
+// RemoveReverse stops the relay of a device port, or every one when
+// devicePort is 0
+func (d *IOSDevice) RemoveReverse(devicePort int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if devicePort == 0 {
+		for port, relay := range d.reverses {
+			_ = relay.close()
+			delete(d.reverses, port)
+		}
+		return nil
+	}
+
+	relay, ok := d.reverses[devicePort]
+	if !ok {
+		return fmt.Errorf("port %d is not relayed", devicePort)
+	}
+	delete(d.reverses, devicePort)
+	return relay.close()
+}
+
+// ListReverses lists the relays this process holds for the device
+func (d *IOSDevice) ListReverses() ([]PortReverse, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reverses := []PortReverse{}
+	for _, relay := range d.reverses {
+		reverses = append(reverses, PortReverse{
+			DeviceID:   d.Udid,
+			DevicePort: relay.devicePort,
+			HostPort:   relay.hostPort,
+		})
+	}
+	sort.Slice(reverses, func(i, j int) bool { return reverses[i].DevicePort < reverses[j].DevicePort })
+	return reverses, nil
+}
+
+// cleanupReverses stops every relay of the device
+func (d *IOSDevice) cleanupReverses() error {
+	return d.RemoveReverse(0)
+}
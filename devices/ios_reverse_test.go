@@ -0,0 +1,54 @@
+package devices
+
+import (
+	"bufio"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostRelay(t *testing.T) {
+	// a server that only listens on localhost, like a mock server
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+	go func() {
+		conn, err := server.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		_, _ = conn.Write([]byte("echo " + line))
+	}()
+
+	relay, err := startHostRelay(0, server.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, err)
+	defer func() { _ = relay.close() }()
+
+	conn, err := net.Dial("tcp", relay.listener.Addr().String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = conn.Write([]byte("hello\n"))
+	require.NoError(t, err)
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo hello\n", reply)
+}
+
+func TestIOSDeviceReverses(t *testing.T) {
+	d := &IOSDevice{Udid: "00008030-001A"}
+
+	require.NoError(t, d.ReversePort(0, 3000))
+	reverses, err := d.ListReverses()
+	require.NoError(t, err)
+	assert.Equal(t, []PortReverse{{DeviceID: "00008030-001A", DevicePort: 0, HostPort: 3000}}, reverses)
+
+	require.NoError(t, d.RemoveReverse(0))
+	reverses, err = d.ListReverses()
+	require.NoError(t, err)
+	assert.Empty(t, reverses)
+}
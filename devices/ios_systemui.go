@@ -0,0 +1,88 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/mobile-next/mobilecli/devices/wda"
+)
+
+// iosSettingsBundleID is the bundle ID of the Settings app
+const iosSettingsBundleID = "com.apple.Preferences"
+
+// openControlCenter swipes down from the top right corner, where Control
+// Center opens on devices without a home button
+func openControlCenter(client *wda.WdaClient) error {
+	size, err := client.GetWindowSize()
+	if err != nil {
+		return err
+	}
+
+	x := size.ScreenSize.Width * 9 / 10
+	return client.Swipe(x, 2, x, size.ScreenSize.Height/2, 300)
+}
+
+// closeControlCenter swipes up from the bottom of the screen, which closes
+// Control Center without leaving the app underneath
+func closeControlCenter(client *wda.WdaClient) error {
+	size, err := client.GetWindowSize()
+	if err != nil {
+		return err
+	}
+
+	x := size.ScreenSize.Width / 2
+	return client.Swipe(x, size.ScreenSize.Height*9/10, x, size.ScreenSize.Height/5, 300)
+}
+
+func (d *IOSDevice) GetAlert() (*wda.Alert, error) {
+	return d.wdaClient.GetAlert()
+}
+
+func (d *IOSDevice) AcceptAlert(button string) error {
+	return d.wdaClient.AcceptAlert(button)
+}
+
+func (d *IOSDevice) DismissAlert(button string) error {
+	return d.wdaClient.DismissAlert(button)
+}
+
+func (d *IOSDevice) OpenControlCenter() error {
+	return openControlCenter(d.wdaClient)
+}
+
+func (d *IOSDevice) CloseControlCenter() error {
+	return closeControlCenter(d.wdaClient)
+}
+
+func (d *IOSDevice) OpenSettings() error {
+	if err := d.LaunchApp(iosSettingsBundleID, LaunchOptions{}); err != nil {
+		return fmt.Errorf("failed to open Settings: %w", err)
+	}
+	return nil
+}
+
+func (s *SimulatorDevice) GetAlert() (*wda.Alert, error) {
+	return s.wdaClient.GetAlert()
+}
+
+func (s *SimulatorDevice) AcceptAlert(button string) error {
+	return s.wdaClient.AcceptAlert(button)
+}
+
+func (s *SimulatorDevice) DismissAlert(button string) error {
+	return s.wdaClient.DismissAlert(button)
+}
+
+func (s *SimulatorDevice) OpenControlCenter() error {
+	return openControlCenter(s.wdaClient)
+}
+
+func (s *SimulatorDevice) CloseControlCenter() error {
+	return closeControlCenter(s.wdaClient)
+}
+
+func (s *SimulatorDevice) OpenSettings() error {
+	if err := s.LaunchApp(iosSettingsBundleID, LaunchOptions{}); err != nil {
+		return fmt.Errorf("failed to open Settings: %w", err)
+	}
+	return nil
+}
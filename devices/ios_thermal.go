@@ -0,0 +1,34 @@
+package devices
+
+import (
+	"fmt"
+
+	"github.com/danielpaulus/go-ios/ios/diagnostics"
+)
+
+// ReadThermal returns the battery temperature. iOS doesn't report a thermal
+// status over diagnostics.
+func (d *IOSDevice) ReadThermal() (*ThermalReading, error) {
+	if err := d.startTunnel(); err != nil {
+		return nil, fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	conn, err := diagnostics.New(device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to diagnostics: %w", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	battery, err := conn.Battery()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read battery state: %w", err)
+	}
+
+	// the registry reports hundredths of a degree Celsius
+	return &ThermalReading{BatteryTemperature: float64(battery.Temperature) / 100}, nil
+}
@@ -0,0 +1,113 @@
+package devices
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	goios "github.com/danielpaulus/go-ios/ios"
+)
+
+// webInspectorDOMExpression returns the HTML of a page, WebKit's
+// DOM.getDocument only returning the top of the tree
+const webInspectorDOMExpression = "document.documentElement.outerHTML"
+
+// webInspector connects to the simulator's webinspectord, through the socket
+// it listens on in the host's filesystem (RWI_LISTEN_SOCKET)
+func (s *SimulatorDevice) webInspector() (*webInspectorClient, error) {
+	output, err := runSimctl("spawn", s.UDID, "launchctl", "getenv", "RWI_LISTEN_SOCKET")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the web inspector socket: %w", err)
+	}
+
+	socket := strings.TrimSpace(string(output))
+	if socket == "" {
+		return nil, fmt.Errorf("web inspector is not running on simulator %s, is it booted?", s.UDID)
+	}
+
+	conn, err := net.DialTimeout("unix", socket, webInspectorTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the web inspector: %w", err)
+	}
+	return newWebInspectorClient(conn)
+}
+
+// ListDevToolsTargets lists the Safari tabs and inspectable webviews of the
+// simulator
+func (s *SimulatorDevice) ListDevToolsTargets() ([]DevToolsTarget, error) {
+	client, err := s.webInspector()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	return listWebInspectorTargets(client)
+}
+
+// DumpDevToolsTarget returns the HTML of a page
+func (s *SimulatorDevice) DumpDevToolsTarget(targetID string) (any, error) {
+	return s.EvaluateDevToolsTarget(targetID, webInspectorDOMExpression)
+}
+
+// EvaluateDevToolsTarget runs a JavaScript expression in a page and returns
+// its value
+func (s *SimulatorDevice) EvaluateDevToolsTarget(targetID, expression string) (any, error) {
+	client, err := s.webInspector()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	return evaluateWebInspectorTarget(client, targetID, expression)
+}
+
+// webInspector connects to the device's webinspectord, through the RSD shim
+// on iOS 17 and later. Pages are only inspectable with Web Inspector enabled
+// in Settings > Safari > Advanced.
+func (d *IOSDevice) webInspector() (*webInspectorClient, error) {
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return nil, err
+	}
+
+	var conn goios.DeviceConnectionInterface
+	if device.SupportsRsd() {
+		conn, err = goios.ConnectToShimService(device, "com.apple.webinspector.shim.remote")
+	} else {
+		conn, err = goios.ConnectToService(device, "com.apple.webinspector")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the web inspector: %w", err)
+	}
+
+	return newWebInspectorClient(conn)
+}
+
+// ListDevToolsTargets lists the Safari tabs and inspectable webviews of the
+// device
+func (d *IOSDevice) ListDevToolsTargets() ([]DevToolsTarget, error) {
+	client, err := d.webInspector()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	return listWebInspectorTargets(client)
+}
+
+// DumpDevToolsTarget returns the HTML of a page
+func (d *IOSDevice) DumpDevToolsTarget(targetID string) (any, error) {
+	return d.EvaluateDevToolsTarget(targetID, webInspectorDOMExpression)
+}
+
+// EvaluateDevToolsTarget runs a JavaScript expression in a page and returns
+// its value
+func (d *IOSDevice) EvaluateDevToolsTarget(targetID, expression string) (any, error) {
+	client, err := d.webInspector()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = client.Close() }()
+
+	return evaluateWebInspectorTarget(client, targetID, expression)
+}
@@ -49,7 +49,7 @@ func setCachedAgentPort(udid, bundleID string, port int) {
 // process of a specific bundle ID inside the given simulator.
 // Returns the PID and the .app bundle path.
 func findSimulatorPIDForBundle(udid, bundleID string) (pid int, appBundlePath string, err error) {
-	out, err := exec.Command("ps", "aux").Output()
+	out, err := utils.Output(exec.Command("ps", "aux"))
 	if err != nil {
 		return 0, "", fmt.Errorf("ps aux: %w", err)
 	}
@@ -99,7 +99,7 @@ func appBundleFromExecPath(execPath string) string {
 // bundleIDFromInfoPlist reads CFBundleIdentifier from an Info.plist using
 // the macOS `defaults read` command, which handles both XML and binary plists.
 func bundleIDFromInfoPlist(plistPath string) (string, error) {
-	out, err := exec.Command("defaults", "read", plistPath, "CFBundleIdentifier").Output()
+	out, err := utils.Output(exec.Command("defaults", "read", plistPath, "CFBundleIdentifier"))
 	if err != nil {
 		return "", fmt.Errorf("defaults read %s: %w", plistPath, err)
 	}
@@ -144,7 +144,7 @@ func injectIOSAgent(pid int, dylibPath string) (int, error) {
 		"-o", "detach",
 		"-o", "quit",
 	)
-	out, err := cmd.CombinedOutput()
+	out, err := utils.CombinedOutput(cmd)
 	if err != nil {
 		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
 			return 0, fmt.Errorf("lldb timed out after %s", lldbTimeout)
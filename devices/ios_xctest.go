@@ -0,0 +1,167 @@
+package devices
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/danielpaulus/go-ios/ios/testmanagerd"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// RunXCTest runs UI tests through testmanagerd. An .xctestrun names apps that
+// must already be installed and runs all of its tests; a runner app is
+// installed along with the app under test, and can run only some tests.
+// Test events come from the runner's console output, the results from
+// testmanagerd.
+func (d *IOSDevice) RunXCTest(config XCTestConfig) (*TestResults, error) {
+	if config.XCTestRun == "" && config.RunnerApp == "" {
+		return nil, fmt.Errorf("an .xctestrun file or a test runner app is required")
+	}
+	if config.XCTestRun != "" && len(config.OnlyTesting) > 0 {
+		return nil, fmt.Errorf("only running some tests of an .xctestrun is not supported on real devices, run the test runner app instead")
+	}
+
+	var testConfig testmanagerd.TestConfig
+	if config.XCTestRun == "" {
+		bundle, err := runnerAppTestBundle(config.RunnerApp)
+		if err != nil {
+			return nil, err
+		}
+
+		runner, err := utils.ParseAppMetadata(config.RunnerApp)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(config.RunnerApp), err)
+		}
+		testConfig = testmanagerd.TestConfig{
+			TestRunnerBundleId: runner.PackageName,
+			XctestConfigName:   bundle,
+			Env:                map[string]any{},
+			Args:               []string{},
+		}
+
+		if config.AppPath != "" {
+			app, err := utils.ParseAppMetadata(config.AppPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(config.AppPath), err)
+			}
+			testConfig.BundleId = app.PackageName
+		}
+
+		for _, test := range config.OnlyTesting {
+			testConfig.TestsToRun = append(testConfig.TestsToRun, normalizeXCTestIdentifier(test))
+		}
+
+		for _, path := range []string{config.AppPath, config.RunnerApp} {
+			if path == "" {
+				continue
+			}
+			if err := d.InstallApp(path); err != nil {
+				return nil, fmt.Errorf("failed to install %s: %w", filepath.Base(path), err)
+			}
+		}
+	}
+
+	// ensure tunnel is running for iOS 17+
+	if err := d.startTunnel(); err != nil {
+		return nil, fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	// screenshots the tests attach aren't reported, but are written somewhere
+	attachments, err := os.MkdirTemp("", "mobilecli-xctest-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(attachments) }()
+
+	parser := newXCTestParser(config.OnEvent)
+	listener := testmanagerd.NewTestListener(parser, io.Discard, attachments)
+
+	startedAt := time.Now()
+	var suites []testmanagerd.TestSuite
+	if config.XCTestRun != "" {
+		suites, err = testmanagerd.StartXCTestWithConfig(context.Background(), config.XCTestRun, device, listener)
+	} else {
+		testConfig.Device = device
+		testConfig.Listener = listener
+		suites, err = testmanagerd.RunTestWithConfig(context.Background(), testConfig)
+	}
+	duration := time.Since(startedAt).Milliseconds()
+
+	runner := config.XCTestRun
+	if runner == "" {
+		runner = testConfig.TestRunnerBundleId
+	}
+
+	// without suites nothing ran, and what the runner logged says why
+	if len(suites) == 0 {
+		result := parser.finish(runner, duration, err)
+		if err != nil && len(result.Tests) == 0 {
+			return nil, fmt.Errorf("failed to run tests: %w", err)
+		}
+		return result, nil
+	}
+
+	result := xctestSuitesResults(runner, suites, duration)
+	if err != nil {
+		result.Complete = false
+		result.Message = fmt.Sprintf("tests ended with %v", err)
+	}
+	return result, nil
+}
+
+// xctestStatusSkipped is the status testmanagerd reports for a test that
+// called XCTSkip, which go-ios has no constant for
+const xctestStatusSkipped = testmanagerd.TestCaseStatus("skipped")
+
+// xctestSuitesResults converts the test suites testmanagerd reported
+func xctestSuitesResults(runner string, suites []testmanagerd.TestSuite, duration int64) *TestResults {
+	result := &TestResults{Runner: runner, Duration: duration}
+
+	var stalled []string
+	for _, suite := range suites {
+		for _, testCase := range suite.TestCases {
+			test := TestCaseResult{
+				Class:    testCase.ClassName,
+				Name:     testCase.MethodName,
+				Duration: testCase.Duration.Milliseconds(),
+			}
+
+			switch testCase.Status {
+			case testmanagerd.StatusPassed, testmanagerd.StatusExpectedFailure:
+				test.Status = TestStatusPassed
+			case xctestStatusSkipped:
+				test.Status = TestStatusSkipped
+			case testmanagerd.StatusStalled:
+				// the test started but never finished
+				test.Status = TestStatusFailed
+				test.Failure = "test did not finish"
+				stalled = append(stalled, test.Class+"/"+test.Name)
+			default:
+				test.Status = TestStatusFailed
+				test.Failure = testCase.Err.Message
+				if testCase.Err.File != "" {
+					test.Failure = fmt.Sprintf("%s:%d: %s", testCase.Err.File, testCase.Err.Line, testCase.Err.Message)
+				}
+			}
+			result.Tests = append(result.Tests, test)
+		}
+	}
+	result.countTests(0)
+
+	if len(stalled) > 0 {
+		result.Message = "tests ended while running " + strings.Join(stalled, ", ")
+	}
+	result.Complete = result.Message == ""
+
+	return result
+}
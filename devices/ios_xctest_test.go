@@ -0,0 +1,24 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/danielpaulus/go-ios/ios/testmanagerd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXCTestSuitesResultsSkipped(t *testing.T) {
+	suites := []testmanagerd.TestSuite{{
+		TestCases: []testmanagerd.TestCase{
+			{ClassName: "LoginTests", MethodName: "testLogin", Status: testmanagerd.StatusPassed},
+			{ClassName: "LoginTests", MethodName: "testSSO", Status: xctestStatusSkipped},
+		},
+	}}
+
+	result := xctestSuitesResults("MyAppUITests-Runner", suites, 0)
+	assert.Equal(t, TestStatusSkipped, result.Tests[1].Status)
+	assert.Equal(t, 1, result.Passed)
+	assert.Equal(t, 0, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+	assert.True(t, result.Complete)
+}
@@ -0,0 +1,165 @@
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// labelsMu serializes reads and writes of the labels file within a process
+var labelsMu sync.Mutex
+
+// labelsFilePath returns where device labels are stored, next to the
+// credentials file
+func labelsFilePath() (string, error) {
+	dir, err := utils.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "labels.json"), nil
+}
+
+// loadLabels reads the labels of all devices, keyed by device ID
+func loadLabels() (map[string]map[string]string, error) {
+	path, err := labelsFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return make(map[string]map[string]string), nil
+		}
+		return nil, fmt.Errorf("failed to read device labels: %w", err)
+	}
+
+	labels := make(map[string]map[string]string)
+	if err := json.Unmarshal(data, &labels); err != nil {
+		return nil, fmt.Errorf("failed to parse device labels %s: %w", path, err)
+	}
+	return labels, nil
+}
+
+// saveLabels writes the labels of all devices
+func saveLabels(labels map[string]map[string]string) error {
+	path, err := labelsFilePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(labels, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write device labels: %w", err)
+	}
+	return nil
+}
+
+// GetAllDeviceLabels returns the labels of every labeled device, keyed by
+// device ID
+func GetAllDeviceLabels() (map[string]map[string]string, error) {
+	labelsMu.Lock()
+	defer labelsMu.Unlock()
+
+	return loadLabels()
+}
+
+// GetDeviceLabels returns a device's labels, or nil when it has none
+func GetDeviceLabels(deviceID string) (map[string]string, error) {
+	all, err := GetAllDeviceLabels()
+	if err != nil {
+		return nil, err
+	}
+	return all[deviceID], nil
+}
+
+// UpdateDeviceLabels sets and removes labels of a device and returns its
+// resulting labels
+func UpdateDeviceLabels(deviceID string, set map[string]string, unset []string) (map[string]string, error) {
+	labelsMu.Lock()
+	defer labelsMu.Unlock()
+
+	all, err := loadLabels()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := all[deviceID]
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	for key, value := range set {
+		labels[key] = value
+	}
+	for _, key := range unset {
+		delete(labels, key)
+	}
+
+	if len(labels) == 0 {
+		delete(all, deviceID)
+	} else {
+		all[deviceID] = labels
+	}
+
+	if err := saveLabels(all); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// ParseLabels parses "key=value" pairs. A pair may also hold several
+// comma-separated labels, e.g. "team=payments,pool=smoke".
+func ParseLabels(pairs []string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range pairs {
+		for _, label := range strings.Split(pair, ",") {
+			label = strings.TrimSpace(label)
+			if label == "" {
+				continue
+			}
+
+			key, value, ok := strings.Cut(label, "=")
+			key = strings.TrimSpace(key)
+			if !ok || key == "" {
+				return nil, fmt.Errorf("invalid label '%s', expected key=value", label)
+			}
+			labels[key] = strings.TrimSpace(value)
+		}
+	}
+	return labels, nil
+}
+
+// MatchesLabels reports whether labels has every label of selector
+func MatchesLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if got, ok := labels[key]; !ok || got != value {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatLabels formats labels as sorted, comma-separated key=value pairs
+func FormatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,57 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateDeviceLabels(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	labels, err := GetDeviceLabels("emulator-5554")
+	require.NoError(t, err)
+	assert.Nil(t, labels)
+
+	labels, err = UpdateDeviceLabels("emulator-5554", map[string]string{"team": "payments", "pool": "smoke"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "pool": "smoke"}, labels)
+
+	labels, err = UpdateDeviceLabels("emulator-5554", map[string]string{"pool": "nightly"}, []string{"team"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pool": "nightly"}, labels)
+
+	labels, err = GetDeviceLabels("emulator-5554")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"pool": "nightly"}, labels)
+
+	// removing the last label forgets the device
+	_, err = UpdateDeviceLabels("emulator-5554", nil, []string{"pool"})
+	require.NoError(t, err)
+	all, err := GetAllDeviceLabels()
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels([]string{"team=payments,pool=smoke", " os = 17 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"team": "payments", "pool": "smoke", "os": "17"}, labels)
+
+	_, err = ParseLabels([]string{"payments"})
+	assert.Error(t, err)
+
+	_, err = ParseLabels([]string{"=smoke"})
+	assert.Error(t, err)
+}
+
+func TestMatchesLabels(t *testing.T) {
+	labels := map[string]string{"team": "payments", "pool": "smoke"}
+
+	assert.True(t, MatchesLabels(labels, nil))
+	assert.True(t, MatchesLabels(labels, map[string]string{"pool": "smoke"}))
+	assert.False(t, MatchesLabels(labels, map[string]string{"pool": "nightly"}))
+	assert.False(t, MatchesLabels(nil, map[string]string{"pool": "smoke"}))
+	assert.Equal(t, "pool=smoke,team=payments", FormatLabels(labels))
+}
@@ -0,0 +1,88 @@
+package devices
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/danielpaulus/go-ios/ios/syslog"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// streamCommandLines runs cmd and calls onLine with each line it prints
+// until onLine returns false or the command exits
+func streamCommandLines(cmd *exec.Cmd, onLine func(line string) bool) error {
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+
+	if err := utils.Start(cmd); err != nil {
+		return fmt.Errorf("failed to start log stream: %w", err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !onLine(scanner.Text()) {
+			break
+		}
+	}
+
+	_ = cmd.Process.Kill()
+	_ = cmd.Wait()
+	return nil
+}
+
+// StreamLogs streams logcat, starting from the newest line already logged
+func (d *AndroidDevice) StreamLogs(ctx context.Context, onLine func(line string) bool) error {
+	cmd := adbCommandContext(ctx, "-s", d.getAdbIdentifier(), "logcat", "-v", "threadtime", "-T", "1")
+	return streamCommandLines(cmd, onLine)
+}
+
+// StreamLogs streams the simulator's unified log
+func (s *SimulatorDevice) StreamLogs(ctx context.Context, onLine func(line string) bool) error {
+	cmd := exec.CommandContext(ctx, getXcrunPath(), "simctl", "spawn", s.UDID, "log", "stream", "--style", "compact")
+	return streamCommandLines(cmd, onLine)
+}
+
+// StreamLogs streams the device's syslog relay
+func (d *IOSDevice) StreamLogs(ctx context.Context, onLine func(line string) bool) error {
+	if err := d.startTunnel(); err != nil {
+		return fmt.Errorf("failed to start tunnel: %w", err)
+	}
+
+	device, err := d.getEnhancedDevice()
+	if err != nil {
+		return fmt.Errorf("failed to get enhanced device connection: %w", err)
+	}
+
+	conn, err := syslog.New(device)
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+
+	// closing the connection unblocks ReadLogMessage when ctx is done
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer func() {
+		if stop() {
+			_ = conn.Close()
+		}
+	}()
+
+	for {
+		message, err := conn.ReadLogMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to read syslog: %w", err)
+		}
+
+		if !onLine(strings.TrimRight(message, "\x00\n")) {
+			return nil
+		}
+	}
+}
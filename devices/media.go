@@ -0,0 +1,35 @@
+package devices
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Media kinds reported by MediaKind
+const (
+	MediaKindPhoto = "photo"
+	MediaKindVideo = "video"
+)
+
+var mediaExtensions = map[string]string{
+	".jpg":  MediaKindPhoto,
+	".jpeg": MediaKindPhoto,
+	".png":  MediaKindPhoto,
+	".gif":  MediaKindPhoto,
+	".heic": MediaKindPhoto,
+	".webp": MediaKindPhoto,
+	".mp4":  MediaKindVideo,
+	".mov":  MediaKindVideo,
+	".m4v":  MediaKindVideo,
+	".3gp":  MediaKindVideo,
+}
+
+// MediaKind tells photos from videos by their file extension
+func MediaKind(path string) (string, error) {
+	kind, ok := mediaExtensions[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return "", fmt.Errorf("unsupported media file %s, expected a photo (.jpg, .jpeg, .png, .gif, .heic, .webp) or a video (.mp4, .mov, .m4v, .3gp)", filepath.Base(path))
+	}
+	return kind, nil
+}
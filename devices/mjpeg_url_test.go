@@ -0,0 +1,14 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMjpegURL(t *testing.T) {
+	assert.Equal(t, "http://localhost:8100/mjpeg", buildMjpegURL(8100, 0, 0, 0))
+	assert.Equal(t, "http://localhost:8100/mjpeg?fps=30&quality=80", buildMjpegURL(8100, 30, 80, 1.0))
+	assert.Equal(t, "http://localhost:8100/mjpeg?quality=50&scale=25", buildMjpegURL(8100, 0, 50, 0.25))
+	assert.Equal(t, "http://localhost:8100/mjpeg?fps=15&scale=50", buildMjpegURL(8100, 15, 0, 0.5))
+}
@@ -0,0 +1,61 @@
+package devices
+
+import "fmt"
+
+// Orientations a device reports and can be set to
+const (
+	OrientationPortrait           = "portrait"
+	OrientationPortraitUpsideDown = "portraitUpsideDown"
+	OrientationLandscapeLeft      = "landscapeLeft"
+	OrientationLandscapeRight     = "landscapeRight"
+
+	// OrientationLandscape is accepted for OrientationLandscapeLeft, which is
+	// what setting "landscape" has always meant
+	OrientationLandscape = "landscape"
+)
+
+// orientationRotations maps each orientation to Android's user_rotation, the
+// number of quarter turns counter-clockwise from portrait
+var orientationRotations = map[string]int{
+	OrientationPortrait:           0,
+	OrientationLandscapeLeft:      1,
+	OrientationPortraitUpsideDown: 2,
+	OrientationLandscapeRight:     3,
+}
+
+// NormalizeOrientation validates an orientation, resolving "landscape" to
+// landscapeLeft
+func NormalizeOrientation(orientation string) (string, error) {
+	if orientation == OrientationLandscape {
+		return OrientationLandscapeLeft, nil
+	}
+	if _, ok := orientationRotations[orientation]; !ok {
+		return "", fmt.Errorf("invalid orientation value '%s', must be one of portrait, portraitUpsideDown, landscapeLeft, landscapeRight or landscape", orientation)
+	}
+	return orientation, nil
+}
+
+// OrientationRotation returns the rotation of an orientation, in quarter
+// turns counter-clockwise from portrait as Android's user_rotation counts them
+func OrientationRotation(orientation string) int {
+	orientation, err := NormalizeOrientation(orientation)
+	if err != nil {
+		return 0
+	}
+	return orientationRotations[orientation]
+}
+
+// RotationOrientation returns the orientation of an Android user_rotation
+func RotationOrientation(rotation int) (string, error) {
+	for orientation, r := range orientationRotations {
+		if r == rotation {
+			return orientation, nil
+		}
+	}
+	return "", fmt.Errorf("invalid rotation %d, must be 0-3", rotation)
+}
+
+// IsLandscape reports whether an orientation is either landscape orientation
+func IsLandscape(orientation string) bool {
+	return orientation == OrientationLandscape || orientation == OrientationLandscapeLeft || orientation == OrientationLandscapeRight
+}
@@ -0,0 +1,58 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOrientation(t *testing.T) {
+	orientation, err := NormalizeOrientation("landscape")
+	require.NoError(t, err)
+	assert.Equal(t, OrientationLandscapeLeft, orientation)
+
+	orientation, err = NormalizeOrientation("portraitUpsideDown")
+	require.NoError(t, err)
+	assert.Equal(t, OrientationPortraitUpsideDown, orientation)
+
+	_, err = NormalizeOrientation("sideways")
+	assert.Error(t, err)
+}
+
+func TestOrientationRotation(t *testing.T) {
+	for rotation, orientation := range []string{OrientationPortrait, OrientationLandscapeLeft, OrientationPortraitUpsideDown, OrientationLandscapeRight} {
+		assert.Equal(t, rotation, OrientationRotation(orientation))
+
+		got, err := RotationOrientation(rotation)
+		require.NoError(t, err)
+		assert.Equal(t, orientation, got)
+	}
+
+	assert.Equal(t, 1, OrientationRotation(OrientationLandscape))
+
+	_, err := RotationOrientation(4)
+	assert.Error(t, err)
+}
+
+func TestIsLandscape(t *testing.T) {
+	assert.True(t, IsLandscape(OrientationLandscape))
+	assert.True(t, IsLandscape(OrientationLandscapeRight))
+	assert.False(t, IsLandscape(OrientationPortraitUpsideDown))
+}
+
+func TestParseSurfaceOrientation(t *testing.T) {
+	rotation, ok := parseSurfaceOrientation("  Viewport INTERNAL: displayId=0\n    SurfaceWidth: 2400px\n    SurfaceHeight: 1080px\n    SurfaceOrientation: 1\n")
+	require.True(t, ok)
+	assert.Equal(t, 1, rotation)
+
+	_, ok = parseSurfaceOrientation("Input Manager State:\n")
+	assert.False(t, ok)
+}
+
+func TestRotateScreenSize(t *testing.T) {
+	for rotation, want := range [][2]int{{1080, 2400}, {2400, 1080}, {1080, 2400}, {2400, 1080}} {
+		width, height := rotateScreenSize(1080, 2400, rotation)
+		assert.Equal(t, want, [2]int{width, height}, "rotation %d", rotation)
+	}
+}
@@ -123,8 +123,12 @@ func (r *RemoteDevice) LongPress(x, y, duration int) error {
 	return r.fireRPC("device.io.longpress", params{"x": x, "y": y, "duration": duration})
 }
 
-func (r *RemoteDevice) Swipe(x1, y1, x2, y2 int) error {
-	return r.fireRPC("device.io.swipe", params{"x1": x1, "y1": y1, "x2": x2, "y2": y2})
+func (r *RemoteDevice) Swipe(x1, y1, x2, y2, duration int) error {
+	p := params{"x1": x1, "y1": y1, "x2": x2, "y2": y2}
+	if duration > 0 {
+		p["durationMs"] = duration
+	}
+	return r.fireRPC("device.io.swipe", p)
 }
 
 func (r *RemoteDevice) Gesture(actions []wda.TapAction) error {
@@ -159,6 +163,9 @@ func (r *RemoteDevice) LaunchApp(bundleID string, opts LaunchOptions) error {
 	if opts.Activity != "" {
 		p["activity"] = opts.Activity
 	}
+	if opts.Wait {
+		p["wait"] = true
+	}
 	return r.fireRPC("device.apps.launch", p)
 }
 
@@ -166,8 +173,12 @@ func (r *RemoteDevice) TerminateApp(bundleID string) error {
 	return r.fireRPC("device.apps.terminate", params{"bundleId": bundleID})
 }
 
-func (r *RemoteDevice) Boot() error {
-	return r.fireRPC("device.boot", params{})
+func (r *RemoteDevice) Boot(config BootConfig) error {
+	p := params{}
+	if config.Timeout > 0 {
+		p["timeout"] = int(config.Timeout.Seconds())
+	}
+	return r.fireRPC("device.boot", p)
 }
 
 func (r *RemoteDevice) Shutdown() error {
@@ -237,7 +248,7 @@ func sanitizeFilename(name string) string {
 
 var uploadHTTPClient = &http.Client{Timeout: 5 * time.Minute}
 
-func uploadFileToURL(filePath, uploadURL string) error {
+func uploadFileToURL(filePath, uploadURL string, config InstallConfig) error {
 	u, err := url.Parse(uploadURL)
 	if err != nil {
 		return fmt.Errorf("invalid upload URL: %w", err)
@@ -262,7 +273,8 @@ func uploadFileToURL(filePath, uploadURL string) error {
 
 	start := time.Now()
 
-	req, err := http.NewRequest(http.MethodPut, u.String(), f)
+	body := &installProgressReader{reader: f, total: fi.Size(), config: config}
+	req, err := http.NewRequest(http.MethodPut, u.String(), body)
 	if err != nil {
 		return fmt.Errorf("failed to create upload request: %w", err)
 	}
@@ -351,6 +363,12 @@ func downloadFile(downloadURL, outputPath string, cb *ScreenRecordCallbacks) err
 }
 
 func (r *RemoteDevice) InstallApp(path string) error {
+	return r.InstallAppWithProgress(path, InstallConfig{})
+}
+
+// InstallAppWithProgress reports the upload of the app to the device's host;
+// the install on the remote device itself reports no progress
+func (r *RemoteDevice) InstallAppWithProgress(path string, config InstallConfig) error {
 	fi, err := os.Stat(path)
 	if err != nil {
 		return fmt.Errorf("failed to stat file: %w", err)
@@ -371,11 +389,16 @@ func (r *RemoteDevice) InstallApp(path string) error {
 		return err
 	}
 
-	if err := uploadFileToURL(path, upload.UploadURL); err != nil {
+	if err := uploadFileToURL(path, upload.UploadURL, config); err != nil {
 		return err
 	}
 
-	return r.fireRPC("device.apps.install", params{"uploadId": upload.UploadID})
+	if err := r.fireRPC("device.apps.install", params{"uploadId": upload.UploadID}); err != nil {
+		return err
+	}
+
+	config.progress(InstallProgress{Phase: InstallPhaseCompleted, Percent: 100})
+	return nil
 }
 
 func (r *RemoteDevice) UninstallApp(packageName string) (*InstalledAppInfo, error) {
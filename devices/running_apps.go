@@ -0,0 +1,39 @@
+package devices
+
+import "sort"
+
+var processStateRank = map[string]int{
+	ProcessStateForeground: 0,
+	ProcessStateBackground: 1,
+	ProcessStateCached:     2,
+}
+
+// groupRunningProcesses groups processes by the package they belong to. An app's
+// state is the most active state of any of its processes.
+func groupRunningProcesses(processes map[string][]RunningProcess) []RunningAppInfo {
+	apps := make([]RunningAppInfo, 0, len(processes))
+	for packageName, procs := range processes {
+		state := ProcessStateCached
+		for _, p := range procs {
+			if processStateRank[p.State] < processStateRank[state] {
+				state = p.State
+			}
+		}
+
+		sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+		apps = append(apps, RunningAppInfo{
+			PackageName: packageName,
+			State:       state,
+			Processes:   procs,
+		})
+	}
+
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].State != apps[j].State {
+			return processStateRank[apps[i].State] < processStateRank[apps[j].State]
+		}
+		return apps[i].PackageName < apps[j].PackageName
+	})
+
+	return apps
+}
@@ -1,6 +1,8 @@
 package devices
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -29,6 +31,7 @@ type AppInfo struct {
 	CFBundleIdentifier  string `json:"CFBundleIdentifier"`
 	CFBundleDisplayName string `json:"CFBundleDisplayName"`
 	CFBundleVersion     string `json:"CFBundleVersion"`
+	CFBundleExecutable  string `json:"CFBundleExecutable"`
 }
 
 // devicePlist represents the structure of device.plist
@@ -99,6 +102,9 @@ func (s SimulatorDevice) Reboot() error {
 		utils.Verbose("SimulatorDevice: Shutdown successful for %s.", s.UDID)
 	}
 
+	// the agent goes down with the simulator
+	wda.Pool.Remove(s.UDID)
+
 	// Boot the simulator
 	utils.Verbose("SimulatorDevice: Booting %s...", s.UDID)
 	output, err = runSimctl("boot", s.UDID)
@@ -112,8 +118,8 @@ func (s SimulatorDevice) Reboot() error {
 // runSimctl executes xcrun simctl with the provided arguments
 func runSimctl(args ...string) ([]byte, error) {
 	fullArgs := append([]string{"simctl"}, args...)
-	cmd := exec.Command("xcrun", fullArgs...)
-	output, err := cmd.CombinedOutput()
+	cmd := exec.Command(getXcrunPath(), fullArgs...)
+	output, err := utils.CombinedOutput(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute xcrun simctl command: %w", err)
 	}
@@ -179,28 +185,105 @@ func GetSimulators() ([]Simulator, error) {
 	return simulators, nil
 }
 
-// filterSimulatorsByDownloadsDirectory filters simulators that have been booted at least once
-// by checking if the Downloads directory exists
-func filterSimulatorsByDownloadsDirectory(simulators []Simulator) []Simulator {
+// hasBootedOnce checks if a simulator has been booted at least once, by
+// checking if its Downloads directory exists
+func hasBootedOnce(udid string) bool {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
-		return nil
+		return false
 	}
 
+	downloadsPath := fmt.Sprintf("%s/Library/Developer/CoreSimulator/Devices/%s/data/Downloads", homeDir, udid)
+	_, err = os.Stat(downloadsPath)
+	return err == nil
+}
+
+// filterSimulatorsByDownloadsDirectory filters simulators that have been booted at least once
+func filterSimulatorsByDownloadsDirectory(simulators []Simulator) []Simulator {
 	var filteredDevices []Simulator
 	for _, device := range simulators {
-		downloadsPath := fmt.Sprintf("%s/Library/Developer/CoreSimulator/Devices/%s/data/Downloads", homeDir, device.UDID)
-		if _, err := os.Stat(downloadsPath); err == nil {
+		if hasBootedOnce(device.UDID) {
 			filteredDevices = append(filteredDevices, device)
 		}
 	}
 	return filteredDevices
 }
 
+// SimulatorDetails describes a simulator's device type and runtime, listed
+// with DeviceListOptions.IncludeUnbooted
+type SimulatorDetails struct {
+	DeviceType string `json:"deviceType"`
+	Runtime    string `json:"runtime"`
+
+	// RuntimeInstalled is false when the simulator's runtime was deleted,
+	// RuntimeAvailable false when it is installed but can't be used, e.g.
+	// with an older Xcode, with the reason in RuntimeError
+	RuntimeInstalled bool   `json:"runtimeInstalled"`
+	RuntimeAvailable bool   `json:"runtimeAvailable"`
+	RuntimeError     string `json:"runtimeError,omitempty"`
+
+	BootedOnce bool `json:"bootedOnce"`
+}
+
+// simulatorRuntime is an entry of 'simctl list runtimes -j'
+type simulatorRuntime struct {
+	Identifier        string `json:"identifier"`
+	IsAvailable       bool   `json:"isAvailable"`
+	AvailabilityError string `json:"availabilityError"`
+}
+
+// parseSimulatorRuntimes maps runtime identifiers to their entry
+func parseSimulatorRuntimes(output []byte) (map[string]simulatorRuntime, error) {
+	var list struct {
+		Runtimes []simulatorRuntime `json:"runtimes"`
+	}
+	if err := json.Unmarshal(output, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse simulator runtimes: %w", err)
+	}
+
+	runtimes := make(map[string]simulatorRuntime, len(list.Runtimes))
+	for _, r := range list.Runtimes {
+		runtimes[r.Identifier] = r
+	}
+	return runtimes, nil
+}
+
+// getSimulatorRuntimes lists the installed simulator runtimes
+func getSimulatorRuntimes() (map[string]simulatorRuntime, error) {
+	output, err := runSimctl("list", "runtimes", "-j")
+	if err != nil {
+		return nil, err
+	}
+	return parseSimulatorRuntimes(output)
+}
+
+// simulatorDetails describes a simulator against the installed runtimes
+func simulatorDetails(sim Simulator, runtimes map[string]simulatorRuntime) *SimulatorDetails {
+	details := &SimulatorDetails{
+		DeviceType: sim.DeviceType,
+		Runtime:    sim.Runtime,
+		BootedOnce: hasBootedOnce(sim.UDID),
+	}
+
+	if runtimes == nil {
+		details.RuntimeError = "simulator runtimes could not be listed"
+		return details
+	}
+
+	if r, ok := runtimes[sim.Runtime]; ok {
+		details.RuntimeInstalled = true
+		details.RuntimeAvailable = r.IsAvailable
+		details.RuntimeError = r.AvailabilityError
+	} else {
+		details.RuntimeError = "runtime is not installed"
+	}
+	return details
+}
+
 func (s SimulatorDevice) LaunchAppWithEnv(bundleID string, env map[string]string) error {
 	// Build simctl command
 	fullArgs := append([]string{"simctl", "launch"}, s.UDID, bundleID)
-	cmd := exec.Command("xcrun", fullArgs...)
+	cmd := exec.Command(getXcrunPath(), fullArgs...)
 
 	// Set environment variables with SIMCTL_CHILD_ prefix for this command only
 	cmd.Env = os.Environ()
@@ -208,7 +291,7 @@ func (s SimulatorDevice) LaunchAppWithEnv(bundleID string, env map[string]string
 		cmd.Env = append(cmd.Env, fmt.Sprintf("SIMCTL_CHILD_%s=%s", key, value))
 	}
 
-	if err := cmd.Run(); err != nil {
+	if err := utils.Run(cmd); err != nil {
 		return fmt.Errorf("failed to launch app with env: %w", err)
 	}
 
@@ -294,17 +377,27 @@ func (s SimulatorDevice) WaitUntilAppExists(bundleID string) error {
 // empty string if it is not installed. The runner bundle id can carry a
 // signing/team prefix, so it is matched on suffix rather than exact equality.
 func (s SimulatorDevice) findInstalledAgentBundleID() (string, error) {
-	installedApps, err := s.ListInstalledApps()
-	if err != nil {
+	agent, err := s.findInstalledAgent()
+	if err != nil || agent == nil {
 		return "", err
 	}
+	return agent.PackageName, nil
+}
+
+// findInstalledAgent returns the installed agent with its version, or nil if
+// it is not installed
+func (s SimulatorDevice) findInstalledAgent() (*InstalledAppInfo, error) {
+	apps, err := s.ListApps(false)
+	if err != nil {
+		return nil, err
+	}
 
-	for bundleID := range installedApps {
-		if strings.HasSuffix(bundleID, agentRunnerBundleID) {
-			return bundleID, nil
+	for _, app := range apps {
+		if strings.HasSuffix(app.PackageName, agentRunnerBundleID) {
+			return &app, nil
 		}
 	}
-	return "", nil
+	return nil, nil
 }
 
 func (s *SimulatorDevice) getState() (string, error) {
@@ -322,8 +415,28 @@ func (s *SimulatorDevice) getState() (string, error) {
 	return "", fmt.Errorf("simulator %s not found", s.UDID)
 }
 
+// waitForBootStatus waits for 'simctl bootstatus' to report the simulator as booted
+func (s *SimulatorDevice) waitForBootStatus(config BootConfig) error {
+	ctx, cancel := context.WithTimeout(config.context(), config.timeout())
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, getXcrunPath(), "simctl", "bootstatus", s.UDID)
+	output, err := utils.CombinedOutput(cmd)
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s waiting for simulator to boot", config.timeout())
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("simulator boot cancelled: %w", ctx.Err())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to wait for boot status %s: %w\n%s", s.UDID, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
+
 // Boot boots the iOS simulator
-func (s *SimulatorDevice) Boot() error {
+func (s *SimulatorDevice) Boot(config BootConfig) error {
 	state, err := s.getState()
 	if err != nil {
 		return fmt.Errorf("failed to get simulator state: %w", err)
@@ -333,32 +446,26 @@ func (s *SimulatorDevice) Boot() error {
 		return fmt.Errorf("simulator is already running")
 	}
 
+	config.progress(BootStageBooting)
+
 	if state == "Booting" {
 		utils.Verbose("Simulator is already booting, waiting for boot to complete...")
-		output, err := runSimctl("bootstatus", s.UDID)
+	} else {
+		utils.Verbose("Booting simulator %s...", s.UDID)
+		output, err := runSimctl("boot", s.UDID)
 		if err != nil {
-			return fmt.Errorf("failed to wait for boot status: %w\n%s", err, output)
+			return fmt.Errorf("failed to boot simulator %s: %w\n%s", s.UDID, err, output)
 		}
-
-		utils.Verbose("Simulator booted successfully")
-		s.Simulator.State = "Booted"
-		return nil
-	}
-
-	utils.Verbose("Booting simulator %s...", s.UDID)
-	output, err := runSimctl("boot", s.UDID)
-	if err != nil {
-		return fmt.Errorf("failed to boot simulator %s: %w\n%s", s.UDID, err, output)
+		utils.Verbose("Waiting for simulator to finish booting...")
 	}
 
-	utils.Verbose("Waiting for simulator to finish booting...")
-	output, err = runSimctl("bootstatus", s.UDID)
-	if err != nil {
-		return fmt.Errorf("failed to wait for boot status %s: %w\n%s", s.UDID, err, output)
+	if err := s.waitForBootStatus(config); err != nil {
+		return err
 	}
 
 	utils.Verbose("Simulator booted successfully")
 	s.Simulator.State = "Booted"
+	config.progress(BootStageCompleted)
 	return nil
 }
 
@@ -381,6 +488,9 @@ func (s *SimulatorDevice) Shutdown() error {
 
 	utils.Verbose("Simulator shut down successfully")
 	s.Simulator.State = "Shutdown"
+
+	// the agent went down with the simulator
+	wda.Pool.Remove(s.UDID)
 	return nil
 }
 
@@ -432,7 +542,7 @@ func (s *SimulatorDevice) StartAgent(config StartAgentConfig) error {
 		utils.Verbose("WebDriverAgent is already running on port %d", currentPort)
 
 		// create new client or update with new port
-		s.wdaClient = wda.NewWdaClient(expectedURL)
+		s.wdaClient = wda.Pool.Get(s.UDID, expectedURL)
 		if _, err := s.wdaClient.GetStatus(); err == nil {
 			// double check succeeded
 			return nil // Already running and accessible
@@ -444,13 +554,26 @@ func (s *SimulatorDevice) StartAgent(config StartAgentConfig) error {
 		utils.Verbose("Failed to get existing WDA port: %v", err)
 	}
 
-	agentBundleID, err := s.findInstalledAgentBundleID()
+	wdaSource, err := simulatorWDAConfig()
 	if err != nil {
 		return err
 	}
 
-	if agentBundleID == "" {
-		return fmt.Errorf("agent is not installed, use 'mobilecli agent install --device %s' to install it", s.UDID)
+	var agentBundleID string
+	if wdaSource == nil {
+		agent, err := s.findInstalledAgent()
+		if err != nil {
+			return err
+		}
+
+		if agent == nil {
+			return fmt.Errorf("agent is not installed, use 'mobilecli agent install --device %s' to install it", s.UDID)
+		}
+
+		if err := CheckAgentVersion(s, agent.Version); err != nil {
+			return err
+		}
+		agentBundleID = agent.PackageName
 	}
 
 	if config.OnProgress != nil {
@@ -463,6 +586,26 @@ func (s *SimulatorDevice) StartAgent(config StartAgentConfig) error {
 		return fmt.Errorf("failed to find available port: %w", err)
 	}
 
+	if wdaSource != nil {
+		process, logPath, err := s.startXCTestRunAgent(wdaSource, usePort, config.OnProgress)
+		if err != nil {
+			return err
+		}
+
+		s.wdaClient = wda.Pool.Get(s.UDID, fmt.Sprintf("localhost:%d", usePort))
+
+		if config.OnProgress != nil {
+			config.OnProgress("Waiting for agent to start")
+		}
+
+		if err := s.wdaClient.WaitForAgent(); err != nil {
+			_ = process.Kill()
+			return fmt.Errorf("%w, see the xcodebuild log at %s", err, logPath)
+		}
+
+		return nil
+	}
+
 	utils.Verbose("Starting agent with DEVICEKIT_LISTEN_PORT=%d", usePort)
 
 	env := map[string]string{
@@ -475,7 +618,7 @@ func (s *SimulatorDevice) StartAgent(config StartAgentConfig) error {
 	}
 
 	// update WDA client to use the actual port
-	s.wdaClient = wda.NewWdaClient(fmt.Sprintf("localhost:%d", usePort))
+	s.wdaClient = wda.Pool.Get(s.UDID, fmt.Sprintf("localhost:%d", usePort))
 
 	if config.OnProgress != nil {
 		config.OnProgress("Waiting for agent to start")
@@ -491,7 +634,7 @@ func (s *SimulatorDevice) StartAgent(config StartAgentConfig) error {
 }
 
 func (s SimulatorDevice) PressButton(key string) error {
-	return s.wdaClient.PressButton(key)
+	return pressIOSButton(s.wdaClient, key)
 }
 
 func (s SimulatorDevice) SendKeys(text string) error {
@@ -510,8 +653,8 @@ func (s SimulatorDevice) LongPress(x, y, duration int) error {
 	return s.wdaClient.LongPress(x, y, duration)
 }
 
-func (s SimulatorDevice) Swipe(x1, y1, x2, y2 int) error {
-	return s.wdaClient.Swipe(x1, y1, x2, y2)
+func (s SimulatorDevice) Swipe(x1, y1, x2, y2, duration int) error {
+	return s.wdaClient.Swipe(x1, y1, x2, y2, duration)
 }
 
 func (s SimulatorDevice) Gesture(actions []wda.TapAction) error {
@@ -520,7 +663,7 @@ func (s SimulatorDevice) Gesture(actions []wda.TapAction) error {
 
 func (s *SimulatorDevice) OpenURL(url string) error {
 	// #nosec G204 -- udid is controlled, no shell interpretation
-	return exec.Command("xcrun", "simctl", "openurl", s.ID(), url).Run()
+	return utils.Run(exec.Command(getXcrunPath(), "simctl", "openurl", s.ID(), url))
 }
 
 func (s *SimulatorDevice) ListApps(onlyLaunchable bool) ([]InstalledAppInfo, error) {
@@ -547,6 +690,82 @@ func (s *SimulatorDevice) ListApps(onlyLaunchable bool) ([]InstalledAppInfo, err
 	return apps, nil
 }
 
+// GetAppExecutable returns the executable name of an installed app
+func (s *SimulatorDevice) GetAppExecutable(bundleID string) (string, error) {
+	output, err := runSimctl("listapps", s.ID())
+	if err != nil {
+		return "", fmt.Errorf("failed to list apps: %w", err)
+	}
+
+	var appsMap map[string]AppInfo
+	err = utils.ConvertPlistToJSON(output, &appsMap)
+	if err != nil {
+		return "", err
+	}
+
+	for _, app := range appsMap {
+		if app.CFBundleIdentifier == bundleID {
+			return app.CFBundleExecutable, nil
+		}
+	}
+
+	return "", fmt.Errorf("app %s is not installed", bundleID)
+}
+
+// launchctlAppLine matches app jobs in 'launchctl list' output inside a simulator, e.g.
+// "12345	0	UIKitApplication:com.example.app[a1b2][rb-legacy]"
+var launchctlAppLine = regexp.MustCompile(`^(\d+)\s+\S+\s+UIKitApplication:([^\[\s]+)\[`)
+
+// parseSimulatorRunningApps parses 'launchctl list' output, marking activeBundleID as foreground
+func parseSimulatorRunningApps(output, activeBundleID string) []RunningAppInfo {
+	processes := make(map[string][]RunningProcess)
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := launchctlAppLine.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+
+		pid, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		bundleID := matches[2]
+		state := ProcessStateBackground
+		if bundleID == activeBundleID {
+			state = ProcessStateForeground
+		}
+
+		processes[bundleID] = append(processes[bundleID], RunningProcess{
+			PID:   pid,
+			Name:  bundleID,
+			State: state,
+		})
+	}
+
+	return groupRunningProcesses(processes)
+}
+
+// ListRunningApps lists apps with running processes using launchd inside the simulator
+func (s *SimulatorDevice) ListRunningApps() ([]RunningAppInfo, error) {
+	output, err := runSimctl("spawn", s.UDID, "launchctl", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list simulator processes: %w", err)
+	}
+
+	activeBundleID := ""
+	if s.wdaClient != nil {
+		if activeApp, err := s.wdaClient.GetActiveAppInfo(); err == nil {
+			activeBundleID = activeApp.BundleID
+		} else {
+			utils.Verbose("failed to get active app, reporting all apps as background: %v", err)
+		}
+	}
+
+	return parseSimulatorRunningApps(string(output), activeBundleID), nil
+}
+
 func (s *SimulatorDevice) GetForegroundApp() (*ForegroundAppInfo, error) {
 	// get active app info from WDA
 	activeApp, err := s.wdaClient.GetActiveAppInfo()
@@ -579,6 +798,64 @@ func (s *SimulatorDevice) GetForegroundApp() (*ForegroundAppInfo, error) {
 	}, nil
 }
 
+// ScreenState reports whether the device is locked. The agent can't tell a
+// screen that is off from one that is locked, so it is reported as locked.
+func (s *SimulatorDevice) ScreenState() (string, error) {
+	locked, err := s.wdaClient.IsLocked()
+	if err != nil {
+		return "", err
+	}
+
+	if locked {
+		return ScreenStateLocked, nil
+	}
+	return ScreenStateOn, nil
+}
+
+func (s *SimulatorDevice) Lock() error {
+	return s.wdaClient.Lock()
+}
+
+func (s *SimulatorDevice) Unlock() error {
+	return s.wdaClient.Unlock()
+}
+
+// Wake turns the screen on through WebDriverAgent's unlock, see IOSDevice.Wake
+func (s *SimulatorDevice) Wake() error {
+	return s.wdaClient.Unlock()
+}
+
+// ScreenOff turns the screen off, which locks the simulator
+func (s *SimulatorDevice) ScreenOff() error {
+	return s.wdaClient.Lock()
+}
+
+// SetStayAwake does nothing, simulators have no idle timer and never sleep or
+// lock on their own
+func (s *SimulatorDevice) SetStayAwake(enabled bool) error {
+	return nil
+}
+
+// SetWindowVisible brings Simulator.app to the front showing this simulator,
+// or hides Simulator.app. Simulators keep running without their window.
+func (s *SimulatorDevice) SetWindowVisible(visible bool) error {
+	if s.Simulator.State != "Booted" {
+		return fmt.Errorf("simulator %s is not booted", s.UDID)
+	}
+
+	var cmd *exec.Cmd
+	if visible {
+		cmd = exec.Command("open", "-a", "Simulator", "--args", "-CurrentDeviceUDID", s.UDID)
+	} else {
+		cmd = exec.Command("osascript", "-e", `tell application "System Events" to set visible of process "Simulator" to false`)
+	}
+
+	if output, err := utils.CombinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to change the Simulator.app window: %w\nOutput: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
 func (s *SimulatorDevice) Info() (*FullDeviceInfo, error) {
 	wdaSize, err := s.wdaClient.GetWindowSize()
 	if err != nil {
@@ -603,6 +880,35 @@ func (s *SimulatorDevice) Info() (*FullDeviceInfo, error) {
 	}, nil
 }
 
+// Properties reports simulator properties. Simulators run on the host CPU, so
+// the ABI is the host architecture.
+func (s *SimulatorDevice) Properties() (*DeviceProperties, error) {
+	props := &DeviceProperties{
+		Manufacturer: "Apple",
+		Model:        s.Simulator.DeviceType,
+		ABI:          simulatorABI(runtime.GOARCH),
+		OSVersion:    parseSimulatorVersion(s.Runtime),
+		Serial:       s.UDID,
+	}
+
+	output, err := runSimctl("spawn", s.UDID, "defaults", "read", "-g", "AppleLocale")
+	if err == nil {
+		props.Locale = strings.TrimSpace(string(output))
+	} else {
+		utils.Verbose("failed to read simulator locale: %v", err)
+	}
+
+	return props, nil
+}
+
+// simulatorABI maps a Go architecture name to the Apple architecture name
+func simulatorABI(goarch string) string {
+	if goarch == "amd64" {
+		return "x86_64"
+	}
+	return goarch
+}
+
 func (s *SimulatorDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 	mjpegPort, err := s.getWdaMjpegPort()
 	if err != nil {
@@ -613,9 +919,9 @@ func (s *SimulatorDevice) StartScreenCapture(config ScreenCaptureConfig) error {
 		config.OnProgress("Starting video stream")
 	}
 
-	mjpegURL := buildMjpegURL(mjpegPort, config.FPS, config.Scale)
+	mjpegURL := buildMjpegURL(mjpegPort, config.FPS, config.Quality, config.Scale)
 	mjpegClient := mjpeg.NewWdaMjpegClient(mjpegURL)
-	return mjpegClient.StartScreenCapture(config.Format, config.OnData)
+	return mjpegClient.StartScreenCaptureContext(config.context(), config.Format, config.OnData)
 }
 
 // ScreenRecord records the simulator screen to a local MP4 file using xcrun simctl.
@@ -629,13 +935,13 @@ func (s *SimulatorDevice) ScreenRecord(localOutput string, timeLimit int, stopCh
 	args := []string{"simctl", "io", s.UDID, "recordVideo", "--codec=h264", "--force", localOutput}
 
 	utils.Verbose("Running: xcrun %s", strings.Join(args, " "))
-	cmd := exec.Command("xcrun", args...)
+	cmd := exec.Command(getXcrunPath(), args...)
 
 	// handle Ctrl+C: send SIGINT to the simctl process so it finalizes the video
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	if err := cmd.Start(); err != nil {
+	if err := utils.Start(cmd); err != nil {
 		signal.Stop(sigChan)
 		return fmt.Errorf("failed to start simctl recordVideo: %w", err)
 	}
@@ -679,7 +985,7 @@ type ProcessInfo struct {
 // listAllProcesses returns a list of all running processes with their PIDs and command info
 func listAllProcesses() ([]ProcessInfo, error) {
 	cmd := exec.Command("/bin/ps", "-o", "pid,command", "-E", "-ww", "-e")
-	output, err := cmd.Output()
+	output, err := utils.Output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to run ps command: %w", err)
 	}
@@ -724,7 +1030,7 @@ func findWdaProcessForDevice(deviceUDID string) (int, string, error) {
 	devicePath := fmt.Sprintf("/Library/Developer/CoreSimulator/Devices/%s", deviceUDID)
 
 	for _, proc := range processes {
-		if strings.Contains(proc.Command, devicePath) && strings.Contains(proc.Command, "devicekit-iosUITests-Runner") {
+		if strings.Contains(proc.Command, devicePath) && (strings.Contains(proc.Command, "devicekit-iosUITests-Runner") || strings.Contains(proc.Command, "WebDriverAgentRunner-Runner")) {
 			return proc.PID, proc.Command, nil
 		}
 	}
@@ -790,12 +1096,23 @@ func (s SimulatorDevice) DumpSource() ([]ScreenElement, error) {
 	return s.wdaClient.GetSourceElements()
 }
 
+// DumpSourceWithLimits dumps the UI tree with the agent's snapshots bounded
+// to limits.MaxDepth, which keeps deep webviews from timing out
+func (s SimulatorDevice) DumpSourceWithLimits(limits DumpLimits) ([]ScreenElement, error) {
+	return s.wdaClient.GetSourceElementsWithDepth(limits.MaxDepth)
+}
+
 func (s SimulatorDevice) DumpSourceRaw() (any, error) {
 	return s.wdaClient.GetSourceRaw()
 }
 
 func (s *SimulatorDevice) getWdaPort() (int, error) {
-	return s.getWdaEnvPort("DEVICEKIT_LISTEN_PORT")
+	port, err := s.getWdaEnvPort("DEVICEKIT_LISTEN_PORT")
+	if err != nil {
+		// WebDriverAgent run with xcodebuild, see SimulatorWDAEnvVar
+		return s.getWdaEnvPort("USE_PORT")
+	}
+	return port, nil
 }
 
 func (s *SimulatorDevice) getWdaMjpegPort() (int, error) {
@@ -38,6 +38,50 @@ func (s *SimulatorDevice) GetAppContainerPath(bundleID string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
+// AppContainers returns the app bundle, data and app group containers of
+// an installed app, all of which are on the host's disk
+func (s *SimulatorDevice) AppContainers(bundleID string) (*AppContainers, error) {
+	bundle, err := runSimctl("get_app_container", s.UDID, bundleID, "app")
+	if err != nil {
+		return nil, fmt.Errorf("get_app_container failed: %w", err)
+	}
+
+	data, err := runSimctl("get_app_container", s.UDID, bundleID, "data")
+	if err != nil {
+		return nil, fmt.Errorf("get_app_container failed: %w", err)
+	}
+
+	groups, err := runSimctl("get_app_container", s.UDID, bundleID, "groups")
+	if err != nil {
+		return nil, fmt.Errorf("get_app_container failed: %w", err)
+	}
+
+	return &AppContainers{
+		BundleID:       bundleID,
+		Bundle:         strings.TrimSpace(string(bundle)),
+		Data:           strings.TrimSpace(string(data)),
+		Groups:         parseAppGroupContainers(string(groups)),
+		DataAccessible: true,
+	}, nil
+}
+
+// parseAppGroupContainers parses the "<group>\t<path>" lines printed by
+// simctl get_app_container for the groups container
+func parseAppGroupContainers(output string) map[string]string {
+	var groups map[string]string
+	for _, line := range strings.Split(output, "\n") {
+		group, path, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		if groups == nil {
+			groups = make(map[string]string)
+		}
+		groups[strings.TrimSpace(group)] = strings.TrimSpace(path)
+	}
+	return groups
+}
+
 func (s *SimulatorDevice) ListFiles(bundleID, remotePath string) ([]FileEntry, error) {
 	if remotePath == "" {
 		if bundleID != "" {
@@ -0,0 +1,21 @@
+package devices
+
+import "fmt"
+
+// AddMedia adds photos and videos to the simulator's photo library
+func (s *SimulatorDevice) AddMedia(paths []string) error {
+	args := append([]string{"addmedia", s.UDID}, paths...)
+	if _, err := runSimctl(args...); err != nil {
+		return fmt.Errorf("failed to add media: %w", err)
+	}
+	return nil
+}
+
+// AddContacts imports the contacts of a vCard file into the simulator's
+// address book
+func (s *SimulatorDevice) AddContacts(vcfPath string) error {
+	if _, err := runSimctl("addmedia", s.UDID, vcfPath); err != nil {
+		return fmt.Errorf("failed to add contacts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package devices
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const simulatorLaunchctlOutput = `PID	Status	Label
+-	0	com.apple.Maps.mapspushd
+4321	0	UIKitApplication:com.example.app[9a7c][rb-legacy]
+-	0	UIKitApplication:com.example.stopped[1f2e][rb-legacy]
+5432	0	UIKitApplication:com.apple.mobilesafari[77aa][rb-legacy]
+678	0	com.apple.backboardd
+`
+
+func TestParseSimulatorRunningApps(t *testing.T) {
+	apps := parseSimulatorRunningApps(simulatorLaunchctlOutput, "com.apple.mobilesafari")
+	require.Len(t, apps, 2)
+
+	assert.Equal(t, "com.apple.mobilesafari", apps[0].PackageName)
+	assert.Equal(t, ProcessStateForeground, apps[0].State)
+	assert.Equal(t, 5432, apps[0].Processes[0].PID)
+
+	assert.Equal(t, "com.example.app", apps[1].PackageName)
+	assert.Equal(t, ProcessStateBackground, apps[1].State)
+}
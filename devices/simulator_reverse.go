@@ -0,0 +1,23 @@
+package devices
+
+import "fmt"
+
+// ReversePort checks that an app can reach hostPort. Simulators share the
+// host's network, so localhost on a simulator is the host and every port is
+// reachable already, but only on the same port.
+func (s *SimulatorDevice) ReversePort(devicePort, hostPort int) error {
+	if devicePort != hostPort {
+		return fmt.Errorf("simulators share the host's network, so apps reach port %d at localhost:%d and it can't be mapped to port %d", hostPort, hostPort, devicePort)
+	}
+	return nil
+}
+
+// RemoveReverse does nothing, simulators reach the host's ports directly
+func (s *SimulatorDevice) RemoveReverse(devicePort int) error {
+	return nil
+}
+
+// ListReverses lists nothing, simulators reach the host's ports directly
+func (s *SimulatorDevice) ListReverses() ([]PortReverse, error) {
+	return []PortReverse{}, nil
+}
@@ -0,0 +1,16 @@
+package devices
+
+import "fmt"
+
+// SetSensor fails, simulators have no way to inject CoreMotion readings
+func (s *SimulatorDevice) SetSensor(name string, values []float64) error {
+	return fmt.Errorf("iOS simulators can't simulate sensor readings, only shake")
+}
+
+// Shake sends the shake gesture of the Simulator's Device menu
+func (s *SimulatorDevice) Shake() error {
+	if output, err := runSimctl("spawn", s.UDID, "notifyutil", "-p", "com.apple.UIKit.SimulatorShake"); err != nil {
+		return fmt.Errorf("failed to shake simulator: %w: %s", err, output)
+	}
+	return nil
+}
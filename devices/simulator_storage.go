@@ -0,0 +1,60 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// dataDir is the simulator's data volume, a directory on the host's disk
+func (s *SimulatorDevice) dataDir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, "Library", "Developer", "CoreSimulator", "Devices", s.UDID, "data"), nil
+}
+
+// StorageInfo reports the space of the simulator's data volume, which is the
+// space of the host's disk
+func (s *SimulatorDevice) StorageInfo() ([]StorageVolume, error) {
+	dir, err := s.dataDir()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := utils.CombinedOutput(exec.Command("df", "-k", dir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage info: %w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	volumes, err := parseDfOutput(string(output))
+	if err != nil {
+		return nil, err
+	}
+	volumes[0].Path = dir
+	return volumes, nil
+}
+
+// FillStorage refuses to fill a simulator: its data volume is the host's
+// disk, so filling it would fill the automation host instead
+func (s *SimulatorDevice) FillStorage(leaveFree int64) (*StorageFillResult, error) {
+	return nil, fmt.Errorf("filling storage is not supported on iOS simulators, their storage is the host's disk")
+}
+
+// FreeStorage removes the filler file
+func (s *SimulatorDevice) FreeStorage() error {
+	dir, err := s.dataDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(filepath.Join(dir, storageFillerName)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove filler file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package devices
+
+import "testing"
+
+func TestSimulatorDetails(t *testing.T) {
+	runtimes, err := parseSimulatorRuntimes([]byte(`{"runtimes": [
+		{"identifier": "com.apple.CoreSimulator.SimRuntime.iOS-18-2", "isAvailable": true},
+		{"identifier": "com.apple.CoreSimulator.SimRuntime.iOS-16-4", "isAvailable": false, "availabilityError": "requires Xcode 14.3"}
+	]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sim := Simulator{UDID: "unknown-udid", DeviceType: "com.apple.CoreSimulator.SimDeviceType.iPhone-16", Runtime: "com.apple.CoreSimulator.SimRuntime.iOS-18-2"}
+	details := simulatorDetails(sim, runtimes)
+	if !details.RuntimeInstalled || !details.RuntimeAvailable || details.RuntimeError != "" || details.BootedOnce {
+		t.Errorf("unexpected details: %+v", details)
+	}
+	if details.DeviceType != sim.DeviceType {
+		t.Errorf("expected device type %s, got %s", sim.DeviceType, details.DeviceType)
+	}
+
+	sim.Runtime = "com.apple.CoreSimulator.SimRuntime.iOS-16-4"
+	details = simulatorDetails(sim, runtimes)
+	if !details.RuntimeInstalled || details.RuntimeAvailable || details.RuntimeError != "requires Xcode 14.3" {
+		t.Errorf("unexpected details: %+v", details)
+	}
+
+	sim.Runtime = "com.apple.CoreSimulator.SimRuntime.iOS-15-0"
+	details = simulatorDetails(sim, runtimes)
+	if details.RuntimeInstalled || details.RuntimeAvailable {
+		t.Errorf("expected a deleted runtime to be reported as not installed: %+v", details)
+	}
+}
+
+func TestParseAppGroupContainers(t *testing.T) {
+	groups := parseAppGroupContainers("group.com.example.shared\t/data/Containers/Shared/AppGroup/A1\ngroup.com.example.widgets\t/data/Containers/Shared/AppGroup/B2\n")
+	if len(groups) != 2 || groups["group.com.example.shared"] != "/data/Containers/Shared/AppGroup/A1" || groups["group.com.example.widgets"] != "/data/Containers/Shared/AppGroup/B2" {
+		t.Errorf("unexpected groups: %v", groups)
+	}
+
+	if groups := parseAppGroupContainers("\n"); groups != nil {
+		t.Errorf("expected no groups, got %v", groups)
+	}
+}
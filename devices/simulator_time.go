@@ -0,0 +1,24 @@
+package devices
+
+import (
+	"fmt"
+	"time"
+)
+
+// SetTime overrides the time shown in the status bar. Simulators run on the
+// host's clock, which can't be changed per simulator, so the time apps read
+// is unchanged.
+func (s *SimulatorDevice) SetTime(t time.Time) (string, error) {
+	if _, err := runSimctl("status_bar", s.UDID, "override", "--time", t.Format(time.RFC3339)); err != nil {
+		return "", fmt.Errorf("failed to override status bar time: %w", err)
+	}
+	return ClockScopeStatusBar, nil
+}
+
+// SyncTime clears the status bar overrides, showing the host's time again
+func (s *SimulatorDevice) SyncTime() error {
+	if _, err := runSimctl("status_bar", s.UDID, "clear"); err != nil {
+		return fmt.Errorf("failed to clear status bar overrides: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// RunXCTest runs UI tests with xcodebuild test-without-building, parsing the
+// results from its output as they stream in. With a runner app instead of an
+// .xctestrun, one is written for it; xcodebuild installs both apps.
+func (s *SimulatorDevice) RunXCTest(config XCTestConfig) (*TestResults, error) {
+	xctestrun := config.XCTestRun
+	if xctestrun == "" {
+		if config.RunnerApp == "" {
+			return nil, fmt.Errorf("an .xctestrun file or a test runner app is required")
+		}
+
+		dir, err := os.MkdirTemp("", "mobilecli-xctest-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp directory: %w", err)
+		}
+		defer func() { _ = os.RemoveAll(dir) }()
+
+		xctestrun, err = writeRunnerXCTestRun(dir, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	onlyTesting, err := xctestOnlyTesting(xctestrun, config.OnlyTesting)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"test-without-building", "-xctestrun", xctestrun, "-destination", "id=" + s.UDID}
+	for _, identifier := range onlyTesting {
+		args = append(args, "-only-testing:"+identifier)
+	}
+
+	utils.Verbose("Running: xcodebuild %s", strings.Join(args, " "))
+	parser := newXCTestParser(config.OnEvent)
+	cmd := exec.Command("xcodebuild", args...)
+	cmd.Stdout = parser
+	cmd.Stderr = parser
+
+	startedAt := time.Now()
+	if err := utils.Start(cmd); err != nil {
+		return nil, fmt.Errorf("failed to run xcodebuild: %w", err)
+	}
+	exitErr := cmd.Wait()
+
+	return parser.finish(xctestrun, time.Since(startedAt).Milliseconds(), exitErr), nil
+}
+
+// xctestOnlyTesting prefixes tests with the test target of the .xctestrun, as
+// xcodebuild's -only-testing wants them, when it has a single target
+func xctestOnlyTesting(xctestrun string, tests []string) ([]string, error) {
+	if len(tests) == 0 {
+		return nil, nil
+	}
+
+	targets, err := xctestrunTargets(xctestrun)
+	if err != nil {
+		return nil, err
+	}
+
+	identifiers := make([]string, 0, len(tests))
+	for _, test := range tests {
+		identifier := normalizeXCTestIdentifier(test)
+		if len(targets) == 1 && !strings.HasPrefix(identifier, targets[0]+"/") {
+			identifier = targets[0] + "/" + identifier
+		}
+		identifiers = append(identifiers, identifier)
+	}
+	return identifiers, nil
+}
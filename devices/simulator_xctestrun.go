@@ -0,0 +1,185 @@
+package devices
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// SimulatorWDAEnvVar selects where simulators get their agent from, taking
+// precedence over simulator_wda in config.json. Unset in both, the
+// DeviceKit runner installed with 'mobilecli agent install' is launched. Set to
+// a .xctestrun file, WebDriverAgent is run from it with xcodebuild
+// test-without-building. Set to a WebDriverAgent checkout, it is built for
+// testing once with xcodebuild and then run the same way. Neither needs a
+// download, for hosts behind firewalls or iOS versions without a release yet.
+const SimulatorWDAEnvVar = "MOBILECLI_SIMULATOR_WDA"
+
+// wdaScheme is the scheme of a WebDriverAgent checkout that builds the runner
+const wdaScheme = "WebDriverAgentRunner"
+
+// simulatorWDASource is where StartAgent runs WebDriverAgent from when
+// SimulatorWDAEnvVar is set. Exactly one of xctestrun and project is set.
+type simulatorWDASource struct {
+	xctestrun string
+	project   string
+}
+
+// simulatorWDAConfig reads SimulatorWDAEnvVar, or else simulator_wda in
+// config.json, returning nil when neither is set
+func simulatorWDAConfig() (*simulatorWDASource, error) {
+	source := SimulatorWDAEnvVar
+	path := strings.TrimSpace(os.Getenv(SimulatorWDAEnvVar))
+	if path == "" {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			return nil, err
+		}
+		source, path = "simulator_wda in config.json", strings.TrimSpace(config.SimulatorWDA)
+	}
+	if path == "" {
+		return nil, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+
+	if !info.IsDir() {
+		if filepath.Ext(path) != ".xctestrun" {
+			return nil, fmt.Errorf("invalid %s '%s', expected a .xctestrun file or a WebDriverAgent checkout", source, path)
+		}
+		return &simulatorWDASource{xctestrun: path}, nil
+	}
+
+	project := filepath.Join(path, "WebDriverAgent.xcodeproj")
+	if _, err := os.Stat(project); err != nil {
+		return nil, fmt.Errorf("invalid %s '%s', no WebDriverAgent.xcodeproj found in it", source, path)
+	}
+
+	return &simulatorWDASource{project: project}, nil
+}
+
+// wdaBuildDir returns the cache directory a checkout is built into and the
+// runner's logs are written to
+func wdaBuildDir(project string) (string, error) {
+	cacheHome, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(project))
+	dir := filepath.Join(cacheHome, "mobilecli", "wda", hex.EncodeToString(sum[:])[:12])
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// findSimulatorXCTestRun returns the simulator .xctestrun a build-for-testing
+// left in derivedData, or "" when there is none
+func findSimulatorXCTestRun(derivedData string) string {
+	matches, _ := filepath.Glob(filepath.Join(derivedData, "Build", "Products", "*iphonesimulator*.xctestrun"))
+	if len(matches) == 0 {
+		return ""
+	}
+	return matches[0]
+}
+
+// resolveXCTestRun returns the .xctestrun to run, building the checkout for
+// testing when it hasn't been built yet
+func (s *SimulatorDevice) resolveXCTestRun(source *simulatorWDASource, onProgress func(string)) (string, error) {
+	if source.xctestrun != "" {
+		return source.xctestrun, nil
+	}
+
+	buildDir, err := wdaBuildDir(source.project)
+	if err != nil {
+		return "", err
+	}
+
+	derivedData := filepath.Join(buildDir, "DerivedData")
+	if xctestrun := findSimulatorXCTestRun(derivedData); xctestrun != "" {
+		utils.Verbose("Using prebuilt %s", xctestrun)
+		return xctestrun, nil
+	}
+
+	if onProgress != nil {
+		onProgress("Building WebDriverAgent")
+	}
+
+	utils.Verbose("Building %s for testing into %s", source.project, derivedData)
+	cmd := exec.Command("xcodebuild", "build-for-testing",
+		"-project", source.project,
+		"-scheme", wdaScheme,
+		"-destination", "id="+s.UDID,
+		"-derivedDataPath", derivedData,
+	)
+	if output, err := utils.CombinedOutput(cmd); err != nil {
+		return "", fmt.Errorf("failed to build WebDriverAgent: %w\n%s", err, lastLines(string(output), bootOutputLines))
+	}
+
+	xctestrun := findSimulatorXCTestRun(derivedData)
+	if xctestrun == "" {
+		return "", fmt.Errorf("xcodebuild build-for-testing produced no simulator .xctestrun in %s", derivedData)
+	}
+	return xctestrun, nil
+}
+
+// startXCTestRunAgent runs WebDriverAgent with xcodebuild test-without-building
+// on port. xcodebuild is detached and writes to a log file, so the agent keeps
+// running after mobilecli exits, like the DeviceKit runner does.
+func (s *SimulatorDevice) startXCTestRunAgent(source *simulatorWDASource, port int, onProgress func(string)) (*os.Process, string, error) {
+	xctestrun, err := s.resolveXCTestRun(source, onProgress)
+	if err != nil {
+		return nil, "", err
+	}
+
+	logDir, err := wdaBuildDir(xctestrun)
+	if err != nil {
+		return nil, "", err
+	}
+	logPath := filepath.Join(logDir, s.UDID+".log")
+
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	utils.Verbose("Running %s with USE_PORT=%d, logging to %s", xctestrun, port, logPath)
+
+	// xcodebuild passes TEST_RUNNER_ variables to the runner without the prefix
+	cmd := exec.Command("xcodebuild", "test-without-building",
+		"-xctestrun", xctestrun,
+		"-destination", "id="+s.UDID,
+	)
+	cmd.Env = append(os.Environ(), "TEST_RUNNER_USE_PORT="+strconv.Itoa(port))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	utils.ConfigureDetachedProcAttr(cmd)
+
+	if err := utils.Start(cmd); err != nil {
+		return nil, "", fmt.Errorf("failed to run xcodebuild: %w", err)
+	}
+
+	// reap xcodebuild if it exits while mobilecli is still running
+	go func() { _ = cmd.Wait() }()
+
+	return cmd.Process, logPath, nil
+}
+
+// lastLines returns up to n trailing non-empty lines of output
+func lastLines(output string, n int) string {
+	tail := newOutputTail(n)
+	_, _ = tail.Write([]byte(output))
+	return tail.String()
+}
@@ -0,0 +1,83 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatorWDAConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(SimulatorWDAEnvVar, "")
+	source, err := simulatorWDAConfig()
+	require.NoError(t, err)
+	assert.Nil(t, source)
+
+	dir := t.TempDir()
+	xctestrun := filepath.Join(dir, "WebDriverAgentRunner_iphonesimulator18.0-arm64.xctestrun")
+	require.NoError(t, os.WriteFile(xctestrun, []byte("<plist/>"), 0o644))
+
+	t.Setenv(SimulatorWDAEnvVar, xctestrun)
+	source, err = simulatorWDAConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &simulatorWDASource{xctestrun: xctestrun}, source)
+
+	// a directory without WebDriverAgent.xcodeproj isn't a checkout
+	t.Setenv(SimulatorWDAEnvVar, dir)
+	_, err = simulatorWDAConfig()
+	assert.Error(t, err)
+
+	project := filepath.Join(dir, "WebDriverAgent.xcodeproj")
+	require.NoError(t, os.Mkdir(project, 0o755))
+	source, err = simulatorWDAConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &simulatorWDASource{project: project}, source)
+
+	t.Setenv(SimulatorWDAEnvVar, filepath.Join(dir, "missing.xctestrun"))
+	_, err = simulatorWDAConfig()
+	assert.Error(t, err)
+}
+
+func TestSimulatorWDAConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(SimulatorWDAEnvVar, "")
+
+	dir := t.TempDir()
+	xctestrun := filepath.Join(dir, "WebDriverAgentRunner_iphonesimulator18.0-arm64.xctestrun")
+	require.NoError(t, os.WriteFile(xctestrun, []byte("<plist/>"), 0o644))
+
+	configPath, err := utils.ConfigFilePath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(configPath), 0o755))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"simulator_wda": "`+xctestrun+`"}`), 0o644))
+
+	source, err := simulatorWDAConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &simulatorWDASource{xctestrun: xctestrun}, source)
+
+	// the environment variable takes precedence
+	project := filepath.Join(dir, "WebDriverAgent.xcodeproj")
+	require.NoError(t, os.Mkdir(project, 0o755))
+	t.Setenv(SimulatorWDAEnvVar, dir)
+	source, err = simulatorWDAConfig()
+	require.NoError(t, err)
+	assert.Equal(t, &simulatorWDASource{project: project}, source)
+}
+
+func TestFindSimulatorXCTestRun(t *testing.T) {
+	derivedData := t.TempDir()
+	assert.Empty(t, findSimulatorXCTestRun(derivedData))
+
+	products := filepath.Join(derivedData, "Build", "Products")
+	require.NoError(t, os.MkdirAll(products, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(products, "WebDriverAgentRunner_iphoneos18.0-arm64.xctestrun"), nil, 0o644))
+	assert.Empty(t, findSimulatorXCTestRun(derivedData))
+
+	simulator := filepath.Join(products, "WebDriverAgentRunner_iphonesimulator18.0-arm64.xctestrun")
+	require.NoError(t, os.WriteFile(simulator, nil, 0o644))
+	assert.Equal(t, simulator, findSimulatorXCTestRun(derivedData))
+}
@@ -0,0 +1,70 @@
+package devices
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StorageVolume is the space of a mounted volume
+type StorageVolume struct {
+	Path       string `json:"path"`
+	TotalBytes int64  `json:"totalBytes"`
+	UsedBytes  int64  `json:"usedBytes"`
+	FreeBytes  int64  `json:"freeBytes"`
+}
+
+// StorageFillResult describes the filler file written by FillStorage
+type StorageFillResult struct {
+	Path        string `json:"path"`
+	FilledBytes int64  `json:"filledBytes"`
+	FreeBytes   int64  `json:"freeBytes"`
+}
+
+// storageFillerName is the file FillStorage writes and FreeStorage removes
+const storageFillerName = "mobilecli-storage-filler"
+
+// parseDfOutput parses 'df -k' output, one volume per line after the header:
+// filesystem, 1K-blocks, used, available, use% and mount point
+func parseDfOutput(output string) ([]StorageVolume, error) {
+	var volumes []StorageVolume
+	for i, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Fields(line)
+		if i == 0 || len(fields) < 6 {
+			continue
+		}
+
+		var kb [3]int64
+		for j := range kb {
+			v, err := strconv.ParseInt(fields[j+1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected df line %q", line)
+			}
+			kb[j] = v
+		}
+
+		volumes = append(volumes, StorageVolume{
+			// macOS df has extra inode columns, the mount point is always last
+			Path:       fields[len(fields)-1],
+			TotalBytes: kb[0] * 1024,
+			UsedBytes:  kb[1] * 1024,
+			FreeBytes:  kb[2] * 1024,
+		})
+	}
+
+	if len(volumes) == 0 {
+		return nil, fmt.Errorf("no volumes in df output")
+	}
+	return volumes, nil
+}
+
+// fillerSize returns how many bytes to write so that leaveFree bytes remain
+func fillerSize(free, leaveFree int64) (int64, error) {
+	if leaveFree < 0 {
+		return 0, fmt.Errorf("bytes to leave free must be non-negative")
+	}
+	if free <= leaveFree {
+		return 0, fmt.Errorf("only %d bytes free, already at or below %d", free, leaveFree)
+	}
+	return free - leaveFree, nil
+}
@@ -0,0 +1,44 @@
+package devices
+
+import "testing"
+
+func TestParseDfOutput(t *testing.T) {
+	android := `Filesystem     1K-blocks    Used Available Use% Mounted on
+/dev/block/dm-5  5998096 2561212   3420500  43% /data
+/dev/fuse        5998096 2561212   3420500  43% /storage/emulated
+`
+	volumes, err := parseDfOutput(android)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(volumes) != 2 {
+		t.Fatalf("expected 2 volumes, got %+v", volumes)
+	}
+	if volumes[0] != (StorageVolume{Path: "/data", TotalBytes: 5998096 * 1024, UsedBytes: 2561212 * 1024, FreeBytes: 3420500 * 1024}) {
+		t.Errorf("unexpected volume: %+v", volumes[0])
+	}
+
+	macOS := `Filesystem     1024-blocks      Used Available Capacity iused      ifree %iused  Mounted on
+/dev/disk3s5     482797652 341264796 118812264    75% 2470311 1188122640    0%   /System/Volumes/Data
+`
+	volumes, err = parseDfOutput(macOS)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if volumes[0].Path != "/System/Volumes/Data" || volumes[0].FreeBytes != 118812264*1024 {
+		t.Errorf("unexpected volume: %+v", volumes[0])
+	}
+
+	if _, err := parseDfOutput("df: /data: No such file or directory"); err == nil {
+		t.Errorf("expected an error without volumes")
+	}
+}
+
+func TestFillerSize(t *testing.T) {
+	if size, err := fillerSize(1000, 100); err != nil || size != 900 {
+		t.Errorf("fillerSize(1000, 100) = %d, %v", size, err)
+	}
+	if _, err := fillerSize(100, 100); err == nil {
+		t.Errorf("expected an error when already at the free space to leave")
+	}
+}
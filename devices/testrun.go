@@ -0,0 +1,77 @@
+package devices
+
+// test statuses of TestCaseResult
+const (
+	TestStatusRunning = "running"
+	TestStatusPassed  = "passed"
+	TestStatusFailed  = "failed"
+	TestStatusIgnored = "ignored"
+	TestStatusSkipped = "skipped"
+)
+
+// TestCaseResult is the outcome of a single test: passed, failed, ignored or
+// skipped (a failed assumption, or XCTSkip)
+type TestCaseResult struct {
+	Class    string `json:"class"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Duration int64  `json:"durationMs"`
+	Failure  string `json:"failure,omitempty"` // the failure message or stack trace
+}
+
+// TestEvent is a test starting or finishing, as a test run streams them
+type TestEvent struct {
+	Event string         `json:"event"` // "started" or "finished"
+	Test  TestCaseResult `json:"test"`
+}
+
+// TestResults is the outcome of a run of instrumentation or XCTest tests
+type TestResults struct {
+	Runner   string           `json:"runner"`
+	Tests    []TestCaseResult `json:"tests"`
+	Total    int              `json:"total"`
+	Passed   int              `json:"passed"`
+	Failed   int              `json:"failed"`
+	Skipped  int              `json:"skipped"`
+	Duration int64            `json:"durationMs"`
+
+	// Complete is false when the run ended early, e.g. because the app
+	// under test crashed, with Message saying why
+	Complete bool   `json:"complete"`
+	Message  string `json:"message,omitempty"`
+}
+
+// countTests fills in the totals of the results from their tests, with total
+// the number of tests the run announced, if more
+func (r *TestResults) countTests(total int) {
+	if r.Tests == nil {
+		r.Tests = []TestCaseResult{}
+	}
+
+	r.Passed, r.Failed, r.Skipped = 0, 0, 0
+	for _, test := range r.Tests {
+		switch test.Status {
+		case TestStatusPassed:
+			r.Passed++
+		case TestStatusFailed:
+			r.Failed++
+		default:
+			r.Skipped++
+		}
+	}
+	r.Total = max(total, len(r.Tests))
+}
+
+// testStarted reports a test starting to onEvent, when set
+func testStarted(onEvent func(TestEvent), class, name string) {
+	if onEvent != nil {
+		onEvent(TestEvent{Event: "started", Test: TestCaseResult{Class: class, Name: name, Status: TestStatusRunning}})
+	}
+}
+
+// testFinished reports a test finishing to onEvent, when set
+func testFinished(onEvent func(TestEvent), test TestCaseResult) {
+	if onEvent != nil {
+		onEvent(TestEvent{Event: "finished", Test: test})
+	}
+}
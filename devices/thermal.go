@@ -0,0 +1,272 @@
+package devices
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// Thermal statuses, as Android's thermal service reports them
+const (
+	ThermalStatusNone      = "none"
+	ThermalStatusLight     = "light"
+	ThermalStatusModerate  = "moderate"
+	ThermalStatusSevere    = "severe"
+	ThermalStatusCritical  = "critical"
+	ThermalStatusEmergency = "emergency"
+	ThermalStatusShutdown  = "shutdown"
+)
+
+// thermalStatuses are the thermal statuses by Android's numeric value
+var thermalStatuses = []string{
+	ThermalStatusNone,
+	ThermalStatusLight,
+	ThermalStatusModerate,
+	ThermalStatusSevere,
+	ThermalStatusCritical,
+	ThermalStatusEmergency,
+	ThermalStatusShutdown,
+}
+
+// ThermalReading is a device's battery temperature and thermal status
+type ThermalReading struct {
+	// BatteryTemperature is in degrees Celsius
+	BatteryTemperature float64 `json:"batteryTemperature"`
+
+	// ThermalStatus is one of the ThermalStatus* values, empty when the
+	// device doesn't report one
+	ThermalStatus string `json:"thermalStatus,omitempty"`
+}
+
+// throttling reports whether the thermal status is severe enough for the
+// device to be throttling
+func (r ThermalReading) throttling() bool {
+	for i, status := range thermalStatuses {
+		if status == r.ThermalStatus {
+			return i >= 3
+		}
+	}
+	return false
+}
+
+// Thermal guard events, see ThermalGuard
+const (
+	ThermalEventWarning = "thermal-warning"
+	ThermalEventPaused  = "thermal-paused"
+	ThermalEventResumed = "thermal-resumed"
+	ThermalEventCooled  = "thermal-cooled"
+)
+
+// ThermalEvent is reported by a ThermalGuard when a device gets hot or cools
+// down again
+type ThermalEvent struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	ThermalReading
+}
+
+// Thermal guard defaults
+const (
+	DefaultMaxTemperature  = 45.0
+	DefaultThermalInterval = 30 * time.Second
+
+	// thermalHysteresis is how far below MaxTemperature a device must cool
+	// down before a paused capture resumes
+	thermalHysteresis = 2.0
+)
+
+// ThermalGuard watches a device's temperature during a screen capture. A
+// device is hot when its battery reaches MaxTemperature, or its thermal
+// status is severe or worse. A hot device raises a warning, or with Pause
+// drops frames until it has cooled down.
+type ThermalGuard struct {
+	MaxTemperature float64       // 0 for DefaultMaxTemperature
+	Interval       time.Duration // 0 for DefaultThermalInterval
+	Pause          bool
+	OnEvent        func(event ThermalEvent) // optional, receives every event
+}
+
+// mjpegPartBoundary starts every part of the agents' MJPEG streams. MJPEG
+// captures pause, resume and report events there, so frames stay whole and
+// notifications written between parts don't split one.
+var mjpegPartBoundary = []byte("--BoundaryString")
+
+// thermalMonitor is the state of a ThermalGuard during one capture
+type thermalMonitor struct {
+	guard  ThermalGuard
+	reader ThermalReader
+	hot    bool
+
+	mu      sync.Mutex
+	paused  bool
+	pending []ThermalEvent
+}
+
+// sample reads the device's temperature and returns the event it causes, if any
+func (m *thermalMonitor) sample(now time.Time) (*ThermalEvent, error) {
+	reading, err := m.reader.ReadThermal()
+	if err != nil {
+		return nil, err
+	}
+
+	maxTemperature := m.guard.MaxTemperature
+	if maxTemperature == 0 {
+		maxTemperature = DefaultMaxTemperature
+	}
+
+	event := ThermalEvent{Time: now, ThermalReading: *reading}
+	switch {
+	case !m.hot && (reading.BatteryTemperature >= maxTemperature || reading.throttling()):
+		m.hot = true
+		event.Event = ThermalEventWarning
+		if m.guard.Pause {
+			event.Event = ThermalEventPaused
+		}
+	case m.hot && reading.BatteryTemperature < maxTemperature-thermalHysteresis && !reading.throttling():
+		m.hot = false
+		event.Event = ThermalEventCooled
+		if m.guard.Pause {
+			event.Event = ThermalEventResumed
+		}
+	default:
+		return nil, nil
+	}
+	return &event, nil
+}
+
+// apply pauses or resumes the capture for an event and reports it
+func (m *thermalMonitor) apply(event ThermalEvent) {
+	m.mu.Lock()
+	switch event.Event {
+	case ThermalEventPaused:
+		m.paused = true
+	case ThermalEventResumed:
+		m.paused = false
+	}
+	m.mu.Unlock()
+
+	if m.guard.OnEvent != nil {
+		m.guard.OnEvent(event)
+	}
+}
+
+// isPaused reports whether frames are being dropped
+func (m *thermalMonitor) isPaused() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.paused
+}
+
+// queue holds an event until the capture reaches a part boundary
+func (m *thermalMonitor) queue(event ThermalEvent) {
+	m.mu.Lock()
+	m.pending = append(m.pending, event)
+	m.mu.Unlock()
+}
+
+// takePending returns the queued events
+func (m *thermalMonitor) takePending() []ThermalEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := m.pending
+	m.pending = nil
+	return pending
+}
+
+// mjpegOnData wraps an MJPEG capture's OnData, applying queued events and
+// dropping frames while paused, both at part boundaries only
+func (m *thermalMonitor) mjpegOnData(onData func([]byte) bool) func([]byte) bool {
+	return func(data []byte) bool {
+		i := bytes.Index(data, mjpegPartBoundary)
+		if i < 0 {
+			if m.isPaused() {
+				return true
+			}
+			return onData(data)
+		}
+
+		if i > 0 && !m.isPaused() && !onData(data[:i]) {
+			return false
+		}
+		for _, event := range m.takePending() {
+			m.apply(event)
+		}
+		if m.isPaused() {
+			return true
+		}
+		return onData(data[i:])
+	}
+}
+
+// StartGuardedScreenCapture starts a screen capture with config.Thermal
+// watching the device's temperature. Devices that can't report it capture
+// unguarded, with a progress message saying so.
+func StartGuardedScreenCapture(device ControllableDevice, config ScreenCaptureConfig) error {
+	if config.Thermal == nil {
+		return device.StartScreenCapture(config)
+	}
+
+	reader, ok := device.(ThermalReader)
+	if !ok {
+		if config.OnProgress != nil {
+			config.OnProgress(fmt.Sprintf("thermal monitoring is not supported on %s %s devices", device.Platform(), device.DeviceType()))
+		}
+		return device.StartScreenCapture(config)
+	}
+
+	monitor := &thermalMonitor{guard: *config.Thermal, reader: reader}
+	interval := monitor.guard.Interval
+	if interval == 0 {
+		interval = DefaultThermalInterval
+	}
+
+	ctx, cancel := context.WithCancel(config.context())
+	defer cancel()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			event, err := monitor.sample(time.Now())
+			if err != nil {
+				utils.Verbose("failed to read temperature of device %s: %v", device.ID(), err)
+			} else if event != nil {
+				utils.Verbose("device %s: %s at %.1f°C", device.ID(), event.Event, event.BatteryTemperature)
+				if config.Format == "mjpeg" {
+					monitor.queue(*event)
+				} else {
+					monitor.apply(*event)
+					if event.Event == ThermalEventResumed {
+						// frames were dropped, decoders need a key frame to resume
+						_ = RequestAvcKeyFrame(device)
+					}
+				}
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	onData := config.OnData
+	config.Context = ctx
+	if config.Format == "mjpeg" {
+		config.OnData = monitor.mjpegOnData(onData)
+	} else {
+		config.OnData = func(data []byte) bool {
+			if monitor.isPaused() {
+				return true
+			}
+			return onData(data)
+		}
+	}
+	return device.StartScreenCapture(config)
+}
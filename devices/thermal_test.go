@@ -0,0 +1,82 @@
+package devices
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubThermalReader returns the readings it is given, one per call
+type stubThermalReader struct {
+	readings []ThermalReading
+}
+
+func (r *stubThermalReader) ReadThermal() (*ThermalReading, error) {
+	reading := r.readings[0]
+	r.readings = r.readings[1:]
+	return &reading, nil
+}
+
+func TestParseBatteryTemperature(t *testing.T) {
+	output := "Current Battery Service state:\n  AC powered: false\n  level: 87\n  temperature: 312\n  technology: Li-ion\n"
+	temperature, err := parseBatteryTemperature(output)
+	require.NoError(t, err)
+	assert.InDelta(t, 31.2, temperature, 0.001)
+
+	_, err = parseBatteryTemperature("Current Battery Service state:\n")
+	assert.Error(t, err)
+}
+
+func TestParseThermalStatus(t *testing.T) {
+	assert.Equal(t, ThermalStatusSevere, parseThermalStatus("IsStatusOverride: false\nThermal Status: 3\nCached temperatures:\n"))
+	assert.Equal(t, "", parseThermalStatus("Can't find service: thermalservice\n"))
+	assert.Equal(t, "", parseThermalStatus("Thermal Status: 9\n"))
+}
+
+func TestThermalMonitorSample(t *testing.T) {
+	reader := &stubThermalReader{readings: []ThermalReading{
+		{BatteryTemperature: 40},
+		{BatteryTemperature: 45.5},
+		{BatteryTemperature: 44},
+		{BatteryTemperature: 42.5},
+		{BatteryTemperature: 30, ThermalStatus: ThermalStatusCritical},
+	}}
+	monitor := &thermalMonitor{guard: ThermalGuard{Pause: true}, reader: reader}
+
+	var events []string
+	for range 5 {
+		event, err := monitor.sample(time.Now())
+		require.NoError(t, err)
+		if event != nil {
+			events = append(events, event.Event)
+		}
+	}
+
+	// 44 degrees is still within the hysteresis
+	assert.Equal(t, []string{ThermalEventPaused, ThermalEventResumed, ThermalEventPaused}, events)
+}
+
+func TestThermalMonitorPausesMJPEGAtBoundaries(t *testing.T) {
+	var reported []string
+	monitor := &thermalMonitor{guard: ThermalGuard{Pause: true, OnEvent: func(event ThermalEvent) {
+		reported = append(reported, event.Event)
+	}}}
+
+	var out []byte
+	onData := monitor.mjpegOnData(func(data []byte) bool {
+		out = append(out, data...)
+		return true
+	})
+
+	onData([]byte("--BoundaryString\r\nframe1-"))
+	monitor.queue(ThermalEvent{Event: ThermalEventPaused})
+	onData([]byte("end--BoundaryString\r\nframe2"))
+	onData([]byte("-end"))
+	monitor.queue(ThermalEvent{Event: ThermalEventResumed})
+	onData([]byte("more--BoundaryString\r\nframe3"))
+
+	assert.Equal(t, "--BoundaryString\r\nframe1-end--BoundaryString\r\nframe3", string(out))
+	assert.Equal(t, []string{ThermalEventPaused, ThermalEventResumed}, reported)
+}
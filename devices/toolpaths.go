@@ -0,0 +1,202 @@
+package devices
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// Where a tool's path came from, see ToolPath
+const (
+	ToolSourceFlag   = "flag"   // --adb-path, --emulator-path or --xcrun-path
+	ToolSourceConfig = "config" // adb_path, emulator_path or xcrun_path in config.json
+	ToolSourceSDK    = "sdk"    // the Android SDK at ANDROID_HOME or its default location
+	ToolSourcePath   = "PATH"   // looked up in PATH when run
+)
+
+// ToolPaths overrides where mobilecli finds adb, the emulator and xcrun,
+// taking precedence over ANDROID_HOME, the default SDK locations and PATH.
+// With several SDKs installed, they pin the one to use. They are read from
+// config.json in mobilecli's configuration directory, e.g.
+//
+//	{"adb_path": "/opt/android-sdk/platform-tools/adb"}
+//
+// and given per invocation with --adb-path, --emulator-path and --xcrun-path.
+type ToolPaths struct {
+	Adb      string
+	Emulator string
+	Xcrun    string
+}
+
+// IsZero reports whether no tool is overridden
+func (p ToolPaths) IsZero() bool {
+	return p == ToolPaths{}
+}
+
+// ToolPath is the path a tool resolved to, and why
+type ToolPath struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Source string `json:"source"`
+
+	// Found is false when nothing exists at Path, or it isn't in PATH
+	Found bool `json:"found"`
+
+	// Others are further copies of the tool in the SDK or PATH, which
+	// weren't picked
+	Others []string `json:"others,omitempty"`
+}
+
+var (
+	toolPathFlags ToolPaths
+
+	toolPathConfigOnce sync.Once
+	toolPathConfig     ToolPaths
+)
+
+// SetToolPathFlags overrides tool paths for this invocation, taking
+// precedence over config.json
+func SetToolPathFlags(paths ToolPaths) {
+	toolPathFlags = paths
+}
+
+// ToolPathsOverridden reports whether this invocation overrides tool paths,
+// so its commands must not run in a daemon resolving them differently
+func ToolPathsOverridden() bool {
+	return !toolPathFlags.IsZero()
+}
+
+// loadToolPathConfig reads the tool paths of config.json once, which are
+// empty when it doesn't exist or can't be read
+func loadToolPathConfig() ToolPaths {
+	toolPathConfigOnce.Do(func() {
+		config, err := utils.LoadConfig()
+		if err != nil {
+			utils.Verbose("Ignoring config: %v", err)
+			return
+		}
+
+		toolPathConfig = ToolPaths{
+			Adb:      config.AdbPath,
+			Emulator: config.EmulatorPath,
+			Xcrun:    config.XcrunPath,
+		}
+	})
+	return toolPathConfig
+}
+
+// toolOverride returns the path a flag or config.json sets for a tool
+func toolOverride(name string, get func(ToolPaths) string) (ToolPath, bool) {
+	if path := get(toolPathFlags); path != "" {
+		return ToolPath{Name: name, Path: path, Source: ToolSourceFlag}, true
+	}
+	if path := get(loadToolPathConfig()); path != "" {
+		return ToolPath{Name: name, Path: path, Source: ToolSourceConfig}, true
+	}
+	return ToolPath{}, false
+}
+
+// sdkToolPath returns a tool of the Android SDK, e.g. platform-tools/adb
+func sdkToolPath(name, dir string) (ToolPath, bool) {
+	sdkPath := getAndroidSdkPath()
+	if sdkPath == "" {
+		return ToolPath{}, false
+	}
+
+	path := filepath.Join(sdkPath, dir, name)
+	if runtime.GOOS == "windows" {
+		path += ".exe"
+	}
+	return ToolPath{Name: name, Path: path, Source: ToolSourceSDK}, true
+}
+
+func resolveAdbPath() ToolPath {
+	if tool, ok := toolOverride("adb", func(p ToolPaths) string { return p.Adb }); ok {
+		return tool
+	}
+	if tool, ok := sdkToolPath("adb", "platform-tools"); ok {
+		return tool
+	}
+
+	// best effort, look in path
+	return ToolPath{Name: "adb", Path: "adb", Source: ToolSourcePath}
+}
+
+func resolveEmulatorPath() ToolPath {
+	if tool, ok := toolOverride("emulator", func(p ToolPaths) string { return p.Emulator }); ok {
+		return tool
+	}
+	if tool, ok := sdkToolPath("emulator", "emulator"); ok {
+		if _, err := os.Stat(tool.Path); err == nil {
+			return tool
+		}
+	}
+
+	// best effort, look in path
+	return ToolPath{Name: "emulator", Path: "emulator", Source: ToolSourcePath}
+}
+
+func resolveXcrunPath() ToolPath {
+	if tool, ok := toolOverride("xcrun", func(p ToolPaths) string { return p.Xcrun }); ok {
+		return tool
+	}
+	return ToolPath{Name: "xcrun", Path: "xcrun", Source: ToolSourcePath}
+}
+
+func getXcrunPath() string {
+	return resolveXcrunPath().Path
+}
+
+// ResolveToolPaths returns the paths adb, the emulator and xcrun resolve to,
+// whether they exist and which other copies were passed over
+func ResolveToolPaths() []ToolPath {
+	tools := []ToolPath{resolveAdbPath(), resolveEmulatorPath(), resolveXcrunPath()}
+	sdkDirs := map[string]string{"adb": "platform-tools", "emulator": "emulator"}
+
+	for i := range tools {
+		tool := &tools[i]
+		if tool.Source == ToolSourcePath {
+			if path, err := exec.LookPath(tool.Path); err == nil {
+				tool.Path = path
+				tool.Found = true
+			}
+		} else {
+			_, err := os.Stat(tool.Path)
+			tool.Found = err == nil
+		}
+
+		var candidates []string
+		if dir, ok := sdkDirs[tool.Name]; ok {
+			if sdkTool, ok := sdkToolPath(tool.Name, dir); ok {
+				candidates = append(candidates, sdkTool.Path)
+			}
+		}
+		if path, err := exec.LookPath(tool.Name); err == nil {
+			candidates = append(candidates, path)
+		}
+		for _, candidate := range candidates {
+			if sameFile(candidate, tool.Path) || slices.Contains(tool.Others, candidate) {
+				continue
+			}
+			if _, err := os.Stat(candidate); err == nil {
+				tool.Others = append(tool.Others, candidate)
+			}
+		}
+	}
+	return tools
+}
+
+// sameFile reports whether two paths are the same file, following symlinks
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return a == b
+	}
+	return os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,60 @@
+package devices
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useToolPathConfig makes config.json hold content, re-reading it
+func useToolPathConfig(t *testing.T, content string) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path, err := utils.ConfigFilePath()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	toolPathConfigOnce = sync.Once{}
+	toolPathConfig = ToolPaths{}
+	t.Cleanup(func() {
+		toolPathConfigOnce = sync.Once{}
+		toolPathConfig = ToolPaths{}
+		SetToolPathFlags(ToolPaths{})
+	})
+}
+
+func TestToolPathPrecedence(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SDK tools have an .exe suffix on windows")
+	}
+
+	sdk := t.TempDir()
+	sdkAdb := filepath.Join(sdk, "platform-tools", "adb")
+	require.NoError(t, os.MkdirAll(filepath.Dir(sdkAdb), 0o755))
+	require.NoError(t, os.WriteFile(sdkAdb, []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("ANDROID_HOME", sdk)
+
+	useToolPathConfig(t, `{}`)
+	assert.Equal(t, ToolPath{Name: "adb", Path: sdkAdb, Source: ToolSourceSDK}, resolveAdbPath())
+	assert.Equal(t, ToolPath{Name: "xcrun", Path: "xcrun", Source: ToolSourcePath}, resolveXcrunPath())
+
+	useToolPathConfig(t, `{"adb_path": "/opt/sdk/platform-tools/adb", "xcrun_path": "/opt/xcode/xcrun"}`)
+	assert.Equal(t, ToolPath{Name: "adb", Path: "/opt/sdk/platform-tools/adb", Source: ToolSourceConfig}, resolveAdbPath())
+	assert.Equal(t, "/opt/xcode/xcrun", getXcrunPath())
+
+	SetToolPathFlags(ToolPaths{Adb: "/usr/local/bin/adb"})
+	assert.True(t, ToolPathsOverridden())
+	assert.Equal(t, ToolPath{Name: "adb", Path: "/usr/local/bin/adb", Source: ToolSourceFlag}, resolveAdbPath())
+	assert.Equal(t, "/usr/local/bin/adb", getAdbPath())
+
+	tools := ResolveToolPaths()
+	require.Len(t, tools, 3)
+	assert.False(t, tools[0].Found)
+	assert.Contains(t, tools[0].Others, sdkAdb)
+}
@@ -0,0 +1,278 @@
+package wda
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// ActionSequence is one input source of a W3C WebDriver actions request. Its
+// actions run in ticks: the nth action of every source runs together, and a
+// tick lasts as long as its longest action.
+type ActionSequence struct {
+	Type       string             `json:"type"` // "pointer" or "key"
+	ID         string             `json:"id"`
+	Parameters *PointerParameters `json:"parameters,omitempty"`
+	Actions    []W3CAction        `json:"actions"`
+}
+
+// PointerParameters describes a pointer input source
+type PointerParameters struct {
+	PointerType string `json:"pointerType"`
+}
+
+// W3CAction is a single action of an input source
+type W3CAction struct {
+	Type     string `json:"type"`
+	Duration *int   `json:"duration,omitempty"`
+	X        *int   `json:"x,omitempty"`
+	Y        *int   `json:"y,omitempty"`
+	Origin   string `json:"origin,omitempty"`
+	Button   *int   `json:"button,omitempty"`
+	Value    string `json:"value,omitempty"`
+}
+
+func intPtr(n int) *int { return &n }
+
+// isKeyAction reports whether a TapAction is a keyboard action
+func isKeyAction(a TapAction) bool {
+	return a.Type == "keyDown" || a.Type == "keyUp"
+}
+
+// ToW3CActions converts TapActions to W3C input sources: a touch pointer, and
+// a keyboard when there are keyDown or keyUp actions. Every TapAction is one
+// tick, so the source an action doesn't belong to pauses for that tick and the
+// order of pointer and key actions is kept.
+func ToW3CActions(actions []TapAction) ([]ActionSequence, error) {
+	hasKeys := false
+	for _, a := range actions {
+		if isKeyAction(a) {
+			hasKeys = true
+			break
+		}
+	}
+
+	pointer := ActionSequence{
+		Type:       "pointer",
+		ID:         "finger1",
+		Parameters: &PointerParameters{PointerType: "touch"},
+		Actions:    []W3CAction{},
+	}
+	keyboard := ActionSequence{
+		Type:    "key",
+		ID:      "keyboard",
+		Actions: []W3CAction{},
+	}
+
+	for i, a := range actions {
+		var pointerAction, keyAction W3CAction
+		switch a.Type {
+		case "pointerMove":
+			pointerAction = W3CAction{Type: "pointerMove", Duration: intPtr(a.Duration), X: intPtr(a.X), Y: intPtr(a.Y), Origin: "viewport"}
+			keyAction = W3CAction{Type: "pause"}
+		case "pointerDown", "pointerUp":
+			pointerAction = W3CAction{Type: a.Type, Button: intPtr(a.Button)}
+			keyAction = W3CAction{Type: "pause"}
+		case "pause":
+			pointerAction = W3CAction{Type: "pause", Duration: intPtr(a.Duration)}
+			keyAction = W3CAction{Type: "pause"}
+		case "keyDown", "keyUp":
+			if a.Value == "" {
+				return nil, fmt.Errorf("action %d: %s requires a value", i, a.Type)
+			}
+			pointerAction = W3CAction{Type: "pause"}
+			keyAction = W3CAction{Type: a.Type, Value: a.Value}
+		default:
+			return nil, fmt.Errorf("action %d: unsupported action type '%s'", i, a.Type)
+		}
+
+		pointer.Actions = append(pointer.Actions, pointerAction)
+		keyboard.Actions = append(keyboard.Actions, keyAction)
+	}
+
+	if !hasKeys {
+		return []ActionSequence{pointer}, nil
+	}
+	return []ActionSequence{pointer, keyboard}, nil
+}
+
+// wdaStatus is the part of WebDriverAgent's GET /status response used to
+// tell it apart from the DeviceKit agent, which has no such endpoint
+type wdaStatus struct {
+	Value *struct {
+		Ready *bool           `json:"ready"`
+		Build json.RawMessage `json:"build"`
+	} `json:"value"`
+	SessionID string `json:"sessionId"`
+}
+
+// getWDAStatus reads GET /status, returning nil when the agent doesn't serve
+// a WebDriverAgent status
+func (c *WdaClient) getWDAStatus() *wdaStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/status", nil)
+	if err != nil {
+		return nil
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+
+	var status wdaStatus
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil || status.Value == nil {
+		return nil
+	}
+	if status.Value.Ready == nil && len(status.Value.Build) == 0 {
+		return nil
+	}
+	return &status
+}
+
+// supportsW3CActions reports whether the agent is WebDriverAgent, which
+// performs gestures with W3C actions, rather than the DeviceKit agent. It is
+// probed once per client.
+func (c *WdaClient) supportsW3CActions() bool {
+	c.capabilitiesOnce.Do(func() {
+		c.w3cActions = c.getWDAStatus() != nil
+		utils.Debug(utils.SubsystemWDA, "Agent at %s supports W3C actions: %v", c.baseURL, c.w3cActions)
+	})
+	return c.w3cActions
+}
+
+// wdaCall sends a WebDriver request and decodes the "value" of its response
+func (c *WdaClient) wdaCall(method, path string, body any, value any) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultRPCTimeout+gestureTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("%s %s failed: %w", method, path, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var envelope struct {
+		Value json.RawMessage `json:"value"`
+	}
+	_ = json.Unmarshal(data, &envelope)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var wdErr struct {
+			Error   string `json:"error"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(envelope.Value, &wdErr) == nil && wdErr.Error != "" {
+			return resp.StatusCode, fmt.Errorf("%s %s: %s: %s", method, path, wdErr.Error, wdErr.Message)
+		}
+		return resp.StatusCode, fmt.Errorf("%s %s returned status %d", method, path, resp.StatusCode)
+	}
+
+	if value != nil && len(envelope.Value) > 0 {
+		if err := json.Unmarshal(envelope.Value, value); err != nil {
+			return resp.StatusCode, fmt.Errorf("invalid response from %s %s: %w", method, path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// gestureTimeout is added to requests that perform gestures, which return
+// once the gesture is over
+const gestureTimeout = 30 * time.Second
+
+// wdaSession returns the ID of WebDriverAgent's session, creating one when
+// there is none
+func (c *WdaClient) wdaSession() (string, error) {
+	c.sessionMu.Lock()
+	defer c.sessionMu.Unlock()
+
+	if c.sessionID != "" {
+		return c.sessionID, nil
+	}
+
+	if status := c.getWDAStatus(); status != nil && status.SessionID != "" {
+		c.sessionID = status.SessionID
+		return c.sessionID, nil
+	}
+
+	var session struct {
+		SessionID string `json:"sessionId"`
+	}
+	body := map[string]any{"capabilities": map[string]any{"alwaysMatch": map[string]any{}}}
+	if _, err := c.wdaCall("POST", "/session", body, &session); err != nil {
+		return "", fmt.Errorf("failed to create session: %w", err)
+	}
+	if session.SessionID == "" {
+		return "", fmt.Errorf("WebDriverAgent returned no session ID")
+	}
+
+	c.sessionID = session.SessionID
+	return c.sessionID, nil
+}
+
+// PerformW3CActions performs actions with WebDriverAgent's W3C actions
+// endpoint, recreating the session once if it has gone away
+func (c *WdaClient) PerformW3CActions(sequences []ActionSequence) error {
+	_, err := c.sessionCall("POST", "/actions", map[string]any{"actions": sequences}, nil)
+	return err
+}
+
+// sessionCall sends a WebDriver request to path within WebDriverAgent's
+// session, recreating the session once if it has gone away
+func (c *WdaClient) sessionCall(method, path string, body any, value any) (int, error) {
+	for attempt := 0; ; attempt++ {
+		sessionID, err := c.wdaSession()
+		if err != nil {
+			return 0, err
+		}
+
+		status, err := c.wdaCall(method, "/session/"+sessionID+path, body, value)
+		if err == nil {
+			return status, nil
+		}
+
+		// a missing alert is reported with 404 as well
+		if status != http.StatusNotFound || strings.Contains(err.Error(), noSuchAlert) || attempt > 0 {
+			return status, err
+		}
+
+		utils.Debug(utils.SubsystemWDA, "Session %s is gone, creating a new one", sessionID)
+		c.sessionMu.Lock()
+		c.sessionID = ""
+		c.sessionMu.Unlock()
+	}
+}
@@ -0,0 +1,138 @@
+package wda
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestToW3CActionsPointerOnly(t *testing.T) {
+	sequences, err := ToW3CActions([]TapAction{
+		{Type: "pointerMove", X: 100, Y: 200},
+		{Type: "pointerDown"},
+		{Type: "pause", Duration: 500},
+		{Type: "pointerMove", X: 100, Y: 600, Duration: 300},
+		{Type: "pointerUp"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := json.Marshal(sequences)
+	want := `[{"type":"pointer","id":"finger1","parameters":{"pointerType":"touch"},"actions":[` +
+		`{"type":"pointerMove","duration":0,"x":100,"y":200,"origin":"viewport"},` +
+		`{"type":"pointerDown","button":0},` +
+		`{"type":"pause","duration":500},` +
+		`{"type":"pointerMove","duration":300,"x":100,"y":600,"origin":"viewport"},` +
+		`{"type":"pointerUp","button":0}]}]`
+	if string(data) != want {
+		t.Errorf("unexpected W3C actions:\n got %s\nwant %s", data, want)
+	}
+}
+
+func TestToW3CActionsKeepsKeyAndPointerTicksAligned(t *testing.T) {
+	sequences, err := ToW3CActions([]TapAction{
+		{Type: "keyDown", Value: ""},
+		{Type: "pointerMove", X: 10, Y: 20},
+		{Type: "pointerDown"},
+		{Type: "pointerUp"},
+		{Type: "keyUp", Value: ""},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(sequences) != 2 {
+		t.Fatalf("expected a pointer and a key source, got %d", len(sequences))
+	}
+
+	pointer, keyboard := sequences[0], sequences[1]
+	if len(pointer.Actions) != 5 || len(keyboard.Actions) != 5 {
+		t.Fatalf("sources must have one action per tick, got %d and %d", len(pointer.Actions), len(keyboard.Actions))
+	}
+
+	var pointerTypes, keyTypes []string
+	for i := range pointer.Actions {
+		pointerTypes = append(pointerTypes, pointer.Actions[i].Type)
+		keyTypes = append(keyTypes, keyboard.Actions[i].Type)
+	}
+	if want := "[pause pointerMove pointerDown pointerUp pause]"; fmt.Sprint(pointerTypes) != want {
+		t.Errorf("pointer actions = %v, want %s", pointerTypes, want)
+	}
+	if want := "[keyDown pause pause pause keyUp]"; fmt.Sprint(keyTypes) != want {
+		t.Errorf("key actions = %v, want %s", keyTypes, want)
+	}
+	if keyboard.Type != "key" || keyboard.Parameters != nil || keyboard.Actions[0].Value != "" {
+		t.Errorf("unexpected key source: %+v", keyboard)
+	}
+}
+
+func TestToW3CActionsRejectsInvalidActions(t *testing.T) {
+	if _, err := ToW3CActions([]TapAction{{Type: "scroll"}}); err == nil {
+		t.Error("expected an error for an unsupported action type")
+	}
+	if _, err := ToW3CActions([]TapAction{{Type: "keyDown"}}); err == nil {
+		t.Error("expected an error for a key action without a value")
+	}
+}
+
+func TestGestureUsesW3CActionsOnWebDriverAgent(t *testing.T) {
+	var actionsBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /status":
+			_, _ = w.Write([]byte(`{"value": {"ready": true, "build": {"version": "9.0.0"}}, "sessionId": null}`))
+		case "POST /session":
+			_, _ = w.Write([]byte(`{"value": {"sessionId": "s-1", "capabilities": {}}, "sessionId": "s-1"}`))
+		case "POST /session/s-1/actions":
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &actionsBody)
+			_, _ = w.Write([]byte(`{"value": null, "sessionId": "s-1"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewWdaClient(srv.URL)
+	err := client.Gesture([]TapAction{{Type: "pointerMove", X: 1, Y: 2}, {Type: "pointerDown"}, {Type: "pointerUp"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sequences, _ := actionsBody["actions"].([]any)
+	if len(sequences) != 1 {
+		t.Fatalf("expected one pointer source, got %v", actionsBody)
+	}
+}
+
+func TestGestureUsesRPCOnDeviceKitAgent(t *testing.T) {
+	var method string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /rpc":
+			var req jsonRPCRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			method = req.Method
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "result": {}, "id": 1}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewWdaClient(srv.URL)
+	if err := client.Gesture([]TapAction{{Type: "pointerMove", X: 1, Y: 2}, {Type: "pointerDown"}, {Type: "pointerUp"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if method != "device.io.gesture" {
+		t.Errorf("expected the gesture RPC, got %q", method)
+	}
+
+	if err := client.Gesture([]TapAction{{Type: "keyDown", Value: "a"}}); err == nil {
+		t.Error("expected key actions to be rejected without W3C actions")
+	}
+}
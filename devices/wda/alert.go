@@ -0,0 +1,180 @@
+package wda
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// noSuchAlert is the WebDriver error of alert requests when no alert is shown
+const noSuchAlert = "no such alert"
+
+// Alert is a system or app alert on screen, such as a permission dialog
+type Alert struct {
+	Text    string   `json:"text"`
+	Buttons []string `json:"buttons"`
+}
+
+// alertElement is an alert found in the UI source, with where its buttons are
+type alertElement struct {
+	Alert
+	buttonRects []sourceTreeElementRect
+}
+
+// GetAlert returns the alert on screen, or nil when there is none
+func (c *WdaClient) GetAlert() (*Alert, error) {
+	if !c.supportsW3CActions() {
+		alert, err := c.findAlert()
+		if err != nil || alert == nil {
+			return nil, err
+		}
+		return &alert.Alert, nil
+	}
+
+	var text string
+	if _, err := c.sessionCall("GET", "/alert/text", nil, &text); err != nil {
+		if strings.Contains(err.Error(), noSuchAlert) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get alert text: %w", err)
+	}
+
+	alert := &Alert{Text: text, Buttons: []string{}}
+	if _, err := c.sessionCall("GET", "/wda/alert/buttons", nil, &alert.Buttons); err != nil {
+		return nil, fmt.Errorf("failed to get alert buttons: %w", err)
+	}
+	return alert, nil
+}
+
+// AcceptAlert taps the named button of the alert on screen, or its default
+// button when button is empty
+func (c *WdaClient) AcceptAlert(button string) error {
+	return c.answerAlert("accept", button)
+}
+
+// DismissAlert taps the named button of the alert on screen, or its cancel
+// button when button is empty
+func (c *WdaClient) DismissAlert(button string) error {
+	return c.answerAlert("dismiss", button)
+}
+
+// answerAlert accepts or dismisses the alert on screen. WebDriverAgent does it
+// with its alert endpoints; with the DeviceKit agent, the alert's button is
+// found in the UI source and tapped.
+func (c *WdaClient) answerAlert(action, button string) error {
+	if c.supportsW3CActions() {
+		var body any
+		if button != "" {
+			body = map[string]string{"name": button}
+		}
+
+		if _, err := c.sessionCall("POST", "/alert/"+action, body, nil); err != nil {
+			if strings.Contains(err.Error(), noSuchAlert) {
+				return fmt.Errorf("no alert is shown")
+			}
+			return fmt.Errorf("failed to %s alert: %w", action, err)
+		}
+		return nil
+	}
+
+	alert, err := c.findAlert()
+	if err != nil {
+		return err
+	}
+	if alert == nil {
+		return fmt.Errorf("no alert is shown")
+	}
+
+	index, err := alert.buttonIndex(action, button)
+	if err != nil {
+		return err
+	}
+
+	rect := alert.buttonRects[index]
+	return c.Tap(int(rect.X+rect.Width/2), int(rect.Y+rect.Height/2))
+}
+
+// buttonIndex returns which button answers an alert: the named one, or like
+// WebDriverAgent does, the last button to accept and the first to dismiss
+func (a *alertElement) buttonIndex(action, button string) (int, error) {
+	if len(a.Buttons) == 0 {
+		return 0, fmt.Errorf("the alert has no buttons")
+	}
+
+	if button != "" {
+		for i, name := range a.Buttons {
+			if name == button {
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("the alert has no button '%s', its buttons are: %s", button, strings.Join(a.Buttons, ", "))
+	}
+
+	if action == "accept" {
+		return len(a.Buttons) - 1, nil
+	}
+	return 0, nil
+}
+
+// findAlert looks for an alert in the UI source, returning nil when there is
+// none
+func (c *WdaClient) findAlert() (*alertElement, error) {
+	result, err := c.CallRPC("device.dump.ui", map[string]any{"format": "json"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get source: %w", err)
+	}
+
+	var source sourceTreeElement
+	if err := json.Unmarshal(result, &source); err != nil {
+		return nil, fmt.Errorf("failed to parse source tree: %w", err)
+	}
+
+	return findAlertElement(source), nil
+}
+
+// findAlertElement returns the first alert of a source tree, with the text of
+// its static texts and its buttons
+func findAlertElement(source sourceTreeElement) *alertElement {
+	if strings.TrimPrefix(source.Type, "XCUIElementType") != "Alert" {
+		for _, child := range source.Children {
+			if alert := findAlertElement(child); alert != nil {
+				return alert
+			}
+		}
+		return nil
+	}
+
+	alert := &alertElement{Alert: Alert{Buttons: []string{}}}
+	var texts []string
+
+	var collect func(element sourceTreeElement)
+	collect = func(element sourceTreeElement) {
+		label := ""
+		if element.Label != nil {
+			label = *element.Label
+		} else if element.Name != nil {
+			label = *element.Name
+		}
+
+		switch strings.TrimPrefix(element.Type, "XCUIElementType") {
+		case "StaticText":
+			if label != "" {
+				texts = append(texts, label)
+			}
+		case "Button":
+			if isVisible(element.Rect) {
+				alert.Buttons = append(alert.Buttons, label)
+				alert.buttonRects = append(alert.buttonRects, element.Rect)
+			}
+			return
+		}
+
+		for _, child := range element.Children {
+			collect(child)
+		}
+	}
+	collect(source)
+
+	alert.Text = strings.Join(texts, "\n")
+	return alert
+}
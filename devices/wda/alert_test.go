@@ -0,0 +1,119 @@
+package wda
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const alertSource = `{"type": "Application", "rect": {"x": 0, "y": 0, "width": 390, "height": 844}, "children": [
+	{"type": "Alert", "rect": {"x": 60, "y": 300, "width": 270, "height": 200}, "children": [
+		{"type": "StaticText", "label": "Allow \"Maps\" to use your location?", "rect": {"x": 70, "y": 310, "width": 250, "height": 40}},
+		{"type": "StaticText", "label": "Your location is used to show nearby places.", "rect": {"x": 70, "y": 350, "width": 250, "height": 40}},
+		{"type": "Button", "label": "Don't Allow", "rect": {"x": 60, "y": 400, "width": 270, "height": 44}},
+		{"type": "Button", "label": "Allow While Using App", "rect": {"x": 60, "y": 444, "width": 270, "height": 44}}
+	]}
+]}`
+
+func TestAlertOnDeviceKitAgent(t *testing.T) {
+	var taps []map[string]float64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rpc" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var req struct {
+			Method string             `json:"method"`
+			Params map[string]float64 `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		switch req.Method {
+		case "device.dump.ui":
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "result": ` + alertSource + `, "id": 1}`))
+		case "device.io.tap":
+			taps = append(taps, req.Params)
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "result": {}, "id": 1}`))
+		}
+	}))
+	defer srv.Close()
+
+	client := NewWdaClient(srv.URL)
+	alert, err := client.GetAlert()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alert == nil || !strings.HasPrefix(alert.Text, "Allow \"Maps\"") || len(alert.Buttons) != 2 || alert.Buttons[1] != "Allow While Using App" {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+
+	if err := client.AcceptAlert(""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := client.DismissAlert(""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(taps) != 2 || taps[0]["y"] != 466 || taps[1]["y"] != 422 {
+		t.Errorf("expected the last button to accept and the first to dismiss, got taps %v", taps)
+	}
+
+	if err := client.AcceptAlert("Ask Next Time"); err == nil || !strings.Contains(err.Error(), "Don't Allow, Allow While Using App") {
+		t.Errorf("expected an error listing the buttons, got %v", err)
+	}
+}
+
+func TestAlertOnWebDriverAgent(t *testing.T) {
+	var accepted map[string]any
+	alertShown := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		noAlert := `{"value": {"error": "no such alert", "message": "An attempt was made to operate on a modal dialog when one was not open"}}`
+		switch r.Method + " " + r.URL.Path {
+		case "GET /status":
+			_, _ = w.Write([]byte(`{"value": {"ready": true, "build": {}}, "sessionId": "s-1"}`))
+		case "GET /session/s-1/alert/text":
+			if !alertShown {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(noAlert))
+				return
+			}
+			_, _ = w.Write([]byte(`{"value": "Allow notifications?"}`))
+		case "GET /session/s-1/wda/alert/buttons":
+			_, _ = w.Write([]byte(`{"value": ["Don't Allow", "Allow"]}`))
+		case "POST /session/s-1/alert/accept":
+			_ = json.NewDecoder(r.Body).Decode(&accepted)
+			alertShown = false
+			_, _ = w.Write([]byte(`{"value": null}`))
+		case "POST /session/s-1/alert/dismiss":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(noAlert))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	client := NewWdaClient(srv.URL)
+	alert, err := client.GetAlert()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if alert == nil || alert.Text != "Allow notifications?" || len(alert.Buttons) != 2 {
+		t.Fatalf("unexpected alert: %+v", alert)
+	}
+
+	if err := client.AcceptAlert("Allow"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if accepted["name"] != "Allow" {
+		t.Errorf("expected the button name to be sent, got %v", accepted)
+	}
+
+	if alert, err := client.GetAlert(); err != nil || alert != nil {
+		t.Errorf("expected no alert, got %+v, %v", alert, err)
+	}
+	if err := client.DismissAlert(""); err == nil || err.Error() != "no alert is shown" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
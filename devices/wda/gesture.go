@@ -78,7 +78,24 @@ func convertActions(actions []TapAction) []gestureAction {
 	return result
 }
 
+// Gesture performs actions with W3C actions when the agent is WebDriverAgent,
+// and with the DeviceKit agent's gesture RPC otherwise. Key actions need W3C
+// actions.
 func (c *WdaClient) Gesture(actions []TapAction) error {
+	if c.supportsW3CActions() {
+		sequences, err := ToW3CActions(actions)
+		if err != nil {
+			return err
+		}
+		return c.PerformW3CActions(sequences)
+	}
+
+	for _, a := range actions {
+		if isKeyAction(a) {
+			return fmt.Errorf("%s actions are not supported by this agent", a.Type)
+		}
+	}
+
 	params := map[string]any{
 		"actions": convertActions(actions),
 	}
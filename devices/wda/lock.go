@@ -0,0 +1,32 @@
+package wda
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func (c *WdaClient) IsLocked() (bool, error) {
+	result, err := c.CallRPC("device.locked", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get lock state: %w", err)
+	}
+
+	var response struct {
+		Locked bool `json:"locked"`
+	}
+	if err := json.Unmarshal(result, &response); err != nil {
+		return false, fmt.Errorf("failed to parse lock state response: %w", err)
+	}
+
+	return response.Locked, nil
+}
+
+func (c *WdaClient) Lock() error {
+	_, err := c.CallRPC("device.lock", nil)
+	return err
+}
+
+func (c *WdaClient) Unlock() error {
+	_, err := c.CallRPC("device.unlock", nil)
+	return err
+}
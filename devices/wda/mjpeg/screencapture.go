@@ -9,6 +9,12 @@ import (
 )
 
 func (c *WdaMjpegClient) StartScreenCapture(format string, callback func([]byte) bool) error {
+	return c.StartScreenCaptureContext(context.Background(), format, callback)
+}
+
+// StartScreenCaptureContext streams until the callback returns false, the
+// stream ends or ctx is cancelled, which closes the connection immediately
+func (c *WdaMjpegClient) StartScreenCaptureContext(ctx context.Context, format string, callback func([]byte) bool) error {
 
 	client := &http.Client{
 		Timeout: 0, // no timeout for long-lived streaming requests
@@ -19,7 +25,7 @@ func (c *WdaMjpegClient) StartScreenCapture(format string, callback func([]byte)
 	var resp *http.Response
 	deadline := time.Now().Add(10 * time.Second)
 	for {
-		req, err := http.NewRequestWithContext(context.Background(), "GET", c.baseURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL, nil)
 		if err != nil {
 			return fmt.Errorf("failed to create request: %w", err)
 		}
@@ -34,6 +40,10 @@ func (c *WdaMjpegClient) StartScreenCapture(format string, callback func([]byte)
 			_ = resp.Body.Close()
 		}
 
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		if time.Now().After(deadline) {
 			if err != nil {
 				return fmt.Errorf("failed to connect to MJPEG stream: %w", err)
@@ -57,8 +67,8 @@ func (c *WdaMjpegClient) StartScreenCapture(format string, callback func([]byte)
 		}
 
 		if err != nil {
-			if err == io.EOF {
-				// Normal end of stream
+			if err == io.EOF || ctx.Err() != nil {
+				// Normal end of stream, or cancelled by the caller
 				break
 			}
 
@@ -5,6 +5,14 @@ import (
 	"fmt"
 )
 
+// wdaOrientations maps orientations to the values WebDriverAgent uses
+var wdaOrientations = map[string]string{
+	"portrait":           "PORTRAIT",
+	"landscapeLeft":      "LANDSCAPE",
+	"landscapeRight":     "UIA_DEVICE_ORIENTATION_LANDSCAPERIGHT",
+	"portraitUpsideDown": "UIA_DEVICE_ORIENTATION_PORTRAIT_UPSIDEDOWN",
+}
+
 func (c *WdaClient) GetOrientation() (string, error) {
 	result, err := c.CallRPC("device.io.orientation.get", nil)
 	if err != nil {
@@ -18,24 +26,22 @@ func (c *WdaClient) GetOrientation() (string, error) {
 		return "", fmt.Errorf("failed to parse orientation response: %w", err)
 	}
 
-	switch response.Orientation {
-	case "PORTRAIT":
-		return "portrait", nil
-	case "LANDSCAPE":
-		return "landscape", nil
-	default:
-		return "portrait", nil
+	for orientation, wdaOrientation := range wdaOrientations {
+		if response.Orientation == wdaOrientation {
+			return orientation, nil
+		}
 	}
+	return "portrait", nil
 }
 
 func (c *WdaClient) SetOrientation(orientation string) error {
-	if orientation != "portrait" && orientation != "landscape" {
-		return fmt.Errorf("invalid orientation value '%s', must be 'portrait' or 'landscape'", orientation)
+	if orientation == "landscape" {
+		orientation = "landscapeLeft"
 	}
 
-	wdaOrientation := "PORTRAIT"
-	if orientation == "landscape" {
-		wdaOrientation = "LANDSCAPE"
+	wdaOrientation, ok := wdaOrientations[orientation]
+	if !ok {
+		return fmt.Errorf("invalid orientation value '%s', must be one of portrait, portraitUpsideDown, landscapeLeft, landscapeRight or landscape", orientation)
 	}
 
 	params := map[string]string{
@@ -0,0 +1,233 @@
+package wda
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+const (
+	// poolDialAttempts is how often a pooled client tries to connect before a
+	// request fails, backing off from poolDialBackoff between attempts
+	poolDialAttempts = 3
+	poolDialBackoff  = 50 * time.Millisecond
+
+	// poolIdleTimeout is how long a kept-alive connection to the agent is
+	// kept without being used
+	poolIdleTimeout = 30 * time.Second
+
+	// poolHealthCheckAfter is how long a pooled client may go unused before
+	// it's checked for a healthy agent again when handed out
+	poolHealthCheckAfter = 10 * time.Second
+)
+
+// ConnectionStats are the metrics of a pooled agent client
+type ConnectionStats struct {
+	DeviceID            string `json:"deviceId"`
+	URL                 string `json:"url"`
+	Requests            int64  `json:"requests"`
+	Connects            int64  `json:"connects"`
+	ConnectFailures     int64  `json:"connectFailures"`
+	HealthCheckFailures int64  `json:"healthCheckFailures"`
+	Retries             int64  `json:"retries"`
+	LastError           string `json:"lastError,omitempty"`
+}
+
+// connectionStats counts the connections and requests of a client
+type connectionStats struct {
+	requests            atomic.Int64
+	connects            atomic.Int64
+	connectFailures     atomic.Int64
+	healthCheckFailures atomic.Int64
+	retries             atomic.Int64
+	lastUsed            atomic.Int64 // unix nanoseconds
+
+	mu        sync.Mutex
+	lastError string
+}
+
+func (s *connectionStats) setLastError(err error) {
+	s.mu.Lock()
+	s.lastError = err.Error()
+	s.mu.Unlock()
+}
+
+// ClientPool shares one agent client per device between all the commands a
+// server runs, so they reuse its kept-alive connections instead of connecting
+// for every request
+type ClientPool struct {
+	mu      sync.Mutex
+	clients map[string]*WdaClient
+}
+
+// Pool is the pool the devices take their agent clients from
+var Pool = NewClientPool()
+
+func NewClientPool() *ClientPool {
+	return &ClientPool{clients: make(map[string]*WdaClient)}
+}
+
+// Get returns the client of a device's agent at hostPort, creating it when
+// the device has none or its agent moved to another port. A client that went
+// unused for a while is checked first, and its connections are dropped when
+// the agent doesn't answer, so the next request reconnects. The check is a
+// request to the agent, so callers must not hold locks of their own.
+func (p *ClientPool) Get(deviceID, hostPort string) *WdaClient {
+	p.mu.Lock()
+	client, ok := p.clients[deviceID]
+	if !ok || client.baseURL != normalizeBaseURL(hostPort) {
+		if ok {
+			client.transport.CloseIdleConnections()
+		}
+		client = newPooledClient(hostPort)
+		p.clients[deviceID] = client
+		p.mu.Unlock()
+		return client
+	}
+	p.mu.Unlock()
+
+	if time.Since(time.Unix(0, client.stats.lastUsed.Load())) > poolHealthCheckAfter {
+		if _, err := client.GetStatus(); err != nil {
+			utils.Verbose("Agent of device %s failed its health check, reconnecting: %v", deviceID, err)
+			client.stats.healthCheckFailures.Add(1)
+			client.stats.setLastError(err)
+			client.transport.CloseIdleConnections()
+		}
+	}
+	return client
+}
+
+// Remove closes and forgets the client of a device, e.g. when its agent
+// was stopped
+func (p *ClientPool) Remove(deviceID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if client, ok := p.clients[deviceID]; ok {
+		client.transport.CloseIdleConnections()
+		delete(p.clients, deviceID)
+	}
+}
+
+// Stats returns the metrics of every pooled client, ordered by device
+func (p *ClientPool) Stats() []ConnectionStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]ConnectionStats, 0, len(p.clients))
+	for deviceID, client := range p.clients {
+		client.stats.mu.Lock()
+		lastError := client.stats.lastError
+		client.stats.mu.Unlock()
+
+		stats = append(stats, ConnectionStats{
+			DeviceID:            deviceID,
+			URL:                 client.baseURL,
+			Requests:            client.stats.requests.Load(),
+			Connects:            client.stats.connects.Load(),
+			ConnectFailures:     client.stats.connectFailures.Load(),
+			HealthCheckFailures: client.stats.healthCheckFailures.Load(),
+			Retries:             client.stats.retries.Load(),
+			LastError:           lastError,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].DeviceID < stats[j].DeviceID })
+	return stats
+}
+
+// newPooledClient creates a client that keeps its connections alive and
+// retries connecting with backoff, since the agent may be restarting
+func newPooledClient(hostPort string) *WdaClient {
+	stats := &connectionStats{}
+	stats.lastUsed.Store(time.Now().UnixNano())
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	transport := &http.Transport{
+		IdleConnTimeout:     poolIdleTimeout,
+		MaxIdleConnsPerHost: 4,
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			backoff := poolDialBackoff
+			for attempt := 1; ; attempt++ {
+				conn, err := dialer.DialContext(ctx, network, address)
+				if err == nil {
+					stats.connects.Add(1)
+					return conn, nil
+				}
+
+				stats.connectFailures.Add(1)
+				stats.setLastError(err)
+				if attempt == poolDialAttempts {
+					return nil, err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil, err
+				case <-time.After(backoff):
+				}
+				backoff *= 2
+			}
+		},
+	}
+
+	return newClient(hostPort, transport, stats)
+}
+
+// countingTransport counts the requests of a pooled client and when it was
+// last used, and retries a request once when the kept-alive connection it
+// was sent on turns out to be closed
+type countingTransport struct {
+	next  *http.Transport
+	stats *connectionStats
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.stats.requests.Add(1)
+	t.stats.lastUsed.Store(time.Now().UnixNano())
+
+	reused := false
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) { reused = info.Reused },
+	}
+
+	resp, err := t.next.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err == nil || !reused || !isBrokenConnection(err) {
+		return resp, err
+	}
+
+	// the agent closed the idle connection, e.g. when it restarted, so the
+	// request never reached it and is sent again on a new connection
+	retry := req.Clone(req.Context())
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+
+	utils.Verbose("Agent connection to %s was closed, retrying on a new connection: %v", req.URL.Host, err)
+	t.stats.retries.Add(1)
+	t.stats.setLastError(err)
+	t.next.CloseIdleConnections()
+	return t.next.RoundTrip(retry)
+}
+
+// isBrokenConnection reports whether a request failed because its connection
+// was closed by the other end before any response
+func isBrokenConnection(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE)
+}
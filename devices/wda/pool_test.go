@@ -0,0 +1,131 @@
+package wda
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestClientPoolReusesConnections(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	pool := NewClientPool()
+	client := pool.Get("device-1", srv.URL)
+	for i := 0; i < 3; i++ {
+		if _, err := client.GetStatus(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if pool.Get("device-1", srv.URL+"/") != client {
+		t.Error("expected the same client for the same agent")
+	}
+
+	stats := pool.Stats()
+	if len(stats) != 1 || stats[0].DeviceID != "device-1" || stats[0].Requests != 3 || stats[0].Connects != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestClientPoolNewPort(t *testing.T) {
+	pool := NewClientPool()
+	client := pool.Get("device-1", "localhost:8100")
+
+	if pool.Get("device-1", "localhost:8101") == client {
+		t.Error("expected a new client when the agent moved")
+	}
+	if pool.Get("device-2", "localhost:8101") == pool.Get("device-1", "localhost:8101") {
+		t.Error("expected a client per device")
+	}
+
+	pool.Remove("device-1")
+	if stats := pool.Stats(); len(stats) != 1 || stats[0].DeviceID != "device-2" {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestClientPoolConnectFailures(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := srv.URL
+	srv.Close()
+
+	pool := NewClientPool()
+	client := pool.Get("device-1", url)
+
+	started := time.Now()
+	if _, err := client.GetStatus(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if elapsed := time.Since(started); elapsed < poolDialBackoff*3 {
+		t.Errorf("expected backoff between attempts, took %v", elapsed)
+	}
+
+	// an idle client is checked when handed out
+	client.stats.lastUsed.Store(time.Now().Add(-2 * poolHealthCheckAfter).UnixNano())
+	pool.Get("device-1", url)
+
+	stats := pool.Stats()[0]
+	if stats.ConnectFailures != 2*poolDialAttempts || stats.Connects != 0 || stats.HealthCheckFailures != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if !strings.Contains(stats.LastError, "refused") {
+		t.Errorf("unexpected last error: %s", stats.LastError)
+	}
+}
+
+func TestClientPoolRetriesClosedConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// the first connection answers one request and is then closed by the
+	// agent, as a restarted agent closes its kept-alive connections
+	var accepted atomic.Int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			first := accepted.Add(1) == 1
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					if _, err := http.ReadRequest(reader); err != nil {
+						return
+					}
+					_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 35\r\n\r\n{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":1}"))
+					if first {
+						_, _ = http.ReadRequest(reader)
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	pool := NewClientPool()
+	client := pool.Get("device-1", listener.Addr().String())
+	// calls are POSTs, which net/http doesn't retry on its own
+	for i := 0; i < 2; i++ {
+		if _, err := client.CallRPC("device.info", nil); err != nil {
+			t.Fatalf("request %d failed: %v", i+1, err)
+		}
+	}
+
+	stats := pool.Stats()[0]
+	if stats.Retries != 1 || stats.Connects != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
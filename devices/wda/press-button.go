@@ -1,26 +1,10 @@
 package wda
 
-import "fmt"
-
-func (c *WdaClient) PressButton(key string) error {
-	buttonMap := map[string]string{
-		"VOLUME_UP":   "volumeUp",
-		"VOLUME_DOWN": "volumeDown",
-		"HOME":        "home",
-		"LOCK":        "lock",
-	}
-
-	if key == "ENTER" {
-		return c.SendKeys("\n")
-	}
-
-	translatedKey, exists := buttonMap[key]
-	if !exists {
-		return fmt.Errorf("unsupported button: %s", key)
-	}
-
+// PressButton presses one of the agent's hardware buttons: home, lock,
+// volumeUp or volumeDown
+func (c *WdaClient) PressButton(button string) error {
 	params := map[string]string{
-		"button": translatedKey,
+		"button": button,
 	}
 
 	_, err := c.CallRPC("device.io.button", params)
@@ -100,11 +100,11 @@ func (c *WdaClient) WaitForAgent() error {
 		case <-ticker.C:
 			_, err := c.GetStatus()
 			if err != nil {
-				utils.Verbose("WebDriverAgent not ready yet: %v", err)
+				utils.Debug(utils.SubsystemWDA, "WebDriverAgent not ready yet: %v", err)
 				continue
 			}
 
-			utils.Verbose("WebDriverAgent is ready!")
+			utils.Debug(utils.SubsystemWDA, "WebDriverAgent is ready!")
 			return nil
 		}
 	}
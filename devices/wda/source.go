@@ -24,6 +24,7 @@ type sourceTreeElement struct {
 	Value            *string               `json:"value"`
 	PlaceholderValue *string               `json:"placeholderValue"`
 	RawIdentifier    *string               `json:"rawIdentifier"`
+	HasFocus         bool                  `json:"hasFocus"`
 	Rect             sourceTreeElementRect `json:"rect"`
 	Children         []sourceTreeElement   `json:"children"`
 }
@@ -81,6 +82,11 @@ func filterSourceElements(source sourceTreeElement) []types.ScreenElement {
 		Children: childElements,
 	}
 
+	if source.HasFocus {
+		focused := true
+		element.Focused = &focused
+	}
+
 	// WKWebView reports as several nested same-rect WebView wrappers; collapse
 	// them so a single element represents a single webview. Children are
 	// already collapsed bottom-up, so one merge per level unwinds the chain.
@@ -108,15 +114,26 @@ func (c *WdaClient) GetSourceRaw() (any, error) {
 	}
 
 	elapsed := time.Since(startTime)
-	utils.Verbose("GetSourceRaw took %.2f seconds", elapsed.Seconds())
+	utils.Debug(utils.SubsystemWDA, "GetSourceRaw took %.2f seconds", elapsed.Seconds())
 
 	return value, nil
 }
 
 func (c *WdaClient) GetSourceElements() ([]types.ScreenElement, error) {
+	return c.GetSourceElementsWithDepth(0)
+}
+
+// GetSourceElementsWithDepth gets the source tree with the agent's snapshot
+// depth (snapshotMaxDepth) bounded to maxDepth levels, 0 for its default
+func (c *WdaClient) GetSourceElementsWithDepth(maxDepth int) ([]types.ScreenElement, error) {
 	startTime := time.Now()
 
-	result, err := c.CallRPC("device.dump.ui", map[string]string{"format": "json"})
+	params := map[string]any{"format": "json"}
+	if maxDepth > 0 {
+		params["maxDepth"] = maxDepth
+	}
+
+	result, err := c.CallRPC("device.dump.ui", params)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +144,7 @@ func (c *WdaClient) GetSourceElements() ([]types.ScreenElement, error) {
 	}
 
 	elapsed := time.Since(startTime)
-	utils.Verbose("GetSourceElements took %.2f seconds", elapsed.Seconds())
+	utils.Debug(utils.SubsystemWDA, "GetSourceElements took %.2f seconds", elapsed.Seconds())
 
 	elements := filterSourceElements(sourceTree)
 	return elements, nil
@@ -279,3 +279,16 @@ func TestFilterSourceElementsOmitsChildrenFromJsonWhenEmpty(t *testing.T) {
 		t.Errorf("expected leaf element to have nil Children, got %+v", output[0].Children)
 	}
 }
+
+func TestFilterSourceElementsReportsFocus(t *testing.T) {
+	output := filterSourceElements(sourceTreeElement{
+		Type:     "XCUIElementTypeTextField",
+		Value:    strPtr("hello"),
+		HasFocus: true,
+		Rect:     visibleRect(24, 200, 354, 44),
+	})
+
+	if len(output) != 1 || output[0].Focused == nil || !*output[0].Focused {
+		t.Fatalf("expected focused TextField, got %+v", output)
+	}
+}
@@ -1,6 +1,17 @@
 package wda
 
-func (c *WdaClient) Swipe(x1, y1, x2, y2 int) error {
+// Swipe swipes from (x1,y1) to (x2,y2). A positive duration (in milliseconds)
+// is replayed as a gesture, since the agent's swipe uses a fixed speed.
+func (c *WdaClient) Swipe(x1, y1, x2, y2, duration int) error {
+	if duration > 0 {
+		return c.Gesture([]TapAction{
+			{Type: "pointerMove", X: x1, Y: y1},
+			{Type: "pointerDown"},
+			{Type: "pointerMove", X: x2, Y: y2, Duration: duration},
+			{Type: "pointerUp"},
+		})
+	}
+
 	params := map[string]int{
 		"x1": x1,
 		"y1": y1,
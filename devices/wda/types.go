@@ -3,29 +3,56 @@ package wda
 import (
 	"net/http"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/mobile-next/mobilecli/utils"
 )
 
 type WdaClient struct {
 	baseURL    string
 	httpClient *http.Client
+	transport  *http.Transport
+
+	// metrics of a client from the Pool, nil otherwise
+	stats *connectionStats
+
+	// whether the agent is WebDriverAgent, see supportsW3CActions
+	capabilitiesOnce sync.Once
+	w3cActions       bool
+
+	// WebDriverAgent session that W3C actions are performed in
+	sessionMu sync.Mutex
+	sessionID string
 }
 
 func NewWdaClient(hostPort string) *WdaClient {
+	return newClient(hostPort, &http.Transport{DisableKeepAlives: true}, nil)
+}
+
+// normalizeBaseURL turns host:port into the base URL of the agent
+func normalizeBaseURL(hostPort string) string {
 	baseURL := hostPort
 	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
 		baseURL = "http://" + baseURL
 	}
-	baseURL = strings.TrimSuffix(baseURL, "/")
+	return strings.TrimSuffix(baseURL, "/")
+}
+
+func newClient(hostPort string, transport *http.Transport, stats *connectionStats) *WdaClient {
+	var roundTripper http.RoundTripper = transport
+	if stats != nil {
+		roundTripper = &countingTransport{next: transport, stats: stats}
+	}
 
 	return &WdaClient{
-		baseURL: baseURL,
+		baseURL: normalizeBaseURL(hostPort),
 		httpClient: &http.Client{
-			Timeout: 60 * time.Second,
-			Transport: &http.Transport{
-				DisableKeepAlives: true,
-			},
+			Timeout:   60 * time.Second,
+			Transport: utils.SubsystemTraceTransport(utils.SubsystemWDA, roundTripper),
 		},
+		transport: transport,
+		stats:     stats,
 	}
 }
 
@@ -35,4 +62,5 @@ type TapAction struct {
 	X        int    `json:"x"`
 	Y        int    `json:"y"`
 	Button   int    `json:"button"`
+	Value    string `json:"value,omitempty"` // key for keyDown and keyUp
 }
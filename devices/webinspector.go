@@ -0,0 +1,399 @@
+package devices
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mobile-next/mobilecli/utils"
+	"howett.net/plist"
+)
+
+// webInspectorTimeout bounds waiting for the replies of the WebKit remote
+// inspector (webinspectord)
+const webInspectorTimeout = 10 * time.Second
+
+// webInspectorListingTimeout bounds waiting for the pages of one app, apps
+// that don't answer are skipped
+const webInspectorListingTimeout = 2 * time.Second
+
+// maxWebInspectorMessageSize bounds a single message of webinspectord
+const maxWebInspectorMessageSize = 64 << 20
+
+// webInspectorClient speaks the WebKit remote inspector protocol (RWI) of
+// webinspectord: length-prefixed binary plists carrying selectors such as
+// _rpc_forwardGetListing:, with the Web Inspector protocol's JSON inside
+// _rpc_forwardSocketData: for a page.
+type webInspectorClient struct {
+	conn         io.ReadWriteCloser
+	connectionID string
+	deadline     func(time.Time) error
+}
+
+// webInspectorApp is an app with inspectable pages
+type webInspectorApp struct {
+	ID       string
+	Name     string
+	BundleID string
+}
+
+// webInspectorPage is an inspectable page of an app
+type webInspectorPage struct {
+	ID    int
+	Type  string
+	Title string
+	URL   string
+}
+
+func newWebInspectorClient(conn io.ReadWriteCloser) (*webInspectorClient, error) {
+	c := &webInspectorClient{
+		conn:         conn,
+		connectionID: strings.ToUpper(uuid.New().String()),
+		deadline:     func(time.Time) error { return nil },
+	}
+	switch netConn := conn.(type) {
+	case net.Conn:
+		c.deadline = netConn.SetReadDeadline
+	case interface{ Conn() net.Conn }:
+		c.deadline = netConn.Conn().SetReadDeadline
+	}
+
+	if err := c.send("_rpc_reportIdentifier:", nil); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *webInspectorClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *webInspectorClient) send(selector string, args map[string]any) error {
+	argument := map[string]any{"WIRConnectionIdentifierKey": c.connectionID}
+	for key, value := range args {
+		argument[key] = value
+	}
+
+	data, err := plist.Marshal(map[string]any{"__selector": selector, "__argument": argument}, plist.BinaryFormat)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", selector, err)
+	}
+
+	message := binary.BigEndian.AppendUint32(nil, uint32(len(data)))
+	if _, err := c.conn.Write(append(message, data...)); err != nil {
+		return fmt.Errorf("failed to send %s: %w", selector, err)
+	}
+	return nil
+}
+
+// receive reads messages until one with the selector arrives, or the
+// deadline passes
+func (c *webInspectorClient) receive(selector string, deadline time.Time) (map[string]any, error) {
+	_ = c.deadline(deadline)
+	defer func() { _ = c.deadline(time.Time{}) }()
+
+	for time.Now().Before(deadline) {
+		var header [4]byte
+		if _, err := io.ReadFull(c.conn, header[:]); err != nil {
+			return nil, fmt.Errorf("failed to read from webinspector: %w", err)
+		}
+		size := binary.BigEndian.Uint32(header[:])
+		if size > maxWebInspectorMessageSize {
+			return nil, fmt.Errorf("webinspector message of %d bytes is too large", size)
+		}
+
+		data := make([]byte, size)
+		if _, err := io.ReadFull(c.conn, data); err != nil {
+			return nil, fmt.Errorf("failed to read from webinspector: %w", err)
+		}
+
+		var message struct {
+			Selector string         `plist:"__selector"`
+			Argument map[string]any `plist:"__argument"`
+		}
+		if _, err := plist.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("invalid webinspector message: %w", err)
+		}
+
+		if message.Selector == selector {
+			return message.Argument, nil
+		}
+		utils.Verbose("webinspector: skipping %s", message.Selector)
+	}
+
+	return nil, fmt.Errorf("timed out waiting for %s", selector)
+}
+
+// apps returns the apps webinspectord knows of
+func (c *webInspectorClient) apps() ([]webInspectorApp, error) {
+	if err := c.send("_rpc_getConnectedApplications:", nil); err != nil {
+		return nil, err
+	}
+
+	argument, err := c.receive("_rpc_reportConnectedApplicationList:", time.Now().Add(webInspectorTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	dictionary, _ := argument["WIRApplicationDictionaryKey"].(map[string]any)
+	apps := make([]webInspectorApp, 0, len(dictionary))
+	for id, value := range dictionary {
+		app, _ := value.(map[string]any)
+		name, _ := app["WIRApplicationNameKey"].(string)
+		bundleID, _ := app["WIRApplicationBundleIdentifierKey"].(string)
+		apps = append(apps, webInspectorApp{ID: id, Name: name, BundleID: bundleID})
+	}
+
+	slices.SortFunc(apps, func(a, b webInspectorApp) int { return strings.Compare(a.ID, b.ID) })
+	return apps, nil
+}
+
+// pages returns the inspectable pages of an app
+func (c *webInspectorClient) pages(appID string) ([]webInspectorPage, error) {
+	if err := c.send("_rpc_forwardGetListing:", map[string]any{"WIRApplicationIdentifierKey": appID}); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(webInspectorListingTimeout)
+	for {
+		argument, err := c.receive("_rpc_applicationSentListing:", deadline)
+		if err != nil {
+			return nil, err
+		}
+		if argument["WIRApplicationIdentifierKey"] != appID {
+			continue
+		}
+
+		listing, _ := argument["WIRListingKey"].(map[string]any)
+		pages := make([]webInspectorPage, 0, len(listing))
+		for _, value := range listing {
+			entry, _ := value.(map[string]any)
+			page := webInspectorPage{}
+			page.ID = plistInt(entry["WIRPageIdentifierKey"])
+			page.Type, _ = entry["WIRTypeKey"].(string)
+			page.Title, _ = entry["WIRTitleKey"].(string)
+			page.URL, _ = entry["WIRURLKey"].(string)
+			pages = append(pages, page)
+		}
+
+		slices.SortFunc(pages, func(a, b webInspectorPage) int { return a.ID - b.ID })
+		return pages, nil
+	}
+}
+
+// evaluate runs a JavaScript expression in a page and returns its value
+func (c *webInspectorClient) evaluate(appID string, pageID int, expression string) (any, error) {
+	senderID := strings.ToUpper(uuid.New().String())
+	page := map[string]any{
+		"WIRApplicationIdentifierKey": appID,
+		"WIRPageIdentifierKey":        pageID,
+		"WIRSenderKey":                senderID,
+	}
+
+	setup := map[string]any{"WIRAutomaticallyPause": false}
+	for key, value := range page {
+		setup[key] = value
+	}
+	if err := c.send("_rpc_forwardSocketSetup:", setup); err != nil {
+		return nil, err
+	}
+
+	// since iOS 12.2 pages are targets, commands are wrapped for the target
+	// announced right after the setup
+	targetID := ""
+	if message, err := c.receivePageMessage(time.Now().Add(time.Second)); err == nil && message.Method == "Target.targetCreated" {
+		var params struct {
+			TargetInfo struct {
+				TargetID string `json:"targetId"`
+			} `json:"targetInfo"`
+		}
+		_ = json.Unmarshal(message.Params, &params)
+		targetID = params.TargetInfo.TargetID
+	}
+
+	command, err := json.Marshal(map[string]any{
+		"id":     1,
+		"method": "Runtime.evaluate",
+		"params": map[string]any{"expression": expression, "returnByValue": true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if targetID != "" {
+		// the wrapper is acknowledged under its own id, the page's reply
+		// comes in a Target.dispatchMessageFromTarget event
+		command, err = json.Marshal(map[string]any{
+			"id":     2,
+			"method": "Target.sendMessageToTarget",
+			"params": map[string]any{"targetId": targetID, "message": string(command)},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data := map[string]any{"WIRSocketDataKey": command}
+	for key, value := range page {
+		data[key] = value
+	}
+	if err := c.send("_rpc_forwardSocketData:", data); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(webInspectorTimeout)
+	for {
+		message, err := c.receivePageMessage(deadline)
+		if err != nil {
+			return nil, err
+		}
+
+		if message.Method == "Target.dispatchMessageFromTarget" {
+			var params struct {
+				Message string `json:"message"`
+			}
+			_ = json.Unmarshal(message.Params, &params)
+			message = webInspectorMessage{}
+			if err := json.Unmarshal([]byte(params.Message), &message); err != nil {
+				continue
+			}
+		}
+		if message.ID != 1 {
+			continue
+		}
+
+		if message.Error != nil {
+			return nil, fmt.Errorf("Runtime.evaluate failed: %s", message.Error.Message)
+		}
+
+		var result struct {
+			Result struct {
+				Type        string `json:"type"`
+				Value       any    `json:"value"`
+				Description string `json:"description"`
+			} `json:"result"`
+			WasThrown bool `json:"wasThrown"`
+		}
+		if err := json.Unmarshal(message.Result, &result); err != nil {
+			return nil, fmt.Errorf("invalid Runtime.evaluate result: %w", err)
+		}
+		if result.WasThrown {
+			return nil, fmt.Errorf("javascript error: %s", result.Result.Description)
+		}
+		return result.Result.Value, nil
+	}
+}
+
+// webInspectorMessage is a Web Inspector protocol reply or event
+type webInspectorMessage struct {
+	ID     int             `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// receivePageMessage reads the next Web Inspector protocol message of the
+// page set up with _rpc_forwardSocketSetup:
+func (c *webInspectorClient) receivePageMessage(deadline time.Time) (webInspectorMessage, error) {
+	argument, err := c.receive("_rpc_applicationSentData:", deadline)
+	if err != nil {
+		return webInspectorMessage{}, err
+	}
+
+	data, _ := argument["WIRMessageDataKey"].([]byte)
+	var message webInspectorMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		return webInspectorMessage{}, fmt.Errorf("invalid web inspector message: %w", err)
+	}
+	return message, nil
+}
+
+// plistInt converts a plist integer, which decodes as any sized integer
+func plistInt(value any) int {
+	switch v := value.(type) {
+	case uint64:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
+
+// webInspectorTargetID identifies a page as "<app id>/<page id>", app IDs
+// being of the form "PID:123"
+func webInspectorTargetID(appID string, pageID int) string {
+	return fmt.Sprintf("%s/%d", appID, pageID)
+}
+
+// parseWebInspectorTargetID splits a target ID from webInspectorTargetID
+func parseWebInspectorTargetID(targetID string) (string, int, error) {
+	appID, page, found := strings.Cut(targetID, "/")
+	pageID, err := strconv.Atoi(page)
+	if !found || appID == "" || err != nil {
+		return "", 0, fmt.Errorf("invalid target '%s', expected an id from 'webview list --devtools'", targetID)
+	}
+	return appID, pageID, nil
+}
+
+// listWebInspectorTargets lists the inspectable pages of all apps
+func listWebInspectorTargets(client *webInspectorClient) ([]DevToolsTarget, error) {
+	apps, err := client.apps()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []DevToolsTarget{}
+	for _, app := range apps {
+		pages, err := client.pages(app.ID)
+		if err != nil {
+			utils.Verbose("webinspector: no pages listed for %s: %v", app.ID, err)
+			continue
+		}
+
+		pid, _ := strconv.Atoi(strings.TrimPrefix(app.ID, "PID:"))
+		for _, page := range pages {
+			targets = append(targets, DevToolsTarget{
+				ID:       webInspectorTargetID(app.ID, page.ID),
+				Type:     page.Type,
+				Title:    page.Title,
+				URL:      page.URL,
+				BundleID: app.BundleID,
+				PID:      pid,
+			})
+		}
+	}
+	return targets, nil
+}
+
+// evaluateWebInspectorTarget runs a JavaScript expression in a page
+func evaluateWebInspectorTarget(client *webInspectorClient, targetID, expression string) (any, error) {
+	appID, pageID, err := parseWebInspectorTargetID(targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the listing has to be requested before a page can be set up
+	if _, err := client.apps(); err != nil {
+		return nil, err
+	}
+	if _, err := client.pages(appID); err != nil {
+		return nil, fmt.Errorf("target %s not found: %w", targetID, err)
+	}
+
+	return client.evaluate(appID, pageID, expression)
+}
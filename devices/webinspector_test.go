@@ -0,0 +1,134 @@
+package devices
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"howett.net/plist"
+)
+
+// fakeWebInspector answers the client like webinspectord, with one app
+// that has one page whose commands go through a target
+func fakeWebInspector(t *testing.T, conn net.Conn) {
+	t.Helper()
+
+	write := func(selector string, argument map[string]any) {
+		data, err := plist.Marshal(map[string]any{"__selector": selector, "__argument": argument}, plist.BinaryFormat)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		_, _ = conn.Write(append(binary.BigEndian.AppendUint32(nil, uint32(len(data))), data...))
+	}
+	sendData := func(message map[string]any) {
+		data, _ := json.Marshal(message)
+		write("_rpc_applicationSentData:", map[string]any{"WIRMessageDataKey": data})
+	}
+
+	for {
+		var header [4]byte
+		if _, err := io.ReadFull(conn, header[:]); err != nil {
+			return
+		}
+		data := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(conn, data); err != nil {
+			return
+		}
+
+		var message struct {
+			Selector string         `plist:"__selector"`
+			Argument map[string]any `plist:"__argument"`
+		}
+		if _, err := plist.Unmarshal(data, &message); err != nil {
+			t.Error(err)
+			return
+		}
+
+		switch message.Selector {
+		case "_rpc_getConnectedApplications:":
+			write("_rpc_reportConnectedApplicationList:", map[string]any{
+				"WIRApplicationDictionaryKey": map[string]any{
+					"PID:42": map[string]any{"WIRApplicationNameKey": "Safari", "WIRApplicationBundleIdentifierKey": "com.apple.mobilesafari"},
+				},
+			})
+		case "_rpc_forwardGetListing:":
+			write("_rpc_applicationSentListing:", map[string]any{
+				"WIRApplicationIdentifierKey": "PID:42",
+				"WIRListingKey": map[string]any{
+					"1": map[string]any{"WIRPageIdentifierKey": 1, "WIRTypeKey": "WIRTypeWebPage", "WIRTitleKey": "Example", "WIRURLKey": "https://example.com/"},
+				},
+			})
+		case "_rpc_forwardSocketSetup:":
+			sendData(map[string]any{"method": "Target.targetCreated", "params": map[string]any{"targetInfo": map[string]any{"targetId": "page-1", "type": "page"}}})
+		case "_rpc_forwardSocketData:":
+			var wrapper struct {
+				ID     int `json:"id"`
+				Params struct {
+					TargetID string `json:"targetId"`
+					Message  string `json:"message"`
+				} `json:"params"`
+			}
+			_ = json.Unmarshal(message.Argument["WIRSocketDataKey"].([]byte), &wrapper)
+			if wrapper.Params.TargetID != "page-1" {
+				t.Errorf("expected the command to be sent to page-1, got %+v", wrapper)
+			}
+
+			var command struct {
+				ID     int `json:"id"`
+				Params struct {
+					Expression string `json:"expression"`
+				} `json:"params"`
+			}
+			_ = json.Unmarshal([]byte(wrapper.Params.Message), &command)
+
+			sendData(map[string]any{"id": wrapper.ID, "result": map[string]any{}})
+			reply, _ := json.Marshal(map[string]any{"id": command.ID, "result": map[string]any{"result": map[string]any{"type": "string", "value": "evaluated " + command.Params.Expression}}})
+			sendData(map[string]any{"method": "Target.dispatchMessageFromTarget", "params": map[string]any{"targetId": "page-1", "message": string(reply)}})
+		}
+	}
+}
+
+func newFakeWebInspectorClient(t *testing.T) *webInspectorClient {
+	clientConn, serverConn := net.Pipe()
+	go fakeWebInspector(t, serverConn)
+	t.Cleanup(func() { _ = serverConn.Close() })
+
+	client, err := newWebInspectorClient(clientConn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+	return client
+}
+
+func TestListWebInspectorTargets(t *testing.T) {
+	targets, err := listWebInspectorTargets(newFakeWebInspectorClient(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(targets) != 1 {
+		t.Fatalf("expected 1 target, got %+v", targets)
+	}
+	target := targets[0]
+	if target.ID != "PID:42/1" || target.BundleID != "com.apple.mobilesafari" || target.PID != 42 || target.URL != "https://example.com/" {
+		t.Errorf("unexpected target %+v", target)
+	}
+}
+
+func TestEvaluateWebInspectorTarget(t *testing.T) {
+	value, err := evaluateWebInspectorTarget(newFakeWebInspectorClient(t), "PID:42/1", "document.title")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value != "evaluated document.title" {
+		t.Errorf("unexpected value %v", value)
+	}
+
+	if _, err := evaluateWebInspectorTarget(newFakeWebInspectorClient(t), "PID:42", "document.title"); err == nil {
+		t.Error("expected an invalid target to fail")
+	}
+}
@@ -0,0 +1,275 @@
+package devices
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/utils"
+	"howett.net/plist"
+)
+
+// xctestOutputLines is how many trailing lines of test output explain a run
+// that ended early
+const xctestOutputLines = 20
+
+// XCTestConfig configures a run of prebuilt XCTest UI tests, from either an
+// .xctestrun file or a UI test runner app
+type XCTestConfig struct {
+	XCTestRun string // an .xctestrun file, as left by xcodebuild build-for-testing
+	RunnerApp string // or a test runner app, e.g. MyAppUITests-Runner.app
+	AppPath   string // the app under test, with RunnerApp
+
+	// OnlyTesting only runs these tests, given as Class or Class/method
+	OnlyTesting []string
+
+	// OnEvent, when set, is called as each test starts and finishes
+	OnEvent func(event TestEvent)
+}
+
+// XCTestRunner is implemented by devices that can run XCTest UI tests
+type XCTestRunner interface {
+	RunXCTest(config XCTestConfig) (*TestResults, error)
+}
+
+var (
+	// Test Case '-[MyAppUITests.LoginTests testLogin]' started.
+	xctestStartedRegex = regexp.MustCompile(`^Test [Cc]ase '-\[(\S+) (\S+)\]' started`)
+
+	// Test Case '-[MyAppUITests.LoginTests testLogin]' passed (1.234 seconds).
+	// Test case 'LoginTests.testLogin()' failed on 'Clone 1 of iPhone 16' (1.234 seconds)
+	xctestFinishedRegex         = regexp.MustCompile(`^Test [Cc]ase '-\[(\S+) (\S+)\]' (passed|failed|skipped) \((\d+(?:\.\d+)?) seconds\)`)
+	xctestParallelFinishedRegex = regexp.MustCompile(`^Test [Cc]ase '(\S+)\.(\w+)\(\)' (passed|failed|skipped) on '.*' \((\d+(?:\.\d+)?) seconds\)`)
+
+	// /path/LoginTests.swift:25: error: -[MyAppUITests.LoginTests testLogin] : XCTAssertTrue failed
+	xctestFailureRegex = regexp.MustCompile(`^(.+?:\d+): error: -\[(\S+) (\S+)\] : (.*)$`)
+)
+
+// xctestParser parses the console output of XCTest, as xcodebuild prints it
+// and testmanagerd relays it, line by line
+type xctestParser struct {
+	mu       sync.Mutex
+	onEvent  func(TestEvent)
+	tests    []TestCaseResult
+	failures map[string][]string
+	running  map[string]bool
+	summary  string // the "** TEST EXECUTE SUCCEEDED **" line
+	partial  string
+	tail     *outputTail
+}
+
+func newXCTestParser(onEvent func(TestEvent)) *xctestParser {
+	return &xctestParser{
+		onEvent:  onEvent,
+		failures: make(map[string][]string),
+		running:  make(map[string]bool),
+		tail:     newOutputTail(xctestOutputLines),
+	}
+}
+
+// Write parses whole lines, so the parser can be the log writer of a test run
+func (p *xctestParser) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	lines := strings.Split(p.partial+string(data), "\n")
+	p.partial = lines[len(lines)-1]
+	p.mu.Unlock()
+
+	for _, line := range lines[:len(lines)-1] {
+		p.line(line)
+	}
+	return len(data), nil
+}
+
+func (p *xctestParser) line(line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return
+	}
+	_, _ = p.tail.Write([]byte(line + "\n"))
+
+	if strings.HasPrefix(line, "** TEST") && strings.HasSuffix(line, "**") {
+		p.summary = line
+		return
+	}
+
+	if m := xctestStartedRegex.FindStringSubmatch(line); m != nil {
+		p.running[m[1]+"/"+m[2]] = true
+		testStarted(p.onEvent, m[1], m[2])
+		return
+	}
+
+	if m := xctestFailureRegex.FindStringSubmatch(line); m != nil {
+		key := m[2] + "/" + m[3]
+		p.failures[key] = append(p.failures[key], m[1]+": "+m[4])
+		return
+	}
+
+	m := xctestFinishedRegex.FindStringSubmatch(line)
+	if m == nil {
+		m = xctestParallelFinishedRegex.FindStringSubmatch(line)
+	}
+	if m == nil {
+		return
+	}
+
+	class, name, status := m[1], m[2], m[3]
+	seconds, _ := strconv.ParseFloat(m[4], 64)
+	key := class + "/" + name
+	delete(p.running, key)
+
+	test := TestCaseResult{Class: class, Name: name, Status: status, Duration: int64(seconds * 1000)}
+	if status == TestStatusFailed {
+		test.Failure = strings.Join(p.failures[key], "\n")
+	}
+	delete(p.failures, key)
+
+	p.tests = append(p.tests, test)
+	testFinished(p.onEvent, test)
+}
+
+// finish summarizes a run from its output. exitErr is how xcodebuild exited,
+// which fails when a test failed as well as when the run did.
+func (p *xctestParser) finish(runner string, duration int64, exitErr error) *TestResults {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := &TestResults{
+		Runner:   runner,
+		Tests:    p.tests,
+		Duration: duration,
+	}
+	result.countTests(0)
+
+	var running []string
+	for key := range p.running {
+		running = append(running, key)
+	}
+	sort.Strings(running)
+
+	switch {
+	case len(running) > 0:
+		result.Message = "tests ended while running " + strings.Join(running, ", ")
+	case exitErr != nil && (p.summary == "" || result.Failed == 0):
+		result.Message = fmt.Sprintf("tests ended with %v", exitErr)
+	}
+	if result.Message != "" {
+		result.Message += "\n" + p.tail.String()
+	}
+	result.Complete = result.Message == ""
+
+	return result
+}
+
+// normalizeXCTestIdentifier turns Class#method, as test run takes for
+// Android, into XCTest's Class/method
+func normalizeXCTestIdentifier(identifier string) string {
+	return strings.Replace(identifier, "#", "/", 1)
+}
+
+// runnerAppTestBundle returns the name of the .xctest bundle in a runner app
+func runnerAppTestBundle(runnerApp string) (string, error) {
+	matches, _ := filepath.Glob(filepath.Join(runnerApp, "PlugIns", "*.xctest"))
+	if len(matches) == 0 {
+		return "", fmt.Errorf("%s contains no .xctest bundle in PlugIns", filepath.Base(runnerApp))
+	}
+	return filepath.Base(matches[0]), nil
+}
+
+// xctestrunTargets returns the names of the test targets of an .xctestrun
+// file, for both format versions 1 and 2
+func xctestrunTargets(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content map[string]any
+	if _, err := plist.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+
+	var targets []string
+	if configurations, ok := content["TestConfigurations"].([]any); ok {
+		for _, configuration := range configurations {
+			configurationMap, _ := configuration.(map[string]any)
+			testTargets, _ := configurationMap["TestTargets"].([]any)
+			for _, target := range testTargets {
+				targetMap, _ := target.(map[string]any)
+				if name, ok := targetMap["BlueprintName"].(string); ok {
+					targets = append(targets, name)
+				}
+			}
+		}
+		return targets, nil
+	}
+
+	for name := range content {
+		if !strings.HasPrefix(name, "__") {
+			targets = append(targets, name)
+		}
+	}
+	return targets, nil
+}
+
+// writeRunnerXCTestRun writes an .xctestrun (format version 1) running the
+// UI tests of a runner app against the app under test into dir
+func writeRunnerXCTestRun(dir string, config XCTestConfig) (string, error) {
+	bundle, err := runnerAppTestBundle(config.RunnerApp)
+	if err != nil {
+		return "", err
+	}
+
+	runner, err := filepath.Abs(config.RunnerApp)
+	if err != nil {
+		return "", err
+	}
+
+	meta, err := utils.ParseAppMetadata(runner)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", filepath.Base(runner), err)
+	}
+
+	target := map[string]any{
+		"TestHostPath":                runner,
+		"TestHostBundleIdentifier":    meta.PackageName,
+		"TestBundlePath":              "__TESTHOST__/PlugIns/" + bundle,
+		"IsUITestBundle":              true,
+		"IsXCTRunnerHostedTestBundle": true,
+		"TestingEnvironmentVariables": map[string]any{},
+		"EnvironmentVariables":        map[string]any{},
+		"CommandLineArguments":        []string{},
+	}
+	products := []string{runner}
+	if config.AppPath != "" {
+		app, err := filepath.Abs(config.AppPath)
+		if err != nil {
+			return "", err
+		}
+		target["UITargetAppPath"] = app
+		products = append(products, app)
+	}
+	target["DependentProductPaths"] = products
+
+	content := map[string]any{
+		strings.TrimSuffix(bundle, ".xctest"): target,
+		"__xctestrun_metadata__":              map[string]any{"FormatVersion": 1},
+	}
+	data, err := plist.MarshalIndent(content, plist.XMLFormat, "\t")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode xctestrun: %w", err)
+	}
+
+	path := filepath.Join(dir, "mobilecli.xctestrun")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
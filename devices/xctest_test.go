@@ -0,0 +1,95 @@
+package devices
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const xctestOutput = `Test Suite 'All tests' started at 2026-10-15 10:00:00.000.
+Test Suite 'LoginTests' started at 2026-10-15 10:00:00.001.
+Test Case '-[MyAppUITests.LoginTests testLogin]' started.
+    t =     0.00s Start Test at 2026-10-15 10:00:00.002
+Test Case '-[MyAppUITests.LoginTests testLogin]' passed (1.250 seconds).
+Test Case '-[MyAppUITests.LoginTests testLogout]' started.
+/src/LoginTests.swift:25: error: -[MyAppUITests.LoginTests testLogout] : XCTAssertTrue failed
+Test Case '-[MyAppUITests.LoginTests testLogout]' failed (0.500 seconds).
+Test Case '-[MyAppUITests.LoginTests testSignup]' started.
+Test Case '-[MyAppUITests.LoginTests testSignup]' skipped (0.010 seconds).
+** TEST EXECUTE FAILED **
+`
+
+func TestXCTestParser(t *testing.T) {
+	var events []TestEvent
+	parser := newXCTestParser(func(event TestEvent) { events = append(events, event) })
+
+	// output arrives in arbitrary chunks
+	for i := 0; i < len(xctestOutput); i += 7 {
+		_, err := parser.Write([]byte(xctestOutput[i:min(i+7, len(xctestOutput))]))
+		require.NoError(t, err)
+	}
+	result := parser.finish("MyApp.xctestrun", 2000, errors.New("exit status 65"))
+
+	assert.True(t, result.Complete, result.Message)
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 1, result.Passed)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, 1, result.Skipped)
+
+	require.Len(t, result.Tests, 3)
+	assert.Equal(t, TestCaseResult{Class: "MyAppUITests.LoginTests", Name: "testLogin", Status: TestStatusPassed, Duration: 1250}, result.Tests[0])
+	assert.Equal(t, "/src/LoginTests.swift:25: XCTAssertTrue failed", result.Tests[1].Failure)
+
+	require.Len(t, events, 6)
+	assert.Equal(t, "started", events[0].Event)
+	assert.Equal(t, TestStatusRunning, events[0].Test.Status)
+	assert.Equal(t, "finished", events[1].Event)
+}
+
+func TestXCTestParserParallelFormat(t *testing.T) {
+	parser := newXCTestParser(nil)
+	_, _ = parser.Write([]byte("Test case 'LoginTests.testLogin()' passed on 'Clone 1 of iPhone 16' (2.000 seconds)\n"))
+
+	result := parser.finish("MyApp.xctestrun", 2000, nil)
+	require.Len(t, result.Tests, 1)
+	assert.Equal(t, TestCaseResult{Class: "LoginTests", Name: "testLogin", Status: TestStatusPassed, Duration: 2000}, result.Tests[0])
+}
+
+func TestXCTestParserEndedEarly(t *testing.T) {
+	parser := newXCTestParser(nil)
+	_, _ = parser.Write([]byte("Test Case '-[MyAppUITests.LoginTests testLogin]' started.\nMyApp crashed\n"))
+
+	result := parser.finish("MyApp.xctestrun", 2000, errors.New("exit status 65"))
+	assert.False(t, result.Complete)
+	assert.Contains(t, result.Message, "tests ended while running MyAppUITests.LoginTests/testLogin")
+	assert.Contains(t, result.Message, "MyApp crashed")
+
+	parser = newXCTestParser(nil)
+	_, _ = parser.Write([]byte("xcodebuild: error: Unable to find a destination\n"))
+
+	result = parser.finish("MyApp.xctestrun", 100, errors.New("exit status 70"))
+	assert.False(t, result.Complete)
+	assert.Contains(t, result.Message, "Unable to find a destination")
+}
+
+func TestXCTestOnlyTesting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "MyApp.xctestrun")
+	require.NoError(t, os.WriteFile(path, []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>MyAppUITests</key>
+	<dict><key>TestHostPath</key><string>__TESTROOT__/MyAppUITests-Runner.app</string></dict>
+	<key>__xctestrun_metadata__</key>
+	<dict><key>FormatVersion</key><integer>1</integer></dict>
+</dict>
+</plist>`), 0o644))
+
+	identifiers, err := xctestOnlyTesting(path, []string{"LoginTests#testLogin", "MyAppUITests/SignupTests"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MyAppUITests/LoginTests/testLogin", "MyAppUITests/SignupTests"}, identifiers)
+}
@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// mjpegBoundary starts every part of the multipart MJPEG stream
+var mjpegBoundary = []byte("--BoundaryString")
+
+// mjpegViewerBuffer is how many frames a slow viewer may fall behind before
+// it drops whole frames
+const mjpegViewerBuffer = 8
+
+// mjpegMaxPartSize bounds how much of a part is buffered while waiting for
+// its end, so a stream without boundaries can't grow it forever
+const mjpegMaxPartSize = 16 * 1024 * 1024
+
+// mjpegContentLengthRe matches the Content-Length header of a part
+var mjpegContentLengthRe = regexp.MustCompile(`(?i)\r\nContent-Length:\s*(\d+)`)
+
+// mjpegViewer receives the complete parts of a shared MJPEG capture
+type mjpegViewer struct {
+	frames chan []byte // closed when the capture ends
+}
+
+// mjpegBroadcaster runs one MJPEG capture for a device and multiplexes it to
+// every viewer. The capture stops when the last viewer leaves.
+type mjpegBroadcaster struct {
+	deviceID string
+	cancel   context.CancelFunc
+
+	mu      sync.Mutex
+	viewers map[*mjpegViewer]struct{}
+	closed  bool
+	pending []byte // the part being assembled from capture chunks
+}
+
+// mjpegPartEnd returns the length of the complete part at the start of data,
+// which begins with a boundary, or -1 while more of it is still to come. The
+// part's Content-Length ends it when given, otherwise the next boundary does.
+func mjpegPartEnd(data []byte) int {
+	if headerEnd := bytes.Index(data, []byte("\r\n\r\n")); headerEnd >= 0 {
+		if matches := mjpegContentLengthRe.FindSubmatch(data[:headerEnd+2]); matches != nil {
+			length, err := strconv.Atoi(string(matches[1]))
+			if err == nil {
+				end := headerEnd + 4 + length
+				// the CRLF after the body goes with it
+				if len(data) < end+2 {
+					return -1
+				}
+				if bytes.Equal(data[end:end+2], []byte("\r\n")) {
+					end += 2
+				}
+				return end
+			}
+		}
+	}
+
+	next := bytes.Index(data[len(mjpegBoundary):], mjpegBoundary)
+	if next < 0 {
+		return -1
+	}
+	return len(mjpegBoundary) + next
+}
+
+// publish assembles the capture's chunks into parts and hands each complete
+// one to every viewer, so a viewer never sees part of a frame
+func (b *mjpegBroadcaster) publish(data []byte) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending = append(b.pending, data...)
+
+	for {
+		// skip anything before a boundary, keeping enough to match one
+		// split across chunks
+		start := bytes.Index(b.pending, mjpegBoundary)
+		if start < 0 {
+			keep := min(len(b.pending), len(mjpegBoundary)-1)
+			b.pending = append(b.pending[:0], b.pending[len(b.pending)-keep:]...)
+			break
+		}
+		b.pending = b.pending[start:]
+
+		end := mjpegPartEnd(b.pending)
+		if end < 0 {
+			if len(b.pending) > mjpegMaxPartSize {
+				utils.Debug(utils.SubsystemServer, "dropping oversized MJPEG part of device %s", b.deviceID)
+				b.pending = b.pending[len(mjpegBoundary):]
+				continue
+			}
+			break
+		}
+
+		// the capture reuses its buffer, so the part is copied once and
+		// shared by every viewer
+		b.deliver(bytes.Clone(b.pending[:end]))
+		b.pending = b.pending[end:]
+	}
+
+	return len(b.viewers) > 0
+}
+
+// publishPart hands a complete part of our own, such as a progress
+// notification, to every viewer
+func (b *mjpegBroadcaster) publishPart(part []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deliver(part)
+}
+
+// deliver sends a complete part to every viewer. A viewer whose buffer is
+// full drops the whole part rather than blocking the capture.
+func (b *mjpegBroadcaster) deliver(part []byte) {
+	for viewer := range b.viewers {
+		select {
+		case viewer.frames <- part:
+		default:
+		}
+	}
+}
+
+// close ends the stream for all viewers once the capture has stopped
+func (b *mjpegBroadcaster) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for viewer := range b.viewers {
+		close(viewer.frames)
+	}
+	b.viewers = nil
+}
+
+// broadcasterRegistry holds the running MJPEG capture of each device
+type broadcasterRegistry struct {
+	mu       sync.Mutex
+	byDevice map[string]*mjpegBroadcaster
+}
+
+var mjpegBroadcasters = &broadcasterRegistry{byDevice: make(map[string]*mjpegBroadcaster)}
+
+// subscribe joins the device's MJPEG capture. The first viewer starts it with
+// config's quality, scale and frame rate; later viewers share it whatever
+// settings they asked for.
+func (r *broadcasterRegistry) subscribe(device devices.ControllableDevice, config devices.ScreenCaptureConfig) (*mjpegBroadcaster, *mjpegViewer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	viewer := &mjpegViewer{frames: make(chan []byte, mjpegViewerBuffer)}
+
+	if b := r.byDevice[device.ID()]; b != nil {
+		b.mu.Lock()
+		if !b.closed {
+			b.viewers[viewer] = struct{}{}
+			b.mu.Unlock()
+			utils.Debug(utils.SubsystemServer, "viewer joined MJPEG capture of device %s", device.ID())
+			return b, viewer
+		}
+		b.mu.Unlock()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &mjpegBroadcaster{
+		deviceID: device.ID(),
+		cancel:   cancel,
+		viewers:  map[*mjpegViewer]struct{}{viewer: {}},
+	}
+	r.byDevice[device.ID()] = b
+
+	// the capture outlives the viewer that started it, so progress goes to
+	// every viewer through the stream rather than to the first one only
+	config.Context = ctx
+	config.Format = "mjpeg"
+	config.OnData = b.publish
+	config.OnProgress = func(message string) {
+		if part, err := mjpegProgressPart(message); err == nil {
+			b.publishPart(part)
+		}
+	}
+	if config.Thermal != nil {
+		guard := *config.Thermal
+		onEvent := guard.OnEvent
+		guard.OnEvent = func(event devices.ThermalEvent) {
+			if part, err := mjpegThermalPart(event); err == nil {
+				b.publishPart(part)
+			}
+			if onEvent != nil {
+				onEvent(event)
+			}
+		}
+		config.Thermal = &guard
+	}
+
+	go func() {
+		utils.Debug(utils.SubsystemServer, "starting shared MJPEG capture of device %s", device.ID())
+		if err := devices.StartGuardedScreenCapture(device, config); err != nil {
+			log.Printf("Error starting screen capture: %v", err)
+		}
+
+		cancel()
+		b.close()
+
+		r.mu.Lock()
+		if r.byDevice[device.ID()] == b {
+			delete(r.byDevice, device.ID())
+		}
+		r.mu.Unlock()
+		utils.Debug(utils.SubsystemServer, "shared MJPEG capture of device %s ended", device.ID())
+	}()
+
+	return b, viewer
+}
+
+// unsubscribe leaves the capture, stopping it if this was the last viewer
+func (r *broadcasterRegistry) unsubscribe(b *mjpegBroadcaster, viewer *mjpegViewer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b.mu.Lock()
+	delete(b.viewers, viewer)
+	last := len(b.viewers) == 0
+	b.mu.Unlock()
+
+	if last {
+		b.cancel()
+		if r.byDevice[b.deviceID] == b {
+			delete(r.byDevice, b.deviceID)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"testing"
+)
+
+func receive(t *testing.T, viewer *mjpegViewer) string {
+	t.Helper()
+	select {
+	case frame := <-viewer.frames:
+		return string(frame)
+	default:
+		t.Fatal("expected a frame")
+		return ""
+	}
+}
+
+func expectNothing(t *testing.T, viewer *mjpegViewer) {
+	t.Helper()
+	select {
+	case frame := <-viewer.frames:
+		t.Fatalf("expected no frame, got %q", frame)
+	default:
+	}
+}
+
+const (
+	frame1 = "--BoundaryString\r\nContent-Type: image/jpeg\r\nContent-Length: 6\r\n\r\nframe1\r\n"
+	frame2 = "--BoundaryString\r\nContent-Type: image/jpeg\r\nContent-Length: 6\r\n\r\nframe2\r\n"
+)
+
+// Chunks are assembled into whole parts, so even a viewer that joins while a
+// frame is arriving gets all of it.
+func TestMjpegBroadcasterDeliversWholeFrames(t *testing.T) {
+	first := &mjpegViewer{frames: make(chan []byte, 4)}
+	b := &mjpegBroadcaster{viewers: map[*mjpegViewer]struct{}{first: {}}}
+
+	b.publish([]byte(frame1[:30]))
+	expectNothing(t, first)
+
+	late := &mjpegViewer{frames: make(chan []byte, 4)}
+	b.viewers[late] = struct{}{}
+
+	b.publish([]byte(frame1[30:] + frame2[:10]))
+	for _, viewer := range []*mjpegViewer{first, late} {
+		if got := receive(t, viewer); got != frame1 {
+			t.Fatalf("unexpected frame %q", got)
+		}
+	}
+	expectNothing(t, first)
+
+	b.publish([]byte(frame2[10:]))
+	for _, viewer := range []*mjpegViewer{first, late} {
+		if got := receive(t, viewer); got != frame2 {
+			t.Fatalf("unexpected frame %q", got)
+		}
+	}
+}
+
+// Parts without a Content-Length end at the next boundary.
+func TestMjpegBroadcasterSplitsAtBoundaries(t *testing.T) {
+	viewer := &mjpegViewer{frames: make(chan []byte, 4)}
+	b := &mjpegBroadcaster{viewers: map[*mjpegViewer]struct{}{viewer: {}}}
+
+	b.publish([]byte("jpeg-tail\r\n--BoundaryString\r\n\r\nframe1\r\n--Bound"))
+	expectNothing(t, viewer)
+
+	b.publish([]byte("aryString\r\n\r\nframe2"))
+	if got := receive(t, viewer); got != "--BoundaryString\r\n\r\nframe1\r\n" {
+		t.Fatalf("unexpected frame %q", got)
+	}
+	expectNothing(t, viewer)
+}
+
+// A viewer that falls behind drops whole frames rather than receiving a
+// corrupted one.
+func TestMjpegBroadcasterDropsFramesForSlowViewers(t *testing.T) {
+	slow := &mjpegViewer{frames: make(chan []byte, 1)}
+	b := &mjpegBroadcaster{viewers: map[*mjpegViewer]struct{}{slow: {}}}
+
+	b.publish([]byte(frame1))
+	b.publish([]byte(frame2[:20]))
+	b.publish([]byte(frame2[20:]))
+
+	if got := receive(t, slow); got != frame1 {
+		t.Fatalf("unexpected frame %q", got)
+	}
+	expectNothing(t, slow)
+
+	b.publish([]byte(frame2))
+	if got := receive(t, slow); got != frame2 {
+		t.Fatalf("slow viewer should get the next whole frame, got %q", got)
+	}
+}
+
+// Our own notification parts go out whole, without splitting a frame being
+// assembled.
+func TestMjpegBroadcasterPublishPart(t *testing.T) {
+	viewer := &mjpegViewer{frames: make(chan []byte, 4)}
+	b := &mjpegBroadcaster{viewers: map[*mjpegViewer]struct{}{viewer: {}}}
+
+	b.publish([]byte(frame1[:30]))
+	b.publishPart([]byte("--BoundaryString\r\nContent-Type: application/json\r\n\r\n{}\r\n"))
+	b.publish([]byte(frame1[30:]))
+
+	if got := receive(t, viewer); got != "--BoundaryString\r\nContent-Type: application/json\r\n\r\n{}\r\n" {
+		t.Fatalf("unexpected part %q", got)
+	}
+	if got := receive(t, viewer); got != frame1 {
+		t.Fatalf("unexpected frame %q", got)
+	}
+}
+
+// The capture keeps running only while someone is watching, and closing the
+// broadcaster ends every viewer's stream.
+func TestMjpegBroadcasterClose(t *testing.T) {
+	viewer := &mjpegViewer{frames: make(chan []byte, 1)}
+	b := &mjpegBroadcaster{viewers: map[*mjpegViewer]struct{}{viewer: {}}}
+
+	if !b.publish([]byte(frame1)) {
+		t.Fatal("expected the capture to continue while a viewer is watching")
+	}
+	receive(t, viewer)
+
+	b.close()
+	if _, ok := <-viewer.frames; ok {
+		t.Fatal("expected the viewer's channel to be closed")
+	}
+
+	if b.publish([]byte(frame1)) {
+		t.Fatal("expected the capture to stop without viewers")
+	}
+}
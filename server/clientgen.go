@@ -0,0 +1,506 @@
+package server
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/mobile-next/mobilecli/commands"
+)
+
+// methodParams maps every public method to the params struct its handler
+// unmarshals, or nil for methods that take no params. Clients are generated
+// from these structs, so a new method must be added here as well as to
+// GetMethodRegistry.
+var methodParams = map[string]any{
+	"devices.list":                          DevicesParams{},
+	"forward.list":                          ForwardListParams{},
+	"operations.start":                      OperationsStartParams{},
+	"operations.status":                     OperationParams{},
+	"operations.cancel":                     OperationParams{},
+	"operations.subscribe":                  OperationParams{},
+	"device.screenshot":                     ScreenshotParams{},
+	"device.snapshot":                       SnapshotParams{},
+	"device.screencapture":                  commands.ScreenCaptureRequest{},
+	"device.screencapture.setConfiguration": screenCaptureSetConfigRequest{},
+	"device.screencapture.requestKeyFrame":  screenCaptureKeyFrameRequest{},
+	"device.screencapture.sessions":         nil,
+	"device.io.tap":                         IoTapParams{},
+	"device.io.longpress":                   IoLongPressParams{},
+	"device.io.text":                        IoTextParams{},
+	"device.io.keys":                        IoKeysParams{},
+	"device.io.button":                      IoButtonParams{},
+	"device.io.swipe":                       IoSwipeParams{},
+	"device.io.gesture":                     IoGestureParams{},
+	"device.url":                            URLParams{},
+	"device.reverse":                        ReverseParams{},
+	"device.reverse.list":                   ReverseListParams{},
+	"device.reverse.remove":                 ReverseRemoveParams{},
+	"device.info":                           InfoParams{},
+	"device.props":                          DevicePropsParams{},
+	"device.bugreport":                      DeviceBugReportParams{},
+	"device.lock":                           DeviceLockParams{},
+	"device.media.add":                      MediaAddParams{},
+	"device.contacts.add":                   ContactsAddParams{},
+	"device.time.set":                       TimeSetParams{},
+	"device.time.sync":                      TimeSyncParams{},
+	"device.root.status":                    RootParams{},
+	"device.root.enable":                    RootParams{},
+	"device.sensor.set":                     SensorSetParams{},
+	"device.sensor.shake":                   SensorShakeParams{},
+	"device.call.incoming":                  CallParams{},
+	"device.call.end":                       CallParams{},
+	"device.sms.send":                       SMSSendParams{},
+	"device.storage.info":                   StorageParams{},
+	"device.storage.fill":                   StorageFillParams{},
+	"device.storage.free":                   StorageParams{},
+	"device.users.list":                     UsersParams{},
+	"device.users.create":                   UsersCreateParams{},
+	"device.users.remove":                   UsersRemoveParams{},
+	"device.unlock":                         DeviceLockParams{},
+	"device.wake":                           DeviceLockParams{},
+	"device.screen.off":                     DeviceLockParams{},
+	"device.stayAwake":                      DeviceStayAwakeParams{},
+	"device.window":                         DeviceWindowParams{},
+	"device.labels":                         DeviceLabelsParams{},
+	"device.notifications.list":             NotificationsListParams{},
+	"device.notifications.clear":            NotificationsClearParams{},
+	"device.notifications.tap":              NotificationsTapParams{},
+	"device.io.orientation.get":             IoOrientationGetParams{},
+	"device.io.orientation.set":             IoOrientationSetParams{},
+	"device.boot":                           DeviceBootParams{},
+	"device.authorize":                      DeviceAuthorizeParams{},
+	"device.shutdown":                       DeviceShutdownParams{},
+	"device.reboot":                         DeviceRebootParams{},
+	"device.settings.apply":                 DeviceSettingsApplyParams{},
+	"device.settings.open":                  SystemUIParams{},
+	"device.controlcenter.open":             SystemUIParams{},
+	"device.controlcenter.close":            SystemUIParams{},
+	"device.alert.text":                     AlertParams{},
+	"device.alert.accept":                   AlertParams{},
+	"device.alert.dismiss":                  AlertParams{},
+	"device.dump.ui":                        DumpUIParams{},
+	"device.apps.launch":                    AppsLaunchParams{},
+	"device.apps.terminate":                 AppsTerminateParams{},
+	"device.apps.list":                      AppsListParams{},
+	"device.apps.foreground":                AppsForegroundParams{},
+	"device.apps.running":                   AppsRunningParams{},
+	"device.apps.state":                     AppsStateParams{},
+	"device.apps.crashes":                   AppsCrashesParams{},
+	"device.apps.install":                   AppsInstallParams{},
+	"device.apps.verify":                    AppsVerifyParams{},
+	"device.apps.uninstall":                 AppsUninstallParams{},
+	"device.screenrecord":                   ScreenRecordParams{},
+	"device.screenrecord.stop":              ScreenRecordStopParams{},
+	"device.crashes.list":                   CrashesListParams{},
+	"device.crashes.get":                    CrashesGetParams{},
+	"device.webview.list":                   WebViewListParams{},
+	"device.webview.content":                WebViewParams{},
+	"device.webview.goto":                   WebViewGotoParams{},
+	"device.webview.reload":                 WebViewReloadParams{},
+	"device.webview.goBack":                 WebViewParams{},
+	"device.webview.goForward":              WebViewParams{},
+	"device.webview.url":                    WebViewParams{},
+	"device.webview.title":                  WebViewParams{},
+	"device.webview.query":                  WebViewQueryParams{},
+	"device.webview.evaluate":               WebViewEvaluateParams{},
+	"device.webview.waitForLoadState":       WebViewWaitForLoadStateParams{},
+	"device.webview.devtools.list":          WebViewDevToolsListParams{},
+	"device.webview.dump":                   WebViewDumpParams{},
+	"device.webview.target.evaluate":        WebViewTargetEvaluateParams{},
+	"server.info":                           nil,
+	"server.shutdown":                       nil,
+	"device.apps.path":                      AppsPathParams{},
+	"device.apps.container":                 AppsPathParams{},
+	"device.fs.ls":                          FsLsParams{},
+	"device.fs.pull":                        FsPullParams{},
+	"device.fs.push":                        FsPushParams{},
+	"device.fs.mkdir":                       FsMkdirParams{},
+	"device.fs.rm":                          FsRmParams{},
+}
+
+// streamingMethods answer with a stream rather than a JSON-RPC response, so
+// generated clients leave them out
+var streamingMethods = map[string]bool{
+	"device.screencapture": true,
+}
+
+// Languages GenerateClient can generate a client in
+const (
+	ClientLanguageTypeScript = "typescript"
+	ClientLanguagePython     = "python"
+)
+
+// clientField is a params struct field as a generated client sees it
+type clientField struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+}
+
+// clientMethod is a method as a generated client sees it
+type clientMethod struct {
+	name       string
+	paramsType reflect.Type // nil when the method takes no params
+	fields     []clientField
+}
+
+// allOptional reports whether the method can be called without params
+func (m clientMethod) allOptional() bool {
+	for _, f := range m.fields {
+		if !f.optional {
+			return false
+		}
+	}
+	return true
+}
+
+// clientMethods returns the methods clients are generated for, sorted by name
+func clientMethods() []clientMethod {
+	var methods []clientMethod
+	for name, params := range methodParams {
+		if streamingMethods[name] {
+			continue
+		}
+
+		method := clientMethod{name: name}
+		if params != nil {
+			method.paramsType = reflect.TypeOf(params)
+			method.fields = structFields(method.paramsType)
+		}
+		methods = append(methods, method)
+	}
+
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].name < methods[j].name
+	})
+	return methods
+}
+
+// structFields returns the JSON fields of a struct. Fields are optional when
+// they are omitempty, pointers or booleans, and deviceId always is since the
+// device is auto-selected without it.
+func structFields(t reflect.Type) []clientField {
+	var fields []clientField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		fields = append(fields, clientField{
+			name:     name,
+			typ:      f.Type,
+			optional: strings.Contains(opts, "omitempty") || f.Type.Kind() == reflect.Pointer || f.Type.Kind() == reflect.Bool || name == "deviceId",
+		})
+	}
+	return fields
+}
+
+// GenerateClient returns the source of a client with a typed method for every
+// public JSON-RPC method, in ClientLanguageTypeScript or ClientLanguagePython
+func GenerateClient(language string) (string, error) {
+	switch language {
+	case ClientLanguageTypeScript:
+		return generateTypeScriptClient(clientMethods()), nil
+	case ClientLanguagePython:
+		return generatePythonClient(clientMethods()), nil
+	default:
+		return "", fmt.Errorf("unsupported language '%s', must be '%s' or '%s'", language, ClientLanguageTypeScript, ClientLanguagePython)
+	}
+}
+
+// methodIdentifier turns a method name into an identifier, e.g.
+// "device.io.tap" into "deviceIoTap"
+func methodIdentifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+	return strings.Join(parts, "")
+}
+
+// snakeCase turns a camelCase identifier into snake_case
+func snakeCase(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				sb.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+const generatedHeader = "Code generated by mobilecli server clientgen. DO NOT EDIT."
+
+func generateTypeScriptClient(methods []clientMethod) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "// %s\n\n", generatedHeader)
+
+	// every params struct and the structs they nest, declared once each
+	declared := map[string]bool{}
+	var declare func(t reflect.Type)
+	declare = func(t reflect.Type) {
+		if declared[t.Name()] {
+			return
+		}
+		declared[t.Name()] = true
+
+		var nested []reflect.Type
+		fmt.Fprintf(&sb, "export interface %s {\n", typeScriptName(t))
+		for _, f := range structFields(t) {
+			optional := ""
+			if f.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&sb, "  %s%s: %s;\n", f.name, optional, typeScriptType(f.typ, &nested))
+		}
+		sb.WriteString("}\n\n")
+
+		for _, n := range nested {
+			declare(n)
+		}
+	}
+	for _, m := range methods {
+		if m.paramsType != nil {
+			declare(m.paramsType)
+		}
+	}
+
+	sb.WriteString(`export class MobilecliError extends Error {
+  constructor(message: string, public code: number, public data?: unknown) {
+    super(message);
+  }
+}
+
+// isSecureUrl reports whether a token can be sent to url: over https, or to
+// this machine
+function isSecureUrl(url: string): boolean {
+  const { protocol, hostname } = new URL(url);
+  return protocol === "https:" || hostname === "localhost" || hostname === "127.0.0.1" || hostname === "[::1]";
+}
+
+export class MobilecliClient {
+  private nextId = 1;
+
+  constructor(private url = "http://localhost:12000", private token?: string) {
+    if (token && !isSecureUrl(url)) {
+      throw new Error(` + "`refusing to send the token to ${url} over plain http, use https`" + `);
+    }
+  }
+
+  async call<T = unknown>(method: string, params?: object): Promise<T> {
+    const headers: Record<string, string> = { "Content-Type": "application/json" };
+    if (this.token) {
+      headers["Authorization"] = ` + "`Bearer ${this.token}`" + `;
+    }
+
+    const response = await fetch(` + "`${this.url.replace(/\\/$/, \"\")}/rpc`" + `, {
+      method: "POST",
+      headers,
+      body: JSON.stringify({ jsonrpc: "2.0", id: this.nextId++, method, params: params ?? {} }),
+    });
+
+    const body = await response.json();
+    if (body.error) {
+      throw new MobilecliError(typeof body.error.data === "string" ? body.error.data : body.error.message, body.error.code, body.error.data);
+    }
+    return body.result as T;
+  }
+`)
+
+	for _, m := range methods {
+		fmt.Fprintf(&sb, "\n  /** %s */\n", m.name)
+		switch {
+		case m.paramsType == nil:
+			fmt.Fprintf(&sb, "  %s(): Promise<unknown> {\n    return this.call(%q);\n  }\n", methodIdentifier(m.name), m.name)
+		case m.allOptional():
+			fmt.Fprintf(&sb, "  %s(params: %s = {}): Promise<unknown> {\n    return this.call(%q, params);\n  }\n", methodIdentifier(m.name), typeScriptName(m.paramsType), m.name)
+		default:
+			fmt.Fprintf(&sb, "  %s(params: %s): Promise<unknown> {\n    return this.call(%q, params);\n  }\n", methodIdentifier(m.name), typeScriptName(m.paramsType), m.name)
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// typeScriptName returns the interface name of a struct, exported even when
+// the Go struct isn't
+func typeScriptName(t reflect.Type) string {
+	return strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+}
+
+// typeScriptType returns the TypeScript type of a Go type, collecting the
+// structs it refers to in nested
+func typeScriptType(t reflect.Type, nested *[]reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeScriptType(t.Elem(), nested)
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return typeScriptType(t.Elem(), nested) + "[]"
+	case reflect.Map:
+		return "Record<string, " + typeScriptType(t.Elem(), nested) + ">"
+	case reflect.Struct:
+		*nested = append(*nested, t)
+		return typeScriptName(t)
+	default:
+		return "unknown"
+	}
+}
+
+// pythonKeywords are the JSON field names that can't be Python parameters
+var pythonKeywords = map[string]bool{
+	"from": true, "in": true, "is": true, "class": true, "global": true, "import": true,
+	"lambda": true, "pass": true, "return": true, "with": true, "yield": true,
+}
+
+func generatePythonClient(methods []clientMethod) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", generatedHeader)
+	sb.WriteString(`
+import json
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Optional
+
+
+class MobilecliError(Exception):
+    def __init__(self, message: str, code: int, data: Any = None):
+        super().__init__(message)
+        self.code = code
+        self.data = data
+
+
+def _is_secure_url(url: str) -> bool:
+    """Whether a token can be sent to url: over https, or to this machine."""
+    parsed = urllib.parse.urlparse(url)
+    return parsed.scheme == "https" or parsed.hostname in ("localhost", "127.0.0.1", "::1")
+
+
+class MobilecliClient:
+    def __init__(self, url: str = "http://localhost:12000", token: Optional[str] = None, timeout: float = 300):
+        if token and not _is_secure_url(url):
+            raise ValueError("refusing to send the token to %s over plain http, use https" % url)
+        self.url = url.rstrip("/") + "/rpc"
+        self.token = token
+        self.timeout = timeout
+        self._next_id = 1
+
+    def call(self, method: str, params: Optional[Dict[str, Any]] = None) -> Any:
+        body = json.dumps({"jsonrpc": "2.0", "id": self._next_id, "method": method, "params": params or {}})
+        self._next_id += 1
+
+        headers = {"Content-Type": "application/json"}
+        if self.token:
+            headers["Authorization"] = "Bearer " + self.token
+
+        request = urllib.request.Request(self.url, data=body.encode(), headers=headers, method="POST")
+        with urllib.request.urlopen(request, timeout=self.timeout) as response:
+            result = json.loads(response.read())
+
+        error = result.get("error")
+        if error:
+            data = error.get("data")
+            raise MobilecliError(data if isinstance(data, str) and data else error.get("message", ""), error.get("code", 0), data)
+        return result.get("result")
+`)
+
+	for _, m := range methods {
+		var required, optional []clientField
+		for _, f := range m.fields {
+			if f.optional {
+				optional = append(optional, f)
+			} else {
+				required = append(required, f)
+			}
+		}
+
+		args := []string{"self"}
+		for _, f := range required {
+			args = append(args, fmt.Sprintf("%s: %s", pythonParam(f.name), pythonType(f.typ)))
+		}
+		for _, f := range optional {
+			args = append(args, fmt.Sprintf("%s: Optional[%s] = None", pythonParam(f.name), pythonType(f.typ)))
+		}
+
+		fmt.Fprintf(&sb, "\n    def %s(%s) -> Any:\n", snakeCase(methodIdentifier(m.name)), strings.Join(args, ", "))
+		fmt.Fprintf(&sb, "        \"\"\"%s\"\"\"\n", m.name)
+		if m.paramsType == nil {
+			fmt.Fprintf(&sb, "        return self.call(%q)\n", m.name)
+			continue
+		}
+
+		sb.WriteString("        params: Dict[str, Any] = {")
+		for i, f := range required {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			fmt.Fprintf(&sb, "%q: %s", f.name, pythonParam(f.name))
+		}
+		sb.WriteString("}\n")
+		for _, f := range optional {
+			fmt.Fprintf(&sb, "        if %s is not None:\n            params[%q] = %s\n", pythonParam(f.name), f.name, pythonParam(f.name))
+		}
+		fmt.Fprintf(&sb, "        return self.call(%q, params)\n", m.name)
+	}
+
+	return sb.String()
+}
+
+// pythonParam returns the Python parameter name of a JSON field
+func pythonParam(name string) string {
+	param := snakeCase(name)
+	if pythonKeywords[param] {
+		param += "_"
+	}
+	return param
+}
+
+// pythonType returns the Python type hint of a Go type
+func pythonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return pythonType(t.Elem())
+	case reflect.String:
+		return "str"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.Slice, reflect.Array:
+		return "List[" + pythonType(t.Elem()) + "]"
+	case reflect.Map:
+		return "Dict[str, " + pythonType(t.Elem()) + "]"
+	case reflect.Struct:
+		return "Dict[str, Any]"
+	default:
+		return "Any"
+	}
+}
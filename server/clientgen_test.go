@@ -0,0 +1,69 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMethodParamsCoverRegistry(t *testing.T) {
+	for name := range GetMethodRegistry() {
+		if _, ok := methodParams[name]; !ok {
+			t.Errorf("method %s has no entry in methodParams, generated clients would miss it", name)
+		}
+	}
+}
+
+func TestGenerateTypeScriptClient(t *testing.T) {
+	source, err := GenerateClient(ClientLanguageTypeScript)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"export interface IoTapParams {\n  deviceId?: string;\n  x?: number;\n  y?: number;\n",
+		"export interface IoTextParams {\n  deviceId?: string;\n  text: string;\n  clear?: boolean;\n  ensureUnlocked?: boolean;\n  handleDialogs?: string;\n}",
+		"  normalized?: NormalizedPoint;",
+		"  clip?: ScreenElementRect;",
+		"export interface ScreenCaptureSetConfigRequest {",
+		"  deviceIoTap(params: IoTapParams = {}): Promise<unknown> {\n    return this.call(\"device.io.tap\", params);",
+		"  deviceIoText(params: IoTextParams): Promise<unknown> {\n    return this.call(\"device.io.text\", params);",
+		"  devicesList(params: DevicesParams = {}): Promise<unknown> {",
+		"  serverInfo(): Promise<unknown> {",
+		"    if (token && !isSecureUrl(url)) {",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("expected the TypeScript client to contain %q", want)
+		}
+	}
+
+	if strings.Contains(source, "deviceScreencapture(") {
+		t.Errorf("expected the streaming device.screencapture method to be left out")
+	}
+}
+
+func TestGeneratePythonClient(t *testing.T) {
+	source, err := GenerateClient(ClientLanguagePython)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, want := range []string{
+		"    def device_io_tap(self, device_id: Optional[str] = None, x: Optional[int] = None, y: Optional[int] = None,",
+		"    def device_io_text(self, text: str, device_id: Optional[str] = None, clear: Optional[bool] = None, ensure_unlocked: Optional[bool] = None, handle_dialogs: Optional[str] = None) -> Any:",
+		"        params: Dict[str, Any] = {\"text\": text}\n        if device_id is not None:\n            params[\"deviceId\"] = device_id\n",
+		"    def device_apps_launch(self, bundle_id: str, device_id: Optional[str] = None, locales: Optional[List[str]] = None, activity: Optional[str] = None,",
+		"    def device_io_keys(self, keys: List[str], device_id: Optional[str] = None, ensure_unlocked: Optional[bool] = None) -> Any:",
+		"    def server_info(self) -> Any:",
+		"        if token and not _is_secure_url(url):",
+	} {
+		if !strings.Contains(source, want) {
+			t.Errorf("expected the Python client to contain %q", want)
+		}
+	}
+}
+
+func TestGenerateClientUnsupportedLanguage(t *testing.T) {
+	if _, err := GenerateClient("ruby"); err == nil {
+		t.Error("expected an error for an unsupported language")
+	}
+}
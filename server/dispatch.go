@@ -3,20 +3,41 @@ package server
 import (
 	"encoding/json"
 	"fmt"
+	"sync"
 )
 
 // HandlerFunc is the signature for non-streaming JSON-RPC method handlers
 type HandlerFunc func(params json.RawMessage) (any, error)
 
+var (
+	extraMethodsMu sync.RWMutex
+	extraMethods   = map[string]HandlerFunc{}
+)
+
+// RegisterMethod adds a method to the registry, e.g. daemon-only methods
+// that are not part of the public HTTP API
+func RegisterMethod(name string, handler HandlerFunc) {
+	extraMethodsMu.Lock()
+	defer extraMethodsMu.Unlock()
+	extraMethods[name] = handler
+}
+
 // GetMethodRegistry returns a map of method names to handler functions
 // This is used by both the HTTP server and embedded clients
 func GetMethodRegistry() map[string]HandlerFunc {
-	return map[string]HandlerFunc{
+	registry := map[string]HandlerFunc{
 		"devices.list":                          handleDevicesList,
+		"forward.list":                          handleForwardList,
+		"operations.start":                      handleOperationsStart,
+		"operations.status":                     handleOperationsStatus,
+		"operations.cancel":                     handleOperationsCancel,
+		"operations.subscribe":                  handleOperationsSubscribe,
 		"device.screenshot":                     handleScreenshot,
+		"device.snapshot":                       handleSnapshot,
 		"device.screencapture":                  handleScreenCaptureSession,
 		"device.screencapture.setConfiguration": handleScreenCaptureSetConfiguration,
 		"device.screencapture.requestKeyFrame":  handleScreenCaptureRequestKeyFrame,
+		"device.screencapture.sessions":         handleScreenCaptureSessions,
 		"device.io.tap":                         handleIoTap,
 		"device.io.longpress":                   handleIoLongPress,
 		"device.io.text":                        handleIoText,
@@ -25,19 +46,62 @@ func GetMethodRegistry() map[string]HandlerFunc {
 		"device.io.swipe":                       handleIoSwipe,
 		"device.io.gesture":                     handleIoGesture,
 		"device.url":                            handleURL,
+		"device.reverse":                        handleReverse,
+		"device.reverse.list":                   handleReverseList,
+		"device.reverse.remove":                 handleReverseRemove,
 		"device.info":                           handleDeviceInfo,
+		"device.props":                          handleDeviceProps,
+		"device.bugreport":                      handleDeviceBugReport,
+		"device.lock":                           handleDeviceLock,
+		"device.unlock":                         handleDeviceUnlock,
+		"device.wake":                           handleDeviceWake,
+		"device.screen.off":                     handleDeviceScreenOff,
+		"device.stayAwake":                      handleDeviceStayAwake,
+		"device.window":                         handleDeviceWindow,
+		"device.labels":                         handleDeviceLabels,
+		"device.media.add":                      handleMediaAdd,
+		"device.contacts.add":                   handleContactsAdd,
+		"device.time.set":                       handleTimeSet,
+		"device.time.sync":                      handleTimeSync,
+		"device.root.status":                    handleRootStatus,
+		"device.root.enable":                    handleRootEnable,
+		"device.sensor.set":                     handleSensorSet,
+		"device.sensor.shake":                   handleSensorShake,
+		"device.call.incoming":                  handleCallIncoming,
+		"device.call.end":                       handleCallEnd,
+		"device.sms.send":                       handleSMSSend,
+		"device.storage.info":                   handleStorageInfo,
+		"device.storage.fill":                   handleStorageFill,
+		"device.storage.free":                   handleStorageFree,
+		"device.users.list":                     handleUsersList,
+		"device.users.create":                   handleUsersCreate,
+		"device.users.remove":                   handleUsersRemove,
+		"device.notifications.list":             handleNotificationsList,
+		"device.notifications.clear":            handleNotificationsClear,
+		"device.notifications.tap":              handleNotificationsTap,
 		"device.io.orientation.get":             handleIoOrientationGet,
 		"device.io.orientation.set":             handleIoOrientationSet,
 		"device.boot":                           handleDeviceBoot,
+		"device.authorize":                      handleDeviceAuthorize,
 		"device.shutdown":                       handleDeviceShutdown,
 		"device.reboot":                         handleDeviceReboot,
 		"device.settings.apply":                 handleSettingsApply,
+		"device.settings.open":                  handleSettingsOpen,
+		"device.controlcenter.open":             handleControlCenterOpen,
+		"device.controlcenter.close":            handleControlCenterClose,
+		"device.alert.text":                     handleAlertText,
+		"device.alert.accept":                   handleAlertAccept,
+		"device.alert.dismiss":                  handleAlertDismiss,
 		"device.dump.ui":                        handleDumpUI,
 		"device.apps.launch":                    handleAppsLaunch,
 		"device.apps.terminate":                 handleAppsTerminate,
 		"device.apps.list":                      handleAppsList,
 		"device.apps.foreground":                handleAppsForeground,
+		"device.apps.running":                   handleAppsRunning,
+		"device.apps.state":                     handleAppsState,
+		"device.apps.crashes":                   handleAppsCrashes,
 		"device.apps.install":                   handleAppsInstall,
+		"device.apps.verify":                    handleAppsVerify,
 		"device.apps.uninstall":                 handleAppsUninstall,
 		"device.screenrecord":                   handleScreenRecord,
 		"device.screenrecord.stop":              handleScreenRecordStop,
@@ -54,15 +118,33 @@ func GetMethodRegistry() map[string]HandlerFunc {
 		"device.webview.query":                  handleWebViewQuery,
 		"device.webview.evaluate":               handleWebViewEvaluate,
 		"device.webview.waitForLoadState":       handleWebViewWaitForLoadState,
+		"device.webview.devtools.list":          handleWebViewDevToolsList,
+		"device.webview.dump":                   handleWebViewDump,
+		"device.webview.target.evaluate":        handleWebViewTargetEvaluate,
 		"server.info":                           handleServerInfo,
 		"server.shutdown":                       handleServerShutdown,
 		"device.apps.path":                      handleAppsPath,
+		"device.apps.container":                 handleAppsContainer,
 		"device.fs.ls":                          handleFsLs,
 		"device.fs.pull":                        handleFsPull,
 		"device.fs.push":                        handleFsPush,
 		"device.fs.mkdir":                       handleFsMkdir,
 		"device.fs.rm":                          handleFsRm,
 	}
+
+	for name, handler := range registry {
+		if invalidatesScreenshots(name) {
+			registry[name] = invalidatingScreenshots(handler)
+		}
+	}
+
+	extraMethodsMu.RLock()
+	defer extraMethodsMu.RUnlock()
+	for name, handler := range extraMethods {
+		registry[name] = handler
+	}
+
+	return registry
 }
 
 // Execute dispatches a method call using the registry
@@ -0,0 +1,423 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/server/grpcapi"
+	"github.com/mobile-next/mobilecli/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultWatchUIInterval is how often WatchUI dumps the UI when the client
+// doesn't say
+const defaultWatchUIInterval = time.Second
+
+var (
+	grpcAddressMu sync.Mutex
+	grpcAddress   string
+)
+
+// SetGRPCAddress makes StartServer also serve the gRPC API on addr. An empty
+// addr doesn't serve it.
+func SetGRPCAddress(addr string) {
+	grpcAddressMu.Lock()
+	grpcAddress = addr
+	grpcAddressMu.Unlock()
+}
+
+// startGRPCServer listens on the address set with SetGRPCAddress, if any, and
+// serves the gRPC API in the background until the shutdown hook runs
+func startGRPCServer(hook *devices.ShutdownHook) error {
+	grpcAddressMu.Lock()
+	addr := grpcAddress
+	grpcAddressMu.Unlock()
+
+	if addr == "" {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for gRPC on %s: %w", addr, err)
+	}
+
+	s := newGRPCServer()
+	hook.Register("grpc", func() error {
+		s.Stop()
+		return nil
+	})
+
+	utils.Info("Serving gRPC on %s...", listener.Addr())
+	go func() {
+		if err := s.Serve(listener); err != nil {
+			utils.Info("gRPC server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// newGRPCServer returns a gRPC server with the MobileCLI service registered,
+// and server reflection for tools such as grpcurl
+func newGRPCServer() *grpc.Server {
+	s := grpc.NewServer()
+	grpcapi.RegisterMobileCLIServer(s, &grpcService{})
+	reflection.Register(s)
+	return s
+}
+
+// grpcService implements the gRPC API on top of the JSON-RPC method registry
+// and the commands layer, so both APIs behave the same
+type grpcService struct {
+	grpcapi.UnimplementedMobileCLIServer
+}
+
+// callMethod invokes a registry method with params marshalled to JSON, so
+// typed calls go through the same handlers, and screenshot invalidation, as
+// JSON-RPC ones
+func callMethod(method string, params any) (any, error) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid parameters: %v", err)
+	}
+	return Execute(method, data)
+}
+
+func (s *grpcService) Call(ctx context.Context, req *grpcapi.CallRequest) (*grpcapi.CallResponse, error) {
+	handler, exists := GetMethodRegistry()[req.GetMethod()]
+	if !exists {
+		return nil, status.Errorf(codes.Unimplemented, "method not found: %s", req.GetMethod())
+	}
+
+	var params json.RawMessage
+	if req.GetParamsJson() != "" {
+		if !json.Valid([]byte(req.GetParamsJson())) {
+			return nil, status.Error(codes.InvalidArgument, "params_json is not valid JSON")
+		}
+		params = json.RawMessage(req.GetParamsJson())
+	}
+
+	result, err := handler(params)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return &grpcapi.CallResponse{ResultJson: string(data)}, nil
+}
+
+func (s *grpcService) ListDevices(ctx context.Context, req *grpcapi.ListDevicesRequest) (*grpcapi.ListDevicesResponse, error) {
+	response := commands.DevicesCommand(devices.DeviceListOptions{
+		IncludeOffline: req.GetIncludeOffline(),
+		Platform:       req.GetPlatform(),
+		DeviceType:     req.GetType(),
+	}, commands.GetFleetToken())
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	data, ok := response.Data.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format")
+	}
+	list, _ := data["devices"].([]devices.DeviceInfo)
+
+	result := &grpcapi.ListDevicesResponse{}
+	for _, d := range list {
+		result.Devices = append(result.Devices, &grpcapi.Device{
+			Id:        d.ID,
+			Name:      d.Name,
+			Platform:  d.Platform,
+			Type:      d.Type,
+			Version:   d.Version,
+			State:     d.State,
+			Model:     d.Model,
+			Transport: d.Transport,
+		})
+	}
+	return result, nil
+}
+
+func (s *grpcService) Screenshot(ctx context.Context, req *grpcapi.ScreenshotRequest) (*grpcapi.ScreenshotResponse, error) {
+	device, err := commands.FindDeviceOrAutoSelect(req.GetDeviceId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	key := screenshotCacheKey{deviceID: device.ID(), format: strings.ToLower(req.GetFormat()), quality: int(req.GetQuality())}
+	if key.format == "" {
+		key.format = "png"
+	}
+	if key.format != "jpeg" {
+		key.quality = 0
+	}
+
+	entry, err := screenshots.get(key, func() ([]byte, error) {
+		return captureScreenshot(key)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.ScreenshotResponse{Format: key.format, Data: entry.data}, nil
+}
+
+func (s *grpcService) DumpUI(ctx context.Context, req *grpcapi.DumpUIRequest) (*grpcapi.DumpUIResponse, error) {
+	response := commands.DumpUICommand(commands.DumpUIRequest{
+		DeviceID:     req.GetDeviceId(),
+		Query:        req.GetQuery(),
+		MaxDepth:     int(req.GetMaxDepth()),
+		MaxElements:  int(req.GetMaxElements()),
+		ViewportOnly: req.GetViewportOnly(),
+		Output:       "-",
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	dump, ok := response.Data.(commands.DumpUIResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format")
+	}
+	return &grpcapi.DumpUIResponse{Elements: screenElementsToProto(dump.Elements), Cached: dump.Cached}, nil
+}
+
+func (s *grpcService) WatchUI(req *grpcapi.WatchUIRequest, stream grpc.ServerStreamingServer[grpcapi.DumpUIResponse]) error {
+	interval := defaultWatchUIInterval
+	if req.GetIntervalMs() > 0 {
+		interval = time.Duration(req.GetIntervalMs()) * time.Millisecond
+	}
+
+	dumpRequest := req.GetDump()
+	if dumpRequest == nil {
+		dumpRequest = &grpcapi.DumpUIRequest{}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *grpcapi.DumpUIResponse
+	for {
+		dump, err := s.DumpUI(stream.Context(), dumpRequest)
+		if err != nil {
+			return err
+		}
+
+		// cached only says how the dump was made, not whether the UI changed
+		if last == nil || !proto.Equal(&grpcapi.DumpUIResponse{Elements: dump.Elements}, &grpcapi.DumpUIResponse{Elements: last.Elements}) {
+			if err := stream.Send(dump); err != nil {
+				return err
+			}
+			last = dump
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *grpcService) Tap(ctx context.Context, req *grpcapi.TapRequest) (*grpcapi.InputResponse, error) {
+	_, err := callMethod("device.io.tap", IoTapParams{
+		DeviceID: req.GetDeviceId(),
+		X:        int(req.GetX()),
+		Y:        int(req.GetY()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.InputResponse{}, nil
+}
+
+func (s *grpcService) Swipe(ctx context.Context, req *grpcapi.SwipeRequest) (*grpcapi.InputResponse, error) {
+	_, err := callMethod("device.io.swipe", IoSwipeParams{
+		DeviceID:   req.GetDeviceId(),
+		X1:         int(req.GetX1()),
+		Y1:         int(req.GetY1()),
+		X2:         int(req.GetX2()),
+		Y2:         int(req.GetY2()),
+		DurationMs: int(req.GetDurationMs()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.InputResponse{}, nil
+}
+
+func (s *grpcService) TypeText(ctx context.Context, req *grpcapi.TypeTextRequest) (*grpcapi.InputResponse, error) {
+	_, err := callMethod("device.io.text", IoTextParams{
+		DeviceID: req.GetDeviceId(),
+		Text:     req.GetText(),
+		Clear:    req.GetClear(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.InputResponse{}, nil
+}
+
+func (s *grpcService) PressButton(ctx context.Context, req *grpcapi.PressButtonRequest) (*grpcapi.InputResponse, error) {
+	_, err := callMethod("device.io.button", IoButtonParams{
+		DeviceID: req.GetDeviceId(),
+		Button:   req.GetButton(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &grpcapi.InputResponse{}, nil
+}
+
+// ScreenFrames joins the device's shared MJPEG capture, like an MJPEG viewer
+// of /stream, and sends the JPEG of every frame part. Progress and thermal
+// notifications are left out.
+func (s *grpcService) ScreenFrames(req *grpcapi.ScreenFramesRequest, stream grpc.ServerStreamingServer[grpcapi.ScreenFrame]) error {
+	quality := int(req.GetQuality())
+	scale := req.GetScale()
+	fps := int(req.GetFps())
+	if err := commands.ValidateScreenCaptureOptions(quality, scale, fps); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if quality == 0 {
+		quality = devices.DefaultQuality
+	}
+	if scale == 0 {
+		scale = devices.DefaultScale
+	}
+
+	device, err := commands.FindDeviceOrAutoSelect(req.GetDeviceId())
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	if err := device.StartAgent(devices.StartAgentConfig{Hook: commands.GetShutdownHook()}); err != nil {
+		return fmt.Errorf("error starting agent: %w", err)
+	}
+
+	captureStream := &CaptureStream{
+		DeviceID: device.ID(),
+		Format:   "mjpeg",
+		Quality:  quality,
+		Scale:    scale,
+		FPS:      fps,
+	}
+	ctx := captureStreams.start(stream.Context(), captureStream)
+	defer captureStreams.finish(captureStream)
+
+	broadcaster, viewer := mjpegBroadcasters.subscribe(device, devices.ScreenCaptureConfig{
+		Quality: quality,
+		Scale:   scale,
+		FPS:     fps,
+	})
+	defer mjpegBroadcasters.unsubscribe(broadcaster, viewer)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case part, ok := <-viewer.frames:
+			if !ok {
+				return nil
+			}
+			jpeg, ok := mjpegPartJPEG(part)
+			if !ok {
+				continue
+			}
+			if err := stream.Send(&grpcapi.ScreenFrame{Jpeg: jpeg}); err != nil {
+				return err
+			}
+			captureStream.Sent(len(jpeg))
+		}
+	}
+}
+
+// Logs streams the device's system log until the client cancels
+func (s *grpcService) Logs(req *grpcapi.LogsRequest, stream grpc.ServerStreamingServer[grpcapi.LogLine]) error {
+	device, err := commands.FindDeviceOrAutoSelect(req.GetDeviceId())
+	if err != nil {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	streamer, ok := device.(devices.LogStreamer)
+	if !ok {
+		return status.Errorf(codes.Unimplemented, "streaming logs is not supported on %s devices", device.Platform())
+	}
+
+	var sendErr error
+	err = streamer.StreamLogs(stream.Context(), func(line string) bool {
+		sendErr = stream.Send(&grpcapi.LogLine{Line: line})
+		return sendErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	return sendErr
+}
+
+// mjpegPartJPEG returns the body of a multipart MJPEG part when it is an
+// image, and false for notification parts
+func mjpegPartJPEG(part []byte) ([]byte, bool) {
+	headerEnd := bytes.Index(part, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return nil, false
+	}
+
+	header := strings.ToLower(string(part[:headerEnd]))
+	if !strings.Contains(header, "content-type: image/") {
+		return nil, false
+	}
+
+	body := part[headerEnd+4:]
+	if matches := mjpegContentLengthRe.FindSubmatch(part[:headerEnd+2]); matches != nil {
+		if length, err := strconv.Atoi(string(matches[1])); err == nil && length <= len(body) {
+			return body[:length], true
+		}
+	}
+	return bytes.TrimSuffix(body, []byte("\r\n")), true
+}
+
+// screenElementsToProto converts a UI dump to its gRPC messages
+func screenElementsToProto(elements []devices.ScreenElement) []*grpcapi.ScreenElement {
+	if len(elements) == 0 {
+		return nil
+	}
+
+	result := make([]*grpcapi.ScreenElement, len(elements))
+	for i, e := range elements {
+		result[i] = &grpcapi.ScreenElement{
+			Type:        e.Type,
+			Label:       e.Label,
+			Text:        e.Text,
+			Name:        e.Name,
+			Value:       e.Value,
+			Placeholder: e.Placeholder,
+			Identifier:  e.Identifier,
+			Rect: &grpcapi.Rect{
+				X:      int32(e.Rect.X),
+				Y:      int32(e.Rect.Y),
+				Width:  int32(e.Rect.Width),
+				Height: int32(e.Rect.Height),
+			},
+			Focused:  e.Focused,
+			Children: screenElementsToProto(e.Children),
+		}
+	}
+	return result
+}
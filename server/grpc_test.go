@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+	"github.com/mobile-next/mobilecli/server/grpcapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTestClient serves the gRPC API over an in-memory connection, with
+// two fake devices
+func newGRPCTestClient(t *testing.T) grpcapi.MobileCLIClient {
+	t.Helper()
+	t.Setenv(devices.FakeDevicesEnvVar, "2")
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	_, _ = devices.GetAllControllableDevices(false)
+
+	listener := bufconn.Listen(1024 * 1024)
+	s := newGRPCServer()
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return grpcapi.NewMobileCLIClient(conn)
+}
+
+func TestGRPCCall(t *testing.T) {
+	client := newGRPCTestClient(t)
+
+	response, err := client.Call(context.Background(), &grpcapi.CallRequest{Method: "server.info"})
+	if err != nil {
+		t.Fatalf("server.info failed: %v", err)
+	}
+	if response.GetResultJson() == "" || response.GetResultJson()[0] != '{' {
+		t.Errorf("expected a JSON object, got %q", response.GetResultJson())
+	}
+
+	_, err = client.Call(context.Background(), &grpcapi.CallRequest{Method: "no.such.method"})
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("expected Unimplemented for an unknown method, got %v", err)
+	}
+
+	_, err = client.Call(context.Background(), &grpcapi.CallRequest{Method: "device.info", ParamsJson: "{"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for bad params, got %v", err)
+	}
+}
+
+func TestGRPCInput(t *testing.T) {
+	client := newGRPCTestClient(t)
+
+	_, err := client.Tap(context.Background(), &grpcapi.TapRequest{DeviceId: "fake-ios-2", X: 10, Y: 20})
+	if err != nil {
+		t.Fatalf("tap failed: %v", err)
+	}
+
+	if actions := fake.Get("fake-ios-2").Actions(); !slices.Contains(actions, "tap 10,20") {
+		t.Errorf("expected the tap to reach the device, got %v", actions)
+	}
+}
+
+func TestGRPCDumpUI(t *testing.T) {
+	client := newGRPCTestClient(t)
+
+	response, err := client.DumpUI(context.Background(), &grpcapi.DumpUIRequest{DeviceId: "fake-ios-2"})
+	if err != nil {
+		t.Fatalf("dump failed: %v", err)
+	}
+
+	elements := response.GetElements()
+	if len(elements) != 2 || elements[0].GetType() != "Button" || elements[0].GetLabel() != "OK" {
+		t.Fatalf("unexpected elements: %v", elements)
+	}
+	if rect := elements[0].GetRect(); rect.GetX() != 40 || rect.GetY() != 200 || rect.GetWidth() != 200 || rect.GetHeight() != 80 {
+		t.Errorf("unexpected rect: %v", rect)
+	}
+}
+
+func TestGRPCScreenFrames(t *testing.T) {
+	client := newGRPCTestClient(t)
+	fake.Get("fake-ios-2").SetScreenshot([]byte("--BoundaryString\r\nContent-Type: image/jpeg\r\nContent-Length: 5\r\n\r\nframe\r\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.ScreenFrames(ctx, &grpcapi.ScreenFramesRequest{DeviceId: "fake-ios-2", Fps: 30})
+	if err != nil {
+		t.Fatalf("failed to start stream: %v", err)
+	}
+
+	for range 2 {
+		frame, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("failed to receive frame: %v", err)
+		}
+		if string(frame.GetJpeg()) != "frame" {
+			t.Fatalf("unexpected frame %q", frame.GetJpeg())
+		}
+	}
+}
+
+func TestGRPCScreenFramesInvalidOptions(t *testing.T) {
+	client := newGRPCTestClient(t)
+
+	stream, err := client.ScreenFrames(context.Background(), &grpcapi.ScreenFramesRequest{DeviceId: "fake-ios-2", Quality: 500})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestGRPCLogs(t *testing.T) {
+	client := newGRPCTestClient(t)
+	fake.Get("fake-android-1").SetLogs([]string{"first line", "second line"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Logs(ctx, &grpcapi.LogsRequest{DeviceId: "fake-android-1"})
+	if err != nil {
+		t.Fatalf("failed to start stream: %v", err)
+	}
+
+	for _, want := range []string{"first line", "second line"} {
+		line, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("failed to receive line: %v", err)
+		}
+		if line.GetLine() != want {
+			t.Errorf("expected %q, got %q", want, line.GetLine())
+		}
+	}
+}
+
+func TestMjpegPartJPEG(t *testing.T) {
+	jpeg, ok := mjpegPartJPEG([]byte("--BoundaryString\r\nContent-Type: image/jpeg\r\nContent-Length: 4\r\n\r\nJPEG\r\n"))
+	if !ok || string(jpeg) != "JPEG" {
+		t.Errorf("expected the image body, got %q %v", jpeg, ok)
+	}
+
+	jpeg, ok = mjpegPartJPEG([]byte("--BoundaryString\r\nContent-Type: image/jpeg\r\n\r\nJPEG\r\n"))
+	if !ok || string(jpeg) != "JPEG" {
+		t.Errorf("expected the image body without a Content-Length, got %q %v", jpeg, ok)
+	}
+
+	part, err := mjpegProgressPart("Starting Agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mjpegPartJPEG(part); ok {
+		t.Error("expected a notification part to be skipped")
+	}
+}
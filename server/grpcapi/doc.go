@@ -0,0 +1,5 @@
+// Package grpcapi is the gRPC service definition of the mobilecli server and
+// the code protoc generates from it
+package grpcapi
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative mobilecli.proto
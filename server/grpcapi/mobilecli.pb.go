@@ -0,0 +1,1509 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.12
+// 	protoc        (unknown)
+// source: mobilecli.proto
+
+package grpcapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CallRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Method string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// params_json is the method's params as JSON, empty for none
+	ParamsJson    string `protobuf:"bytes,2,opt,name=params_json,json=paramsJson,proto3" json:"params_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallRequest) Reset() {
+	*x = CallRequest{}
+	mi := &file_mobilecli_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallRequest) ProtoMessage() {}
+
+func (x *CallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallRequest.ProtoReflect.Descriptor instead.
+func (*CallRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CallRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *CallRequest) GetParamsJson() string {
+	if x != nil {
+		return x.ParamsJson
+	}
+	return ""
+}
+
+type CallResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResultJson    string                 `protobuf:"bytes,1,opt,name=result_json,json=resultJson,proto3" json:"result_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CallResponse) Reset() {
+	*x = CallResponse{}
+	mi := &file_mobilecli_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallResponse) ProtoMessage() {}
+
+func (x *CallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallResponse.ProtoReflect.Descriptor instead.
+func (*CallResponse) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CallResponse) GetResultJson() string {
+	if x != nil {
+		return x.ResultJson
+	}
+	return ""
+}
+
+type ListDevicesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IncludeOffline bool                   `protobuf:"varint,1,opt,name=include_offline,json=includeOffline,proto3" json:"include_offline,omitempty"`
+	Platform       string                 `protobuf:"bytes,2,opt,name=platform,proto3" json:"platform,omitempty"` // "ios" or "android"
+	Type           string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`         // "real", "simulator" or "emulator"
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListDevicesRequest) Reset() {
+	*x = ListDevicesRequest{}
+	mi := &file_mobilecli_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesRequest) ProtoMessage() {}
+
+func (x *ListDevicesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesRequest.ProtoReflect.Descriptor instead.
+func (*ListDevicesRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListDevicesRequest) GetIncludeOffline() bool {
+	if x != nil {
+		return x.IncludeOffline
+	}
+	return false
+}
+
+func (x *ListDevicesRequest) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ListDevicesRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type Device struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Platform      string                 `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Version       string                 `protobuf:"bytes,5,opt,name=version,proto3" json:"version,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	Model         string                 `protobuf:"bytes,7,opt,name=model,proto3" json:"model,omitempty"`
+	Transport     string                 `protobuf:"bytes,8,opt,name=transport,proto3" json:"transport,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Device) Reset() {
+	*x = Device{}
+	mi := &file_mobilecli_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Device) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Device) ProtoMessage() {}
+
+func (x *Device) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Device.ProtoReflect.Descriptor instead.
+func (*Device) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Device) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Device) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Device) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *Device) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Device) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *Device) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Device) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Device) GetTransport() string {
+	if x != nil {
+		return x.Transport
+	}
+	return ""
+}
+
+type ListDevicesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       []*Device              `protobuf:"bytes,1,rep,name=devices,proto3" json:"devices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDevicesResponse) Reset() {
+	*x = ListDevicesResponse{}
+	mi := &file_mobilecli_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDevicesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDevicesResponse) ProtoMessage() {}
+
+func (x *ListDevicesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDevicesResponse.ProtoReflect.Descriptor instead.
+func (*ListDevicesResponse) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDevicesResponse) GetDevices() []*Device {
+	if x != nil {
+		return x.Devices
+	}
+	return nil
+}
+
+type ScreenshotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`    // "png" (default) or "jpeg"
+	Quality       int32                  `protobuf:"varint,3,opt,name=quality,proto3" json:"quality,omitempty"` // 1-100, jpeg only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenshotRequest) Reset() {
+	*x = ScreenshotRequest{}
+	mi := &file_mobilecli_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotRequest) ProtoMessage() {}
+
+func (x *ScreenshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotRequest.ProtoReflect.Descriptor instead.
+func (*ScreenshotRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ScreenshotRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ScreenshotRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ScreenshotRequest) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+type ScreenshotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenshotResponse) Reset() {
+	*x = ScreenshotResponse{}
+	mi := &file_mobilecli_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenshotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotResponse) ProtoMessage() {}
+
+func (x *ScreenshotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotResponse.ProtoReflect.Descriptor instead.
+func (*ScreenshotResponse) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ScreenshotResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ScreenshotResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type Rect struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rect) Reset() {
+	*x = Rect{}
+	mi := &file_mobilecli_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rect) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rect) ProtoMessage() {}
+
+func (x *Rect) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rect.ProtoReflect.Descriptor instead.
+func (*Rect) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Rect) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Rect) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Rect) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Rect) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type ScreenElement struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Label         *string                `protobuf:"bytes,2,opt,name=label,proto3,oneof" json:"label,omitempty"`
+	Text          *string                `protobuf:"bytes,3,opt,name=text,proto3,oneof" json:"text,omitempty"`
+	Name          *string                `protobuf:"bytes,4,opt,name=name,proto3,oneof" json:"name,omitempty"`
+	Value         *string                `protobuf:"bytes,5,opt,name=value,proto3,oneof" json:"value,omitempty"`
+	Placeholder   *string                `protobuf:"bytes,6,opt,name=placeholder,proto3,oneof" json:"placeholder,omitempty"`
+	Identifier    *string                `protobuf:"bytes,7,opt,name=identifier,proto3,oneof" json:"identifier,omitempty"`
+	Rect          *Rect                  `protobuf:"bytes,8,opt,name=rect,proto3" json:"rect,omitempty"`
+	Focused       *bool                  `protobuf:"varint,9,opt,name=focused,proto3,oneof" json:"focused,omitempty"`
+	Children      []*ScreenElement       `protobuf:"bytes,10,rep,name=children,proto3" json:"children,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenElement) Reset() {
+	*x = ScreenElement{}
+	mi := &file_mobilecli_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenElement) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenElement) ProtoMessage() {}
+
+func (x *ScreenElement) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenElement.ProtoReflect.Descriptor instead.
+func (*ScreenElement) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ScreenElement) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetLabel() string {
+	if x != nil && x.Label != nil {
+		return *x.Label
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetName() string {
+	if x != nil && x.Name != nil {
+		return *x.Name
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetValue() string {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetPlaceholder() string {
+	if x != nil && x.Placeholder != nil {
+		return *x.Placeholder
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetIdentifier() string {
+	if x != nil && x.Identifier != nil {
+		return *x.Identifier
+	}
+	return ""
+}
+
+func (x *ScreenElement) GetRect() *Rect {
+	if x != nil {
+		return x.Rect
+	}
+	return nil
+}
+
+func (x *ScreenElement) GetFocused() bool {
+	if x != nil && x.Focused != nil {
+		return *x.Focused
+	}
+	return false
+}
+
+func (x *ScreenElement) GetChildren() []*ScreenElement {
+	if x != nil {
+		return x.Children
+	}
+	return nil
+}
+
+type DumpUIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"` // see 'mobilecli dump ui --query'
+	MaxDepth      int32                  `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	MaxElements   int32                  `protobuf:"varint,4,opt,name=max_elements,json=maxElements,proto3" json:"max_elements,omitempty"`
+	ViewportOnly  bool                   `protobuf:"varint,5,opt,name=viewport_only,json=viewportOnly,proto3" json:"viewport_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpUIRequest) Reset() {
+	*x = DumpUIRequest{}
+	mi := &file_mobilecli_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpUIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpUIRequest) ProtoMessage() {}
+
+func (x *DumpUIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpUIRequest.ProtoReflect.Descriptor instead.
+func (*DumpUIRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DumpUIRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *DumpUIRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *DumpUIRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *DumpUIRequest) GetMaxElements() int32 {
+	if x != nil {
+		return x.MaxElements
+	}
+	return 0
+}
+
+func (x *DumpUIRequest) GetViewportOnly() bool {
+	if x != nil {
+		return x.ViewportOnly
+	}
+	return false
+}
+
+type DumpUIResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Elements []*ScreenElement       `protobuf:"bytes,1,rep,name=elements,proto3" json:"elements,omitempty"`
+	// cached is true when the dump was reused from an earlier one of the same
+	// screen, see --dump-cache-ms
+	Cached        bool `protobuf:"varint,2,opt,name=cached,proto3" json:"cached,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpUIResponse) Reset() {
+	*x = DumpUIResponse{}
+	mi := &file_mobilecli_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpUIResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpUIResponse) ProtoMessage() {}
+
+func (x *DumpUIResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpUIResponse.ProtoReflect.Descriptor instead.
+func (*DumpUIResponse) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DumpUIResponse) GetElements() []*ScreenElement {
+	if x != nil {
+		return x.Elements
+	}
+	return nil
+}
+
+func (x *DumpUIResponse) GetCached() bool {
+	if x != nil {
+		return x.Cached
+	}
+	return false
+}
+
+type WatchUIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dump          *DumpUIRequest         `protobuf:"bytes,1,opt,name=dump,proto3" json:"dump,omitempty"`
+	IntervalMs    int32                  `protobuf:"varint,2,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"` // 1000 when unset
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchUIRequest) Reset() {
+	*x = WatchUIRequest{}
+	mi := &file_mobilecli_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchUIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchUIRequest) ProtoMessage() {}
+
+func (x *WatchUIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchUIRequest.ProtoReflect.Descriptor instead.
+func (*WatchUIRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchUIRequest) GetDump() *DumpUIRequest {
+	if x != nil {
+		return x.Dump
+	}
+	return nil
+}
+
+func (x *WatchUIRequest) GetIntervalMs() int32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+type TapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	X             int32                  `protobuf:"varint,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,3,opt,name=y,proto3" json:"y,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TapRequest) Reset() {
+	*x = TapRequest{}
+	mi := &file_mobilecli_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TapRequest) ProtoMessage() {}
+
+func (x *TapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TapRequest.ProtoReflect.Descriptor instead.
+func (*TapRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TapRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *TapRequest) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *TapRequest) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+type SwipeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	X1            int32                  `protobuf:"varint,2,opt,name=x1,proto3" json:"x1,omitempty"`
+	Y1            int32                  `protobuf:"varint,3,opt,name=y1,proto3" json:"y1,omitempty"`
+	X2            int32                  `protobuf:"varint,4,opt,name=x2,proto3" json:"x2,omitempty"`
+	Y2            int32                  `protobuf:"varint,5,opt,name=y2,proto3" json:"y2,omitempty"`
+	DurationMs    int32                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"` // 0 for the platform default
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SwipeRequest) Reset() {
+	*x = SwipeRequest{}
+	mi := &file_mobilecli_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SwipeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SwipeRequest) ProtoMessage() {}
+
+func (x *SwipeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SwipeRequest.ProtoReflect.Descriptor instead.
+func (*SwipeRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SwipeRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *SwipeRequest) GetX1() int32 {
+	if x != nil {
+		return x.X1
+	}
+	return 0
+}
+
+func (x *SwipeRequest) GetY1() int32 {
+	if x != nil {
+		return x.Y1
+	}
+	return 0
+}
+
+func (x *SwipeRequest) GetX2() int32 {
+	if x != nil {
+		return x.X2
+	}
+	return 0
+}
+
+func (x *SwipeRequest) GetY2() int32 {
+	if x != nil {
+		return x.Y2
+	}
+	return 0
+}
+
+func (x *SwipeRequest) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type TypeTextRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Clear         bool                   `protobuf:"varint,3,opt,name=clear,proto3" json:"clear,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TypeTextRequest) Reset() {
+	*x = TypeTextRequest{}
+	mi := &file_mobilecli_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TypeTextRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TypeTextRequest) ProtoMessage() {}
+
+func (x *TypeTextRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TypeTextRequest.ProtoReflect.Descriptor instead.
+func (*TypeTextRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TypeTextRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *TypeTextRequest) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *TypeTextRequest) GetClear() bool {
+	if x != nil {
+		return x.Clear
+	}
+	return false
+}
+
+type PressButtonRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Button        string                 `protobuf:"bytes,2,opt,name=button,proto3" json:"button,omitempty"` // e.g. "HOME", see 'mobilecli io button'
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PressButtonRequest) Reset() {
+	*x = PressButtonRequest{}
+	mi := &file_mobilecli_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PressButtonRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PressButtonRequest) ProtoMessage() {}
+
+func (x *PressButtonRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PressButtonRequest.ProtoReflect.Descriptor instead.
+func (*PressButtonRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PressButtonRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *PressButtonRequest) GetButton() string {
+	if x != nil {
+		return x.Button
+	}
+	return ""
+}
+
+type InputResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InputResponse) Reset() {
+	*x = InputResponse{}
+	mi := &file_mobilecli_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InputResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputResponse) ProtoMessage() {}
+
+func (x *InputResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputResponse.ProtoReflect.Descriptor instead.
+func (*InputResponse) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{16}
+}
+
+type ScreenFramesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	Quality       int32                  `protobuf:"varint,2,opt,name=quality,proto3" json:"quality,omitempty"`
+	Scale         float64                `protobuf:"fixed64,3,opt,name=scale,proto3" json:"scale,omitempty"`
+	Fps           int32                  `protobuf:"varint,4,opt,name=fps,proto3" json:"fps,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenFramesRequest) Reset() {
+	*x = ScreenFramesRequest{}
+	mi := &file_mobilecli_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenFramesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenFramesRequest) ProtoMessage() {}
+
+func (x *ScreenFramesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenFramesRequest.ProtoReflect.Descriptor instead.
+func (*ScreenFramesRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ScreenFramesRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *ScreenFramesRequest) GetQuality() int32 {
+	if x != nil {
+		return x.Quality
+	}
+	return 0
+}
+
+func (x *ScreenFramesRequest) GetScale() float64 {
+	if x != nil {
+		return x.Scale
+	}
+	return 0
+}
+
+func (x *ScreenFramesRequest) GetFps() int32 {
+	if x != nil {
+		return x.Fps
+	}
+	return 0
+}
+
+type ScreenFrame struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jpeg          []byte                 `protobuf:"bytes,1,opt,name=jpeg,proto3" json:"jpeg,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScreenFrame) Reset() {
+	*x = ScreenFrame{}
+	mi := &file_mobilecli_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenFrame) ProtoMessage() {}
+
+func (x *ScreenFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenFrame.ProtoReflect.Descriptor instead.
+func (*ScreenFrame) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ScreenFrame) GetJpeg() []byte {
+	if x != nil {
+		return x.Jpeg
+	}
+	return nil
+}
+
+type LogsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogsRequest) Reset() {
+	*x = LogsRequest{}
+	mi := &file_mobilecli_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogsRequest) ProtoMessage() {}
+
+func (x *LogsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogsRequest.ProtoReflect.Descriptor instead.
+func (*LogsRequest) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *LogsRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type LogLine struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Line          string                 `protobuf:"bytes,1,opt,name=line,proto3" json:"line,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogLine) Reset() {
+	*x = LogLine{}
+	mi := &file_mobilecli_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogLine) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogLine) ProtoMessage() {}
+
+func (x *LogLine) ProtoReflect() protoreflect.Message {
+	mi := &file_mobilecli_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogLine.ProtoReflect.Descriptor instead.
+func (*LogLine) Descriptor() ([]byte, []int) {
+	return file_mobilecli_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LogLine) GetLine() string {
+	if x != nil {
+		return x.Line
+	}
+	return ""
+}
+
+var File_mobilecli_proto protoreflect.FileDescriptor
+
+const file_mobilecli_proto_rawDesc = "" +
+	"\n" +
+	"\x0fmobilecli.proto\x12\fmobilecli.v1\"F\n" +
+	"\vCallRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1f\n" +
+	"\vparams_json\x18\x02 \x01(\tR\n" +
+	"paramsJson\"/\n" +
+	"\fCallResponse\x12\x1f\n" +
+	"\vresult_json\x18\x01 \x01(\tR\n" +
+	"resultJson\"m\n" +
+	"\x12ListDevicesRequest\x12'\n" +
+	"\x0finclude_offline\x18\x01 \x01(\bR\x0eincludeOffline\x12\x1a\n" +
+	"\bplatform\x18\x02 \x01(\tR\bplatform\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\"\xc0\x01\n" +
+	"\x06Device\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
+	"\bplatform\x18\x03 \x01(\tR\bplatform\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x18\n" +
+	"\aversion\x18\x05 \x01(\tR\aversion\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x14\n" +
+	"\x05model\x18\a \x01(\tR\x05model\x12\x1c\n" +
+	"\ttransport\x18\b \x01(\tR\ttransport\"E\n" +
+	"\x13ListDevicesResponse\x12.\n" +
+	"\adevices\x18\x01 \x03(\v2\x14.mobilecli.v1.DeviceR\adevices\"b\n" +
+	"\x11ScreenshotRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x18\n" +
+	"\aquality\x18\x03 \x01(\x05R\aquality\"@\n" +
+	"\x12ScreenshotResponse\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"P\n" +
+	"\x04Rect\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\"\xa8\x03\n" +
+	"\rScreenElement\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x19\n" +
+	"\x05label\x18\x02 \x01(\tH\x00R\x05label\x88\x01\x01\x12\x17\n" +
+	"\x04text\x18\x03 \x01(\tH\x01R\x04text\x88\x01\x01\x12\x17\n" +
+	"\x04name\x18\x04 \x01(\tH\x02R\x04name\x88\x01\x01\x12\x19\n" +
+	"\x05value\x18\x05 \x01(\tH\x03R\x05value\x88\x01\x01\x12%\n" +
+	"\vplaceholder\x18\x06 \x01(\tH\x04R\vplaceholder\x88\x01\x01\x12#\n" +
+	"\n" +
+	"identifier\x18\a \x01(\tH\x05R\n" +
+	"identifier\x88\x01\x01\x12&\n" +
+	"\x04rect\x18\b \x01(\v2\x12.mobilecli.v1.RectR\x04rect\x12\x1d\n" +
+	"\afocused\x18\t \x01(\bH\x06R\afocused\x88\x01\x01\x127\n" +
+	"\bchildren\x18\n" +
+	" \x03(\v2\x1b.mobilecli.v1.ScreenElementR\bchildrenB\b\n" +
+	"\x06_labelB\a\n" +
+	"\x05_textB\a\n" +
+	"\x05_nameB\b\n" +
+	"\x06_valueB\x0e\n" +
+	"\f_placeholderB\r\n" +
+	"\v_identifierB\n" +
+	"\n" +
+	"\b_focused\"\xa7\x01\n" +
+	"\rDumpUIRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1b\n" +
+	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\x12!\n" +
+	"\fmax_elements\x18\x04 \x01(\x05R\vmaxElements\x12#\n" +
+	"\rviewport_only\x18\x05 \x01(\bR\fviewportOnly\"a\n" +
+	"\x0eDumpUIResponse\x127\n" +
+	"\belements\x18\x01 \x03(\v2\x1b.mobilecli.v1.ScreenElementR\belements\x12\x16\n" +
+	"\x06cached\x18\x02 \x01(\bR\x06cached\"b\n" +
+	"\x0eWatchUIRequest\x12/\n" +
+	"\x04dump\x18\x01 \x01(\v2\x1b.mobilecli.v1.DumpUIRequestR\x04dump\x12\x1f\n" +
+	"\vinterval_ms\x18\x02 \x01(\x05R\n" +
+	"intervalMs\"E\n" +
+	"\n" +
+	"TapRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x05R\x01y\"\x8c\x01\n" +
+	"\fSwipeRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x0e\n" +
+	"\x02x1\x18\x02 \x01(\x05R\x02x1\x12\x0e\n" +
+	"\x02y1\x18\x03 \x01(\x05R\x02y1\x12\x0e\n" +
+	"\x02x2\x18\x04 \x01(\x05R\x02x2\x12\x0e\n" +
+	"\x02y2\x18\x05 \x01(\x05R\x02y2\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x05R\n" +
+	"durationMs\"X\n" +
+	"\x0fTypeTextRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x14\n" +
+	"\x05clear\x18\x03 \x01(\bR\x05clear\"I\n" +
+	"\x12PressButtonRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x16\n" +
+	"\x06button\x18\x02 \x01(\tR\x06button\"\x0f\n" +
+	"\rInputResponse\"t\n" +
+	"\x13ScreenFramesRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x18\n" +
+	"\aquality\x18\x02 \x01(\x05R\aquality\x12\x14\n" +
+	"\x05scale\x18\x03 \x01(\x01R\x05scale\x12\x10\n" +
+	"\x03fps\x18\x04 \x01(\x05R\x03fps\"!\n" +
+	"\vScreenFrame\x12\x12\n" +
+	"\x04jpeg\x18\x01 \x01(\fR\x04jpeg\"*\n" +
+	"\vLogsRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\"\x1d\n" +
+	"\aLogLine\x12\x12\n" +
+	"\x04line\x18\x01 \x01(\tR\x04line2\x9f\x06\n" +
+	"\tMobileCLI\x12=\n" +
+	"\x04Call\x12\x19.mobilecli.v1.CallRequest\x1a\x1a.mobilecli.v1.CallResponse\x12R\n" +
+	"\vListDevices\x12 .mobilecli.v1.ListDevicesRequest\x1a!.mobilecli.v1.ListDevicesResponse\x12O\n" +
+	"\n" +
+	"Screenshot\x12\x1f.mobilecli.v1.ScreenshotRequest\x1a .mobilecli.v1.ScreenshotResponse\x12C\n" +
+	"\x06DumpUI\x12\x1b.mobilecli.v1.DumpUIRequest\x1a\x1c.mobilecli.v1.DumpUIResponse\x12G\n" +
+	"\aWatchUI\x12\x1c.mobilecli.v1.WatchUIRequest\x1a\x1c.mobilecli.v1.DumpUIResponse0\x01\x12<\n" +
+	"\x03Tap\x12\x18.mobilecli.v1.TapRequest\x1a\x1b.mobilecli.v1.InputResponse\x12@\n" +
+	"\x05Swipe\x12\x1a.mobilecli.v1.SwipeRequest\x1a\x1b.mobilecli.v1.InputResponse\x12F\n" +
+	"\bTypeText\x12\x1d.mobilecli.v1.TypeTextRequest\x1a\x1b.mobilecli.v1.InputResponse\x12L\n" +
+	"\vPressButton\x12 .mobilecli.v1.PressButtonRequest\x1a\x1b.mobilecli.v1.InputResponse\x12N\n" +
+	"\fScreenFrames\x12!.mobilecli.v1.ScreenFramesRequest\x1a\x19.mobilecli.v1.ScreenFrame0\x01\x12:\n" +
+	"\x04Logs\x12\x19.mobilecli.v1.LogsRequest\x1a\x15.mobilecli.v1.LogLine0\x01B1Z/github.com/mobile-next/mobilecli/server/grpcapib\x06proto3"
+
+var (
+	file_mobilecli_proto_rawDescOnce sync.Once
+	file_mobilecli_proto_rawDescData []byte
+)
+
+func file_mobilecli_proto_rawDescGZIP() []byte {
+	file_mobilecli_proto_rawDescOnce.Do(func() {
+		file_mobilecli_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mobilecli_proto_rawDesc), len(file_mobilecli_proto_rawDesc)))
+	})
+	return file_mobilecli_proto_rawDescData
+}
+
+var file_mobilecli_proto_msgTypes = make([]protoimpl.MessageInfo, 21)
+var file_mobilecli_proto_goTypes = []any{
+	(*CallRequest)(nil),         // 0: mobilecli.v1.CallRequest
+	(*CallResponse)(nil),        // 1: mobilecli.v1.CallResponse
+	(*ListDevicesRequest)(nil),  // 2: mobilecli.v1.ListDevicesRequest
+	(*Device)(nil),              // 3: mobilecli.v1.Device
+	(*ListDevicesResponse)(nil), // 4: mobilecli.v1.ListDevicesResponse
+	(*ScreenshotRequest)(nil),   // 5: mobilecli.v1.ScreenshotRequest
+	(*ScreenshotResponse)(nil),  // 6: mobilecli.v1.ScreenshotResponse
+	(*Rect)(nil),                // 7: mobilecli.v1.Rect
+	(*ScreenElement)(nil),       // 8: mobilecli.v1.ScreenElement
+	(*DumpUIRequest)(nil),       // 9: mobilecli.v1.DumpUIRequest
+	(*DumpUIResponse)(nil),      // 10: mobilecli.v1.DumpUIResponse
+	(*WatchUIRequest)(nil),      // 11: mobilecli.v1.WatchUIRequest
+	(*TapRequest)(nil),          // 12: mobilecli.v1.TapRequest
+	(*SwipeRequest)(nil),        // 13: mobilecli.v1.SwipeRequest
+	(*TypeTextRequest)(nil),     // 14: mobilecli.v1.TypeTextRequest
+	(*PressButtonRequest)(nil),  // 15: mobilecli.v1.PressButtonRequest
+	(*InputResponse)(nil),       // 16: mobilecli.v1.InputResponse
+	(*ScreenFramesRequest)(nil), // 17: mobilecli.v1.ScreenFramesRequest
+	(*ScreenFrame)(nil),         // 18: mobilecli.v1.ScreenFrame
+	(*LogsRequest)(nil),         // 19: mobilecli.v1.LogsRequest
+	(*LogLine)(nil),             // 20: mobilecli.v1.LogLine
+}
+var file_mobilecli_proto_depIdxs = []int32{
+	3,  // 0: mobilecli.v1.ListDevicesResponse.devices:type_name -> mobilecli.v1.Device
+	7,  // 1: mobilecli.v1.ScreenElement.rect:type_name -> mobilecli.v1.Rect
+	8,  // 2: mobilecli.v1.ScreenElement.children:type_name -> mobilecli.v1.ScreenElement
+	8,  // 3: mobilecli.v1.DumpUIResponse.elements:type_name -> mobilecli.v1.ScreenElement
+	9,  // 4: mobilecli.v1.WatchUIRequest.dump:type_name -> mobilecli.v1.DumpUIRequest
+	0,  // 5: mobilecli.v1.MobileCLI.Call:input_type -> mobilecli.v1.CallRequest
+	2,  // 6: mobilecli.v1.MobileCLI.ListDevices:input_type -> mobilecli.v1.ListDevicesRequest
+	5,  // 7: mobilecli.v1.MobileCLI.Screenshot:input_type -> mobilecli.v1.ScreenshotRequest
+	9,  // 8: mobilecli.v1.MobileCLI.DumpUI:input_type -> mobilecli.v1.DumpUIRequest
+	11, // 9: mobilecli.v1.MobileCLI.WatchUI:input_type -> mobilecli.v1.WatchUIRequest
+	12, // 10: mobilecli.v1.MobileCLI.Tap:input_type -> mobilecli.v1.TapRequest
+	13, // 11: mobilecli.v1.MobileCLI.Swipe:input_type -> mobilecli.v1.SwipeRequest
+	14, // 12: mobilecli.v1.MobileCLI.TypeText:input_type -> mobilecli.v1.TypeTextRequest
+	15, // 13: mobilecli.v1.MobileCLI.PressButton:input_type -> mobilecli.v1.PressButtonRequest
+	17, // 14: mobilecli.v1.MobileCLI.ScreenFrames:input_type -> mobilecli.v1.ScreenFramesRequest
+	19, // 15: mobilecli.v1.MobileCLI.Logs:input_type -> mobilecli.v1.LogsRequest
+	1,  // 16: mobilecli.v1.MobileCLI.Call:output_type -> mobilecli.v1.CallResponse
+	4,  // 17: mobilecli.v1.MobileCLI.ListDevices:output_type -> mobilecli.v1.ListDevicesResponse
+	6,  // 18: mobilecli.v1.MobileCLI.Screenshot:output_type -> mobilecli.v1.ScreenshotResponse
+	10, // 19: mobilecli.v1.MobileCLI.DumpUI:output_type -> mobilecli.v1.DumpUIResponse
+	10, // 20: mobilecli.v1.MobileCLI.WatchUI:output_type -> mobilecli.v1.DumpUIResponse
+	16, // 21: mobilecli.v1.MobileCLI.Tap:output_type -> mobilecli.v1.InputResponse
+	16, // 22: mobilecli.v1.MobileCLI.Swipe:output_type -> mobilecli.v1.InputResponse
+	16, // 23: mobilecli.v1.MobileCLI.TypeText:output_type -> mobilecli.v1.InputResponse
+	16, // 24: mobilecli.v1.MobileCLI.PressButton:output_type -> mobilecli.v1.InputResponse
+	18, // 25: mobilecli.v1.MobileCLI.ScreenFrames:output_type -> mobilecli.v1.ScreenFrame
+	20, // 26: mobilecli.v1.MobileCLI.Logs:output_type -> mobilecli.v1.LogLine
+	16, // [16:27] is the sub-list for method output_type
+	5,  // [5:16] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_mobilecli_proto_init() }
+func file_mobilecli_proto_init() {
+	if File_mobilecli_proto != nil {
+		return
+	}
+	file_mobilecli_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mobilecli_proto_rawDesc), len(file_mobilecli_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   21,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mobilecli_proto_goTypes,
+		DependencyIndexes: file_mobilecli_proto_depIdxs,
+		MessageInfos:      file_mobilecli_proto_msgTypes,
+	}.Build()
+	File_mobilecli_proto = out.File
+	file_mobilecli_proto_goTypes = nil
+	file_mobilecli_proto_depIdxs = nil
+}
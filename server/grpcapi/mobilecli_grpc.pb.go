@@ -0,0 +1,537 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: mobilecli.proto
+
+package grpcapi
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MobileCLI_Call_FullMethodName         = "/mobilecli.v1.MobileCLI/Call"
+	MobileCLI_ListDevices_FullMethodName  = "/mobilecli.v1.MobileCLI/ListDevices"
+	MobileCLI_Screenshot_FullMethodName   = "/mobilecli.v1.MobileCLI/Screenshot"
+	MobileCLI_DumpUI_FullMethodName       = "/mobilecli.v1.MobileCLI/DumpUI"
+	MobileCLI_WatchUI_FullMethodName      = "/mobilecli.v1.MobileCLI/WatchUI"
+	MobileCLI_Tap_FullMethodName          = "/mobilecli.v1.MobileCLI/Tap"
+	MobileCLI_Swipe_FullMethodName        = "/mobilecli.v1.MobileCLI/Swipe"
+	MobileCLI_TypeText_FullMethodName     = "/mobilecli.v1.MobileCLI/TypeText"
+	MobileCLI_PressButton_FullMethodName  = "/mobilecli.v1.MobileCLI/PressButton"
+	MobileCLI_ScreenFrames_FullMethodName = "/mobilecli.v1.MobileCLI/ScreenFrames"
+	MobileCLI_Logs_FullMethodName         = "/mobilecli.v1.MobileCLI/Logs"
+)
+
+// MobileCLIClient is the client API for MobileCLI service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MobileCLI is the gRPC surface of the mobilecli server, served with
+// 'mobilecli server start --grpc :12002'. The calls that clients poll or
+// stream at high rates have typed methods; Call reaches every other JSON-RPC
+// method with the same params and result.
+type MobileCLIClient interface {
+	// Call invokes a JSON-RPC method, e.g. "device.apps.launch"
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error)
+	// Screenshot returns the raw image, served from the screenshot cache when
+	// the server runs with --screenshot-cache-ms
+	Screenshot(ctx context.Context, in *ScreenshotRequest, opts ...grpc.CallOption) (*ScreenshotResponse, error)
+	DumpUI(ctx context.Context, in *DumpUIRequest, opts ...grpc.CallOption) (*DumpUIResponse, error)
+	// WatchUI dumps the UI every interval and sends the dumps that differ
+	// from the last one sent
+	WatchUI(ctx context.Context, in *WatchUIRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DumpUIResponse], error)
+	Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (*InputResponse, error)
+	Swipe(ctx context.Context, in *SwipeRequest, opts ...grpc.CallOption) (*InputResponse, error)
+	TypeText(ctx context.Context, in *TypeTextRequest, opts ...grpc.CallOption) (*InputResponse, error)
+	PressButton(ctx context.Context, in *PressButtonRequest, opts ...grpc.CallOption) (*InputResponse, error)
+	// ScreenFrames streams JPEG frames of the device screen. Viewers of a
+	// device share one capture, and a slow client skips whole frames.
+	ScreenFrames(ctx context.Context, in *ScreenFramesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScreenFrame], error)
+	// Logs streams the device's system log, from now on
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error)
+}
+
+type mobileCLIClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMobileCLIClient(cc grpc.ClientConnInterface) MobileCLIClient {
+	return &mobileCLIClient{cc}
+}
+
+func (c *mobileCLIClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_Call_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) ListDevices(ctx context.Context, in *ListDevicesRequest, opts ...grpc.CallOption) (*ListDevicesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDevicesResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_ListDevices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) Screenshot(ctx context.Context, in *ScreenshotRequest, opts ...grpc.CallOption) (*ScreenshotResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScreenshotResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_Screenshot_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) DumpUI(ctx context.Context, in *DumpUIRequest, opts ...grpc.CallOption) (*DumpUIResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DumpUIResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_DumpUI_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) WatchUI(ctx context.Context, in *WatchUIRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DumpUIResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MobileCLI_ServiceDesc.Streams[0], MobileCLI_WatchUI_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchUIRequest, DumpUIResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_WatchUIClient = grpc.ServerStreamingClient[DumpUIResponse]
+
+func (c *mobileCLIClient) Tap(ctx context.Context, in *TapRequest, opts ...grpc.CallOption) (*InputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InputResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_Tap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) Swipe(ctx context.Context, in *SwipeRequest, opts ...grpc.CallOption) (*InputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InputResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_Swipe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) TypeText(ctx context.Context, in *TypeTextRequest, opts ...grpc.CallOption) (*InputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InputResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_TypeText_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) PressButton(ctx context.Context, in *PressButtonRequest, opts ...grpc.CallOption) (*InputResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InputResponse)
+	err := c.cc.Invoke(ctx, MobileCLI_PressButton_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mobileCLIClient) ScreenFrames(ctx context.Context, in *ScreenFramesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ScreenFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MobileCLI_ServiceDesc.Streams[1], MobileCLI_ScreenFrames_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ScreenFramesRequest, ScreenFrame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_ScreenFramesClient = grpc.ServerStreamingClient[ScreenFrame]
+
+func (c *mobileCLIClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[LogLine], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MobileCLI_ServiceDesc.Streams[2], MobileCLI_Logs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LogsRequest, LogLine]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_LogsClient = grpc.ServerStreamingClient[LogLine]
+
+// MobileCLIServer is the server API for MobileCLI service.
+// All implementations must embed UnimplementedMobileCLIServer
+// for forward compatibility.
+//
+// MobileCLI is the gRPC surface of the mobilecli server, served with
+// 'mobilecli server start --grpc :12002'. The calls that clients poll or
+// stream at high rates have typed methods; Call reaches every other JSON-RPC
+// method with the same params and result.
+type MobileCLIServer interface {
+	// Call invokes a JSON-RPC method, e.g. "device.apps.launch"
+	Call(context.Context, *CallRequest) (*CallResponse, error)
+	ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error)
+	// Screenshot returns the raw image, served from the screenshot cache when
+	// the server runs with --screenshot-cache-ms
+	Screenshot(context.Context, *ScreenshotRequest) (*ScreenshotResponse, error)
+	DumpUI(context.Context, *DumpUIRequest) (*DumpUIResponse, error)
+	// WatchUI dumps the UI every interval and sends the dumps that differ
+	// from the last one sent
+	WatchUI(*WatchUIRequest, grpc.ServerStreamingServer[DumpUIResponse]) error
+	Tap(context.Context, *TapRequest) (*InputResponse, error)
+	Swipe(context.Context, *SwipeRequest) (*InputResponse, error)
+	TypeText(context.Context, *TypeTextRequest) (*InputResponse, error)
+	PressButton(context.Context, *PressButtonRequest) (*InputResponse, error)
+	// ScreenFrames streams JPEG frames of the device screen. Viewers of a
+	// device share one capture, and a slow client skips whole frames.
+	ScreenFrames(*ScreenFramesRequest, grpc.ServerStreamingServer[ScreenFrame]) error
+	// Logs streams the device's system log, from now on
+	Logs(*LogsRequest, grpc.ServerStreamingServer[LogLine]) error
+	mustEmbedUnimplementedMobileCLIServer()
+}
+
+// UnimplementedMobileCLIServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMobileCLIServer struct{}
+
+func (UnimplementedMobileCLIServer) Call(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Call not implemented")
+}
+func (UnimplementedMobileCLIServer) ListDevices(context.Context, *ListDevicesRequest) (*ListDevicesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListDevices not implemented")
+}
+func (UnimplementedMobileCLIServer) Screenshot(context.Context, *ScreenshotRequest) (*ScreenshotResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Screenshot not implemented")
+}
+func (UnimplementedMobileCLIServer) DumpUI(context.Context, *DumpUIRequest) (*DumpUIResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DumpUI not implemented")
+}
+func (UnimplementedMobileCLIServer) WatchUI(*WatchUIRequest, grpc.ServerStreamingServer[DumpUIResponse]) error {
+	return status.Error(codes.Unimplemented, "method WatchUI not implemented")
+}
+func (UnimplementedMobileCLIServer) Tap(context.Context, *TapRequest) (*InputResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Tap not implemented")
+}
+func (UnimplementedMobileCLIServer) Swipe(context.Context, *SwipeRequest) (*InputResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Swipe not implemented")
+}
+func (UnimplementedMobileCLIServer) TypeText(context.Context, *TypeTextRequest) (*InputResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TypeText not implemented")
+}
+func (UnimplementedMobileCLIServer) PressButton(context.Context, *PressButtonRequest) (*InputResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PressButton not implemented")
+}
+func (UnimplementedMobileCLIServer) ScreenFrames(*ScreenFramesRequest, grpc.ServerStreamingServer[ScreenFrame]) error {
+	return status.Error(codes.Unimplemented, "method ScreenFrames not implemented")
+}
+func (UnimplementedMobileCLIServer) Logs(*LogsRequest, grpc.ServerStreamingServer[LogLine]) error {
+	return status.Error(codes.Unimplemented, "method Logs not implemented")
+}
+func (UnimplementedMobileCLIServer) mustEmbedUnimplementedMobileCLIServer() {}
+func (UnimplementedMobileCLIServer) testEmbeddedByValue()                   {}
+
+// UnsafeMobileCLIServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MobileCLIServer will
+// result in compilation errors.
+type UnsafeMobileCLIServer interface {
+	mustEmbedUnimplementedMobileCLIServer()
+}
+
+func RegisterMobileCLIServer(s grpc.ServiceRegistrar, srv MobileCLIServer) {
+	// If the following call panics, it indicates UnimplementedMobileCLIServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MobileCLI_ServiceDesc, srv)
+}
+
+func _MobileCLI_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_Call_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_ListDevices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDevicesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).ListDevices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_ListDevices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).ListDevices(ctx, req.(*ListDevicesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_Screenshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScreenshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).Screenshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_Screenshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).Screenshot(ctx, req.(*ScreenshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_DumpUI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DumpUIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).DumpUI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_DumpUI_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).DumpUI(ctx, req.(*DumpUIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_WatchUI_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchUIRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MobileCLIServer).WatchUI(m, &grpc.GenericServerStream[WatchUIRequest, DumpUIResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_WatchUIServer = grpc.ServerStreamingServer[DumpUIResponse]
+
+func _MobileCLI_Tap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).Tap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_Tap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).Tap(ctx, req.(*TapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_Swipe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SwipeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).Swipe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_Swipe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).Swipe(ctx, req.(*SwipeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_TypeText_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TypeTextRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).TypeText(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_TypeText_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).TypeText(ctx, req.(*TypeTextRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_PressButton_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PressButtonRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MobileCLIServer).PressButton(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MobileCLI_PressButton_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MobileCLIServer).PressButton(ctx, req.(*PressButtonRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MobileCLI_ScreenFrames_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ScreenFramesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MobileCLIServer).ScreenFrames(m, &grpc.GenericServerStream[ScreenFramesRequest, ScreenFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_ScreenFramesServer = grpc.ServerStreamingServer[ScreenFrame]
+
+func _MobileCLI_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MobileCLIServer).Logs(m, &grpc.GenericServerStream[LogsRequest, LogLine]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MobileCLI_LogsServer = grpc.ServerStreamingServer[LogLine]
+
+// MobileCLI_ServiceDesc is the grpc.ServiceDesc for MobileCLI service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MobileCLI_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mobilecli.v1.MobileCLI",
+	HandlerType: (*MobileCLIServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    _MobileCLI_Call_Handler,
+		},
+		{
+			MethodName: "ListDevices",
+			Handler:    _MobileCLI_ListDevices_Handler,
+		},
+		{
+			MethodName: "Screenshot",
+			Handler:    _MobileCLI_Screenshot_Handler,
+		},
+		{
+			MethodName: "DumpUI",
+			Handler:    _MobileCLI_DumpUI_Handler,
+		},
+		{
+			MethodName: "Tap",
+			Handler:    _MobileCLI_Tap_Handler,
+		},
+		{
+			MethodName: "Swipe",
+			Handler:    _MobileCLI_Swipe_Handler,
+		},
+		{
+			MethodName: "TypeText",
+			Handler:    _MobileCLI_TypeText_Handler,
+		},
+		{
+			MethodName: "PressButton",
+			Handler:    _MobileCLI_PressButton_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchUI",
+			Handler:       _MobileCLI_WatchUI_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ScreenFrames",
+			Handler:       _MobileCLI_ScreenFrames_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Logs",
+			Handler:       _MobileCLI_Logs_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mobilecli.proto",
+}
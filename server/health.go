@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// readinessCheckTimeout bounds each /readyz check, so a wedged adb server
+// fails the probe instead of hanging it
+const readinessCheckTimeout = 5 * time.Second
+
+var (
+	readinessMu         sync.Mutex
+	readinessMinDevices int
+)
+
+// SetReadinessMinDevices makes /readyz report not ready until at least n
+// devices are online. 0 doesn't check devices.
+func SetReadinessMinDevices(n int) {
+	readinessMu.Lock()
+	readinessMinDevices = n
+	readinessMu.Unlock()
+}
+
+// ReadinessCheck is the result of one /readyz check
+type ReadinessCheck struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message,omitempty"`
+}
+
+// ReadinessStatus is the body of /readyz
+type ReadinessStatus struct {
+	Ready  bool                      `json:"ready"`
+	Checks map[string]ReadinessCheck `json:"checks"`
+}
+
+// handleHealthz is the liveness probe: the server answers, so it is alive
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(okResponse)
+}
+
+// handleReadyz is the readiness probe. It answers 503 until adb is reachable
+// and, when configured, enough devices are online.
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessCheckTimeout)
+	defer cancel()
+
+	status := checkReadiness(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(status)
+}
+
+// checkReadiness runs the readiness checks
+func checkReadiness(ctx context.Context) *ReadinessStatus {
+	status := &ReadinessStatus{
+		Ready:  true,
+		Checks: make(map[string]ReadinessCheck),
+	}
+
+	record := func(name string, check ReadinessCheck) {
+		status.Checks[name] = check
+		if !check.OK {
+			status.Ready = false
+		}
+	}
+
+	// hosts without the Android SDK only serve iOS devices, adb isn't needed
+	if devices.AdbInstalled() {
+		if err := devices.PingAdbServer(ctx); err != nil {
+			record("adb", ReadinessCheck{OK: false, Message: err.Error()})
+		} else {
+			record("adb", ReadinessCheck{OK: true})
+		}
+	} else {
+		record("adb", ReadinessCheck{OK: true, Message: "adb is not installed, skipped"})
+	}
+
+	readinessMu.Lock()
+	minDevices := readinessMinDevices
+	readinessMu.Unlock()
+
+	if minDevices > 0 {
+		record("devices", checkOnlineDevices(minDevices))
+	}
+
+	return status
+}
+
+// checkOnlineDevices checks that at least minDevices devices are online
+func checkOnlineDevices(minDevices int) ReadinessCheck {
+	all, err := devices.GetAllControllableDevices(false)
+	if err != nil {
+		return ReadinessCheck{OK: false, Message: fmt.Sprintf("failed to list devices: %v", err)}
+	}
+
+	online := 0
+	for _, device := range all {
+		if device.State() == "online" {
+			online++
+		}
+	}
+
+	return ReadinessCheck{
+		OK:      online >= minDevices,
+		Message: fmt.Sprintf("%d of %d required devices online", online, minDevices),
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func TestHealthz(t *testing.T) {
+	rec := httptest.NewRecorder()
+	handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handleHealthz(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+func TestReadyzMinDevices(t *testing.T) {
+	// an SDK without adb, so the adb check is skipped
+	t.Setenv("ANDROID_HOME", t.TempDir())
+	t.Setenv(devices.FakeDevicesEnvVar, "2")
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Cleanup(func() { SetReadinessMinDevices(0) })
+
+	tests := []struct {
+		minDevices int
+		code       int
+		ready      bool
+	}{
+		{0, http.StatusOK, true},
+		{2, http.StatusOK, true},
+		{3, http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		SetReadinessMinDevices(tt.minDevices)
+
+		rec := httptest.NewRecorder()
+		handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != tt.code {
+			t.Errorf("min %d: expected %d, got %d", tt.minDevices, tt.code, rec.Code)
+		}
+
+		var status ReadinessStatus
+		if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+			t.Fatalf("min %d: invalid body: %v", tt.minDevices, err)
+		}
+		if status.Ready != tt.ready {
+			t.Errorf("min %d: expected ready=%v, got %+v", tt.minDevices, tt.ready, status)
+		}
+		if _, checked := status.Checks["devices"]; checked != (tt.minDevices > 0) {
+			t.Errorf("min %d: unexpected devices check in %+v", tt.minDevices, status.Checks)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// Request limits of the HTTP server. A request body holds at most one
+// JSON-RPC message, so it is bounded like a WebSocket message.
+const (
+	ReadHeaderTimeout     = 5 * time.Second
+	MaxRequestBodySize    = wsMaxMessageSize
+	MaxConcurrentRequests = 64
+)
+
+// streamWriteTimeout bounds each write of a screen capture stream, so a
+// client that stops reading is dropped instead of holding the stream open
+const streamWriteTimeout = 30 * time.Second
+
+// extendWriteDeadline gives the next write to w streamWriteTimeout to finish
+func extendWriteDeadline(w http.ResponseWriter) {
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+}
+
+// requestSlots bounds the request/response calls handled at once, like the
+// WebSocket server does per connection. WebSockets, screen streams, viewers
+// and progress or subscription streams hold their connection for as long as
+// they run, so they don't take a slot.
+var requestSlots = make(chan struct{}, MaxConcurrentRequests)
+
+// acquireRequestSlot takes one of slots, or rejects the request with 503
+// and returns false when all are taken
+func acquireRequestSlot(w http.ResponseWriter, slots chan struct{}) bool {
+	// non-blocking acquire; reject immediately when all slots are taken
+	select {
+	case slots <- struct{}{}:
+		return true
+	default:
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "too many concurrent requests", http.StatusServiceUnavailable)
+		return false
+	}
+}
+
+// limitConcurrentRequests makes a request/response handler take one of
+// slots while it runs
+func limitConcurrentRequests(next http.HandlerFunc, slots chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !acquireRequestSlot(w, slots) {
+			return
+		}
+		defer func() { <-slots }()
+
+		next(w, r)
+	}
+}
+
+// runHandler runs a method's handler until it returns or ctx is done because
+// the client went away. Handlers can't be interrupted, so an abandoned one
+// finishes in the background and its result is dropped.
+func runHandler(ctx context.Context, method string, handler HandlerFunc, params json.RawMessage) (any, error) {
+	type outcome struct {
+		result any
+		err    error
+	}
+
+	done := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in handler %s: %v\n%s", method, r, debug.Stack())
+				done <- outcome{err: fmt.Errorf("panic: %v", r)}
+			}
+		}()
+
+		result, err := handler(params)
+		done <- outcome{result: result, err: err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
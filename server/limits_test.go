@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimitConcurrentRequests(t *testing.T) {
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := limitConcurrentRequests(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+	}, make(chan struct{}, 1))
+
+	done := make(chan struct{})
+	go func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+		close(done)
+	}()
+	<-started
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while the slot is taken, got %d", rec.Code)
+	}
+
+	close(release)
+	<-done
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/rpc", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the slot to be released, got %d", rec.Code)
+	}
+}
+
+func TestJSONRPCRequestSlots(t *testing.T) {
+	for range cap(requestSlots) {
+		requestSlots <- struct{}{}
+	}
+	defer func() {
+		for range cap(requestSlots) {
+			<-requestSlots
+		}
+	}()
+
+	rec := httptest.NewRecorder()
+	handleJSONRPC(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"server.info"}`)))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while all slots are taken, got %d", rec.Code)
+	}
+
+	// health probes never take a slot
+	rec = httptest.NewRecorder()
+	handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected health probes to be served, got %d", rec.Code)
+	}
+}
+
+func TestJSONRPCBodyLimit(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"device.fs.push","params":{"content":"` + strings.Repeat("A", MaxRequestBodySize) + `"}}`
+
+	rec := httptest.NewRecorder()
+	handleJSONRPC(rec, httptest.NewRequest(http.MethodPost, "/rpc", strings.NewReader(body)))
+
+	var response struct {
+		Error map[string]any `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if response.Error["code"] != float64(ErrCodeInvalidRequest) {
+		t.Errorf("expected an invalid request error, got %v", response.Error)
+	}
+}
+
+func TestRunHandlerClientGone(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := runHandler(ctx, "test.slow", func(params json.RawMessage) (any, error) {
+		<-release
+		return nil, nil
+	}, nil)
+	if err != context.Canceled {
+		t.Errorf("expected the abandoned handler to return context.Canceled, got %v", err)
+	}
+
+	result, err := runHandler(context.Background(), "test.fast", func(params json.RawMessage) (any, error) {
+		return "done", nil
+	}, nil)
+	if err != nil || result != "done" {
+		t.Errorf("unexpected result %v, %v", result, err)
+	}
+}
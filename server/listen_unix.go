@@ -0,0 +1,19 @@
+//go:build unix
+
+package server
+
+import (
+	"net"
+	"syscall"
+)
+
+// listenUnixSocket listens on a Unix socket that only the current user can
+// connect to. The umask is set around Listen, so the socket never exists with
+// looser permissions. It's process wide, so this runs before the daemon starts
+// anything that creates files.
+func listenUnixSocket(socketPath string) (net.Listener, error) {
+	oldMask := syscall.Umask(0o177)
+	defer syscall.Umask(oldMask)
+
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListenUnixSocketIsPrivate(t *testing.T) {
+	// a permissive umask would otherwise leave the socket open to everyone
+	oldMask := syscall.Umask(0)
+	defer syscall.Umask(oldMask)
+
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	listener, err := listenUnixSocket(socketPath)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	info, err := os.Stat(socketPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// the umask is restored once the socket exists
+	assert.Equal(t, 0, syscall.Umask(0))
+}
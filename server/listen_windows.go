@@ -0,0 +1,11 @@
+//go:build windows
+
+package server
+
+import "net"
+
+// listenUnixSocket listens on a Unix socket. Windows has no umask, the
+// socket's access follows the ACL of the directory it's in.
+func listenUnixSocket(socketPath string) (net.Listener, error) {
+	return net.Listen("unix", socketPath)
+}
@@ -0,0 +1,330 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// Operation states
+const (
+	OperationRunning   = "running"
+	OperationCompleted = "completed"
+	OperationFailed    = "failed"
+	OperationCancelled = "cancelled"
+)
+
+// operationRetention is how long finished operations can still be queried
+const operationRetention = 10 * time.Minute
+
+// operationWriteTimeout bounds each write of an HTTP operations.subscribe
+// stream, which lasts as long as the operation
+const operationWriteTimeout = time.Minute
+
+// Operation is a method call running in the background, started with
+// operations.start so clients can follow its progress and cancel it
+type Operation struct {
+	ID     string `json:"operationId"`
+	Method string `json:"method"`
+	State  string `json:"state"`
+
+	// Progress is the latest progress reported by the method, e.g. a boot
+	// stage or install progress
+	Progress any `json:"progress,omitempty"`
+
+	Result     any        `json:"result,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// operationEntry is an Operation with what's needed to follow and cancel it
+type operationEntry struct {
+	Operation
+	cancel context.CancelFunc
+
+	// changed is closed and replaced whenever the operation changes
+	changed chan struct{}
+}
+
+// progressHandler is a method handler that reports progress and stops when
+// ctx is cancelled. Methods without one run their HandlerFunc and can't be
+// interrupted, cancelling only drops their result.
+type progressHandler func(ctx context.Context, params json.RawMessage, report func(progress any)) (any, error)
+
+var progressHandlers = map[string]progressHandler{
+	"device.boot":         bootOperation,
+	"device.apps.install": installOperation,
+}
+
+var (
+	operationsMu sync.Mutex
+	operations   = map[string]*operationEntry{}
+)
+
+func bootOperation(ctx context.Context, params json.RawMessage, report func(progress any)) (any, error) {
+	var bootParams DeviceBootParams
+	if err := json.Unmarshal(params, &bootParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, timeout (optional)", err)
+	}
+
+	response := commands.BootCommand(commands.BootRequest{
+		DeviceID: bootParams.DeviceID,
+		Timeout:  bootParams.Timeout,
+		Context:  ctx,
+		OnProgress: func(stage string) {
+			report(map[string]string{"stage": stage})
+		},
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
+func installOperation(ctx context.Context, params json.RawMessage, report func(progress any)) (any, error) {
+	req, err := parseAppsInstallParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	req.OnProgress = func(progress devices.InstallProgress) {
+		report(progress)
+	}
+	req.Context = ctx
+
+	response := commands.InstallAppCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// update changes an operation and wakes up its subscribers, unless it was
+// cancelled meanwhile
+func (e *operationEntry) update(change func(op *Operation)) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+
+	if e.State != OperationRunning {
+		return
+	}
+	change(&e.Operation)
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+// startOperation runs a method in the background and returns its operation
+func startOperation(method string, params json.RawMessage) (Operation, error) {
+	handler, ok := progressHandlers[method]
+	if !ok {
+		registryHandler, exists := GetMethodRegistry()[method]
+		if !exists {
+			return Operation{}, fmt.Errorf("method not found: %s", method)
+		}
+		handler = func(ctx context.Context, params json.RawMessage, report func(progress any)) (any, error) {
+			return registryHandler(params)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	entry := &operationEntry{
+		Operation: Operation{
+			ID:        uuid.New().String(),
+			Method:    method,
+			State:     OperationRunning,
+			StartedAt: time.Now(),
+		},
+		cancel:  cancel,
+		changed: make(chan struct{}),
+	}
+
+	operationsMu.Lock()
+	pruneOperationsLocked()
+	operations[entry.ID] = entry
+	snapshot := entry.Operation
+	operationsMu.Unlock()
+
+	go func() {
+		defer cancel()
+		result, err := handler(ctx, params, func(progress any) {
+			entry.update(func(op *Operation) { op.Progress = progress })
+		})
+		entry.update(func(op *Operation) {
+			now := time.Now()
+			op.FinishedAt = &now
+			if err != nil {
+				op.State = OperationFailed
+				op.Error = err.Error()
+				return
+			}
+			op.State = OperationCompleted
+			op.Result = result
+		})
+	}()
+
+	return snapshot, nil
+}
+
+// pruneOperationsLocked forgets operations that finished a while ago
+func pruneOperationsLocked() {
+	for id, entry := range operations {
+		if entry.FinishedAt != nil && time.Since(*entry.FinishedAt) > operationRetention {
+			delete(operations, id)
+		}
+	}
+}
+
+// getOperation returns an operation and a channel closed on its next change
+func getOperation(id string) (Operation, <-chan struct{}, error) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+
+	entry, ok := operations[id]
+	if !ok {
+		return Operation{}, nil, fmt.Errorf("operation %s not found", id)
+	}
+	return entry.Operation, entry.changed, nil
+}
+
+// cancelOperation stops a running operation
+func cancelOperation(id string) (Operation, error) {
+	operationsMu.Lock()
+	defer operationsMu.Unlock()
+
+	entry, ok := operations[id]
+	if !ok {
+		return Operation{}, fmt.Errorf("operation %s not found", id)
+	}
+
+	if entry.State == OperationRunning {
+		entry.cancel()
+		now := time.Now()
+		entry.State = OperationCancelled
+		entry.FinishedAt = &now
+		close(entry.changed)
+		entry.changed = make(chan struct{})
+	}
+	return entry.Operation, nil
+}
+
+// followOperation calls onChange with every change of an operation until it
+// finishes, and returns the finished operation
+func followOperation(ctx context.Context, id string, onChange func(op Operation)) (Operation, error) {
+	for {
+		op, changed, err := getOperation(id)
+		if err != nil {
+			return Operation{}, err
+		}
+		if op.State != OperationRunning {
+			return op, nil
+		}
+		onChange(op)
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Operation{}, ctx.Err()
+		}
+	}
+}
+
+type OperationsStartParams struct {
+	Method string         `json:"method"`
+	Params map[string]any `json:"params,omitempty"`
+}
+
+func handleOperationsStart(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: method, params (optional)")
+	}
+
+	var startParams OperationsStartParams
+	err := json.Unmarshal(params, &startParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: method, params (optional)", err)
+	}
+
+	if startParams.Method == "" {
+		return nil, fmt.Errorf("'method' is required")
+	}
+	if startParams.Method == "operations.start" || startParams.Method == "operations.subscribe" {
+		return nil, fmt.Errorf("%s can't run as an operation", startParams.Method)
+	}
+
+	var methodParams json.RawMessage
+	if startParams.Params != nil {
+		methodParams, err = json.Marshal(startParams.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+
+	return startOperation(startParams.Method, methodParams)
+}
+
+type OperationParams struct {
+	OperationID string `json:"operationId"`
+}
+
+func parseOperationParams(params json.RawMessage) (OperationParams, error) {
+	var opParams OperationParams
+	if len(params) == 0 {
+		return opParams, fmt.Errorf("'params' is required with fields: operationId")
+	}
+	if err := json.Unmarshal(params, &opParams); err != nil {
+		return opParams, fmt.Errorf("invalid parameters: %w. Expected fields: operationId", err)
+	}
+	if opParams.OperationID == "" {
+		return opParams, fmt.Errorf("'operationId' is required")
+	}
+	return opParams, nil
+}
+
+func handleOperationsStatus(params json.RawMessage) (any, error) {
+	opParams, err := parseOperationParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	op, _, err := getOperation(opParams.OperationID)
+	if err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+func handleOperationsCancel(params json.RawMessage) (any, error) {
+	opParams, err := parseOperationParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return cancelOperation(opParams.OperationID)
+}
+
+// handleOperationsSubscribe waits for an operation to finish. Over HTTP,
+// WebSocket and stdio its changes are streamed as notification/operation
+// notifications meanwhile, until the client goes away, see
+// subscribeOperation; this is only the registry's fallback.
+func handleOperationsSubscribe(params json.RawMessage) (any, error) {
+	opParams, err := parseOperationParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return followOperation(context.Background(), opParams.OperationID, func(Operation) {})
+}
+
+// newOperationNotification wraps an operation change as a JSON-RPC notification
+func newOperationNotification(op Operation) map[string]any {
+	return map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "notification/operation",
+		"params":  op,
+	}
+}
@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useSlowOperation adds a method that reports progress and runs until it is
+// cancelled or released
+func useSlowOperation(t *testing.T) chan struct{} {
+	release := make(chan struct{})
+	progressHandlers["test.slow"] = func(ctx context.Context, params json.RawMessage, report func(progress any)) (any, error) {
+		report(map[string]string{"stage": "started"})
+		select {
+		case <-release:
+			return map[string]string{"message": "done"}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	t.Cleanup(func() { delete(progressHandlers, "test.slow") })
+	return release
+}
+
+func startTestOperation(t *testing.T, method string) Operation {
+	result, err := handleOperationsStart(json.RawMessage(`{"method":"` + method + `"}`))
+	require.NoError(t, err)
+	op := result.(Operation)
+	assert.Equal(t, OperationRunning, op.State)
+	return op
+}
+
+func TestOperationCompletes(t *testing.T) {
+	release := useSlowOperation(t)
+	op := startTestOperation(t, "test.slow")
+
+	var changes []Operation
+	done := make(chan Operation)
+	go func() {
+		finished, err := followOperation(context.Background(), op.ID, func(op Operation) { changes = append(changes, op) })
+		assert.NoError(t, err)
+		done <- finished
+	}()
+
+	require.Eventually(t, func() bool {
+		status, err := handleOperationsStatus(json.RawMessage(`{"operationId":"` + op.ID + `"}`))
+		return err == nil && status.(Operation).Progress != nil
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	finished := <-done
+	assert.Equal(t, OperationCompleted, finished.State)
+	assert.Equal(t, map[string]string{"message": "done"}, finished.Result)
+	assert.NotNil(t, finished.FinishedAt)
+	assert.NotEmpty(t, changes)
+}
+
+func TestOperationCancel(t *testing.T) {
+	useSlowOperation(t)
+	op := startTestOperation(t, "test.slow")
+
+	result, err := handleOperationsCancel(json.RawMessage(`{"operationId":"` + op.ID + `"}`))
+	require.NoError(t, err)
+	assert.Equal(t, OperationCancelled, result.(Operation).State)
+
+	// the handler returning afterwards doesn't undo the cancellation
+	time.Sleep(50 * time.Millisecond)
+	status, err := handleOperationsStatus(json.RawMessage(`{"operationId":"` + op.ID + `"}`))
+	require.NoError(t, err)
+	assert.Equal(t, OperationCancelled, status.(Operation).State)
+	assert.Empty(t, status.(Operation).Error)
+}
+
+func TestOperationErrors(t *testing.T) {
+	_, err := handleOperationsStart(json.RawMessage(`{"method":"no.such.method"}`))
+	assert.ErrorContains(t, err, "method not found")
+
+	_, err = handleOperationsStart(json.RawMessage(`{"method":"operations.subscribe"}`))
+	assert.Error(t, err)
+
+	_, err = handleOperationsStatus(json.RawMessage(`{"operationId":"missing"}`))
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestWebSocket_OperationSubscribe(t *testing.T) {
+	release := useSlowOperation(t)
+	op := startTestOperation(t, "test.slow")
+
+	server, wsURL := setupTestServer(false)
+	defer server.Close()
+	conn := connectWebSocket(t, wsURL)
+	defer conn.Close()
+
+	sendJSONRPCRequest(t, conn, newJSONRPCRequest("operations.subscribe", json.RawMessage(`{"operationId":"`+op.ID+`"}`)))
+
+	var notification struct {
+		Method string    `json:"method"`
+		Params Operation `json:"params"`
+	}
+	require.NoError(t, conn.ReadJSON(&notification))
+	assert.Equal(t, "notification/operation", notification.Method)
+	assert.Equal(t, op.ID, notification.Params.ID)
+	assert.Equal(t, OperationRunning, notification.Params.State)
+
+	close(release)
+	for {
+		resp := readJSONRPCResponse(t, conn)
+		if resp.ID == nil {
+			continue // a later progress notification
+		}
+		require.Nil(t, resp.Error)
+		assert.Equal(t, OperationCompleted, resp.Result.(map[string]any)["state"])
+		break
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+// maxRESTBodySize bounds the JSON body of REST requests
+const maxRESTBodySize = 1 << 20
+
+// mountREST adds plain REST endpoints for integrations that don't speak
+// JSON-RPC, such as webhooks and curl scripts. They run the same handlers as
+// the JSON-RPC methods they map to.
+func mountREST(mux *http.ServeMux) {
+	mux.HandleFunc("GET /devices", limitConcurrentRequests(handleRESTDevices, requestSlots))
+	mux.HandleFunc("GET /device/{id}/screenshot", limitConcurrentRequests(handleDeviceScreenshot, requestSlots))
+	mux.HandleFunc("POST /device/{id}/tap", limitConcurrentRequests(restDeviceMethod("device.io.tap"), requestSlots))
+	mux.HandleFunc("POST /device/{id}/text", limitConcurrentRequests(restDeviceMethod("device.io.text"), requestSlots))
+}
+
+// restError is the body of failed REST requests
+type restError struct {
+	Error string `json:"error"`
+}
+
+func sendRESTResult(w http.ResponseWriter, status int, result any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(result)
+}
+
+// handleRESTDevices serves GET /devices, taking the devices.list params as
+// query parameters, e.g. /devices?platform=ios&includeOffline=true&label=team=payments
+func handleRESTDevices(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	params := DevicesParams{
+		Platform:  query.Get("platform"),
+		Type:      query.Get("type"),
+		Transport: query.Get("transport"),
+	}
+
+	for name, value := range map[string]*bool{"includeOffline": &params.IncludeOffline, "includeUnbooted": &params.IncludeUnbooted, "checkAgents": &params.CheckAgents} {
+		if query.Get(name) == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(query.Get(name))
+		if err != nil {
+			sendRESTResult(w, http.StatusBadRequest, restError{Error: fmt.Sprintf("invalid %s '%s'", name, query.Get(name))})
+			return
+		}
+		*value = b
+	}
+
+	if labels := query["label"]; len(labels) > 0 {
+		selector, err := devices.ParseLabels(labels)
+		if err != nil {
+			sendRESTResult(w, http.StatusBadRequest, restError{Error: err.Error()})
+			return
+		}
+		params.Labels = selector
+	}
+
+	body, err := json.Marshal(params)
+	if err != nil {
+		sendRESTResult(w, http.StatusInternalServerError, restError{Error: err.Error()})
+		return
+	}
+
+	result, err := Execute("devices.list", body)
+	if err != nil {
+		sendRESTResult(w, http.StatusInternalServerError, restError{Error: err.Error()})
+		return
+	}
+	sendRESTResult(w, http.StatusOK, result)
+}
+
+// restDeviceMethod serves a JSON-RPC method on a device: the JSON body holds
+// the method's params, and the device ID comes from the path
+func restDeviceMethod(method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params := map[string]any{}
+
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxRESTBodySize))
+		if err != nil {
+			sendRESTResult(w, http.StatusBadRequest, restError{Error: fmt.Sprintf("failed to read body: %v", err)})
+			return
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &params); err != nil {
+				sendRESTResult(w, http.StatusBadRequest, restError{Error: fmt.Sprintf("invalid JSON body: %v", err)})
+				return
+			}
+		}
+		params["deviceId"] = r.PathValue("id")
+
+		body, err := json.Marshal(params)
+		if err != nil {
+			sendRESTResult(w, http.StatusInternalServerError, restError{Error: err.Error()})
+			return
+		}
+
+		result, err := Execute(method, body)
+		if err != nil {
+			sendRESTResult(w, http.StatusInternalServerError, restError{Error: err.Error()})
+			return
+		}
+		sendRESTResult(w, http.StatusOK, result)
+	}
+}
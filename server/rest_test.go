@@ -0,0 +1,82 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+func TestRESTEndpoints(t *testing.T) {
+	t.Setenv(devices.FakeDevicesEnvVar, "2")
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	_, _ = devices.GetAllControllableDevices(false)
+	device := fake.Get("fake-ios-2")
+	if device == nil {
+		t.Fatal("fake device not found")
+	}
+
+	mux := http.NewServeMux()
+	mountREST(mux)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "/devices?platform=ios", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /devices: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var list struct {
+		Devices []devices.DeviceInfo `json:"devices"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("GET /devices: invalid body: %v", err)
+	}
+	if len(list.Devices) != 1 || list.Devices[0].ID != "fake-ios-2" {
+		t.Errorf("GET /devices: expected only fake-ios-2, got %+v", list.Devices)
+	}
+
+	rec = serve(http.MethodGet, "/devices?includeOffline=maybe", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for a bad boolean, got %d", rec.Code)
+	}
+
+	rec = serve(http.MethodPost, "/device/fake-ios-2/tap", `{"x":10,"y":20}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST tap: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(http.MethodPost, "/device/fake-ios-2/text", `{"text":"hello"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST text: expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	actions := device.Actions()
+	if len(actions) < 2 || actions[len(actions)-2] != "tap 10,20" || actions[len(actions)-1] != "type hello" {
+		t.Errorf("expected a tap and text input, got %v", actions)
+	}
+
+	rec = serve(http.MethodPost, "/device/fake-ios-2/tap", `{"x":`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for invalid JSON, got %d", rec.Code)
+	}
+
+	rec = serve(http.MethodPost, "/device/missing/tap", `{"x":1,"y":1}`)
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "error") {
+		t.Errorf("expected an error for an unknown device, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serve(http.MethodGet, "/device/fake-ios-2/tap", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET tap, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mobile-next/mobilecli/commands"
+)
+
+// screenshotReadOnlyMethods only read from their device. Every other device
+// method may change what is on screen, so it invalidates the cached
+// screenshots and UI dumps of its device, see invalidatesScreenshots.
+var screenshotReadOnlyMethods = map[string]bool{
+	"device.screenshot":                     true,
+	"device.snapshot":                       true,
+	"device.screencapture":                  true,
+	"device.screencapture.setConfiguration": true,
+	"device.screencapture.requestKeyFrame":  true,
+	"device.screencapture.sessions":         true,
+	"device.screenrecord":                   true,
+	"device.screenrecord.stop":              true,
+	"device.dump.ui":                        true,
+	"device.info":                           true,
+	"device.props":                          true,
+	"device.window":                         true,
+	"device.labels":                         true,
+	"device.bugreport":                      true,
+	"device.io.orientation.get":             true,
+	"device.alert.text":                     true,
+	"device.apps.list":                      true,
+	"device.apps.running":                   true,
+	"device.apps.state":                     true,
+	"device.apps.foreground":                true,
+	"device.apps.crashes":                   true,
+	"device.apps.verify":                    true,
+	"device.apps.path":                      true,
+	"device.apps.container":                 true,
+	"device.crashes.list":                   true,
+	"device.crashes.get":                    true,
+	"device.fs.ls":                          true,
+	"device.fs.pull":                        true,
+	"device.notifications.list":             true,
+	"device.root.status":                    true,
+	"device.storage.info":                   true,
+	"device.users.list":                     true,
+	"device.reverse.list":                   true,
+	"device.webview.list":                   true,
+	"device.webview.content":                true,
+	"device.webview.url":                    true,
+	"device.webview.title":                  true,
+	"device.webview.query":                  true,
+	"device.webview.dump":                   true,
+	"device.webview.devtools.list":          true,
+	"device.webview.waitForLoadState":       true,
+}
+
+// invalidatesScreenshots reports whether a method may change what is on
+// its device's screen
+func invalidatesScreenshots(method string) bool {
+	return strings.HasPrefix(method, "device.") && !screenshotReadOnlyMethods[method]
+}
+
+// screenshotCacheKey identifies a cached capture: the same device, format and
+// quality produce the same image
+type screenshotCacheKey struct {
+	deviceID string
+	format   string
+	quality  int
+}
+
+type cachedScreenshot struct {
+	data       []byte
+	etag       string
+	capturedAt time.Time
+}
+
+// screenshotCache reuses screenshots captured within ttl, so clients polling
+// a device don't each cost a capture. Input commands invalidate a device's
+// screenshots by bumping its generation.
+type screenshotCache struct {
+	mu          sync.Mutex
+	ttl         time.Duration
+	entries     map[screenshotCacheKey]*cachedScreenshot
+	generations map[string]uint64
+	capturing   map[screenshotCacheKey]*sync.Mutex
+}
+
+var screenshots = &screenshotCache{
+	entries:     make(map[screenshotCacheKey]*cachedScreenshot),
+	generations: make(map[string]uint64),
+	capturing:   make(map[screenshotCacheKey]*sync.Mutex),
+}
+
+// SetScreenshotCacheTTL makes screenshots of a device reuse a capture taken
+// within ttl. 0 disables the cache.
+func SetScreenshotCacheTTL(ttl time.Duration) {
+	screenshots.mu.Lock()
+	defer screenshots.mu.Unlock()
+	screenshots.ttl = ttl
+	clear(screenshots.entries)
+}
+
+// InvalidateScreenshotCache drops the cached screenshots of a device, or of
+// every device when deviceID is empty
+func InvalidateScreenshotCache(deviceID string) {
+	screenshots.mu.Lock()
+	defer screenshots.mu.Unlock()
+
+	if deviceID == "" {
+		for id := range screenshots.generations {
+			screenshots.generations[id]++
+		}
+		clear(screenshots.entries)
+		return
+	}
+
+	screenshots.generations[deviceID]++
+	for key := range screenshots.entries {
+		if key.deviceID == deviceID {
+			delete(screenshots.entries, key)
+		}
+	}
+}
+
+// invalidatingScreenshots wraps the handler of a device method to invalidate
+// the screenshots and UI dumps of its device once it has run
+func invalidatingScreenshots(handler HandlerFunc) HandlerFunc {
+	return func(params json.RawMessage) (any, error) {
+		defer func() {
+			var target struct {
+				DeviceID string `json:"deviceId"`
+			}
+			_ = json.Unmarshal(params, &target)
+			InvalidateScreenshotCache(target.DeviceID)
+			commands.InvalidateDumpCache(target.DeviceID)
+		}()
+		return handler(params)
+	}
+}
+
+// lookup returns a capture of key taken within the ttl, or nil
+func (c *screenshotCache) lookup(key screenshotCacheKey) *cachedScreenshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Since(entry.capturedAt) > c.ttl {
+		return nil
+	}
+	return entry
+}
+
+// get returns a screenshot of key, captured with capture unless a recent
+// enough one is cached. Concurrent requests for the same key share a capture.
+func (c *screenshotCache) get(key screenshotCacheKey, capture func() ([]byte, error)) (*cachedScreenshot, error) {
+	c.mu.Lock()
+	if c.ttl <= 0 {
+		c.mu.Unlock()
+		data, err := capture()
+		if err != nil {
+			return nil, err
+		}
+		return &cachedScreenshot{data: data, etag: screenshotETag(data), capturedAt: time.Now()}, nil
+	}
+
+	lock, ok := c.capturing[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		c.capturing[key] = lock
+	}
+	c.mu.Unlock()
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	if entry := c.lookup(key); entry != nil {
+		return entry, nil
+	}
+
+	c.mu.Lock()
+	generation := c.generations[key.deviceID]
+	c.mu.Unlock()
+
+	data, err := capture()
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &cachedScreenshot{
+		data:       data,
+		etag:       screenshotETag(data),
+		capturedAt: time.Now(),
+	}
+
+	// an input command during the capture makes it stale before it is cached
+	c.mu.Lock()
+	if c.generations[key.deviceID] == generation {
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	return entry, nil
+}
+
+// screenshotETag is a strong ETag over the image bytes
+func screenshotETag(data []byte) string {
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// captureScreenshot takes a screenshot with ScreenshotCommand and returns the
+// image bytes
+func captureScreenshot(key screenshotCacheKey) ([]byte, error) {
+	response := commands.ScreenshotCommand(commands.ScreenshotRequest{
+		DeviceID:   key.deviceID,
+		Format:     key.format,
+		Quality:    key.quality,
+		OutputPath: "-",
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	screenshot, ok := response.Data.(commands.ScreenshotResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format")
+	}
+	return base64.StdEncoding.DecodeString(screenshot.Data)
+}
+
+// handleDeviceScreenshot serves GET /device/{id}/screenshot, the raw image of
+// a device's screen. It takes format (png, jpeg) and quality query
+// parameters, and answers If-None-Match with 304 when the screen is unchanged.
+func handleDeviceScreenshot(w http.ResponseWriter, r *http.Request) {
+	key := screenshotCacheKey{
+		deviceID: r.PathValue("id"),
+		format:   strings.ToLower(r.URL.Query().Get("format")),
+	}
+	if key.format == "" {
+		key.format = "png"
+	}
+	if key.format != "png" && key.format != "jpeg" {
+		http.Error(w, fmt.Sprintf("invalid format '%s'. Supported formats are 'png' and 'jpeg'", key.format), http.StatusBadRequest)
+		return
+	}
+	if quality := r.URL.Query().Get("quality"); quality != "" && key.format == "jpeg" {
+		n, err := strconv.Atoi(quality)
+		if err != nil || n < 1 || n > 100 {
+			http.Error(w, "quality must be a number between 1 and 100", http.StatusBadRequest)
+			return
+		}
+		key.quality = n
+	}
+
+	entry, err := screenshots.get(key, func() ([]byte, error) {
+		return captureScreenshot(key)
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", entry.etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Last-Modified", entry.capturedAt.UTC().Format(http.TimeFormat))
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/"+key.format)
+	w.Header().Set("Content-Length", strconv.Itoa(len(entry.data)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(entry.data)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
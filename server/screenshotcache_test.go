@@ -0,0 +1,126 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/fake"
+)
+
+// getScreenshot requests /device/{id}/screenshot with an optional If-None-Match
+func getScreenshot(t *testing.T, mux *http.ServeMux, deviceID, etag string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/device/"+deviceID+"/screenshot", nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDeviceScreenshotCache(t *testing.T) {
+	t.Setenv(devices.FakeDevicesEnvVar, "1")
+	t.Setenv("MOBILECLI_REMOTE_ONLY", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { SetScreenshotCacheTTL(0) })
+
+	_, _ = devices.GetAllControllableDevices(false)
+	device := fake.Get("fake-android-1")
+	if device == nil {
+		t.Fatal("fake device not found")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /device/{id}/screenshot", handleDeviceScreenshot)
+
+	SetScreenshotCacheTTL(time.Minute)
+	device.SetScreenshot([]byte("first"))
+
+	rec := getScreenshot(t, mux, "fake-android-1", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "first" {
+		t.Fatalf("expected first screenshot, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected an ETag")
+	}
+
+	// the cached capture is served even though the screen changed
+	device.SetScreenshot([]byte("second"))
+	rec = getScreenshot(t, mux, "fake-android-1", etag)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 from the cache, got %d", rec.Code)
+	}
+
+	// an input command invalidates the cache
+	tap := GetMethodRegistry()["device.io.tap"]
+	if _, err := tap(json.RawMessage(`{"deviceId":"fake-android-1","x":1,"y":1}`)); err != nil {
+		t.Fatalf("tap failed: %v", err)
+	}
+
+	rec = getScreenshot(t, mux, "fake-android-1", etag)
+	if rec.Code != http.StatusOK || rec.Body.String() != "second" {
+		t.Fatalf("expected a fresh screenshot after input, got %d %q", rec.Code, rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == etag {
+		t.Error("expected the ETag to change with the screen")
+	}
+
+	// without a cache every request captures the screen
+	SetScreenshotCacheTTL(0)
+	device.SetScreenshot([]byte("third"))
+	rec = getScreenshot(t, mux, "fake-android-1", "")
+	if rec.Body.String() != "third" {
+		t.Fatalf("expected an uncached screenshot, got %q", rec.Body.String())
+	}
+
+	rec = getScreenshot(t, mux, "unknown-device", "")
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for an unknown device, got %d", rec.Code)
+	}
+}
+
+func TestInvalidatesScreenshots(t *testing.T) {
+	for method, want := range map[string]bool{
+		"device.io.tap":        true,
+		"device.sensor.shake":  true,
+		"device.apps.install":  true,
+		"device.screenshot":    false,
+		"device.dump.ui":       false,
+		"device.webview.query": false,
+		"devices.list":         false,
+		"server.info":          false,
+	} {
+		if got := invalidatesScreenshots(method); got != want {
+			t.Errorf("invalidatesScreenshots(%s) = %v, want %v", method, got, want)
+		}
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		match  bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{``, false},
+	}
+
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"abc"`); got != tt.match {
+			t.Errorf("etagMatches(%q) = %v, want %v", tt.header, got, tt.match)
+		}
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"path"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
@@ -19,6 +21,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/mobile-next/mobilecli/commands"
 	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/devices/wda"
 	"github.com/mobile-next/mobilecli/utils"
 )
 
@@ -60,6 +63,8 @@ type StreamSession struct {
 	Format    string // "mjpeg" or "avc"
 	Quality   int
 	Scale     float64
+	FPS       int
+	Thermal   *devices.ThermalGuard // nil when not asked for
 	CreatedAt time.Time
 	ExpiresAt time.Time // CreatedAt + 1 minute
 	InUse     bool      // prevents duplicate connections
@@ -95,9 +100,11 @@ type JSONRPCResponse struct {
 
 // ScreenshotParams represents the parameters for the screenshot request
 type ScreenshotParams struct {
-	DeviceID string `json:"deviceId"`
-	Format   string `json:"format,omitempty"`  // "png" or "jpeg"
-	Quality  int    `json:"quality,omitempty"` // 1-100, only used for JPEG
+	DeviceID string                     `json:"deviceId"`
+	Format   string                     `json:"format,omitempty"`  // "png" or "jpeg"
+	Quality  int                        `json:"quality,omitempty"` // 1-100, only used for JPEG
+	Clip     *devices.ScreenElementRect `json:"clip,omitempty"`
+	Element  string                     `json:"element,omitempty"` // e.g. "identifier=loginButton"
 }
 
 // DevicesParams represents the parameters for the devices request
@@ -105,6 +112,19 @@ type DevicesParams struct {
 	IncludeOffline bool   `json:"includeOffline,omitempty"`
 	Platform       string `json:"platform,omitempty"`
 	Type           string `json:"type,omitempty"`
+	Transport      string `json:"transport,omitempty"` // "usb" or "network"
+	CheckAgents    bool   `json:"checkAgents,omitempty"`
+
+	// IncludeUnbooted also lists simulators that were never booted, with
+	// their runtime's availability
+	IncludeUnbooted bool `json:"includeUnbooted,omitempty"`
+
+	// Labels only lists devices with all of these labels
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// BackendTimeoutMs is how long to wait for each backend before leaving
+	// its devices out (default: 10000)
+	BackendTimeoutMs int `json:"backendTimeoutMs,omitempty"`
 }
 
 // corsMiddleware handles CORS preflight requests and adds CORS headers to responses.
@@ -190,11 +210,16 @@ func (sm *SessionManager) RemoveSession(id string) {
 	delete(sm.sessions, id)
 }
 
-func StartServer(addr string, enableCORS bool) error {
+// StartServer serves JSON-RPC on addr until shutdown. With enableWebDriver it
+// also serves a minimal W3C WebDriver facade for WebDriver clients.
+func StartServer(addr string, enableCORS bool, enableWebDriver bool) error {
 	// create shutdown hook for cleanup tracking
 	hook := devices.NewShutdownHook()
 	commands.SetShutdownHook(hook)
 
+	// clients don't share a last used device
+	commands.SetLastUsedDeviceEnabled(false)
+
 	// initialize session manager
 	sessionManager = &SessionManager{
 		sessions: make(map[string]*StreamSession),
@@ -209,6 +234,16 @@ func StartServer(addr string, enableCORS bool) error {
 	mux.HandleFunc("/rpc", handleJSONRPC)
 	mux.HandleFunc("/ws", NewWebSocketHandler(enableCORS))
 	mux.HandleFunc("/stream", handleStream)
+	mux.HandleFunc("/healthz", handleHealthz)
+	mux.HandleFunc("/readyz", handleReadyz)
+	mountREST(mux)
+	mountViewer(mux)
+	mountUploads(mux)
+	hook.Register("uploads", uploads.removeAll)
+
+	if enableWebDriver {
+		mountWebDriver(mux)
+	}
 
 	// if host is missing, default to localhost
 	if !strings.Contains(addr, ":") {
@@ -227,20 +262,36 @@ func StartServer(addr string, enableCORS bool) error {
 	}
 
 	server := &http.Server{
-		Addr:         addr,
-		Handler:      handler,
-		ReadTimeout:  ReadTimeout,
-		WriteTimeout: WriteTimeout,
-		IdleTimeout:  IdleTimeout,
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+		ReadTimeout:       ReadTimeout,
+		WriteTimeout:      WriteTimeout,
+		IdleTimeout:       IdleTimeout,
+	}
+
+	if err := startGRPCServer(hook); err != nil {
+		return err
 	}
 
+	utils.Info("Starting server on http://%s...", server.Addr)
+	return serveUntilShutdown(server, server.ListenAndServe, hook)
+}
+
+// devicePruneInterval is how often the server checks for disconnected devices
+// whose resources can be released
+const devicePruneInterval = 30 * time.Second
+
+// serveUntilShutdown runs serve in the background and blocks until the server
+// fails, a termination signal arrives, or server.shutdown is called over JSON-RPC.
+// On shutdown it stops any active recording and runs the cleanup hooks.
+func serveUntilShutdown(server *http.Server, serve func() error, hook *devices.ShutdownHook) error {
 	// channel to catch server errors
 	serverErr := make(chan error, 1)
 
 	// start server in goroutine
 	go func() {
-		utils.Info("Starting server on http://%s...", server.Addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			serverErr <- err
 		}
 	}()
@@ -249,6 +300,22 @@ func StartServer(addr string, enableCORS bool) error {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
+	// release the port forwarders of devices that get disconnected
+	stopPruning := make(chan struct{})
+	defer close(stopPruning)
+	go func() {
+		ticker := time.NewTicker(devicePruneInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				commands.PruneDisconnectedDevices()
+			case <-stopPruning:
+				return
+			}
+		}
+	}()
+
 	performShutdown := func() error {
 		// stop any active recording
 		if session, err := recorder.stop(); err == nil {
@@ -294,8 +361,15 @@ func handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodySize)
+
 	var req JSONRPCRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			sendJSONRPCError(w, nil, ErrCodeInvalidRequest, "Invalid Request", fmt.Sprintf("request body exceeds the limit of %d bytes", tooLarge.Limit))
+			return
+		}
 		sendJSONRPCError(w, nil, ErrCodeParseError, "Parse error", "expecting jsonrpc payload")
 		return
 	}
@@ -318,10 +392,41 @@ func handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 	// HTTP-specific: extend timeout for long-running operations
 	switch req.Method {
 	case "device.boot":
-		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(3 * time.Minute))
+		var bootParams DeviceBootParams
+		_ = json.Unmarshal(req.Params, &bootParams)
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(bootWriteTimeout(bootParams.Timeout)))
+		if bootParams.Progress {
+			handleDeviceBootWithProgress(w, req.ID, bootParams)
+			return
+		}
+	case "device.authorize":
+		var authorizeParams DeviceAuthorizeParams
+		_ = json.Unmarshal(req.Params, &authorizeParams)
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Duration(authorizeParams.Wait)*time.Second + time.Minute))
 	case "device.screenrecord.stop":
 		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(35 * time.Second))
+	case "device.bugreport":
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(10 * time.Minute))
+	case "operations.subscribe":
+		handleOperationsSubscribeHTTP(r.Context(), w, req.ID, req.Params)
+		return
+	case "device.apps.install":
+		// multi-gigabyte apps take minutes to push and install
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(installWriteTimeout))
+		var installParams AppsInstallParams
+		_ = json.Unmarshal(req.Params, &installParams)
+		if installParams.Progress {
+			handleAppsInstallWithProgress(w, req.ID, req.Params)
+			return
+		}
+	}
+
+	// the streaming methods above hold their connection, the rest share the
+	// request slots
+	if !acquireRequestSlot(w, requestSlots) {
+		return
 	}
+	defer func() { <-requestSlots }()
 
 	// Use registry for all methods
 	if req.Method == "" {
@@ -330,7 +435,11 @@ func handleJSONRPC(w http.ResponseWriter, r *http.Request) {
 		registry := GetMethodRegistry()
 		handler, exists := registry[req.Method]
 		if exists {
-			result, err = handler(req.Params)
+			result, err = runHandler(r.Context(), req.Method, handler, req.Params)
+			if r.Context().Err() != nil {
+				utils.Info("Request ID: %v, Method: %s abandoned, the client disconnected", req.ID, req.Method)
+				return
+			}
 		} else {
 			sendJSONRPCError(w, req.ID, ErrCodeMethodNotFound, "Method not found", fmt.Sprintf("Method '%s' not found", req.Method))
 			return
@@ -375,6 +484,11 @@ func handleDevicesList(params json.RawMessage) (any, error) {
 		opts.IncludeOffline = devicesParams.IncludeOffline
 		opts.Platform = devicesParams.Platform
 		opts.DeviceType = devicesParams.Type
+		opts.Transport = devicesParams.Transport
+		opts.CheckAgents = devicesParams.CheckAgents
+		opts.Labels = devicesParams.Labels
+		opts.IncludeUnbooted = devicesParams.IncludeUnbooted
+		opts.BackendTimeoutMs = devicesParams.BackendTimeoutMs
 	}
 
 	response := commands.DevicesCommand(opts, commands.GetFleetToken())
@@ -384,6 +498,112 @@ func handleDevicesList(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
+// ForwardListParams represents the parameters for listing port forwarders
+type ForwardListParams struct {
+	DeviceID string `json:"deviceId,omitempty"`
+}
+
+func handleForwardList(params json.RawMessage) (any, error) {
+	var forwardParams ForwardListParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &forwardParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.ForwardListCommand(commands.ForwardListRequest{
+		DeviceID: forwardParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// ReverseParams represents the parameters for reverse port forwarding
+type ReverseParams struct {
+	DeviceID   string `json:"deviceId"`
+	DevicePort int    `json:"devicePort"`
+	HostPort   int    `json:"hostPort,omitempty"`
+}
+
+func handleReverse(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: devicePort")
+	}
+
+	var reverseParams ReverseParams
+	if err := json.Unmarshal(params, &reverseParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), devicePort, hostPort (optional)", err)
+	}
+
+	// the host port defaults to the device port, as with 'reverse 8080'
+	hostPort := reverseParams.HostPort
+	if hostPort == 0 {
+		hostPort = reverseParams.DevicePort
+	}
+
+	response := commands.ReverseCommand(commands.ReverseRequest{
+		DeviceID:   reverseParams.DeviceID,
+		DevicePort: reverseParams.DevicePort,
+		HostPort:   hostPort,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// ReverseListParams represents the parameters for listing reversed ports
+type ReverseListParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleReverseList(params json.RawMessage) (any, error) {
+	var listParams ReverseListParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &listParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.ReverseListCommand(commands.ReverseListRequest{
+		DeviceID: listParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
+// ReverseRemoveParams represents the parameters for removing reversed ports
+type ReverseRemoveParams struct {
+	DeviceID   string `json:"deviceId"`
+	DevicePort int    `json:"devicePort,omitempty"`
+	All        bool   `json:"all,omitempty"`
+}
+
+func handleReverseRemove(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: devicePort or all")
+	}
+
+	var removeParams ReverseRemoveParams
+	if err := json.Unmarshal(params, &removeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), devicePort or all", err)
+	}
+
+	response := commands.ReverseRemoveCommand(commands.ReverseRemoveRequest{
+		DeviceID:   removeParams.DeviceID,
+		DevicePort: removeParams.DevicePort,
+		All:        removeParams.All,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
 func handleScreenshot(params json.RawMessage) (any, error) {
 	var screenshotParams ScreenshotParams
 	if err := json.Unmarshal(params, &screenshotParams); err != nil {
@@ -395,6 +615,29 @@ func handleScreenshot(params json.RawMessage) (any, error) {
 		Format:     screenshotParams.Format,
 		Quality:    screenshotParams.Quality,
 		OutputPath: "-", // Always return base64 data for server
+		Clip:       screenshotParams.Clip,
+		Element:    screenshotParams.Element,
+	}
+
+	// whole screen captures of a given device can be served from the cache
+	if req.DeviceID != "" && req.Clip == nil && req.Element == "" {
+		key := screenshotCacheKey{deviceID: req.DeviceID, format: strings.ToLower(req.Format), quality: req.Quality}
+		if key.format == "" {
+			key.format = "png"
+		}
+		if key.format != "jpeg" {
+			key.quality = 0
+		}
+		entry, err := screenshots.get(key, func() ([]byte, error) {
+			return captureScreenshot(key)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{
+			"format": key.format,
+			"data":   fmt.Sprintf("data:image/%s;base64,%s", key.format, base64.StdEncoding.EncodeToString(entry.data)),
+		}, nil
 	}
 
 	response := commands.ScreenshotCommand(req)
@@ -413,25 +656,68 @@ func handleScreenshot(params json.RawMessage) (any, error) {
 	return nil, fmt.Errorf("unexpected response format")
 }
 
-type IoTapParams struct {
+type SnapshotParams struct {
 	DeviceID string `json:"deviceId"`
-	X        int    `json:"x"`
-	Y        int    `json:"y"`
+	Format   string `json:"format,omitempty"`
+	Quality  int    `json:"quality,omitempty"`
+	Query    string `json:"query,omitempty"`
+}
+
+func handleSnapshot(params json.RawMessage) (any, error) {
+	var snapshotParams SnapshotParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &snapshotParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, format (optional), quality (optional), query (optional)", err)
+		}
+	}
+
+	response := commands.SnapshotCommand(commands.SnapshotRequest{
+		DeviceID: snapshotParams.DeviceID,
+		Format:   snapshotParams.Format,
+		Quality:  snapshotParams.Quality,
+		Query:    snapshotParams.Query,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type IoTapParams struct {
+	DeviceID       string                    `json:"deviceId"`
+	X              int                       `json:"x,omitempty"`
+	Y              int                       `json:"y,omitempty"`
+	Normalized     *commands.NormalizedPoint `json:"normalized,omitempty"`
+	EnsureUnlocked bool                      `json:"ensureUnlocked,omitempty"`
+	HandleDialogs  string                    `json:"handleDialogs,omitempty"`
+	Show           bool                      `json:"show,omitempty"`
 }
 
 type IoLongPressParams struct {
-	DeviceID string `json:"deviceId"`
-	X        int    `json:"x"`
-	Y        int    `json:"y"`
-	Duration int    `json:"duration"`
+	DeviceID       string                    `json:"deviceId"`
+	X              int                       `json:"x,omitempty"`
+	Y              int                       `json:"y,omitempty"`
+	Duration       int                       `json:"duration"`
+	Normalized     *commands.NormalizedPoint `json:"normalized,omitempty"`
+	EnsureUnlocked bool                      `json:"ensureUnlocked,omitempty"`
+	HandleDialogs  string                    `json:"handleDialogs,omitempty"`
 }
 
 type IoSwipeParams struct {
-	DeviceID string `json:"deviceId"`
-	X1       int    `json:"x1"`
-	Y1       int    `json:"y1"`
-	X2       int    `json:"x2"`
-	Y2       int    `json:"y2"`
+	DeviceID   string `json:"deviceId"`
+	X1         int    `json:"x1,omitempty"`
+	Y1         int    `json:"y1,omitempty"`
+	X2         int    `json:"x2,omitempty"`
+	Y2         int    `json:"y2,omitempty"`
+	DurationMs int    `json:"durationMs"`
+	Direction  string `json:"direction,omitempty"`
+	Distance   int    `json:"distance,omitempty"`
+
+	NormalizedFrom *commands.NormalizedPoint `json:"normalizedFrom,omitempty"`
+	NormalizedTo   *commands.NormalizedPoint `json:"normalizedTo,omitempty"`
+	EnsureUnlocked bool                      `json:"ensureUnlocked,omitempty"`
+	HandleDialogs  string                    `json:"handleDialogs,omitempty"`
 }
 
 func handleIoTap(params json.RawMessage) (any, error) {
@@ -445,9 +731,13 @@ func handleIoTap(params json.RawMessage) (any, error) {
 	}
 
 	req := commands.TapRequest{
-		DeviceID: ioTapParams.DeviceID,
-		X:        ioTapParams.X,
-		Y:        ioTapParams.Y,
+		DeviceID:       ioTapParams.DeviceID,
+		EnsureUnlocked: ioTapParams.EnsureUnlocked,
+		HandleDialogs:  ioTapParams.HandleDialogs,
+		X:              ioTapParams.X,
+		Y:              ioTapParams.Y,
+		Normalized:     ioTapParams.Normalized,
+		Show:           ioTapParams.Show,
 	}
 
 	response := commands.TapCommand(req)
@@ -455,6 +745,11 @@ func handleIoTap(params json.RawMessage) (any, error) {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
 
+	// the annotated screenshot's path is the result of a shown tap
+	if req.Show {
+		return response.Data, nil
+	}
+
 	return okResponse, nil
 }
 
@@ -475,10 +770,13 @@ func handleIoLongPress(params json.RawMessage) (any, error) {
 	}
 
 	req := commands.LongPressRequest{
-		DeviceID: ioLongPressParams.DeviceID,
-		X:        ioLongPressParams.X,
-		Y:        ioLongPressParams.Y,
-		Duration: duration,
+		DeviceID:       ioLongPressParams.DeviceID,
+		EnsureUnlocked: ioLongPressParams.EnsureUnlocked,
+		HandleDialogs:  ioLongPressParams.HandleDialogs,
+		X:              ioLongPressParams.X,
+		Y:              ioLongPressParams.Y,
+		Duration:       duration,
+		Normalized:     ioLongPressParams.Normalized,
 	}
 
 	response := commands.LongPressCommand(req)
@@ -491,37 +789,48 @@ func handleIoLongPress(params json.RawMessage) (any, error) {
 
 func handleIoSwipe(params json.RawMessage) (any, error) {
 	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId, x1, y1, x2, y2")
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, x1, y1, x2, y2 or direction")
 	}
 
 	var ioSwipeParams IoSwipeParams
 	if err := json.Unmarshal(params, &ioSwipeParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, x1, y1, x2, y2", err)
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, x1, y1, x2, y2 or direction, distance (optional), durationMs (optional)", err)
 	}
 
 	if ioSwipeParams.DeviceID == "" {
 		return nil, fmt.Errorf("'deviceId' is required")
 	}
 
-	// validate that coordinates are provided (x1,y1,x2,y2 must be present)
-	var rawParams map[string]any
-	if err := json.Unmarshal(params, &rawParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters format")
-	}
+	// validate that coordinates are provided (x1,y1,x2,y2 must be present),
+	// unless the swipe is directional or normalized
+	if ioSwipeParams.Direction == "" && ioSwipeParams.NormalizedFrom == nil {
+		var rawParams map[string]any
+		if err := json.Unmarshal(params, &rawParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters format")
+		}
 
-	requiredFields := []string{"x1", "y1", "x2", "y2"}
-	for _, field := range requiredFields {
-		if _, exists := rawParams[field]; !exists {
-			return nil, fmt.Errorf("'%s' is required", field)
+		requiredFields := []string{"x1", "y1", "x2", "y2"}
+		for _, field := range requiredFields {
+			if _, exists := rawParams[field]; !exists {
+				return nil, fmt.Errorf("'%s' is required", field)
+			}
 		}
 	}
 
 	req := commands.SwipeRequest{
-		DeviceID: ioSwipeParams.DeviceID,
-		X1:       ioSwipeParams.X1,
-		Y1:       ioSwipeParams.Y1,
-		X2:       ioSwipeParams.X2,
-		Y2:       ioSwipeParams.Y2,
+		DeviceID:       ioSwipeParams.DeviceID,
+		EnsureUnlocked: ioSwipeParams.EnsureUnlocked,
+		HandleDialogs:  ioSwipeParams.HandleDialogs,
+		X1:             ioSwipeParams.X1,
+		Y1:             ioSwipeParams.Y1,
+		X2:             ioSwipeParams.X2,
+		Y2:             ioSwipeParams.Y2,
+		DurationMs:     ioSwipeParams.DurationMs,
+		Direction:      ioSwipeParams.Direction,
+		Distance:       ioSwipeParams.Distance,
+
+		NormalizedFrom: ioSwipeParams.NormalizedFrom,
+		NormalizedTo:   ioSwipeParams.NormalizedTo,
 	}
 
 	response := commands.SwipeCommand(req)
@@ -533,8 +842,11 @@ func handleIoSwipe(params json.RawMessage) (any, error) {
 }
 
 type IoTextParams struct {
-	DeviceID string `json:"deviceId"`
-	Text     string `json:"text"`
+	DeviceID       string `json:"deviceId"`
+	Text           string `json:"text"`
+	Clear          bool   `json:"clear"`
+	EnsureUnlocked bool   `json:"ensureUnlocked,omitempty"`
+	HandleDialogs  string `json:"handleDialogs,omitempty"`
 }
 
 func handleIoText(params json.RawMessage) (any, error) {
@@ -544,12 +856,15 @@ func handleIoText(params json.RawMessage) (any, error) {
 
 	var ioTextParams IoTextParams
 	if err := json.Unmarshal(params, &ioTextParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, text", err)
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, text, clear (optional)", err)
 	}
 
 	req := commands.TextRequest{
-		DeviceID: ioTextParams.DeviceID,
-		Text:     ioTextParams.Text,
+		DeviceID:       ioTextParams.DeviceID,
+		EnsureUnlocked: ioTextParams.EnsureUnlocked,
+		HandleDialogs:  ioTextParams.HandleDialogs,
+		Text:           ioTextParams.Text,
+		Clear:          ioTextParams.Clear,
 	}
 
 	response := commands.TextCommand(req)
@@ -561,8 +876,9 @@ func handleIoText(params json.RawMessage) (any, error) {
 }
 
 type IoKeysParams struct {
-	DeviceID string   `json:"deviceId"`
-	Keys     []string `json:"keys"`
+	DeviceID       string   `json:"deviceId"`
+	Keys           []string `json:"keys"`
+	EnsureUnlocked bool     `json:"ensureUnlocked,omitempty"`
 }
 
 func handleIoKeys(params json.RawMessage) (any, error) {
@@ -576,8 +892,9 @@ func handleIoKeys(params json.RawMessage) (any, error) {
 	}
 
 	req := commands.KeysRequest{
-		DeviceID: ioKeysParams.DeviceID,
-		Keys:     ioKeysParams.Keys,
+		DeviceID:       ioKeysParams.DeviceID,
+		EnsureUnlocked: ioKeysParams.EnsureUnlocked,
+		Keys:           ioKeysParams.Keys,
 	}
 
 	response := commands.KeysCommand(req)
@@ -589,8 +906,11 @@ func handleIoKeys(params json.RawMessage) (any, error) {
 }
 
 type IoButtonParams struct {
-	DeviceID string `json:"deviceId"`
-	Button   string `json:"button"`
+	DeviceID       string `json:"deviceId"`
+	Button         string `json:"button,omitempty"`
+	Keycode        int    `json:"keycode,omitempty"`
+	EnsureUnlocked bool   `json:"ensureUnlocked,omitempty"`
+	HandleDialogs  string `json:"handleDialogs,omitempty"`
 }
 
 type IoGestureParams struct {
@@ -623,6 +943,13 @@ type DeviceSettingsApplyParams struct {
 
 type DeviceBootParams struct {
 	DeviceID string `json:"deviceId"`
+	Timeout  int    `json:"timeout,omitempty"`
+	Progress bool   `json:"progress,omitempty"`
+}
+
+type DeviceAuthorizeParams struct {
+	DeviceID string `json:"deviceId"`
+	Wait     int    `json:"wait,omitempty"`
 }
 
 type DeviceShutdownParams struct {
@@ -636,6 +963,13 @@ type DeviceRebootParams struct {
 type DumpUIParams struct {
 	DeviceID string `json:"deviceId"`
 	Format   string `json:"format,omitempty"` // "json" or "raw"
+	Query    string `json:"query,omitempty"`  // see commands.ElementQuery
+
+	MaxDepth     int  `json:"maxDepth,omitempty"`
+	MaxElements  int  `json:"maxElements,omitempty"`
+	ViewportOnly bool `json:"viewportOnly,omitempty"`
+
+	HandleDialogs string `json:"handleDialogs,omitempty"`
 }
 
 type AppsLaunchParams struct {
@@ -643,32 +977,70 @@ type AppsLaunchParams struct {
 	BundleID string   `json:"bundleId"`
 	Locales  []string `json:"locales,omitempty"`
 	Activity string   `json:"activity,omitempty"`
+	Wait     bool     `json:"wait,omitempty"`
+	User     string   `json:"user,omitempty"`
 }
 
 type AppsTerminateParams struct {
+	DeviceID string   `json:"deviceId"`
+	BundleID string   `json:"bundleId,omitempty"`
+	All      bool     `json:"all,omitempty"`
+	Exclude  []string `json:"exclude,omitempty"`
+}
+
+type AppsStateParams struct {
 	DeviceID string `json:"deviceId"`
 	BundleID string `json:"bundleId"`
 }
 
 type AppsListParams struct {
 	DeviceID string `json:"deviceId"`
+	User     string `json:"user,omitempty"`
 }
 
 type AppsForegroundParams struct {
 	DeviceID string `json:"deviceId"`
 }
 
+type AppsRunningParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+type AppsCrashesParams struct {
+	DeviceID string `json:"deviceId"`
+	BundleID string `json:"bundleId"`
+}
+
 type AppsInstallParams struct {
 	DeviceID            string `json:"deviceId"`
-	Path                string `json:"path"`
+	Path                string `json:"path,omitempty"`
+	URL                 string `json:"url,omitempty"`
+	SHA256              string `json:"sha256,omitempty"`
 	ForceResign         bool   `json:"forceResign,omitempty"`
 	ProvisioningProfile string `json:"provisioningProfile,omitempty"`
 	SigningIdentity     string `json:"signingIdentity,omitempty"`
+	GrantPermissions    bool   `json:"grantPermissions,omitempty"`
+	AllowTest           bool   `json:"allowTest,omitempty"`
+	Instant             bool   `json:"instant,omitempty"`
+	AllowDowngrade      bool   `json:"allowDowngrade,omitempty"`
+	User                string `json:"user,omitempty"`
+	Keystore            string `json:"keystore,omitempty"`
+	KeystorePassword    string `json:"keystorePassword,omitempty"`
+	KeyAlias            string `json:"keyAlias,omitempty"`
+	KeyPassword         string `json:"keyPassword,omitempty"`
+	Progress            bool   `json:"progress,omitempty"`
+	ArtifactID          string `json:"artifactId,omitempty"`
+}
+
+type AppsVerifyParams struct {
+	DeviceID string `json:"deviceId,omitempty"`
+	Path     string `json:"path"`
 }
 
 type AppsUninstallParams struct {
 	DeviceID string `json:"deviceId"`
 	BundleID string `json:"bundleId"`
+	User     string `json:"user,omitempty"`
 }
 
 type ScreenRecordParams struct {
@@ -679,17 +1051,20 @@ type ScreenRecordParams struct {
 
 func handleIoButton(params json.RawMessage) (any, error) {
 	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId, button")
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, button or keycode")
 	}
 
 	var ioButtonParams IoButtonParams
 	if err := json.Unmarshal(params, &ioButtonParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, button", err)
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, button or keycode", err)
 	}
 
 	req := commands.ButtonRequest{
-		DeviceID: ioButtonParams.DeviceID,
-		Button:   ioButtonParams.Button,
+		DeviceID:       ioButtonParams.DeviceID,
+		EnsureUnlocked: ioButtonParams.EnsureUnlocked,
+		HandleDialogs:  ioButtonParams.HandleDialogs,
+		Button:         ioButtonParams.Button,
+		Keycode:        ioButtonParams.Keycode,
 	}
 
 	response := commands.ButtonCommand(req)
@@ -761,14 +1136,815 @@ func handleDeviceInfo(params json.RawMessage) (any, error) {
 		return nil, fmt.Errorf("error finding device: %w", err)
 	}
 
-	err = targetDevice.StartAgent(devices.StartAgentConfig{
-		Hook: commands.GetShutdownHook(),
-	})
-	if err != nil {
-		return nil, fmt.Errorf("error starting agent: %w", err)
+	err = targetDevice.StartAgent(devices.StartAgentConfig{
+		Hook: commands.GetShutdownHook(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error starting agent: %w", err)
+	}
+
+	response := commands.InfoCommand(infoParams.DeviceID)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type DevicePropsParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleDeviceProps(params json.RawMessage) (any, error) {
+	var propsParams DevicePropsParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &propsParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.PropertiesCommand(commands.PropertiesRequest{
+		DeviceID: propsParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleIoOrientationGet(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+	}
+
+	var orientationGetParams IoOrientationGetParams
+	if err := json.Unmarshal(params, &orientationGetParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId", err)
+	}
+
+	req := commands.OrientationGetRequest{
+		DeviceID: orientationGetParams.DeviceID,
+	}
+
+	response := commands.OrientationGetCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleIoOrientationSet(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, orientation")
+	}
+
+	var orientationSetParams IoOrientationSetParams
+	if err := json.Unmarshal(params, &orientationSetParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, orientation", err)
+	}
+
+	req := commands.OrientationSetRequest{
+		DeviceID:    orientationSetParams.DeviceID,
+		Orientation: orientationSetParams.Orientation,
+	}
+
+	response := commands.OrientationSetCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+type SystemUIParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleSettingsOpen(params json.RawMessage) (any, error) {
+	return handleSystemUI(params, commands.OpenSettingsCommand)
+}
+
+func handleControlCenterOpen(params json.RawMessage) (any, error) {
+	return handleSystemUI(params, func(req commands.SystemUIRequest) *commands.CommandResponse {
+		return commands.ControlCenterCommand(req, true)
+	})
+}
+
+func handleControlCenterClose(params json.RawMessage) (any, error) {
+	return handleSystemUI(params, func(req commands.SystemUIRequest) *commands.CommandResponse {
+		return commands.ControlCenterCommand(req, false)
+	})
+}
+
+// handleSystemUI runs a command that opens or closes system UI
+func handleSystemUI(params json.RawMessage, command func(commands.SystemUIRequest) *commands.CommandResponse) (any, error) {
+	var systemUIParams SystemUIParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &systemUIParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := command(commands.SystemUIRequest{DeviceID: systemUIParams.DeviceID})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+type AlertParams struct {
+	DeviceID string `json:"deviceId"`
+	Button   string `json:"button,omitempty"`
+}
+
+func handleAlertText(params json.RawMessage) (any, error) {
+	return handleAlert(params, commands.AlertTextCommand)
+}
+
+func handleAlertAccept(params json.RawMessage) (any, error) {
+	return handleAlert(params, commands.AcceptAlertCommand)
+}
+
+func handleAlertDismiss(params json.RawMessage) (any, error) {
+	return handleAlert(params, commands.DismissAlertCommand)
+}
+
+// handleAlert runs a command that reads or answers the alert on screen
+func handleAlert(params json.RawMessage, command func(commands.AlertRequest) *commands.CommandResponse) (any, error) {
+	var alertParams AlertParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &alertParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), button (optional)", err)
+		}
+	}
+
+	response := command(commands.AlertRequest{DeviceID: alertParams.DeviceID, Button: alertParams.Button})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleSettingsApply(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+	}
+
+	var settingsParams DeviceSettingsApplyParams
+	if err := json.Unmarshal(params, &settingsParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, animations", err)
+	}
+
+	req := commands.ApplySettingsRequest{
+		DeviceID:   settingsParams.DeviceID,
+		Animations: settingsParams.Animations,
+	}
+
+	response := commands.ApplySettingsCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+func handleDeviceBoot(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+	}
+
+	var bootParams DeviceBootParams
+	if err := json.Unmarshal(params, &bootParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, timeout (optional), progress (optional)", err)
+	}
+
+	req := commands.BootRequest{
+		DeviceID: bootParams.DeviceID,
+		Timeout:  bootParams.Timeout,
+	}
+
+	response := commands.BootCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleDeviceAuthorize(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+	}
+
+	var authorizeParams DeviceAuthorizeParams
+	if err := json.Unmarshal(params, &authorizeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, wait (optional)", err)
+	}
+
+	req := commands.AuthorizeRequest{
+		DeviceID: authorizeParams.DeviceID,
+		Wait:     authorizeParams.Wait,
+	}
+
+	response := commands.AuthorizeCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+// bootWriteTimeout leaves a minute on top of the boot timeout for the response
+func bootWriteTimeout(timeoutSeconds int) time.Duration {
+	if timeoutSeconds <= 0 {
+		return devices.DefaultBootTimeout + time.Minute
+	}
+	return time.Duration(timeoutSeconds)*time.Second + time.Minute
+}
+
+// handleDeviceBootWithProgress streams boot stages as newline-delimited
+// JSON-RPC notifications, followed by the final response
+func handleDeviceBootWithProgress(w http.ResponseWriter, id any, bootParams DeviceBootParams) {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+
+	req := commands.BootRequest{
+		DeviceID: bootParams.DeviceID,
+		Timeout:  bootParams.Timeout,
+		OnProgress: func(stage string) {
+			_ = encoder.Encode(newJsonRpcNotification(stage))
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+		},
+	}
+
+	response := commands.BootCommand(req)
+	if response.Status == "error" {
+		sendJSONRPCError(w, id, ErrCodeServerError, "Server error", response.Error)
+		return
+	}
+
+	sendJSONRPCResponse(w, id, response.Data)
+}
+
+// handleOperationsSubscribeHTTP streams an operation's changes as
+// newline-delimited JSON-RPC notifications, followed by the finished
+// operation, until the client goes away
+func handleOperationsSubscribeHTTP(ctx context.Context, w http.ResponseWriter, id any, params json.RawMessage) {
+	opParams, err := parseOperationParams(params)
+	if err != nil {
+		sendJSONRPCError(w, id, ErrCodeServerError, "Server error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	controller := http.NewResponseController(w)
+
+	op, err := followOperation(ctx, opParams.OperationID, func(op Operation) {
+		_ = controller.SetWriteDeadline(time.Now().Add(operationWriteTimeout))
+		_ = encoder.Encode(newOperationNotification(op))
+		_ = controller.Flush()
+	})
+	if err != nil {
+		sendJSONRPCError(w, id, ErrCodeServerError, "Server error", err.Error())
+		return
+	}
+
+	_ = controller.SetWriteDeadline(time.Now().Add(operationWriteTimeout))
+	sendJSONRPCResponse(w, id, op)
+}
+
+type DeviceBugReportParams struct {
+	DeviceID string `json:"deviceId"`
+	Output   string `json:"output"`
+}
+
+// bugReportDir is where device.bugreport writes archives on the server host,
+// clients only choosing their file names
+func bugReportDir() string {
+	return filepath.Join(os.TempDir(), "mobilecli-bugreports")
+}
+
+func handleDeviceBugReport(params json.RawMessage) (any, error) {
+	var bugReportParams DeviceBugReportParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &bugReportParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), output (optional)", err)
+		}
+	}
+
+	response := commands.BugReportCommand(commands.BugReportRequest{
+		DeviceID:  bugReportParams.DeviceID,
+		Output:    bugReportParams.Output,
+		OutputDir: bugReportDir(),
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type DeviceLockParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleDeviceLock(params json.RawMessage) (any, error) {
+	var lockParams DeviceLockParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &lockParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.LockCommand(commands.LockRequest{
+		DeviceID: lockParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+func handleDeviceUnlock(params json.RawMessage) (any, error) {
+	var lockParams DeviceLockParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &lockParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.UnlockCommand(commands.LockRequest{
+		DeviceID: lockParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+func handleDeviceWake(params json.RawMessage) (any, error) {
+	var lockParams DeviceLockParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &lockParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.WakeCommand(commands.LockRequest{
+		DeviceID: lockParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+func handleDeviceScreenOff(params json.RawMessage) (any, error) {
+	var lockParams DeviceLockParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &lockParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.ScreenOffCommand(commands.LockRequest{
+		DeviceID: lockParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+type DeviceStayAwakeParams struct {
+	DeviceID string `json:"deviceId"`
+	Enabled  bool   `json:"enabled"`
+}
+
+func handleDeviceStayAwake(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with field: enabled")
+	}
+
+	var stayAwakeParams DeviceStayAwakeParams
+	if err := json.Unmarshal(params, &stayAwakeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), enabled", err)
+	}
+
+	response := commands.StayAwakeCommand(commands.StayAwakeRequest{
+		DeviceID: stayAwakeParams.DeviceID,
+		Enabled:  stayAwakeParams.Enabled,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+type DeviceWindowParams struct {
+	DeviceID string `json:"deviceId"`
+	Visible  bool   `json:"visible"`
+}
+
+func handleDeviceWindow(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with field: visible")
+	}
+
+	var windowParams DeviceWindowParams
+	if err := json.Unmarshal(params, &windowParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), visible", err)
+	}
+
+	response := commands.WindowCommand(commands.WindowRequest{
+		DeviceID: windowParams.DeviceID,
+		Visible:  windowParams.Visible,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return okResponse, nil
+}
+
+type MediaAddParams struct {
+	DeviceID string   `json:"deviceId"`
+	Paths    []string `json:"paths"`
+}
+
+func handleMediaAdd(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, paths")
+	}
+
+	var mediaParams MediaAddParams
+	if err := json.Unmarshal(params, &mediaParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, paths", err)
+	}
+
+	response := commands.MediaAddCommand(commands.MediaAddRequest{
+		DeviceID: mediaParams.DeviceID,
+		Paths:    mediaParams.Paths,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type ContactsAddParams struct {
+	DeviceID string `json:"deviceId"`
+	Path     string `json:"path"`
+}
+
+func handleContactsAdd(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, path")
+	}
+
+	var contactsParams ContactsAddParams
+	if err := json.Unmarshal(params, &contactsParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, path", err)
+	}
+
+	response := commands.ContactsAddCommand(commands.ContactsAddRequest{
+		DeviceID: contactsParams.DeviceID,
+		Path:     contactsParams.Path,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type TimeSetParams struct {
+	DeviceID string `json:"deviceId"`
+	Time     string `json:"time"`
+}
+
+func handleTimeSet(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, time")
+	}
+
+	var timeParams TimeSetParams
+	if err := json.Unmarshal(params, &timeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, time", err)
+	}
+
+	response := commands.TimeSetCommand(commands.TimeSetRequest{
+		DeviceID: timeParams.DeviceID,
+		Time:     timeParams.Time,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type TimeSyncParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleTimeSync(params json.RawMessage) (any, error) {
+	var timeParams TimeSyncParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &timeParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.TimeSyncCommand(commands.TimeSyncRequest{
+		DeviceID: timeParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type SensorSetParams struct {
+	DeviceID string    `json:"deviceId"`
+	Sensor   string    `json:"sensor"`
+	Values   []float64 `json:"values"`
+}
+
+func handleSensorSet(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, sensor, values")
+	}
+
+	var sensorParams SensorSetParams
+	if err := json.Unmarshal(params, &sensorParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, sensor, values", err)
+	}
+
+	response := commands.SensorSetCommand(commands.SensorSetRequest{
+		DeviceID: sensorParams.DeviceID,
+		Sensor:   sensorParams.Sensor,
+		Values:   sensorParams.Values,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type SensorShakeParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleSensorShake(params json.RawMessage) (any, error) {
+	var shakeParams SensorShakeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &shakeParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.SensorShakeCommand(commands.SensorShakeRequest{
+		DeviceID: shakeParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type CallParams struct {
+	DeviceID string `json:"deviceId"`
+	Number   string `json:"number"`
+}
+
+func parseCallParams(params json.RawMessage) (commands.CallRequest, error) {
+	if len(params) == 0 {
+		return commands.CallRequest{}, fmt.Errorf("'params' is required with fields: deviceId, number")
+	}
+
+	var callParams CallParams
+	if err := json.Unmarshal(params, &callParams); err != nil {
+		return commands.CallRequest{}, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, number", err)
+	}
+
+	return commands.CallRequest{
+		DeviceID: callParams.DeviceID,
+		Number:   callParams.Number,
+	}, nil
+}
+
+func handleCallIncoming(params json.RawMessage) (any, error) {
+	req, err := parseCallParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	response := commands.CallIncomingCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleCallEnd(params json.RawMessage) (any, error) {
+	req, err := parseCallParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	response := commands.CallEndCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type SMSSendParams struct {
+	DeviceID string `json:"deviceId"`
+	Number   string `json:"number"`
+	Text     string `json:"text"`
+}
+
+func handleSMSSend(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, number, text")
+	}
+
+	var smsParams SMSSendParams
+	if err := json.Unmarshal(params, &smsParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, number, text", err)
+	}
+
+	response := commands.SMSSendCommand(commands.SMSRequest{
+		DeviceID: smsParams.DeviceID,
+		Number:   smsParams.Number,
+		Text:     smsParams.Text,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type StorageParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func parseStorageParams(params json.RawMessage) (commands.StorageRequest, error) {
+	var storageParams StorageParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &storageParams); err != nil {
+			return commands.StorageRequest{}, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+	return commands.StorageRequest{DeviceID: storageParams.DeviceID}, nil
+}
+
+func handleStorageInfo(params json.RawMessage) (any, error) {
+	req, err := parseStorageParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	response := commands.StorageInfoCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleStorageFree(params json.RawMessage) (any, error) {
+	req, err := parseStorageParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	response := commands.StorageFreeCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type StorageFillParams struct {
+	DeviceID  string `json:"deviceId"`
+	LeaveFree string `json:"leaveFree,omitempty"`
+}
+
+func handleStorageFill(params json.RawMessage) (any, error) {
+	var fillParams StorageFillParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &fillParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), leaveFree (optional)", err)
+		}
+	}
+
+	response := commands.StorageFillCommand(commands.StorageFillRequest{
+		DeviceID:  fillParams.DeviceID,
+		LeaveFree: fillParams.LeaveFree,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type UsersParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleUsersList(params json.RawMessage) (any, error) {
+	var usersParams UsersParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &usersParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	response := commands.ListUsersCommand(commands.UsersRequest{DeviceID: usersParams.DeviceID})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type UsersCreateParams struct {
+	DeviceID  string `json:"deviceId"`
+	Name      string `json:"name"`
+	ProfileOf *int   `json:"profileOf,omitempty"`
+	Guest     bool   `json:"guest,omitempty"`
+}
+
+func handleUsersCreate(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, name")
+	}
+
+	var createParams UsersCreateParams
+	if err := json.Unmarshal(params, &createParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, name, profileOf (optional), guest (optional)", err)
+	}
+
+	response := commands.CreateUserCommand(commands.CreateUserRequest{
+		DeviceID:  createParams.DeviceID,
+		Name:      createParams.Name,
+		ProfileOf: createParams.ProfileOf,
+		Guest:     createParams.Guest,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+type UsersRemoveParams struct {
+	DeviceID string `json:"deviceId"`
+	UserID   *int   `json:"userId"`
+}
+
+func handleUsersRemove(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, userId")
+	}
+
+	var removeParams UsersRemoveParams
+	if err := json.Unmarshal(params, &removeParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, userId", err)
+	}
+
+	if removeParams.UserID == nil {
+		return nil, fmt.Errorf("'userId' is required")
 	}
 
-	response := commands.InfoCommand(infoParams.DeviceID)
+	response := commands.RemoveUserCommand(commands.RemoveUserRequest{
+		DeviceID: removeParams.DeviceID,
+		UserID:   *removeParams.UserID,
+	})
 	if response.Status == "error" {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
@@ -776,21 +1952,39 @@ func handleDeviceInfo(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
-func handleIoOrientationGet(params json.RawMessage) (any, error) {
-	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+type RootParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleRootStatus(params json.RawMessage) (any, error) {
+	var rootParams RootParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &rootParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
 	}
 
-	var orientationGetParams IoOrientationGetParams
-	if err := json.Unmarshal(params, &orientationGetParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId", err)
+	response := commands.RootStatusCommand(commands.RootRequest{
+		DeviceID: rootParams.DeviceID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
 	}
 
-	req := commands.OrientationGetRequest{
-		DeviceID: orientationGetParams.DeviceID,
+	return response.Data, nil
+}
+
+func handleRootEnable(params json.RawMessage) (any, error) {
+	var rootParams RootParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &rootParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
 	}
 
-	response := commands.OrientationGetCommand(req)
+	response := commands.RootEnableCommand(commands.RootRequest{
+		DeviceID: rootParams.DeviceID,
+	})
 	if response.Status == "error" {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
@@ -798,45 +1992,73 @@ func handleIoOrientationGet(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
-func handleIoOrientationSet(params json.RawMessage) (any, error) {
-	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId, orientation")
-	}
+type DeviceLabelsParams struct {
+	DeviceID string            `json:"deviceId"`
+	Set      map[string]string `json:"set,omitempty"`
+	Unset    []string          `json:"unset,omitempty"`
+}
 
-	var orientationSetParams IoOrientationSetParams
-	if err := json.Unmarshal(params, &orientationSetParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, orientation", err)
+func handleDeviceLabels(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with field: deviceId")
 	}
 
-	req := commands.OrientationSetRequest{
-		DeviceID:    orientationSetParams.DeviceID,
-		Orientation: orientationSetParams.Orientation,
+	var labelsParams DeviceLabelsParams
+	if err := json.Unmarshal(params, &labelsParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, set (optional), unset (optional)", err)
 	}
 
-	response := commands.OrientationSetCommand(req)
+	response := commands.DeviceLabelsCommand(commands.DeviceLabelsRequest{
+		DeviceID: labelsParams.DeviceID,
+		Set:      labelsParams.Set,
+		Unset:    labelsParams.Unset,
+	})
 	if response.Status == "error" {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
 
-	return okResponse, nil
+	return response.Data, nil
 }
 
-func handleSettingsApply(params json.RawMessage) (any, error) {
-	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId")
+type NotificationsListParams struct {
+	DeviceID string `json:"deviceId"`
+	Clear    bool   `json:"clear,omitempty"`
+}
+
+func handleNotificationsList(params json.RawMessage) (any, error) {
+	var listParams NotificationsListParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &listParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional), clear (optional)", err)
+		}
 	}
 
-	var settingsParams DeviceSettingsApplyParams
-	if err := json.Unmarshal(params, &settingsParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, animations", err)
+	response := commands.NotificationsCommand(commands.NotificationsRequest{
+		DeviceID: listParams.DeviceID,
+		Clear:    listParams.Clear,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
 	}
 
-	req := commands.ApplySettingsRequest{
-		DeviceID:   settingsParams.DeviceID,
-		Animations: settingsParams.Animations,
+	return response.Data, nil
+}
+
+type NotificationsClearParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+func handleNotificationsClear(params json.RawMessage) (any, error) {
+	var clearParams NotificationsClearParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &clearParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
 	}
 
-	response := commands.ApplySettingsCommand(req)
+	response := commands.NotificationClearCommand(commands.NotificationClearRequest{
+		DeviceID: clearParams.DeviceID,
+	})
 	if response.Status == "error" {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
@@ -844,21 +2066,25 @@ func handleSettingsApply(params json.RawMessage) (any, error) {
 	return okResponse, nil
 }
 
-func handleDeviceBoot(params json.RawMessage) (any, error) {
-	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId")
-	}
+type NotificationsTapParams struct {
+	DeviceID string `json:"deviceId"`
+	Text     string `json:"text"`
+}
 
-	var bootParams DeviceBootParams
-	if err := json.Unmarshal(params, &bootParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId", err)
+func handleNotificationsTap(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, text")
 	}
 
-	req := commands.BootRequest{
-		DeviceID: bootParams.DeviceID,
+	var tapParams NotificationsTapParams
+	if err := json.Unmarshal(params, &tapParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, text", err)
 	}
 
-	response := commands.BootCommand(req)
+	response := commands.NotificationTapCommand(commands.NotificationTapRequest{
+		DeviceID: tapParams.DeviceID,
+		Text:     tapParams.Text,
+	})
 	if response.Status == "error" {
 		return nil, fmt.Errorf("%s", response.Error)
 	}
@@ -917,12 +2143,19 @@ func handleDumpUI(params json.RawMessage) (any, error) {
 
 	var dumpUIParams DumpUIParams
 	if err := json.Unmarshal(params, &dumpUIParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, format (optional)", err)
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, format (optional), query (optional), maxDepth (optional), maxElements (optional), viewportOnly (optional)", err)
 	}
 
 	req := commands.DumpUIRequest{
-		DeviceID: dumpUIParams.DeviceID,
-		Format:   dumpUIParams.Format,
+		DeviceID:     dumpUIParams.DeviceID,
+		Format:       dumpUIParams.Format,
+		Query:        dumpUIParams.Query,
+		MaxDepth:     dumpUIParams.MaxDepth,
+		MaxElements:  dumpUIParams.MaxElements,
+		ViewportOnly: dumpUIParams.ViewportOnly,
+		Output:       "-", // always return the dump for server
+
+		HandleDialogs: dumpUIParams.HandleDialogs,
 	}
 
 	response := commands.DumpUICommand(req)
@@ -948,6 +2181,8 @@ func handleAppsLaunch(params json.RawMessage) (any, error) {
 		BundleID: appsLaunchParams.BundleID,
 		Locales:  appsLaunchParams.Locales,
 		Activity: appsLaunchParams.Activity,
+		Wait:     appsLaunchParams.Wait,
+		User:     appsLaunchParams.User,
 	}
 
 	response := commands.LaunchAppCommand(req)
@@ -965,7 +2200,23 @@ func handleAppsTerminate(params json.RawMessage) (any, error) {
 
 	var appsTerminateParams AppsTerminateParams
 	if err := json.Unmarshal(params, &appsTerminateParams); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, bundleId", err)
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, bundleId or all, exclude (optional)", err)
+	}
+
+	if appsTerminateParams.All {
+		if appsTerminateParams.BundleID != "" {
+			return nil, fmt.Errorf("bundleId and all cannot be used together")
+		}
+
+		response := commands.TerminateAllAppsCommand(commands.TerminateAllAppsRequest{
+			DeviceID: appsTerminateParams.DeviceID,
+			Exclude:  appsTerminateParams.Exclude,
+		})
+		if response.Status == "error" {
+			return nil, fmt.Errorf("%s", response.Error)
+		}
+
+		return response.Data, nil
 	}
 
 	req := commands.AppRequest{
@@ -981,6 +2232,27 @@ func handleAppsTerminate(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
+func handleAppsState(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, bundleId")
+	}
+
+	var appsStateParams AppsStateParams
+	if err := json.Unmarshal(params, &appsStateParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, bundleId", err)
+	}
+
+	response := commands.AppStateCommand(commands.AppStateRequest{
+		DeviceID: appsStateParams.DeviceID,
+		BundleID: appsStateParams.BundleID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
 func handleAppsList(params json.RawMessage) (any, error) {
 	var appsListParams AppsListParams
 	if len(params) > 0 {
@@ -991,6 +2263,7 @@ func handleAppsList(params json.RawMessage) (any, error) {
 
 	req := commands.ListAppsRequest{
 		DeviceID: appsListParams.DeviceID,
+		User:     appsListParams.User,
 	}
 
 	response := commands.ListAppsCommand(req)
@@ -1021,26 +2294,101 @@ func handleAppsForeground(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
-func handleAppsInstall(params json.RawMessage) (any, error) {
+func handleAppsRunning(params json.RawMessage) (any, error) {
+	var appsRunningParams AppsRunningParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &appsRunningParams); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId (optional)", err)
+		}
+	}
+
+	req := commands.RunningAppsRequest{
+		DeviceID: appsRunningParams.DeviceID,
+	}
+
+	response := commands.RunningAppsCommand(req)
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+func handleAppsCrashes(params json.RawMessage) (any, error) {
 	if len(params) == 0 {
-		return nil, fmt.Errorf("'params' is required with fields: deviceId, path")
+		return nil, fmt.Errorf("'params' is required with fields: deviceId, bundleId")
+	}
+
+	var appsCrashesParams AppsCrashesParams
+	if err := json.Unmarshal(params, &appsCrashesParams); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, bundleId", err)
+	}
+
+	response := commands.AppCrashesCommand(commands.AppCrashesRequest{
+		DeviceID: appsCrashesParams.DeviceID,
+		BundleID: appsCrashesParams.BundleID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+
+	return response.Data, nil
+}
+
+// installWriteTimeout bounds how long an install request may take to answer
+const installWriteTimeout = 30 * time.Minute
+
+func parseAppsInstallParams(params json.RawMessage) (commands.InstallAppRequest, error) {
+	if len(params) == 0 {
+		return commands.InstallAppRequest{}, fmt.Errorf("'params' is required with fields: deviceId, path, url or artifactId")
 	}
 
 	var p AppsInstallParams
 	if err := json.Unmarshal(params, &p); err != nil {
-		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, path", err)
+		return commands.InstallAppRequest{}, fmt.Errorf("invalid parameters: %w. Expected fields: deviceId, path, url or artifactId, sha256 (optional), progress (optional)", err)
 	}
 
 	if p.DeviceID == "" {
-		return nil, fmt.Errorf("'deviceId' is required")
+		return commands.InstallAppRequest{}, fmt.Errorf("'deviceId' is required")
+	}
+
+	// an app uploaded to POST /upload is installed from the server's copy
+	if p.ArtifactID != "" {
+		if p.Path != "" || p.URL != "" {
+			return commands.InstallAppRequest{}, fmt.Errorf("'artifactId' cannot be used together with 'path' or 'url'")
+		}
+
+		path, err := uploads.path(p.ArtifactID)
+		if err != nil {
+			return commands.InstallAppRequest{}, err
+		}
+		p.Path = path
 	}
 
-	req := commands.InstallAppRequest{
+	return commands.InstallAppRequest{
 		DeviceID:            p.DeviceID,
 		Path:                p.Path,
+		URL:                 p.URL,
+		SHA256:              p.SHA256,
 		ForceResign:         p.ForceResign,
 		ProvisioningProfile: p.ProvisioningProfile,
 		SigningIdentity:     p.SigningIdentity,
+		GrantPermissions:    p.GrantPermissions,
+		AllowTest:           p.AllowTest,
+		Instant:             p.Instant,
+		AllowDowngrade:      p.AllowDowngrade,
+		User:                p.User,
+		Keystore:            p.Keystore,
+		KeystorePassword:    p.KeystorePassword,
+		KeyAlias:            p.KeyAlias,
+		KeyPassword:         p.KeyPassword,
+	}, nil
+}
+
+func handleAppsInstall(params json.RawMessage) (any, error) {
+	req, err := parseAppsInstallParams(params)
+	if err != nil {
+		return nil, err
 	}
 
 	response := commands.InstallAppCommand(req)
@@ -1051,6 +2399,63 @@ func handleAppsInstall(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
+// handleAppsInstallWithProgress streams install progress as newline-delimited
+// JSON-RPC notifications, followed by the final response
+func handleAppsInstallWithProgress(w http.ResponseWriter, id any, params json.RawMessage) {
+	req, err := parseAppsInstallParams(params)
+	if err != nil {
+		sendJSONRPCError(w, id, ErrCodeServerError, "Server error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+
+	req.OnProgress = func(progress devices.InstallProgress) {
+		_ = encoder.Encode(map[string]any{
+			"jsonrpc": "2.0",
+			"method":  "notification/installProgress",
+			"params":  progress,
+		})
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+	}
+
+	response := commands.InstallAppCommand(req)
+	if response.Status == "error" {
+		sendJSONRPCError(w, id, ErrCodeServerError, "Server error", response.Error)
+		return
+	}
+
+	sendJSONRPCResponse(w, id, response.Data)
+}
+
+func handleAppsVerify(params json.RawMessage) (any, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("'params' is required with fields: path")
+	}
+
+	var p AppsVerifyParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w. Expected fields: path, deviceId", err)
+	}
+
+	response := commands.AppVerifyCommand(commands.AppVerifyRequest{
+		DeviceID: p.DeviceID,
+		Path:     p.Path,
+	})
+
+	// an incompatible app is still a result, with compatible set to false
+	if result, ok := response.Data.(commands.AppVerifyResult); ok {
+		return result, nil
+	}
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
 func handleAppsUninstall(params json.RawMessage) (any, error) {
 	if len(params) == 0 {
 		return nil, fmt.Errorf("'params' is required with fields: deviceId, bundleId")
@@ -1072,6 +2477,7 @@ func handleAppsUninstall(params json.RawMessage) (any, error) {
 	req := commands.UninstallAppRequest{
 		DeviceID:    p.DeviceID,
 		PackageName: p.BundleID,
+		User:        p.User,
 	}
 
 	response := commands.UninstallAppCommand(req)
@@ -1200,9 +2606,10 @@ func handleCrashesGet(params json.RawMessage) (any, error) {
 }
 
 func handleServerInfo(params json.RawMessage) (any, error) {
-	return map[string]string{
-		"name":    "mobilecli",
-		"version": Version,
+	return map[string]any{
+		"name":             "mobilecli",
+		"version":          Version,
+		"agentConnections": wda.Pool.Stats(),
 	}, nil
 }
 
@@ -1251,6 +2658,31 @@ func newJsonRpcNotification(message string) map[string]any {
 	}
 }
 
+// mjpegProgressPart wraps a progress notification as a part of the MJPEG
+// multipart stream
+func mjpegProgressPart(message string) ([]byte, error) {
+	return mjpegNotificationPart(newJsonRpcNotification(message))
+}
+
+// mjpegThermalPart wraps a thermal guard event as a notification/thermal
+// notification in a multipart part
+func mjpegThermalPart(event devices.ThermalEvent) ([]byte, error) {
+	return mjpegNotificationPart(map[string]any{
+		"jsonrpc": "2.0",
+		"method":  "notification/thermal",
+		"params":  event,
+	})
+}
+
+// mjpegNotificationPart wraps a JSON-RPC notification in a multipart part
+func mjpegNotificationPart(notification map[string]any) ([]byte, error) {
+	statusJSON, err := json.Marshal(notification)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, "--BoundaryString\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s\r\n", len(statusJSON), statusJSON), nil
+}
+
 // handleScreenCaptureSession creates a streaming session and returns sessionUrl
 func handleScreenCaptureSession(params json.RawMessage) (any, error) {
 	var screenCaptureParams commands.ScreenCaptureRequest
@@ -1268,6 +2700,15 @@ func handleScreenCaptureSession(params json.RawMessage) (any, error) {
 		return nil, fmt.Errorf("format must be 'mjpeg' or 'avc' for screen capture")
 	}
 
+	if err := commands.ValidateScreenCaptureOptions(screenCaptureParams.Quality, screenCaptureParams.Scale, screenCaptureParams.FPS); err != nil {
+		return nil, err
+	}
+
+	thermal, err := commands.NewThermalGuard(screenCaptureParams.ThermalGuard, screenCaptureParams.MaxTemperature, screenCaptureParams.ThermalInterval, screenCaptureParams.PauseWhenHot)
+	if err != nil {
+		return nil, err
+	}
+
 	// validate device exists (early error detection)
 	targetDevice, err := commands.FindDeviceOrAutoSelect(screenCaptureParams.DeviceID)
 	if err != nil {
@@ -1313,6 +2754,8 @@ func handleScreenCaptureSession(params json.RawMessage) (any, error) {
 		Format:    screenCaptureParams.Format,
 		Quality:   quality,
 		Scale:     scale,
+		FPS:       screenCaptureParams.FPS,
+		Thermal:   thermal,
 		CreatedAt: time.Now(),
 		ExpiresAt: time.Now().Add(1 * time.Minute),
 		InUse:     false,
@@ -1412,8 +2855,8 @@ func handleStream(w http.ResponseWriter, r *http.Request) {
 	// ensure cleanup on exit
 	defer sessionManager.RemoveSession(sessionID)
 
-	// set extended write deadline for long-running stream
-	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(10 * time.Minute))
+	// every write of the stream gets a fresh deadline
+	extendWriteDeadline(w)
 
 	// find device
 	targetDevice, err := commands.FindDeviceOrAutoSelect(session.DeviceID)
@@ -1437,14 +2880,13 @@ func handleStream(w http.ResponseWriter, r *http.Request) {
 	var progressCallback func(string)
 	if session.Format == "mjpeg" {
 		progressCallback = func(message string) {
-			notification := newJsonRpcNotification(message)
-			statusJSON, err := json.Marshal(notification)
+			mimeMessage, err := mjpegProgressPart(message)
 			if err != nil {
 				log.Printf("Failed to marshal progress message: %v", err)
 				return
 			}
-			mimeMessage := fmt.Sprintf("--BoundaryString\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s\r\n", len(statusJSON), statusJSON)
-			_, _ = w.Write([]byte(mimeMessage))
+			extendWriteDeadline(w)
+			_, _ = w.Write(mimeMessage)
 			if flusher, ok := w.(http.Flusher); ok {
 				flusher.Flush()
 			}
@@ -1457,30 +2899,23 @@ func handleStream(w http.ResponseWriter, r *http.Request) {
 		Hook:       commands.GetShutdownHook(),
 	})
 	if err != nil {
+		extendWriteDeadline(w)
 		http.Error(w, fmt.Sprintf("Error starting agent: %v", err), http.StatusInternalServerError)
 		return
 	}
 
-	// start screen capture and stream
-	err = targetDevice.StartScreenCapture(devices.ScreenCaptureConfig{
+	// mjpeg viewers share one capture per device, an avc stream has the
+	// device to itself; either ends as soon as the client disconnects
+	stream := &CaptureStream{
+		DeviceID:   targetDevice.ID(),
 		Format:     session.Format,
 		Quality:    session.Quality,
 		Scale:      session.Scale,
-		OnProgress: progressCallback,
-		OnData: func(data []byte) bool {
-			_, writeErr := w.Write(data)
-			if writeErr != nil {
-				fmt.Println("Error writing data:", writeErr)
-				return false
-			}
-
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
-
-			return true
-		},
-	})
+		FPS:        session.FPS,
+		Thermal:    session.Thermal,
+		RemoteAddr: r.RemoteAddr,
+	}
+	err = serveCaptureStream(r.Context(), w, targetDevice, stream, progressCallback)
 
 	if err != nil {
 		// can't send HTTP error after streaming started, just log
@@ -1492,8 +2927,8 @@ func handleStream(w http.ResponseWriter, r *http.Request) {
 }
 
 func handleScreenCapture(r *http.Request, w http.ResponseWriter, params json.RawMessage) error {
-
-	_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(10 * time.Minute))
+	// every write of the stream gets a fresh deadline
+	extendWriteDeadline(w)
 
 	var screenCaptureParams commands.ScreenCaptureRequest
 	if err := json.Unmarshal(params, &screenCaptureParams); err != nil {
@@ -1516,6 +2951,15 @@ func handleScreenCapture(r *http.Request, w http.ResponseWriter, params json.Raw
 		return fmt.Errorf("format must be 'mjpeg' or 'avc' for screen capture")
 	}
 
+	if err := commands.ValidateScreenCaptureOptions(screenCaptureParams.Quality, screenCaptureParams.Scale, screenCaptureParams.FPS); err != nil {
+		return err
+	}
+
+	thermal, err := commands.NewThermalGuard(screenCaptureParams.ThermalGuard, screenCaptureParams.MaxTemperature, screenCaptureParams.ThermalInterval, screenCaptureParams.PauseWhenHot)
+	if err != nil {
+		return err
+	}
+
 	// avc format is supported on Android and iOS real devices (not simulators)
 	if screenCaptureParams.Format == "avc" {
 		if targetDevice.Platform() == "ios" && targetDevice.DeviceType() == "simulator" {
@@ -1550,14 +2994,13 @@ func handleScreenCapture(r *http.Request, w http.ResponseWriter, params json.Raw
 	var progressCallback func(string)
 	if screenCaptureParams.Format == "mjpeg" {
 		progressCallback = func(message string) {
-			notification := newJsonRpcNotification(message)
-			statusJSON, err := json.Marshal(notification)
+			mimeMessage, err := mjpegProgressPart(message)
 			if err != nil {
 				log.Printf("Failed to marshal progress message: %v", err)
 				return
 			}
-			mimeMessage := fmt.Sprintf("--BoundaryString\r\nContent-Type: application/json\r\nContent-Length: %d\r\n\r\n%s\r\n", len(statusJSON), statusJSON)
-			_, _ = w.Write([]byte(mimeMessage))
+			extendWriteDeadline(w)
+			_, _ = w.Write(mimeMessage)
 			if flusher, ok := w.(http.Flusher); ok {
 				flusher.Flush()
 			}
@@ -1572,26 +3015,18 @@ func handleScreenCapture(r *http.Request, w http.ResponseWriter, params json.Raw
 		return fmt.Errorf("error starting agent: %w", err)
 	}
 
-	// start screen capture and stream to the response writer
-	err = targetDevice.StartScreenCapture(devices.ScreenCaptureConfig{
+	// mjpeg viewers share one capture per device, an avc stream has the
+	// device to itself; either ends as soon as the client disconnects
+	stream := &CaptureStream{
+		DeviceID:   targetDevice.ID(),
 		Format:     screenCaptureParams.Format,
 		Quality:    quality,
 		Scale:      scale,
-		OnProgress: progressCallback,
-		OnData: func(data []byte) bool {
-			_, writeErr := w.Write(data)
-			if writeErr != nil {
-				fmt.Println("Error writing data:", writeErr)
-				return false
-			}
-
-			if flusher, ok := w.(http.Flusher); ok {
-				flusher.Flush()
-			}
-
-			return true
-		},
-	})
+		FPS:        screenCaptureParams.FPS,
+		Thermal:    thermal,
+		RemoteAddr: r.RemoteAddr,
+	}
+	err = serveCaptureStream(r.Context(), w, targetDevice, stream, progressCallback)
 
 	if err != nil {
 		return fmt.Errorf("error starting screen capture: %w", err)
@@ -1657,6 +3092,25 @@ func handleAppsPath(params json.RawMessage) (any, error) {
 	return response.Data, nil
 }
 
+func handleAppsContainer(params json.RawMessage) (any, error) {
+	var p AppsPathParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if p.BundleID == "" {
+		return nil, fmt.Errorf("'bundleId' is required")
+	}
+
+	response := commands.AppContainersCommand(commands.AppContainersRequest{
+		DeviceID: p.DeviceID,
+		BundleID: p.BundleID,
+	})
+	if response.Status == "error" {
+		return nil, fmt.Errorf("%s", response.Error)
+	}
+	return response.Data, nil
+}
+
 func handleFsLs(params json.RawMessage) (any, error) {
 	var p FsLsParams
 	if len(params) > 0 {
@@ -0,0 +1,285 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// stdioMaxConcurrentHandlers bounds the requests of a stdio client handled
+// at once, like wsMaxConcurrentHandlers for a WebSocket connection
+const stdioMaxConcurrentHandlers = 4
+
+// stdioMaxMessageSize bounds a single message, as wsMaxMessageSize does
+const stdioMaxMessageSize = wsMaxMessageSize
+
+// stdioConnection is a JSON-RPC client on the other end of stdin and stdout.
+// Each message is either a line of JSON, or framed LSP-style with a
+// Content-Length header; responses are framed the way their request was.
+type stdioConnection struct {
+	reader *bufio.Reader
+
+	// ctx is done once stdin is closed
+	ctx context.Context
+
+	writeMu    sync.Mutex
+	writer     io.Writer
+	handlerSem chan struct{}
+	handlers   sync.WaitGroup
+}
+
+// StartStdioServer serves the JSON-RPC API over in and out until in is
+// closed, server.shutdown is called or a termination signal arrives, so a
+// parent process can embed mobilecli without opening a network port. Logs go
+// to stderr, out only carries JSON-RPC messages.
+func StartStdioServer(in io.Reader, out io.Writer) error {
+	hook := devices.NewShutdownHook()
+	commands.SetShutdownHook(hook)
+
+	// clients don't share a last used device
+	commands.SetLastUsedDeviceEnabled(false)
+
+	sessionManager = &SessionManager{
+		sessions: make(map[string]*StreamSession),
+	}
+
+	shutdownChan = make(chan os.Signal, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn := &stdioConnection{
+		reader:     bufio.NewReaderSize(in, 64*1024),
+		ctx:        ctx,
+		writer:     out,
+		handlerSem: make(chan struct{}, stdioMaxConcurrentHandlers),
+	}
+
+	readDone := make(chan error, 1)
+	go func() {
+		readDone <- conn.readMessages()
+	}()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	utils.Info("Serving JSON-RPC over stdio...")
+
+	var err error
+	select {
+	case err = <-readDone:
+		// finish the requests already read before shutting down, but stop
+		// following operations for a client that's gone
+		cancel()
+		conn.handlers.Wait()
+		if err != nil {
+			err = fmt.Errorf("failed to read from stdin: %w", err)
+		}
+	case sig := <-sigChan:
+		utils.Info("Received signal %v, shutting down gracefully...", sig)
+	case <-shutdownChan:
+		utils.Info("Received shutdown command via JSON-RPC, shutting down gracefully...")
+	}
+
+	if shutdownErr := hook.Shutdown(); shutdownErr != nil {
+		utils.Info("hook shutdown error: %v", shutdownErr)
+	}
+	return err
+}
+
+// readMessages handles messages until stdin is closed, returning nil then
+func (c *stdioConnection) readMessages() error {
+	for {
+		message, framed, err := c.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(message)) == 0 {
+			continue
+		}
+
+		c.handleMessage(message, framed)
+	}
+}
+
+// readMessage reads the next message, reporting whether it was framed with
+// a Content-Length header rather than sent as a line
+func (c *stdioConnection) readMessage() ([]byte, bool, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !strings.HasPrefix(strings.ToLower(string(line)), "content-length:") {
+		return line, false, nil
+	}
+
+	length := -1
+	for {
+		if name, value, ok := strings.Cut(string(line), ":"); ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || length < 0 {
+				return nil, true, fmt.Errorf("invalid Content-Length header: %s", line)
+			}
+		}
+
+		line, err = c.readLine()
+		if err != nil {
+			return nil, true, err
+		}
+		if len(line) == 0 {
+			break // the blank line ending the headers
+		}
+	}
+
+	if length > stdioMaxMessageSize {
+		return nil, true, fmt.Errorf("message of %d bytes exceeds the limit of %d bytes", length, stdioMaxMessageSize)
+	}
+
+	message := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, message); err != nil {
+		return nil, true, err
+	}
+	return message, true, nil
+}
+
+// readLine reads a line without its line ending
+func (c *stdioConnection) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := c.reader.ReadLine()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return line, nil
+			}
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > stdioMaxMessageSize {
+			return nil, fmt.Errorf("line exceeds the limit of %d bytes", stdioMaxMessageSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+func (c *stdioConnection) handleMessage(message []byte, framed bool) {
+	var req JSONRPCRequest
+	if err := json.Unmarshal(message, &req); err != nil {
+		c.sendError(framed, nil, ErrCodeParseError, errTitleParseError, errMsgParseError)
+		return
+	}
+
+	if validationErr := validateJSONRPCRequest(req); validationErr != nil {
+		c.sendError(framed, req.ID, validationErr.code, validationErr.message, validationErr.data)
+		return
+	}
+
+	utils.Info("Stdio Request ID: %v, Method: %s, Params: %s", req.ID, req.Method, string(req.Params))
+
+	handler, exists := GetMethodRegistry()[req.Method]
+	if !exists {
+		c.sendError(framed, req.ID, ErrCodeMethodNotFound, errTitleMethodNotSupp, req.Method+" not found")
+		return
+	}
+
+	// non-blocking acquire; reject immediately when all slots are taken
+	select {
+	case c.handlerSem <- struct{}{}:
+	default:
+		c.sendError(framed, req.ID, ErrCodeServerError, "Server error", "too many concurrent requests")
+		return
+	}
+
+	// stream the changes of an operation until it finishes
+	if req.Method == "operations.subscribe" {
+		handler = func(params json.RawMessage) (any, error) {
+			opParams, err := parseOperationParams(params)
+			if err != nil {
+				return nil, err
+			}
+			return followOperation(c.ctx, opParams.OperationID, func(op Operation) {
+				_ = c.send(framed, newOperationNotification(op))
+			})
+		}
+	}
+
+	c.handlers.Add(1)
+	go func() {
+		defer c.handlers.Done()
+		defer func() { <-c.handlerSem }()
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic in handler %s: %v\n%s", req.Method, r, debug.Stack())
+				c.sendError(framed, req.ID, ErrCodeServerError, "Server error", fmt.Sprintf("panic: %v", r))
+			}
+		}()
+
+		result, err := handler(req.Params)
+		if err != nil {
+			log.Printf("Error executing method %s: %v", req.Method, err)
+			c.sendError(framed, req.ID, ErrCodeServerError, "Server error", err.Error())
+			return
+		}
+
+		_ = c.send(framed, JSONRPCResponse{
+			JSONRPC: jsonRPCVersion,
+			Result:  result,
+			ID:      req.ID,
+		})
+	}()
+}
+
+func (c *stdioConnection) sendError(framed bool, id any, code int, message string, data any) {
+	_ = c.send(framed, JSONRPCResponse{
+		JSONRPC: jsonRPCVersion,
+		Error: map[string]any{
+			"code":    code,
+			"message": message,
+			"data":    data,
+		},
+		ID: id,
+	})
+}
+
+// send writes a message, framed with a Content-Length header or as a line
+func (c *stdioConnection) send(framed bool, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if framed {
+		if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(data)); err != nil {
+			return err
+		}
+		_, err = c.writer.Write(data)
+		return err
+	}
+
+	_, err = c.writer.Write(append(data, '\n'))
+	return err
+}
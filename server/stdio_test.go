@@ -0,0 +1,62 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveStdio runs the stdio server on input until it ends, returning what
+// the server wrote
+func serveStdio(t *testing.T, input string) string {
+	var out bytes.Buffer
+	require.NoError(t, StartStdioServer(strings.NewReader(input), &out))
+	return out.String()
+}
+
+func TestStdio_LineDelimited(t *testing.T) {
+	out := serveStdio(t, `{"jsonrpc":"2.0","id":1,"method":"server.info"}`+"\n")
+
+	var resp JSONRPCResponse
+	require.NoError(t, json.Unmarshal([]byte(out), &resp))
+	assert.Nil(t, resp.Error)
+	assert.Equal(t, "mobilecli", resp.Result.(map[string]any)["name"])
+	assert.True(t, strings.HasSuffix(out, "\n"))
+}
+
+func TestStdio_ContentLengthFraming(t *testing.T) {
+	body := `{"jsonrpc":"2.0","id":"a","method":"server.info"}`
+	out := serveStdio(t, fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body))
+
+	reader := bufio.NewReader(strings.NewReader(out))
+	header, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(header, "Content-Length: "))
+	blank, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "\r\n", blank)
+
+	var resp JSONRPCResponse
+	require.NoError(t, json.NewDecoder(reader).Decode(&resp))
+	assert.Equal(t, "a", resp.ID)
+	assert.Nil(t, resp.Error)
+}
+
+func TestStdio_Errors(t *testing.T) {
+	out := serveStdio(t, "not json\n"+`{"jsonrpc":"2.0","id":2,"method":"no.such.method"}`+"\n")
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+
+	var parseErr, notFound JSONRPCResponse
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &parseErr))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &notFound))
+	assert.Equal(t, float64(ErrCodeParseError), parseErr.Error.(map[string]any)["code"])
+	assert.Equal(t, float64(ErrCodeMethodNotFound), notFound.Error.(map[string]any)["code"])
+}
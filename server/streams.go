@@ -0,0 +1,237 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// streamTakeoverTimeout bounds how long a new stream waits for the device's
+// previous stream to shut down before starting anyway
+const streamTakeoverTimeout = 5 * time.Second
+
+// CaptureStream is a screen capture stream being served to a client
+type CaptureStream struct {
+	ID         string
+	DeviceID   string
+	Format     string
+	Quality    int
+	Scale      float64
+	FPS        int
+	RemoteAddr string
+	StartedAt  time.Time
+
+	// Thermal watches the device's temperature, nil when not asked for
+	Thermal *devices.ThermalGuard
+
+	bytesSent atomic.Int64
+	thermal   atomic.Pointer[devices.ThermalEvent]
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// CaptureStreamInfo is the introspection view of a CaptureStream
+type CaptureStreamInfo struct {
+	ID         string    `json:"id"`
+	DeviceID   string    `json:"deviceId"`
+	Format     string    `json:"format"`
+	Quality    int       `json:"quality,omitempty"`
+	Scale      float64   `json:"scale,omitempty"`
+	FPS        int       `json:"fps,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	BytesSent  int64     `json:"bytesSent"`
+
+	// Thermal is the latest thermal guard event of the stream's device
+	Thermal *devices.ThermalEvent `json:"thermal,omitempty"`
+}
+
+// Sent records bytes written to the client
+func (s *CaptureStream) Sent(n int) {
+	s.bytesSent.Add(int64(n))
+}
+
+func (s *CaptureStream) info() CaptureStreamInfo {
+	return CaptureStreamInfo{
+		ID:         s.ID,
+		DeviceID:   s.DeviceID,
+		Format:     s.Format,
+		Quality:    s.Quality,
+		Scale:      s.Scale,
+		FPS:        s.FPS,
+		RemoteAddr: s.RemoteAddr,
+		StartedAt:  s.StartedAt,
+		BytesSent:  s.bytesSent.Load(),
+		Thermal:    s.thermal.Load(),
+	}
+}
+
+// streamManager tracks the capture streams being served. A device serves
+// either any number of MJPEG viewers, which share one capture, or a single
+// AVC stream.
+type streamManager struct {
+	mu      sync.Mutex
+	streams map[string]*CaptureStream // by stream ID
+}
+
+var captureStreams = &streamManager{streams: make(map[string]*CaptureStream)}
+
+// conflicts reports whether two streams can't be served from the same device at once
+func (s *CaptureStream) conflicts(other *CaptureStream) bool {
+	return s.DeviceID == other.DeviceID && (s.Format != "mjpeg" || other.Format != "mjpeg")
+}
+
+// start registers the stream and returns the context it must be served
+// under. The context ends when parent does (the client disconnected) or when
+// a conflicting newer stream takes the device over, so a reconnecting viewer
+// never waits for a stale stream to fail a write.
+func (sm *streamManager) start(parent context.Context, stream *CaptureStream) context.Context {
+	ctx, cancel := context.WithCancel(parent)
+	stream.ID = uuid.New().String()
+	stream.StartedAt = time.Now()
+	stream.cancel = cancel
+	stream.done = make(chan struct{})
+
+	sm.mu.Lock()
+	var previous []*CaptureStream
+	for _, other := range sm.streams {
+		if stream.conflicts(other) {
+			previous = append(previous, other)
+		}
+	}
+	sm.streams[stream.ID] = stream
+	sm.mu.Unlock()
+
+	for _, other := range previous {
+		utils.Debug(utils.SubsystemServer, "stream %s takes over device %s from stream %s", stream.ID, stream.DeviceID, other.ID)
+		other.cancel()
+
+		select {
+		case <-other.done:
+		case <-time.After(streamTakeoverTimeout):
+			utils.Debug(utils.SubsystemServer, "stream %s did not stop within %v", other.ID, streamTakeoverTimeout)
+		}
+	}
+
+	return ctx
+}
+
+// finish unregisters the stream
+func (sm *streamManager) finish(stream *CaptureStream) {
+	stream.cancel()
+
+	sm.mu.Lock()
+	delete(sm.streams, stream.ID)
+	sm.mu.Unlock()
+
+	close(stream.done)
+}
+
+// recordThermal keeps a thermal guard event on every stream of the device,
+// MJPEG viewers sharing the capture of the stream that asked for the guard
+func (sm *streamManager) recordThermal(deviceID string, event devices.ThermalEvent) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	for _, stream := range sm.streams {
+		if stream.DeviceID == deviceID {
+			stream.thermal.Store(&event)
+		}
+	}
+}
+
+// list returns the active streams ordered by device ID and start time
+func (sm *streamManager) list() []CaptureStreamInfo {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	streams := make([]CaptureStreamInfo, 0, len(sm.streams))
+	for _, stream := range sm.streams {
+		streams = append(streams, stream.info())
+	}
+
+	sort.Slice(streams, func(i, j int) bool {
+		if streams[i].DeviceID != streams[j].DeviceID {
+			return streams[i].DeviceID < streams[j].DeviceID
+		}
+		return streams[i].StartedAt.Before(streams[j].StartedAt)
+	})
+
+	return streams
+}
+
+// serveCaptureStream registers stream and writes the device's screen capture
+// to w until the client disconnects, the stream is taken over or the capture
+// ends. MJPEG viewers of a device share a single capture.
+func serveCaptureStream(parent context.Context, w http.ResponseWriter, device devices.ControllableDevice, stream *CaptureStream, onProgress func(string)) error {
+	ctx := captureStreams.start(parent, stream)
+	defer captureStreams.finish(stream)
+
+	write := func(data []byte) bool {
+		extendWriteDeadline(w)
+		n, err := w.Write(data)
+		stream.Sent(n)
+		if err != nil {
+			fmt.Println("Error writing data:", err)
+			return false
+		}
+
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		return true
+	}
+
+	config := devices.ScreenCaptureConfig{
+		Context:    ctx,
+		Format:     stream.Format,
+		Quality:    stream.Quality,
+		Scale:      stream.Scale,
+		FPS:        stream.FPS,
+		OnProgress: onProgress,
+		OnData:     write,
+	}
+
+	if stream.Thermal != nil {
+		guard := *stream.Thermal
+		guard.OnEvent = func(event devices.ThermalEvent) {
+			captureStreams.recordThermal(stream.DeviceID, event)
+		}
+		config.Thermal = &guard
+	}
+
+	if stream.Format != "mjpeg" {
+		return devices.StartGuardedScreenCapture(device, config)
+	}
+
+	broadcaster, viewer := mjpegBroadcasters.subscribe(device, config)
+	defer mjpegBroadcasters.unsubscribe(broadcaster, viewer)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case frame, ok := <-viewer.frames:
+			if !ok || !write(frame) {
+				return nil
+			}
+		}
+	}
+}
+
+// handleScreenCaptureSessions lists the active screen capture streams
+func handleScreenCaptureSessions(params json.RawMessage) (any, error) {
+	return map[string]any{
+		"streams": captureStreams.list(),
+	}, nil
+}
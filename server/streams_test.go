@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A new AVC stream on a busy device cancels the previous one and waits for it
+// to finish, so a reconnecting viewer takes over without waiting for a failed write.
+func TestStreamManagerTakeover(t *testing.T) {
+	sm := &streamManager{streams: make(map[string]*CaptureStream)}
+
+	first := &CaptureStream{DeviceID: "dev123", Format: "avc"}
+	firstCtx := sm.start(context.Background(), first)
+
+	firstStopped := make(chan struct{})
+	go func() {
+		<-firstCtx.Done()
+		close(firstStopped)
+		sm.finish(first)
+	}()
+
+	second := &CaptureStream{DeviceID: "dev123", Format: "avc"}
+	secondCtx := sm.start(context.Background(), second)
+
+	select {
+	case <-firstStopped:
+	case <-time.After(time.Second):
+		t.Fatal("expected the first stream to be cancelled")
+	}
+
+	if secondCtx.Err() != nil {
+		t.Fatal("expected the second stream to be running")
+	}
+
+	streams := sm.list()
+	if len(streams) != 1 || streams[0].ID != second.ID {
+		t.Fatalf("expected only the second stream to be listed, got %+v", streams)
+	}
+
+	sm.finish(second)
+	if len(sm.list()) != 0 {
+		t.Fatal("expected no streams after finish")
+	}
+}
+
+// Cancelling the client's request context ends the capture context.
+func TestStreamManagerClientDisconnect(t *testing.T) {
+	sm := &streamManager{streams: make(map[string]*CaptureStream)}
+
+	parent, disconnect := context.WithCancel(context.Background())
+	stream := &CaptureStream{DeviceID: "dev123", Format: "avc"}
+	ctx := sm.start(parent, stream)
+	stream.Sent(42)
+
+	if info := sm.list(); len(info) != 1 || info[0].BytesSent != 42 {
+		t.Fatalf("expected one stream with 42 bytes sent, got %+v", info)
+	}
+
+	disconnect()
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the capture context to end when the client disconnects")
+	}
+
+	sm.finish(stream)
+}
+
+// MJPEG viewers of the same device share its capture instead of taking it over.
+func TestStreamManagerSharedMjpeg(t *testing.T) {
+	sm := &streamManager{streams: make(map[string]*CaptureStream)}
+
+	first := &CaptureStream{DeviceID: "dev123", Format: "mjpeg"}
+	firstCtx := sm.start(context.Background(), first)
+	second := &CaptureStream{DeviceID: "dev123", Format: "mjpeg"}
+	secondCtx := sm.start(context.Background(), second)
+
+	if firstCtx.Err() != nil || secondCtx.Err() != nil {
+		t.Fatal("expected both mjpeg viewers to be running")
+	}
+
+	if streams := sm.list(); len(streams) != 2 || streams[0].ID != first.ID || streams[1].ID != second.ID {
+		t.Fatalf("expected both viewers listed in start order, got %+v", streams)
+	}
+
+	sm.finish(first)
+	sm.finish(second)
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// StartUnixServer serves the JSON-RPC API on a Unix domain socket. Unlike the
+// HTTP server it only exposes /rpc, and the socket is only accessible by the
+// current user. A stale socket file left behind by a crashed daemon is removed.
+func StartUnixServer(socketPath string) error {
+	hook := devices.NewShutdownHook()
+	commands.SetShutdownHook(hook)
+
+	sessionManager = &SessionManager{
+		sessions: make(map[string]*StreamSession),
+	}
+
+	shutdownChan = make(chan os.Signal, 1)
+
+	if conn, err := net.Dial("unix", socketPath); err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("daemon is already listening on %s", socketPath)
+	}
+
+	if err := os.Remove(socketPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale socket %s: %w", socketPath, err)
+	}
+
+	listener, err := listenUnixSocket(socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	defer func() { _ = os.Remove(socketPath) }()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", sendBanner)
+	mux.HandleFunc("/rpc", handleJSONRPC)
+
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+		ReadTimeout:       ReadTimeout,
+		IdleTimeout:       IdleTimeout,
+	}
+
+	utils.Info("Starting daemon on unix://%s...", socketPath)
+	return serveUntilShutdown(server, func() error { return server.Serve(listener) }, hook)
+}
@@ -0,0 +1,376 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Upload limits. An upload's body may take as long as it needs to arrive,
+// as long as each read makes progress within uploadReadTimeout. Artifacts
+// are kept on the server's disk, so together they may take at most
+// MaxUploadsTotalSize, in at most MaxUploads artifacts.
+const (
+	MaxUploadSize       = 8 << 30
+	MaxUploadsTotalSize = 32 << 30
+	MaxUploads          = 16
+	uploadReadTimeout   = 30 * time.Second
+
+	// uploadTTL is how long an uploaded artifact is kept after it was last used
+	uploadTTL = time.Hour
+)
+
+var (
+	// errUploadOffset is returned when a chunk doesn't continue where its upload is
+	errUploadOffset = errors.New("offset does not match the uploaded size")
+
+	errUploadTooLarge = fmt.Errorf("upload exceeds the limit of %d bytes", MaxUploadSize)
+
+	// errUploadQuota is returned when the artifacts together would take more
+	// than the store's total size
+	errUploadQuota = errors.New("uploads exceed the server's quota, delete finished uploads first")
+
+	// errTooManyUploads is returned when the store holds its maximum number
+	// of artifacts
+	errTooManyUploads = errors.New("too many uploads, delete finished uploads first")
+)
+
+// UploadedArtifact is a file uploaded to the server, which device.apps.install
+// accepts by its ID
+type UploadedArtifact struct {
+	ID       string `json:"artifactId"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+	SHA256   string `json:"sha256"`
+}
+
+type uploadedArtifact struct {
+	mu       sync.Mutex // serializes the chunks of an upload
+	store    *uploadStore
+	id       string
+	filename string
+	path     string
+	size     int64
+	hash     hash.Hash
+	lastUsed time.Time
+
+	// charged is the size counted against the store's quota, guarded by the
+	// store's mu
+	charged int64
+}
+
+func (a *uploadedArtifact) info() UploadedArtifact {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return UploadedArtifact{
+		ID:       a.id,
+		Filename: a.filename,
+		Size:     a.size,
+		SHA256:   hex.EncodeToString(a.hash.Sum(nil)),
+	}
+}
+
+// uploadStore keeps uploaded artifacts in a temporary directory until they
+// expire, are deleted or the server shuts down
+type uploadStore struct {
+	mu        sync.Mutex
+	dir       string
+	artifacts map[string]*uploadedArtifact
+
+	// maxTotalSize and maxArtifacts bound the artifacts together, used is
+	// their size
+	maxTotalSize int64
+	maxArtifacts int
+	used         int64
+}
+
+var uploads = newUploadStore(MaxUploadsTotalSize, MaxUploads)
+
+func newUploadStore(maxTotalSize int64, maxArtifacts int) *uploadStore {
+	return &uploadStore{
+		artifacts:    make(map[string]*uploadedArtifact),
+		maxTotalSize: maxTotalSize,
+		maxArtifacts: maxArtifacts,
+	}
+}
+
+// create starts an empty artifact named filename
+func (s *uploadStore) create(filename string, now time.Time) (*uploadedArtifact, error) {
+	filename = filepath.Base(filepath.Clean(filename))
+	if filename == "" || filename == "." || filename == string(filepath.Separator) {
+		return nil, fmt.Errorf("'filename' is required, e.g. app.apk")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.pruneLocked(now)
+
+	if len(s.artifacts) >= s.maxArtifacts {
+		return nil, fmt.Errorf("%w: at most %d are kept", errTooManyUploads, s.maxArtifacts)
+	}
+
+	if s.dir == "" {
+		dir, err := os.MkdirTemp("", "mobilecli-uploads-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload directory: %w", err)
+		}
+		s.dir = dir
+	}
+
+	// a directory per artifact keeps the file name, whose extension tells
+	// the installer what kind of app it is
+	id := uuid.New().String()
+	artifactDir := filepath.Join(s.dir, id)
+	if err := os.Mkdir(artifactDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	path := filepath.Join(artifactDir, filename)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create upload file: %w", err)
+	}
+	_ = file.Close()
+
+	artifact := &uploadedArtifact{
+		store:    s,
+		id:       id,
+		filename: filename,
+		path:     path,
+		hash:     sha256.New(),
+		lastUsed: now,
+	}
+	s.artifacts[id] = artifact
+	return artifact, nil
+}
+
+// get returns an artifact, marking it as used
+func (s *uploadStore) get(id string, now time.Time) (*uploadedArtifact, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	artifact, ok := s.artifacts[id]
+	if !ok {
+		return nil, fmt.Errorf("artifact '%s' not found, it may have expired", id)
+	}
+	artifact.lastUsed = now
+	return artifact, nil
+}
+
+// path returns the file of an uploaded artifact
+func (s *uploadStore) path(id string) (string, error) {
+	artifact, err := s.get(id, time.Now())
+	if err != nil {
+		return "", err
+	}
+	return artifact.path, nil
+}
+
+// remove deletes an artifact and its file
+func (s *uploadStore) remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	artifact, ok := s.artifacts[id]
+	if !ok {
+		return fmt.Errorf("artifact '%s' not found", id)
+	}
+	s.deleteLocked(artifact)
+	return os.RemoveAll(filepath.Dir(artifact.path))
+}
+
+// deleteLocked forgets an artifact, releasing its share of the quota
+func (s *uploadStore) deleteLocked(artifact *uploadedArtifact) {
+	delete(s.artifacts, artifact.id)
+	s.used -= artifact.charged
+	artifact.charged = 0
+}
+
+// charge counts n more bytes of an artifact against the quota, failing
+// when they don't fit
+func (s *uploadStore) charge(artifact *uploadedArtifact, n int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.used+n > s.maxTotalSize {
+		return fmt.Errorf("%w: %d of %d bytes are used", errUploadQuota, s.used, s.maxTotalSize)
+	}
+	s.used += n
+	artifact.charged += n
+	return nil
+}
+
+// pruneLocked deletes the artifacts unused for uploadTTL
+func (s *uploadStore) pruneLocked(now time.Time) {
+	for _, artifact := range s.artifacts {
+		if now.Sub(artifact.lastUsed) > uploadTTL {
+			s.deleteLocked(artifact)
+			_ = os.RemoveAll(filepath.Dir(artifact.path))
+		}
+	}
+}
+
+// removeAll deletes every artifact, on shutdown
+func (s *uploadStore) removeAll() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.artifacts = make(map[string]*uploadedArtifact)
+	s.used = 0
+	if s.dir == "" {
+		return nil
+	}
+	dir := s.dir
+	s.dir = ""
+	return os.RemoveAll(dir)
+}
+
+// appendChunk adds body to the end of an artifact. A negative offset appends
+// wherever the upload is, otherwise it must match the uploaded size so a
+// retried chunk isn't appended twice.
+func (a *uploadedArtifact) appendChunk(body io.Reader, offset int64) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if offset >= 0 && offset != a.size {
+		return fmt.Errorf("%w: offset %d, uploaded %d bytes", errUploadOffset, offset, a.size)
+	}
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return fmt.Errorf("failed to open upload file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// one byte over the limit tells a too large upload apart
+	remaining := MaxUploadSize - a.size
+	n, err := io.Copy(io.MultiWriter(quotaWriter{a}, file, a.hash), io.LimitReader(body, remaining+1))
+	a.size += n
+	if errors.Is(err, errUploadQuota) {
+		return fmt.Errorf("%w, resume at offset %d", err, a.size)
+	}
+	if err != nil {
+		return fmt.Errorf("upload interrupted after %d bytes, resume at offset %d: %w", n, a.size, err)
+	}
+	if a.size > MaxUploadSize {
+		return errUploadTooLarge
+	}
+	return nil
+}
+
+// quotaWriter charges what is written to an artifact against its store's
+// quota, failing before a write that doesn't fit
+type quotaWriter struct {
+	artifact *uploadedArtifact
+}
+
+func (w quotaWriter) Write(p []byte) (int, error) {
+	if err := w.artifact.store.charge(w.artifact, int64(len(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// progressDeadlineReader pushes back the read deadline of a request before
+// each read of its body, so only a stalled upload times out
+type progressDeadlineReader struct {
+	body       io.Reader
+	controller *http.ResponseController
+}
+
+func (r *progressDeadlineReader) Read(p []byte) (int, error) {
+	_ = r.controller.SetReadDeadline(time.Now().Add(uploadReadTimeout))
+	return r.body.Read(p)
+}
+
+// mountUploads adds the endpoints uploading files to the server, so apps on a
+// client's machine can be installed through device.apps.install's artifactId:
+//
+//	POST /upload?filename=app.apk    starts an upload with the body as its first chunk
+//	POST /upload/{id}?offset=N       appends the body, at offset N when given
+//	DELETE /upload/{id}              deletes the upload
+func mountUploads(mux *http.ServeMux) {
+	mux.HandleFunc("POST /upload", handleUploadStart)
+	mux.HandleFunc("POST /upload/{id}", handleUploadAppend)
+	mux.HandleFunc("DELETE /upload/{id}", handleUploadDelete)
+}
+
+func handleUploadStart(w http.ResponseWriter, r *http.Request) {
+	artifact, err := uploads.create(r.URL.Query().Get("filename"), time.Now())
+	if errors.Is(err, errTooManyUploads) {
+		sendRESTResult(w, http.StatusTooManyRequests, restError{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		sendRESTResult(w, http.StatusBadRequest, restError{Error: err.Error()})
+		return
+	}
+
+	receiveUploadChunk(w, r, artifact, 0)
+}
+
+func handleUploadAppend(w http.ResponseWriter, r *http.Request) {
+	artifact, err := uploads.get(r.PathValue("id"), time.Now())
+	if err != nil {
+		sendRESTResult(w, http.StatusNotFound, restError{Error: err.Error()})
+		return
+	}
+
+	offset := int64(-1)
+	if value := r.URL.Query().Get("offset"); value != "" {
+		offset, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || offset < 0 {
+			sendRESTResult(w, http.StatusBadRequest, restError{Error: fmt.Sprintf("invalid offset '%s'", value)})
+			return
+		}
+	}
+
+	receiveUploadChunk(w, r, artifact, offset)
+}
+
+// receiveUploadChunk appends a request's body to an artifact and answers with
+// the artifact as uploaded so far
+func receiveUploadChunk(w http.ResponseWriter, r *http.Request, artifact *uploadedArtifact, offset int64) {
+	controller := http.NewResponseController(w)
+	body := &progressDeadlineReader{body: r.Body, controller: controller}
+
+	err := artifact.appendChunk(body, offset)
+
+	// the upload may have taken longer than the server's write timeout
+	extendWriteDeadline(w)
+
+	switch {
+	case errors.Is(err, errUploadOffset):
+		sendRESTResult(w, http.StatusConflict, restError{Error: err.Error()})
+	case errors.Is(err, errUploadQuota):
+		sendRESTResult(w, http.StatusInsufficientStorage, restError{Error: err.Error()})
+	case errors.Is(err, errUploadTooLarge):
+		_ = uploads.remove(artifact.id)
+		sendRESTResult(w, http.StatusRequestEntityTooLarge, restError{Error: err.Error()})
+	case err != nil:
+		sendRESTResult(w, http.StatusBadRequest, restError{Error: err.Error()})
+	default:
+		sendRESTResult(w, http.StatusOK, artifact.info())
+	}
+}
+
+func handleUploadDelete(w http.ResponseWriter, r *http.Request) {
+	if err := uploads.remove(r.PathValue("id")); err != nil {
+		sendRESTResult(w, http.StatusNotFound, restError{Error: err.Error()})
+		return
+	}
+	sendRESTResult(w, http.StatusOK, okResponse)
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChunkedUpload(t *testing.T) {
+	t.Cleanup(func() { _ = uploads.removeAll() })
+
+	mux := http.NewServeMux()
+	mountUploads(mux)
+
+	serve := func(method, target, body string) (*httptest.ResponseRecorder, UploadedArtifact) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		var artifact UploadedArtifact
+		_ = json.Unmarshal(rec.Body.Bytes(), &artifact)
+		return rec, artifact
+	}
+
+	rec, _ := serve(http.MethodPost, "/upload", "no name")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected an upload without a filename to be rejected, got %d", rec.Code)
+	}
+
+	rec, artifact := serve(http.MethodPost, "/upload?filename=../app.apk", "first,")
+	if rec.Code != http.StatusOK || artifact.Size != 6 || artifact.Filename != "app.apk" {
+		t.Fatalf("unexpected start of upload: %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec, _ = serve(http.MethodPost, "/upload/"+artifact.ID+"?offset=2", "again")
+	if rec.Code != http.StatusConflict {
+		t.Errorf("expected a chunk at the wrong offset to conflict, got %d", rec.Code)
+	}
+
+	rec, artifact = serve(http.MethodPost, "/upload/"+artifact.ID+"?offset=6", "second")
+	if rec.Code != http.StatusOK || artifact.Size != 12 {
+		t.Fatalf("unexpected chunk response: %d %s", rec.Code, rec.Body.String())
+	}
+	sum := sha256.Sum256([]byte("first,second"))
+	if artifact.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected sha256 %s", artifact.SHA256)
+	}
+
+	req, err := parseAppsInstallParams(json.RawMessage(`{"deviceId":"emulator-5554","artifactId":"` + artifact.ID + `"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasSuffix(req.Path, "app.apk") {
+		t.Errorf("expected the install to use the uploaded file, got %s", req.Path)
+	}
+	if data, err := os.ReadFile(req.Path); err != nil || string(data) != "first,second" {
+		t.Errorf("unexpected uploaded file: %q, %v", data, err)
+	}
+
+	if _, err := parseAppsInstallParams(json.RawMessage(`{"deviceId":"emulator-5554","artifactId":"` + artifact.ID + `","path":"/tmp/app.apk"}`)); err == nil {
+		t.Error("expected artifactId and path together to be rejected")
+	}
+
+	rec, _ = serve(http.MethodDelete, "/upload/"+artifact.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected the upload to be deleted, got %d", rec.Code)
+	}
+	if _, err := os.Stat(req.Path); !os.IsNotExist(err) {
+		t.Errorf("expected the uploaded file to be removed, got %v", err)
+	}
+	if _, err := parseAppsInstallParams(json.RawMessage(`{"deviceId":"emulator-5554","artifactId":"` + artifact.ID + `"}`)); err == nil {
+		t.Error("expected a deleted artifact to be rejected")
+	}
+}
+
+func TestUploadQuota(t *testing.T) {
+	store := newUploadStore(10, 2)
+	t.Cleanup(func() { _ = store.removeAll() })
+
+	now := time.Now()
+	first, err := store.create("first.apk", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := first.appendChunk(strings.NewReader("123456"), 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	second, err := store.create("second.apk", now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := store.create("third.apk", now); !errors.Is(err, errTooManyUploads) {
+		t.Errorf("expected a third upload to be rejected, got %v", err)
+	}
+
+	if err := second.appendChunk(strings.NewReader("123456"), 0); !errors.Is(err, errUploadQuota) {
+		t.Errorf("expected the quota to be exceeded, got %v", err)
+	}
+	if second.size != 0 {
+		t.Errorf("expected nothing past the quota to be written, got %d bytes", second.size)
+	}
+
+	// deleting an upload frees its share of the quota
+	if err := store.remove(first.id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := second.appendChunk(strings.NewReader("123456"), 0); err != nil {
+		t.Errorf("expected the upload to fit after deleting another, got %v", err)
+	}
+}
@@ -0,0 +1,25 @@
+package server
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// viewerPage mirrors a device screen in the browser, see handleViewer
+//
+//go:embed viewer.html
+var viewerPage []byte
+
+// mountViewer adds the screen mirroring page at /view/{id}
+func mountViewer(mux *http.ServeMux) {
+	mux.HandleFunc("GET /view/{id}", handleViewer)
+}
+
+// handleViewer serves a page that shows the device's live MJPEG stream and
+// forwards clicks, drags and key presses to it as taps, swipes and text. The
+// page drives the device through /rpc and /stream, like any other client.
+func handleViewer(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	_, _ = w.Write(viewerPage)
+}
@@ -0,0 +1,457 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/mobile-next/mobilecli/commands"
+	"github.com/mobile-next/mobilecli/devices"
+	"github.com/mobile-next/mobilecli/utils"
+)
+
+// webElementKey is the W3C WebDriver key that identifies an element reference
+const webElementKey = "element-6066-11e4-a52f-4f1e4a11d9c1"
+
+// webDriverError is a W3C WebDriver error, with the HTTP status it is sent with
+type webDriverError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *webDriverError) Error() string {
+	return e.message
+}
+
+func errInvalidArgument(format string, args ...any) *webDriverError {
+	return &webDriverError{http.StatusBadRequest, "invalid argument", fmt.Sprintf(format, args...)}
+}
+
+func errInvalidSelector(format string, args ...any) *webDriverError {
+	return &webDriverError{http.StatusBadRequest, "invalid selector", fmt.Sprintf(format, args...)}
+}
+
+func errInvalidSession(id string) *webDriverError {
+	return &webDriverError{http.StatusNotFound, "invalid session id", fmt.Sprintf("no active session with id %s", id)}
+}
+
+func errNoSuchElement(format string, args ...any) *webDriverError {
+	return &webDriverError{http.StatusNotFound, "no such element", fmt.Sprintf(format, args...)}
+}
+
+func errSessionNotCreated(err error) *webDriverError {
+	return &webDriverError{http.StatusInternalServerError, "session not created", err.Error()}
+}
+
+func errUnknown(err error) *webDriverError {
+	return &webDriverError{http.StatusInternalServerError, "unknown error", err.Error()}
+}
+
+// webDriverSession is a WebDriver session driving one device. Found elements
+// are remembered by reference so later commands can act on them.
+type webDriverSession struct {
+	id     string
+	device devices.ControllableDevice
+
+	mu       sync.Mutex
+	elements map[string]devices.ScreenElement
+}
+
+func (s *webDriverSession) addElement(element devices.ScreenElement) map[string]string {
+	id := uuid.New().String()
+
+	s.mu.Lock()
+	s.elements[id] = element
+	s.mu.Unlock()
+
+	return map[string]string{webElementKey: id}
+}
+
+func (s *webDriverSession) element(id string) (devices.ScreenElement, *webDriverError) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.elements[id]
+	if !ok {
+		return devices.ScreenElement{}, errNoSuchElement("no element with id %s in this session", id)
+	}
+	return element, nil
+}
+
+// webDriver serves a minimal W3C WebDriver facade over the devices, enough for
+// common clients to create a session, find elements, click, type and take
+// screenshots without an Appium server
+type webDriver struct {
+	mu       sync.Mutex
+	sessions map[string]*webDriverSession
+}
+
+// newWebDriverHandler returns the WebDriver routes, relative to the base path
+func newWebDriverHandler() http.Handler {
+	wd := &webDriver{sessions: make(map[string]*webDriverSession)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", wd.handle(wd.status))
+	mux.HandleFunc("POST /session", wd.handle(wd.newSession))
+	mux.HandleFunc("DELETE /session/{session}", wd.handle(wd.deleteSession))
+	mux.HandleFunc("GET /session/{session}/screenshot", wd.handleSession(wd.screenshot))
+	mux.HandleFunc("GET /session/{session}/source", wd.handleSession(wd.source))
+	mux.HandleFunc("POST /session/{session}/element", wd.handleSession(wd.findElement))
+	mux.HandleFunc("POST /session/{session}/elements", wd.handleSession(wd.findElements))
+	mux.HandleFunc("POST /session/{session}/element/{element}/click", wd.handleSession(wd.click))
+	mux.HandleFunc("POST /session/{session}/element/{element}/value", wd.handleSession(wd.sendKeys))
+	mux.HandleFunc("GET /session/{session}/element/{element}/text", wd.handleSession(wd.elementText))
+	mux.HandleFunc("GET /session/{session}/element/{element}/rect", wd.handleSession(wd.elementRect))
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeWebDriverError(w, &webDriverError{http.StatusNotFound, "unknown command", fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path)})
+	})
+
+	return mux
+}
+
+// mountWebDriver serves the WebDriver routes both at the root, like modern
+// clients expect, and under /wd/hub for clients configured for Appium 1
+func mountWebDriver(mux *http.ServeMux) {
+	wd := newWebDriverHandler()
+	mux.Handle("/status", wd)
+	mux.Handle("/session", wd)
+	mux.Handle("/session/", wd)
+	mux.Handle("/wd/hub/", http.StripPrefix("/wd/hub", wd))
+}
+
+type webDriverHandlerFunc func(r *http.Request) (any, *webDriverError)
+
+type webDriverSessionHandlerFunc func(session *webDriverSession, r *http.Request) (any, *webDriverError)
+
+func (wd *webDriver) handle(fn webDriverHandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value, wdErr := fn(r)
+		if wdErr != nil {
+			utils.Debug(utils.SubsystemServer, "webdriver %s %s: %s", r.Method, r.URL.Path, wdErr.message)
+			writeWebDriverError(w, wdErr)
+			return
+		}
+		writeWebDriverValue(w, value)
+	}
+}
+
+func (wd *webDriver) handleSession(fn webDriverSessionHandlerFunc) http.HandlerFunc {
+	return wd.handle(func(r *http.Request) (any, *webDriverError) {
+		id := r.PathValue("session")
+
+		wd.mu.Lock()
+		session, ok := wd.sessions[id]
+		wd.mu.Unlock()
+		if !ok {
+			return nil, errInvalidSession(id)
+		}
+
+		return fn(session, r)
+	})
+}
+
+func writeWebDriverValue(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(map[string]any{"value": value})
+}
+
+func writeWebDriverError(w http.ResponseWriter, wdErr *webDriverError) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(wdErr.status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"value": map[string]string{
+			"error":      wdErr.code,
+			"message":    wdErr.message,
+			"stacktrace": "",
+		},
+	})
+}
+
+func decodeWebDriverBody(r *http.Request, v any) *webDriverError {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return errInvalidArgument("invalid request body: %v", err)
+	}
+	return nil
+}
+
+func (wd *webDriver) status(r *http.Request) (any, *webDriverError) {
+	return map[string]any{
+		"ready":   true,
+		"message": fmt.Sprintf("mobilecli %s", Version),
+	}, nil
+}
+
+// sessionDeviceID picks the device from the capabilities, accepting the
+// Appium capability names so existing configurations work unchanged
+func sessionDeviceID(capabilities map[string]any) string {
+	for _, key := range []string{"mobilecli:deviceId", "appium:udid", "udid"} {
+		if value, ok := capabilities[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func (wd *webDriver) newSession(r *http.Request) (any, *webDriverError) {
+	var body struct {
+		Capabilities struct {
+			AlwaysMatch map[string]any   `json:"alwaysMatch"`
+			FirstMatch  []map[string]any `json:"firstMatch"`
+		} `json:"capabilities"`
+	}
+	if wdErr := decodeWebDriverBody(r, &body); wdErr != nil {
+		return nil, wdErr
+	}
+
+	capabilities := map[string]any{}
+	for key, value := range body.Capabilities.AlwaysMatch {
+		capabilities[key] = value
+	}
+	if len(body.Capabilities.FirstMatch) > 0 {
+		for key, value := range body.Capabilities.FirstMatch[0] {
+			capabilities[key] = value
+		}
+	}
+
+	device, err := commands.FindDeviceOrAutoSelect(sessionDeviceID(capabilities))
+	if err != nil {
+		return nil, errSessionNotCreated(err)
+	}
+
+	err = device.StartAgent(devices.StartAgentConfig{
+		Hook: commands.GetShutdownHook(),
+	})
+	if err != nil {
+		return nil, errSessionNotCreated(fmt.Errorf("failed to start agent on device %s: %w", device.ID(), err))
+	}
+
+	session := &webDriverSession{
+		id:       uuid.New().String(),
+		device:   device,
+		elements: make(map[string]devices.ScreenElement),
+	}
+
+	wd.mu.Lock()
+	wd.sessions[session.id] = session
+	wd.mu.Unlock()
+
+	utils.Debug(utils.SubsystemServer, "webdriver session %s created for device %s", session.id, device.ID())
+
+	return map[string]any{
+		"sessionId": session.id,
+		"capabilities": map[string]any{
+			"platformName":       device.Platform(),
+			"platformVersion":    device.Version(),
+			"appium:udid":        device.ID(),
+			"appium:deviceName":  device.Name(),
+			"mobilecli:deviceId": device.ID(),
+		},
+	}, nil
+}
+
+func (wd *webDriver) deleteSession(r *http.Request) (any, *webDriverError) {
+	id := r.PathValue("session")
+
+	wd.mu.Lock()
+	_, ok := wd.sessions[id]
+	delete(wd.sessions, id)
+	wd.mu.Unlock()
+
+	if !ok {
+		return nil, errInvalidSession(id)
+	}
+	return nil, nil
+}
+
+func (wd *webDriver) screenshot(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	data, err := session.device.TakeScreenshot()
+	if err != nil {
+		return nil, errUnknown(fmt.Errorf("failed to take screenshot: %w", err))
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func (wd *webDriver) source(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	source, err := session.device.DumpSourceRaw()
+	if err != nil {
+		return nil, errUnknown(fmt.Errorf("failed to dump UI: %w", err))
+	}
+
+	if text, ok := source.(string); ok {
+		return text, nil
+	}
+
+	data, err := json.Marshal(source)
+	if err != nil {
+		return nil, errUnknown(err)
+	}
+	return string(data), nil
+}
+
+// elementLocator returns the match function for a WebDriver locator strategy.
+// Besides the common Appium strategies, "mobilecli selector" takes the
+// attribute=value selectors of the CLI's --element flags.
+func elementLocator(using, value string) (func(devices.ScreenElement) bool, *webDriverError) {
+	is := func(field *string) bool { return field != nil && *field == value }
+	contains := func(field *string) bool { return field != nil && strings.Contains(*field, value) }
+
+	switch using {
+	case "id":
+		return func(e devices.ScreenElement) bool { return is(e.Identifier) }, nil
+	case "accessibility id":
+		return func(e devices.ScreenElement) bool { return is(e.Identifier) || is(e.Label) || is(e.Name) }, nil
+	case "name":
+		return func(e devices.ScreenElement) bool { return is(e.Name) }, nil
+	case "class name", "tag name":
+		return func(e devices.ScreenElement) bool { return e.Type == value }, nil
+	case "link text":
+		return func(e devices.ScreenElement) bool { return is(e.Text) || is(e.Label) }, nil
+	case "partial link text":
+		return func(e devices.ScreenElement) bool { return contains(e.Text) || contains(e.Label) }, nil
+	case "mobilecli selector":
+		selector, err := commands.ParseElementSelector(value)
+		if err != nil {
+			return nil, errInvalidSelector("%v", err)
+		}
+		return selector.Matches, nil
+	}
+
+	return nil, errInvalidSelector("unsupported locator strategy '%s', supported strategies are: id, accessibility id, name, class name, tag name, link text, partial link text, mobilecli selector", using)
+}
+
+// findMatchingElements returns the elements (depth-first, including children) matching match
+func findMatchingElements(elements []devices.ScreenElement, match func(devices.ScreenElement) bool) []devices.ScreenElement {
+	var found []devices.ScreenElement
+	for _, element := range elements {
+		if match(element) {
+			found = append(found, element)
+		}
+		found = append(found, findMatchingElements(element.Children, match)...)
+	}
+	return found
+}
+
+func (wd *webDriver) locate(session *webDriverSession, r *http.Request) ([]devices.ScreenElement, *webDriverError) {
+	var body struct {
+		Using string `json:"using"`
+		Value string `json:"value"`
+	}
+	if wdErr := decodeWebDriverBody(r, &body); wdErr != nil {
+		return nil, wdErr
+	}
+
+	match, wdErr := elementLocator(body.Using, body.Value)
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	elements, err := session.device.DumpSource()
+	if err != nil {
+		return nil, errUnknown(fmt.Errorf("failed to dump UI: %w", err))
+	}
+
+	return findMatchingElements(elements, match), nil
+}
+
+func (wd *webDriver) findElement(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	found, wdErr := wd.locate(session, r)
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	if len(found) == 0 {
+		return nil, errNoSuchElement("no element matches the locator")
+	}
+	return session.addElement(found[0]), nil
+}
+
+func (wd *webDriver) findElements(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	found, wdErr := wd.locate(session, r)
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	refs := make([]map[string]string, 0, len(found))
+	for _, element := range found {
+		refs = append(refs, session.addElement(element))
+	}
+	return refs, nil
+}
+
+func tapElement(device devices.ControllableDevice, element devices.ScreenElement) *webDriverError {
+	x := element.Rect.X + element.Rect.Width/2
+	y := element.Rect.Y + element.Rect.Height/2
+	if err := device.Tap(x, y); err != nil {
+		return errUnknown(fmt.Errorf("failed to tap element: %w", err))
+	}
+	return nil
+}
+
+func (wd *webDriver) click(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	element, wdErr := session.element(r.PathValue("element"))
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	if wdErr := tapElement(session.device, element); wdErr != nil {
+		return nil, wdErr
+	}
+	return nil, nil
+}
+
+// sendKeys focuses the element by tapping it, then types the text
+func (wd *webDriver) sendKeys(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	element, wdErr := session.element(r.PathValue("element"))
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	var body struct {
+		Text  string   `json:"text"`
+		Value []string `json:"value"`
+	}
+	if wdErr := decodeWebDriverBody(r, &body); wdErr != nil {
+		return nil, wdErr
+	}
+
+	text := body.Text
+	if text == "" {
+		text = strings.Join(body.Value, "")
+	}
+
+	if wdErr := tapElement(session.device, element); wdErr != nil {
+		return nil, wdErr
+	}
+
+	if err := session.device.SendKeys(text); err != nil {
+		return nil, errUnknown(fmt.Errorf("failed to send keys: %w", err))
+	}
+	return nil, nil
+}
+
+func (wd *webDriver) elementText(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	element, wdErr := session.element(r.PathValue("element"))
+	if wdErr != nil {
+		return nil, wdErr
+	}
+
+	for _, field := range []*string{element.Text, element.Label, element.Value} {
+		if field != nil {
+			return *field, nil
+		}
+	}
+	return "", nil
+}
+
+func (wd *webDriver) elementRect(session *webDriverSession, r *http.Request) (any, *webDriverError) {
+	element, wdErr := session.element(r.PathValue("element"))
+	if wdErr != nil {
+		return nil, wdErr
+	}
+	return element.Rect, nil
+}
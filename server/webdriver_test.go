@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mobile-next/mobilecli/devices"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestWebDriverElementLocator(t *testing.T) {
+	tree := []devices.ScreenElement{
+		{
+			Type:  "Window",
+			Label: strPtr("Main"),
+			Children: []devices.ScreenElement{
+				{Type: "Button", Identifier: strPtr("loginButton"), Label: strPtr("Log in")},
+				{Type: "Button", Identifier: strPtr("signupButton"), Label: strPtr("Sign up")},
+				{Type: "TextField", Name: strPtr("email"), Text: strPtr("you@example.com")},
+			},
+		},
+	}
+
+	tests := []struct {
+		using string
+		value string
+		want  int
+	}{
+		{"id", "loginButton", 1},
+		{"accessibility id", "Sign up", 1},
+		{"name", "email", 1},
+		{"class name", "Button", 2},
+		{"link text", "Log in", 1},
+		{"partial link text", "example", 1},
+		{"mobilecli selector", "identifier=signupButton", 1},
+		{"id", "missing", 0},
+	}
+
+	for _, tt := range tests {
+		match, wdErr := elementLocator(tt.using, tt.value)
+		if wdErr != nil {
+			t.Fatalf("%s=%s: unexpected error %v", tt.using, tt.value, wdErr)
+		}
+
+		if got := len(findMatchingElements(tree, match)); got != tt.want {
+			t.Errorf("%s=%s: expected %d matches, got %d", tt.using, tt.value, tt.want, got)
+		}
+	}
+
+	if _, wdErr := elementLocator("xpath", "//Button"); wdErr == nil || wdErr.code != "invalid selector" {
+		t.Errorf("expected xpath to be an invalid selector, got %v", wdErr)
+	}
+}
+
+func TestWebDriverRoutes(t *testing.T) {
+	mux := http.NewServeMux()
+	mountWebDriver(mux)
+
+	tests := []struct {
+		method string
+		path   string
+		status int
+		error  string
+	}{
+		{http.MethodGet, "/status", http.StatusOK, ""},
+		{http.MethodGet, "/wd/hub/status", http.StatusOK, ""},
+		{http.MethodGet, "/session/nope/screenshot", http.StatusNotFound, "invalid session id"},
+		{http.MethodDelete, "/wd/hub/session/nope", http.StatusNotFound, "invalid session id"},
+		{http.MethodPost, "/session/nope/actions", http.StatusNotFound, "unknown command"},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if rec.Code != tt.status {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.status, rec.Code)
+			continue
+		}
+
+		var body struct {
+			Value map[string]any `json:"value"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s %s: invalid response body: %v", tt.method, tt.path, err)
+		}
+
+		if got, _ := body.Value["error"].(string); got != tt.error {
+			t.Errorf("%s %s: expected error %q, got %q", tt.method, tt.path, tt.error, got)
+		}
+	}
+}
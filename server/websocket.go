@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -15,6 +16,8 @@ import (
 )
 
 type wsConnection struct {
+	// ctx is done once the connection closes
+	ctx        context.Context
 	conn       *websocket.Conn
 	writeMu    sync.Mutex
 	handlerSem chan struct{}
@@ -72,7 +75,7 @@ func upgradeConnection(w http.ResponseWriter, r *http.Request, upgrader *websock
 func configureConnection(conn *websocket.Conn) {
 	conn.SetReadLimit(wsMaxMessageSize)
 	if err := conn.SetReadDeadline(time.Now().Add(wsPongWait)); err != nil {
-		utils.Verbose("failed to set read deadline: %v", err)
+		utils.Debug(utils.SubsystemServer, "failed to set read deadline: %v", err)
 	}
 	conn.SetPongHandler(func(string) error {
 		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
@@ -93,7 +96,7 @@ func pingLoop(wsConn *wsConnection, done <-chan struct{}) {
 		case <-ticker.C:
 			wsConn.writeMu.Lock()
 			if err := wsConn.conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
-				utils.Verbose("failed to set write deadline: %v", err)
+				utils.Debug(utils.SubsystemServer, "failed to set write deadline: %v", err)
 				wsConn.writeMu.Unlock()
 				return
 			}
@@ -112,7 +115,7 @@ func readMessages(wsConn *wsConnection) {
 	for {
 		messageType, message, err := wsConn.conn.ReadMessage()
 		if err != nil {
-			utils.Verbose("WebSocket connection closed: %v", err)
+			utils.Debug(utils.SubsystemServer, "WebSocket connection closed: %v", err)
 			break
 		}
 
@@ -135,7 +138,10 @@ func NewWebSocketHandler(enableCORS bool) http.HandlerFunc {
 		}
 		defer conn.Close()
 
-		wsConn := &wsConnection{conn: conn, handlerSem: make(chan struct{}, wsMaxConcurrentHandlers)}
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		wsConn := &wsConnection{ctx: ctx, conn: conn, handlerSem: make(chan struct{}, wsMaxConcurrentHandlers)}
 		configureConnection(conn)
 		stopPing := startPingRoutine(wsConn)
 		defer stopPing()
@@ -234,6 +240,13 @@ func handleWSMethodCall(wsConn *wsConnection, req JSONRPCRequest) {
 				wsConn.sendError(req.ID, ErrCodeServerError, "Server error", fmt.Sprintf("panic: %v", r))
 			}
 		}()
+		// stream the changes of an operation until it finishes
+		if req.Method == "operations.subscribe" {
+			handler = func(params json.RawMessage) (any, error) {
+				return wsConn.subscribeOperation(params)
+			}
+		}
+
 		result, err := handler(req.Params)
 		if err != nil {
 			log.Printf("Error executing method %s: %v", req.Method, err)
@@ -245,6 +258,19 @@ func handleWSMethodCall(wsConn *wsConnection, req JSONRPCRequest) {
 	}()
 }
 
+// subscribeOperation sends an operation's changes as notification/operation
+// notifications and returns the finished operation
+func (wsc *wsConnection) subscribeOperation(params json.RawMessage) (any, error) {
+	opParams, err := parseOperationParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return followOperation(wsc.ctx, opParams.OperationID, func(op Operation) {
+		_ = wsc.sendJSON(newOperationNotification(op))
+	})
+}
+
 func (wsc *wsConnection) sendResponse(id any, result any) error {
 	response := JSONRPCResponse{
 		JSONRPC: jsonRPCVersion,
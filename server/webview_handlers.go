@@ -49,6 +49,21 @@ type WebViewWaitForLoadStateParams struct {
 	Timeout   int    `json:"timeout,omitempty"`
 }
 
+type WebViewDevToolsListParams struct {
+	DeviceID string `json:"deviceId"`
+}
+
+type WebViewDumpParams struct {
+	DeviceID string `json:"deviceId"`
+	Target   string `json:"target"`
+}
+
+type WebViewTargetEvaluateParams struct {
+	DeviceID   string `json:"deviceId"`
+	Target     string `json:"target"`
+	Expression string `json:"expression"`
+}
+
 // ─── Shared helpers ───────────────────────────────────────────
 
 func unmarshal[T any](params json.RawMessage) (T, error) {
@@ -249,3 +264,54 @@ func handleWebViewWaitForLoadState(params json.RawMessage) (any, error) {
 		Timeout:   p.Timeout,
 	}))
 }
+
+func handleWebViewDevToolsList(params json.RawMessage) (any, error) {
+	p, err := unmarshal[WebViewDevToolsListParams](params)
+	if err != nil {
+		return nil, err
+	}
+	if p.DeviceID == "" {
+		return nil, fmt.Errorf("deviceId is required")
+	}
+	return resultOf(commands.DevToolsListCommand(commands.DevToolsListRequest{
+		DeviceID: p.DeviceID,
+	}))
+}
+
+func handleWebViewDump(params json.RawMessage) (any, error) {
+	p, err := unmarshal[WebViewDumpParams](params)
+	if err != nil {
+		return nil, err
+	}
+	if p.DeviceID == "" {
+		return nil, fmt.Errorf("deviceId is required")
+	}
+	if p.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+	return resultOf(commands.DevToolsDumpCommand(commands.DevToolsDumpRequest{
+		DeviceID: p.DeviceID,
+		TargetID: p.Target,
+	}))
+}
+
+func handleWebViewTargetEvaluate(params json.RawMessage) (any, error) {
+	p, err := unmarshal[WebViewTargetEvaluateParams](params)
+	if err != nil {
+		return nil, err
+	}
+	if p.DeviceID == "" {
+		return nil, fmt.Errorf("deviceId is required")
+	}
+	if p.Target == "" {
+		return nil, fmt.Errorf("target is required")
+	}
+	if p.Expression == "" {
+		return nil, fmt.Errorf("expression is required")
+	}
+	return resultOf(commands.DevToolsEvaluateCommand(commands.DevToolsEvaluateRequest{
+		DeviceID:   p.DeviceID,
+		TargetID:   p.Target,
+		Expression: p.Expression,
+	}))
+}
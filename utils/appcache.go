@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// appCacheDir returns the directory downloaded apps are cached in
+func appCacheDir() (string, error) {
+	cacheHome, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find cache directory: %w", err)
+	}
+
+	dir := filepath.Join(cacheHome, "mobilecli", "apps")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return dir, nil
+}
+
+// appFileExtension returns the app file extension of the URL's path, which
+// installers need to pick how to install the file
+func appFileExtension(u *url.URL) string {
+	ext := strings.ToLower(path.Ext(u.Path))
+	switch ext {
+	case ".apk", ".apks", ".aab", ".ipa", ".zip":
+		return ext
+	}
+	return ""
+}
+
+// DownloadApp downloads an app to the local app cache and returns its path.
+// Cached apps are named by their SHA-256, so with expectedSHA256 an app that
+// is already cached is reused without downloading it again; otherwise the
+// download is verified against it. An interrupted download resumes where it
+// stopped on the next attempt.
+func DownloadApp(appURL, expectedSHA256 string) (string, error) {
+	u, err := url.Parse(appURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return "", fmt.Errorf("invalid app URL '%s', expected an http(s) URL", appURL)
+	}
+
+	ext := appFileExtension(u)
+	if ext == "" {
+		return "", fmt.Errorf("cannot tell the app type of '%s', expected a URL ending in .apk, .apks, .aab, .ipa or .zip", appURL)
+	}
+
+	dir, err := appCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	expectedSHA256 = strings.ToLower(expectedSHA256)
+	if expectedSHA256 != "" {
+		cached := filepath.Join(dir, expectedSHA256+ext)
+		if _, err := os.Stat(cached); err == nil {
+			Verbose("using cached app %s", cached)
+			return cached, nil
+		}
+	}
+
+	urlHash := sha256.Sum256([]byte(appURL))
+	partPath := filepath.Join(dir, hex.EncodeToString(urlHash[:8])+ext+".part")
+
+	// only one download at a time resumes the partial file of a URL, others
+	// download into files of their own
+	lock, err := lockPartFile(partPath)
+	if err != nil {
+		return "", err
+	}
+	if lock != nil {
+		defer func() {
+			_ = UnlockFile(lock)
+			_ = lock.Close()
+		}()
+	} else {
+		unique, err := os.CreateTemp(dir, hex.EncodeToString(urlHash[:8])+"-*"+ext+".part")
+		if err != nil {
+			return "", fmt.Errorf("failed to create download file: %w", err)
+		}
+		_ = unique.Close()
+		partPath = unique.Name()
+		defer func() { _ = os.Remove(partPath) }()
+	}
+
+	Verbose("downloading app from %s", appURL)
+	if err := DownloadFileResumable(appURL, partPath); err != nil {
+		return "", err
+	}
+
+	actual, err := SHA256File(partPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+
+	if expectedSHA256 != "" && actual != expectedSHA256 {
+		_ = os.Remove(partPath)
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", appURL, expectedSHA256, actual)
+	}
+
+	cached := filepath.Join(dir, actual+ext)
+	if err := os.Rename(partPath, cached); err != nil {
+		return "", fmt.Errorf("failed to move download into cache: %w", err)
+	}
+
+	Verbose("cached app at %s", cached)
+	return cached, nil
+}
+
+// lockPartFile locks the partial download at partPath with a lock file
+// beside it, returning nil if another download holds it
+func lockPartFile(partPath string) (*os.File, error) {
+	// #nosec G304 -- path is built from the cache directory and a hash
+	f, err := os.OpenFile(partPath+".lock", os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open download lock: %w", err)
+	}
+
+	locked, err := TryLockFile(f)
+	if err != nil || !locked {
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock download: %w", err)
+		}
+		return nil, nil
+	}
+	return f, nil
+}
+
+// resumeValidator returns the validator a response's file can be resumed
+// with in If-Range: a strong ETag, or else its Last-Modified date
+func resumeValidator(resp *http.Response) string {
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return resp.Header.Get("Last-Modified")
+}
+
+// DownloadFileResumable downloads downloadURL to localPath, continuing a partial
+// file already at localPath with a range request when the server allows it.
+// The partial file's ETag or Last-Modified date is kept in a .validator file
+// beside it and sent in If-Range, so a file that changed on the server since
+// is downloaded again from the start rather than spliced onto the old one.
+func DownloadFileResumable(downloadURL, localPath string) error {
+	validatorPath := localPath + ".validator"
+
+	var offset int64
+	var validator []byte
+	if info, err := os.Stat(localPath); err == nil {
+		// #nosec G304 -- path is the caller's download path
+		validator, _ = os.ReadFile(validatorPath)
+		if len(validator) > 0 {
+			offset = info.Size()
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %v", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", string(validator))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		Verbose("resuming download at byte %d", offset)
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// the partial file is already complete
+		_ = os.Remove(validatorPath)
+		return nil
+	case resp.StatusCode == http.StatusOK:
+		// a new download, or the file changed since the partial one
+		flags |= os.O_TRUNC
+		_ = os.Remove(validatorPath)
+		if validator := resumeValidator(resp); validator != "" {
+			if err := os.WriteFile(validatorPath, []byte(validator), 0o600); err != nil {
+				return fmt.Errorf("failed to write download validator: %v", err)
+			}
+		}
+	default:
+		return fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	file, err := os.OpenFile(localPath, flags, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		return fmt.Errorf("failed to write file: %v", err)
+	}
+
+	_ = os.Remove(validatorPath)
+	return nil
+}
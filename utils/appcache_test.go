@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadApp_CachesByChecksum(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	content := []byte("not really an apk")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.ServeContent(w, r, "app.apk", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path, err := DownloadApp(server.URL+"/builds/app.apk", checksum)
+	require.NoError(t, err)
+	assert.Equal(t, checksum+".apk", filepath.Base(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+
+	again, err := DownloadApp(server.URL+"/builds/app.apk", checksum)
+	require.NoError(t, err)
+	assert.Equal(t, path, again)
+	assert.Equal(t, int32(1), requests.Load(), "cached app should not be downloaded again")
+}
+
+func TestDownloadApp_ChecksumMismatch(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tampered"))
+	}))
+	defer server.Close()
+
+	_, err := DownloadApp(server.URL+"/app.ipa", "0000")
+	assert.ErrorContains(t, err, "checksum mismatch")
+}
+
+func TestDownloadApp_RejectsUnknownType(t *testing.T) {
+	_, err := DownloadApp("https://example.com/download?id=42", "")
+	assert.ErrorContains(t, err, "cannot tell the app type")
+}
+
+func TestDownloadFileResumable(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file.part")
+	require.NoError(t, os.WriteFile(path, content[:6], 0o644))
+
+	require.NoError(t, DownloadFileResumable(server.URL, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestDownloadFileResumable_ResumesUnchangedFile(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	var ranged atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranged.Store(r.Header.Get("Range") != "")
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file.part")
+	require.NoError(t, os.WriteFile(path, content[:6], 0o644))
+	require.NoError(t, os.WriteFile(path+".validator", []byte(`"v1"`), 0o600))
+
+	require.NoError(t, DownloadFileResumable(server.URL, path))
+
+	assert.True(t, ranged.Load())
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	assert.NoFileExists(t, path+".validator")
+}
+
+func TestDownloadFileResumable_RestartsChangedFile(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v2"`)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file.part")
+	require.NoError(t, os.WriteFile(path, []byte("stale!"), 0o644))
+	require.NoError(t, os.WriteFile(path+".validator", []byte(`"v1"`), 0o600))
+
+	require.NoError(t, DownloadFileResumable(server.URL, path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestLockPartFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.apk.part")
+
+	lock, err := lockPartFile(path)
+	require.NoError(t, err)
+	require.NotNil(t, lock)
+	defer func() { _ = lock.Close() }()
+
+	again, err := lockPartFile(path)
+	require.NoError(t, err)
+	assert.Nil(t, again)
+}
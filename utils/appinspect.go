@@ -0,0 +1,387 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shogo82148/androidbinary/apk"
+	"howett.net/plist"
+)
+
+// AppPackageInfo is what an app file says about where it can be installed,
+// parsed locally without touching any device. MinOSVersion is the Android
+// minSdkVersion or the iOS MinimumOSVersion. ABIs are the Android native
+// library ABIs, empty for apps without native code, or the architectures of
+// the iOS executable.
+type AppPackageInfo struct {
+	AppMetadata
+	Platform     string `json:"platform"`
+	MinOSVersion string `json:"minOsVersion,omitempty"`
+	TargetSDK    int    `json:"targetSdk,omitempty"`
+
+	// SupportedPlatforms are the iOS CFBundleSupportedPlatforms, iPhoneOS for
+	// real devices or iPhoneSimulator for simulators
+	SupportedPlatforms []string `json:"supportedPlatforms,omitempty"`
+
+	ABIs    []string       `json:"abis,omitempty"`
+	Signing AppSigningInfo `json:"signing"`
+}
+
+// AppSigningInfo is how an app file is signed. Schemes are the Android APK
+// signature schemes, v1 (JAR signing) through v3.1. Profile is the iOS
+// provisioning profile embedded in the app.
+type AppSigningInfo struct {
+	Signed  bool                     `json:"signed"`
+	Schemes []string                 `json:"schemes,omitempty"`
+	Profile *ProvisioningProfileInfo `json:"profile,omitempty"`
+}
+
+// ProvisioningProfileInfo is an iOS provisioning profile. A development
+// profile lists the devices it can be installed on, unless it is an
+// enterprise profile that provisions all devices.
+type ProvisioningProfileInfo struct {
+	Name                 string    `json:"name"`
+	TeamID               string    `json:"teamId,omitempty"`
+	TeamName             string    `json:"teamName,omitempty"`
+	ExpirationDate       time.Time `json:"expirationDate"`
+	Development          bool      `json:"development"`
+	ProvisionsAllDevices bool      `json:"provisionsAllDevices,omitempty"`
+	ProvisionedDevices   []string  `json:"provisionedDevices,omitempty"`
+}
+
+// InspectAppPackage parses an app file (.apk, .ipa, .zip, or .app) for its
+// identity, minimum OS version, architectures and signing, dispatching by
+// extension like ParseAppMetadata
+func InspectAppPackage(path string) (*AppPackageInfo, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".apk"):
+		return inspectApk(path)
+	case strings.HasSuffix(lower, ".ipa"), strings.HasSuffix(lower, ".zip"):
+		return inspectIpa(path)
+	case strings.HasSuffix(lower, ".app"):
+		return inspectIOSBundle(func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		})
+	default:
+		return nil, fmt.Errorf("unsupported app file type: %s", path)
+	}
+}
+
+func inspectApk(path string) (*AppPackageInfo, error) {
+	pkg, err := apk.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open apk: %w", err)
+	}
+
+	info := &AppPackageInfo{AppMetadata: *apkMetadata(pkg), Platform: "android"}
+
+	manifest := pkg.Manifest()
+	if minSDK, err := manifest.SDK.Min.Int32(); err == nil && minSDK > 0 {
+		info.MinOSVersion = fmt.Sprint(minSDK)
+	}
+	if targetSDK, err := manifest.SDK.Target.Int32(); err == nil {
+		info.TargetSDK = int(targetSDK)
+	}
+	_ = pkg.Close()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open apk: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat apk: %w", err)
+	}
+
+	reader, err := zip.NewReader(file, stat.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open apk: %w", err)
+	}
+
+	abis := map[string]bool{}
+	for _, entry := range reader.File {
+		parts := strings.Split(entry.Name, "/")
+		if len(parts) == 3 && parts[0] == "lib" && strings.HasSuffix(parts[2], ".so") {
+			abis[parts[1]] = true
+		}
+		if isJarSignature(entry.Name) && !slices.Contains(info.Signing.Schemes, "v1") {
+			info.Signing.Schemes = append(info.Signing.Schemes, "v1")
+		}
+	}
+	for abi := range abis {
+		info.ABIs = append(info.ABIs, abi)
+	}
+	sort.Strings(info.ABIs)
+
+	schemes, err := apkSigningBlockSchemes(file, stat.Size())
+	if err != nil {
+		Verbose("failed to read the APK signing block of %s: %v", path, err)
+	}
+	info.Signing.Schemes = append(info.Signing.Schemes, schemes...)
+	info.Signing.Signed = len(info.Signing.Schemes) > 0
+
+	return info, nil
+}
+
+// isJarSignature reports whether a zip entry is a v1 (JAR) signature block
+func isJarSignature(name string) bool {
+	if !strings.HasPrefix(name, "META-INF/") || strings.Count(name, "/") != 1 {
+		return false
+	}
+	ext := strings.ToUpper(filepath.Ext(name))
+	return ext == ".RSA" || ext == ".DSA" || ext == ".EC"
+}
+
+// apkSigningBlockMagic ends the APK signing block, which sits right before
+// the zip central directory
+const apkSigningBlockMagic = "APK Sig Block 42"
+
+// apkSignatureSchemes names the IDs of signatures in the APK signing block
+var apkSignatureSchemes = map[uint32]string{
+	0x7109871a: "v2",
+	0xf05368c0: "v3",
+	0x1b93ad61: "v3.1",
+}
+
+// apkSigningBlockSchemes returns the signature schemes in an APK's signing
+// block, in the order they appear, or none if it has no signing block
+func apkSigningBlockSchemes(r io.ReaderAt, size int64) ([]string, error) {
+	centralDirectory, err := zipCentralDirectoryOffset(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if centralDirectory < 32 {
+		return nil, nil
+	}
+
+	// the block ends with its size and the magic
+	footer := make([]byte, 24)
+	if _, err := r.ReadAt(footer, centralDirectory-24); err != nil {
+		return nil, err
+	}
+	if string(footer[8:]) != apkSigningBlockMagic {
+		return nil, nil
+	}
+
+	blockSize := int64(binary.LittleEndian.Uint64(footer[:8]))
+	start := centralDirectory - blockSize - 8
+	if blockSize < 24 || start < 0 {
+		return nil, fmt.Errorf("invalid APK signing block size %d", blockSize)
+	}
+
+	// the ID-value pairs are between the leading size and the footer
+	pairs := make([]byte, blockSize-24)
+	if _, err := r.ReadAt(pairs, start+8); err != nil {
+		return nil, err
+	}
+
+	var schemes []string
+	for len(pairs) >= 12 {
+		length := binary.LittleEndian.Uint64(pairs[:8])
+		if length < 4 || length > uint64(len(pairs)-8) {
+			return schemes, fmt.Errorf("invalid APK signing block entry length %d", length)
+		}
+		if scheme, ok := apkSignatureSchemes[binary.LittleEndian.Uint32(pairs[8:12])]; ok {
+			schemes = append(schemes, scheme)
+		}
+		pairs = pairs[8+length:]
+	}
+	return schemes, nil
+}
+
+// zipCentralDirectoryOffset finds the offset of the central directory in the
+// end of central directory record, which ends the file after an optional comment
+func zipCentralDirectoryOffset(r io.ReaderAt, size int64) (int64, error) {
+	const recordSize = 22
+	searchSize := min(size, recordSize+0xffff)
+	tail := make([]byte, searchSize)
+	if _, err := r.ReadAt(tail, size-searchSize); err != nil {
+		return 0, err
+	}
+
+	for i := len(tail) - recordSize; i >= 0; i-- {
+		if binary.LittleEndian.Uint32(tail[i:]) == 0x06054b50 {
+			return int64(binary.LittleEndian.Uint32(tail[i+16:])), nil
+		}
+	}
+	return 0, fmt.Errorf("end of central directory not found")
+}
+
+// inspectIpa inspects the top-level app bundle of an .ipa or simulator .zip
+func inspectIpa(path string) (*AppPackageInfo, error) {
+	reader, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	bundle := ""
+	entries := map[string]*zip.File{}
+	for _, file := range reader.File {
+		entries[file.Name] = file
+		if isAppInfoPlist(file.Name) {
+			bundle = strings.TrimSuffix(file.Name, "Info.plist")
+		}
+	}
+	if bundle == "" {
+		return nil, fmt.Errorf("no app Info.plist found in %s", path)
+	}
+
+	return inspectIOSBundle(func(name string) ([]byte, error) {
+		file, ok := entries[bundle+name]
+		if !ok {
+			return nil, os.ErrNotExist
+		}
+
+		rc, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = rc.Close() }()
+		return io.ReadAll(rc)
+	})
+}
+
+// inspectIOSBundle inspects an app bundle, reading its files by their path
+// within the bundle
+func inspectIOSBundle(readFile func(name string) ([]byte, error)) (*AppPackageInfo, error) {
+	data, err := readFile("Info.plist")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Info.plist: %w", err)
+	}
+
+	// bundleInfo mirrors the Info.plist keys that say where the app runs
+	type bundleInfo struct {
+		CFBundleExecutable         string   `plist:"CFBundleExecutable"`
+		MinimumOSVersion           string   `plist:"MinimumOSVersion"`
+		CFBundleSupportedPlatforms []string `plist:"CFBundleSupportedPlatforms"`
+	}
+
+	var bundle bundleInfo
+	if _, err := plist.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse Info.plist: %w", err)
+	}
+
+	meta, err := decodeInfoPlist(data)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &AppPackageInfo{
+		AppMetadata:        *meta,
+		Platform:           "ios",
+		MinOSVersion:       bundle.MinimumOSVersion,
+		SupportedPlatforms: bundle.CFBundleSupportedPlatforms,
+	}
+
+	if bundle.CFBundleExecutable != "" {
+		executable, err := readFile(bundle.CFBundleExecutable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read executable %s: %w", bundle.CFBundleExecutable, err)
+		}
+
+		info.ABIs, err = machoArchitectures(executable)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse executable %s: %w", bundle.CFBundleExecutable, err)
+		}
+	}
+
+	if _, err := readFile("_CodeSignature/CodeResources"); err == nil {
+		info.Signing.Signed = true
+	}
+
+	if profile, err := readFile("embedded.mobileprovision"); err == nil {
+		info.Signing.Profile, err = parseEmbeddedProfile(profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return info, nil
+}
+
+// machoArchitectures returns the architectures of a thin or universal Mach-O
+// executable, e.g. arm64 and x86_64
+func machoArchitectures(data []byte) ([]string, error) {
+	fat, err := macho.NewFatFile(bytes.NewReader(data))
+	if err == nil {
+		defer func() { _ = fat.Close() }()
+		var archs []string
+		for _, arch := range fat.Arches {
+			archs = append(archs, machoArchName(arch.Cpu, arch.SubCpu))
+		}
+		return archs, nil
+	}
+	if err != macho.ErrNotFat {
+		return nil, err
+	}
+
+	file, err := macho.NewFile(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+	return []string{machoArchName(file.Cpu, file.SubCpu)}, nil
+}
+
+// machoArchName returns the Apple name of a Mach-O CPU type
+func machoArchName(cpu macho.Cpu, subCpu uint32) string {
+	switch cpu {
+	case macho.CpuArm64:
+		// CPU_SUBTYPE_ARM64E, ignoring the capability bits
+		if subCpu&0xff == 2 {
+			return "arm64e"
+		}
+		return "arm64"
+	case macho.CpuAmd64:
+		return "x86_64"
+	case macho.CpuArm:
+		return "armv7"
+	case macho.Cpu386:
+		return "i386"
+	default:
+		return cpu.String()
+	}
+}
+
+// parseEmbeddedProfile reads a provisioning profile's plist out of its
+// signed envelope, which keeps the plist as is, so openssl isn't needed just
+// to read it
+func parseEmbeddedProfile(data []byte) (*ProvisioningProfileInfo, error) {
+	start := bytes.Index(data, []byte("<?xml"))
+	end := bytes.LastIndex(data, []byte("</plist>"))
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("no plist found in embedded.mobileprovision")
+	}
+
+	var profile provisioningProfile
+	if _, err := plist.Unmarshal(data[start:end+len("</plist>")], &profile); err != nil {
+		return nil, fmt.Errorf("failed to parse embedded.mobileprovision: %w", err)
+	}
+
+	info := &ProvisioningProfileInfo{
+		Name:                 profile.Name,
+		TeamName:             profile.TeamName,
+		ExpirationDate:       profile.ExpirationDate,
+		Development:          profile.Entitlements.GetTaskAllow,
+		ProvisionsAllDevices: profile.ProvisionsAllDevices,
+		ProvisionedDevices:   profile.ProvisionedDevices,
+	}
+	if len(profile.TeamIdentifier) > 0 {
+		info.TeamID = profile.TeamIdentifier[0]
+	}
+	return info, nil
+}
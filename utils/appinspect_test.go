@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const inspectInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>CFBundleIdentifier</key>
+	<string>com.mobilenext.playground</string>
+	<key>CFBundleShortVersionString</key>
+	<string>1.4.0</string>
+	<key>CFBundleExecutable</key>
+	<string>Playground</string>
+	<key>MinimumOSVersion</key>
+	<string>16.0</string>
+	<key>CFBundleSupportedPlatforms</key>
+	<array><string>iPhoneOS</string></array>
+</dict>
+</plist>`
+
+// sampleProfile is a provisioning profile's plist inside stand-in envelope bytes
+const sampleProfile = "\x30\x80\x06\x09" + `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Name</key>
+	<string>Playground Development</string>
+	<key>TeamIdentifier</key>
+	<array><string>ABCDE12345</string></array>
+	<key>ProvisionedDevices</key>
+	<array><string>00008110-000A</string></array>
+	<key>Entitlements</key>
+	<dict><key>get-task-allow</key><true/></dict>
+	<key>ExpirationDate</key>
+	<date>2030-01-01T00:00:00Z</date>
+</dict>
+</plist>` + "\x00\x00"
+
+// thinMachO returns the header of a Mach-O executable with no load commands
+func thinMachO(cpu, subCpu uint32) string {
+	header := []uint32{0xfeedfacf, cpu, subCpu, 2, 0, 0, 0, 0}
+	var buf bytes.Buffer
+	_ = binary.Write(&buf, binary.LittleEndian, header)
+	return buf.String()
+}
+
+func TestInspectAppPackageIpa(t *testing.T) {
+	ipa := writeZip(t, map[string]string{
+		"Payload/Playground.app/Info.plist":                   inspectInfoPlist,
+		"Payload/Playground.app/Playground":                   thinMachO(0x0100000c, 2),
+		"Payload/Playground.app/_CodeSignature/CodeResources": "<plist/>",
+		"Payload/Playground.app/embedded.mobileprovision":     sampleProfile,
+	})
+	ipaPath := ipa + ".ipa"
+	require.NoError(t, os.Rename(ipa, ipaPath))
+
+	info, err := InspectAppPackage(ipaPath)
+	require.NoError(t, err)
+	assert.Equal(t, "ios", info.Platform)
+	assert.Equal(t, "com.mobilenext.playground", info.PackageName)
+	assert.Equal(t, "16.0", info.MinOSVersion)
+	assert.Equal(t, []string{"iPhoneOS"}, info.SupportedPlatforms)
+	assert.Equal(t, []string{"arm64e"}, info.ABIs)
+	assert.True(t, info.Signing.Signed)
+
+	require.NotNil(t, info.Signing.Profile)
+	assert.Equal(t, "Playground Development", info.Signing.Profile.Name)
+	assert.Equal(t, "ABCDE12345", info.Signing.Profile.TeamID)
+	assert.True(t, info.Signing.Profile.Development)
+	assert.Equal(t, []string{"00008110-000A"}, info.Signing.Profile.ProvisionedDevices)
+}
+
+func TestInspectAppPackageUnsignedSimulatorZip(t *testing.T) {
+	zipPath := writeZip(t, map[string]string{
+		"Playground.app/Info.plist": inspectInfoPlist,
+		"Playground.app/Playground": thinMachO(0x01000007, 3),
+	})
+
+	info, err := InspectAppPackage(zipPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"x86_64"}, info.ABIs)
+	assert.False(t, info.Signing.Signed)
+	assert.Nil(t, info.Signing.Profile)
+}
+
+func TestInspectAppPackageApk(t *testing.T) {
+	info, err := InspectAppPackage("testdata/sample.apk")
+	require.NoError(t, err)
+	assert.Equal(t, "android", info.Platform)
+	assert.Equal(t, "com.example.helloworld", info.PackageName)
+	assert.Empty(t, info.ABIs)
+	assert.False(t, info.Signing.Signed)
+}
+
+func TestApkSigningBlockSchemes(t *testing.T) {
+	pair := func(id uint32) []byte {
+		b := binary.LittleEndian.AppendUint64(nil, 8)
+		b = binary.LittleEndian.AppendUint32(b, id)
+		return binary.LittleEndian.AppendUint32(b, 0)
+	}
+	pairs := append(pair(0x7109871a), pair(0xf05368c0)...)
+	blockSize := uint64(len(pairs) + 24)
+
+	var apk []byte
+	apk = binary.LittleEndian.AppendUint64(apk, blockSize)
+	apk = append(apk, pairs...)
+	apk = binary.LittleEndian.AppendUint64(apk, blockSize)
+	apk = append(apk, apkSigningBlockMagic...)
+
+	// an empty central directory right after the block
+	eocd := make([]byte, 22)
+	binary.LittleEndian.PutUint32(eocd, 0x06054b50)
+	binary.LittleEndian.PutUint32(eocd[16:], uint32(len(apk)))
+	apk = append(apk, eocd...)
+
+	schemes, err := apkSigningBlockSchemes(bytes.NewReader(apk), int64(len(apk)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2", "v3"}, schemes)
+}
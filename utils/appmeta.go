@@ -48,6 +48,10 @@ func parseApkMetadata(path string) (*AppMetadata, error) {
 	}
 	defer func() { _ = pkg.Close() }()
 
+	return apkMetadata(pkg), nil
+}
+
+func apkMetadata(pkg *apk.Apk) *AppMetadata {
 	meta := &AppMetadata{
 		PackageName: pkg.PackageName(),
 	}
@@ -60,7 +64,26 @@ func parseApkMetadata(path string) (*AppMetadata, error) {
 		meta.VersionCode = strconv.FormatInt(int64(versionCode), 10)
 	}
 
-	return meta, nil
+	return meta
+}
+
+// ParseApkInstrumentation reads the instrumentation a test apk declares, as
+// the package/runner component 'am instrument' takes
+func ParseApkInstrumentation(path string) (string, error) {
+	pkg, err := apk.OpenFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open apk: %w", err)
+	}
+	defer func() { _ = pkg.Close() }()
+
+	runner, err := pkg.Manifest().Instrument.Name.String()
+	if err != nil || runner == "" {
+		return "", fmt.Errorf("%s declares no instrumentation", filepath.Base(path))
+	}
+	if strings.HasPrefix(runner, ".") {
+		runner = pkg.PackageName() + runner
+	}
+	return pkg.PackageName() + "/" + runner, nil
 }
 
 // parseIpaMetadata reads the top-level app's Info.plist from an .ipa or .zip
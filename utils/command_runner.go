@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCommandTimeout bounds how long a one-shot command may run, so a
+	// wedged adb or simctl fails the command instead of hanging it forever.
+	// Commands whose output is streamed to the caller, such as recordings,
+	// instrumentation and log streams, aren't bounded by it.
+	DefaultCommandTimeout = 10 * time.Minute
+
+	// DefaultMaxCommandOutput bounds the output buffered from a command
+	DefaultMaxCommandOutput = 256 * 1024 * 1024
+
+	// commandWaitDelay is how long to wait for a killed command's output
+	// pipes to close, which children it spawned can keep open
+	commandWaitDelay = 2 * time.Second
+)
+
+// ErrOutputLimit is returned when a command writes more output than the
+// runner buffers
+var ErrOutputLimit = errors.New("command output exceeds the limit")
+
+// CommandRunner runs external commands. Every adb, simctl and other tool
+// invocation goes through it, so tests can replace it with SetCommandRunner.
+type CommandRunner interface {
+	CombinedOutput(cmd *exec.Cmd) ([]byte, error)
+	Output(cmd *exec.Cmd) ([]byte, error)
+	Run(cmd *exec.Cmd) error
+	Start(cmd *exec.Cmd) error
+}
+
+// ExecRunner is the CommandRunner that runs commands on this machine
+type ExecRunner struct {
+	Timeout   time.Duration // how long a one-shot command may run; zero means no limit
+	MaxOutput int           // bytes of output buffered; zero means no limit
+	Env       []string      // added to the environment of every command
+}
+
+var (
+	commandRunner CommandRunner = &ExecRunner{
+		Timeout:   DefaultCommandTimeout,
+		MaxOutput: DefaultMaxCommandOutput,
+	}
+
+	// commandTimeouts holds the timeouts set with WithTimeout, until the
+	// command runs
+	commandTimeouts sync.Map
+)
+
+// SetCommandRunner replaces the runner external commands go through and
+// returns the previous one
+func SetCommandRunner(runner CommandRunner) CommandRunner {
+	previous := commandRunner
+	commandRunner = runner
+	return previous
+}
+
+// WithTimeout overrides the runner's timeout for cmd, for commands such as
+// installs that legitimately run long. Zero means no limit.
+func WithTimeout(cmd *exec.Cmd, timeout time.Duration) *exec.Cmd {
+	commandTimeouts.Store(cmd, timeout)
+	return cmd
+}
+
+// WithoutTimeout lets cmd run until it exits, for commands that run as long
+// as the caller wants them to
+func WithoutTimeout(cmd *exec.Cmd) *exec.Cmd {
+	return WithTimeout(cmd, 0)
+}
+
+// timeoutFor returns the timeout set with WithTimeout, or the runner's
+// timeout for a one-shot command
+func (r *ExecRunner) timeoutFor(cmd *exec.Cmd, oneShot bool) time.Duration {
+	if timeout, ok := commandTimeouts.LoadAndDelete(cmd); ok {
+		return timeout.(time.Duration)
+	}
+	if !oneShot {
+		return 0
+	}
+	return r.Timeout
+}
+
+func (r *ExecRunner) prepare(cmd *exec.Cmd) {
+	if len(r.Env) > 0 {
+		if cmd.Env == nil {
+			cmd.Env = os.Environ()
+		}
+		cmd.Env = append(cmd.Env, r.Env...)
+	}
+}
+
+// wait runs a started command to completion, killing it once its timeout passes
+func (r *ExecRunner) wait(cmd *exec.Cmd, timeout time.Duration) error {
+	if timeout <= 0 {
+		return cmd.Wait()
+	}
+
+	timer := time.AfterFunc(timeout, func() { _ = cmd.Process.Kill() })
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return fmt.Errorf("%s timed out after %s", filepath.Base(cmd.Path), timeout)
+	}
+	return err
+}
+
+func (r *ExecRunner) run(cmd *exec.Cmd, timeout time.Duration) error {
+	r.prepare(cmd)
+	if cmd.WaitDelay == 0 {
+		cmd.WaitDelay = commandWaitDelay
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return r.wait(cmd, timeout)
+}
+
+func (r *ExecRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil || cmd.Stderr != nil {
+		return nil, errors.New("exec: Stdout or Stderr already set")
+	}
+
+	timeout := r.timeoutFor(cmd, true)
+	output := &limitedBuffer{limit: r.MaxOutput}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := r.run(cmd, timeout); err != nil {
+		return output.Bytes(), err
+	}
+	return output.Bytes(), output.err()
+}
+
+func (r *ExecRunner) Output(cmd *exec.Cmd) ([]byte, error) {
+	if cmd.Stdout != nil {
+		return nil, errors.New("exec: Stdout already set")
+	}
+
+	timeout := r.timeoutFor(cmd, true)
+	output := &limitedBuffer{limit: r.MaxOutput}
+	cmd.Stdout = output
+
+	// keep stderr on the exit error, as cmd.Output does
+	var stderr *limitedBuffer
+	if cmd.Stderr == nil {
+		stderr = &limitedBuffer{limit: 64 * 1024}
+		cmd.Stderr = stderr
+	}
+
+	err := r.run(cmd, timeout)
+	var exitErr *exec.ExitError
+	if stderr != nil && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+	if err != nil {
+		return output.Bytes(), err
+	}
+	return output.Bytes(), output.err()
+}
+
+// Run runs cmd to completion. A command whose output goes to the caller's
+// writers is streaming and runs until it exits, unless given a timeout with
+// WithTimeout; one with no output attached is one-shot.
+func (r *ExecRunner) Run(cmd *exec.Cmd) error {
+	oneShot := cmd.Stdout == nil && cmd.Stderr == nil
+	return r.run(cmd, r.timeoutFor(cmd, oneShot))
+}
+
+// Start starts cmd without a timeout, since whoever started it owns how
+// long it runs
+func (r *ExecRunner) Start(cmd *exec.Cmd) error {
+	commandTimeouts.Delete(cmd)
+	r.prepare(cmd)
+	return cmd.Start()
+}
+
+// limitedBuffer buffers output up to a limit and discards the rest, so a
+// runaway command can't exhaust memory. The buffer is not embedded, so
+// io.Copy can't bypass the limit through bytes.Buffer's ReadFrom.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.buf.Len()+len(p) > b.limit {
+		b.truncated = true
+		_, _ = b.buf.Write(p[:max(0, b.limit-b.buf.Len())])
+		// report the whole write as consumed so the command isn't killed by
+		// a broken pipe before it exits
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func (b *limitedBuffer) err() error {
+	if b.truncated {
+		return fmt.Errorf("%w of %d bytes", ErrOutputLimit, b.limit)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperCommand runs this test binary as a stand-in external tool, doing what
+// TestHelperProcess is told through its arguments
+func helperCommand(args ...string) *exec.Cmd {
+	cmd := exec.Command(os.Args[0], append([]string{"-test.run=TestHelperProcess", "--"}, args...)...)
+	cmd.Env = append(os.Environ(), "MOBILECLI_HELPER_PROCESS=1")
+	return cmd
+}
+
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("MOBILECLI_HELPER_PROCESS") != "1" {
+		return
+	}
+
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+
+	switch args[1] {
+	case "echo":
+		fmt.Print(strings.Join(args[2:], " "))
+	case "env":
+		fmt.Print(os.Getenv(args[2]))
+	case "sleep":
+		time.Sleep(time.Minute)
+	case "nap":
+		time.Sleep(500 * time.Millisecond)
+		fmt.Print("awake")
+	case "flood":
+		fmt.Print(strings.Repeat("x", 4096))
+	}
+	os.Exit(0)
+}
+
+func TestExecRunnerOutput(t *testing.T) {
+	runner := &ExecRunner{}
+	output, err := runner.CombinedOutput(helperCommand("echo", "hello", "adb"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello adb", string(output))
+}
+
+func TestExecRunnerTimeout(t *testing.T) {
+	runner := &ExecRunner{Timeout: 100 * time.Millisecond}
+
+	start := time.Now()
+	_, err := runner.CombinedOutput(helperCommand("sleep"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+	assert.Less(t, time.Since(start), 10*time.Second)
+}
+
+func TestExecRunnerWithTimeoutOverrides(t *testing.T) {
+	runner := &ExecRunner{Timeout: time.Nanosecond}
+	output, err := runner.Output(WithTimeout(helperCommand("echo", "ok"), 0))
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(output))
+}
+
+func TestExecRunnerStreamingRunHasNoDefaultTimeout(t *testing.T) {
+	runner := &ExecRunner{Timeout: 100 * time.Millisecond}
+
+	var output strings.Builder
+	cmd := helperCommand("nap")
+	cmd.Stdout = &output
+	require.NoError(t, runner.Run(cmd))
+	assert.Equal(t, "awake", output.String())
+
+	err := runner.Run(helperCommand("nap"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out after 100ms")
+}
+
+func TestExecRunnerWithoutTimeout(t *testing.T) {
+	runner := &ExecRunner{Timeout: 100 * time.Millisecond}
+	output, err := runner.CombinedOutput(WithoutTimeout(helperCommand("nap")))
+	require.NoError(t, err)
+	assert.Equal(t, "awake", string(output))
+}
+
+func TestExecRunnerOutputLimit(t *testing.T) {
+	runner := &ExecRunner{MaxOutput: 1024}
+	output, err := runner.Output(helperCommand("flood"))
+	assert.ErrorIs(t, err, ErrOutputLimit)
+	assert.Len(t, output, 1024)
+}
+
+func TestExecRunnerEnv(t *testing.T) {
+	runner := &ExecRunner{Env: []string{"MOBILECLI_INJECTED=yes"}}
+	output, err := runner.Output(helperCommand("env", "MOBILECLI_INJECTED"))
+	require.NoError(t, err)
+	assert.Equal(t, "yes", string(output))
+}
+
+type recordingRunner struct {
+	ExecRunner
+	args [][]string
+}
+
+func (r *recordingRunner) CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	r.args = append(r.args, cmd.Args)
+	return []byte("Success"), nil
+}
+
+func TestSetCommandRunner(t *testing.T) {
+	runner := &recordingRunner{}
+	previous := SetCommandRunner(runner)
+	defer SetCommandRunner(previous)
+
+	output, err := CombinedOutput(exec.Command("adb", "devices"))
+	require.NoError(t, err)
+	assert.Equal(t, "Success", string(output))
+	assert.Equal(t, [][]string{{"adb", "devices"}}, runner.args)
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Config is config.json in mobilecli's configuration directory, the one file
+// settings are read from. Its keys are snake_case, e.g.
+//
+//	{
+//	  "default_device": "emulator-5554",
+//	  "adb_path": "/opt/android-sdk/platform-tools/adb",
+//	  "wda_version": "0.0.20",
+//	  "auth": {"provider": "oidc", "issuer_url": "https://sso.example.com/realms/qa", "client_id": "mobilecli"}
+//	}
+type Config struct {
+	// DefaultDevice is auto-selected whenever it is online and matches the
+	// platform, type and labels asked for
+	DefaultDevice string `json:"default_device,omitempty"`
+
+	// AdbPath, EmulatorPath and XcrunPath override where the tools are
+	// found, taking precedence over ANDROID_HOME and PATH
+	AdbPath      string `json:"adb_path,omitempty"`
+	EmulatorPath string `json:"emulator_path,omitempty"`
+	XcrunPath    string `json:"xcrun_path,omitempty"`
+
+	// WDAVersion and DeviceKitVersion pin the agent versions installed and
+	// started, and AgentChecksums the sha256 of pinned agent downloads by
+	// file name
+	WDAVersion       string            `json:"wda_version,omitempty"`
+	DeviceKitVersion string            `json:"devicekit_version,omitempty"`
+	AgentChecksums   map[string]string `json:"agent_checksums,omitempty"`
+
+	// SimulatorWDA runs simulators' WebDriverAgent from a .xctestrun file or
+	// a WebDriverAgent checkout, MOBILECLI_SIMULATOR_WDA taking precedence
+	SimulatorWDA string `json:"simulator_wda,omitempty"`
+
+	// AndroidTextInput picks how non-ASCII text is typed on Android,
+	// MOBILECLI_ANDROID_TEXT_INPUT taking precedence
+	AndroidTextInput string `json:"android_text_input,omitempty"`
+
+	// Auth configures where 'auth login' gets its token from
+	Auth AuthConfig `json:"auth,omitempty"`
+}
+
+// AuthConfig is the auth section of config.json
+type AuthConfig struct {
+	Provider  string `json:"provider,omitempty"`
+	IssuerURL string `json:"issuer_url,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+
+	// DeviceCodeURL and TokenURL override the endpoints discovered from
+	// IssuerURL, for servers without OpenID Connect discovery
+	DeviceCodeURL string `json:"device_code_url,omitempty"`
+	TokenURL      string `json:"token_url,omitempty"`
+}
+
+// ConfigFilePath returns the path of config.json
+func ConfigFilePath() (string, error) {
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// LoadConfig reads config.json, which is empty when it doesn't exist
+func LoadConfig() (*Config, error) {
+	config := &Config{}
+
+	path, err := ConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return config, nil
+}
@@ -6,8 +6,23 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 )
 
+// ConfigDir returns mobilecli's configuration directory, under
+// $XDG_CONFIG_HOME or ~/.config
+func ConfigDir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "mobilecli"), nil
+}
+
 func SHA256File(path string) (string, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -4,33 +4,96 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// gitHubAPIURL is the GitHub API base URL, overridden in tests
+var gitHubAPIURL = "https://api.github.com"
+
+// gitHubClient bounds release lookups, so an unreachable GitHub doesn't hang
+// commands that only report versions
+var gitHubClient = &http.Client{Timeout: 10 * time.Second}
+
 type GitHubRelease struct {
-	Assets []struct {
+	TagName    string `json:"tag_name"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
 		BrowserDownloadURL string `json:"browser_download_url"`
 		Name               string `json:"name"`
 	} `json:"assets"`
 }
 
-// GetLatestReleaseDownloadURL fetches the latest release from a GitHub repository
-// and returns the browser download URL of the first asset
-func GetLatestReleaseDownloadURL(repo string) (string, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", repo)
+// GetLatestRelease fetches the latest release of a GitHub repository
+func GetLatestRelease(repo string) (*GitHubRelease, error) {
+	return GetLatestReleaseFrom("", repo, false)
+}
+
+// GetLatestReleaseFrom fetches the latest release of a repository from a
+// GitHub-compatible API at apiURL, such as a mirror, or from GitHub when
+// apiURL is empty. With prerelease, the newest release is returned even if
+// it is a prerelease.
+func GetLatestReleaseFrom(apiURL, repo string, prerelease bool) (*GitHubRelease, error) {
+	if apiURL == "" {
+		apiURL = gitHubAPIURL
+	}
+	apiURL = strings.TrimSuffix(apiURL, "/")
 
-	resp, err := http.Get(url)
+	if !prerelease {
+		var release GitHubRelease
+		if err := getGitHubJSON(fmt.Sprintf("%s/repos/%s/releases/latest", apiURL, repo), &release); err != nil {
+			return nil, fmt.Errorf("failed to fetch latest release: %v", err)
+		}
+		return &release, nil
+	}
+
+	// releases are listed newest first
+	var releases []GitHubRelease
+	if err := getGitHubJSON(fmt.Sprintf("%s/repos/%s/releases?per_page=30", apiURL, repo), &releases); err != nil {
+		return nil, fmt.Errorf("failed to fetch releases: %v", err)
+	}
+	for i := range releases {
+		if !releases[i].Draft {
+			return &releases[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no releases found for %s", repo)
+}
+
+// AssetURL returns the download URL of a release's asset
+func (r *GitHubRelease) AssetURL(name string) (string, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset.BrowserDownloadURL, true
+		}
+	}
+	return "", false
+}
+
+func getGitHubJSON(url string, v any) error {
+	resp, err := gitHubClient.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch latest release: %v", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode release JSON: %v", err)
 	}
+	return nil
+}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return "", fmt.Errorf("failed to decode release JSON: %v", err)
+// GetLatestReleaseDownloadURL fetches the latest release from a GitHub repository
+// and returns the browser download URL of the first asset
+func GetLatestReleaseDownloadURL(repo string) (string, error) {
+	release, err := GetLatestRelease(repo)
+	if err != nil {
+		return "", err
 	}
 
 	if len(release.Assets) == 0 {
@@ -39,3 +102,18 @@ func GetLatestReleaseDownloadURL(repo string) (string, error) {
 
 	return release.Assets[0].BrowserDownloadURL, nil
 }
+
+// GetLatestReleaseVersion returns the tag of a GitHub repository's latest
+// release, without a leading "v"
+func GetLatestReleaseVersion(repo string) (string, error) {
+	release, err := GetLatestRelease(repo)
+	if err != nil {
+		return "", err
+	}
+
+	if release.TagName == "" {
+		return "", fmt.Errorf("latest release of %s has no tag", repo)
+	}
+
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
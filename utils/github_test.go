@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func useGitHubAPI(t *testing.T, handler http.HandlerFunc) {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	previous := gitHubAPIURL
+	gitHubAPIURL = srv.URL
+	t.Cleanup(func() { gitHubAPIURL = previous })
+}
+
+func TestGetLatestReleaseVersion(t *testing.T) {
+	useGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/mobile-next/devicekit-android/releases/latest", r.URL.Path)
+		_, _ = w.Write([]byte(`{"tag_name": "v1.3.0", "assets": [{"name": "devicekit.apk", "browser_download_url": "https://example.com/devicekit.apk"}]}`))
+	})
+
+	version, err := GetLatestReleaseVersion("mobile-next/devicekit-android")
+	require.NoError(t, err)
+	assert.Equal(t, "1.3.0", version)
+
+	url, err := GetLatestReleaseDownloadURL("mobile-next/devicekit-android")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/devicekit.apk", url)
+}
+
+func TestGetLatestReleaseVersionError(t *testing.T) {
+	useGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	_, err := GetLatestReleaseVersion("mobile-next/devicekit-ios")
+	assert.Error(t, err)
+}
+
+func TestGetLatestReleaseFromBetaChannel(t *testing.T) {
+	useGitHubAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/repos/mobile-next/mobilecli/releases", r.URL.Path)
+		_, _ = w.Write([]byte(`[
+			{"tag_name": "0.2.0-beta.2", "draft": true},
+			{"tag_name": "0.2.0-beta.1", "prerelease": true, "assets": [{"name": "checksums.txt", "browser_download_url": "https://example.com/checksums.txt"}]},
+			{"tag_name": "0.1.9"}
+		]`))
+	})
+
+	release, err := GetLatestReleaseFrom("", "mobile-next/mobilecli", true)
+	require.NoError(t, err)
+	assert.Equal(t, "0.2.0-beta.1", release.TagName)
+
+	url, ok := release.AssetURL("checksums.txt")
+	assert.True(t, ok)
+	assert.Equal(t, "https://example.com/checksums.txt", url)
+
+	_, ok = release.AssetURL("mobilecli-0.2.0-beta.1-linux-amd64.zip")
+	assert.False(t, ok)
+}
+
+func TestGetLatestReleaseFromMirror(t *testing.T) {
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/repos/mobile-next/mobilecli/releases/latest", r.URL.Path)
+		_, _ = w.Write([]byte(`{"tag_name": "0.1.9"}`))
+	}))
+	defer mirror.Close()
+
+	release, err := GetLatestReleaseFrom(mirror.URL+"/api/v3/", "mobile-next/mobilecli", false)
+	require.NoError(t, err)
+	assert.Equal(t, "0.1.9", release.TagName)
+}
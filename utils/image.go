@@ -2,6 +2,12 @@ package utils
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
 	"image/jpeg"
 	"image/png"
 )
@@ -19,3 +25,128 @@ func ConvertPngToJpeg(pngBytes []byte, quality int) ([]byte, error) {
 
 	return jpegBytes.Bytes(), nil
 }
+
+// CropPng crops a PNG image to the given rectangle (in image pixels) and
+// returns the result re-encoded as PNG. The rectangle is clamped to the
+// image bounds; an error is returned if nothing remains after clamping.
+func CropPng(pngBytes []byte, x, y, width, height int) ([]byte, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid crop size %dx%d", width, height)
+	}
+
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	rect := image.Rect(bounds.Min.X+x, bounds.Min.Y+y, bounds.Min.X+x+width, bounds.Min.Y+y+height).Intersect(bounds)
+	if rect.Empty() {
+		return nil, fmt.Errorf("crop rectangle %d,%d,%d,%d is outside of image bounds %dx%d", x, y, width, height, bounds.Dx(), bounds.Dy())
+	}
+
+	cropped := image.NewRGBA(image.Rect(0, 0, rect.Dx(), rect.Dy()))
+	draw.Draw(cropped, cropped.Bounds(), img, rect.Min, draw.Src)
+
+	var pngOut bytes.Buffer
+	if err := png.Encode(&pngOut, cropped); err != nil {
+		return nil, err
+	}
+
+	return pngOut.Bytes(), nil
+}
+
+// MarkPoint draws a red crosshair inside a ring centered on the given point
+// (in image pixels) and returns the result re-encoded as PNG, e.g. to show
+// where a tap landed. The mark is sized relative to the image.
+func MarkPoint(pngBytes []byte, x, y int) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := img.Bounds()
+	if x < 0 || y < 0 || x >= bounds.Dx() || y >= bounds.Dy() {
+		return nil, fmt.Errorf("point %d,%d is outside of image bounds %dx%d", x, y, bounds.Dx(), bounds.Dy())
+	}
+
+	marked := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(marked, marked.Bounds(), img, bounds.Min, draw.Src)
+
+	radius := max(min(bounds.Dx(), bounds.Dy())/20, 8)
+	thickness := max(radius/8, 1)
+	red := color.RGBA{255, 0, 0, 255}
+
+	// crosshair
+	for d := -radius; d <= radius; d++ {
+		for t := -thickness / 2; t <= thickness/2; t++ {
+			setIn(marked, x+d, y+t, red)
+			setIn(marked, x+t, y+d, red)
+		}
+	}
+
+	// ring
+	outer := radius * radius
+	inner := (radius - thickness) * (radius - thickness)
+	for dy := -radius; dy <= radius; dy++ {
+		for dx := -radius; dx <= radius; dx++ {
+			if d := dx*dx + dy*dy; d <= outer && d > inner {
+				setIn(marked, x+dx, y+dy, red)
+			}
+		}
+	}
+
+	var pngOut bytes.Buffer
+	if err := png.Encode(&pngOut, marked); err != nil {
+		return nil, err
+	}
+
+	return pngOut.Bytes(), nil
+}
+
+// setIn sets a pixel if it falls inside the image
+func setIn(img *image.RGBA, x, y int, c color.Color) {
+	if image.Pt(x, y).In(img.Bounds()) {
+		img.Set(x, y, c)
+	}
+}
+
+// fingerprintWidth is the width, in cells, of the thumbnail PngFingerprint hashes
+const fingerprintWidth = 64
+
+// PngFingerprint hashes a thumbnail of a PNG image, the average color of
+// each cell of a grid fingerprintWidth cells wide, so two screenshots of the
+// same screen have the same fingerprint. Data that isn't a PNG is hashed as is.
+func PngFingerprint(pngBytes []byte) string {
+	img, err := png.Decode(bytes.NewReader(pngBytes))
+	if err != nil {
+		sum := sha256.Sum256(pngBytes)
+		return hex.EncodeToString(sum[:])
+	}
+
+	bounds := img.Bounds()
+	cellSize := max(bounds.Dx()/fingerprintWidth, 1)
+	columns := (bounds.Dx() + cellSize - 1) / cellSize
+	rows := (bounds.Dy() + cellSize - 1) / cellSize
+
+	type cell struct{ r, g, b, n uint64 }
+	cells := make([]cell, columns*rows)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := (y - bounds.Min.Y) / cellSize * columns
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, _ := img.At(x, y).RGBA()
+			c := &cells[row+(x-bounds.Min.X)/cellSize]
+			c.r += uint64(r)
+			c.g += uint64(g)
+			c.b += uint64(b)
+			c.n++
+		}
+	}
+
+	thumbnail := make([]byte, 0, len(cells)*3)
+	for _, c := range cells {
+		thumbnail = append(thumbnail, byte(c.r/c.n>>8), byte(c.g/c.n>>8), byte(c.b/c.n>>8))
+	}
+	sum := sha256.Sum256(thumbnail)
+	return hex.EncodeToString(sum[:])
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"image"
 	"image/color"
+	"image/draw"
 	"image/jpeg"
 	"image/png"
 	"testing"
@@ -61,3 +62,96 @@ func TestConvertPngToJpeg_EmptyData(t *testing.T) {
 		t.Error("Expected error for empty data, got nil")
 	}
 }
+
+func encodeTestPng(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img), "Failed to encode test PNG")
+	return buf.Bytes()
+}
+
+func TestCropPng(t *testing.T) {
+	cropped, err := CropPng(encodeTestPng(t, 32, 32), 4, 8, 10, 6)
+	require.NoError(t, err)
+
+	out, err := png.Decode(bytes.NewReader(cropped))
+	require.NoError(t, err, "Output should be valid PNG")
+
+	assert.Equal(t, 10, out.Bounds().Dx())
+	assert.Equal(t, 6, out.Bounds().Dy())
+
+	// top-left pixel of the crop must come from (4,8) in the source
+	r, g, _, _ := out.At(0, 0).RGBA()
+	assert.Equal(t, uint32(4), r>>8)
+	assert.Equal(t, uint32(8), g>>8)
+}
+
+func TestCropPng_ClampsToBounds(t *testing.T) {
+	cropped, err := CropPng(encodeTestPng(t, 32, 32), 24, 24, 100, 100)
+	require.NoError(t, err)
+
+	out, err := png.Decode(bytes.NewReader(cropped))
+	require.NoError(t, err)
+
+	assert.Equal(t, 8, out.Bounds().Dx())
+	assert.Equal(t, 8, out.Bounds().Dy())
+}
+
+func TestCropPng_OutOfBounds(t *testing.T) {
+	_, err := CropPng(encodeTestPng(t, 32, 32), 40, 40, 10, 10)
+	assert.Error(t, err)
+}
+
+func TestCropPng_InvalidSize(t *testing.T) {
+	_, err := CropPng(encodeTestPng(t, 32, 32), 0, 0, 0, 10)
+	assert.Error(t, err)
+}
+
+func TestMarkPoint(t *testing.T) {
+	marked, err := MarkPoint(encodeTestPng(t, 200, 200), 50, 60)
+	require.NoError(t, err)
+
+	out, err := png.Decode(bytes.NewReader(marked))
+	require.NoError(t, err, "Output should be valid PNG")
+	assert.Equal(t, 200, out.Bounds().Dx())
+	assert.Equal(t, 200, out.Bounds().Dy())
+
+	// the point itself is marked, a far away pixel is untouched
+	r, g, b, _ := out.At(50, 60).RGBA()
+	assert.Equal(t, []uint32{255, 0, 0}, []uint32{r >> 8, g >> 8, b >> 8})
+
+	r, g, _, _ = out.At(150, 150).RGBA()
+	assert.Equal(t, []uint32{150, 150}, []uint32{r >> 8, g >> 8})
+}
+
+func TestMarkPoint_OutOfBounds(t *testing.T) {
+	_, err := MarkPoint(encodeTestPng(t, 32, 32), 32, 10)
+	assert.Error(t, err)
+}
+
+func TestPngFingerprint(t *testing.T) {
+	screen := encodeTestPng(t, 320, 640)
+	assert.Equal(t, PngFingerprint(screen), PngFingerprint(encodeTestPng(t, 320, 640)))
+
+	// a change the size of a text caret still changes the thumbnail
+	img, err := png.Decode(bytes.NewReader(screen))
+	require.NoError(t, err)
+	changed := image.NewRGBA(img.Bounds())
+	draw.Draw(changed, changed.Bounds(), img, image.Point{}, draw.Src)
+	for y := 100; y < 120; y++ {
+		changed.Set(50, y, color.White)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, changed))
+	assert.NotEqual(t, PngFingerprint(screen), PngFingerprint(buf.Bytes()))
+
+	assert.Equal(t, PngFingerprint([]byte("not a png")), PngFingerprint([]byte("not a png")))
+	assert.NotEqual(t, PngFingerprint([]byte("not a png")), PngFingerprint([]byte("other")))
+}
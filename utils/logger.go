@@ -1,13 +1,41 @@
 package utils
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 )
 
+// Subsystems whose debug logs can be enabled on their own with
+// SetDebugSubsystems
+const (
+	SubsystemWDA    = "wda"
+	SubsystemADB    = "adb"
+	SubsystemTunnel = "tunnel"
+	SubsystemServer = "server"
+)
+
+// Subsystems lists the subsystems SetDebugSubsystems accepts
+var Subsystems = []string{SubsystemWDA, SubsystemADB, SubsystemTunnel, SubsystemServer}
+
 var (
 	isVerbose bool
+
+	debugMu         sync.RWMutex
+	debugSubsystems = map[string]bool{}
+
+	capturesMu sync.Mutex
+	captures   = map[*logCapture]struct{}{}
 )
 
+type logCapture struct {
+	w io.Writer
+}
+
 func SetVerbose(verbose bool) {
 	isVerbose = verbose
 }
@@ -16,12 +44,98 @@ func IsVerbose() bool {
 	return isVerbose
 }
 
+// SetVerboseLevel sets how much is logged: 1 (-v) enables verbose logs, 2
+// (-vv) also traces every external command and HTTP call, with the bodies of
+// HTTP requests and responses
+func SetVerboseLevel(level int) {
+	SetVerbose(level >= 1)
+	if level >= 2 {
+		SetTrace(true)
+		SetTraceBodies(true)
+	}
+}
+
+// SetDebugSubsystems enables the debug logs of some subsystems without
+// enabling verbose logs for everything
+func SetDebugSubsystems(subsystems []string) error {
+	enabled := map[string]bool{}
+	for _, subsystem := range subsystems {
+		subsystem = strings.ToLower(strings.TrimSpace(subsystem))
+		if subsystem == "" {
+			continue
+		}
+		if !slices.Contains(Subsystems, subsystem) {
+			return fmt.Errorf("unknown subsystem '%s', expected one of: %s", subsystem, strings.Join(Subsystems, ", "))
+		}
+		enabled[subsystem] = true
+	}
+
+	debugMu.Lock()
+	debugSubsystems = enabled
+	debugMu.Unlock()
+	return nil
+}
+
+// IsDebugEnabled reports whether debug logs of a subsystem are enabled,
+// either on their own or with verbose logs
+func IsDebugEnabled(subsystem string) bool {
+	return isVerbose || isDebugSubsystem(subsystem)
+}
+
+func isDebugSubsystem(subsystem string) bool {
+	debugMu.RLock()
+	defer debugMu.RUnlock()
+	return debugSubsystems[subsystem]
+}
+
 func Verbose(format string, args ...any) {
 	if isVerbose {
 		log.Printf("[VERBOSE] "+format, args...)
 	}
+	writeCaptures("[VERBOSE] "+format, args...)
+}
+
+// Debug logs a verbose line of a subsystem, shown with verbose logs or when
+// the subsystem's debug logs are enabled
+func Debug(subsystem, format string, args ...any) {
+	prefix := "[DEBUG " + subsystem + "] "
+	if IsDebugEnabled(subsystem) {
+		log.Printf(prefix+format, args...)
+	}
+	writeCaptures(prefix+format, args...)
 }
 
 func Info(format string, args ...any) {
 	log.Printf("[INFO] "+format, args...)
+	writeCaptures("[INFO] "+format, args...)
+}
+
+// CaptureLogs copies every Verbose and Info line to w, regardless of whether
+// verbose output is enabled, until the returned function is called.
+func CaptureLogs(w io.Writer) func() {
+	capture := &logCapture{w: w}
+
+	capturesMu.Lock()
+	captures[capture] = struct{}{}
+	capturesMu.Unlock()
+
+	return func() {
+		capturesMu.Lock()
+		delete(captures, capture)
+		capturesMu.Unlock()
+	}
+}
+
+func writeCaptures(format string, args ...any) {
+	capturesMu.Lock()
+	defer capturesMu.Unlock()
+
+	if len(captures) == 0 {
+		return
+	}
+
+	line := time.Now().Format("2006/01/02 15:04:05.000") + " " + fmt.Sprintf(format, args...) + "\n"
+	for capture := range captures {
+		_, _ = io.WriteString(capture.w, line)
+	}
 }
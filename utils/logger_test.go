@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaptureLogsRecordsVerboseWhenNotEnabled(t *testing.T) {
+	SetVerbose(false)
+
+	var buf bytes.Buffer
+	stop := CaptureLogs(&buf)
+	Verbose("collecting %s", "crash logs")
+	stop()
+	Verbose("after stop")
+
+	assert.Contains(t, buf.String(), "[VERBOSE] collecting crash logs")
+	assert.NotContains(t, buf.String(), "after stop")
+}
+
+func TestSetDebugSubsystems(t *testing.T) {
+	SetVerbose(false)
+	t.Cleanup(func() { _ = SetDebugSubsystems(nil) })
+
+	assert.Error(t, SetDebugSubsystems([]string{"wda", "bluetooth"}))
+
+	assert.NoError(t, SetDebugSubsystems([]string{" WDA", "adb"}))
+	assert.True(t, IsDebugEnabled(SubsystemWDA))
+	assert.True(t, IsDebugEnabled(SubsystemADB))
+	assert.False(t, IsDebugEnabled(SubsystemTunnel))
+
+	SetVerbose(true)
+	defer SetVerbose(false)
+	assert.True(t, IsDebugEnabled(SubsystemTunnel))
+}
@@ -18,11 +18,11 @@ type ModifyPlistInput struct {
 func ModifyPlist(input ModifyPlistInput) error {
 	// try to replace first (if key exists)
 	cmd := exec.Command("plutil", "-replace", input.Key, "-string", input.Value, input.PlistPath)
-	_, err := cmd.CombinedOutput()
+	_, err := CombinedOutput(cmd)
 	if err != nil {
 		// if replace failed, try to insert (key doesn't exist)
 		cmd = exec.Command("plutil", "-insert", input.Key, "-string", input.Value, input.PlistPath)
-		output, err := cmd.CombinedOutput()
+		output, err := CombinedOutput(cmd)
 		if err != nil {
 			return fmt.Errorf("failed to modify plist: %w\n%s", err, output)
 		}
@@ -35,13 +35,13 @@ func ModifyPlist(input ModifyPlistInput) error {
 func AddBundleIconFilesToPlist(plistPath string) error {
 	// insert CFBundleIconFiles as array
 	cmd := exec.Command("plutil", "-insert", "CFBundleIconFiles", "-array", plistPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := CombinedOutput(cmd); err != nil {
 		return fmt.Errorf("failed to insert CFBundleIconFiles: %w\n%s", err, output)
 	}
 
 	// insert AppIcon.png as first element in the array
 	cmd = exec.Command("plutil", "-insert", "CFBundleIconFiles.0", "-string", "AppIcon.png", plistPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := CombinedOutput(cmd); err != nil {
 		return fmt.Errorf("failed to insert AppIcon.png: %w\n%s", err, output)
 	}
 
@@ -56,7 +56,7 @@ func ConvertPlistToJSON(plistData []byte, result any) error {
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	err := cmd.Run()
+	err := Run(cmd)
 	if err != nil {
 		return fmt.Errorf("failed to convert plist to JSON: %w\n%s", err, stderr.String())
 	}
@@ -13,11 +13,13 @@ import (
 )
 
 type provisioningProfile struct {
-	Name               string       `plist:"Name"`
-	TeamIdentifier     []string     `plist:"TeamIdentifier"`
-	ProvisionedDevices []string     `plist:"ProvisionedDevices"`
-	Entitlements       entitlements `plist:"Entitlements"`
-	ExpirationDate     time.Time    `plist:"ExpirationDate"`
+	Name                 string       `plist:"Name"`
+	TeamName             string       `plist:"TeamName"`
+	TeamIdentifier       []string     `plist:"TeamIdentifier"`
+	ProvisionedDevices   []string     `plist:"ProvisionedDevices"`
+	ProvisionsAllDevices bool         `plist:"ProvisionsAllDevices"`
+	Entitlements         entitlements `plist:"Entitlements"`
+	ExpirationDate       time.Time    `plist:"ExpirationDate"`
 }
 
 type entitlements struct {
@@ -133,7 +135,7 @@ func ResignIPA(ipaPath, deviceUDID, profileOverride, identityOverride string) (s
 	Verbose("Repackaging IPA to %s", outputPath)
 	cmd := exec.Command("zip", "-qr", outputPath, "Payload")
 	cmd.Dir = tempDir
-	output, err := cmd.CombinedOutput()
+	output, err := CombinedOutput(cmd)
 	if err != nil {
 		_ = os.Remove(outputPath)
 		return "", fmt.Errorf("failed to repackage IPA: %w\n%s", err, output)
@@ -198,7 +200,7 @@ func readBundleID(appPath string) (string, error) {
 
 func decodeProvisioningProfile(profilePath string) (*provisioningProfile, error) {
 	cmd := exec.Command("openssl", "smime", "-inform", "DER", "-verify", "-noverify", "-in", profilePath)
-	output, err := cmd.Output()
+	output, err := Output(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode profile %s: %w", profilePath, err)
 	}
@@ -328,7 +330,7 @@ note: provisioning profiles require a paid Apple Developer Program membership ($
 
 func findSigningIdentity(teamID string) (string, error) {
 	cmd := exec.Command("security", "find-identity", "-v", "-p", "codesigning")
-	output, err := cmd.Output()
+	output, err := Output(cmd)
 	if err != nil {
 		return "", fmt.Errorf("failed to list signing identities: %w", err)
 	}
@@ -382,7 +384,7 @@ func extractCertHash(identityLine string) string {
 
 func dumpAllCertificates() string {
 	cmd := exec.Command("security", "find-certificate", "-a", "-Z")
-	output, err := cmd.Output()
+	output, err := Output(cmd)
 	if err != nil {
 		return ""
 	}
@@ -446,7 +448,7 @@ func codesign(path, identity, entitlementsPath string) error {
 	args = append(args, path)
 
 	cmd := exec.Command("codesign", args...)
-	output, err := cmd.CombinedOutput()
+	output, err := CombinedOutput(cmd)
 	if err != nil {
 		return fmt.Errorf("codesign failed: %w\n%s", err, output)
 	}
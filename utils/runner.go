@@ -0,0 +1,269 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	isTrace       bool
+	isTraceBodies bool
+	isDryRun      bool
+
+	// traceOutput is where trace and dry-run lines are written
+	traceOutput io.Writer = os.Stderr
+)
+
+// ErrDryRun is returned for long-running commands that can't be started in
+// dry-run mode, whose callers need a real process to talk to
+var ErrDryRun = errors.New("not started in dry-run mode")
+
+// SetTrace enables logging every external command and HTTP call with its timing
+func SetTrace(trace bool) {
+	isTrace = trace
+}
+
+// SetTraceBodies enables logging the bodies of traced HTTP requests and
+// responses
+func SetTraceBodies(traceBodies bool) {
+	isTraceBodies = traceBodies
+}
+
+// SetDryRun enables printing external commands and HTTP calls instead of
+// running them
+func SetDryRun(dryRun bool) {
+	isDryRun = dryRun
+}
+
+func IsTrace() bool {
+	return isTrace
+}
+
+func IsDryRun() bool {
+	return isDryRun
+}
+
+// tracing reports whether calls of a subsystem are traced: with --trace, or
+// when the subsystem is selected with --log-debug
+func tracing(subsystem string) bool {
+	return isTrace || (subsystem != "" && isDebugSubsystem(subsystem))
+}
+
+// commandSubsystem returns the subsystem an external command belongs to
+func commandSubsystem(cmd *exec.Cmd) string {
+	if len(cmd.Args) > 0 && strings.TrimSuffix(filepath.Base(cmd.Args[0]), ".exe") == "adb" {
+		return SubsystemADB
+	}
+	return ""
+}
+
+func tracef(format string, args ...any) {
+	_, _ = fmt.Fprintf(traceOutput, format+"\n", args...)
+}
+
+// commandLine renders cmd as a shell-like command line for trace output
+func commandLine(cmd *exec.Cmd) string {
+	parts := make([]string, len(cmd.Args))
+	for i, arg := range cmd.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'$") {
+			arg = fmt.Sprintf("%q", arg)
+		}
+		parts[i] = arg
+	}
+	return strings.Join(parts, " ")
+}
+
+// runTraced runs a command through run, printing it instead in dry-run mode
+// and logging its duration and exit status in trace mode
+func runTraced(cmd *exec.Cmd, run func() error) error {
+	if isDryRun {
+		commandTimeouts.Delete(cmd)
+		tracef("[dry-run] %s", commandLine(cmd))
+		return nil
+	}
+
+	start := time.Now()
+	err := run()
+	if tracing(commandSubsystem(cmd)) {
+		status := "ok"
+		if err != nil {
+			status = err.Error()
+		}
+		tracef("[trace] exec %s (%s, %s)", commandLine(cmd), time.Since(start).Round(time.Millisecond), status)
+	}
+	return err
+}
+
+// CombinedOutput runs cmd like cmd.CombinedOutput through the command runner,
+// honoring trace and dry-run mode. In dry-run mode the output is empty.
+func CombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	var output []byte
+	err := runTraced(cmd, func() error {
+		var err error
+		output, err = commandRunner.CombinedOutput(cmd)
+		return err
+	})
+	return output, err
+}
+
+// Output runs cmd like cmd.Output through the command runner, honoring trace
+// and dry-run mode
+func Output(cmd *exec.Cmd) ([]byte, error) {
+	var output []byte
+	err := runTraced(cmd, func() error {
+		var err error
+		output, err = commandRunner.Output(cmd)
+		return err
+	})
+	return output, err
+}
+
+// Run runs cmd like cmd.Run through the command runner, honoring trace and
+// dry-run mode
+func Run(cmd *exec.Cmd) error {
+	return runTraced(cmd, func() error { return commandRunner.Run(cmd) })
+}
+
+// Start starts cmd like cmd.Start through the command runner. In dry-run mode
+// the command is printed and ErrDryRun returned, since there is no process
+// for the caller to wait on.
+func Start(cmd *exec.Cmd) error {
+	if isDryRun {
+		commandTimeouts.Delete(cmd)
+		tracef("[dry-run] %s", commandLine(cmd))
+		return ErrDryRun
+	}
+
+	err := commandRunner.Start(cmd)
+	if tracing(commandSubsystem(cmd)) {
+		status := "started"
+		if err != nil {
+			status = err.Error()
+		}
+		tracef("[trace] exec %s (%s)", commandLine(cmd), status)
+	}
+	return err
+}
+
+// traceTransport logs HTTP calls in trace mode and prints them without
+// sending them in dry-run mode
+type traceTransport struct {
+	next      http.RoundTripper
+	subsystem string
+}
+
+// TraceTransport wraps an HTTP transport so its requests honor trace and
+// dry-run mode. A nil transport wraps http.DefaultTransport.
+func TraceTransport(next http.RoundTripper) http.RoundTripper {
+	return SubsystemTraceTransport("", next)
+}
+
+// SubsystemTraceTransport is TraceTransport for the HTTP calls of a
+// subsystem, which are also traced when the subsystem's debug logs are enabled
+func SubsystemTraceTransport(subsystem string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceTransport{next: next, subsystem: subsystem}
+}
+
+// maxTracedBodySize bounds the part of an HTTP body that is logged, so
+// screenshots don't flood the trace
+const maxTracedBodySize = 4096
+
+// tracedBody returns the start of a body for trace output
+func tracedBody(body []byte) string {
+	if len(body) > maxTracedBodySize {
+		return fmt.Sprintf("%s... (%d bytes)", body[:maxTracedBodySize], len(body))
+	}
+	return string(body)
+}
+
+// isTextContent reports whether a response body can be read in full and
+// logged, unlike images and streams such as MJPEG
+func isTextContent(contentType string) bool {
+	return strings.Contains(contentType, "json") || strings.HasPrefix(contentType, "text/") || strings.Contains(contentType, "xml")
+}
+
+// traceRequestBody logs the body of a request, replacing it with a copy
+func traceRequestBody(req *http.Request) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		tracef("[trace] http %s %s request body unreadable: %v", req.Method, req.URL.Redacted(), err)
+		return
+	}
+	tracef("[trace] http %s %s request: %s", req.Method, req.URL.Redacted(), tracedBody(body))
+}
+
+// traceResponseBody logs the body of a text response, replacing it with a copy
+func traceResponseBody(req *http.Request, resp *http.Response) {
+	if !isTextContent(resp.Header.Get("Content-Type")) {
+		return
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		tracef("[trace] http %s %s response body unreadable: %v", req.Method, req.URL.Redacted(), err)
+		return
+	}
+	tracef("[trace] http %s %s response: %s", req.Method, req.URL.Redacted(), tracedBody(body))
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if isDryRun {
+		tracef("[dry-run] %s %s", req.Method, req.URL.Redacted())
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		// an empty JSON object lets callers decoding a response carry on
+		return &http.Response{
+			Status:     "200 OK",
+			StatusCode: http.StatusOK,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader("{}")),
+			Request:    req,
+		}, nil
+	}
+
+	if !tracing(t.subsystem) {
+		return t.next.RoundTrip(req)
+	}
+
+	if isTraceBodies {
+		traceRequestBody(req)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	status := ""
+	if err != nil {
+		status = err.Error()
+	} else {
+		status = resp.Status
+	}
+	tracef("[trace] http %s %s (%s, %s)", req.Method, req.URL.Redacted(), time.Since(start).Round(time.Millisecond), status)
+
+	if err == nil && isTraceBodies {
+		traceResponseBody(req, resp)
+	}
+	return resp, err
+}
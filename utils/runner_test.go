@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func captureTrace(t *testing.T, trace, dryRun bool) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := traceOutput
+	traceOutput = &buf
+	SetTrace(trace)
+	SetDryRun(dryRun)
+	t.Cleanup(func() {
+		traceOutput = previous
+		SetTrace(false)
+		SetDryRun(false)
+	})
+	return &buf
+}
+
+func TestDryRunPrintsCommandWithoutRunning(t *testing.T) {
+	buf := captureTrace(t, false, true)
+
+	output, err := CombinedOutput(exec.Command("mobilecli-no-such-tool", "shell", "wm size"))
+	require.NoError(t, err)
+	assert.Empty(t, output)
+	assert.Equal(t, "[dry-run] mobilecli-no-such-tool shell \"wm size\"\n", buf.String())
+
+	err = Start(exec.Command("mobilecli-no-such-tool"))
+	assert.ErrorIs(t, err, ErrDryRun)
+}
+
+func TestTraceLogsCommand(t *testing.T) {
+	buf := captureTrace(t, true, false)
+
+	// the test binary with no tests to run exits successfully
+	err := Run(exec.Command(os.Args[0], "-test.run=^$"))
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "[trace] exec ")
+	assert.Contains(t, buf.String(), ", ok)")
+}
+
+func TestTraceTransport(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		_, _ = io.WriteString(w, "pong")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: TraceTransport(nil)}
+
+	buf := captureTrace(t, true, false)
+	resp, err := client.Get(srv.URL + "/status")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, hits)
+	assert.Contains(t, buf.String(), "[trace] http GET "+srv.URL+"/status")
+
+	buf = captureTrace(t, false, true)
+	resp, err = client.Post(srv.URL+"/session", "application/json", nil)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, "{}", string(body))
+	assert.Equal(t, "[dry-run] POST "+srv.URL+"/session\n", buf.String())
+}
+
+func TestTraceTransportBodies(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+
+	buf := captureTrace(t, false, false)
+	SetTraceBodies(true)
+	t.Cleanup(func() { SetTraceBodies(false) })
+	require.NoError(t, SetDebugSubsystems([]string{SubsystemWDA}))
+	t.Cleanup(func() { _ = SetDebugSubsystems(nil) })
+
+	client := &http.Client{Transport: SubsystemTraceTransport(SubsystemWDA, nil)}
+	resp, err := client.Post(srv.URL+"/session", "application/json", bytes.NewBufferString(`{"x":1}`))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	// the bodies are logged and still reach the server and the caller
+	assert.Equal(t, `{"x":1}`, string(body))
+	assert.Contains(t, buf.String(), "[trace] http POST "+srv.URL+"/session request: {\"x\":1}")
+	assert.Contains(t, buf.String(), "[trace] http POST "+srv.URL+"/session response: {\"x\":1}")
+
+	// other subsystems aren't traced
+	buf.Reset()
+	other := &http.Client{Transport: TraceTransport(nil)}
+	resp, err = other.Get(srv.URL + "/status")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Empty(t, buf.String())
+}
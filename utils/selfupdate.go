@@ -0,0 +1,170 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ParseChecksums parses a sha256sum-style checksums file, lines of
+// "<hex digest>  <filename>", into digests keyed by file name
+func ParseChecksums(data string) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// a leading '*' marks a file hashed in binary mode
+		checksums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// ReplaceExecutable replaces the executable at target with the file at
+// source in a single rename, so target is never left half written. The new
+// file is first copied next to target, where the rename can't cross file
+// systems. Windows can't replace a running executable, so there target is
+// moved aside to target.old first.
+func ReplaceExecutable(target, source string) error {
+	info, err := os.Stat(target)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".new-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", filepath.Dir(target), err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if _, err := io.Copy(tmp, in); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	if runtime.GOOS == "windows" {
+		old := target + ".old"
+		_ = os.Remove(old)
+		if err := os.Rename(target, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, target); err != nil {
+			_ = os.Rename(old, target)
+			return err
+		}
+		return nil
+	}
+
+	return os.Rename(tmpPath, target)
+}
+
+// CompareSemver compares two semantic versions such as 1.2.3 or v1.3.0-beta.2,
+// ignoring build metadata. A prerelease is older than its release. It returns
+// false when either isn't a semantic version, e.g. a "dev" build.
+func CompareSemver(a, b string) (int, bool) {
+	coreA, preA, ok := parseSemver(a)
+	if !ok {
+		return 0, false
+	}
+	coreB, preB, ok := parseSemver(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := range coreA {
+		if coreA[i] != coreB[i] {
+			return compareInts(coreA[i], coreB[i]), true
+		}
+	}
+
+	switch {
+	case preA == "" && preB == "":
+		return 0, true
+	case preA == "":
+		return 1, true
+	case preB == "":
+		return -1, true
+	}
+
+	// prerelease identifiers compare numerically when both are numbers, and
+	// a number is older than a word
+	idsA := strings.Split(preA, ".")
+	idsB := strings.Split(preB, ".")
+	for i := 0; i < min(len(idsA), len(idsB)); i++ {
+		numA, errA := strconv.Atoi(idsA[i])
+		numB, errB := strconv.Atoi(idsB[i])
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				return compareInts(numA, numB), true
+			}
+		case errA == nil:
+			return -1, true
+		case errB == nil:
+			return 1, true
+		default:
+			if c := strings.Compare(idsA[i], idsB[i]); c != 0 {
+				return c, true
+			}
+		}
+	}
+	return compareInts(len(idsA), len(idsB)), true
+}
+
+// parseSemver splits a semantic version into major, minor and patch, and
+// its prerelease
+func parseSemver(version string) ([3]int, string, bool) {
+	var core [3]int
+
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	version, _, _ = strings.Cut(version, "+")
+	version, prerelease, _ := strings.Cut(version, "-")
+
+	parts := strings.Split(version, ".")
+	if len(parts) != 3 {
+		return core, "", false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return core, "", false
+		}
+		core[i] = n
+	}
+	return core, prerelease, true
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
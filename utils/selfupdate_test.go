@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChecksums(t *testing.T) {
+	checksums := ParseChecksums("ABC123  mobilecli-0.1.9-linux-amd64.zip\ndef456 *mobilecli-0.1.9-macos-arm64.zip\n\nnot a checksum line here\n")
+	assert.Equal(t, map[string]string{
+		"mobilecli-0.1.9-linux-amd64.zip": "abc123",
+		"mobilecli-0.1.9-macos-arm64.zip": "def456",
+	}, checksums)
+}
+
+func TestReplaceExecutable(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "mobilecli")
+	require.NoError(t, os.WriteFile(target, []byte("old"), 0o755))
+
+	source := filepath.Join(t.TempDir(), "mobilecli")
+	require.NoError(t, os.WriteFile(source, []byte("new"), 0o644))
+
+	require.NoError(t, ReplaceExecutable(target, source))
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o755), info.Mode().Perm())
+
+	// no temporary file is left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestCompareSemver(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"0.0.40", "0.0.39", 1},
+		{"v0.0.40", "0.0.40", 0},
+		{"0.1.0", "0.0.99", 1},
+		{"0.0.40-beta.1", "0.0.40", -1},
+		{"0.0.40-beta.2", "0.0.40-beta.10", -1},
+		{"0.0.40-beta.1", "0.0.39", 1},
+		{"0.0.40-alpha", "0.0.40-beta", -1},
+		{"0.0.40+build.5", "0.0.40", 0},
+	} {
+		got, ok := CompareSemver(tc.a, tc.b)
+		assert.True(t, ok, "%s vs %s", tc.a, tc.b)
+		assert.Equal(t, tc.want, got, "%s vs %s", tc.a, tc.b)
+	}
+
+	_, ok := CompareSemver("dev", "0.0.40")
+	assert.False(t, ok)
+}
@@ -0,0 +1,19 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// IsTerminal reports whether f is a terminal, which can't be told here
+func IsTerminal(f *os.File) bool {
+	return false
+}
+
+// MakeTerminalRaw is not supported on this platform
+func MakeTerminalRaw(f *os.File) (func() error, error) {
+	return nil, fmt.Errorf("raw terminal input is not supported on %s", runtime.GOOS)
+}
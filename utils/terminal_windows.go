@@ -0,0 +1,37 @@
+//go:build windows
+
+package utils
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// IsTerminal reports whether f is a console rather than a pipe or file
+func IsTerminal(f *os.File) bool {
+	var mode uint32
+	return windows.GetConsoleMode(windows.Handle(f.Fd()), &mode) == nil
+}
+
+// MakeTerminalRaw puts the console f into raw mode, so every keystroke is
+// read as it's typed without being echoed, including ctrl+c, and arrow keys
+// arrive as the escape sequences of a unix terminal. The returned func
+// restores the console.
+func MakeTerminalRaw(f *os.File) (func() error, error) {
+	handle := windows.Handle(f.Fd())
+	var original uint32
+	if err := windows.GetConsoleMode(handle, &original); err != nil {
+		return nil, err
+	}
+
+	mode := original &^ (windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT)
+	mode |= windows.ENABLE_VIRTUAL_TERMINAL_INPUT
+	if err := windows.SetConsoleMode(handle, mode); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		return windows.SetConsoleMode(handle, original)
+	}, nil
+}
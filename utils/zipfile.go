@@ -24,6 +24,55 @@ func Unzip(zipPath string) (string, error) {
 	return tempDir, nil
 }
 
+// ZipDirectory writes the contents of srcDir into a new zip archive at zipPath,
+// with entry names relative to srcDir
+func ZipDirectory(srcDir, zipPath string) error {
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", zipPath, err)
+	}
+	defer func() { _ = out.Close() }()
+
+	writer := zip.NewWriter(out)
+
+	err = filepath.WalkDir(srcDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		dst, err := writer.Create(filepath.ToSlash(relPath))
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = src.Close() }()
+
+		_, err = io.Copy(dst, src)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add files to archive: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+
+	return out.Close()
+}
+
 // unzipFile extracts a zip file to the specified destination
 func unzipFile(zipPath, destDir string) error {
 	reader, err := zip.OpenReader(zipPath)
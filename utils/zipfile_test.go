@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZipDirectoryRoundTrip(t *testing.T) {
+	srcDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "device.json"), []byte(`{"id":"emulator-5554"}`), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "crashes"), 0750))
+	require.NoError(t, os.WriteFile(filepath.Join(srcDir, "crashes", "app.ips"), []byte("crash"), 0600))
+
+	zipPath := filepath.Join(t.TempDir(), "report.zip")
+	require.NoError(t, ZipDirectory(srcDir, zipPath))
+
+	extracted, err := Unzip(zipPath)
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(extracted) }()
+
+	content, err := os.ReadFile(filepath.Join(extracted, "crashes", "app.ips"))
+	require.NoError(t, err)
+	assert.Equal(t, "crash", string(content))
+}